@@ -13,7 +13,9 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/mohamedkhairy/stock-scanner/internal/alert"
 	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
 	"github.com/mohamedkhairy/stock-scanner/internal/pubsub"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
 	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -47,7 +49,6 @@ func main() {
 			logger.ErrorField(err),
 		)
 	}
-	defer redisClient.Close()
 
 	// Initialize alert service components
 	deduplicator := alert.NewDeduplicator(redisClient, cfg.Alert.DedupeTTL)
@@ -60,6 +61,8 @@ func main() {
 		QueueSize:  cfg.Alert.DBWriteQueueSize,
 		MaxRetries: cfg.Alert.DBMaxRetries,
 		RetryDelay: cfg.Alert.DBRetryDelay,
+
+		AggregateWindow: cfg.Alert.DBAggregateWindow,
 	}
 	persister, err := alert.NewAlertPersister(cfg.Database, writeConfig)
 	if err != nil {
@@ -67,7 +70,17 @@ func main() {
 			logger.ErrorField(err),
 		)
 	}
-	defer persister.Close()
+
+	// Apply alert history chunk sizing and compression policy
+	if cfg.Database.ManageHypertables {
+		policyCtx, policyCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := persister.Hypertables().ApplyPolicy(policyCtx, storage.AlertsHypertablePolicy(cfg.Database)); err != nil {
+			logger.Warn("Failed to apply alert hypertable policy",
+				logger.ErrorField(err),
+			)
+		}
+		policyCancel()
+	}
 
 	// Start persister
 	if err := persister.Start(); err != nil {
@@ -78,6 +91,41 @@ func main() {
 
 	// Initialize router
 	router := alert.NewRouter(redisClient, cfg.Alert.FilteredStreamName, 5*time.Second)
+	router.SetSinkCircuitBreaker(alert.CircuitBreakerConfig{
+		FailureThreshold: cfg.Alert.SinkBreakerFailureThreshold,
+		OpenDuration:     cfg.Alert.SinkBreakerOpenDuration,
+	})
+	router.SetRestrictedSinks(cfg.Alert.RestrictedSinks, models.NewAlertRedactor(cfg.Alert.AlertRedactFields))
+	if cfg.Alert.AlertmanagerURL != "" {
+		router.AddSink(alert.NewAlertmanagerSink(alert.AlertmanagerConfig{
+			URL:     cfg.Alert.AlertmanagerURL,
+			Timeout: cfg.Alert.AlertmanagerTimeout,
+		}))
+		logger.Info("Alertmanager alert sink enabled",
+			logger.String("url", cfg.Alert.AlertmanagerURL),
+		)
+	}
+	webhookConfig := alert.DefaultWebhookConfig()
+	webhookConfig.Timeout = cfg.Alert.WebhookTimeout
+	webhookConfig.MaxRetries = cfg.Alert.WebhookMaxRetries
+	webhookConfig.RetryDelay = cfg.Alert.WebhookRetryDelay
+	webhookConfig.DeadLetterStream = cfg.Alert.WebhookDeadLetterStream
+	webhooks := alert.NewWebhookDeliverer(webhookConfig, redisClient)
+	if cfg.Alert.WebhookURL != "" {
+		if err := webhooks.Register(alert.WebhookEndpoint{URL: cfg.Alert.WebhookURL, Secret: cfg.Alert.WebhookSecret}); err != nil {
+			logger.Fatal("Invalid alert webhook",
+				logger.ErrorField(err),
+			)
+		}
+	}
+	// Users register their own webhooks, each with its own secret, through the API
+	webhooks.SetWebhookSource(storage.NewWebhookStore(redisClient), cfg.Alert.WebhookRefreshInterval)
+	router.AddSink(webhooks)
+	logger.Info("Webhook alert sink enabled",
+		logger.Bool("operator_webhook", cfg.Alert.WebhookURL != ""),
+		logger.Duration("refresh_interval", cfg.Alert.WebhookRefreshInterval),
+		logger.String("dead_letter_stream", cfg.Alert.WebhookDeadLetterStream),
+	)
 
 	// Initialize consumer
 	consumer := alert.NewConsumer(
@@ -89,13 +137,27 @@ func main() {
 		router,
 	)
 
+	// Initialize localizer for per-user alert messages
+	localizer := alert.NewLocalizer(cfg.Alert.DefaultLocale)
+	for userID, locale := range cfg.Alert.UserLocales {
+		localizer.SetUserLocale(userID, locale)
+	}
+	consumer.SetLocalizer(localizer)
+	consumer.SetUserPreferences(storage.NewUserPreferencesStore(redisClient))
+	if budget := alert.NewSymbolBudget(redisClient, cfg.Alert.SymbolBudget, cfg.Alert.SymbolBudgetWindow); budget != nil {
+		consumer.SetSymbolBudget(budget)
+		logger.Info("Per-symbol alert budget enabled",
+			logger.Int("alerts_per_window", cfg.Alert.SymbolBudget),
+			logger.Duration("window", cfg.Alert.SymbolBudgetWindow),
+		)
+	}
+
 	// Start consumer
 	if err := consumer.Start(); err != nil {
 		logger.Fatal("Failed to start alert consumer",
 			logger.ErrorField(err),
 		)
 	}
-	defer consumer.Stop()
 
 	// Set up HTTP server for health checks and metrics
 	routerMux := mux.NewRouter()
@@ -166,6 +228,32 @@ func main() {
 		)
 	}
 
+	// Drain the alert pipeline before closing connections: stop consuming and finish
+	// routing the in-flight batch, then flush the persister queue, then close DB and Redis
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), cfg.Alert.ShutdownTimeout)
+	defer drainCancel()
+
+	if err := consumer.Shutdown(drainCtx); err != nil {
+		logger.Error("Error stopping alert consumer",
+			logger.ErrorField(err),
+		)
+	}
+	if err := persister.Shutdown(drainCtx); err != nil {
+		logger.Error("Error draining alert persister",
+			logger.ErrorField(err),
+		)
+	}
+	if err := persister.Close(); err != nil {
+		logger.Error("Error closing alert database connection",
+			logger.ErrorField(err),
+		)
+	}
+	if err := redisClient.Close(); err != nil {
+		logger.Error("Error closing Redis client",
+			logger.ErrorField(err),
+		)
+	}
+
 	logger.Info("Alert service stopped")
 }
 
@@ -13,10 +13,12 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/mohamedkhairy/stock-scanner/internal/api"
 	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/metrics"
 	"github.com/mohamedkhairy/stock-scanner/internal/pubsub"
 	"github.com/mohamedkhairy/stock-scanner/internal/rules"
 	"github.com/mohamedkhairy/stock-scanner/internal/storage"
 	"github.com/mohamedkhairy/stock-scanner/internal/toplist"
+	"github.com/mohamedkhairy/stock-scanner/internal/wsgateway"
 	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -94,6 +96,24 @@ func main() {
 	}
 	defer alertStorage.Close()
 
+	// Initialize indicator history storage (read-only here; values are written by the indicator engine)
+	indicatorStorage, err := storage.NewTimescaleIndicatorStorage(cfg.Database, storage.WriteConfigFromIndicatorConfig(cfg.Indicator))
+	if err != nil {
+		logger.Fatal("Failed to initialize indicator storage",
+			logger.ErrorField(err),
+		)
+	}
+	defer indicatorStorage.Close()
+
+	// Initialize scan statistics history storage (read-only here; samples are written by scanner workers)
+	scanStatsStorage, err := storage.NewTimescaleScanStatsStorage(cfg.Database)
+	if err != nil {
+		logger.Fatal("Failed to initialize scan stats storage",
+			logger.ErrorField(err),
+		)
+	}
+	defer scanStatsStorage.Close()
+
 	// Initialize toplist store
 	toplistStore, err := toplist.NewDatabaseToplistStore(cfg.Database)
 	if err != nil {
@@ -109,10 +129,31 @@ func main() {
 
 	// Initialize handlers
 	ruleHandler := api.NewRuleHandler(ruleStore, compiler, syncService)
+	ruleHandler.SetLimits(rules.RuleLimits{
+		MaxRulesPerUser:      cfg.API.MaxRulesPerUser,
+		MaxConditionsPerRule: cfg.API.MaxConditionsPerRule,
+		MaxNestingDepth:      cfg.API.MaxRuleNestingDepth,
+	})
+	ruleHandler.SetDisabledRuleStore(storage.NewDisabledRuleStore(redisClient))
+	if len(cfg.API.ScannerURLs) > 0 {
+		ruleHandler.SetRuleEvaluator(api.NewScannerRuleEvaluator(cfg.API.ScannerURLs, cfg.API.ScannerToken, cfg.API.ScannerTimeout))
+	}
 	alertHandler := api.NewAlertHandler(alertStorage)
+	testAlertHandler := api.NewTestAlertHandler(redisClient, cfg.Alert.StreamName)
+	alertNoteHandler := api.NewAlertNoteHandler(alertStorage, alertStorage, redisClient)
 	symbolHandler := api.NewSymbolHandler(cfg.MarketData.Symbols)
-	userHandler := api.NewUserHandler()
+	symbolHandler.SetUniverseStore(storage.NewSymbolUniverseStore(redisClient))
+	indicatorHandler := api.NewIndicatorHandler(indicatorStorage)
+	scanStatsHandler := api.NewScanStatsHandler(scanStatsStorage)
+	userHandler := api.NewUserHandler(storage.NewUserPreferencesStore(redisClient))
 	toplistHandler := api.NewToplistHandler(toplistService, toplistStore)
+	adminHandler := api.NewAdminHandler(alertStorage.Hypertables(), []storage.HypertablePolicy{
+		storage.BarsHypertablePolicy(cfg.Database),
+		storage.AlertsHypertablePolicy(cfg.Database),
+	})
+	muteHandler := api.NewMuteHandler(storage.NewSymbolMuteStore(redisClient))
+	customMetricHandler := api.NewCustomMetricHandler(storage.NewCustomMetricStore(redisClient), metrics.NewRegistry().Names())
+	webhookHandler := api.NewWebhookHandler(storage.NewWebhookStore(redisClient), ruleStore)
 
 	// Set up router
 	router := mux.NewRouter()
@@ -120,36 +161,74 @@ func main() {
 	// API v1 routes
 	v1 := router.PathPrefix("/api/v1").Subrouter()
 
+	// Admin endpoints are limited to API_ADMIN_USERS
+	requireAdmin := api.AdminMiddleware(cfg.API.AdminUsers)
+
 	// Rule management endpoints
 	v1.HandleFunc("/rules", ruleHandler.ListRules).Methods("GET")
 	v1.HandleFunc("/rules", ruleHandler.CreateRule).Methods("POST")
+	v1.HandleFunc("/rules/import", ruleHandler.ImportRules).Methods("POST")
+	v1.HandleFunc("/rules/export", ruleHandler.ExportRules).Methods("GET")
+	v1.HandleFunc("/rules/evaluate", ruleHandler.EvaluateRule).Methods("POST")
+	v1.HandleFunc("/rules/disabled", ruleHandler.ListDisabledRules).Methods("GET")
 	v1.HandleFunc("/rules/{id}", ruleHandler.GetRule).Methods("GET")
 	v1.HandleFunc("/rules/{id}", ruleHandler.UpdateRule).Methods("PUT")
 	v1.HandleFunc("/rules/{id}", ruleHandler.DeleteRule).Methods("DELETE")
 	v1.HandleFunc("/rules/{id}/validate", ruleHandler.ValidateRule).Methods("POST")
+	v1.HandleFunc("/rules/{id}/enable", ruleHandler.EnableRule).Methods("POST")
 
 	// Alert history endpoints
 	v1.HandleFunc("/alerts", alertHandler.ListAlerts).Methods("GET")
+	v1.HandleFunc("/alerts/test", testAlertHandler.SendTestAlert).Methods("POST")
 	v1.HandleFunc("/alerts/{id}", alertHandler.GetAlert).Methods("GET")
+	v1.HandleFunc("/alerts/{id}/notes", alertNoteHandler.ListNotes).Methods("GET")
+	v1.HandleFunc("/alerts/{id}/notes", alertNoteHandler.AddNote).Methods("POST")
+
+	// User webhook endpoints
+	v1.HandleFunc("/webhooks", webhookHandler.ListWebhooks).Methods("GET")
+	v1.HandleFunc("/webhooks", webhookHandler.CreateWebhook).Methods("POST")
+	v1.HandleFunc("/webhooks/{id}", webhookHandler.DeleteWebhook).Methods("DELETE")
 
 	// Symbol management endpoints
 	v1.HandleFunc("/symbols", symbolHandler.ListSymbols).Methods("GET")
+	v1.Handle("/symbols", requireAdmin(http.HandlerFunc(symbolHandler.UpdateSymbols))).Methods("PUT")
 	v1.HandleFunc("/symbols/{symbol}", symbolHandler.GetSymbol).Methods("GET")
+	v1.HandleFunc("/symbols/{symbol}/alerts", alertHandler.ListSymbolAlerts).Methods("GET")
+
+	// User custom metric endpoints
+	v1.HandleFunc("/metrics/custom", customMetricHandler.ListCustomMetrics).Methods("GET")
+	v1.HandleFunc("/metrics/custom/{name}", customMetricHandler.SaveCustomMetric).Methods("PUT")
+	v1.HandleFunc("/metrics/custom/{name}", customMetricHandler.DeleteCustomMetric).Methods("DELETE")
+
+	// Indicator history endpoints
+	v1.HandleFunc("/indicators/{symbol}", indicatorHandler.GetIndicators).Methods("GET")
+	v1.HandleFunc("/scan-stats", scanStatsHandler.GetScanStats).Methods("GET")
 
 	// User management endpoints
 	v1.HandleFunc("/user/profile", userHandler.GetProfile).Methods("GET")
 	v1.HandleFunc("/user/profile", userHandler.UpdateProfile).Methods("PUT")
+	v1.HandleFunc("/user/preferences", userHandler.GetPreferences).Methods("GET")
+	v1.HandleFunc("/user/preferences", userHandler.UpdatePreferences).Methods("PUT")
 
 	// Toplist endpoints
 	v1.HandleFunc("/toplists", toplistHandler.ListToplists).Methods("GET")
 	v1.HandleFunc("/toplists/system/{id}", toplistHandler.GetSystemToplist).Methods("GET")
+	v1.HandleFunc("/toplists/preview", toplistHandler.PreviewToplist).Methods("POST")
 	v1.HandleFunc("/toplists/user", toplistHandler.ListUserToplists).Methods("GET")
 	v1.HandleFunc("/toplists/user", toplistHandler.CreateUserToplist).Methods("POST")
+	v1.HandleFunc("/toplists/user/export", toplistHandler.ExportUserToplists).Methods("GET")
+	v1.HandleFunc("/toplists/user/import", toplistHandler.ImportUserToplists).Methods("POST")
 	v1.HandleFunc("/toplists/user/{id}", toplistHandler.GetUserToplist).Methods("GET")
 	v1.HandleFunc("/toplists/user/{id}", toplistHandler.UpdateUserToplist).Methods("PUT")
 	v1.HandleFunc("/toplists/user/{id}", toplistHandler.DeleteUserToplist).Methods("DELETE")
 	v1.HandleFunc("/toplists/user/{id}/rankings", toplistHandler.GetToplistRankings).Methods("GET")
 
+	// Admin endpoints
+	v1.Handle("/admin/storage", requireAdmin(http.HandlerFunc(adminHandler.GetStorageSettings))).Methods("GET")
+	v1.Handle("/admin/mute", requireAdmin(http.HandlerFunc(muteHandler.ListMutes))).Methods("GET")
+	v1.Handle("/admin/mute/{symbol}", requireAdmin(http.HandlerFunc(muteHandler.MuteSymbol))).Methods("POST")
+	v1.Handle("/admin/mute/{symbol}", requireAdmin(http.HandlerFunc(muteHandler.UnmuteSymbol))).Methods("DELETE")
+
 	// TODO: access the bars data from the database (bars_1m table, ..etc)
 
 	// Health check endpoints
@@ -179,12 +258,39 @@ func main() {
 	// Metrics endpoint
 	router.Handle("/metrics", promhttp.Handler())
 
+	// Shed low-priority routes (list endpoints by default) before auth and database work under overload
+	loadSheddingConfig := api.LoadSheddingConfig{
+		MaxInFlight:       cfg.API.LoadShedMaxInFlight,
+		MaxLatency:        cfg.API.LoadShedMaxLatency,
+		LowPriorityRoutes: cfg.API.LoadShedLowPriorityRoutes,
+	}
+	if len(loadSheddingConfig.LowPriorityRoutes) == 0 {
+		loadSheddingConfig.LowPriorityRoutes = api.DefaultLowPriorityRoutes()
+	}
+
+	trustedProxies, err := wsgateway.ParseTrustedProxies(cfg.API.TrustedProxies)
+	if err != nil {
+		logger.Fatal("Invalid trusted proxies",
+			logger.ErrorField(err),
+		)
+	}
+
 	// Apply middleware
 	middlewares := api.ChainMiddleware(
 		api.CORSMiddleware(),
-		api.LoggingMiddleware(),
+		api.LoggingMiddlewareWithConfig(api.LoggingConfig{
+			Service:    "api",
+			SampleRate: cfg.API.LogSampleRate,
+		}),
 		api.ErrorHandlingMiddleware(),
-		api.AuthMiddleware(cfg.API.JWTSecret),
+		api.MaxBodySizeMiddleware(int64(cfg.API.MaxRequestBodyBytes)),
+		api.LoadSheddingMiddleware(loadSheddingConfig),
+		api.AuthMiddlewareWithConfig(api.AuthConfig{
+			JWTSecret:      cfg.API.JWTSecret,
+			FailureLimit:   cfg.API.AuthFailureLimit,
+			FailureWindow:  cfg.API.AuthFailureWindow,
+			TrustedProxies: trustedProxies,
+		}),
 		api.RateLimitMiddleware(cfg.API.RateLimitRPS),
 	)
 
@@ -62,6 +62,17 @@ func main() {
 	}
 	defer dbClient.Close()
 
+	// Apply bars chunk sizing and compression policy
+	if cfg.Database.ManageHypertables {
+		policyCtx, policyCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := dbClient.Hypertables().ApplyPolicy(policyCtx, storage.BarsHypertablePolicy(cfg.Database)); err != nil {
+			logger.Warn("Failed to apply bars hypertable policy",
+				logger.ErrorField(err),
+			)
+		}
+		policyCancel()
+	}
+
 	// Start TimescaleDB write queue processor
 	if err := dbClient.Start(); err != nil {
 		logger.Fatal("Failed to start TimescaleDB client",
@@ -70,7 +81,26 @@ func main() {
 	}
 
 	// Initialize bar aggregator
-	aggregator := bars.NewAggregator()
+	timeframes, err := bars.ParseTimeframes(cfg.Bars.Timeframes)
+	if err != nil {
+		logger.Fatal("Invalid bar timeframes",
+			logger.ErrorField(err),
+		)
+	}
+	aggregatorConfig := bars.DefaultAggregatorConfig()
+	aggregatorConfig.Timeframes = timeframes
+	aggregator := bars.NewAggregatorWithConfig(aggregatorConfig)
+	if cfg.Bars.ExchangeTimezone != "" {
+		exchangeLocation, err := time.LoadLocation(cfg.Bars.ExchangeTimezone)
+		if err != nil {
+			logger.Fatal("Invalid exchange timezone",
+				logger.String("timezone", cfg.Bars.ExchangeTimezone),
+				logger.ErrorField(err),
+			)
+		}
+		aggregator.SetLocation(exchangeLocation)
+	}
+	aggregator.SetVWAPExcludedTypes(cfg.Bars.VWAPExcludedTickTypes)
 
 	// Initialize bar publisher
 	publisherConfig := bars.DefaultPublisherConfig()
@@ -94,17 +124,87 @@ func main() {
 		}
 	})
 
+	// Publish each longer timeframe to its own stream. Only 1m bars are persisted to TimescaleDB.
+	for _, timeframe := range timeframes {
+		timeframeConfig := publisherConfig
+		timeframeConfig.FinalizedStream = bars.TimeframeStream(publisherConfig.FinalizedStream, timeframe)
+		timeframePublisher := bars.NewPublisher(redisClient, timeframeConfig)
+		if err := timeframePublisher.Start(); err != nil {
+			logger.Fatal("Failed to start timeframe bar publisher",
+				logger.ErrorField(err),
+				logger.String("timeframe", bars.TimeframeLabel(timeframe)),
+			)
+		}
+		defer timeframePublisher.Stop()
+
+		if err := aggregator.SetOnTimeframeBarFinal(timeframe, func(bar *models.Bar1m) {
+			if err := timeframePublisher.PublishFinalizedBar(bar); err != nil {
+				logger.Error("Failed to publish finalized timeframe bar",
+					logger.ErrorField(err),
+					logger.String("symbol", bar.Symbol),
+					logger.String("timeframe", bar.Timeframe),
+				)
+			}
+		}); err != nil {
+			logger.Fatal("Failed to set timeframe bar callback",
+				logger.ErrorField(err),
+			)
+		}
+	}
+
 	// Initialize stream consumer
+	// Offset recovery reads the consumer's own pending messages, so the name must survive
+	// restarts; messages pending for other names are claimed once idle for the claim timeout
+	consumerName := cfg.Bars.ConsumerName
+	if consumerName == "" {
+		consumerName = fmt.Sprintf("bars-consumer-%d", os.Getpid())
+		if cfg.Bars.PersistConsumerOffsets {
+			if hostname, err := os.Hostname(); err == nil {
+				consumerName = fmt.Sprintf("bars-consumer-%s", hostname)
+			}
+		}
+	}
 	consumerConfig := pubsub.DefaultStreamConsumerConfig(
 		cfg.Ingest.StreamName,
 		cfg.Bars.ConsumerGroup,
-		fmt.Sprintf("bars-consumer-%d", os.Getpid()),
+		consumerName,
 	)
 	// Use partitions from ingest config if available, otherwise default to 0
 	consumerConfig.Partitions = 0 // Can be configured via environment variable if needed
 	consumerConfig.BatchSize = cfg.Bars.BatchSize
 	consumerConfig.ProcessTimeout = 5 * time.Second
 	consumerConfig.AckTimeout = 10 * time.Second
+	consumerConfig.PersistOffsets = cfg.Bars.PersistConsumerOffsets
+	consumerConfig.DrainTimeout = cfg.Bars.ConsumerDrainTimeout
+	consumerConfig.ClaimMinIdle = cfg.Bars.ConsumerClaimMinIdle
+	replayFrom, err := pubsub.ParseReplayFrom(cfg.Bars.ReplayEnabled, cfg.Bars.ReplayFrom)
+	if err != nil {
+		logger.Fatal("Invalid replay configuration",
+			logger.ErrorField(err),
+		)
+	}
+	if !replayFrom.IsZero() {
+		logger.Warn("Replaying tick stream",
+			logger.Time("from", replayFrom),
+		)
+	} else if cfg.Bars.ReplayFrom != "" {
+		logger.Warn("BARS_REPLAY_FROM is set but BARS_REPLAY_ENABLED is not, ignoring replay")
+	}
+	consumerConfig.ReplayFrom = replayFrom
+
+	// Resume the timeframe bars left open by the last shutdown, so their buckets are emitted once
+	openBarsKey := bars.OpenTimeframeBarsKey(consumerName)
+	if len(timeframes) > 0 {
+		openBars, err := bars.LoadOpenTimeframeBars(context.Background(), redisClient, openBarsKey)
+		if err != nil {
+			logger.Error("Failed to restore open timeframe bars", logger.ErrorField(err))
+		} else if len(openBars) > 0 {
+			aggregator.RestoreOpenTimeframeBars(openBars)
+			logger.Info("Restored open timeframe bars",
+				logger.Int("count", len(openBars)),
+			)
+		}
+	}
 
 	consumer := pubsub.NewStreamConsumer(redisClient, consumerConfig)
 	consumer.SetAggregator(aggregator)
@@ -125,7 +225,7 @@ func main() {
 
 	// Setup health and metrics server
 	var wg sync.WaitGroup
-	healthRouter := setupHealthAndMetricsServer(cfg, aggregator, consumer, publisher, dbClient)
+	healthRouter := setupHealthAndMetricsServer(cfg, aggregator, consumer, publisher, dbClient, redisClient)
 	healthServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Bars.HealthCheckPort),
 		Handler:      healthRouter,
@@ -164,6 +264,9 @@ func main() {
 	// Wait for all goroutines to finish
 	wg.Wait()
 
+	// Stop reading ticks and process the ones already read, so they land in the bars finalized below
+	consumer.Stop()
+
 	// Finalize all remaining live bars
 	finalizedBars := aggregator.FinalizeAllBars()
 	if len(finalizedBars) > 0 {
@@ -177,6 +280,20 @@ func main() {
 		time.Sleep(500 * time.Millisecond)
 	}
 
+	// Save the timeframe bars whose bucket has not completed instead of emitting them short
+	if len(timeframes) > 0 {
+		openBars := aggregator.TakeOpenTimeframeBars()
+		saveCtx, saveCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := bars.SaveOpenTimeframeBars(saveCtx, redisClient, openBarsKey, openBars); err != nil {
+			logger.Error("Failed to save open timeframe bars", logger.ErrorField(err))
+		} else if len(openBars) > 0 {
+			logger.Info("Saved open timeframe bars for restart",
+				logger.Int("count", len(openBars)),
+			)
+		}
+		saveCancel()
+	}
+
 	logger.Info("Bars aggregator service stopped")
 }
 
@@ -187,6 +304,7 @@ func setupHealthAndMetricsServer(
 	consumer *pubsub.StreamConsumer,
 	publisher *bars.Publisher,
 	dbClient *storage.TimescaleDBClient,
+	redisClient storage.RedisClient,
 ) *mux.Router {
 	router := mux.NewRouter()
 
@@ -214,6 +332,7 @@ func setupHealthAndMetricsServer(
 					"status":  "ok",
 					"running": dbClient.IsRunning(),
 				},
+				"redis": pubsub.RedisHealthCheck(redisClient),
 			},
 		}
 
@@ -15,7 +15,9 @@ import (
 	"github.com/mohamedkhairy/stock-scanner/internal/config"
 	"github.com/mohamedkhairy/stock-scanner/internal/indicator"
 	"github.com/mohamedkhairy/stock-scanner/internal/pubsub"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
 	"github.com/mohamedkhairy/stock-scanner/internal/toplist"
+	indicatorpkg "github.com/mohamedkhairy/stock-scanner/pkg/indicator"
 	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -52,7 +54,14 @@ func main() {
 
 	// Initialize indicator registry
 	indicatorRegistry := indicator.NewIndicatorRegistry()
-	if err := indicator.RegisterAllIndicators(indicatorRegistry); err != nil {
+	registrationConfig := indicator.DefaultRegistrationConfig()
+	registrationConfig.MACD, err = indicatorpkg.ParseMACDParams(cfg.Indicator.MACDPeriods)
+	if err != nil {
+		logger.Fatal("Invalid INDICATOR_MACD_PERIODS",
+			logger.ErrorField(err),
+		)
+	}
+	if err := indicator.RegisterAllIndicatorsWithConfig(indicatorRegistry, registrationConfig); err != nil {
 		logger.Fatal("Failed to register indicators",
 			logger.ErrorField(err),
 		)
@@ -64,8 +73,25 @@ func main() {
 
 	// Initialize indicator engine
 	engineConfig := indicator.DefaultEngineConfig()
+	overrides, err := indicator.ParseParameterOverrides(cfg.Indicator.ParameterOverrides)
+	if err != nil {
+		logger.Fatal("Invalid INDICATOR_PARAMETER_OVERRIDES",
+			logger.ErrorField(err),
+		)
+	}
+	engineConfig.ParameterOverrides = overrides
+	if len(overrides) > 0 {
+		logger.Info("Loaded indicator parameter overrides",
+			logger.Int("symbol_count", len(overrides)),
+		)
+	}
 	engine := indicator.NewEngine(engineConfig, indicatorRegistry)
 
+	// Seed indicator state from stored bars when starting mid-session
+	if cfg.Indicator.RehydrateBars > 0 {
+		rehydrateIndicators(cfg, engine)
+	}
+
 	// Initialize indicator publisher
 	publisherConfig := indicator.DefaultPublisherConfig()
 	publisher := indicator.NewPublisher(redisClient, publisherConfig)
@@ -84,6 +110,8 @@ func main() {
 	}
 
 	toplistUpdater := toplist.NewRedisToplistUpdater(redisClient)
+	toplistUpdater.SetDefaultMaxSize(cfg.Toplist.DefaultMaxSize)
+	toplistUpdater.SetEvictionWindow(cfg.Toplist.EvictAfter)
 	publisher.SetToplistUpdater(toplistUpdater, toplistStore != nil)
 	if toplistStore != nil {
 		publisher.SetToplistStore(toplistStore)
@@ -92,6 +120,27 @@ func main() {
 		logger.Info("Toplist integration disabled (database unavailable)")
 	}
 
+	// Initialize historical indicator storage (optional)
+	if cfg.Indicator.DBPersistEnabled {
+		indicatorStorage, err := storage.NewTimescaleIndicatorStorage(cfg.Database, storage.WriteConfigFromIndicatorConfig(cfg.Indicator))
+		if err != nil {
+			logger.Fatal("Failed to initialize indicator storage",
+				logger.ErrorField(err),
+			)
+		}
+		if err := indicatorStorage.Start(); err != nil {
+			logger.Fatal("Failed to start indicator storage",
+				logger.ErrorField(err),
+			)
+		}
+		defer indicatorStorage.Close()
+		publisher.SetIndicatorStorage(indicatorStorage)
+		logger.Info("Indicator history persistence enabled",
+			logger.Int("batch_size", cfg.Indicator.DBWriteBatchSize),
+			logger.Duration("interval", cfg.Indicator.DBWriteInterval),
+		)
+	}
+
 	if err := publisher.Start(); err != nil {
 		logger.Fatal("Failed to start indicator publisher",
 			logger.ErrorField(err),
@@ -100,8 +149,8 @@ func main() {
 	defer publisher.Stop()
 
 	// Set up engine to publish indicators after processing bars
-	engine.SetOnIndicatorsUpdated(func(symbol string, indicators map[string]float64) {
-		if err := publisher.PublishIndicators(symbol, indicators); err != nil {
+	engine.SetOnIndicatorsUpdated(func(symbol string, timestamp time.Time, indicators map[string]float64) {
+		if err := publisher.PublishIndicators(symbol, timestamp, indicators); err != nil {
 			logger.Error("Failed to publish indicators",
 				logger.ErrorField(err),
 				logger.String("symbol", symbol),
@@ -138,7 +187,7 @@ func main() {
 
 	// Setup health and metrics server
 	var wg sync.WaitGroup
-	healthRouter := setupHealthAndMetricsServer(cfg, engine, barConsumer, publisher)
+	healthRouter := setupHealthAndMetricsServer(cfg, engine, barConsumer, publisher, redisClient)
 	healthServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Indicator.HealthCheckPort),
 		Handler:      healthRouter,
@@ -180,6 +229,35 @@ func main() {
 	logger.Info("Indicator engine service stopped")
 }
 
+// rehydrateIndicators replays the latest stored bars of the configured symbols into the
+// engine. Rehydration is best effort: without the database the engine starts empty.
+func rehydrateIndicators(cfg *config.Config, engine *indicator.Engine) {
+	dbClient, err := storage.NewTimescaleDBClient(cfg.Database, storage.WriteConfig{
+		BatchSize:  100,
+		Interval:   5 * time.Second,
+		QueueSize:  1,
+		MaxRetries: 1,
+		RetryDelay: time.Second,
+	})
+	if err != nil {
+		logger.Warn("Failed to connect to TimescaleDB, skipping indicator rehydration",
+			logger.ErrorField(err),
+		)
+		return
+	}
+	defer dbClient.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	symbols := cfg.MarketData.IngestSymbols()
+	rehydrated := engine.RehydrateFromStorage(ctx, dbClient, symbols, cfg.Indicator.RehydrateBars)
+	logger.Info("Indicator rehydration complete",
+		logger.Int("symbols_rehydrated", rehydrated),
+		logger.Int("total_symbols", len(symbols)),
+		logger.Int("max_bars", cfg.Indicator.RehydrateBars),
+	)
+}
 
 // setupHealthAndMetricsServer sets up HTTP endpoints for health checks and metrics
 func setupHealthAndMetricsServer(
@@ -187,6 +265,7 @@ func setupHealthAndMetricsServer(
 	engine *indicator.Engine,
 	consumer *indicator.BarConsumer,
 	publisher *indicator.Publisher,
+	redisClient storage.RedisClient,
 ) *mux.Router {
 	router := mux.NewRouter()
 
@@ -210,6 +289,7 @@ func setupHealthAndMetricsServer(
 					"status":  "ok",
 					"running": publisher.IsRunning(),
 				},
+				"redis": pubsub.RedisHealthCheck(redisClient),
 			},
 		}
 
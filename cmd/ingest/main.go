@@ -16,6 +16,7 @@ import (
 	"github.com/mohamedkhairy/stock-scanner/internal/data"
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
 	"github.com/mohamedkhairy/stock-scanner/internal/pubsub"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
 	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -65,8 +66,17 @@ func main() {
 	streamPublisher.Start()
 	defer streamPublisher.Close()
 
-	// Initialize normalizer
-	normalizer := data.NewNormalizer(cfg.MarketData.Provider)
+	// Initialize clock skew detector
+	skewDetector := data.NewClockSkewDetector(data.ClockSkewConfig{
+		MaxSkew: cfg.Ingest.MaxClockSkew,
+		Action:  data.ClockSkewAction(cfg.Ingest.ClockSkewAction),
+	}, cfg.MarketData.Provider)
+
+	// Initialize provider data quality monitor (nil if disabled)
+	qualityMonitor := data.NewDataQualityMonitor(data.DataQualityConfig{
+		Window: cfg.Ingest.DataQualityWindow,
+	}, cfg.MarketData.Provider)
+	go qualityMonitor.Run(ctx)
 
 	// Initialize provider factory
 	providerFactory := data.NewProviderFactory()
@@ -77,9 +87,18 @@ func main() {
 		APISecret: cfg.MarketData.APISecret,
 		BaseURL:   cfg.MarketData.BaseURL,
 		WSURL:     cfg.MarketData.WebSocketURL,
+		Channels:  cfg.MarketData.Channels,
+		// Providers normalize their own messages with the configured timestamp source
+		TimestampSource: data.TimestampSource(cfg.MarketData.TimestampSourceFor(cfg.MarketData.Provider)),
+	}
+
+	// Backoff used to resubscribe the provider (and each provider of a composite one)
+	reconnectConfig := data.ReconnectConfig{
+		InitialDelay: cfg.Ingest.ReconnectDelay,
+		MaxDelay:     cfg.Ingest.MaxReconnectDelay,
 	}
 
-	provider, err := providerFactory.CreateProvider(cfg.MarketData.Provider, providerConfig)
+	provider, err := createProvider(providerFactory, cfg.MarketData, providerConfig, reconnectConfig)
 	if err != nil {
 		logger.Fatal("Failed to create provider",
 			logger.ErrorField(err),
@@ -87,6 +106,7 @@ func main() {
 		)
 	}
 	defer provider.Close()
+	qualityMonitor.SetProvider(provider)
 
 	// Connect to provider
 	if err := provider.Connect(ctx); err != nil {
@@ -95,8 +115,19 @@ func main() {
 		)
 	}
 
-	// Subscribe to symbols
-	tickChan, err := provider.Subscribe(ctx, cfg.MarketData.Symbols)
+	// The symbol universe set through the API overrides the configured symbols
+	universeStore := storage.NewSymbolUniverseStore(redisClient)
+	if universe, err := universeStore.GetUniverse(ctx); err != nil {
+		logger.Warn("Failed to load symbol universe (using configured symbols)",
+			logger.ErrorField(err),
+		)
+	} else if len(universe) > 0 {
+		cfg.MarketData.Symbols = universe
+	}
+
+	// Subscribe to symbols (including reference symbols)
+	symbols := cfg.MarketData.IngestSymbols()
+	tickChan, err := provider.Subscribe(ctx, symbols)
 	if err != nil {
 		logger.Fatal("Failed to subscribe to symbols",
 			logger.ErrorField(err),
@@ -104,17 +135,43 @@ func main() {
 	}
 
 	logger.Info("Subscribed to symbols",
-		logger.Int("count", len(cfg.MarketData.Symbols)),
-		logger.String("symbols", fmt.Sprintf("%v", cfg.MarketData.Symbols)),
+		logger.Int("count", len(symbols)),
+		logger.String("symbols", fmt.Sprintf("%v", symbols)),
+		logger.Int("reference_count", len(cfg.MarketData.ReferenceSymbols)),
 	)
 
+	// Resubscribe with backoff when the provider's tick channel closes
+	feed := data.NewReconnectingFeed(provider, symbols, reconnectConfig)
+
 	// Start ingestion loop
 	var wg sync.WaitGroup
 	wg.Add(1)
-	go ingestLoop(ctx, &wg, tickChan, normalizer, streamPublisher)
+	go ingestLoop(ctx, &wg, feed, tickChan, skewDetector, qualityMonitor, streamPublisher)
+
+	// Follow symbol universe changes without a restart
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		universeStore.Follow(ctx, cfg.MarketData.Symbols, storage.DefaultUniverseReconcileInterval, func(universe []string) {
+			marketData := cfg.MarketData
+			marketData.Symbols = universe
+			added, removed, err := feed.Resubscribe(ctx, marketData.IngestSymbols())
+			if err != nil {
+				logger.Error("Failed to resubscribe to symbol universe",
+					logger.ErrorField(err),
+				)
+				return
+			}
+			logger.Info("Resubscribed to symbol universe",
+				logger.Int("count", len(universe)),
+				logger.String("added", fmt.Sprintf("%v", added)),
+				logger.String("removed", fmt.Sprintf("%v", removed)),
+			)
+		})
+	}()
 
 	// Start HTTP server for health checks and metrics
-	healthServer := startHealthServer(cfg.Ingest.HealthCheckPort, provider, streamPublisher)
+	healthServer := startHealthServer(cfg.Ingest.HealthCheckPort, provider, feed, streamPublisher, skewDetector, qualityMonitor, redisClient)
 	defer func() {
 		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer shutdownCancel()
@@ -137,12 +194,57 @@ func main() {
 	logger.Info("Ingest service stopped")
 }
 
+// createProvider creates the configured provider. When symbols are mapped to other
+// providers, a composite provider merging all of them is returned, each created with its own
+// credentials and endpoints and resubscribed with the reconnect backoff when its channel closes.
+func createProvider(factory data.ProviderFactory, marketData config.MarketDataConfig, providerConfig data.ProviderConfig, reconnect data.ReconnectConfig) (data.Provider, error) {
+	if len(marketData.SymbolProviders) == 0 {
+		return factory.CreateProvider(marketData.Provider, providerConfig)
+	}
+
+	providers := make(map[string]data.Provider)
+	names := []string{marketData.Provider}
+	for _, name := range marketData.SymbolProviders {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		if _, exists := providers[name]; exists {
+			continue
+		}
+		settings := marketData.ProviderSettingsFor(name)
+		childConfig := providerConfig
+		childConfig.APIKey = settings.APIKey
+		childConfig.APISecret = settings.APISecret
+		childConfig.BaseURL = settings.BaseURL
+		childConfig.WSURL = settings.WebSocketURL
+		childConfig.TimestampSource = data.TimestampSource(marketData.TimestampSourceFor(name))
+		provider, err := factory.CreateProvider(name, childConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create provider %s: %w", name, err)
+		}
+		providers[name] = provider
+	}
+
+	logger.Info("Using composite provider",
+		logger.Int("providers", len(providers)),
+		logger.Int("routed_symbols", len(marketData.SymbolProviders)),
+	)
+
+	return data.NewCompositeProvider(providers, data.CompositeProviderConfig{
+		SymbolProviders: marketData.SymbolProviders,
+		DefaultProvider: marketData.Provider,
+		Reconnect:       reconnect,
+	})
+}
+
 // ingestLoop processes ticks from the provider and publishes them to Redis streams
 func ingestLoop(
 	ctx context.Context,
 	wg *sync.WaitGroup,
+	feed *data.ReconnectingFeed,
 	tickChan <-chan *models.Tick,
-	normalizer data.Normalizer,
+	skewDetector *data.ClockSkewDetector,
+	qualityMonitor *data.DataQualityMonitor,
 	publisher *pubsub.StreamPublisher,
 ) {
 	defer wg.Done()
@@ -150,49 +252,41 @@ func ingestLoop(
 	tickCount := 0
 	errorCount := 0
 
-	for {
-		select {
-		case <-ctx.Done():
-			logger.Info("Ingestion loop stopped",
-				logger.Int("ticks_processed", tickCount),
-				logger.Int("errors", errorCount),
-			)
-			return
+	// Runs until ctx is done, resubscribing when the provider's tick channel closes
+	feed.Run(ctx, tickChan, func(tick *models.Tick) {
+		// Record feed quality before the timestamp can be corrected for skew
+		qualityMonitor.Observe(tick)
 
-		case tick, ok := <-tickChan:
-			if !ok {
-				logger.Warn("Tick channel closed")
-				return
-			}
-
-			if tick == nil {
-				continue
-			}
+		// Detect provider clock skew (corrects timestamp or flags symbol)
+		skewDetector.Check(tick)
 
-			// Publish tick directly (already normalized by provider)
-			// If provider returns raw messages, we'd normalize here
-			if err := publisher.Publish(tick); err != nil {
-				errorCount++
-				logger.Error("Failed to publish tick",
-					logger.ErrorField(err),
-					logger.String("symbol", tick.Symbol),
-				)
-				continue
-			}
+		// Publish tick directly (already normalized by provider)
+		if err := publisher.Publish(tick); err != nil {
+			errorCount++
+			logger.Error("Failed to publish tick",
+				logger.ErrorField(err),
+				logger.String("symbol", tick.Symbol),
+			)
+			return
+		}
 
-			tickCount++
-			if tickCount%1000 == 0 {
-				logger.Debug("Processed ticks",
-					logger.Int("count", tickCount),
-					logger.Int("errors", errorCount),
-				)
-			}
+		tickCount++
+		if tickCount%1000 == 0 {
+			logger.Debug("Processed ticks",
+				logger.Int("count", tickCount),
+				logger.Int("errors", errorCount),
+			)
 		}
-	}
+	})
+
+	logger.Info("Ingestion loop stopped",
+		logger.Int("ticks_processed", tickCount),
+		logger.Int("errors", errorCount),
+	)
 }
 
 // startHealthServer starts the HTTP server for health checks and metrics
-func startHealthServer(port int, provider data.Provider, publisher *pubsub.StreamPublisher) *http.Server {
+func startHealthServer(port int, provider data.Provider, feed *data.ReconnectingFeed, publisher *pubsub.StreamPublisher, skewDetector *data.ClockSkewDetector, qualityMonitor *data.DataQualityMonitor, redisClient storage.RedisClient) *http.Server {
 	router := mux.NewRouter()
 
 	// Health check endpoint
@@ -205,14 +299,23 @@ func startHealthServer(port int, provider data.Provider, publisher *pubsub.Strea
 					"status":    "ok",
 					"connected": provider.IsConnected(),
 					"provider":  provider.GetName(),
+					"reconnect": feed.GetStats(),
 				},
 				"publisher": map[string]interface{}{
 					"status":     "ok",
 					"batch_size": publisher.GetBatchSize(),
 				},
+				"clock_skew": skewStatus(skewDetector.GetStats()),
+				"redis":      pubsub.RedisHealthCheck(redisClient),
 			},
 		}
 
+		// Report each underlying provider when running a composite provider
+		if composite, ok := provider.(*data.CompositeProvider); ok {
+			checks := health["checks"].(map[string]interface{})
+			checks["provider"].(map[string]interface{})["providers"] = composite.ProviderStatus()
+		}
+
 		// Determine overall status
 		if !provider.IsConnected() {
 			health["status"] = "degraded"
@@ -225,6 +328,18 @@ func startHealthServer(port int, provider data.Provider, publisher *pubsub.Strea
 		json.NewEncoder(w).Encode(health)
 	}).Methods("GET")
 
+	// Clock skew endpoint
+	router.HandleFunc("/skew", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(skewDetector.GetStats())
+	}).Methods("GET")
+
+	// Provider data quality endpoint
+	router.HandleFunc("/quality", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(qualityMonitor.GetStats())
+	}).Methods("GET")
+
 	// Readiness probe
 	router.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
 		if provider.IsConnected() {
@@ -266,3 +381,19 @@ func startHealthServer(port int, provider data.Provider, publisher *pubsub.Strea
 
 	return server
 }
+
+// skewStatus builds the clock skew section of the health response
+func skewStatus(stats data.ClockSkewStats) map[string]interface{} {
+	status := "ok"
+	if len(stats.FlaggedSymbols) > 0 {
+		status = "skewed"
+	}
+	return map[string]interface{}{
+		"status":            status,
+		"last_skew":         stats.LastSkew.String(),
+		"max_observed_skew": stats.MaxObservedSkew.String(),
+		"ticks_skewed":      stats.TicksSkewed,
+		"ticks_corrected":   stats.TicksCorrected,
+		"flagged_symbols":   stats.FlaggedSymbols,
+	}
+}
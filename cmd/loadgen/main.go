@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/loadgen"
+	"github.com/mohamedkhairy/stock-scanner/internal/pubsub"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+func main() {
+	defaults := loadgen.DefaultConfig()
+
+	symbols := flag.Int("symbols", defaults.Symbols, "number of synthetic symbols")
+	rate := flag.Int("rate", defaults.Rate, "target ticks per second after ramp-up")
+	rampUp := flag.Duration("ramp-up", defaults.RampUp, "time to ramp linearly from 0 to the target rate")
+	duration := flag.Duration("duration", defaults.Duration, "total run time including ramp-up (0 = until interrupted)")
+	stream := flag.String("stream", "", "tick stream name (defaults to INGEST_STREAM_NAME)")
+	flag.Parse()
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Initialize logger
+	if err := logger.Init(cfg.LogLevel, cfg.Environment); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer logger.Sync()
+
+	streamName := *stream
+	if streamName == "" {
+		streamName = cfg.Ingest.StreamName
+	}
+
+	// Create context for graceful shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Initialize Redis client
+	redisClient, err := pubsub.NewRedisClient(cfg.Redis)
+	if err != nil {
+		logger.Fatal("Failed to initialize Redis client",
+			logger.ErrorField(err),
+		)
+	}
+	defer redisClient.Close()
+
+	// Initialize stream publisher (same batching as the ingest service)
+	publisherConfig := pubsub.DefaultStreamPublisherConfig(streamName)
+	publisherConfig.BatchSize = cfg.Ingest.BatchSize
+	publisherConfig.BatchTimeout = cfg.Ingest.BatchTimeout
+
+	streamPublisher := pubsub.NewStreamPublisher(redisClient, publisherConfig)
+	streamPublisher.Start()
+	defer streamPublisher.Close()
+
+	genConfig := defaults
+	genConfig.Symbols = *symbols
+	genConfig.Rate = *rate
+	genConfig.RampUp = *rampUp
+	genConfig.Duration = *duration
+
+	generator, err := loadgen.NewGenerator(genConfig, streamPublisher)
+	if err != nil {
+		logger.Fatal("Invalid load generator configuration",
+			logger.ErrorField(err),
+		)
+	}
+
+	// Stop on interrupt
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		logger.Info("Received shutdown signal")
+		cancel()
+	}()
+
+	stats := generator.Run(ctx)
+
+	fmt.Printf("stream:          %s\n", streamName)
+	fmt.Printf("symbols:         %d\n", genConfig.Symbols)
+	fmt.Printf("target rate:     %d ticks/s\n", genConfig.Rate)
+	fmt.Printf("ticks published: %d\n", stats.TicksPublished)
+	fmt.Printf("publish errors:  %d\n", stats.PublishErrors)
+	fmt.Printf("elapsed:         %s\n", stats.Elapsed)
+	fmt.Printf("throughput:      %.1f ticks/s\n", stats.Throughput)
+}
@@ -14,6 +14,8 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/metrics"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
 	"github.com/mohamedkhairy/stock-scanner/internal/pubsub"
 	"github.com/mohamedkhairy/stock-scanner/internal/rules"
 	"github.com/mohamedkhairy/stock-scanner/internal/scanner"
@@ -87,9 +89,35 @@ func main() {
 			logger.ErrorField(err),
 		)
 	}
+	partitionGroups, err := scanner.ParseSymbolGroups(cfg.Scanner.PartitionGroups)
+	if err != nil {
+		logger.Fatal("Invalid SCANNER_PARTITION_GROUPS",
+			logger.ErrorField(err),
+		)
+	}
+	partitionKeyFunc, err := scanner.NewPartitionKeyFunc(cfg.Scanner.PartitionKey, partitionGroups)
+	if err != nil {
+		logger.Fatal("Invalid SCANNER_PARTITION_KEY",
+			logger.ErrorField(err),
+		)
+	}
+	partitionManager.SetPartitionKeyFunc(partitionKeyFunc)
+	if err := partitionManager.SetPartitionStrategy(cfg.Scanner.PartitionStrategy, cfg.Scanner.PartitionVirtualNodes); err != nil {
+		logger.Fatal("Invalid SCANNER_PARTITION_STRATEGY",
+			logger.ErrorField(err),
+		)
+	}
+	partitionWeights, err := scanner.ParseSymbolWeights(cfg.Scanner.PartitionWeights)
+	if err != nil {
+		logger.Fatal("Invalid SCANNER_PARTITION_WEIGHTS",
+			logger.ErrorField(err),
+		)
+	}
+	partitionManager.SetSymbolWeights(partitionWeights)
 
 	// Initialize state manager
 	stateManager := scanner.NewStateManager(200) // Keep last 200 finalized bars
+	stateManager.SetOutOfOrderBarPolicy(scanner.OutOfOrderBarPolicy(cfg.Scanner.OutOfOrderBarPolicy))
 
 	// Initialize rule store (memory or Redis based on config)
 	var ruleStore rules.RuleStore
@@ -110,11 +138,13 @@ func main() {
 		logger.Info("Using in-memory rule store")
 	}
 
-	// Initialize rule compiler
+	// Initialize rule compiler with user-scoped custom metrics (system metric names are reserved)
+	customMetrics := rules.NewCustomMetricRegistry(metrics.NewRegistry().Names())
 	compiler := rules.NewCompiler(nil)
+	compiler.SetCustomMetricRegistry(customMetrics)
 
-	// Initialize cooldown tracker with global cooldown from config
-	cooldownTracker := scanner.NewCooldownTracker(cfg.Scanner.CooldownDefault, 5*time.Minute)
+	// Initialize cooldown tracker (cooldowns are per rule)
+	cooldownTracker := scanner.NewCooldownTracker(5 * time.Minute)
 	if err := cooldownTracker.Start(); err != nil {
 		logger.Fatal("Failed to start cooldown tracker",
 			logger.ErrorField(err),
@@ -141,12 +171,17 @@ func main() {
 			defer toplistStore.Close()
 
 			toplistUpdater := toplist.NewRedisToplistUpdater(redisClient)
+			toplistUpdater.SetDefaultMaxSize(cfg.Toplist.DefaultMaxSize)
+			toplistUpdater.SetEvictionWindow(cfg.Toplist.EvictAfter)
 			toplistIntegration = scanner.NewToplistIntegration(
 				toplistUpdater,
 				toplistStore,
 				cfg.Scanner.EnableToplists,
 				cfg.Scanner.ToplistUpdateInterval,
 			)
+			toplistIntegration.SetCustomMetricResolver(customMetrics)
+			toplistIntegration.SetFlushLimits(cfg.Toplist.FlushChunkSize, cfg.Toplist.FlushConcurrency)
+			toplistIntegration.SetNormalizationWindow(cfg.Toplist.NormalizationWindow)
 			logger.Info("Toplist integration enabled",
 				logger.Duration("update_interval", cfg.Scanner.ToplistUpdateInterval),
 			)
@@ -157,6 +192,43 @@ func main() {
 	scanLoopConfig := scanner.DefaultScanLoopConfig()
 	scanLoopConfig.ScanInterval = cfg.Scanner.ScanInterval
 	scanLoopConfig.RuleReloadInterval = cfg.Scanner.RuleReloadInterval
+	scanLoopConfig.ReferenceSymbols = cfg.MarketData.ReferenceSymbols
+	if cfg.Scanner.LULDTiers != "" {
+		luldTiers, err := metrics.ParseLULDTiers(cfg.Scanner.LULDTiers)
+		if err != nil {
+			logger.Fatal("Invalid LULD tier configuration",
+				logger.ErrorField(err),
+			)
+		}
+		scanLoopConfig.LULDTiers = luldTiers
+	}
+	if err := scanner.ValidateTrackedSymbols(cfg.Scanner.TrackedSymbols); err != nil {
+		logger.Fatal("Invalid tracked symbols configuration",
+			logger.ErrorField(err),
+		)
+	}
+	scanLoopConfig.TrackedSymbols = cfg.Scanner.TrackedSymbols
+	scanLoopConfig.MaxDataStaleness = cfg.Scanner.MaxDataStaleness
+	scanLoopConfig.MaxAlertMetrics = cfg.Scanner.MaxAlertMetrics
+	scanLoopConfig.CoalesceCycles = cfg.Scanner.AlertCoalesceCycles
+	scanLoopConfig.ExplainAlerts = cfg.Scanner.AlertExplain
+	breadthRules, err := scanner.ParseBreadthRules(cfg.Scanner.BreadthRules)
+	if err != nil {
+		logger.Fatal("Invalid breadth rule configuration",
+			logger.ErrorField(err),
+		)
+	}
+	scanLoopConfig.BreadthRules = breadthRules
+	scanLoopConfig.BreadthTopSymbols = cfg.Scanner.BreadthTopSymbols
+	symbolTiers, err := scanner.ParseSymbolTiers(cfg.Scanner.SymbolTiers)
+	if err != nil {
+		logger.Fatal("Invalid symbol tier configuration",
+			logger.ErrorField(err),
+		)
+	}
+	scanLoopConfig.SymbolTiers = symbolTiers
+	scanLoopConfig.RuleErrorLimit = cfg.Scanner.RuleErrorLimit
+	scanLoopConfig.RuleErrorWindow = cfg.Scanner.RuleErrorWindow
 	scanLoop := scanner.NewScanLoop(
 		scanLoopConfig,
 		stateManager,
@@ -167,9 +239,53 @@ func main() {
 		toplistIntegration,
 	)
 
+	// Replays drive time-dependent logic from the replayed data instead of the wall clock
+	if cfg.Scanner.ReplayMode {
+		clock := scanner.NewSimulationClock(time.Time{})
+		stateManager.SetClock(clock)
+		cooldownTracker.SetClock(clock)
+		scanLoop.SetClock(clock)
+		logger.Info("Replay mode enabled: scanner time follows event timestamps")
+	}
+
+	// Symbol mutes (admin kill switch) are checked before every alert emission
+	symbolMutes := scanner.NewSymbolMuteCache(storage.NewSymbolMuteStore(redisClient), cfg.Scanner.MuteRefreshInterval)
+	symbolMutes.Start()
+	defer symbolMutes.Stop()
+	scanLoop.SetSymbolMutes(symbolMutes)
+
+	// Breadth rules count the matching symbols of every worker, not just this partition
+	scanLoop.SetBreadthStore(redisClient)
+
+	// User custom metrics are managed through the API and loaded with every rule reload
+	scanLoop.SetCustomMetricSource(storage.NewCustomMetricStore(redisClient))
+
+	// Operators are notified of rules disabled after repeated evaluation errors
+	// and recorded for GET /api/v1/rules/disabled
+	scanLoop.SetRuleDisableNotifier(scanner.NewRedisRuleDisableNotifier(redisClient))
+	scanLoop.SetDisabledRuleRecorder(storage.NewDisabledRuleStore(redisClient))
+
+	// The symbol universe set through the API overrides the configured one, and can change
+	// at runtime
+	universeStore := storage.NewSymbolUniverseStore(redisClient)
+	symbolUniverse := cfg.Scanner.SymbolUniverse
+	universeCtx, universeCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if stored, err := universeStore.GetUniverse(universeCtx); err != nil {
+		logger.Warn("Failed to load symbol universe (using configured symbols)",
+			logger.ErrorField(err),
+		)
+	} else if len(stored) > 0 {
+		symbolUniverse = stored
+	}
+	universeCancel()
+
 	// Initialize rehydrator
 	rehydratorConfig := scanner.DefaultRehydrationConfig()
-	rehydratorConfig.Symbols = cfg.Scanner.SymbolUniverse
+	rehydratorConfig.Symbols = append([]string(nil), symbolUniverse...)
+	if len(rehydratorConfig.Symbols) > 0 {
+		// Reference symbols must be rehydrated for cross-symbol metrics
+		rehydratorConfig.Symbols = append(rehydratorConfig.Symbols, cfg.MarketData.ReferenceSymbols...)
+	}
 	rehydrator := scanner.NewRehydrator(rehydratorConfig, stateManager, dbClient, redisClient)
 
 	// Rehydrate state on startup
@@ -191,10 +307,16 @@ func main() {
 	// TODO: Load rules from database or config file
 	logger.Info("No initial rules loaded (rules will be added via API)")
 
+	// Every worker reads the full tick and bar streams through its own consumer group and
+	// applies only the symbols it owns (plus the reference symbols every worker needs).
+	// A shared group would hand entries out round-robin regardless of symbol.
+	consumerGroup := fmt.Sprintf("scanner-group-%d", workerID)
+	ownedSymbols := partitionManager.OwnershipFilter(cfg.MarketData.ReferenceSymbols)
+
 	// Initialize tick consumer
 	tickConsumerConfig := pubsub.DefaultStreamConsumerConfig(
 		cfg.Ingest.StreamName,
-		"scanner-group",
+		consumerGroup,
 		fmt.Sprintf("scanner-%s", cfg.Scanner.WorkerID),
 	)
 	tickConsumerConfig.Partitions = 0 // Will be configured based on partitioning
@@ -203,15 +325,17 @@ func main() {
 	tickConsumerConfig.AckTimeout = 10 * time.Second
 
 	tickConsumer := scanner.NewTickConsumer(redisClient, tickConsumerConfig, stateManager)
+	tickConsumer.SetSymbolFilter(ownedSymbols)
 
 	// Initialize indicator consumer
 	indicatorConsumerConfig := scanner.DefaultIndicatorConsumerConfig()
 	indicatorConsumer := scanner.NewIndicatorConsumer(redisClient, indicatorConsumerConfig, stateManager)
+	indicatorConsumer.SetSymbolFilter(ownedSymbols)
 
 	// Initialize bar finalization handler
 	barHandlerConfig := pubsub.DefaultStreamConsumerConfig(
 		"bars.finalized",
-		"scanner-group",
+		consumerGroup,
 		fmt.Sprintf("scanner-%s", cfg.Scanner.WorkerID),
 	)
 	barHandlerConfig.Partitions = 0
@@ -220,6 +344,7 @@ func main() {
 	barHandlerConfig.AckTimeout = 10 * time.Second
 
 	barHandler := scanner.NewBarFinalizationHandler(redisClient, barHandlerConfig, stateManager)
+	barHandler.SetSymbolFilter(ownedSymbols)
 
 	// Start all consumers
 	logger.Info("Starting consumers...")
@@ -254,14 +379,61 @@ func main() {
 	}
 	defer scanLoop.Stop()
 
+	// Start rules health analyzer
+	ruleHealthConfig := scanner.RuleHealthConfig{
+		NeverFiringAfter:  cfg.Scanner.RuleHealthNeverFiringAfter,
+		AlwaysFiringRatio: cfg.Scanner.RuleHealthAlwaysFiringRatio,
+		MinEvaluations:    int64(cfg.Scanner.RuleHealthMinEvaluations),
+		CheckInterval:     cfg.Scanner.RuleHealthCheckInterval,
+	}
+	ruleHealthAnalyzer := scanner.NewRuleHealthAnalyzer(ruleHealthConfig, ruleStore, scanLoop.GetRuleStats)
+	ruleHealthAnalyzer.Start()
+	defer ruleHealthAnalyzer.Stop()
+
+	// Start scan statistics persistence (optional)
+	if cfg.Scanner.StatsPersistEnabled {
+		scanStatsStorage, err := storage.NewTimescaleScanStatsStorage(cfg.Database)
+		if err != nil {
+			logger.Fatal("Failed to initialize scan stats storage",
+				logger.ErrorField(err),
+			)
+		}
+		defer scanStatsStorage.Close()
+
+		scanStatsRecorder := scanner.NewScanStatsRecorder(cfg.Scanner.WorkerID, cfg.Scanner.StatsPersistInterval, scanLoop.GetStats, scanStatsStorage)
+		scanStatsRecorder.Start()
+		defer scanStatsRecorder.Stop()
+		logger.Info("Scan stats persistence enabled",
+			logger.Duration("interval", cfg.Scanner.StatsPersistInterval),
+		)
+	}
+
 	logger.Info("Scanner worker service started",
 		logger.String("worker_id", cfg.Scanner.WorkerID),
 		logger.Int("worker_count", cfg.Scanner.WorkerCount),
 		logger.Int("symbol_count", stateManager.GetSymbolCount()),
 	)
 
-	// Setup health and metrics server
+	// Assign this worker its partition of the universe and follow universe changes
+	universeHandler := scanner.NewSymbolUniverseHandler(partitionManager, stateManager, cfg.MarketData.ReferenceSymbols)
+	if len(symbolUniverse) > 0 {
+		universeHandler.Apply(symbolUniverse)
+	} else {
+		partitionManager.Repartition(cfg.MarketData.Symbols)
+	}
+
 	var wg sync.WaitGroup
+	watchCtx, watchCancel := context.WithCancel(context.Background())
+	defer watchCancel()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		universeStore.Follow(watchCtx, symbolUniverse, storage.DefaultUniverseReconcileInterval, func(symbols []string) {
+			universeHandler.Apply(symbols)
+		})
+	}()
+
+	// Setup health and metrics server
 	healthRouter := setupHealthAndMetricsServer(
 		cfg,
 		stateManager,
@@ -272,6 +444,8 @@ func main() {
 		cooldownTracker,
 		alertEmitter,
 		partitionManager,
+		ruleHealthAnalyzer,
+		redisClient,
 	)
 	healthServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Scanner.HealthCheckPort),
@@ -308,6 +482,8 @@ func main() {
 		logger.Error("Health server shutdown failed", logger.ErrorField(err))
 	}
 
+	watchCancel()
+
 	// Wait for all goroutines to finish
 	wg.Wait()
 
@@ -346,6 +522,8 @@ func setupHealthAndMetricsServer(
 	cooldownTracker *scanner.InMemoryCooldownTracker,
 	alertEmitter *scanner.AlertEmitterImpl,
 	partitionManager *scanner.PartitionManager,
+	ruleHealthAnalyzer *scanner.RuleHealthAnalyzer,
+	redisClient storage.RedisClient,
 ) *mux.Router {
 	router := mux.NewRouter()
 
@@ -360,6 +538,7 @@ func setupHealthAndMetricsServer(
 				"count": cfg.Scanner.WorkerCount,
 			},
 			"checks": map[string]interface{}{
+				"redis": pubsub.RedisHealthCheck(redisClient),
 				"state_manager": map[string]interface{}{
 					"status":       "ok",
 					"symbol_count": stateManager.GetSymbolCount(),
@@ -367,6 +546,7 @@ func setupHealthAndMetricsServer(
 				"scan_loop": map[string]interface{}{
 					"status":  "ok",
 					"running": scanLoop.IsRunning(),
+					"paused":  scanLoop.IsPaused(),
 					"stats":   scanLoop.GetStats(),
 				},
 				"tick_consumer": map[string]interface{}{
@@ -405,6 +585,9 @@ func setupHealthAndMetricsServer(
 		if !scanLoop.IsRunning() || !tickConsumer.IsRunning() || !indicatorConsumer.IsRunning() || !barHandler.IsRunning() {
 			status = http.StatusServiceUnavailable
 			healthStatus["status"] = "DOWN"
+		} else if scanLoop.IsPaused() {
+			// Paused for maintenance: still healthy, but not evaluating rules
+			healthStatus["status"] = "PAUSED"
 		}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -453,6 +636,7 @@ func setupHealthAndMetricsServer(
 			"partition_manager": map[string]interface{}{
 				"worker_id":        partitionManager.GetWorkerID(),
 				"total_workers":    partitionManager.GetTotalWorkers(),
+				"strategy":         partitionManager.GetPartitionStrategy(),
 				"assigned_count":   partitionManager.GetAssignedSymbolCount(),
 				"assigned_symbols": partitionManager.GetAssignedSymbols(),
 			},
@@ -462,5 +646,68 @@ func setupHealthAndMetricsServer(
 		json.NewEncoder(w).Encode(stats)
 	}).Methods("GET")
 
+	// Pause/resume scanning for maintenance (state and consumers stay alive; token protected)
+	if cfg.Scanner.ControlToken == "" {
+		logger.Warn("SCANNER_CONTROL_TOKEN not set, control endpoints will reject all requests")
+	}
+	router.Handle("/scan/pause", scanner.RequireToken(cfg.Scanner.ControlToken, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scanLoop.Pause()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"paused": true})
+	}))).Methods("POST")
+
+	router.Handle("/scan/resume", scanner.RequireToken(cfg.Scanner.ControlToken, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scanLoop.Resume()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"paused": false})
+	}))).Methods("POST")
+
+	// Rules health report (never-firing and always-firing rules)
+	router.HandleFunc("/rules/health", func(w http.ResponseWriter, r *http.Request) {
+		report, err := ruleHealthAnalyzer.Report()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to build rules health report: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	}).Methods("GET")
+
+	// Dry-run evaluation of an unsaved rule against this worker's live state (token protected,
+	// called by the API)
+	router.Handle("/rules/evaluate", scanner.RequireToken(cfg.Scanner.ControlToken, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var rule models.Rule
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&rule); err != nil {
+			http.Error(w, fmt.Sprintf("invalid rule: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := rules.ValidateRule(&rule); err != nil {
+			http.Error(w, fmt.Sprintf("invalid rule: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		evaluation, err := scanLoop.EvaluateRule(&rule)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to evaluate rule: %v", err), http.StatusUnprocessableEntity)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(evaluation)
+	}))).Methods("POST")
+
+	// Full state dump for offline analysis (opt-in, token protected)
+	if cfg.Scanner.DebugDumpEnabled {
+		if cfg.Scanner.DebugDumpToken == "" {
+			logger.Warn("Debug dump enabled without SCANNER_DEBUG_DUMP_TOKEN, all requests will be rejected")
+		}
+		router.Handle("/debug/dump", scanner.NewStateDumpHandler(
+			stateManager,
+			cfg.Scanner.DebugDumpToken,
+			cfg.Scanner.DebugDumpMaxSymbols,
+		)).Methods("GET")
+	}
+
 	return router
 }
@@ -14,7 +14,9 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
 	"github.com/mohamedkhairy/stock-scanner/internal/pubsub"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
 	"github.com/mohamedkhairy/stock-scanner/internal/wsgateway"
 	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -62,9 +64,44 @@ func main() {
 
 	// Initialize auth manager
 	authManager := wsgateway.NewAuthManager(cfg.WSGateway.JWTSecret)
+	authLimiter := wsgateway.NewAuthFailureLimiter(cfg.WSGateway.AuthFailureLimit, cfg.WSGateway.AuthFailureWindow)
+	trustedProxies, err := wsgateway.ParseTrustedProxies(cfg.WSGateway.TrustedProxies)
+	if err != nil {
+		logger.Fatal("Invalid trusted proxies",
+			logger.ErrorField(err),
+		)
+	}
 
 	// Initialize hub
 	hub := wsgateway.NewHub(cfg.WSGateway, redisClient, cfg.WSGateway.AlertStream, cfg.WSGateway.ConsumerGroup)
+	if cfg.WSGateway.UserPreferencesTTL > 0 {
+		hub.SetUserPreferences(storage.NewUserPreferencesStore(redisClient), cfg.WSGateway.UserPreferencesTTL)
+	}
+	hub.SetAlertRedactor(models.NewAlertRedactor(cfg.WSGateway.AlertRedactFields))
+
+	// The symbol universe set through the API overrides the configured one and follows
+	// runtime changes, so newly added symbols can be subscribed
+	universeStore := storage.NewSymbolUniverseStore(redisClient)
+	symbolUniverse := cfg.WSGateway.SymbolUniverse
+	universeCtx, universeCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if stored, err := universeStore.GetUniverse(universeCtx); err != nil {
+		logger.Warn("Failed to load symbol universe (using configured symbols)",
+			logger.ErrorField(err),
+		)
+	} else if len(stored) > 0 {
+		symbolUniverse = stored
+		hub.SetSymbolUniverse(stored)
+	}
+	universeCancel()
+
+	watchCtx, watchCancel := context.WithCancel(context.Background())
+	defer watchCancel()
+	go universeStore.Follow(watchCtx, symbolUniverse, storage.DefaultUniverseReconcileInterval, func(symbols []string) {
+		hub.SetSymbolUniverse(symbols)
+		logger.Info("Applied symbol universe",
+			logger.Int("universe_size", len(symbols)),
+		)
+	})
 
 	// Start hub
 	if err := hub.Start(); err != nil {
@@ -79,7 +116,7 @@ func main() {
 
 	// WebSocket endpoint
 	router.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
-		handleWebSocket(hub, authManager, w, r, cfg.WSGateway)
+		handleWebSocket(hub, authManager, authLimiter, trustedProxies, w, r, cfg.WSGateway)
 	})
 
 	// Health check endpoints
@@ -138,9 +175,13 @@ func main() {
 	<-sigChan
 	logger.Info("Shutting down WebSocket gateway service")
 
-	// Shutdown HTTP server
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second+cfg.WSGateway.ShutdownGracePeriod)
 	defer cancel()
+
+	// Notify clients and drain connections before the HTTP server stops
+	hub.Shutdown(ctx)
+
+	// Shutdown HTTP server
 	if err := server.Shutdown(ctx); err != nil {
 		logger.Error("Error shutting down HTTP server",
 			logger.ErrorField(err),
@@ -151,7 +192,13 @@ func main() {
 }
 
 // handleWebSocket handles WebSocket connections
-func handleWebSocket(hub *wsgateway.Hub, authManager *wsgateway.AuthManager, w http.ResponseWriter, r *http.Request, config config.WSGatewayConfig) {
+func handleWebSocket(hub *wsgateway.Hub, authManager *wsgateway.AuthManager, authLimiter *wsgateway.AuthFailureLimiter, trustedProxies *wsgateway.TrustedProxies, w http.ResponseWriter, r *http.Request, config config.WSGatewayConfig) {
+	// Refuse new connections while draining for shutdown
+	if hub.Draining() {
+		http.Error(w, "Server shutting down", http.StatusServiceUnavailable)
+		return
+	}
+
 	// Check max connections
 	stats := hub.GetStats()
 	if int(stats.ConnectionsActive) >= config.MaxConnections {
@@ -174,6 +221,7 @@ func handleWebSocket(hub *wsgateway.Hub, authManager *wsgateway.AuthManager, w h
 	}
 
 	var userID string
+	alertScope := config.DefaultAlertScope
 	tokenString, err := authManager.ExtractTokenFromHeader(authHeader)
 	if err != nil {
 		// MVP: If no token, use default user
@@ -183,15 +231,25 @@ func handleWebSocket(hub *wsgateway.Hub, authManager *wsgateway.AuthManager, w h
 		)
 		userID = "default"
 	} else {
+		remoteIP := trustedProxies.ClientIP(r)
+		if !authLimiter.Allow(remoteIP) {
+			wsgateway.RecordAuthFailure("ws_gateway", remoteIP, wsgateway.AuthFailureRateLimited, nil)
+			http.Error(w, "Too many failed authentication attempts", http.StatusTooManyRequests)
+			return
+		}
+
 		// Validate token
-		userID, err = authManager.ValidateToken(tokenString)
+		claims, err := authManager.ValidateTokenClaims(tokenString)
 		if err != nil {
-			logger.Warn("Invalid token, rejecting connection",
-				logger.ErrorField(err),
-			)
+			authLimiter.RecordFailure(remoteIP)
+			wsgateway.RecordAuthFailure("ws_gateway", remoteIP, wsgateway.ClassifyAuthError(err), err)
 			http.Error(w, "Invalid authentication token", http.StatusUnauthorized)
 			return
 		}
+		userID = claims.UserID
+		if claims.AlertScope != "" {
+			alertScope = claims.AlertScope
+		}
 	}
 
 	// Upgrade connection to WebSocket
@@ -206,6 +264,7 @@ func handleWebSocket(hub *wsgateway.Hub, authManager *wsgateway.AuthManager, w h
 	// Create connection object
 	connectionID := uuid.New().String()
 	wsConn := wsgateway.NewConnection(connectionID, userID, conn)
+	wsConn.SetAlertScope(alertScope)
 
 	// Register connection with hub
 	hub.Register(wsConn)
@@ -0,0 +1,142 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// alertmanagerAlertsPath is the Alertmanager v2 API endpoint for posting alerts
+const alertmanagerAlertsPath = "/api/v2/alerts"
+
+// AlertmanagerConfig configures delivery of alerts to Prometheus Alertmanager
+type AlertmanagerConfig struct {
+	URL     string        // Alertmanager base URL (e.g. "http://alertmanager:9093")
+	Timeout time.Duration // HTTP request timeout (default: 5s)
+}
+
+// AlertmanagerAlert is a single alert in the Alertmanager v2 API payload
+type AlertmanagerAlert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       *time.Time        `json:"endsAt,omitempty"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+// AlertmanagerSink posts filtered alerts to Alertmanager. Labels identify the (rule, symbol)
+// pair, so an exit alert resolves the Alertmanager alert opened by its entry alert.
+type AlertmanagerSink struct {
+	client *http.Client
+	url    string
+}
+
+// NewAlertmanagerSink creates an Alertmanager alert sink
+func NewAlertmanagerSink(config AlertmanagerConfig) *AlertmanagerSink {
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &AlertmanagerSink{
+		client: &http.Client{Timeout: timeout},
+		url:    strings.TrimRight(config.URL, "/") + alertmanagerAlertsPath,
+	}
+}
+
+// Name returns the sink name
+func (s *AlertmanagerSink) Name() string {
+	return "alertmanager"
+}
+
+// Publish posts all alerts to Alertmanager in a single request
+func (s *AlertmanagerSink) Publish(ctx context.Context, alerts []*models.Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	payload := make([]AlertmanagerAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		payload = append(payload, s.toAlertmanagerAlert(alert))
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alertmanager payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create alertmanager request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post alerts to alertmanager: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("alertmanager returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// toAlertmanagerAlert formats an alert for the Alertmanager v2 API
+func (s *AlertmanagerSink) toAlertmanagerAlert(alert *models.Alert) AlertmanagerAlert {
+	alertName := alert.RuleName
+	if alertName == "" {
+		alertName = alert.RuleID
+	}
+
+	labels := map[string]string{
+		"alertname": alertName,
+		"rule_id":   alert.RuleID,
+		"severity":  alertmanagerSeverity(alert),
+		"source":    "stock-scanner",
+	}
+	if alert.Symbol != "" {
+		labels["symbol"] = alert.Symbol
+	}
+
+	annotations := map[string]string{
+		"summary":  alert.Message,
+		"alert_id": alert.ID,
+		"price":    strconv.FormatFloat(alert.Price, 'f', -1, 64),
+	}
+	if alert.Type != "" {
+		annotations["type"] = alert.Type
+	}
+
+	amAlert := AlertmanagerAlert{
+		Labels:      labels,
+		Annotations: annotations,
+		StartsAt:    alert.Timestamp.UTC(),
+	}
+
+	// Exit alerts resolve the alert their entry alert opened
+	if alert.IsExit() {
+		endsAt := alert.Timestamp.UTC()
+		amAlert.EndsAt = &endsAt
+	}
+
+	return amAlert
+}
+
+// alertmanagerSeverity returns the alert's severity label: the severity the scanner classified
+// it with, or "info" for alerts without one (e.g. breadth and test alerts)
+func alertmanagerSeverity(alert *models.Alert) string {
+	if alert.Severity == "" {
+		return models.SeverityInfo
+	}
+	return alert.Severity
+}
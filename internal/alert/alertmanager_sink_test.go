@@ -0,0 +1,136 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// fakeAlertmanager records requests posted to the v2 alerts endpoint
+type fakeAlertmanager struct {
+	mu       sync.Mutex
+	requests [][]map[string]interface{}
+	paths    []string
+	status   int
+}
+
+func (f *fakeAlertmanager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.paths = append(f.paths, r.Method+" "+r.URL.Path+" "+r.Header.Get("Content-Type"))
+	body, _ := io.ReadAll(r.Body)
+	var payload []map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	f.requests = append(f.requests, payload)
+
+	if f.status != 0 {
+		http.Error(w, "unavailable", f.status)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestAlertmanagerSink_PayloadSchema(t *testing.T) {
+	am := &fakeAlertmanager{}
+	server := httptest.NewServer(am)
+	defer server.Close()
+
+	sink := NewAlertmanagerSink(AlertmanagerConfig{URL: server.URL + "/"})
+	ts := time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC)
+
+	alerts := []*models.Alert{
+		{ID: "alert-1", RuleID: "rule-1", RuleName: "Breakout", Symbol: "AAPL", Timestamp: ts, Price: 150.25, Message: "Breakout on AAPL", Severity: models.SeverityCritical},
+		{ID: "alert-2", RuleID: "rule-2", RuleName: "Dip", Symbol: "MSFT", Timestamp: ts, Price: 300, Message: "Dip on MSFT", Severity: models.SeverityWarning, Priority: 10},
+		{ID: "alert-3", RuleID: "rule-1", RuleName: "Breakout", Symbol: "AAPL", Timestamp: ts.Add(time.Minute), Message: "Exit", Type: models.AlertTypeExit},
+	}
+	if err := sink.Publish(context.Background(), alerts); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	if len(am.requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(am.requests))
+	}
+	if am.paths[0] != "POST /api/v2/alerts application/json" {
+		t.Errorf("Unexpected request %q", am.paths[0])
+	}
+
+	payload := am.requests[0]
+	if len(payload) != 3 {
+		t.Fatalf("Expected 3 alerts in payload, got %d", len(payload))
+	}
+
+	first := payload[0]
+	labels := first["labels"].(map[string]interface{})
+	wantLabels := map[string]string{
+		"alertname": "Breakout",
+		"rule_id":   "rule-1",
+		"symbol":    "AAPL",
+		"severity":  models.SeverityCritical,
+		"source":    "stock-scanner",
+	}
+	for name, want := range wantLabels {
+		if labels[name] != want {
+			t.Errorf("labels[%s] = %v, want %s", name, labels[name], want)
+		}
+	}
+	annotations := first["annotations"].(map[string]interface{})
+	if annotations["summary"] != "Breakout on AAPL" || annotations["alert_id"] != "alert-1" || annotations["price"] != "150.25" {
+		t.Errorf("Unexpected annotations %v", annotations)
+	}
+	if first["startsAt"] != "2024-01-02T15:30:00Z" {
+		t.Errorf("startsAt = %v, want 2024-01-02T15:30:00Z", first["startsAt"])
+	}
+	if _, ok := first["endsAt"]; ok {
+		t.Error("Entry alerts should not set endsAt")
+	}
+
+	// Severity follows the alert's severity, not its priority
+	if severity := payload[1]["labels"].(map[string]interface{})["severity"]; severity != models.SeverityWarning {
+		t.Errorf("Expected warning severity, got %v", severity)
+	}
+
+	// The exit alert carries the entry's labels and resolves it
+	exit := payload[2]
+	if exit["labels"].(map[string]interface{})["severity"] != models.SeverityInfo {
+		t.Errorf("Expected info severity for an alert without one, got %v", exit["labels"])
+	}
+	if exit["endsAt"] != "2024-01-02T15:31:00Z" {
+		t.Errorf("endsAt = %v, want 2024-01-02T15:31:00Z", exit["endsAt"])
+	}
+}
+
+func TestAlertmanagerSink_ErrorStatus(t *testing.T) {
+	am := &fakeAlertmanager{status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(am)
+	defer server.Close()
+
+	sink := NewAlertmanagerSink(AlertmanagerConfig{URL: server.URL})
+	alert := &models.Alert{ID: "alert-1", RuleID: "rule-1", Symbol: "AAPL", Timestamp: time.Now()}
+	if err := sink.Publish(context.Background(), []*models.Alert{alert}); err == nil {
+		t.Error("Expected error for non-2xx response")
+	}
+
+	// Empty batches make no request
+	if err := sink.Publish(context.Background(), nil); err != nil {
+		t.Errorf("Publish(nil) error = %v", err)
+	}
+	if len(am.requests) != 1 {
+		t.Errorf("Expected 1 request, got %d", len(am.requests))
+	}
+
+	// Without a rule name the rule ID names the alert
+	if name := sink.toAlertmanagerAlert(alert).Labels["alertname"]; name != "rule-1" {
+		t.Errorf("alertname = %s, want rule-1", name)
+	}
+}
@@ -0,0 +1,152 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// ErrCircuitOpen is returned when a sink's circuit breaker is open and alerts are dropped
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Circuit breaker states
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+// CircuitBreakerConfig configures a sink circuit breaker
+type CircuitBreakerConfig struct {
+	FailureThreshold int           // Consecutive failures that open the breaker (0 = disabled)
+	OpenDuration     time.Duration // Time the breaker stays open before probing the sink again
+}
+
+// CircuitBreakerStats is a snapshot of a sink circuit breaker
+type CircuitBreakerStats struct {
+	Sink                string    `json:"sink"`
+	State               string    `json:"state"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	Trips               int64     `json:"trips"`
+	AlertsDropped       int64     `json:"alerts_dropped"`
+	OpenedAt            time.Time `json:"opened_at,omitempty"`
+}
+
+// CircuitBreakerSink wraps a sink so a persistently failing downstream is short-circuited
+// instead of backing up alert routing. After FailureThreshold consecutive failures the
+// breaker opens and alerts are dropped; after OpenDuration a single publish probes the sink
+// (half-open) and closes the breaker on success or reopens it on failure.
+type CircuitBreakerSink struct {
+	sink   AlertSink
+	config CircuitBreakerConfig
+	now    func() time.Time
+
+	mu                  sync.Mutex
+	state               string
+	consecutiveFailures int
+	openedAt            time.Time
+	trips               int64
+	dropped             int64
+}
+
+// NewCircuitBreakerSink wraps a sink in a circuit breaker
+func NewCircuitBreakerSink(sink AlertSink, config CircuitBreakerConfig) *CircuitBreakerSink {
+	return &CircuitBreakerSink{
+		sink:   sink,
+		config: config,
+		now:    time.Now,
+		state:  CircuitClosed,
+	}
+}
+
+// Name returns the wrapped sink's name
+func (s *CircuitBreakerSink) Name() string {
+	return s.sink.Name()
+}
+
+// Publish delivers alerts to the wrapped sink unless the breaker is open
+func (s *CircuitBreakerSink) Publish(ctx context.Context, alerts []*models.Alert) error {
+	if !s.allow(len(alerts)) {
+		return ErrCircuitOpen
+	}
+
+	err := s.sink.Publish(ctx, alerts)
+	s.record(err)
+	return err
+}
+
+// allow reports whether a publish may reach the sink, moving an expired open breaker to half-open
+func (s *CircuitBreakerSink) allow(alertCount int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch s.state {
+	case CircuitOpen:
+		if s.now().Sub(s.openedAt) >= s.config.OpenDuration {
+			// Let one publish through to probe recovery
+			s.state = CircuitHalfOpen
+			return true
+		}
+	case CircuitHalfOpen:
+		// A probe is already in flight
+	default:
+		return true
+	}
+
+	s.dropped += int64(alertCount)
+	return false
+}
+
+// record updates the breaker with a publish result
+func (s *CircuitBreakerSink) record(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		if s.state != CircuitClosed {
+			logger.Info("Alert sink recovered, circuit breaker closed",
+				logger.String("sink", s.sink.Name()),
+			)
+		}
+		s.state = CircuitClosed
+		s.consecutiveFailures = 0
+		return
+	}
+
+	s.consecutiveFailures++
+	if s.state == CircuitHalfOpen ||
+		(s.config.FailureThreshold > 0 && s.consecutiveFailures >= s.config.FailureThreshold) {
+		if s.state != CircuitOpen {
+			s.trips++
+			logger.Warn("Alert sink failing, circuit breaker opened",
+				logger.String("sink", s.sink.Name()),
+				logger.Int("consecutive_failures", s.consecutiveFailures),
+				logger.Duration("open_duration", s.config.OpenDuration),
+			)
+		}
+		s.state = CircuitOpen
+		s.openedAt = s.now()
+	}
+}
+
+// Stats returns a snapshot of the breaker state
+func (s *CircuitBreakerSink) Stats() CircuitBreakerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := CircuitBreakerStats{
+		Sink:                s.sink.Name(),
+		State:               s.state,
+		ConsecutiveFailures: s.consecutiveFailures,
+		Trips:               s.trips,
+		AlertsDropped:       s.dropped,
+	}
+	if s.state != CircuitClosed {
+		stats.OpenedAt = s.openedAt
+	}
+	return stats
+}
@@ -0,0 +1,163 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+// flakySink fails while err is set and counts publish attempts that reach it
+type flakySink struct {
+	mu        sync.Mutex
+	err       error
+	attempts  int
+	delivered int
+}
+
+func (s *flakySink) Name() string { return "flaky" }
+
+func (s *flakySink) Publish(ctx context.Context, alerts []*models.Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	if s.err != nil {
+		return s.err
+	}
+	s.delivered += len(alerts)
+	return nil
+}
+
+func (s *flakySink) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *flakySink) Attempts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts
+}
+
+func TestCircuitBreakerSink_TripsAndRecovers(t *testing.T) {
+	sink := &flakySink{err: errors.New("webhook returned 503")}
+	breaker := NewCircuitBreakerSink(sink, CircuitBreakerConfig{FailureThreshold: 3, OpenDuration: 30 * time.Second})
+	now := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	breaker.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	alerts := []*models.Alert{{ID: "alert-1", Symbol: "AAPL"}}
+
+	// Failures below the threshold reach the sink and keep the breaker closed
+	for i := 0; i < 2; i++ {
+		if err := breaker.Publish(ctx, alerts); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Publish %d: expected sink error, got %v", i, err)
+		}
+	}
+	if state := breaker.Stats().State; state != CircuitClosed {
+		t.Fatalf("Expected breaker closed below threshold, got %s", state)
+	}
+
+	// Third consecutive failure trips the breaker
+	breaker.Publish(ctx, alerts)
+	stats := breaker.Stats()
+	if stats.State != CircuitOpen || stats.Trips != 1 {
+		t.Fatalf("Expected breaker open after 3 failures with 1 trip, got %+v", stats)
+	}
+
+	// While open, publishes are short-circuited without reaching the sink
+	for i := 0; i < 5; i++ {
+		if err := breaker.Publish(ctx, alerts); !errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected ErrCircuitOpen while open, got %v", err)
+		}
+	}
+	if sink.Attempts() != 3 {
+		t.Errorf("Expected 3 attempts to reach the sink, got %d", sink.Attempts())
+	}
+	if dropped := breaker.Stats().AlertsDropped; dropped != 5 {
+		t.Errorf("Expected 5 dropped alerts, got %d", dropped)
+	}
+
+	// Probe after the open duration fails: breaker reopens
+	now = now.Add(30 * time.Second)
+	if err := breaker.Publish(ctx, alerts); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected half-open probe to reach the failing sink, got %v", err)
+	}
+	if state := breaker.Stats().State; state != CircuitOpen {
+		t.Fatalf("Expected breaker to reopen after failed probe, got %s", state)
+	}
+	if err := breaker.Publish(ctx, alerts); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen after failed probe, got %v", err)
+	}
+
+	// Endpoint heals: the next probe closes the breaker
+	sink.setErr(nil)
+	now = now.Add(30 * time.Second)
+	if err := breaker.Publish(ctx, alerts); err != nil {
+		t.Fatalf("Expected successful probe, got %v", err)
+	}
+	stats = breaker.Stats()
+	if stats.State != CircuitClosed || stats.ConsecutiveFailures != 0 {
+		t.Errorf("Expected breaker closed after recovery, got %+v", stats)
+	}
+	if err := breaker.Publish(ctx, alerts); err != nil {
+		t.Errorf("Expected publishes to flow after recovery, got %v", err)
+	}
+	if sink.delivered != 2 {
+		t.Errorf("Expected 2 alerts delivered after recovery, got %d", sink.delivered)
+	}
+}
+
+func TestCircuitBreakerSink_SuccessResetsFailures(t *testing.T) {
+	sink := &flakySink{err: errors.New("timeout")}
+	breaker := NewCircuitBreakerSink(sink, CircuitBreakerConfig{FailureThreshold: 2, OpenDuration: time.Minute})
+	ctx := context.Background()
+
+	breaker.Publish(ctx, nil)
+	sink.setErr(nil)
+	breaker.Publish(ctx, nil)
+	sink.setErr(errors.New("timeout"))
+	breaker.Publish(ctx, nil)
+
+	if state := breaker.Stats().State; state != CircuitClosed {
+		t.Errorf("Expected non-consecutive failures to keep breaker closed, got %s", state)
+	}
+}
+
+func TestRouter_SinkCircuitBreakerStats(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	router := NewRouter(redis, "alerts.filtered", 5*time.Second)
+	router.SetSinkCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 2, OpenDuration: time.Minute})
+	sink := &flakySink{err: errors.New("webhook down")}
+	router.AddSink(sink)
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		if err := router.RouteAlert(ctx, &models.Alert{ID: "alert-1", RuleID: "rule-1", Symbol: "AAPL", Timestamp: time.Now()}); err != nil {
+			t.Fatalf("RouteAlert() error = %v", err)
+		}
+	}
+
+	if sink.Attempts() != 2 {
+		t.Errorf("Expected sink to be short-circuited after 2 failures, got %d attempts", sink.Attempts())
+	}
+
+	stats := router.SinkStats()
+	if len(stats) != 1 {
+		t.Fatalf("Expected stats for 1 sink, got %d", len(stats))
+	}
+	if stats[0].Sink != "flaky" || stats[0].State != CircuitOpen || stats[0].AlertsDropped != 2 {
+		t.Errorf("Unexpected sink stats: %+v", stats[0])
+	}
+
+	consumer := newTestConsumer(redis, &mockAlertWriter{})
+	consumer.router = router
+	if sinks := consumer.GetStats().Sinks; len(sinks) != 1 || sinks[0].State != CircuitOpen {
+		t.Errorf("Expected consumer stats to expose sink breaker state, got %+v", sinks)
+	}
+}
@@ -13,14 +13,29 @@ import (
 	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
 )
 
+// AlertWriter persists alerts (implemented by AlertPersister)
+type AlertWriter interface {
+	WriteAlerts(ctx context.Context, alerts []*models.Alert) error
+}
+
+// UserPreferencesSource looks up a user's delivery preferences (implemented by storage.UserPreferencesStore)
+type UserPreferencesSource interface {
+	GetPreferences(ctx context.Context, userID string) (*models.UserPreferences, error)
+}
+
 // Consumer consumes alerts from Redis stream and processes them
 type Consumer struct {
 	config        config.AlertConfig
 	redis         storage.RedisClient
 	deduplicator  *Deduplicator
 	filter        *UserFilter
-	persister     *AlertPersister
+	persister     AlertWriter
 	router        *Router
+	localizer     *Localizer
+	lanes         map[string]*DeliveryQueue // Delivery lane -> alerts waiting for its worker
+	preferences   UserPreferencesSource // Applied to alerts targeted at a user (nil = disabled)
+	symbolBudget  *SymbolBudget         // Per-symbol alert cap shared by all rules and users (nil = unlimited)
+	now           func() time.Time
 	ctx           context.Context
 	cancel        context.CancelFunc
 	wg            sync.WaitGroup
@@ -35,9 +50,11 @@ type ConsumerStats struct {
 	AlertsProcessed   int64
 	AlertsDeduplicated int64
 	AlertsFiltered    int64
+	AlertsOverBudget  int64 // Dropped because their symbol's alert budget for the window was spent
 	AlertsRouted      int64
 	AlertsFailed      int64
 	LastAlertTime     time.Time
+	Sinks             []CircuitBreakerStats // Circuit breaker state of alert sinks
 	mu                sync.RWMutex
 }
 
@@ -47,7 +64,7 @@ func NewConsumer(
 	redis storage.RedisClient,
 	deduplicator *Deduplicator,
 	filter *UserFilter,
-	persister *AlertPersister,
+	persister AlertWriter,
 	router *Router,
 ) *Consumer {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -59,12 +76,141 @@ func NewConsumer(
 		filter:       filter,
 		persister:    persister,
 		router:       router,
+		lanes:        newDeliveryLanes(),
+		now:          time.Now,
 		ctx:          ctx,
 		cancel:       cancel,
 		stats:        ConsumerStats{},
 	}
 }
 
+// SetLocalizer sets the localizer used to render alert messages per user locale
+func (c *Consumer) SetLocalizer(localizer *Localizer) {
+	c.localizer = localizer
+}
+
+// SetUserPreferences sets the source of user preferences applied to the alerts of user rules:
+// quiet hours and snoozes suppress the alert, default channels and locale shape its delivery
+func (c *Consumer) SetUserPreferences(preferences UserPreferencesSource) {
+	c.preferences = preferences
+}
+
+// SetSymbolBudget sets the per-symbol alert budget shared by all rules and users (and by all
+// replicas when the budget counts in Redis)
+func (c *Consumer) SetSymbolBudget(budget *SymbolBudget) {
+	c.symbolBudget = budget
+}
+
+// userPreferences looks up the preferences of the alert's target user: the owner of the user
+// rule that matched, or the user who requested a test alert. Returns nil for system rule
+// alerts; lookup failures are logged and the alert is delivered as is.
+func (c *Consumer) userPreferences(alert *models.Alert) *models.UserPreferences {
+	userID := alert.TargetUserID()
+	if c.preferences == nil || userID == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.ProcessTimeout)
+	defer cancel()
+
+	prefs, err := c.preferences.GetPreferences(ctx, userID)
+	if err != nil {
+		logger.Warn("Failed to get user preferences",
+			logger.ErrorField(err),
+			logger.String("alert_id", alert.ID),
+			logger.String("user_id", userID),
+		)
+		return nil
+	}
+	return prefs
+}
+
+// applyUserPreferences applies the target user's preferences to an alert.
+// Returns false if the user's quiet hours or snoozes suppress the alert.
+func (c *Consumer) applyUserPreferences(alert *models.Alert, prefs *models.UserPreferences) bool {
+	if prefs == nil {
+		return true
+	}
+	userID := alert.TargetUserID()
+
+	if prefs.SuppressesAlert(alert, c.now()) {
+		logger.Debug("Alert suppressed by user preferences",
+			logger.String("alert_id", alert.ID),
+			logger.String("user_id", userID),
+		)
+		return false
+	}
+
+	// The rule's delivery policy takes precedence over the user's default channels
+	if alert.Delivery == nil {
+		alert.Delivery = prefs.DefaultDelivery()
+	}
+	return true
+}
+
+// deliveryPriority combines the alert's rule priority with its target user's preference
+func deliveryPriority(alert *models.Alert, prefs *models.UserPreferences) int {
+	priority := alert.Priority
+	if prefs != nil {
+		priority += prefs.Priority
+	}
+	return priority
+}
+
+// newDeliveryLanes creates an empty queue for each delivery lane
+func newDeliveryLanes() map[string]*DeliveryQueue {
+	lanes := make(map[string]*DeliveryQueue, len(deliveryLanes))
+	for _, lane := range deliveryLanes {
+		lanes[lane] = NewDeliveryQueue(lane)
+	}
+	return lanes
+}
+
+// startLanes starts one delivery worker per lane
+func (c *Consumer) startLanes() {
+	for _, lane := range deliveryLanes {
+		c.wg.Add(1)
+		go c.runLane(c.lanes[lane])
+	}
+}
+
+// closeLanes lets the lane workers exit once the alerts already queued are delivered
+func (c *Consumer) closeLanes() {
+	for _, queue := range c.lanes {
+		queue.Close()
+	}
+}
+
+// runLane delivers the alerts of one lane until the lane is closed and empty
+func (c *Consumer) runLane(queue *DeliveryQueue) {
+	defer c.wg.Done()
+
+	for {
+		item, ok := queue.pop()
+		if !ok {
+			return
+		}
+
+		shouldAck, err := c.processAlertWithPreferences(item.alert, item.prefs)
+		if err != nil {
+			// Left unacknowledged, so the consumer group redelivers it
+			logger.Error("Failed to process alert",
+				logger.ErrorField(err),
+				logger.String("alert_id", item.alert.ID),
+				logger.String("message_id", item.messageID),
+			)
+			c.incrementFailed()
+			continue
+		}
+
+		if shouldAck {
+			c.incrementProcessed()
+		}
+		// Filtered and duplicated alerts are acknowledged too
+		c.acknowledgeMessages([]string{item.messageID})
+	}
+}
+
 // Start starts consuming alerts from the stream
 func (c *Consumer) Start() error {
 	c.mu.Lock()
@@ -95,6 +241,7 @@ func (c *Consumer) Start() error {
 		return fmt.Errorf("failed to start consuming from stream: %w", err)
 	}
 
+	c.startLanes()
 	c.wg.Add(1)
 	go c.processMessages(messageChan)
 
@@ -103,23 +250,44 @@ func (c *Consumer) Start() error {
 
 // Stop stops the consumer
 func (c *Consumer) Stop() {
+	_ = c.Shutdown(context.Background())
+}
+
+// Shutdown stops consuming and waits for the alerts already queued in the delivery lanes to
+// finish routing and being handed to the persister. Returns ctx's error if the batch doesn't finish in
+// time; its unacknowledged messages are redelivered by the consumer group.
+func (c *Consumer) Shutdown(ctx context.Context) error {
 	c.mu.Lock()
 	if !c.running {
 		c.mu.Unlock()
-		return
+		return nil
 	}
 	c.running = false
 	c.mu.Unlock()
 
 	logger.Info("Stopping alert consumer")
 	c.cancel()
-	c.wg.Wait()
-	logger.Info("Alert consumer stopped")
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Info("Alert consumer stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("alert consumer drain timed out: %w", ctx.Err())
+	}
 }
 
-// processMessages processes messages from the stream
+// processMessages queues messages from the stream in the delivery lanes, closing the lanes
+// when it stops
 func (c *Consumer) processMessages(messageChan <-chan storage.StreamMessage) {
 	defer c.wg.Done()
+	defer c.closeLanes()
 
 	batch := make([]storage.StreamMessage, 0, c.config.BatchSize)
 	ticker := time.NewTicker(c.config.ProcessTimeout)
@@ -128,7 +296,8 @@ func (c *Consumer) processMessages(messageChan <-chan storage.StreamMessage) {
 	for {
 		select {
 		case <-c.ctx.Done():
-			// Process remaining batch before exiting
+			// Process remaining batch, including messages already delivered to us, before exiting
+			batch = c.drainDelivered(messageChan, batch)
 			if len(batch) > 0 {
 				c.processBatch(batch)
 			}
@@ -163,15 +332,25 @@ func (c *Consumer) processMessages(messageChan <-chan storage.StreamMessage) {
 	}
 }
 
-// processBatch processes a batch of messages
-func (c *Consumer) processBatch(messages []storage.StreamMessage) {
-	if len(messages) == 0 {
-		return
+// drainDelivered appends messages already buffered in messageChan to batch without blocking
+func (c *Consumer) drainDelivered(messageChan <-chan storage.StreamMessage, batch []storage.StreamMessage) []storage.StreamMessage {
+	for {
+		select {
+		case msg, ok := <-messageChan:
+			if !ok {
+				return batch
+			}
+			batch = append(batch, msg)
+			c.incrementReceived()
+		default:
+			return batch
+		}
 	}
+}
 
-	processed := make([]string, 0, len(messages)) // Message IDs to acknowledge
-	failed := make([]string, 0)                  // Message IDs that failed
-
+// processBatch queues a batch of messages in the delivery lanes by priority and severity
+func (c *Consumer) processBatch(messages []storage.StreamMessage) {
+	failed := 0
 	for _, msg := range messages {
 		// Deserialize alert
 		alert, err := c.deserializeAlert(msg)
@@ -180,42 +359,27 @@ func (c *Consumer) processBatch(messages []storage.StreamMessage) {
 				logger.ErrorField(err),
 				logger.String("message_id", msg.ID),
 			)
-			failed = append(failed, msg.ID)
+			failed++
 			c.incrementFailed()
 			continue
 		}
 
-		// Process alert
-		shouldAck, err := c.processAlert(alert)
-		if err != nil {
-			logger.Error("Failed to process alert",
-				logger.ErrorField(err),
-				logger.String("alert_id", alert.ID),
-				logger.String("message_id", msg.ID),
-			)
-			failed = append(failed, msg.ID)
-			c.incrementFailed()
-			continue
-		}
-
-		if shouldAck {
-			processed = append(processed, msg.ID)
-			c.incrementProcessed()
-		} else {
-			// Alert was filtered/duplicated, still acknowledge
-			processed = append(processed, msg.ID)
-		}
-	}
-
-	// Acknowledge successfully processed messages
-	if len(processed) > 0 {
-		c.acknowledgeMessages(processed)
+		// The user's preferences are looked up once: they set the priority and are applied
+		// when the alert is delivered
+		prefs := c.userPreferences(alert)
+		priority := deliveryPriority(alert, prefs)
+		c.lanes[laneFor(priority, alert.Severity)].push(&deliveryItem{
+			alert:     alert,
+			messageID: msg.ID,
+			priority:  priority,
+			prefs:     prefs,
+		})
 	}
 
 	// Log failed messages (they will be retried by consumer group)
-	if len(failed) > 0 {
+	if failed > 0 {
 		logger.Warn("Some alerts failed to process",
-			logger.Int("failed_count", len(failed)),
+			logger.Int("failed_count", failed),
 		)
 	}
 }
@@ -223,6 +387,12 @@ func (c *Consumer) processBatch(messages []storage.StreamMessage) {
 // processAlert processes a single alert through the pipeline
 // Returns true if alert should be acknowledged, false if it was filtered/duplicated
 func (c *Consumer) processAlert(alert *models.Alert) (bool, error) {
+	return c.processAlertWithPreferences(alert, c.userPreferences(alert))
+}
+
+// processAlertWithPreferences processes an alert whose target user's preferences were
+// already looked up (nil = none)
+func (c *Consumer) processAlertWithPreferences(alert *models.Alert, prefs *models.UserPreferences) (bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), c.config.ProcessTimeout)
 	defer cancel()
 
@@ -236,27 +406,63 @@ func (c *Consumer) processAlert(alert *models.Alert) (bool, error) {
 		return true, nil // Acknowledge but don't process further
 	}
 
-	// Step 2: User filtering
+	// Step 2: User filtering and target user preferences (quiet hours, snoozes)
 	passFilter, err := c.filter.FilterAlert(ctx, alert)
 	if err != nil {
 		return false, fmt.Errorf("filtering failed: %w", err)
 	}
-	if !passFilter {
+	if !passFilter || !c.applyUserPreferences(alert, prefs) {
 		c.incrementFiltered()
 		return true, nil // Acknowledge but don't process further
 	}
 
+	// Step 2b: Per-symbol budget, after filtering so only deliverable alerts spend it.
+	// Test alerts never spend a symbol's budget, and exit alerts neither spend it nor are
+	// dropped, so an alert delivered to a user is always followed by its exit.
+	if !alert.IsTest() && !alert.IsExit() && !c.symbolBudget.Allow(ctx, alert.Symbol, c.now()) {
+		logger.Debug("Alert dropped, symbol alert budget exceeded",
+			logger.String("alert_id", alert.ID),
+			logger.String("rule_id", alert.RuleID),
+			logger.String("symbol", alert.Symbol),
+		)
+		alertsDropped.WithLabelValues(DropReasonSymbolBudget).Inc()
+		c.incrementOverBudget()
+		return true, nil // Acknowledge but don't process further
+	}
+
 	// Step 3: Persist alert (async, non-blocking)
-	err = c.persister.WriteAlerts(ctx, []*models.Alert{alert})
-	if err != nil {
-		logger.Warn("Failed to persist alert",
-			logger.ErrorField(err),
+	// Test alerts exercise delivery only and are never stored as production data
+	if alert.IsTest() {
+		logger.Debug("Skipping persistence for test alert",
 			logger.String("alert_id", alert.ID),
 		)
-		// Don't fail the operation, continue to routing
+	} else {
+		err = c.persister.WriteAlerts(ctx, []*models.Alert{alert})
+		if err != nil {
+			logger.Warn("Failed to persist alert",
+				logger.ErrorField(err),
+				logger.String("alert_id", alert.ID),
+			)
+			// Don't fail the operation, continue to routing
+		}
 	}
 
-	// Step 4: Route to filtered stream
+	// Step 4: Render localized messages for delivery channels
+	if c.localizer != nil {
+		var locale string
+		if prefs != nil {
+			locale = prefs.Locale
+		}
+		if err := c.localizer.Localize(alert, locale); err != nil {
+			logger.Warn("Failed to localize alert",
+				logger.ErrorField(err),
+				logger.String("alert_id", alert.ID),
+			)
+			// Deliver with the original message
+		}
+	}
+
+	// Step 5: Route to filtered stream
 	err = c.router.RouteAlert(ctx, alert)
 	if err != nil {
 		return false, fmt.Errorf("routing failed: %w", err)
@@ -305,15 +511,22 @@ func (c *Consumer) acknowledgeMessages(messageIDs []string) {
 
 // GetStats returns current consumer statistics
 func (c *Consumer) GetStats() ConsumerStats {
+	var sinks []CircuitBreakerStats
+	if c.router != nil {
+		sinks = c.router.SinkStats()
+	}
+
 	c.stats.mu.RLock()
 	defer c.stats.mu.RUnlock()
 
 	// Return a copy
 	return ConsumerStats{
+		Sinks:             sinks,
 		AlertsReceived:    c.stats.AlertsReceived,
 		AlertsProcessed:   c.stats.AlertsProcessed,
 		AlertsDeduplicated: c.stats.AlertsDeduplicated,
 		AlertsFiltered:    c.stats.AlertsFiltered,
+		AlertsOverBudget:  c.stats.AlertsOverBudget,
 		AlertsRouted:      c.stats.AlertsRouted,
 		AlertsFailed:      c.stats.AlertsFailed,
 		LastAlertTime:     c.stats.LastAlertTime,
@@ -346,6 +559,11 @@ func (c *Consumer) incrementFiltered() {
 	c.stats.AlertsFiltered++
 }
 
+func (c *Consumer) incrementOverBudget() {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	c.stats.AlertsOverBudget++
+}
 
 func (c *Consumer) incrementRouted() {
 	c.stats.mu.Lock()
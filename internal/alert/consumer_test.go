@@ -0,0 +1,259 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+	"github.com/mohamedkhairy/stock-scanner/internal/scanner"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+// mockAlertWriter records persisted alerts for testing
+type mockAlertWriter struct {
+	alerts []*models.Alert
+}
+
+func (m *mockAlertWriter) WriteAlerts(ctx context.Context, alerts []*models.Alert) error {
+	m.alerts = append(m.alerts, alerts...)
+	return nil
+}
+
+func newTestConsumer(redis *storage.MockRedisClient, writer AlertWriter) *Consumer {
+	cfg := config.AlertConfig{
+		StreamName:         "alerts",
+		ConsumerGroup:      "alert-service",
+		FilteredStreamName: "alerts.filtered",
+		ProcessTimeout:     5 * time.Second,
+		BatchSize:          10,
+	}
+	return NewConsumer(
+		cfg,
+		redis,
+		NewDeduplicator(redis, time.Hour),
+		NewUserFilter(),
+		writer,
+		NewRouter(redis, cfg.FilteredStreamName, 5*time.Second),
+	)
+}
+
+// scanRuleAlert runs one scanner cycle of a rule against AAPL trading at 150 and returns the
+// alert the scanner published, decoded from the alert stream as the alert service reads it
+func scanRuleAlert(t *testing.T, rule *models.Rule) *models.Alert {
+	t.Helper()
+
+	redis := storage.NewMockRedisClient()
+	sm := scanner.NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	if err := ruleStore.AddRule(rule); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+	emitter := scanner.NewAlertEmitter(redis, scanner.AlertEmitterConfig{StreamName: "alerts", PublishTimeout: time.Second})
+	sl := scanner.NewScanLoop(scanner.DefaultScanLoopConfig(), sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	tick := &models.Tick{Symbol: "AAPL", Price: 150.0, Size: 100, Timestamp: time.Now(), Type: "trade"}
+	if err := sm.UpdateLiveBar("AAPL", tick); err != nil {
+		t.Fatalf("Failed to update live bar: %v", err)
+	}
+	sl.Scan()
+
+	if len(redis.StreamData) != 1 {
+		t.Fatalf("Expected the scanner to publish 1 alert, got %d", len(redis.StreamData))
+	}
+	var alert models.Alert
+	if err := json.Unmarshal([]byte(redis.StreamData[0].Values["alert"].(string)), &alert); err != nil {
+		t.Fatalf("Failed to unmarshal published alert: %v", err)
+	}
+	return &alert
+}
+
+func TestConsumer_ProcessAlert_Persists(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	writer := &mockAlertWriter{}
+	consumer := newTestConsumer(redis, writer)
+
+	alert := &models.Alert{
+		ID:        "alert-1",
+		RuleID:    "rule-1",
+		Symbol:    "AAPL",
+		Timestamp: time.Now(),
+		Price:     150.0,
+	}
+
+	if _, err := consumer.processAlert(alert); err != nil {
+		t.Fatalf("Failed to process alert: %v", err)
+	}
+
+	if len(writer.alerts) != 1 {
+		t.Errorf("Expected 1 persisted alert, got %d", len(writer.alerts))
+	}
+}
+
+func TestConsumer_ProcessAlert_TestAlertNotPersisted(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	writer := &mockAlertWriter{}
+	consumer := newTestConsumer(redis, writer)
+
+	alert := &models.Alert{
+		ID:        "test-1",
+		RuleID:    "test",
+		RuleName:  "Test Alert",
+		Symbol:    "TEST",
+		Timestamp: time.Now(),
+		Metadata: map[string]interface{}{
+			models.AlertMetadataTest:   true,
+			models.AlertMetadataUserID: "user-1",
+		},
+	}
+
+	if _, err := consumer.processAlert(alert); err != nil {
+		t.Fatalf("Failed to process test alert: %v", err)
+	}
+
+	// Test alert must not be stored as production data
+	if len(writer.alerts) != 0 {
+		t.Errorf("Expected test alert not to be persisted, got %d persisted", len(writer.alerts))
+	}
+
+	// Test alert must still reach the delivery channel
+	var routed *models.Alert
+	for _, msg := range redis.StreamData {
+		if msg.Stream != "alerts.filtered" {
+			continue
+		}
+		var a models.Alert
+		if err := json.Unmarshal([]byte(msg.Values["alert"].(string)), &a); err != nil {
+			t.Fatalf("Failed to unmarshal routed alert: %v", err)
+		}
+		routed = &a
+	}
+	if routed == nil {
+		t.Fatal("Expected test alert to be routed to filtered stream")
+	}
+	if !routed.IsTest() || routed.TargetUserID() != "user-1" {
+		t.Errorf("Expected routed alert to keep test metadata, got %v", routed.Metadata)
+	}
+
+	stats := consumer.GetStats()
+	if stats.AlertsRouted != 1 {
+		t.Errorf("Expected 1 routed alert, got %d", stats.AlertsRouted)
+	}
+}
+
+// userRule returns a rule owned by userID that matches AAPL trading at 150
+func userRule(id, userID string) *models.Rule {
+	return &models.Rule{
+		ID:         id,
+		UserID:     userID,
+		Name:       "Breakout",
+		Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+		Enabled:    true,
+	}
+}
+
+// consumeAlerts drives alerts through the consumer as read from the alert stream: they are
+// queued in the delivery lanes and delivered by the lane workers
+func consumeAlerts(t *testing.T, consumer *Consumer, alerts ...*models.Alert) {
+	t.Helper()
+
+	messages := make([]storage.StreamMessage, 0, len(alerts))
+	for _, alert := range alerts {
+		messages = append(messages, alertMessage(t, alert))
+	}
+	consumer.lanes = newDeliveryLanes()
+	consumer.processBatch(messages)
+	consumer.startLanes()
+	consumer.closeLanes()
+	consumer.wg.Wait()
+}
+
+func TestConsumer_UserPreferences_SuppressAlerts(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	writer := &mockAlertWriter{}
+	consumer := newTestConsumer(redis, writer)
+	prefsStore := storage.NewUserPreferencesStore(redis)
+	consumer.SetUserPreferences(prefsStore)
+
+	now := time.Date(2024, 3, 15, 14, 0, 0, 0, time.UTC)
+	consumer.now = func() time.Time { return now }
+
+	if err := prefsStore.SetPreferences(context.Background(), &models.UserPreferences{
+		UserID:  "user-1",
+		Snoozes: []models.AlertSnooze{{Symbol: "AAPL", Until: time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)}},
+	}); err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+
+	// The snoozing user's rule alert is suppressed; other users' and system rules' alerts are delivered
+	consumeAlerts(t, consumer,
+		scanRuleAlert(t, userRule("rule-1", "user-1")),
+		scanRuleAlert(t, userRule("rule-2", "user-2")),
+		scanRuleAlert(t, userRule("rule-3", "")),
+	)
+
+	stats := consumer.GetStats()
+	if stats.AlertsFiltered != 1 || stats.AlertsRouted != 2 {
+		t.Errorf("Expected 1 filtered and 2 routed alerts, got filtered=%d routed=%d", stats.AlertsFiltered, stats.AlertsRouted)
+	}
+
+	// Changing preferences changes delivery: quiet hours now cover every alert of the user's rules
+	if err := prefsStore.SetPreferences(context.Background(), &models.UserPreferences{
+		UserID:     "user-2",
+		QuietHours: &models.QuietHours{Start: "13:00", End: "15:00"},
+	}); err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+	consumeAlerts(t, consumer, scanRuleAlert(t, userRule("rule-4", "user-2")))
+	if stats := consumer.GetStats(); stats.AlertsFiltered != 2 {
+		t.Errorf("Expected alert in quiet hours to be filtered, got filtered=%d", stats.AlertsFiltered)
+	}
+}
+
+func TestConsumer_UserPreferences_DefaultChannelsAndLocale(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	consumer := newTestConsumer(redis, &mockAlertWriter{})
+	kafka := &channelSink{name: "kafka"}
+	consumer.router.AddSink(kafka)
+	consumer.SetLocalizer(NewLocalizer("en-US"))
+	prefsStore := storage.NewUserPreferencesStore(redis)
+	consumer.SetUserPreferences(prefsStore)
+
+	if err := prefsStore.SetPreferences(context.Background(), &models.UserPreferences{
+		UserID:          "user-1",
+		DefaultChannels: []string{"kafka"},
+		Locale:          "de-DE",
+	}); err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+
+	consumeAlerts(t, consumer, scanRuleAlert(t, userRule("rule-1", "user-1")))
+
+	// Delivered on the rule owner's primary channel only
+	if kafka.count() != 1 {
+		t.Fatalf("Expected alert delivered to the kafka channel, got %d", kafka.count())
+	}
+	if len(redis.StreamData) != 0 {
+		t.Errorf("Expected websocket channel to be skipped, got %d stream messages", len(redis.StreamData))
+	}
+	if delivered := kafka.delivered[0]; delivered.Message != delivered.Messages["de-DE"] {
+		t.Errorf("Expected message rendered in the rule owner's locale, got %q", delivered.Message)
+	}
+	if locale := consumer.localizer.LocaleFor("user-1"); locale != DefaultLocale {
+		t.Errorf("Expected the preferred locale to apply per alert only, got %s configured", locale)
+	}
+
+	// A rule's own delivery policy takes precedence over the user's default channels
+	rule := userRule("rule-2", "user-1")
+	rule.Delivery = &models.DeliveryPolicy{Mode: models.DeliveryModeAll}
+	consumeAlerts(t, consumer, scanRuleAlert(t, rule))
+	if len(redis.StreamData) != 1 {
+		t.Errorf("Expected rule delivery policy to broadcast to the websocket channel, got %d stream messages", len(redis.StreamData))
+	}
+}
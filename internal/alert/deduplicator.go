@@ -3,6 +3,8 @@ package alert
 import (
 	"context"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
@@ -32,9 +34,64 @@ func GenerateIdempotencyKey(alert *models.Alert) string {
 	return fmt.Sprintf("%s:%s:%d", alert.RuleID, alert.Symbol, roundedTime.Unix())
 }
 
-// IsDuplicate checks if an alert is a duplicate based on its idempotency key
+// GenerateDedupKey generates a deduplication key from the configured fields, in order.
+// A nil config uses GenerateIdempotencyKey. A bucketed metric missing from the alert's
+// metrics is keyed as "na" so the alert is still deduplicated on the other fields.
+func GenerateDedupKey(alert *models.Alert, config *models.DedupKey) string {
+	if config == nil {
+		return GenerateIdempotencyKey(alert)
+	}
+
+	parts := make([]string, 0, len(config.Fields))
+	for _, field := range config.Fields {
+		switch field {
+		case models.DedupFieldRule:
+			parts = append(parts, alert.RuleID)
+		case models.DedupFieldSymbol:
+			parts = append(parts, alert.Symbol)
+		case models.DedupFieldTimestamp:
+			parts = append(parts, fmt.Sprintf("%d", alert.Timestamp.Truncate(time.Second).Unix()))
+		case models.DedupFieldBar:
+			parts = append(parts, fmt.Sprintf("bar=%d", alert.Timestamp.Truncate(time.Minute).Unix()))
+		case models.DedupFieldMetric:
+			bucket := "na"
+			if value, ok := alertMetricValue(alert, config.Metric); ok {
+				bucket = fmt.Sprintf("%d", int64(math.Floor(value/config.BucketSize)))
+			}
+			parts = append(parts, fmt.Sprintf("%s=%s", config.Metric, bucket))
+		}
+	}
+	return strings.Join(parts, ":")
+}
+
+// alertMetricValue returns a metric from the alert's metrics metadata
+func alertMetricValue(alert *models.Alert, metric string) (float64, bool) {
+	switch metrics := alert.Metadata["metrics"].(type) {
+	case map[string]float64:
+		value, ok := metrics[metric]
+		return value, ok
+	case map[string]interface{}:
+		// Decoded from JSON
+		value, ok := metrics[metric].(float64)
+		return value, ok
+	}
+	return 0, false
+}
+
+// IsDuplicate checks if an alert is a duplicate based on its idempotency key,
+// composed per the rule's dedup key configuration carried in the alert metadata
 func (d *Deduplicator) IsDuplicate(ctx context.Context, alert *models.Alert) (bool, error) {
-	key := GenerateIdempotencyKey(alert)
+	config, err := alert.DedupKeyConfig()
+	if err != nil {
+		logger.Warn("Invalid dedup key configuration, using default key",
+			logger.ErrorField(err),
+			logger.String("alert_id", alert.ID),
+			logger.String("rule_id", alert.RuleID),
+		)
+		config = nil
+	}
+
+	key := GenerateDedupKey(alert, config)
 	redisKey := fmt.Sprintf("alert:dedupe:%s", key)
 
 	// Check if key exists
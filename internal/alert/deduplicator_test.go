@@ -2,6 +2,7 @@ package alert
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -124,3 +125,107 @@ func TestDeduplicator_DifferentSymbols(t *testing.T) {
 	}
 }
 
+
+func TestDeduplicator_KeyStrategies(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 5, 0, time.UTC)
+
+	// Same rule and symbol: two alerts within one bar, then one in the next bar
+	newSequence := func() []*models.Alert {
+		return []*models.Alert{
+			{ID: "alert-1", RuleID: "rule-1", Symbol: "AAPL", Timestamp: start,
+				Metadata: map[string]interface{}{"metrics": map[string]float64{"rsi_14": 25.0}}},
+			{ID: "alert-2", RuleID: "rule-1", Symbol: "AAPL", Timestamp: start.Add(10 * time.Second),
+				Metadata: map[string]interface{}{"metrics": map[string]float64{"rsi_14": 38.0}}},
+			{ID: "alert-3", RuleID: "rule-1", Symbol: "AAPL", Timestamp: start.Add(70 * time.Second),
+				Metadata: map[string]interface{}{"metrics": map[string]float64{"rsi_14": 21.0}}},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		key        *models.DedupKey
+		duplicates []bool
+	}{
+		{
+			name:       "default key",
+			key:        nil,
+			duplicates: []bool{false, false, false},
+		},
+		{
+			name:       "per bar",
+			key:        &models.DedupKey{Fields: []string{models.DedupFieldRule, models.DedupFieldSymbol, models.DedupFieldBar}},
+			duplicates: []bool{false, true, false},
+		},
+		{
+			name: "metric bucket",
+			key: &models.DedupKey{
+				Fields:     []string{models.DedupFieldRule, models.DedupFieldSymbol, models.DedupFieldMetric},
+				Metric:     "rsi_14",
+				BucketSize: 10,
+			},
+			duplicates: []bool{false, false, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deduplicator := NewDeduplicator(storage.NewMockRedisClient(), time.Hour)
+
+			for i, alert := range newSequence() {
+				if tt.key != nil {
+					alert.Metadata[models.AlertMetadataDedupKey] = tt.key
+				}
+
+				isDuplicate, err := deduplicator.IsDuplicate(context.Background(), alert)
+				if err != nil {
+					t.Fatalf("IsDuplicate() error = %v", err)
+				}
+				if isDuplicate != tt.duplicates[i] {
+					t.Errorf("Alert %d: expected duplicate=%v, got %v", i+1, tt.duplicates[i], isDuplicate)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateDedupKey_FromStreamMetadata(t *testing.T) {
+	// Alerts read from the stream carry metadata decoded from JSON
+	data, err := json.Marshal(&models.Alert{
+		ID:        "alert-1",
+		RuleID:    "rule-1",
+		Symbol:    "AAPL",
+		Timestamp: time.Date(2024, 1, 1, 12, 0, 5, 0, time.UTC),
+		Metadata: map[string]interface{}{
+			"metrics": map[string]float64{"rsi_14": 25.0},
+			models.AlertMetadataDedupKey: &models.DedupKey{
+				Fields:     []string{models.DedupFieldSymbol, models.DedupFieldBar, models.DedupFieldMetric},
+				Metric:     "rsi_14",
+				BucketSize: 10,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal alert: %v", err)
+	}
+
+	var alert models.Alert
+	if err := json.Unmarshal(data, &alert); err != nil {
+		t.Fatalf("Failed to unmarshal alert: %v", err)
+	}
+
+	config, err := alert.DedupKeyConfig()
+	if err != nil {
+		t.Fatalf("DedupKeyConfig() error = %v", err)
+	}
+
+	want := "AAPL:bar=1704110400:rsi_14=2"
+	if key := GenerateDedupKey(&alert, config); key != want {
+		t.Errorf("GenerateDedupKey() = %s, want %s", key, want)
+	}
+
+	// A bucketed metric missing from the alert is keyed as "na"
+	delete(alert.Metadata, "metrics")
+	if key := GenerateDedupKey(&alert, config); key != "AAPL:bar=1704110400:rsi_14=na" {
+		t.Errorf("Expected missing metric keyed as na, got %s", key)
+	}
+}
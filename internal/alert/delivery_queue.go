@@ -0,0 +1,160 @@
+package alert
+
+import (
+	"container/heap"
+	"sync"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Delivery lanes, from most to least urgent. Each lane is delivered by its own worker, so
+// alerts backed up in one lane never delay the alerts of another.
+const (
+	LaneHigh   = "high"
+	LaneNormal = "normal"
+	LaneLow    = "low"
+)
+
+// deliveryLanes lists the lanes in order of urgency
+var deliveryLanes = []string{LaneHigh, LaneNormal, LaneLow}
+
+// deliveryQueueDepth reports the alerts waiting in each delivery lane
+var deliveryQueueDepth = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "alert_delivery_queue_depth",
+		Help: "Number of alerts waiting for delivery, by lane",
+	},
+	[]string{"lane"},
+)
+
+// laneFor picks the lane of an alert from its delivery priority and severity: critical alerts
+// and alerts with a positive priority are high, info alerts with a negative priority are low,
+// and everything else is normal
+func laneFor(priority int, severity string) string {
+	rank := models.SeverityRank(severity)
+	switch {
+	case rank >= models.SeverityRank(models.SeverityCritical) || priority > 0:
+		return LaneHigh
+	case rank == models.SeverityRank(models.SeverityInfo) && priority < 0:
+		return LaneLow
+	default:
+		return LaneNormal
+	}
+}
+
+// deliveryItem is an alert waiting for delivery together with its stream message ID
+type deliveryItem struct {
+	alert     *models.Alert
+	messageID string
+	priority  int
+	severity  int                     // Severity rank, breaks priority ties
+	prefs     *models.UserPreferences // Target user's preferences, looked up when queued (nil = none)
+	seq       int                     // Arrival order, keeps equal alerts FIFO
+}
+
+// deliveryHeap orders items by priority (highest first), then severity, then arrival order
+type deliveryHeap []*deliveryItem
+
+func (h deliveryHeap) Len() int { return len(h) }
+
+func (h deliveryHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	if h[i].severity != h[j].severity {
+		return h[i].severity > h[j].severity
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h deliveryHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *deliveryHeap) Push(x interface{}) { *h = append(*h, x.(*deliveryItem)) }
+
+func (h *deliveryHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
+
+// DeliveryQueue is the priority queue of one delivery lane: the highest-priority alert is
+// delivered first, then the most severe, then the oldest. Safe for concurrent use.
+type DeliveryQueue struct {
+	lane   string
+	mu     sync.Mutex
+	ready  *sync.Cond
+	items  deliveryHeap
+	seq    int
+	closed bool
+}
+
+// NewDeliveryQueue creates an empty delivery queue for a lane
+func NewDeliveryQueue(lane string) *DeliveryQueue {
+	q := &DeliveryQueue{lane: lane}
+	q.ready = sync.NewCond(&q.mu)
+	return q
+}
+
+// Push enqueues an alert with its delivery priority
+func (q *DeliveryQueue) Push(alert *models.Alert, messageID string, priority int) {
+	q.push(&deliveryItem{alert: alert, messageID: messageID, priority: priority})
+}
+
+// push enqueues an item, ranking it by its alert's severity
+func (q *DeliveryQueue) push(item *deliveryItem) {
+	item.severity = models.SeverityRank(item.alert.Severity)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item.seq = q.seq
+	q.seq++
+	heap.Push(&q.items, item)
+	deliveryQueueDepth.WithLabelValues(q.lane).Set(float64(q.items.Len()))
+	q.ready.Signal()
+}
+
+// Pop dequeues the highest-priority alert, waiting for one to be queued. ok is false once
+// the queue is closed and empty.
+func (q *DeliveryQueue) Pop() (alert *models.Alert, messageID string, ok bool) {
+	item, ok := q.pop()
+	if !ok {
+		return nil, "", false
+	}
+	return item.alert, item.messageID, true
+}
+
+// pop dequeues the next item, waiting for one to be queued
+func (q *DeliveryQueue) pop() (*deliveryItem, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.items.Len() == 0 && !q.closed {
+		q.ready.Wait()
+	}
+	if q.items.Len() == 0 {
+		return nil, false
+	}
+	item := heap.Pop(&q.items).(*deliveryItem)
+	deliveryQueueDepth.WithLabelValues(q.lane).Set(float64(q.items.Len()))
+	return item, true
+}
+
+// Close stops the queue from waiting for alerts; those already queued are still popped
+func (q *DeliveryQueue) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.ready.Broadcast()
+}
+
+// Len returns the number of queued alerts
+func (q *DeliveryQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.items.Len()
+}
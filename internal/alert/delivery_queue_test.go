@@ -0,0 +1,231 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+// slowSink simulates a slow delivery channel and records delivery order
+type slowSink struct {
+	mu        sync.Mutex
+	delay     time.Duration
+	delivered []string
+}
+
+func (s *slowSink) Name() string { return "slow" }
+
+func (s *slowSink) Publish(ctx context.Context, alerts []*models.Alert) error {
+	time.Sleep(s.delay)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, alert := range alerts {
+		s.delivered = append(s.delivered, alert.ID)
+	}
+	return nil
+}
+
+func (s *slowSink) Delivered() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.delivered...)
+}
+
+func alertMessage(t *testing.T, alert *models.Alert) storage.StreamMessage {
+	t.Helper()
+	data, err := json.Marshal(alert)
+	if err != nil {
+		t.Fatalf("Failed to marshal alert: %v", err)
+	}
+	return storage.StreamMessage{
+		ID:     "msg-" + alert.ID,
+		Stream: "alerts",
+		Values: map[string]interface{}{"alert": string(data)},
+	}
+}
+
+func TestDeliveryQueue_Order(t *testing.T) {
+	queue := NewDeliveryQueue(LaneNormal)
+	queue.Push(&models.Alert{ID: "low-1"}, "m1", 0)
+	queue.Push(&models.Alert{ID: "high"}, "m2", 10)
+	queue.Push(&models.Alert{ID: "low-2"}, "m3", 0)
+	queue.Push(&models.Alert{ID: "negative"}, "m4", -1)
+	queue.Push(&models.Alert{ID: "mid"}, "m5", 5)
+	queue.Push(&models.Alert{ID: "low-warning", Severity: models.SeverityWarning}, "m6", 0)
+	queue.Close()
+
+	var got []string
+	for {
+		alert, _, ok := queue.Pop()
+		if !ok {
+			break
+		}
+		got = append(got, alert.ID)
+	}
+
+	// Priority first, then severity, then arrival order
+	want := []string{"high", "mid", "low-warning", "low-1", "low-2", "negative"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d alerts, got %v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected delivery order %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestDeliveryQueue_PopWaitsForAlerts(t *testing.T) {
+	queue := NewDeliveryQueue(LaneHigh)
+	popped := make(chan string, 1)
+	go func() {
+		alert, _, ok := queue.Pop()
+		if ok {
+			popped <- alert.ID
+		}
+		close(popped)
+	}()
+
+	queue.Push(&models.Alert{ID: "late"}, "m1", 0)
+	select {
+	case id := <-popped:
+		if id != "late" {
+			t.Errorf("Expected the queued alert, got %q", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for Pop")
+	}
+
+	queue.Close()
+	if _, _, ok := queue.Pop(); ok {
+		t.Error("Expected Pop on a closed, empty queue to return ok=false")
+	}
+}
+
+func TestLaneFor(t *testing.T) {
+	tests := []struct {
+		priority int
+		severity string
+		want     string
+	}{
+		{0, models.SeverityCritical, LaneHigh},
+		{-10, models.SeverityCritical, LaneHigh},
+		{5, "", LaneHigh},
+		{0, models.SeverityInfo, LaneNormal},
+		{0, models.SeverityWarning, LaneNormal},
+		{-5, models.SeverityWarning, LaneNormal},
+		{-5, "", LaneLow},
+	}
+	for _, tt := range tests {
+		if got := laneFor(tt.priority, tt.severity); got != tt.want {
+			t.Errorf("laneFor(%d, %q) = %s, want %s", tt.priority, tt.severity, got, tt.want)
+		}
+	}
+}
+
+func TestConsumer_RuleAlertPriorityCombinesOwnerPreference(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	consumer := newTestConsumer(redis, &mockAlertWriter{})
+	prefsStore := storage.NewUserPreferencesStore(redis)
+	consumer.SetUserPreferences(prefsStore)
+	if err := prefsStore.SetPreferences(context.Background(), &models.UserPreferences{UserID: "user-1", Priority: 20}); err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+
+	rule := userRule("rule-1", "user-1")
+	rule.Priority = 3
+	consumer.processBatch([]storage.StreamMessage{alertMessage(t, scanRuleAlert(t, rule))})
+
+	if got := consumer.lanes[LaneHigh].Len(); got != 1 {
+		t.Fatalf("Expected the rule alert in the high lane, got %d alerts", got)
+	}
+	item, _ := consumer.lanes[LaneHigh].pop()
+	if item.priority != 23 {
+		t.Errorf("Expected rule priority 3 plus owner preference 20, got %d", item.priority)
+	}
+}
+
+func TestConsumer_HighLaneNotDelayedByBacklog(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	consumer := newTestConsumer(redis, &mockAlertWriter{})
+	sink := &slowSink{delay: 5 * time.Millisecond}
+	consumer.router.AddSink(sink)
+	prefsStore := storage.NewUserPreferencesStore(redis)
+	consumer.SetUserPreferences(prefsStore)
+	if err := prefsStore.SetPreferences(context.Background(), &models.UserPreferences{UserID: "vip", Priority: 20}); err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+
+	// A backlog of normal alerts is queued ahead of the urgent ones
+	now := time.Now()
+	var alerts []*models.Alert
+	var normal []string
+	for i := 0; i < 10; i++ {
+		id := fmt.Sprintf("normal-%d", i)
+		normal = append(normal, id)
+		alerts = append(alerts, &models.Alert{ID: id, RuleID: "rule-normal", Symbol: fmt.Sprintf("SYM%d", i), Timestamp: now})
+	}
+	alerts = append(alerts,
+		&models.Alert{ID: "critical", RuleID: "rule-normal", Symbol: "TSLA", Timestamp: now, Severity: models.SeverityCritical},
+		&models.Alert{ID: "rule-priority", RuleID: "rule-priority", Symbol: "NVDA", Timestamp: now, Priority: 10},
+		&models.Alert{ID: "vip", RuleID: "rule-normal", Symbol: "AMD", Timestamp: now,
+			Metadata: map[string]interface{}{models.AlertMetadataUserID: "vip"}},
+		&models.Alert{ID: "muted", RuleID: "rule-muted", Symbol: "F", Timestamp: now, Priority: -5},
+	)
+
+	messages := make([]storage.StreamMessage, 0, len(alerts))
+	for _, alert := range alerts {
+		messages = append(messages, alertMessage(t, alert))
+	}
+	consumer.processBatch(messages)
+	if got := consumer.lanes[LaneHigh].Len(); got != 3 {
+		t.Fatalf("Expected 3 alerts in the high lane, got %d", got)
+	}
+	if got := consumer.lanes[LaneLow].Len(); got != 1 {
+		t.Fatalf("Expected 1 alert in the low lane, got %d", got)
+	}
+
+	consumer.startLanes()
+	consumer.closeLanes()
+	consumer.wg.Wait()
+
+	got := sink.Delivered()
+	if len(got) != len(alerts) {
+		t.Fatalf("Expected %d deliveries, got %v", len(alerts), got)
+	}
+	position := make(map[string]int, len(got))
+	for i, id := range got {
+		position[id] = i
+	}
+
+	// The high lane is delivered in its own order, without waiting for the normal backlog
+	high := []string{"vip", "rule-priority", "critical"}
+	for i, id := range high {
+		if i > 0 && position[id] < position[high[i-1]] {
+			t.Errorf("Expected high lane order %v, got %v", high, got)
+		}
+		if position[id] > position[normal[len(normal)-1]] {
+			t.Errorf("Expected %s delivered before the normal backlog finished, got %v", id, got)
+		}
+	}
+	for i := 1; i < len(normal); i++ {
+		if position[normal[i]] < position[normal[i-1]] {
+			t.Errorf("Expected normal lane delivered in arrival order, got %v", got)
+			break
+		}
+	}
+
+	if routed := consumer.GetStats().AlertsRouted; routed != int64(len(alerts)) {
+		t.Errorf("Expected %d routed alerts, got %d", len(alerts), routed)
+	}
+	if acked := len(redis.Acked); acked != len(alerts) {
+		t.Errorf("Expected %d acknowledged messages, got %d", len(alerts), acked)
+	}
+}
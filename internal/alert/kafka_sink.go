@@ -0,0 +1,87 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// AlertSink receives filtered alerts in addition to the filtered Redis stream
+type AlertSink interface {
+	// Name identifies the sink in logs
+	Name() string
+
+	// Publish delivers filtered alerts to the sink
+	Publish(ctx context.Context, alerts []*models.Alert) error
+}
+
+// KafkaMessage is a message produced to a Kafka topic
+type KafkaMessage struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+}
+
+// KafkaWriter produces messages to Kafka. No Kafka client ships with the services: a build
+// embedding the router adds the sink with an adapter over its own client.
+type KafkaWriter interface {
+	WriteMessages(ctx context.Context, messages ...KafkaMessage) error
+	Close() error
+}
+
+// KafkaSink publishes filtered alerts to a Kafka topic, keyed by symbol so
+// alerts for a symbol stay ordered within a partition
+type KafkaSink struct {
+	writer KafkaWriter
+	topic  string
+}
+
+// NewKafkaSink creates a Kafka alert sink
+func NewKafkaSink(writer KafkaWriter, topic string) *KafkaSink {
+	return &KafkaSink{
+		writer: writer,
+		topic:  topic,
+	}
+}
+
+// Name returns the sink name
+func (s *KafkaSink) Name() string {
+	return "kafka"
+}
+
+// Publish produces one JSON message per alert
+func (s *KafkaSink) Publish(ctx context.Context, alerts []*models.Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	messages := make([]KafkaMessage, 0, len(alerts))
+	for _, alert := range alerts {
+		value, err := json.Marshal(alert)
+		if err != nil {
+			return fmt.Errorf("failed to marshal alert %s: %w", alert.ID, err)
+		}
+		messages = append(messages, KafkaMessage{
+			Topic: s.topic,
+			Key:   []byte(alert.Symbol),
+			Value: value,
+			Headers: map[string]string{
+				"alert_id": alert.ID,
+				"rule_id":  alert.RuleID,
+			},
+		})
+	}
+
+	if err := s.writer.WriteMessages(ctx, messages...); err != nil {
+		return fmt.Errorf("failed to write alerts to kafka topic %s: %w", s.topic, err)
+	}
+	return nil
+}
+
+// Close closes the underlying writer
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
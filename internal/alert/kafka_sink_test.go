@@ -0,0 +1,128 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+// mockKafkaWriter records produced messages
+type mockKafkaWriter struct {
+	mu       sync.Mutex
+	messages []KafkaMessage
+	err      error
+	closed   bool
+}
+
+func (w *mockKafkaWriter) WriteMessages(ctx context.Context, messages ...KafkaMessage) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	w.messages = append(w.messages, messages...)
+	return nil
+}
+
+func (w *mockKafkaWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	return nil
+}
+
+func (w *mockKafkaWriter) Messages() []KafkaMessage {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]KafkaMessage(nil), w.messages...)
+}
+
+func TestKafkaSink_KeyAndPayload(t *testing.T) {
+	writer := &mockKafkaWriter{}
+	sink := NewKafkaSink(writer, "alerts.kafka")
+
+	alert := &models.Alert{
+		ID:        "alert-1",
+		RuleID:    "rule-1",
+		RuleName:  "Test Rule",
+		Symbol:    "AAPL",
+		Timestamp: time.Now().UTC().Truncate(time.Second),
+		Price:     150.0,
+		Message:   "Test alert",
+	}
+
+	if err := sink.Publish(context.Background(), []*models.Alert{alert}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	messages := writer.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(messages))
+	}
+	msg := messages[0]
+	if msg.Topic != "alerts.kafka" {
+		t.Errorf("Expected topic alerts.kafka, got %s", msg.Topic)
+	}
+	if string(msg.Key) != "AAPL" {
+		t.Errorf("Expected key AAPL, got %s", msg.Key)
+	}
+	if msg.Headers["alert_id"] != "alert-1" || msg.Headers["rule_id"] != "rule-1" {
+		t.Errorf("Unexpected headers: %v", msg.Headers)
+	}
+
+	var decoded models.Alert
+	if err := json.Unmarshal(msg.Value, &decoded); err != nil {
+		t.Fatalf("Payload is not a JSON alert: %v", err)
+	}
+	if decoded.ID != alert.ID || decoded.Symbol != alert.Symbol || decoded.Price != alert.Price || !decoded.Timestamp.Equal(alert.Timestamp) {
+		t.Errorf("Payload mismatch: got %+v, want %+v", decoded, *alert)
+	}
+
+	if err := sink.Close(); err != nil || !writer.closed {
+		t.Errorf("Expected Close() to close the writer (err = %v)", err)
+	}
+}
+
+func TestRouter_RoutesToSinks(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	router := NewRouter(redis, "alerts.filtered", 5*time.Second)
+	writer := &mockKafkaWriter{}
+	router.AddSink(NewKafkaSink(writer, "alerts.kafka"))
+
+	ctx := context.Background()
+	if err := router.RouteAlert(ctx, &models.Alert{ID: "alert-1", RuleID: "rule-1", Symbol: "AAPL", Timestamp: time.Now()}); err != nil {
+		t.Fatalf("RouteAlert() error = %v", err)
+	}
+	if err := router.RouteAlerts(ctx, []*models.Alert{
+		{ID: "alert-2", RuleID: "rule-1", Symbol: "MSFT", Timestamp: time.Now()},
+		{ID: "alert-3", RuleID: "rule-2", Symbol: "TSLA", Timestamp: time.Now()},
+	}); err != nil {
+		t.Fatalf("RouteAlerts() error = %v", err)
+	}
+
+	messages := writer.Messages()
+	if len(messages) != 3 {
+		t.Fatalf("Expected 3 Kafka messages, got %d", len(messages))
+	}
+	for i, want := range []string{"AAPL", "MSFT", "TSLA"} {
+		if string(messages[i].Key) != want {
+			t.Errorf("Message %d: expected key %s, got %s", i, want, messages[i].Key)
+		}
+	}
+}
+
+func TestRouter_SinkFailureDoesNotFailRouting(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	router := NewRouter(redis, "alerts.filtered", 5*time.Second)
+	router.AddSink(NewKafkaSink(&mockKafkaWriter{err: errors.New("broker unavailable")}, "alerts.kafka"))
+
+	if err := router.RouteAlert(context.Background(), &models.Alert{ID: "alert-1", RuleID: "rule-1", Symbol: "AAPL", Timestamp: time.Now()}); err != nil {
+		t.Errorf("Expected routing to succeed despite sink failure, got %v", err)
+	}
+}
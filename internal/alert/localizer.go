@@ -0,0 +1,206 @@
+package alert
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// DefaultLocale is the locale used when a user has no locale configured
+const DefaultLocale = "en-US"
+
+// NumberFormat describes how numbers are formatted for a locale
+type NumberFormat struct {
+	DecimalSeparator   string
+	ThousandsSeparator string
+	Decimals           int
+}
+
+// LocaleConfig holds the message template and number format for a locale
+type LocaleConfig struct {
+	Template string // text/template rendered with the alert (use {{number .Price}} for numbers)
+	Format   NumberFormat
+}
+
+// builtinLocales are the locales available without additional configuration
+var builtinLocales = map[string]LocaleConfig{
+	"en-US": {
+		Template: "Rule '{{.RuleName}}' matched for {{.Symbol}} at {{number .Price}}",
+		Format:   NumberFormat{DecimalSeparator: ".", ThousandsSeparator: ",", Decimals: 2},
+	},
+	"de-DE": {
+		Template: "Regel '{{.RuleName}}' ausgelöst für {{.Symbol}} bei {{number .Price}}",
+		Format:   NumberFormat{DecimalSeparator: ",", ThousandsSeparator: ".", Decimals: 2},
+	},
+	"fr-FR": {
+		Template: "Règle '{{.RuleName}}' déclenchée pour {{.Symbol}} à {{number .Price}}",
+		Format:   NumberFormat{DecimalSeparator: ",", ThousandsSeparator: " ", Decimals: 2},
+	},
+	"es-ES": {
+		Template: "Regla '{{.RuleName}}' activada para {{.Symbol}} a {{number .Price}}",
+		Format:   NumberFormat{DecimalSeparator: ",", ThousandsSeparator: ".", Decimals: 2},
+	},
+}
+
+// Localizer renders alert messages in each user's locale
+type Localizer struct {
+	defaultLocale string
+	templates     map[string]*template.Template
+	userLocales   map[string]string // User ID -> locale
+	mu            sync.RWMutex
+}
+
+// NewLocalizer creates a localizer with the built-in locales registered
+func NewLocalizer(defaultLocale string) *Localizer {
+	if defaultLocale == "" {
+		defaultLocale = DefaultLocale
+	}
+
+	l := &Localizer{
+		defaultLocale: defaultLocale,
+		templates:     make(map[string]*template.Template),
+		userLocales:   make(map[string]string),
+	}
+	for locale, cfg := range builtinLocales {
+		// Built-in templates are known to be valid
+		_ = l.RegisterLocale(locale, cfg)
+	}
+	if _, exists := l.templates[defaultLocale]; !exists {
+		logger.Warn("Default locale not registered, falling back",
+			logger.String("locale", defaultLocale),
+			logger.String("fallback", DefaultLocale),
+		)
+		l.defaultLocale = DefaultLocale
+	}
+
+	return l
+}
+
+// RegisterLocale registers (or replaces) a locale's template and number format
+func (l *Localizer) RegisterLocale(locale string, cfg LocaleConfig) error {
+	format := cfg.Format
+	tmpl, err := template.New(locale).Funcs(template.FuncMap{
+		"number": func(value float64) string {
+			return FormatNumber(value, format)
+		},
+	}).Parse(cfg.Template)
+	if err != nil {
+		return fmt.Errorf("invalid template for locale %s: %w", locale, err)
+	}
+
+	l.mu.Lock()
+	l.templates[locale] = tmpl
+	l.mu.Unlock()
+	return nil
+}
+
+// SetUserLocale sets the locale for a user
+func (l *Localizer) SetUserLocale(userID, locale string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.userLocales[userID] = locale
+}
+
+// LocaleFor returns the locale used for a user, falling back to the default locale
+func (l *Localizer) LocaleFor(userID string) string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if locale, exists := l.userLocales[userID]; exists {
+		if _, registered := l.templates[locale]; registered {
+			return locale
+		}
+	}
+	return l.defaultLocale
+}
+
+// Render renders an alert message in the given locale (default locale if unknown)
+func (l *Localizer) Render(alert *models.Alert, locale string) (string, error) {
+	l.mu.RLock()
+	tmpl, exists := l.templates[locale]
+	if !exists {
+		tmpl = l.templates[l.defaultLocale]
+	}
+	l.mu.RUnlock()
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, alert); err != nil {
+		return "", fmt.Errorf("failed to render alert message: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// Localize attaches localized messages to an alert for delivery channels.
+// Messages are rendered for the default locale and every configured user locale.
+// Alerts targeted at a single user also get their Message rendered in that user's locale:
+// userLocale if registered (e.g. from the user's preferences), else the configured one.
+func (l *Localizer) Localize(alert *models.Alert, userLocale string) error {
+	l.mu.RLock()
+	locales := map[string]bool{l.defaultLocale: true}
+	for _, locale := range l.userLocales {
+		if _, registered := l.templates[locale]; registered {
+			locales[locale] = true
+		}
+	}
+	if _, registered := l.templates[userLocale]; !registered {
+		userLocale = ""
+	}
+	l.mu.RUnlock()
+
+	userID := alert.TargetUserID()
+	if userID != "" && userLocale != "" {
+		locales[userLocale] = true
+	}
+
+	messages := make(map[string]string, len(locales))
+	for locale := range locales {
+		message, err := l.Render(alert, locale)
+		if err != nil {
+			return err
+		}
+		messages[locale] = message
+	}
+	alert.Messages = messages
+
+	if userID != "" {
+		if userLocale == "" {
+			userLocale = l.LocaleFor(userID)
+		}
+		alert.Message = messages[userLocale]
+	}
+
+	return nil
+}
+
+// FormatNumber formats a number using a locale's separators
+func FormatNumber(value float64, format NumberFormat) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+
+	formatted := strconv.FormatFloat(math.Abs(value), 'f', format.Decimals, 64)
+	intPart, fracPart, _ := strings.Cut(formatted, ".")
+
+	// Group integer digits in thousands
+	var sb strings.Builder
+	if value < 0 && strings.Trim(formatted, "0.") != "" {
+		sb.WriteString("-")
+	}
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			sb.WriteString(format.ThousandsSeparator)
+		}
+		sb.WriteRune(digit)
+	}
+	if fracPart != "" {
+		sb.WriteString(format.DecimalSeparator)
+		sb.WriteString(fracPart)
+	}
+
+	return sb.String()
+}
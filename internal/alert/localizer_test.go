@@ -0,0 +1,167 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+func newLocalizerTestAlert() *models.Alert {
+	return &models.Alert{
+		ID:        "alert-1",
+		RuleID:    "rule-1",
+		RuleName:  "Breakout",
+		Symbol:    "AAPL",
+		Timestamp: time.Now(),
+		Price:     1234567.891,
+		Message:   "Rule 'Breakout' matched for AAPL",
+	}
+}
+
+func TestLocalizer_RenderLocales(t *testing.T) {
+	localizer := NewLocalizer("en-US")
+	alert := newLocalizerTestAlert()
+
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"en-US", "Rule 'Breakout' matched for AAPL at 1,234,567.89"},
+		{"de-DE", "Regel 'Breakout' ausgelöst für AAPL bei 1.234.567,89"},
+		{"xx-XX", "Rule 'Breakout' matched for AAPL at 1,234,567.89"}, // Unknown falls back to default
+	}
+
+	for _, tt := range tests {
+		got, err := localizer.Render(alert, tt.locale)
+		if err != nil {
+			t.Fatalf("Render(%s) error = %v", tt.locale, err)
+		}
+		if got != tt.want {
+			t.Errorf("Render(%s) = %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestLocalizer_UserLocaleFallback(t *testing.T) {
+	localizer := NewLocalizer("")
+	localizer.SetUserLocale("user-de", "de-DE")
+	localizer.SetUserLocale("user-bad", "xx-XX")
+
+	if got := localizer.LocaleFor("user-de"); got != "de-DE" {
+		t.Errorf("LocaleFor(user-de) = %s, want de-DE", got)
+	}
+	if got := localizer.LocaleFor("user-bad"); got != DefaultLocale {
+		t.Errorf("LocaleFor(user-bad) = %s, want %s", got, DefaultLocale)
+	}
+	if got := localizer.LocaleFor("unknown"); got != DefaultLocale {
+		t.Errorf("LocaleFor(unknown) = %s, want %s", got, DefaultLocale)
+	}
+}
+
+func TestLocalizer_Localize(t *testing.T) {
+	localizer := NewLocalizer("en-US")
+	localizer.SetUserLocale("user-de", "de-DE")
+
+	// Broadcast alert gets messages for every configured locale
+	alert := newLocalizerTestAlert()
+	if err := localizer.Localize(alert, ""); err != nil {
+		t.Fatalf("Localize() error = %v", err)
+	}
+	if len(alert.Messages) != 2 {
+		t.Errorf("Expected 2 localized messages, got %d", len(alert.Messages))
+	}
+	if alert.Message != "Rule 'Breakout' matched for AAPL" {
+		t.Errorf("Broadcast alert message should be unchanged, got %q", alert.Message)
+	}
+	if got := alert.MessageFor("de-DE"); got != "Regel 'Breakout' ausgelöst für AAPL bei 1.234.567,89" {
+		t.Errorf("MessageFor(de-DE) = %q", got)
+	}
+	if got := alert.MessageFor("fr-FR"); got != alert.Message {
+		t.Errorf("MessageFor(fr-FR) should fall back to Message, got %q", got)
+	}
+
+	// Targeted alert gets its message rendered in the user's locale
+	targeted := newLocalizerTestAlert()
+	targeted.Metadata = map[string]interface{}{models.AlertMetadataUserID: "user-de"}
+	if err := localizer.Localize(targeted, ""); err != nil {
+		t.Fatalf("Localize() error = %v", err)
+	}
+	if targeted.Message != "Regel 'Breakout' ausgelöst für AAPL bei 1.234.567,89" {
+		t.Errorf("Targeted alert message = %q", targeted.Message)
+	}
+
+	// A locale passed for the alert (e.g. from preferences) overrides the configured one
+	preferred := newLocalizerTestAlert()
+	preferred.Metadata = map[string]interface{}{models.AlertMetadataUserID: "user-de"}
+	if err := localizer.Localize(preferred, "fr-FR"); err != nil {
+		t.Fatalf("Localize() error = %v", err)
+	}
+	if preferred.Message != preferred.Messages["fr-FR"] || preferred.Message == "" {
+		t.Errorf("Expected message rendered in fr-FR, got %q", preferred.Message)
+	}
+	if got := localizer.LocaleFor("user-de"); got != "de-DE" {
+		t.Errorf("Expected configured locale unchanged, got %s", got)
+	}
+}
+
+func TestLocalizer_RegisterLocale(t *testing.T) {
+	localizer := NewLocalizer("en-US")
+
+	err := localizer.RegisterLocale("pt-BR", LocaleConfig{
+		Template: "{{.Symbol}}: {{number .Price}}",
+		Format:   NumberFormat{DecimalSeparator: ",", ThousandsSeparator: ".", Decimals: 3},
+	})
+	if err != nil {
+		t.Fatalf("RegisterLocale() error = %v", err)
+	}
+
+	got, _ := localizer.Render(&models.Alert{Symbol: "PETR4", Price: -1234.5}, "pt-BR")
+	if got != "PETR4: -1.234,500" {
+		t.Errorf("Render(pt-BR) = %q", got)
+	}
+
+	if err := localizer.RegisterLocale("bad", LocaleConfig{Template: "{{.Symbol"}); err == nil {
+		t.Error("Expected error for invalid template")
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	us := NumberFormat{DecimalSeparator: ".", ThousandsSeparator: ",", Decimals: 2}
+
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{0, "0.00"},
+		{999.999, "1,000.00"},
+		{123, "123.00"},
+		{-0.001, "0.00"},
+		{-98765.4321, "-98,765.43"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatNumber(tt.value, us); got != tt.want {
+			t.Errorf("FormatNumber(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestConsumer_ProcessAlert_Localized(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	consumer := newTestConsumer(redis, &mockAlertWriter{})
+
+	localizer := NewLocalizer("en-US")
+	localizer.SetUserLocale("user-de", "de-DE")
+	consumer.SetLocalizer(localizer)
+
+	alert := newLocalizerTestAlert()
+	if _, err := consumer.processAlert(alert); err != nil {
+		t.Fatalf("Failed to process alert: %v", err)
+	}
+
+	if alert.Messages["de-DE"] == "" || alert.Messages["en-US"] == "" {
+		t.Errorf("Expected routed alert to carry localized messages, got %v", alert.Messages)
+	}
+}
@@ -8,9 +8,10 @@ import (
 	"sync"
 	"time"
 
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/lib/pq"
 	"github.com/mohamedkhairy/stock-scanner/internal/config"
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
 	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
 )
 
@@ -22,11 +23,15 @@ type AlertPersister struct {
 
 	// Write queue
 	writeQueue chan []*models.Alert
+	insert     func(ctx context.Context, alerts []*models.Alert) error
+	aggregator *alertAggregator // nil when aggregation is disabled; owned by processWriteQueue
+	now        func() time.Time
 	ctx        context.Context
 	cancel     context.CancelFunc
 	wg         sync.WaitGroup
 	mu         sync.RWMutex
 	running    bool
+	closed     bool // Write queue closed, no more alerts accepted
 }
 
 // WriteConfig holds configuration for write operations
@@ -36,6 +41,10 @@ type WriteConfig struct {
 	QueueSize   int
 	MaxRetries  int
 	RetryDelay  time.Duration
+
+	// AggregateWindow collapses alerts with the same rule and symbol within a window into
+	// one persisted row with an occurrence count (0 = persist every alert)
+	AggregateWindow time.Duration
 }
 
 // NewAlertPersister creates a new alert persister
@@ -71,16 +80,10 @@ func NewAlertPersister(dbConfig config.DatabaseConfig, writeConfig WriteConfig)
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	clientCtx, clientCancel := context.WithCancel(context.Background())
-
-	persister := &AlertPersister{
-		db:          db,
-		dbConfig:    dbConfig,
-		writeConfig: writeConfig,
-		writeQueue:  make(chan []*models.Alert, writeConfig.QueueSize),
-		ctx:         clientCtx,
-		cancel:      clientCancel,
-	}
+	persister := newAlertPersister(writeConfig, nil)
+	persister.db = db
+	persister.dbConfig = dbConfig
+	persister.insert = persister.insertBatch
 
 	logger.Info("Alert persister initialized",
 		logger.String("host", dbConfig.Host),
@@ -91,6 +94,24 @@ func NewAlertPersister(dbConfig config.DatabaseConfig, writeConfig WriteConfig)
 	return persister, nil
 }
 
+// newAlertPersister creates a persister writing batches with insert
+func newAlertPersister(writeConfig WriteConfig, insert func(ctx context.Context, alerts []*models.Alert) error) *AlertPersister {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	persister := &AlertPersister{
+		writeConfig: writeConfig,
+		writeQueue:  make(chan []*models.Alert, writeConfig.QueueSize),
+		insert:      insert,
+		now:         time.Now,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	if writeConfig.AggregateWindow > 0 {
+		persister.aggregator = newAlertAggregator(writeConfig.AggregateWindow)
+	}
+	return persister
+}
+
 // Start starts the write queue processor
 func (p *AlertPersister) Start() error {
 	p.mu.Lock()
@@ -108,21 +129,49 @@ func (p *AlertPersister) Start() error {
 	return nil
 }
 
-// Stop stops the write queue processor
+// Stop stops the write queue processor after writing all queued alerts
 func (p *AlertPersister) Stop() {
+	_ = p.Shutdown(context.Background())
+}
+
+// Shutdown stops accepting alerts and writes everything already queued.
+// If ctx expires first, in-flight writes are cancelled, alerts still queued are
+// dropped and ctx's error is returned.
+func (p *AlertPersister) Shutdown(ctx context.Context) error {
 	p.mu.Lock()
-	if !p.running {
+	if p.closed {
 		p.mu.Unlock()
-		return
+		return nil
 	}
+	p.closed = true
+	running := p.running
 	p.running = false
+	close(p.writeQueue)
 	p.mu.Unlock()
 
-	logger.Info("Stopping alert persister")
-	p.cancel()
-	close(p.writeQueue)
-	p.wg.Wait()
-	logger.Info("Alert persister stopped")
+	if !running {
+		return nil
+	}
+
+	logger.Info("Draining alert persister",
+		logger.Int("queue_depth", len(p.writeQueue)),
+	)
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Info("Alert persister stopped")
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		<-done
+		return fmt.Errorf("alert persister drain timed out: %w", ctx.Err())
+	}
 }
 
 // WriteAlerts enqueues alerts for async writing
@@ -148,6 +197,13 @@ func (p *AlertPersister) WriteAlerts(ctx context.Context, alerts []*models.Alert
 		return nil
 	}
 
+	// Hold the read lock while enqueueing so Shutdown can't close the queue mid-send
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return fmt.Errorf("persister is shut down")
+	}
+
 	// Try to enqueue (non-blocking with timeout)
 	select {
 	case p.writeQueue <- validAlerts:
@@ -181,20 +237,31 @@ func (p *AlertPersister) processWriteQueue() {
 		select {
 		case <-p.ctx.Done():
 			// Process remaining batch before exiting
+			batch = append(batch, p.flushAggregated(true)...)
 			if len(batch) > 0 {
 				p.writeBatch(batch)
 			}
+			if dropped := len(p.writeQueue); dropped > 0 {
+				logger.Warn("Dropping queued alert batches on persister abort",
+					logger.Int("batches", dropped),
+				)
+			}
 			return
 
 		case alerts, ok := <-p.writeQueue:
 			if !ok {
 				// Channel closed, process remaining batch
+				batch = append(batch, p.flushAggregated(true)...)
 				if len(batch) > 0 {
 					p.writeBatch(batch)
 				}
 				return
 			}
 
+			if p.aggregator != nil {
+				p.aggregator.add(alerts)
+				alerts = p.flushAggregated(false)
+			}
 			batch = append(batch, alerts...)
 
 			// Write batch if it's full
@@ -205,6 +272,7 @@ func (p *AlertPersister) processWriteQueue() {
 
 		case <-ticker.C:
 			// Write batch on interval
+			batch = append(batch, p.flushAggregated(false)...)
 			if len(batch) > 0 {
 				p.writeBatch(batch)
 				batch = batch[:0] // Clear batch
@@ -213,19 +281,31 @@ func (p *AlertPersister) processWriteQueue() {
 	}
 }
 
+// flushAggregated returns aggregated alerts whose window has closed (all of them if all is set)
+func (p *AlertPersister) flushAggregated(all bool) []*models.Alert {
+	if p.aggregator == nil {
+		return nil
+	}
+	if all {
+		return p.aggregator.flush(time.Time{})
+	}
+	return p.aggregator.flush(p.now())
+}
+
 // writeBatch writes a batch of alerts to the database
 func (p *AlertPersister) writeBatch(alerts []*models.Alert) {
 	if len(alerts) == 0 {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Derived from the persister context so an aborted shutdown cancels in-flight writes
+	ctx, cancel := context.WithTimeout(p.ctx, 30*time.Second)
 	defer cancel()
 
 	// Retry logic
 	var err error
 	for attempt := 0; attempt < p.writeConfig.MaxRetries; attempt++ {
-		err = p.insertBatch(ctx, alerts)
+		err = p.insert(ctx, alerts)
 		if err == nil {
 			logger.Debug("Successfully wrote alerts batch",
 				logger.Int("count", len(alerts)),
@@ -233,6 +313,10 @@ func (p *AlertPersister) writeBatch(alerts []*models.Alert) {
 			return
 		}
 
+		if ctx.Err() != nil {
+			break
+		}
+
 		if attempt < p.writeConfig.MaxRetries-1 {
 			logger.Warn("Failed to write alerts batch, retrying",
 				logger.ErrorField(err),
@@ -252,8 +336,8 @@ func (p *AlertPersister) writeBatch(alerts []*models.Alert) {
 // insertBatch inserts a batch of alerts into the database
 func (p *AlertPersister) insertBatch(ctx context.Context, alerts []*models.Alert) error {
 	query := `
-		INSERT INTO alert_history (id, rule_id, rule_name, symbol, timestamp, price, message, metadata, trace_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO alert_history (id, rule_id, rule_name, symbol, timestamp, price, message, metadata, trace_id, occurrences, severity, alert_ids)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		ON CONFLICT (id, timestamp) DO NOTHING
 	`
 
@@ -285,6 +369,15 @@ func (p *AlertPersister) insertBatch(ctx context.Context, alerts []*models.Alert
 			metadataJSON = []byte("{}")
 		}
 
+		severity := alert.Severity
+		if severity == "" {
+			severity = models.SeverityInfo
+		}
+		alertIDs := alert.AlertIDs
+		if len(alertIDs) == 0 {
+			alertIDs = []string{alert.ID}
+		}
+
 		_, err := stmt.ExecContext(ctx,
 			alert.ID,
 			alert.RuleID,
@@ -295,6 +388,9 @@ func (p *AlertPersister) insertBatch(ctx context.Context, alerts []*models.Alert
 			alert.Message,
 			string(metadataJSON),
 			alert.TraceID,
+			max(alert.Occurrences, 1),
+			severity,
+			pq.Array(alertIDs),
 		)
 		if err != nil {
 			return fmt.Errorf("failed to insert alert %s: %w", alert.ID, err)
@@ -308,6 +404,77 @@ func (p *AlertPersister) insertBatch(ctx context.Context, alerts []*models.Alert
 	return nil
 }
 
+// alertAggregator collapses alerts with the same (rule, symbol, window) into one record
+type alertAggregator struct {
+	window  time.Duration
+	pending map[string]*aggregatedAlert
+	order   []string // Keys in first-seen order so rows are written chronologically
+}
+
+// aggregatedAlert is the record persisted for an aggregation window
+type aggregatedAlert struct {
+	alert     *models.Alert
+	windowEnd time.Time
+}
+
+// newAlertAggregator creates an aggregator with the given window
+func newAlertAggregator(window time.Duration) *alertAggregator {
+	return &alertAggregator{
+		window:  window,
+		pending: make(map[string]*aggregatedAlert),
+	}
+}
+
+// add counts alerts into their windows. The first alert of a window is copied as the
+// persisted record so alerts being delivered concurrently are never modified.
+func (a *alertAggregator) add(alerts []*models.Alert) {
+	for _, alert := range alerts {
+		windowStart := alert.Timestamp.Truncate(a.window)
+		key := fmt.Sprintf("%s|%s|%d", alert.RuleID, alert.Symbol, windowStart.UnixNano())
+
+		if agg, exists := a.pending[key]; exists {
+			agg.alert.Occurrences++
+			agg.alert.AlertIDs = append(agg.alert.AlertIDs, alert.ID)
+			// The record carries the highest severity of its window
+			if models.SeverityRank(alert.Severity) > models.SeverityRank(agg.alert.Severity) {
+				agg.alert.Severity = alert.Severity
+			}
+			continue
+		}
+
+		record := *alert
+		record.Occurrences = 1
+		record.AlertIDs = []string{alert.ID}
+		a.pending[key] = &aggregatedAlert{
+			alert:     &record,
+			windowEnd: windowStart.Add(a.window),
+		}
+		a.order = append(a.order, key)
+	}
+}
+
+// flush removes and returns records whose window ended by now (every record if now is zero)
+func (a *alertAggregator) flush(now time.Time) []*models.Alert {
+	var flushed []*models.Alert
+	remaining := a.order[:0]
+	for _, key := range a.order {
+		agg := a.pending[key]
+		if !now.IsZero() && now.Before(agg.windowEnd) {
+			remaining = append(remaining, key)
+			continue
+		}
+		flushed = append(flushed, agg.alert)
+		delete(a.pending, key)
+	}
+	a.order = remaining
+	return flushed
+}
+
+// Hypertables returns a hypertable manager using the persister's connection
+func (p *AlertPersister) Hypertables() *storage.HypertableManager {
+	return storage.NewHypertableManager(p.db)
+}
+
 // Close closes the database connection
 func (p *AlertPersister) Close() error {
 	p.Stop()
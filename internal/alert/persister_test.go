@@ -0,0 +1,354 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+// recordingInserter records inserted alerts, optionally blocking until released
+type recordingInserter struct {
+	mu      sync.Mutex
+	alerts  []*models.Alert
+	release chan struct{}
+}
+
+func (r *recordingInserter) insert(ctx context.Context, alerts []*models.Alert) error {
+	if r.release != nil {
+		select {
+		case <-r.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, alerts...)
+	return nil
+}
+
+func (r *recordingInserter) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.alerts)
+}
+
+func newTestAlert(id string) *models.Alert {
+	return &models.Alert{
+		ID:        id,
+		RuleID:    "rule-1",
+		RuleName:  "Test Rule",
+		Symbol:    "AAPL",
+		Timestamp: time.Now(),
+		Price:     150.0,
+	}
+}
+
+func newTestWriteConfig() WriteConfig {
+	// Long interval and large batch so nothing is written before shutdown
+	return WriteConfig{
+		BatchSize:  1000,
+		Interval:   time.Hour,
+		QueueSize:  100,
+		MaxRetries: 3,
+		RetryDelay: time.Millisecond,
+	}
+}
+
+func TestAlertPersister_ShutdownFlushesQueue(t *testing.T) {
+	inserter := &recordingInserter{}
+	persister := newAlertPersister(newTestWriteConfig(), inserter.insert)
+	if err := persister.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		if err := persister.WriteAlerts(context.Background(), []*models.Alert{newTestAlert(fmt.Sprintf("alert-%d", i))}); err != nil {
+			t.Fatalf("WriteAlerts() error = %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := persister.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	if got := inserter.count(); got != 20 {
+		t.Errorf("Expected all 20 queued alerts written on shutdown, got %d", got)
+	}
+
+	// No alerts are accepted after shutdown
+	if err := persister.WriteAlerts(context.Background(), []*models.Alert{newTestAlert("late")}); err == nil {
+		t.Error("Expected WriteAlerts after shutdown to fail")
+	}
+
+	// Shutdown is idempotent
+	if err := persister.Shutdown(ctx); err != nil {
+		t.Errorf("Second Shutdown() error = %v", err)
+	}
+}
+
+func TestAlertPersister_ShutdownTimeout(t *testing.T) {
+	inserter := &recordingInserter{release: make(chan struct{})}
+	config := newTestWriteConfig()
+	config.BatchSize = 1
+	persister := newAlertPersister(config, inserter.insert)
+	if err := persister.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if err := persister.WriteAlerts(context.Background(), []*models.Alert{newTestAlert("alert-1")}); err != nil {
+		t.Fatalf("WriteAlerts() error = %v", err)
+	}
+
+	// The database never responds, so the drain is bounded by the timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := persister.Shutdown(ctx); err == nil {
+		t.Error("Expected Shutdown() to report the drain timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected Shutdown() bounded by timeout, took %v", elapsed)
+	}
+}
+
+func TestAlertPipeline_ShutdownDrainsInFlightAlerts(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	for i := 0; i < 5; i++ {
+		// Distinct rules so none of the alerts are deduplicated
+		alert := newTestAlert(fmt.Sprintf("alert-%d", i))
+		alert.RuleID = fmt.Sprintf("rule-%d", i)
+		data, err := json.Marshal(alert)
+		if err != nil {
+			t.Fatalf("Failed to marshal alert: %v", err)
+		}
+		redis.StreamData = append(redis.StreamData, storage.StreamMessage{
+			ID:     fmt.Sprintf("1-%d", i),
+			Stream: "alerts",
+			Values: map[string]interface{}{"alert": string(data)},
+		})
+	}
+
+	inserter := &recordingInserter{}
+	persister := newAlertPersister(newTestWriteConfig(), inserter.insert)
+	if err := persister.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	cfg := config.AlertConfig{
+		StreamName:         "alerts",
+		ConsumerGroup:      "alert-service",
+		FilteredStreamName: "alerts.filtered",
+		ProcessTimeout:     time.Hour, // Only shutdown or a full batch flushes
+		BatchSize:          10,
+	}
+	consumer := NewConsumer(
+		cfg,
+		redis,
+		NewDeduplicator(redis, time.Hour),
+		NewUserFilter(),
+		persister,
+		NewRouter(redis, cfg.FilteredStreamName, 5*time.Second),
+	)
+	if err := consumer.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := consumer.Shutdown(ctx); err != nil {
+		t.Fatalf("consumer Shutdown() error = %v", err)
+	}
+	if err := persister.Shutdown(ctx); err != nil {
+		t.Fatalf("persister Shutdown() error = %v", err)
+	}
+
+	if stats := consumer.GetStats(); stats.AlertsRouted != 5 {
+		t.Errorf("Expected 5 routed alerts, got %d", stats.AlertsRouted)
+	}
+	if got := inserter.count(); got != 5 {
+		t.Errorf("Expected 5 persisted alerts, got %d", got)
+	}
+	for i := 0; i < 5; i++ {
+		if id := fmt.Sprintf("1-%d", i); !redis.Acked[id] {
+			t.Errorf("Expected message %s acknowledged", id)
+		}
+	}
+}
+
+func TestAlertPersister_AggregatesBySymbolWindow(t *testing.T) {
+	inserter := &recordingInserter{}
+	config := newTestWriteConfig()
+	config.AggregateWindow = time.Minute
+	persister := newAlertPersister(config, inserter.insert)
+	windowStart := time.Now().Truncate(time.Minute)
+	persister.now = func() time.Time { return windowStart.Add(10 * time.Second) }
+	if err := persister.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	var sent []*models.Alert
+	for i := 0; i < 5; i++ {
+		alert := newTestAlert(fmt.Sprintf("alert-%d", i))
+		alert.Timestamp = windowStart.Add(time.Duration(i) * time.Second)
+		if i == 2 {
+			alert.Severity = models.SeverityCritical
+		}
+		sent = append(sent, alert)
+	}
+	other := newTestAlert("alert-msft")
+	other.Symbol = "MSFT"
+	other.Timestamp = windowStart
+	sent = append(sent, other)
+
+	for _, alert := range sent {
+		if err := persister.WriteAlerts(context.Background(), []*models.Alert{alert}); err != nil {
+			t.Fatalf("WriteAlerts() error = %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := persister.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	if got := inserter.count(); got != 2 {
+		t.Fatalf("Expected 2 aggregated rows, got %d", got)
+	}
+	occurrences := map[string]int{}
+	severities := map[string]string{}
+	for _, alert := range inserter.alerts {
+		occurrences[alert.Symbol] = alert.Occurrences
+		severities[alert.Symbol] = alert.Severity
+	}
+	if occurrences["AAPL"] != 5 {
+		t.Errorf("Expected AAPL row with 5 occurrences, got %d", occurrences["AAPL"])
+	}
+	if severities["AAPL"] != models.SeverityCritical {
+		t.Errorf("Expected AAPL row with the window's highest severity, got %q", severities["AAPL"])
+	}
+	if occurrences["MSFT"] != 1 {
+		t.Errorf("Expected MSFT row with 1 occurrence, got %d", occurrences["MSFT"])
+	}
+	for _, alert := range inserter.alerts {
+		if alert.Symbol == "AAPL" && strings.Join(alert.AlertIDs, ",") != "alert-0,alert-1,alert-2,alert-3,alert-4" {
+			t.Errorf("Expected AAPL row to keep every alert ID, got %v", alert.AlertIDs)
+		}
+	}
+
+	// The alerts handed to the persister are never modified
+	for _, alert := range sent {
+		if alert.Occurrences != 0 {
+			t.Errorf("Expected alert %s unchanged, got occurrences %d", alert.ID, alert.Occurrences)
+		}
+	}
+}
+
+func TestAlertAggregator_FlushesClosedWindows(t *testing.T) {
+	aggregator := newAlertAggregator(time.Minute)
+	windowStart := time.Now().Truncate(time.Minute)
+
+	first := newTestAlert("alert-1")
+	first.Timestamp = windowStart
+	next := newTestAlert("alert-2")
+	next.Timestamp = windowStart.Add(time.Minute)
+	aggregator.add([]*models.Alert{first, next})
+
+	if flushed := aggregator.flush(windowStart.Add(30 * time.Second)); len(flushed) != 0 {
+		t.Errorf("Expected no rows before the window ends, got %d", len(flushed))
+	}
+
+	flushed := aggregator.flush(windowStart.Add(time.Minute))
+	if len(flushed) != 1 || flushed[0].ID != "alert-1" {
+		t.Fatalf("Expected only the first window flushed, got %v", flushed)
+	}
+
+	if flushed := aggregator.flush(time.Time{}); len(flushed) != 1 || flushed[0].ID != "alert-2" {
+		t.Errorf("Expected the remaining window flushed, got %v", flushed)
+	}
+}
+
+func TestAlertPipeline_AggregatedAlertsDeliveredInRealTime(t *testing.T) {
+	const n = 5
+	redis := storage.NewMockRedisClient()
+	windowStart := time.Now().Truncate(time.Minute)
+	for i := 0; i < n; i++ {
+		// Same rule and symbol, one second apart so none are deduplicated
+		alert := newTestAlert(fmt.Sprintf("alert-%d", i))
+		alert.Timestamp = windowStart.Add(time.Duration(i) * time.Second)
+		data, err := json.Marshal(alert)
+		if err != nil {
+			t.Fatalf("Failed to marshal alert: %v", err)
+		}
+		redis.StreamData = append(redis.StreamData, storage.StreamMessage{
+			ID:     fmt.Sprintf("1-%d", i),
+			Stream: "alerts",
+			Values: map[string]interface{}{"alert": string(data)},
+		})
+	}
+
+	inserter := &recordingInserter{}
+	writeConfig := newTestWriteConfig()
+	writeConfig.AggregateWindow = time.Minute
+	persister := newAlertPersister(writeConfig, inserter.insert)
+	persister.now = func() time.Time { return windowStart.Add(10 * time.Second) }
+	if err := persister.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	cfg := config.AlertConfig{
+		StreamName:         "alerts",
+		ConsumerGroup:      "alert-service",
+		FilteredStreamName: "alerts.filtered",
+		ProcessTimeout:     time.Hour,
+		BatchSize:          10,
+	}
+	consumer := NewConsumer(
+		cfg,
+		redis,
+		NewDeduplicator(redis, time.Hour),
+		NewUserFilter(),
+		persister,
+		NewRouter(redis, cfg.FilteredStreamName, 5*time.Second),
+	)
+	if err := consumer.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := consumer.Shutdown(ctx); err != nil {
+		t.Fatalf("consumer Shutdown() error = %v", err)
+	}
+
+	// Every alert is delivered even though none has been persisted yet
+	if stats := consumer.GetStats(); stats.AlertsRouted != n {
+		t.Errorf("Expected %d routed alerts, got %d", n, stats.AlertsRouted)
+	}
+	if got := inserter.count(); got != 0 {
+		t.Errorf("Expected the open window not yet persisted, got %d rows", got)
+	}
+
+	if err := persister.Shutdown(ctx); err != nil {
+		t.Fatalf("persister Shutdown() error = %v", err)
+	}
+	if got := inserter.count(); got != 1 {
+		t.Fatalf("Expected 1 aggregated row, got %d", got)
+	}
+	if got := inserter.alerts[0].Occurrences; got != n {
+		t.Errorf("Expected %d occurrences, got %d", n, got)
+	}
+}
@@ -0,0 +1,43 @@
+package alert
+
+import (
+	"context"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// RedactingSink delivers alerts to a restricted sink (e.g. a third-party webhook) without
+// internal metadata
+type RedactingSink struct {
+	sink     AlertSink
+	redactor *models.AlertRedactor
+}
+
+// NewRedactingSink wraps a sink so it receives alerts redacted for the restricted scope
+func NewRedactingSink(sink AlertSink, redactor *models.AlertRedactor) *RedactingSink {
+	return &RedactingSink{sink: sink, redactor: redactor}
+}
+
+// Name returns the wrapped sink's name
+func (s *RedactingSink) Name() string {
+	return s.sink.Name()
+}
+
+// Publish delivers redacted copies of the alerts to the wrapped sink
+func (s *RedactingSink) Publish(ctx context.Context, alerts []*models.Alert) error {
+	redacted := make([]*models.Alert, len(alerts))
+	for i, alert := range alerts {
+		redacted[i] = s.redactor.Redact(alert, models.AlertScopeRestricted)
+	}
+	return s.sink.Publish(ctx, redacted)
+}
+
+// SetRestrictedSinks redacts alerts for the named sinks added afterwards; other sinks
+// receive full metadata
+func (r *Router) SetRestrictedSinks(names []string, redactor *models.AlertRedactor) {
+	r.restrictedSinks = make(map[string]bool, len(names))
+	for _, name := range names {
+		r.restrictedSinks[name] = true
+	}
+	r.redactor = redactor
+}
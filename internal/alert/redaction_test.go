@@ -0,0 +1,89 @@
+package alert
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+func TestRouter_RestrictedSinksReceiveRedactedAlerts(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	router := NewRouter(redis, "alerts.filtered", 5*time.Second)
+	router.SetRestrictedSinks([]string{"webhook"}, models.NewAlertRedactor(nil))
+
+	webhook := &channelSink{name: "webhook"}
+	internal := &channelSink{name: "kafka"}
+	router.AddSink(webhook)
+	router.AddSink(internal)
+
+	alert := &models.Alert{
+		ID:      "alert-1",
+		RuleID:  "rule-1",
+		Symbol:  "AAPL",
+		TraceID: "trace-1",
+		Metadata: map[string]interface{}{
+			"metrics":                  map[string]float64{"price": 150.0},
+			models.AlertMetadataUserID: "user-1",
+			"alert_type":               models.AlertTypeEntry,
+		},
+	}
+	if err := router.RouteAlert(context.Background(), alert); err != nil {
+		t.Fatalf("RouteAlert() error = %v", err)
+	}
+
+	if webhook.count() != 1 || internal.count() != 1 {
+		t.Fatalf("Expected both sinks to receive the alert, got %d and %d", webhook.count(), internal.count())
+	}
+
+	redacted := webhook.delivered[0]
+	if _, ok := redacted.Metadata["metrics"]; ok {
+		t.Error("Expected metrics to be redacted for the restricted sink")
+	}
+	if _, ok := redacted.Metadata[models.AlertMetadataUserID]; ok {
+		t.Error("Expected user ID to be redacted for the restricted sink")
+	}
+	if redacted.TraceID != "" {
+		t.Error("Expected trace ID to be redacted for the restricted sink")
+	}
+	if redacted.Metadata["alert_type"] != models.AlertTypeEntry {
+		t.Error("Expected non-sensitive metadata to reach the restricted sink")
+	}
+
+	full := internal.delivered[0]
+	if _, ok := full.Metadata["metrics"]; !ok || full.TraceID != "trace-1" {
+		t.Error("Expected unrestricted sink to receive full metadata")
+	}
+
+	// The filtered stream keeps full metadata for downstream services
+	if _, ok := alert.Metadata["metrics"]; !ok {
+		t.Error("Expected routed alert not to be modified")
+	}
+}
+
+func TestRouter_RestrictedSinkKeepsDeliveryPolicyName(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	router := NewRouter(redis, "alerts.filtered", 5*time.Second)
+	router.SetRestrictedSinks([]string{"webhook"}, models.NewAlertRedactor(nil))
+	webhook := &channelSink{name: "webhook"}
+	router.AddSink(webhook)
+
+	err := router.RouteAlert(context.Background(), &models.Alert{
+		ID:       "alert-1",
+		RuleID:   "rule-1",
+		Symbol:   "AAPL",
+		Delivery: &models.DeliveryPolicy{Mode: models.DeliveryModePrimaryFallback, Primary: "webhook"},
+		Metadata: map[string]interface{}{"metrics": map[string]float64{"price": 150.0}},
+	})
+	if err != nil {
+		t.Fatalf("RouteAlert() error = %v", err)
+	}
+	if webhook.count() != 1 {
+		t.Fatalf("Expected restricted primary sink to deliver 1 alert, got %d", webhook.count())
+	}
+	if _, ok := webhook.delivered[0].Metadata["metrics"]; ok {
+		t.Error("Expected metrics to be redacted for the restricted primary sink")
+	}
+}
@@ -2,7 +2,9 @@ package alert
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
@@ -10,11 +12,19 @@ import (
 	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
 )
 
+// FilteredStreamChannel names the filtered stream (consumed by the WebSocket Gateway) in delivery policies
+const FilteredStreamChannel = "websocket"
+
 // Router routes filtered alerts to the filtered stream for WebSocket Gateway
+// and to any additional sinks (e.g. Kafka) in parallel
 type Router struct {
 	redis           storage.RedisClient
 	filteredStream  string
 	publishTimeout  time.Duration
+	sinks           []AlertSink
+	sinkBreaker     CircuitBreakerConfig // Circuit breaker applied to sinks added after SetSinkCircuitBreaker
+	restrictedSinks map[string]bool       // Sinks receiving redacted alerts (by name)
+	redactor        *models.AlertRedactor
 }
 
 // NewRouter creates a new alert router
@@ -26,12 +36,71 @@ func NewRouter(redis storage.RedisClient, filteredStream string, publishTimeout
 	}
 }
 
+// SetSinkCircuitBreaker wraps sinks added afterwards in a circuit breaker (FailureThreshold 0 = disabled)
+func (r *Router) SetSinkCircuitBreaker(config CircuitBreakerConfig) {
+	r.sinkBreaker = config
+}
+
+// AddSink adds a sink that receives every routed alert alongside the filtered stream
+func (r *Router) AddSink(sink AlertSink) {
+	if r.restrictedSinks[sink.Name()] {
+		sink = NewRedactingSink(sink, r.redactor)
+	}
+	if r.sinkBreaker.FailureThreshold > 0 {
+		sink = NewCircuitBreakerSink(sink, r.sinkBreaker)
+	}
+	r.sinks = append(r.sinks, sink)
+}
+
+// SinkStats returns circuit breaker state for sinks wrapped in a breaker
+func (r *Router) SinkStats() []CircuitBreakerStats {
+	stats := make([]CircuitBreakerStats, 0, len(r.sinks))
+	for _, sink := range r.sinks {
+		if breaker, ok := sink.(*CircuitBreakerSink); ok {
+			stats = append(stats, breaker.Stats())
+		}
+	}
+	return stats
+}
+
+// publishToSinks starts publishing alerts to all sinks; the returned function waits for them.
+// Sink failures are logged and never fail routing to the filtered stream.
+func (r *Router) publishToSinks(ctx context.Context, alerts []*models.Alert) func() {
+	var wg sync.WaitGroup
+	for _, sink := range r.sinks {
+		wg.Add(1)
+		go func(sink AlertSink) {
+			defer wg.Done()
+			if err := sink.Publish(ctx, alerts); errors.Is(err, ErrCircuitOpen) {
+				logger.Debug("Dropped alerts for sink with open circuit breaker",
+					logger.String("sink", sink.Name()),
+					logger.Int("count", len(alerts)),
+				)
+			} else if err != nil {
+				logger.Warn("Failed to publish alerts to sink",
+					logger.ErrorField(err),
+					logger.String("sink", sink.Name()),
+					logger.Int("count", len(alerts)),
+				)
+			}
+		}(sink)
+	}
+	return wg.Wait
+}
+
 // RouteAlert routes a filtered alert to the filtered stream
 func (r *Router) RouteAlert(ctx context.Context, alert *models.Alert) error {
 	// Create context with timeout
 	routeCtx, cancel := context.WithTimeout(ctx, r.publishTimeout)
 	defer cancel()
 
+	if alert.Delivery.IsPrimaryFallback() {
+		return r.deliverWithFallback(routeCtx, alert)
+	}
+
+	waitSinks := r.publishToSinks(routeCtx, []*models.Alert{alert})
+	defer waitSinks()
+
 	// Publish to filtered stream - pass alert object directly, PublishToStream will handle JSON marshaling
 	err := r.redis.PublishToStream(routeCtx, r.filteredStream, "alert", alert)
 	if err != nil {
@@ -58,19 +127,35 @@ func (r *Router) RouteAlerts(ctx context.Context, alerts []*models.Alert) error
 	routeCtx, cancel := context.WithTimeout(ctx, r.publishTimeout)
 	defer cancel()
 
+	// Alerts with a primary channel are delivered individually so fallbacks only fire on failure
+	var fallbackErr error
+	broadcast := make([]*models.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		if !alert.Delivery.IsPrimaryFallback() {
+			broadcast = append(broadcast, alert)
+			continue
+		}
+		if err := r.deliverWithFallback(routeCtx, alert); err != nil && fallbackErr == nil {
+			fallbackErr = err
+		}
+	}
+
 	// Prepare batch messages
 	// Pass alert objects directly - Redis will serialize them correctly
-	messages := make([]map[string]interface{}, 0, len(alerts))
-	for _, alert := range alerts {
+	messages := make([]map[string]interface{}, 0, len(broadcast))
+	for _, alert := range broadcast {
 		messages = append(messages, map[string]interface{}{
 			"alert": alert, // Pass object directly, Redis will serialize
 		})
 	}
 
 	if len(messages) == 0 {
-		return nil
+		return fallbackErr
 	}
 
+	waitSinks := r.publishToSinks(routeCtx, broadcast)
+	defer waitSinks()
+
 	// Publish batch to filtered stream
 	err := r.redis.PublishBatchToStream(routeCtx, r.filteredStream, messages)
 	if err != nil {
@@ -82,6 +167,67 @@ func (r *Router) RouteAlerts(ctx context.Context, alerts []*models.Alert) error
 		logger.String("stream", r.filteredStream),
 	)
 
-	return nil
+	return fallbackErr
+}
+
+// deliverWithFallback delivers an alert to its primary channel, trying the fallback
+// channels in order only while delivery fails
+func (r *Router) deliverWithFallback(ctx context.Context, alert *models.Alert) error {
+	var lastErr error
+	for _, channel := range r.deliveryOrder(alert.Delivery) {
+		err := r.publishToChannel(ctx, channel, alert)
+		if err == nil {
+			logger.Debug("Delivered alert via channel",
+				logger.String("alert_id", alert.ID),
+				logger.String("rule_id", alert.RuleID),
+				logger.String("channel", channel),
+			)
+			return nil
+		}
+		lastErr = err
+		logger.Warn("Failed to deliver alert via channel, trying fallback",
+			logger.ErrorField(err),
+			logger.String("alert_id", alert.ID),
+			logger.String("channel", channel),
+		)
+	}
+	return fmt.Errorf("failed to deliver alert %s on any channel: %w", alert.ID, lastErr)
+}
+
+// deliveryOrder returns the channels tried for a primary/fallback policy: the primary,
+// then the configured fallbacks or, if none are set, every other channel
+func (r *Router) deliveryOrder(policy *models.DeliveryPolicy) []string {
+	order := []string{policy.Primary}
+	if len(policy.Fallbacks) > 0 {
+		return append(order, policy.Fallbacks...)
+	}
+	for _, channel := range r.Channels() {
+		if channel != policy.Primary {
+			order = append(order, channel)
+		}
+	}
+	return order
+}
+
+// Channels returns the names of the channels alerts can be delivered to
+func (r *Router) Channels() []string {
+	channels := []string{FilteredStreamChannel}
+	for _, sink := range r.sinks {
+		channels = append(channels, sink.Name())
+	}
+	return channels
+}
+
+// publishToChannel publishes an alert to a single named channel
+func (r *Router) publishToChannel(ctx context.Context, channel string, alert *models.Alert) error {
+	if channel == FilteredStreamChannel {
+		return r.redis.PublishToStream(ctx, r.filteredStream, "alert", alert)
+	}
+	for _, sink := range r.sinks {
+		if sink.Name() == channel {
+			return sink.Publish(ctx, []*models.Alert{alert})
+		}
+	}
+	return fmt.Errorf("unknown delivery channel: %s", channel)
 }
 
@@ -3,6 +3,8 @@ package alert
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
@@ -99,3 +101,135 @@ func TestRouter_AlertSerialization(t *testing.T) {
 	}
 }
 
+// channelSink records alerts delivered to a named channel, failing while err is set
+type channelSink struct {
+	name      string
+	err       error
+	delivered []*models.Alert
+	mu        sync.Mutex
+}
+
+func (s *channelSink) Name() string { return s.name }
+
+func (s *channelSink) Publish(ctx context.Context, alerts []*models.Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.delivered = append(s.delivered, alerts...)
+	return nil
+}
+
+func (s *channelSink) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.delivered)
+}
+
+func TestRouter_PrimaryFallback_SkipsSecondariesOnSuccess(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	router := NewRouter(redis, "alerts.filtered", 5*time.Second)
+	webhook := &channelSink{name: "webhook"}
+	kafka := &channelSink{name: "kafka"}
+	router.AddSink(webhook)
+	router.AddSink(kafka)
+
+	alert := &models.Alert{
+		ID:       "alert-1",
+		RuleID:   "rule-1",
+		Symbol:   "AAPL",
+		Delivery: &models.DeliveryPolicy{Mode: models.DeliveryModePrimaryFallback, Primary: "webhook"},
+	}
+
+	if err := router.RouteAlert(context.Background(), alert); err != nil {
+		t.Fatalf("RouteAlert() error = %v", err)
+	}
+
+	if webhook.count() != 1 {
+		t.Errorf("Expected primary channel to deliver 1 alert, got %d", webhook.count())
+	}
+	if kafka.count() != 0 || len(redis.StreamData) != 0 {
+		t.Errorf("Expected secondary channels to be skipped, got kafka=%d websocket=%d", kafka.count(), len(redis.StreamData))
+	}
+}
+
+func TestRouter_PrimaryFallback_SecondaryDeliversOnFailure(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	router := NewRouter(redis, "alerts.filtered", 5*time.Second)
+	webhook := &channelSink{name: "webhook", err: errors.New("endpoint unavailable")}
+	kafka := &channelSink{name: "kafka"}
+	router.AddSink(webhook)
+	router.AddSink(kafka)
+
+	alert := &models.Alert{
+		ID:     "alert-1",
+		RuleID: "rule-1",
+		Symbol: "AAPL",
+		Delivery: &models.DeliveryPolicy{
+			Mode:      models.DeliveryModePrimaryFallback,
+			Primary:   "webhook",
+			Fallbacks: []string{"kafka", FilteredStreamChannel},
+		},
+	}
+
+	if err := router.RouteAlert(context.Background(), alert); err != nil {
+		t.Fatalf("RouteAlert() error = %v", err)
+	}
+
+	if kafka.count() != 1 {
+		t.Errorf("Expected first fallback to deliver 1 alert, got %d", kafka.count())
+	}
+	if len(redis.StreamData) != 0 {
+		t.Errorf("Expected later fallbacks to be skipped once one succeeds, got %d", len(redis.StreamData))
+	}
+}
+
+func TestRouter_PrimaryFallback_DefaultFallbacks(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	router := NewRouter(redis, "alerts.filtered", 5*time.Second)
+	kafka := &channelSink{name: "kafka"}
+	router.AddSink(kafka)
+
+	// The WebSocket stream is primary; without fallbacks every other channel is tried in order
+	redis.PublishErr = errors.New("connection refused")
+	alerts := []*models.Alert{
+		{ID: "alert-1", RuleID: "rule-1", Symbol: "AAPL", Delivery: &models.DeliveryPolicy{Mode: models.DeliveryModePrimaryFallback, Primary: FilteredStreamChannel}},
+	}
+
+	if err := router.RouteAlerts(context.Background(), alerts); err != nil {
+		t.Fatalf("RouteAlerts() error = %v", err)
+	}
+	if kafka.count() != 1 {
+		t.Errorf("Expected kafka fallback to deliver 1 alert, got %d", kafka.count())
+	}
+
+	// Every channel failing is reported
+	kafka.err = errors.New("broker down")
+	if err := router.RouteAlerts(context.Background(), alerts); err == nil {
+		t.Error("Expected error when every channel fails")
+	}
+}
+
+func TestRouter_RouteAlerts_MixedDeliveryModes(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	router := NewRouter(redis, "alerts.filtered", 5*time.Second)
+	kafka := &channelSink{name: "kafka"}
+	router.AddSink(kafka)
+
+	alerts := []*models.Alert{
+		{ID: "broadcast", RuleID: "rule-1", Symbol: "AAPL"},
+		{ID: "primary", RuleID: "rule-2", Symbol: "MSFT", Delivery: &models.DeliveryPolicy{Mode: models.DeliveryModePrimaryFallback, Primary: "kafka"}},
+	}
+
+	if err := router.RouteAlerts(context.Background(), alerts); err != nil {
+		t.Fatalf("RouteAlerts() error = %v", err)
+	}
+
+	if kafka.count() != 2 {
+		t.Errorf("Expected kafka to receive both alerts, got %d", kafka.count())
+	}
+	if len(redis.StreamData) != 1 {
+		t.Errorf("Expected only the broadcast alert on the WebSocket stream, got %d", len(redis.StreamData))
+	}
+}
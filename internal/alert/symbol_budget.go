@@ -0,0 +1,95 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Reasons an alert is dropped by the consumer
+const (
+	DropReasonSymbolBudget = "symbol_budget"
+)
+
+// alertsDropped counts alerts dropped by the consumer before delivery, by reason
+var alertsDropped = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "alert_consumer_dropped_total",
+		Help: "Total number of alerts dropped by the alert consumer before delivery",
+	},
+	[]string{"reason"},
+)
+
+// symbolBudgetKeyPrefix prefixes the Redis counters of the per-symbol alert budget
+const symbolBudgetKeyPrefix = "alert:budget"
+
+// SymbolBudget caps the alerts delivered per symbol within fixed time windows, across all
+// rules and users, to protect downstream systems from a single symbol flooding them. With a
+// Redis client the counts are shared by every consumer replica, so the cap is global; without
+// one they are kept in memory and each replica has its own budget.
+type SymbolBudget struct {
+	limit       int
+	window      time.Duration
+	redis       storage.RedisClient // Shared counters (nil = in-memory counts)
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[string]int // Symbol -> alerts allowed in the current window (in-memory only)
+}
+
+// NewSymbolBudget creates a budget of limit alerts per symbol per window, counted in redis
+// when it is set (nil = unlimited)
+func NewSymbolBudget(redis storage.RedisClient, limit int, window time.Duration) *SymbolBudget {
+	if limit <= 0 || window <= 0 {
+		return nil
+	}
+	return &SymbolBudget{
+		limit:  limit,
+		window: window,
+		redis:  redis,
+		counts: make(map[string]int),
+	}
+}
+
+// Allow returns whether an alert for symbol at now fits in the symbol's budget, and if so
+// consumes one unit of it. Budgets reset when the window rolls. If the shared count can't be
+// read the alert is allowed, so a Redis outage never drops alerts. Safe to call on a nil budget.
+func (b *SymbolBudget) Allow(ctx context.Context, symbol string, now time.Time) bool {
+	if b == nil {
+		return true
+	}
+
+	windowStart := now.Truncate(b.window)
+	if b.redis != nil {
+		key := fmt.Sprintf("%s:%s:%d", symbolBudgetKeyPrefix, symbol, windowStart.Unix())
+		count, err := b.redis.IncrWithTTL(ctx, key, b.window)
+		if err != nil {
+			logger.Warn("Failed to count symbol alert budget, allowing alert",
+				logger.ErrorField(err),
+				logger.String("symbol", symbol),
+			)
+			return true
+		}
+		return count <= int64(b.limit)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !windowStart.Equal(b.windowStart) {
+		b.windowStart = windowStart
+		b.counts = make(map[string]int)
+	}
+
+	if b.counts[symbol] >= b.limit {
+		return false
+	}
+	b.counts[symbol]++
+	return true
+}
@@ -0,0 +1,126 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+func TestSymbolBudget_Allow(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	for name, redis := range map[string]storage.RedisClient{
+		"in-memory": nil,
+		"redis":     storage.NewMockRedisClient(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			budget := NewSymbolBudget(redis, 2, time.Minute)
+
+			want := []bool{true, true, false, false}
+			for i, expected := range want {
+				if got := budget.Allow(ctx, "AAPL", start.Add(time.Duration(i)*time.Second)); got != expected {
+					t.Errorf("Alert %d: Allow() = %v, want %v", i+1, got, expected)
+				}
+			}
+
+			// Symbols have independent budgets
+			if !budget.Allow(ctx, "MSFT", start) {
+				t.Error("Expected MSFT to have its own budget")
+			}
+
+			// The budget resets when the window rolls
+			if !budget.Allow(ctx, "AAPL", start.Add(time.Minute)) {
+				t.Error("Expected AAPL budget to reset in the next window")
+			}
+		})
+	}
+
+	// Disabled budgets allow everything
+	var disabled *SymbolBudget
+	if NewSymbolBudget(nil, 0, time.Minute) != nil || !disabled.Allow(ctx, "AAPL", start) {
+		t.Error("Expected a zero limit to disable the budget")
+	}
+}
+
+func TestSymbolBudget_SharedAcrossReplicas(t *testing.T) {
+	ctx := context.Background()
+	redis := storage.NewMockRedisClient()
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	// Two consumer replicas counting in the same Redis share one budget of 3
+	replicas := []*SymbolBudget{NewSymbolBudget(redis, 3, time.Minute), NewSymbolBudget(redis, 3, time.Minute)}
+	allowed := 0
+	for i := 0; i < 6; i++ {
+		if replicas[i%2].Allow(ctx, "AAPL", start.Add(time.Duration(i)*time.Second)) {
+			allowed++
+		}
+	}
+	if allowed != 3 {
+		t.Errorf("Expected 3 alerts allowed across replicas, got %d", allowed)
+	}
+
+	// A Redis failure allows the alert rather than dropping it
+	redis.SetErr = errors.New("connection refused")
+	if !replicas[0].Allow(ctx, "AAPL", start) {
+		t.Error("Expected the alert to be allowed when the budget can't be counted")
+	}
+}
+
+func TestConsumer_SymbolBudgetCapsAcrossRulesAndUsers(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	writer := &mockAlertWriter{}
+	consumer := newTestConsumer(redis, writer)
+	consumer.SetSymbolBudget(NewSymbolBudget(redis, 3, time.Minute))
+
+	now := time.Date(2024, 1, 2, 15, 0, 10, 0, time.UTC)
+	consumer.now = func() time.Time { return now }
+
+	// Five AAPL alerts from different rules and users, plus one MSFT alert
+	var alerts []*models.Alert
+	for i := 0; i < 5; i++ {
+		alerts = append(alerts, &models.Alert{
+			ID:        fmt.Sprintf("alert-%d", i),
+			RuleID:    fmt.Sprintf("rule-%d", i%2),
+			Symbol:    "AAPL",
+			Timestamp: now.Add(time.Duration(i) * time.Second),
+			Metadata:  map[string]interface{}{models.AlertMetadataUserID: fmt.Sprintf("user-%d", i%3)},
+		})
+	}
+	alerts = append(alerts, &models.Alert{ID: "alert-msft", RuleID: "rule-0", Symbol: "MSFT", Timestamp: now})
+	// Exit alerts are delivered over budget and don't spend it
+	alerts = append(alerts, &models.Alert{ID: "alert-exit", RuleID: "rule-0", Symbol: "AAPL", Type: models.AlertTypeExit, Timestamp: now.Add(10 * time.Second)})
+
+	for _, alert := range alerts {
+		ack, err := consumer.processAlert(alert)
+		if err != nil || !ack {
+			t.Fatalf("processAlert(%s) = %v, %v", alert.ID, ack, err)
+		}
+	}
+
+	stats := consumer.GetStats()
+	if stats.AlertsRouted != 5 {
+		t.Errorf("Expected 3 AAPL alerts, 1 AAPL exit and 1 MSFT alert routed, got %d", stats.AlertsRouted)
+	}
+	if stats.AlertsOverBudget != 2 {
+		t.Errorf("Expected 2 alerts dropped over budget, got %d", stats.AlertsOverBudget)
+	}
+	if len(writer.alerts) != 5 {
+		t.Errorf("Expected dropped alerts not to be persisted, got %d persisted", len(writer.alerts))
+	}
+
+	// Once the window rolls, the symbol has a fresh budget
+	now = now.Add(time.Minute)
+	late := &models.Alert{ID: "alert-late", RuleID: "rule-1", Symbol: "AAPL", Timestamp: now}
+	if _, err := consumer.processAlert(late); err != nil {
+		t.Fatalf("processAlert() error = %v", err)
+	}
+	if got := consumer.GetStats().AlertsRouted; got != 6 {
+		t.Errorf("Expected the alert in the next window to be routed, got %d routed", got)
+	}
+}
@@ -0,0 +1,395 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// WebhookSignatureHeader carries "sha256=" followed by the hex HMAC-SHA256 of the request
+	// body, keyed with the endpoint's secret
+	WebhookSignatureHeader = "X-Scanner-Signature"
+
+	// WebhookAlertIDHeader carries the delivered alert's ID, so receivers can drop duplicates
+	WebhookAlertIDHeader = "X-Scanner-Alert-ID"
+)
+
+// Webhook delivery outcomes
+const (
+	WebhookResultDelivered    = "delivered"
+	WebhookResultRetried      = "retried"
+	WebhookResultDeadLettered = "dead_lettered"
+)
+
+// webhookDeliveries counts webhook delivery attempts by outcome
+var webhookDeliveries = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "alert_webhook_deliveries_total",
+		Help: "Total number of webhook alert deliveries by result (delivered, retried, dead_lettered)",
+	},
+	[]string{"result"},
+)
+
+// DefaultWebhookRefreshInterval is how often the user-registered webhooks are reloaded
+const DefaultWebhookRefreshInterval = 30 * time.Second
+
+// WebhookSource lists the webhooks users registered through the API
+type WebhookSource interface {
+	ListWebhooks(ctx context.Context) ([]*models.Webhook, error)
+}
+
+// WebhookEndpoint is an HTTP endpoint receiving alerts of a rule, of a user, or all alerts
+type WebhookEndpoint struct {
+	URL    string `json:"url"`
+	Secret string `json:"-"`                 // HMAC-SHA256 signing secret (empty = unsigned)
+	RuleID string `json:"rule_id,omitempty"` // Only alerts of this rule (empty = any rule)
+	UserID string `json:"user_id,omitempty"` // Only alerts targeted at this user (empty = any alert)
+}
+
+// Matches returns whether the endpoint receives the alert
+func (e WebhookEndpoint) Matches(alert *models.Alert) bool {
+	if e.RuleID != "" && e.RuleID != alert.RuleID {
+		return false
+	}
+	if e.UserID != "" && e.UserID != alert.TargetUserID() {
+		return false
+	}
+	return true
+}
+
+// WebhookConfig configures webhook alert delivery
+type WebhookConfig struct {
+	Timeout          time.Duration // HTTP request timeout (default: 5s)
+	MaxRetries       int           // Retries after the first failed attempt (default: 3, 0 = none)
+	RetryDelay       time.Duration // Delay before the first retry, doubled for each further retry (default: 500ms)
+	MaxRetryDelay    time.Duration // Upper bound for the retry delay (default: 10s)
+	DeadLetterStream string        // Stream undeliverable alerts are written to (empty = logged and dropped)
+}
+
+// DefaultWebhookConfig returns default configuration
+func DefaultWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		Timeout:       5 * time.Second,
+		MaxRetries:    3,
+		RetryDelay:    500 * time.Millisecond,
+		MaxRetryDelay: 10 * time.Second,
+	}
+}
+
+// WebhookDeadLetter is written to the dead-letter stream when an alert cannot be delivered
+type WebhookDeadLetter struct {
+	Alert    *models.Alert `json:"alert"`
+	URL      string        `json:"url"`
+	RuleID   string        `json:"rule_id,omitempty"`
+	UserID   string        `json:"user_id,omitempty"`
+	Attempts int           `json:"attempts"`
+	Error    string        `json:"error"`
+	FailedAt time.Time     `json:"failed_at"`
+}
+
+// WebhookDeliverer POSTs each alert as JSON to the webhooks it matches: the endpoints
+// registered by the operator and those users registered in the webhook source. Failed
+// requests (network errors, 429 and 5xx responses) are retried with exponential backoff;
+// alerts still undelivered are written to the dead-letter stream.
+type WebhookDeliverer struct {
+	client *http.Client
+	config WebhookConfig
+	redis  storage.RedisClient // Dead-letter stream client (optional)
+
+	mu        sync.RWMutex
+	endpoints []WebhookEndpoint
+
+	source   WebhookSource // User-registered webhooks (optional)
+	refresh  time.Duration
+	sourceMu sync.Mutex
+	stored   []WebhookEndpoint // Last loaded user-registered webhooks
+	loadedAt time.Time
+}
+
+// NewWebhookDeliverer creates a webhook alert sink. redis may be nil when no dead-letter
+// stream is configured.
+func NewWebhookDeliverer(config WebhookConfig, redis storage.RedisClient) *WebhookDeliverer {
+	defaults := DefaultWebhookConfig()
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = defaults.RetryDelay
+	}
+	if config.MaxRetryDelay < config.RetryDelay {
+		config.MaxRetryDelay = config.RetryDelay
+	}
+
+	return &WebhookDeliverer{
+		client: &http.Client{Timeout: config.Timeout},
+		config: config,
+		redis:  redis,
+	}
+}
+
+// Register adds a webhook endpoint
+func (d *WebhookDeliverer) Register(endpoint WebhookEndpoint) error {
+	parsed, err := url.Parse(endpoint.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q", endpoint.URL)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.endpoints = append(d.endpoints, endpoint)
+	return nil
+}
+
+// SetWebhookSource delivers to the webhooks users registered as well, reloading them from
+// source at most every refresh (<= 0 = DefaultWebhookRefreshInterval)
+func (d *WebhookDeliverer) SetWebhookSource(source WebhookSource, refresh time.Duration) {
+	if refresh <= 0 {
+		refresh = DefaultWebhookRefreshInterval
+	}
+
+	d.sourceMu.Lock()
+	defer d.sourceMu.Unlock()
+	d.source = source
+	d.refresh = refresh
+	d.stored = nil
+	d.loadedAt = time.Time{}
+}
+
+// Endpoints returns the registered endpoints
+func (d *WebhookDeliverer) Endpoints() []WebhookEndpoint {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return append([]WebhookEndpoint(nil), d.endpoints...)
+}
+
+// storedEndpoints returns the user-registered webhooks, reloading them once the refresh
+// interval has passed. If a reload fails the previously loaded webhooks are used.
+func (d *WebhookDeliverer) storedEndpoints(ctx context.Context) []WebhookEndpoint {
+	d.sourceMu.Lock()
+	defer d.sourceMu.Unlock()
+
+	if d.source == nil || time.Since(d.loadedAt) < d.refresh {
+		return d.stored
+	}
+
+	webhooks, err := d.source.ListWebhooks(ctx)
+	if err != nil {
+		logger.Warn("Failed to load user webhooks, using the previous ones",
+			logger.ErrorField(err),
+			logger.Int("webhooks", len(d.stored)),
+		)
+		return d.stored
+	}
+
+	stored := make([]WebhookEndpoint, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		endpoint := WebhookEndpoint{URL: webhook.URL, Secret: webhook.Secret}
+		// The API only registers rule webhooks for the rule's owner, so a rule webhook gets
+		// every alert of the rule; other webhooks get the alerts addressed to their user
+		if webhook.RuleID != "" {
+			endpoint.RuleID = webhook.RuleID
+		} else {
+			endpoint.UserID = webhook.UserID
+		}
+		stored = append(stored, endpoint)
+	}
+	d.stored = stored
+	d.loadedAt = time.Now()
+	return d.stored
+}
+
+// Name returns the sink name
+func (d *WebhookDeliverer) Name() string {
+	return "webhook"
+}
+
+// Publish delivers every alert to each endpoint it matches, in parallel. Retries stop early
+// when ctx is done (the router bounds delivery by its publish timeout).
+func (d *WebhookDeliverer) Publish(ctx context.Context, alerts []*models.Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+	endpoints := append(d.Endpoints(), d.storedEndpoints(ctx)...)
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	var (
+		wg     sync.WaitGroup
+		errsMu sync.Mutex
+		errs   []error
+	)
+	for _, alert := range alerts {
+		for _, endpoint := range endpoints {
+			if !endpoint.Matches(alert) {
+				continue
+			}
+			wg.Add(1)
+			go func(alert *models.Alert, endpoint WebhookEndpoint) {
+				defer wg.Done()
+				if err := d.deliver(ctx, endpoint, alert); err != nil {
+					errsMu.Lock()
+					errs = append(errs, err)
+					errsMu.Unlock()
+				}
+			}(alert, endpoint)
+		}
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// deliver posts an alert to an endpoint, retrying retryable failures and dead-lettering the
+// alert once retries are exhausted
+func (d *WebhookDeliverer) deliver(ctx context.Context, endpoint WebhookEndpoint, alert *models.Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert %s: %w", alert.ID, err)
+	}
+
+	delay := d.config.RetryDelay
+	attempts := 0
+	for {
+		attempts++
+		retryable, err := d.post(ctx, endpoint, alert.ID, body)
+		if err == nil {
+			webhookDeliveries.WithLabelValues(WebhookResultDelivered).Inc()
+			return nil
+		}
+
+		if !retryable || attempts > d.config.MaxRetries || !d.wait(ctx, delay) {
+			d.deadLetter(endpoint, alert, attempts, err)
+			return fmt.Errorf("failed to deliver alert %s to webhook %s after %d attempts: %w", alert.ID, endpoint.URL, attempts, err)
+		}
+
+		webhookDeliveries.WithLabelValues(WebhookResultRetried).Inc()
+		logger.Debug("Retrying webhook delivery",
+			logger.ErrorField(err),
+			logger.String("alert_id", alert.ID),
+			logger.String("url", endpoint.URL),
+			logger.Int("attempt", attempts),
+		)
+
+		delay *= 2
+		if delay > d.config.MaxRetryDelay {
+			delay = d.config.MaxRetryDelay
+		}
+	}
+}
+
+// post sends a single request; retryable reports whether a failure may succeed on retry
+func (d *WebhookDeliverer) post(ctx context.Context, endpoint WebhookEndpoint, alertID string, body []byte) (retryable bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookAlertIDHeader, alertID)
+	if endpoint.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(endpoint.Secret, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// wait sleeps for delay, returning false if ctx is done first
+func (d *WebhookDeliverer) wait(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// deadLetter records an undeliverable alert in the dead-letter stream
+func (d *WebhookDeliverer) deadLetter(endpoint WebhookEndpoint, alert *models.Alert, attempts int, deliveryErr error) {
+	webhookDeliveries.WithLabelValues(WebhookResultDeadLettered).Inc()
+
+	if d.redis == nil || d.config.DeadLetterStream == "" {
+		logger.Error("Dropped undeliverable webhook alert",
+			logger.ErrorField(deliveryErr),
+			logger.String("alert_id", alert.ID),
+			logger.String("url", endpoint.URL),
+			logger.Int("attempts", attempts),
+		)
+		return
+	}
+
+	entry := WebhookDeadLetter{
+		Alert:    alert,
+		URL:      endpoint.URL,
+		RuleID:   endpoint.RuleID,
+		UserID:   endpoint.UserID,
+		Attempts: attempts,
+		Error:    deliveryErr.Error(),
+		FailedAt: time.Now().UTC(),
+	}
+
+	// The delivery context may already be done, so the dead letter gets its own deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.redis.PublishToStream(ctx, d.config.DeadLetterStream, "dead_letter", entry); err != nil {
+		logger.Error("Failed to write webhook dead letter",
+			logger.ErrorField(err),
+			logger.String("alert_id", alert.ID),
+			logger.String("stream", d.config.DeadLetterStream),
+		)
+		return
+	}
+
+	logger.Warn("Webhook alert dead-lettered",
+		logger.ErrorField(deliveryErr),
+		logger.String("alert_id", alert.ID),
+		logger.String("url", endpoint.URL),
+		logger.Int("attempts", attempts),
+	)
+}
+
+// SignWebhookPayload returns the signature header value for a request body
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature reports whether signature is the valid signature of body for secret
+func VerifyWebhookSignature(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhookPayload(secret, body)), []byte(signature))
+}
@@ -0,0 +1,312 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+// fakeWebhook records webhook requests and answers with queued status codes (200 once exhausted)
+type fakeWebhook struct {
+	mu         sync.Mutex
+	bodies     [][]byte
+	signatures []string
+	alertIDs   []string
+	statuses   []int
+}
+
+func (f *fakeWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	f.bodies = append(f.bodies, body)
+	f.signatures = append(f.signatures, r.Header.Get(WebhookSignatureHeader))
+	f.alertIDs = append(f.alertIDs, r.Header.Get(WebhookAlertIDHeader))
+
+	status := http.StatusOK
+	if len(f.statuses) > 0 {
+		status = f.statuses[0]
+		f.statuses = f.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (f *fakeWebhook) requestCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.bodies)
+}
+
+func newTestWebhookDeliverer(redis storage.RedisClient) *WebhookDeliverer {
+	return NewWebhookDeliverer(WebhookConfig{
+		Timeout:          time.Second,
+		MaxRetries:       2,
+		RetryDelay:       time.Millisecond,
+		DeadLetterStream: "alerts.webhook.dead_letter",
+	}, redis)
+}
+
+func TestWebhookDeliverer_Success(t *testing.T) {
+	hook := &fakeWebhook{}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	deliverer := newTestWebhookDeliverer(nil)
+	if err := deliverer.Register(WebhookEndpoint{URL: server.URL}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	alert := &models.Alert{ID: "alert-1", RuleID: "rule-1", Symbol: "AAPL", Price: 150.25, Timestamp: time.Now()}
+	if err := deliverer.Publish(context.Background(), []*models.Alert{alert}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	if hook.requestCount() != 1 {
+		t.Fatalf("Expected 1 request, got %d", hook.requestCount())
+	}
+	var delivered models.Alert
+	if err := json.Unmarshal(hook.bodies[0], &delivered); err != nil {
+		t.Fatalf("Failed to decode webhook body: %v", err)
+	}
+	if delivered.ID != "alert-1" || delivered.Symbol != "AAPL" || delivered.Price != 150.25 {
+		t.Errorf("Unexpected delivered alert: %+v", delivered)
+	}
+	if hook.alertIDs[0] != "alert-1" {
+		t.Errorf("Expected alert ID header alert-1, got %q", hook.alertIDs[0])
+	}
+	if hook.signatures[0] != "" {
+		t.Errorf("Expected no signature without a secret, got %q", hook.signatures[0])
+	}
+}
+
+func TestWebhookDeliverer_RetriesServerErrors(t *testing.T) {
+	hook := &fakeWebhook{statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway}}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	redis := storage.NewMockRedisClient()
+	deliverer := newTestWebhookDeliverer(redis)
+	deliverer.Register(WebhookEndpoint{URL: server.URL})
+
+	alert := &models.Alert{ID: "alert-1", RuleID: "rule-1", Symbol: "AAPL", Timestamp: time.Now()}
+	if err := deliverer.Publish(context.Background(), []*models.Alert{alert}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	if hook.requestCount() != 3 {
+		t.Errorf("Expected 3 attempts (2 retries), got %d", hook.requestCount())
+	}
+	if len(redis.StreamData) != 0 {
+		t.Errorf("Expected no dead letters, got %d", len(redis.StreamData))
+	}
+}
+
+func TestWebhookDeliverer_DeadLetterAfterRetries(t *testing.T) {
+	hook := &fakeWebhook{statuses: []int{500, 500, 500, 500}}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	redis := storage.NewMockRedisClient()
+	deliverer := newTestWebhookDeliverer(redis)
+	deliverer.Register(WebhookEndpoint{URL: server.URL, RuleID: "rule-1"})
+
+	alert := &models.Alert{ID: "alert-1", RuleID: "rule-1", Symbol: "AAPL", Timestamp: time.Now()}
+	if err := deliverer.Publish(context.Background(), []*models.Alert{alert}); err == nil {
+		t.Fatal("Expected an error once retries are exhausted")
+	}
+
+	if hook.requestCount() != 3 {
+		t.Errorf("Expected 3 attempts, got %d", hook.requestCount())
+	}
+	if len(redis.StreamData) != 1 {
+		t.Fatalf("Expected 1 dead letter, got %d", len(redis.StreamData))
+	}
+	message := redis.StreamData[0]
+	if message.Stream != "alerts.webhook.dead_letter" {
+		t.Errorf("Expected dead letter stream, got %s", message.Stream)
+	}
+	var deadLetter WebhookDeadLetter
+	if err := json.Unmarshal([]byte(message.Values["dead_letter"].(string)), &deadLetter); err != nil {
+		t.Fatalf("Failed to decode dead letter: %v", err)
+	}
+	if deadLetter.Alert.ID != "alert-1" || deadLetter.URL != server.URL || deadLetter.Attempts != 3 || deadLetter.RuleID != "rule-1" {
+		t.Errorf("Unexpected dead letter: %+v", deadLetter)
+	}
+}
+
+func TestWebhookDeliverer_ClientErrorNotRetried(t *testing.T) {
+	hook := &fakeWebhook{statuses: []int{http.StatusBadRequest}}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	redis := storage.NewMockRedisClient()
+	deliverer := newTestWebhookDeliverer(redis)
+	deliverer.Register(WebhookEndpoint{URL: server.URL})
+
+	alert := &models.Alert{ID: "alert-1", RuleID: "rule-1", Symbol: "AAPL", Timestamp: time.Now()}
+	if err := deliverer.Publish(context.Background(), []*models.Alert{alert}); err == nil {
+		t.Fatal("Expected an error for a 400 response")
+	}
+	if hook.requestCount() != 1 {
+		t.Errorf("Expected 1 attempt, got %d", hook.requestCount())
+	}
+	if len(redis.StreamData) != 1 {
+		t.Errorf("Expected 1 dead letter, got %d", len(redis.StreamData))
+	}
+}
+
+func TestWebhookDeliverer_Signature(t *testing.T) {
+	hook := &fakeWebhook{}
+	server := httptest.NewServer(hook)
+	defer server.Close()
+
+	deliverer := newTestWebhookDeliverer(nil)
+	deliverer.Register(WebhookEndpoint{URL: server.URL, Secret: "s3cret"})
+
+	alert := &models.Alert{ID: "alert-1", RuleID: "rule-1", Symbol: "AAPL", Timestamp: time.Now()}
+	if err := deliverer.Publish(context.Background(), []*models.Alert{alert}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	body, signature := hook.bodies[0], hook.signatures[0]
+	if !VerifyWebhookSignature("s3cret", body, signature) {
+		t.Errorf("Expected signature %q to verify", signature)
+	}
+	if VerifyWebhookSignature("other-secret", body, signature) {
+		t.Error("Expected signature not to verify with another secret")
+	}
+	if VerifyWebhookSignature("s3cret", append(body, ' '), signature) {
+		t.Error("Expected signature not to verify for a modified body")
+	}
+}
+
+func TestWebhookDeliverer_RuleAndUserEndpoints(t *testing.T) {
+	ruleHook, userHook := &fakeWebhook{}, &fakeWebhook{}
+	ruleServer, userServer := httptest.NewServer(ruleHook), httptest.NewServer(userHook)
+	defer ruleServer.Close()
+	defer userServer.Close()
+
+	deliverer := newTestWebhookDeliverer(nil)
+	deliverer.Register(WebhookEndpoint{URL: ruleServer.URL, RuleID: "rule-1"})
+	deliverer.Register(WebhookEndpoint{URL: userServer.URL, UserID: "user-1"})
+
+	alerts := []*models.Alert{
+		{ID: "alert-1", RuleID: "rule-1", Symbol: "AAPL", Timestamp: time.Now()},
+		{ID: "alert-2", RuleID: "rule-2", Symbol: "AAPL", Timestamp: time.Now(),
+			Metadata: map[string]interface{}{models.AlertMetadataUserID: "user-1"}},
+		{ID: "alert-3", RuleID: "rule-2", Symbol: "MSFT", Timestamp: time.Now(),
+			Metadata: map[string]interface{}{models.AlertMetadataUserID: "user-2"}},
+	}
+	if err := deliverer.Publish(context.Background(), alerts); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	if ruleHook.requestCount() != 1 || ruleHook.alertIDs[0] != "alert-1" {
+		t.Errorf("Expected rule webhook to receive alert-1 only, got %v", ruleHook.alertIDs)
+	}
+	if userHook.requestCount() != 1 || userHook.alertIDs[0] != "alert-2" {
+		t.Errorf("Expected user webhook to receive alert-2 only, got %v", userHook.alertIDs)
+	}
+
+	if err := deliverer.Register(WebhookEndpoint{URL: "ftp://example.com"}); err == nil {
+		t.Error("Expected an error for a non-HTTP webhook URL")
+	}
+}
+
+func TestWebhookDeliverer_UserWebhookReceivesOwnedRuleAlerts(t *testing.T) {
+	ownerHook, otherHook := &fakeWebhook{}, &fakeWebhook{}
+	ownerServer, otherServer := httptest.NewServer(ownerHook), httptest.NewServer(otherHook)
+	defer ownerServer.Close()
+	defer otherServer.Close()
+
+	// Rule-less webhooks registered through the API by two users
+	deliverer := newTestWebhookDeliverer(nil)
+	deliverer.SetWebhookSource(&fakeWebhookSource{webhooks: []*models.Webhook{
+		{ID: "hook-1", UserID: "user-1", URL: ownerServer.URL},
+		{ID: "hook-2", UserID: "user-2", URL: otherServer.URL},
+	}}, time.Hour)
+
+	alert := scanRuleAlert(t, &models.Rule{
+		ID:         "rule-user-1",
+		UserID:     "user-1",
+		Name:       "Price Above 100",
+		Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+		Enabled:    true,
+	})
+	if err := deliverer.Publish(context.Background(), []*models.Alert{alert}); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	if ownerHook.requestCount() != 1 || ownerHook.alertIDs[0] != alert.ID {
+		t.Errorf("Expected the rule owner's webhook to receive %s, got %v", alert.ID, ownerHook.alertIDs)
+	}
+	if otherHook.requestCount() != 0 {
+		t.Errorf("Expected another user's webhook to receive nothing, got %v", otherHook.alertIDs)
+	}
+}
+
+// fakeWebhookSource serves a fixed list of user webhooks and counts the loads
+type fakeWebhookSource struct {
+	webhooks []*models.Webhook
+	loads    int
+}
+
+func (f *fakeWebhookSource) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	f.loads++
+	return f.webhooks, nil
+}
+
+func TestWebhookDeliverer_WebhookSource(t *testing.T) {
+	ruleHook, userHook := &fakeWebhook{}, &fakeWebhook{}
+	ruleServer, userServer := httptest.NewServer(ruleHook), httptest.NewServer(userHook)
+	defer ruleServer.Close()
+	defer userServer.Close()
+
+	source := &fakeWebhookSource{webhooks: []*models.Webhook{
+		{ID: "hook-1", UserID: "user-1", URL: ruleServer.URL, RuleID: "rule-1", Secret: "rule-secret"},
+		{ID: "hook-2", UserID: "user-2", URL: userServer.URL, Secret: "user-secret"},
+	}}
+	deliverer := newTestWebhookDeliverer(nil)
+	deliverer.SetWebhookSource(source, time.Hour)
+
+	alerts := []*models.Alert{
+		{ID: "alert-1", RuleID: "rule-1", Symbol: "AAPL", Timestamp: time.Now()},
+		{ID: "alert-2", RuleID: "rule-2", Symbol: "AAPL", Timestamp: time.Now(),
+			Metadata: map[string]interface{}{models.AlertMetadataUserID: "user-2"}},
+	}
+	for i := 0; i < 2; i++ {
+		if err := deliverer.Publish(context.Background(), alerts); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	if source.loads != 1 {
+		t.Errorf("Expected webhooks to be loaded once within the refresh interval, got %d loads", source.loads)
+	}
+	if ruleHook.requestCount() != 2 || ruleHook.alertIDs[0] != "alert-1" {
+		t.Errorf("Expected rule webhook to receive alert-1 only, got %v", ruleHook.alertIDs)
+	}
+	if userHook.requestCount() != 2 || userHook.alertIDs[0] != "alert-2" {
+		t.Errorf("Expected user webhook to receive alert-2 only, got %v", userHook.alertIDs)
+	}
+
+	// Each webhook is signed with its own secret
+	if !VerifyWebhookSignature("rule-secret", ruleHook.bodies[0], ruleHook.signatures[0]) {
+		t.Error("Expected rule webhook request to be signed with its secret")
+	}
+	if VerifyWebhookSignature("rule-secret", userHook.bodies[0], userHook.signatures[0]) {
+		t.Error("Expected user webhook request not to verify with another webhook's secret")
+	}
+	if !VerifyWebhookSignature("user-secret", userHook.bodies[0], userHook.signatures[0]) {
+		t.Error("Expected user webhook request to be signed with its secret")
+	}
+}
@@ -2,8 +2,10 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -17,9 +19,12 @@ import (
 
 // RuleHandler handles rule management endpoints
 type RuleHandler struct {
-	ruleStore   rules.RuleStore
-	compiler    *rules.Compiler
-	syncService *rules.RuleSyncService
+	ruleStore     rules.RuleStore
+	compiler      *rules.Compiler
+	syncService   *rules.RuleSyncService
+	limits        rules.RuleLimits
+	evaluator     RuleEvaluator     // Dry-run evaluation against live scanner state (nil = unavailable)
+	disabledRules DisabledRuleStore // Rules disabled automatically by scanner workers (nil = unavailable)
 }
 
 // NewRuleHandler creates a new rule handler
@@ -31,6 +36,17 @@ func NewRuleHandler(ruleStore rules.RuleStore, compiler *rules.Compiler, syncSer
 	}
 }
 
+// SetLimits sets the rule count and complexity limits enforced on create and update
+func (h *RuleHandler) SetLimits(limits rules.RuleLimits) {
+	h.limits = limits
+}
+
+// canManageRule returns whether a user may manage a rule owned by ownerID. Rules without an
+// owner are shared.
+func canManageRule(userID, ownerID string) bool {
+	return ownerID == "" || ownerID == userID
+}
+
 // ListRules handles GET /api/v1/rules
 func (h *RuleHandler) ListRules(w http.ResponseWriter, r *http.Request) {
 	allRules, err := h.ruleStore.GetAllRules()
@@ -62,8 +78,7 @@ func (h *RuleHandler) GetRule(w http.ResponseWriter, r *http.Request) {
 // CreateRule handles POST /api/v1/rules
 func (h *RuleHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
 	var rule models.Rule
-	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeStrictJSON(w, r, &rule) {
 		return
 	}
 
@@ -85,6 +100,20 @@ func (h *RuleHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Enforce complexity and per-user rule count limits
+	if err := h.limits.CheckComplexity(&rule); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.limits.CheckRuleCount(h.ruleStore, &rule); err != nil {
+		if errors.Is(err, rules.ErrTooManyRules) {
+			respondWithError(w, http.StatusForbidden, err.Error())
+		} else {
+			respondWithError(w, http.StatusInternalServerError, "Failed to check rule limits")
+		}
+		return
+	}
+
 	// Try to compile rule to ensure it's valid
 	_, err := h.compiler.CompileRule(&rule)
 	if err != nil {
@@ -101,7 +130,7 @@ func (h *RuleHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
 	// Sync to Redis if sync service is available
 	if h.syncService != nil {
 		if err := h.syncService.SyncRule(rule.ID); err != nil {
-			logger.Warn("Failed to sync rule to Redis",
+			logger.WithContext(r.Context()).Warn("Failed to sync rule to Redis",
 				logger.ErrorField(err),
 				logger.String("rule_id", rule.ID),
 			)
@@ -109,7 +138,7 @@ func (h *RuleHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	logger.Info("Rule created",
+	logger.WithContext(r.Context()).Info("Rule created",
 		logger.String("rule_id", rule.ID),
 		logger.String("rule_name", rule.Name),
 	)
@@ -130,8 +159,7 @@ func (h *RuleHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var rule models.Rule
-	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeStrictJSON(w, r, &rule) {
 		return
 	}
 
@@ -146,6 +174,12 @@ func (h *RuleHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Enforce complexity limits
+	if err := h.limits.CheckComplexity(&rule); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Try to compile rule to ensure it's valid
 	_, err = h.compiler.CompileRule(&rule)
 	if err != nil {
@@ -162,7 +196,7 @@ func (h *RuleHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
 	// Sync to Redis if sync service is available
 	if h.syncService != nil {
 		if err := h.syncService.SyncRule(rule.ID); err != nil {
-			logger.Warn("Failed to sync rule to Redis",
+			logger.WithContext(r.Context()).Warn("Failed to sync rule to Redis",
 				logger.ErrorField(err),
 				logger.String("rule_id", rule.ID),
 			)
@@ -170,7 +204,7 @@ func (h *RuleHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	logger.Info("Rule updated",
+	logger.WithContext(r.Context()).Info("Rule updated",
 		logger.String("rule_id", rule.ID),
 		logger.String("rule_name", rule.Name),
 	)
@@ -191,7 +225,7 @@ func (h *RuleHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
 	// Remove from Redis if sync service is available
 	if h.syncService != nil {
 		if err := h.syncService.DeleteRuleFromRedis(ruleID); err != nil {
-			logger.Warn("Failed to delete rule from Redis",
+			logger.WithContext(r.Context()).Warn("Failed to delete rule from Redis",
 				logger.ErrorField(err),
 				logger.String("rule_id", ruleID),
 			)
@@ -199,7 +233,7 @@ func (h *RuleHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	logger.Info("Rule deleted",
+	logger.WithContext(r.Context()).Info("Rule deleted",
 		logger.String("rule_id", ruleID),
 	)
 
@@ -323,9 +357,356 @@ func (h *AlertHandler) GetAlert(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, alert)
 }
 
+// ListSymbolAlerts handles GET /api/v1/symbols/:symbol/alerts
+// Returns the symbol's most recent alerts, newest first
+func (h *AlertHandler) ListSymbolAlerts(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	symbol := strings.ToUpper(vars["symbol"])
+
+	filter := storage.AlertFilter{
+		Symbol: symbol,
+		Limit:  100, // Default limit
+	}
+
+	// Parse limit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if limit, err := parseInt(limitStr); err == nil && limit > 0 && limit <= 1000 {
+			filter.Limit = limit
+		}
+	}
+
+	alerts, err := h.alertStorage.GetAlerts(r.Context(), filter)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve alerts")
+		return
+	}
+
+	// Storage returns newest first; keep that order regardless of backend
+	sort.SliceStable(alerts, func(i, j int) bool {
+		return alerts[i].Timestamp.After(alerts[j].Timestamp)
+	})
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"symbol": symbol,
+		"alerts": alerts,
+		"count":  len(alerts),
+		"limit":  filter.Limit,
+	})
+}
+
+// TestAlertHandler handles synthetic test alerts used to verify delivery
+type TestAlertHandler struct {
+	redis       storage.RedisClient
+	alertStream string
+}
+
+// NewTestAlertHandler creates a new test alert handler
+// Test alerts are published to the raw alert stream so they go through the real pipeline
+func NewTestAlertHandler(redis storage.RedisClient, alertStream string) *TestAlertHandler {
+	return &TestAlertHandler{
+		redis:       redis,
+		alertStream: alertStream,
+	}
+}
+
+// TestAlertRequest is the optional request body for POST /api/v1/alerts/test
+type TestAlertRequest struct {
+	Symbol  string  `json:"symbol,omitempty"`
+	Price   float64 `json:"price,omitempty"`
+	Message string  `json:"message,omitempty"`
+}
+
+// SendTestAlert handles POST /api/v1/alerts/test
+func (h *TestAlertHandler) SendTestAlert(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+
+	var req TestAlertRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	if req.Symbol == "" {
+		req.Symbol = "TEST"
+	}
+	if req.Message == "" {
+		req.Message = fmt.Sprintf("Test alert for %s", userID)
+	}
+
+	alert := &models.Alert{
+		ID:        "test-" + uuid.New().String(),
+		RuleID:    "test",
+		RuleName:  "Test Alert",
+		Symbol:    strings.ToUpper(req.Symbol),
+		Timestamp: time.Now(),
+		Price:     req.Price,
+		Message:   req.Message,
+		Metadata: map[string]interface{}{
+			models.AlertMetadataTest:   true,
+			models.AlertMetadataUserID: userID,
+		},
+	}
+
+	if err := h.redis.PublishToStream(r.Context(), h.alertStream, "alert", alert); err != nil {
+		logger.WithContext(r.Context()).Error("Failed to publish test alert",
+			logger.ErrorField(err),
+			logger.String("user_id", userID),
+		)
+		respondWithError(w, http.StatusInternalServerError, "Failed to send test alert")
+		return
+	}
+
+	logger.WithContext(r.Context()).Info("Test alert sent",
+		logger.String("alert_id", alert.ID),
+		logger.String("user_id", userID),
+	)
+
+	respondWithJSON(w, http.StatusAccepted, alert)
+}
+
+// AlertNoteHandler handles alert note endpoints
+type AlertNoteHandler struct {
+	noteStorage  storage.AlertNoteStorage
+	alertStorage storage.AlertStorage
+	redis        storage.RedisClient
+}
+
+// NewAlertNoteHandler creates a new alert note handler
+// New notes are announced on the alert notes pub/sub channel for WebSocket delivery
+func NewAlertNoteHandler(noteStorage storage.AlertNoteStorage, alertStorage storage.AlertStorage, redis storage.RedisClient) *AlertNoteHandler {
+	return &AlertNoteHandler{
+		noteStorage:  noteStorage,
+		alertStorage: alertStorage,
+		redis:        redis,
+	}
+}
+
+// AddNoteRequest is the request body for POST /api/v1/alerts/:id/notes
+type AddNoteRequest struct {
+	Text string `json:"text"`
+}
+
+// AddNote handles POST /api/v1/alerts/:id/notes
+func (h *AlertNoteHandler) AddNote(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	alertID := vars["id"]
+
+	author := getUserID(r)
+
+	var req AddNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	alert, err := h.alertStorage.GetAlert(r.Context(), alertID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve alert")
+		return
+	}
+	if alert == nil {
+		respondWithError(w, http.StatusNotFound, "Alert not found")
+		return
+	}
+
+	note := &models.AlertNote{
+		ID:        uuid.New().String(),
+		AlertID:   alertID,
+		Author:    author,
+		Text:      strings.TrimSpace(req.Text),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := note.Validate(); err != nil {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("Invalid note: %v", err))
+		return
+	}
+
+	if err := h.noteStorage.AddNote(r.Context(), note); err != nil {
+		logger.WithContext(r.Context()).Error("Failed to add alert note",
+			logger.ErrorField(err),
+			logger.String("alert_id", alertID),
+		)
+		respondWithError(w, http.StatusInternalServerError, "Failed to add note")
+		return
+	}
+
+	// Announce the note to WebSocket subscribers (note is already stored)
+	if h.redis != nil {
+		if err := h.redis.Publish(r.Context(), models.AlertNotesChannel, note); err != nil {
+			logger.WithContext(r.Context()).Warn("Failed to publish alert note",
+				logger.ErrorField(err),
+				logger.String("alert_id", alertID),
+				logger.String("note_id", note.ID),
+			)
+		}
+	}
+
+	respondWithJSON(w, http.StatusCreated, note)
+}
+
+// ListNotes handles GET /api/v1/alerts/:id/notes
+func (h *AlertNoteHandler) ListNotes(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	alertID := vars["id"]
+
+	notes, err := h.noteStorage.GetNotes(r.Context(), alertID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve notes")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"notes": notes,
+		"count": len(notes),
+	})
+}
+
+// AdminHandler handles admin endpoints
+type AdminHandler struct {
+	hypertables storage.HypertableSettingsReader
+	policies    []storage.HypertablePolicy
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(hypertables storage.HypertableSettingsReader, policies []storage.HypertablePolicy) *AdminHandler {
+	return &AdminHandler{
+		hypertables: hypertables,
+		policies:    policies,
+	}
+}
+
+// hypertablePolicyResponse is the JSON form of a configured hypertable policy
+type hypertablePolicyResponse struct {
+	Table         string `json:"table"`
+	ChunkInterval string `json:"chunk_interval"`
+	CompressAfter string `json:"compress_after"`
+	SegmentBy     string `json:"segment_by,omitempty"`
+	OrderBy       string `json:"order_by,omitempty"`
+}
+
+// GetStorageSettings handles GET /api/v1/admin/storage
+func (h *AdminHandler) GetStorageSettings(w http.ResponseWriter, r *http.Request) {
+	tables := make([]string, 0, len(h.policies))
+	configured := make([]hypertablePolicyResponse, 0, len(h.policies))
+	for _, policy := range h.policies {
+		tables = append(tables, policy.Table)
+		configured = append(configured, hypertablePolicyResponse{
+			Table:         policy.Table,
+			ChunkInterval: policy.ChunkInterval.String(),
+			CompressAfter: policy.CompressAfter.String(),
+			SegmentBy:     policy.SegmentBy,
+			OrderBy:       policy.OrderBy,
+		})
+	}
+
+	settings, err := h.hypertables.GetSettings(r.Context(), tables)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get storage settings: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"hypertables": settings,
+		"configured":  configured,
+	})
+}
+
+// MuteHandler handles the symbol mute kill switch endpoints
+type MuteHandler struct {
+	muteStore *storage.SymbolMuteStore
+}
+
+// NewMuteHandler creates a new mute handler
+func NewMuteHandler(muteStore *storage.SymbolMuteStore) *MuteHandler {
+	return &MuteHandler{
+		muteStore: muteStore,
+	}
+}
+
+// muteRequest is the optional body of a mute request
+type muteRequest struct {
+	Reason string `json:"reason"`
+	TTL    string `json:"ttl"` // Duration, e.g. "30m"; empty mutes until unmuted
+}
+
+// MuteSymbol handles POST /api/v1/admin/mute/:symbol
+func (h *MuteHandler) MuteSymbol(w http.ResponseWriter, r *http.Request) {
+	symbol := mux.Vars(r)["symbol"]
+
+	var req muteRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		parsed, err := time.ParseDuration(req.TTL)
+		if err != nil || parsed <= 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid ttl: must be a positive duration (e.g. 30m)")
+			return
+		}
+		ttl = parsed
+	}
+
+	mute, err := h.muteStore.Mute(r.Context(), symbol, req.Reason, ttl)
+	if err == models.ErrInvalidSymbol {
+		respondWithError(w, http.StatusBadRequest, "Invalid symbol")
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to mute symbol: "+err.Error())
+		return
+	}
+
+	logger.Info("Muted symbol",
+		logger.String("symbol", mute.Symbol),
+		logger.String("reason", mute.Reason),
+		logger.Duration("ttl", ttl),
+	)
+
+	respondWithJSON(w, http.StatusOK, mute)
+}
+
+// UnmuteSymbol handles DELETE /api/v1/admin/mute/:symbol
+func (h *MuteHandler) UnmuteSymbol(w http.ResponseWriter, r *http.Request) {
+	symbol := mux.Vars(r)["symbol"]
+
+	if err := h.muteStore.Unmute(r.Context(), symbol); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to unmute symbol: "+err.Error())
+		return
+	}
+
+	logger.Info("Unmuted symbol",
+		logger.String("symbol", strings.ToUpper(symbol)),
+	)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListMutes handles GET /api/v1/admin/mute
+func (h *MuteHandler) ListMutes(w http.ResponseWriter, r *http.Request) {
+	mutes, err := h.muteStore.ListMutes(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list mutes: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"mutes": mutes,
+		"count": len(mutes),
+	})
+}
+
 // SymbolHandler handles symbol management endpoints
 type SymbolHandler struct {
-	symbols []string // MVP: hardcoded list, in production this would come from database
+	symbols  []string                     // Configured symbols, used until a universe is stored
+	universe *storage.SymbolUniverseStore // Runtime symbol universe (nil = configured symbols only)
 }
 
 // NewSymbolHandler creates a new symbol handler
@@ -335,17 +716,41 @@ func NewSymbolHandler(symbols []string) *SymbolHandler {
 	}
 }
 
+// SetUniverseStore sets the store of the runtime symbol universe, enabling PUT /api/v1/symbols
+func (h *SymbolHandler) SetUniverseStore(universe *storage.SymbolUniverseStore) {
+	h.universe = universe
+}
+
+// currentSymbols returns the stored symbol universe, or the configured symbols if none is set
+func (h *SymbolHandler) currentSymbols(r *http.Request) []string {
+	if h.universe == nil {
+		return h.symbols
+	}
+	symbols, err := h.universe.GetUniverse(r.Context())
+	if err != nil {
+		logger.WithContext(r.Context()).Warn("Failed to get symbol universe, using configured symbols",
+			logger.ErrorField(err),
+		)
+		return h.symbols
+	}
+	if len(symbols) == 0 {
+		return h.symbols
+	}
+	return symbols
+}
+
 // ListSymbols handles GET /api/v1/symbols
 func (h *SymbolHandler) ListSymbols(w http.ResponseWriter, r *http.Request) {
 	search := r.URL.Query().Get("search")
+	symbols := h.currentSymbols(r)
 	
 	var filtered []string
 	if search == "" {
-		filtered = h.symbols
+		filtered = symbols
 	} else {
 		// Simple case-insensitive search
 		searchLower := strings.ToLower(search)
-		for _, symbol := range h.symbols {
+		for _, symbol := range symbols {
 			if strings.Contains(strings.ToLower(symbol), searchLower) {
 				filtered = append(filtered, symbol)
 			}
@@ -364,7 +769,7 @@ func (h *SymbolHandler) GetSymbol(w http.ResponseWriter, r *http.Request) {
 	symbol := vars["symbol"]
 
 	// Check if symbol exists
-	for _, s := range h.symbols {
+	for _, s := range h.currentSymbols(r) {
 		if s == symbol {
 			respondWithJSON(w, http.StatusOK, map[string]interface{}{
 				"symbol": symbol,
@@ -376,14 +781,206 @@ func (h *SymbolHandler) GetSymbol(w http.ResponseWriter, r *http.Request) {
 	respondWithError(w, http.StatusNotFound, "Symbol not found")
 }
 
+// updateSymbolsRequest is the body of PUT /api/v1/symbols
+type updateSymbolsRequest struct {
+	Symbols []string `json:"symbols"`
+}
+
+// UpdateSymbols handles PUT /api/v1/symbols, replacing the symbol universe. The ingest
+// service and scanner workers are notified and pick up the change without a restart.
+func (h *SymbolHandler) UpdateSymbols(w http.ResponseWriter, r *http.Request) {
+	if h.universe == nil {
+		respondWithError(w, http.StatusServiceUnavailable, "Symbol universe updates unavailable")
+		return
+	}
+
+	var req updateSymbolsRequest
+	if !decodeStrictJSON(w, r, &req) {
+		return
+	}
+
+	update, err := h.universe.SetUniverse(r.Context(), req.Symbols)
+	if errors.Is(err, models.ErrInvalidSymbol) || errors.Is(err, models.ErrEmptySymbolUniverse) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update symbols: "+err.Error())
+		return
+	}
+
+	logger.WithContext(r.Context()).Info("Updated symbol universe",
+		logger.Int("count", len(update.Symbols)),
+		logger.Int("added", len(update.Added)),
+		logger.Int("removed", len(update.Removed)),
+	)
+
+	respondWithJSON(w, http.StatusOK, update)
+}
+
+// defaultIndicatorHistoryWindow is how far back indicator and scan stats history is read when "from" is omitted
+const defaultIndicatorHistoryWindow = 24 * time.Hour
+
+// IndicatorHandler handles historical indicator endpoints
+type IndicatorHandler struct {
+	indicatorStorage storage.IndicatorStorage
+	now              func() time.Time
+}
+
+// NewIndicatorHandler creates a new indicator handler
+func NewIndicatorHandler(indicatorStorage storage.IndicatorStorage) *IndicatorHandler {
+	return &IndicatorHandler{
+		indicatorStorage: indicatorStorage,
+		now:              time.Now,
+	}
+}
+
+// GetIndicators handles GET /api/v1/indicators/:symbol?name=&from=&to=&limit=
+// Returns persisted indicator values oldest first; the range defaults to the last 24 hours
+func (h *IndicatorHandler) GetIndicators(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	filter := storage.IndicatorFilter{
+		Symbol:  strings.ToUpper(vars["symbol"]),
+		Name:    r.URL.Query().Get("name"),
+		EndTime: h.now(),
+		Limit:   1000, // Default limit
+	}
+
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		to, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid to: must be an RFC3339 timestamp")
+			return
+		}
+		filter.EndTime = to
+	}
+
+	filter.StartTime = filter.EndTime.Add(-defaultIndicatorHistoryWindow)
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		from, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid from: must be an RFC3339 timestamp")
+			return
+		}
+		filter.StartTime = from
+	}
+
+	if filter.StartTime.After(filter.EndTime) {
+		respondWithError(w, http.StatusBadRequest, "Invalid range: from must not be after to")
+		return
+	}
+
+	// Parse limit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if limit, err := parseInt(limitStr); err == nil && limit > 0 && limit <= 10000 {
+			filter.Limit = limit
+		}
+	}
+
+	values, err := h.indicatorStorage.GetIndicators(r.Context(), filter)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve indicators")
+		return
+	}
+	if values == nil {
+		values = []*models.IndicatorValue{}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"symbol":     filter.Symbol,
+		"indicators": values,
+		"count":      len(values),
+		"from":       filter.StartTime,
+		"to":         filter.EndTime,
+		"limit":      filter.Limit,
+	})
+}
+
+// ScanStatsHandler handles scan statistics history endpoints
+type ScanStatsHandler struct {
+	scanStatsStorage storage.ScanStatsStorage
+	now              func() time.Time
+}
+
+// NewScanStatsHandler creates a new scan statistics handler
+func NewScanStatsHandler(scanStatsStorage storage.ScanStatsStorage) *ScanStatsHandler {
+	return &ScanStatsHandler{
+		scanStatsStorage: scanStatsStorage,
+		now:              time.Now,
+	}
+}
+
+// GetScanStats handles GET /api/v1/scan-stats?worker=&from=&to=&limit=
+// Returns persisted scan loop samples oldest first; the range defaults to the last 24 hours
+func (h *ScanStatsHandler) GetScanStats(w http.ResponseWriter, r *http.Request) {
+	filter := storage.ScanStatsFilter{
+		WorkerID: r.URL.Query().Get("worker"),
+		EndTime:  h.now(),
+		Limit:    1000, // Default limit
+	}
+
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		to, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid to: must be an RFC3339 timestamp")
+			return
+		}
+		filter.EndTime = to
+	}
+
+	filter.StartTime = filter.EndTime.Add(-defaultIndicatorHistoryWindow)
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		from, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid from: must be an RFC3339 timestamp")
+			return
+		}
+		filter.StartTime = from
+	}
+
+	if filter.StartTime.After(filter.EndTime) {
+		respondWithError(w, http.StatusBadRequest, "Invalid range: from must not be after to")
+		return
+	}
+
+	// Parse limit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if limit, err := parseInt(limitStr); err == nil && limit > 0 && limit <= 10000 {
+			filter.Limit = limit
+		}
+	}
+
+	samples, err := h.scanStatsStorage.GetScanStats(r.Context(), filter)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve scan stats")
+		return
+	}
+	if samples == nil {
+		samples = []*models.ScanStatsSample{}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"worker":  filter.WorkerID,
+		"samples": samples,
+		"count":   len(samples),
+		"from":    filter.StartTime,
+		"to":      filter.EndTime,
+		"limit":   filter.Limit,
+	})
+}
+
 // UserHandler handles user management endpoints
 type UserHandler struct {
-	// MVP: No user storage, just return default user info
+	// MVP: No profile storage, just return default user info
+	preferences *storage.UserPreferencesStore
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler() *UserHandler {
-	return &UserHandler{}
+func NewUserHandler(preferences *storage.UserPreferencesStore) *UserHandler {
+	return &UserHandler{
+		preferences: preferences,
+	}
 }
 
 // GetProfile handles GET /api/v1/user/profile
@@ -424,6 +1021,150 @@ func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetPreferences handles GET /api/v1/user/preferences
+func (h *UserHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+
+	prefs, err := h.preferences.GetPreferences(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get preferences: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, prefs)
+}
+
+// UpdatePreferences handles PUT /api/v1/user/preferences, replacing the user's preferences
+func (h *UserHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+
+	var prefs models.UserPreferences
+	if err := json.NewDecoder(r.Body).Decode(&prefs); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	prefs.UserID = userID // Users can only update their own preferences
+
+	if err := h.preferences.SetPreferences(r.Context(), &prefs); err != nil {
+		if errors.Is(err, models.ErrInvalidUserPreferences) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to update preferences: "+err.Error())
+		return
+	}
+
+	logger.Info("Updated user preferences",
+		logger.String("user_id", userID),
+	)
+
+	respondWithJSON(w, http.StatusOK, &prefs)
+}
+
+// CustomMetricHandler handles user custom metric endpoints. Definitions are stored in Redis,
+// where scanner workers load them at every rule reload.
+type CustomMetricHandler struct {
+	store         *storage.CustomMetricStore
+	systemMetrics []string // Names custom metrics cannot redefine
+}
+
+// NewCustomMetricHandler creates a new custom metric handler
+func NewCustomMetricHandler(store *storage.CustomMetricStore, systemMetrics []string) *CustomMetricHandler {
+	return &CustomMetricHandler{
+		store:         store,
+		systemMetrics: systemMetrics,
+	}
+}
+
+// ListCustomMetrics handles GET /api/v1/metrics/custom, listing the user's custom metrics
+func (h *CustomMetricHandler) ListCustomMetrics(w http.ResponseWriter, r *http.Request) {
+	metrics, err := h.store.ListUserMetrics(r.Context(), getUserID(r))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list custom metrics: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"metrics": metrics,
+		"count":   len(metrics),
+	})
+}
+
+// SaveCustomMetric handles PUT /api/v1/metrics/custom/{name}, creating or replacing one of
+// the user's custom metrics
+func (h *CustomMetricHandler) SaveCustomMetric(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+	name := mux.Vars(r)["name"]
+
+	var body struct {
+		Description string `json:"description"`
+		Expression  string `json:"expression"`
+	}
+	if !decodeStrictJSON(w, r, &body) {
+		return
+	}
+	metric := &models.CustomMetric{
+		UserID:      userID,
+		Name:        name,
+		Description: body.Description,
+		Expression:  body.Expression,
+	}
+
+	// Validate against the system metrics and the user's other custom metrics
+	existing, err := h.store.ListUserMetrics(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list custom metrics: "+err.Error())
+		return
+	}
+	others := make([]*models.CustomMetric, 0, len(existing))
+	for _, other := range existing {
+		if other.Name != name {
+			others = append(others, other)
+		}
+	}
+	registry := rules.NewCustomMetricRegistry(h.systemMetrics)
+	_ = registry.Load(others)
+	if err := registry.Register(metric); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.store.SaveMetric(r.Context(), metric); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to save custom metric: "+err.Error())
+		return
+	}
+
+	logger.Info("Saved custom metric",
+		logger.String("user_id", userID),
+		logger.String("name", name),
+	)
+
+	respondWithJSON(w, http.StatusOK, metric)
+}
+
+// DeleteCustomMetric handles DELETE /api/v1/metrics/custom/{name}
+func (h *CustomMetricHandler) DeleteCustomMetric(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+	name := mux.Vars(r)["name"]
+
+	existing, err := h.store.GetMetric(r.Context(), userID, name)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get custom metric: "+err.Error())
+		return
+	}
+	if existing == nil {
+		respondWithError(w, http.StatusNotFound, "Custom metric not found: "+name)
+		return
+	}
+
+	if err := h.store.DeleteMetric(r.Context(), userID, name); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete custom metric: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Custom metric deleted"})
+}
+
 // Helper functions
 
 func parseInt(s string) (int, error) {
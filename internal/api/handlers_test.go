@@ -2,9 +2,11 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -373,6 +375,61 @@ func TestAlertHandler_ListAlerts_WithFilter(t *testing.T) {
 	}
 }
 
+func TestAlertHandler_ListSymbolAlerts(t *testing.T) {
+	alertStorage := &storage.MockAlertStorage{}
+	handler := NewAlertHandler(alertStorage)
+
+	now := time.Now()
+	alerts := []*models.Alert{
+		{ID: "alert-1", RuleID: "rule-1", Symbol: "AAPL", Timestamp: now.Add(-3 * time.Minute), Price: 150.0},
+		{ID: "alert-2", RuleID: "rule-1", Symbol: "MSFT", Timestamp: now.Add(-2 * time.Minute), Price: 200.0},
+		{ID: "alert-3", RuleID: "rule-2", Symbol: "AAPL", Timestamp: now.Add(-1 * time.Minute), Price: 151.0},
+		{ID: "alert-4", RuleID: "rule-3", Symbol: "AAPL", Timestamp: now.Add(-5 * time.Minute), Price: 149.0},
+	}
+	alertStorage.WriteAlerts(nil, alerts)
+
+	req := httptest.NewRequest("GET", "/api/v1/symbols/aapl/alerts?limit=10", nil)
+	req = mux.SetURLVars(req, map[string]string{"symbol": "aapl"})
+	w := httptest.NewRecorder()
+
+	handler.ListSymbolAlerts(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response struct {
+		Symbol string          `json:"symbol"`
+		Alerts []*models.Alert `json:"alerts"`
+		Count  int             `json:"count"`
+		Limit  int             `json:"limit"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Symbol != "AAPL" {
+		t.Errorf("Expected symbol AAPL, got %s", response.Symbol)
+	}
+	if response.Count != 3 || response.Limit != 10 {
+		t.Errorf("Expected count 3 and limit 10, got count %d and limit %d", response.Count, response.Limit)
+	}
+
+	// Only AAPL alerts, newest first
+	want := []string{"alert-3", "alert-1", "alert-4"}
+	if len(response.Alerts) != len(want) {
+		t.Fatalf("Expected %d alerts, got %d", len(want), len(response.Alerts))
+	}
+	for i, alert := range response.Alerts {
+		if alert.Symbol != "AAPL" {
+			t.Errorf("Expected only AAPL alerts, got %s", alert.Symbol)
+		}
+		if alert.ID != want[i] {
+			t.Errorf("Expected alert %d to be %s, got %s", i, want[i], alert.ID)
+		}
+	}
+}
+
 func TestSymbolHandler_ListSymbols(t *testing.T) {
 	symbols := []string{"AAPL", "MSFT", "GOOGL"}
 	handler := NewSymbolHandler(symbols)
@@ -429,8 +486,193 @@ func TestSymbolHandler_ListSymbols_WithSearch(t *testing.T) {
 	}
 }
 
+func TestSymbolHandler_UpdateSymbols(t *testing.T) {
+	handler := NewSymbolHandler([]string{"AAPL", "MSFT"})
+
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PUT", "/api/v1/symbols", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		handler.UpdateSymbols(w, req)
+		return w
+	}
+
+	// Without a universe store the universe is fixed
+	if w := put(`{"symbols": ["AAPL"]}`); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	redisClient := storage.NewMockRedisClient()
+	handler.SetUniverseStore(storage.NewSymbolUniverseStore(redisClient))
+
+	w := put(`{"symbols": ["aapl", "NVDA"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var update models.SymbolUniverseUpdate
+	if err := json.Unmarshal(w.Body.Bytes(), &update); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(update.Symbols) != 2 || update.Symbols[0] != "AAPL" || update.Symbols[1] != "NVDA" {
+		t.Errorf("Expected AAPL and NVDA, got %v", update.Symbols)
+	}
+	if len(redisClient.Published) != 1 || redisClient.Published[0].Channel != models.SymbolUniverseChannel {
+		t.Errorf("Expected the update to be announced, got %+v", redisClient.Published)
+	}
+
+	// Reads follow the stored universe
+	req := httptest.NewRequest("GET", "/api/v1/symbols/NVDA", nil)
+	req = mux.SetURLVars(req, map[string]string{"symbol": "NVDA"})
+	w = httptest.NewRecorder()
+	handler.GetSymbol(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected NVDA to be found, got status %d", w.Code)
+	}
+
+	for name, body := range map[string]string{
+		"empty universe": `{"symbols": []}`,
+		"empty symbol":   `{"symbols": ["AAPL", ""]}`,
+		"unknown field":  `{"symbols": ["AAPL"], "extra": true}`,
+	} {
+		if w := put(body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", name, http.StatusBadRequest, w.Code)
+		}
+	}
+}
+
+func TestIndicatorHandler_GetIndicators(t *testing.T) {
+	base := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	indicatorStorage := &storage.MockIndicatorStorage{
+		Values: []*models.IndicatorValue{
+			{Symbol: "AAPL", Timestamp: base.Add(time.Minute), Name: "rsi_14", Value: 55.5},
+			{Symbol: "AAPL", Timestamp: base.Add(time.Minute), Name: "ema_20", Value: 150.2},
+			{Symbol: "AAPL", Timestamp: base.Add(2 * time.Minute), Name: "rsi_14", Value: 61.0},
+			{Symbol: "MSFT", Timestamp: base.Add(time.Minute), Name: "rsi_14", Value: 40.0},
+			{Symbol: "AAPL", Timestamp: base.Add(2 * time.Hour), Name: "rsi_14", Value: 70.0},
+		},
+	}
+	handler := NewIndicatorHandler(indicatorStorage)
+
+	req := httptest.NewRequest("GET", "/api/v1/indicators/aapl?name=rsi_14&from=2024-01-02T14:00:00Z&to=2024-01-02T15:00:00Z", nil)
+	req = mux.SetURLVars(req, map[string]string{"symbol": "aapl"})
+	w := httptest.NewRecorder()
+
+	handler.GetIndicators(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response struct {
+		Symbol     string                   `json:"symbol"`
+		Indicators []*models.IndicatorValue `json:"indicators"`
+		Count      int                      `json:"count"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Symbol != "AAPL" || response.Count != 2 {
+		t.Fatalf("Expected 2 AAPL values, got symbol %s count %d", response.Symbol, response.Count)
+	}
+	if response.Indicators[0].Value != 55.5 || response.Indicators[1].Value != 61.0 {
+		t.Errorf("Expected rsi_14 values [55.5 61], got [%v %v]", response.Indicators[0].Value, response.Indicators[1].Value)
+	}
+
+	filter := indicatorStorage.Filters[0]
+	if !filter.StartTime.Equal(base) || !filter.EndTime.Equal(base.Add(time.Hour)) || filter.Name != "rsi_14" {
+		t.Errorf("Unexpected storage filter %+v", filter)
+	}
+}
+
+func TestScanStatsHandler_GetScanStats(t *testing.T) {
+	base := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	scanStatsStorage := &storage.MockScanStatsStorage{
+		Samples: []*models.ScanStatsSample{
+			{WorkerID: "worker-1", Timestamp: base.Add(time.Minute), ScanCycles: 60, AvgCycleTimeMs: 12.5},
+			{WorkerID: "worker-2", Timestamp: base.Add(time.Minute), ScanCycles: 58},
+			{WorkerID: "worker-1", Timestamp: base.Add(2 * time.Minute), ScanCycles: 59, AvgCycleTimeMs: 14},
+			{WorkerID: "worker-1", Timestamp: base.Add(2 * time.Hour), ScanCycles: 60},
+		},
+	}
+	handler := NewScanStatsHandler(scanStatsStorage)
+
+	req := httptest.NewRequest("GET", "/api/v1/scan-stats?worker=worker-1&from=2024-01-02T14:00:00Z&to=2024-01-02T15:00:00Z", nil)
+	w := httptest.NewRecorder()
+	handler.GetScanStats(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response struct {
+		Worker  string                    `json:"worker"`
+		Samples []*models.ScanStatsSample `json:"samples"`
+		Count   int                       `json:"count"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Worker != "worker-1" || response.Count != 2 {
+		t.Fatalf("Expected 2 worker-1 samples, got worker %s count %d", response.Worker, response.Count)
+	}
+	if response.Samples[0].AvgCycleTimeMs != 12.5 || response.Samples[1].AvgCycleTimeMs != 14 {
+		t.Errorf("Expected cycle times [12.5 14], got [%v %v]", response.Samples[0].AvgCycleTimeMs, response.Samples[1].AvgCycleTimeMs)
+	}
+
+	// Without a range the last 24 hours are read
+	now := base.Add(3 * time.Hour)
+	handler.now = func() time.Time { return now }
+	w = httptest.NewRecorder()
+	handler.GetScanStats(w, httptest.NewRequest("GET", "/api/v1/scan-stats", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	filter := scanStatsStorage.Filters[1]
+	if filter.WorkerID != "" || !filter.EndTime.Equal(now) || !filter.StartTime.Equal(now.Add(-24*time.Hour)) {
+		t.Errorf("Unexpected default filter %+v", filter)
+	}
+
+	w = httptest.NewRecorder()
+	handler.GetScanStats(w, httptest.NewRequest("GET", "/api/v1/scan-stats?from=yesterday", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for invalid from, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestIndicatorHandler_GetIndicators_DefaultRangeAndValidation(t *testing.T) {
+	now := time.Date(2024, 1, 2, 16, 0, 0, 0, time.UTC)
+	indicatorStorage := &storage.MockIndicatorStorage{}
+	handler := NewIndicatorHandler(indicatorStorage)
+	handler.now = func() time.Time { return now }
+
+	req := httptest.NewRequest("GET", "/api/v1/indicators/AAPL", nil)
+	req = mux.SetURLVars(req, map[string]string{"symbol": "AAPL"})
+	w := httptest.NewRecorder()
+	handler.GetIndicators(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	filter := indicatorStorage.Filters[0]
+	if !filter.EndTime.Equal(now) || !filter.StartTime.Equal(now.Add(-24*time.Hour)) {
+		t.Errorf("Expected the last 24h by default, got %v to %v", filter.StartTime, filter.EndTime)
+	}
+
+	for _, query := range []string{"from=yesterday", "to=2024-01-02", "from=2024-01-02T15:00:00Z&to=2024-01-02T14:00:00Z"} {
+		req := httptest.NewRequest("GET", "/api/v1/indicators/AAPL?"+query, nil)
+		req = mux.SetURLVars(req, map[string]string{"symbol": "AAPL"})
+		w := httptest.NewRecorder()
+		handler.GetIndicators(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %q, got %d", http.StatusBadRequest, query, w.Code)
+		}
+	}
+}
+
 func TestUserHandler_GetProfile(t *testing.T) {
-	handler := NewUserHandler()
+	handler := NewUserHandler(storage.NewUserPreferencesStore(storage.NewMockRedisClient()))
 
 	req := httptest.NewRequest("GET", "/api/v1/user/profile", nil)
 	req = req.WithContext(req.Context())
@@ -452,3 +694,469 @@ func TestUserHandler_GetProfile(t *testing.T) {
 	}
 }
 
+func TestUserHandler_Preferences(t *testing.T) {
+	handler := NewUserHandler(storage.NewUserPreferencesStore(storage.NewMockRedisClient()))
+
+	body := `{"user_id": "someone-else", "default_channels": ["kafka", "websocket"], "locale": "de-DE",
+		"quiet_hours": {"start": "22:00", "end": "07:00", "timezone": "Europe/Berlin"},
+		"snoozes": [{"symbol": "AAPL", "until": "2099-01-01T00:00:00Z"}]}`
+	req := httptest.NewRequest("PUT", "/api/v1/user/preferences", bytes.NewBufferString(body))
+	req = req.WithContext(context.WithValue(req.Context(), "user_id", "user-1"))
+	w := httptest.NewRecorder()
+	handler.UpdatePreferences(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/user/preferences", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "user_id", "user-1"))
+	w = httptest.NewRecorder()
+	handler.GetPreferences(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var prefs models.UserPreferences
+	if err := json.Unmarshal(w.Body.Bytes(), &prefs); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if prefs.UserID != "user-1" {
+		t.Errorf("Expected preferences stored for the authenticated user, got %q", prefs.UserID)
+	}
+	if prefs.Locale != "de-DE" || len(prefs.DefaultChannels) != 2 || len(prefs.Snoozes) != 1 {
+		t.Errorf("Unexpected preferences %+v", prefs)
+	}
+	if prefs.QuietHours == nil || prefs.QuietHours.Start != "22:00" {
+		t.Errorf("Expected quiet hours to be persisted, got %+v", prefs.QuietHours)
+	}
+
+	// Invalid preferences are rejected
+	req = httptest.NewRequest("PUT", "/api/v1/user/preferences", bytes.NewBufferString(`{"quiet_hours": {"start": "7am", "end": "09:00"}}`))
+	req = req.WithContext(context.WithValue(req.Context(), "user_id", "user-1"))
+	w = httptest.NewRecorder()
+	handler.UpdatePreferences(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for invalid quiet hours, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestTestAlertHandler_SendTestAlert(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	handler := NewTestAlertHandler(redis, "alerts")
+
+	body, _ := json.Marshal(map[string]interface{}{"symbol": "aapl", "price": 150.0})
+	req := httptest.NewRequest("POST", "/api/v1/alerts/test", bytes.NewBuffer(body))
+	req = req.WithContext(context.WithValue(req.Context(), "user_id", "user-1"))
+	w := httptest.NewRecorder()
+
+	handler.SendTestAlert(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d", http.StatusAccepted, w.Code)
+	}
+
+	// Alert should be pushed to the raw alert stream (real pipeline)
+	if len(redis.StreamData) != 1 {
+		t.Fatalf("Expected 1 message published, got %d", len(redis.StreamData))
+	}
+	msg := redis.StreamData[0]
+	if msg.Stream != "alerts" {
+		t.Errorf("Expected stream 'alerts', got '%s'", msg.Stream)
+	}
+
+	var alert models.Alert
+	if err := json.Unmarshal([]byte(msg.Values["alert"].(string)), &alert); err != nil {
+		t.Fatalf("Failed to unmarshal published alert: %v", err)
+	}
+	if !alert.IsTest() {
+		t.Error("Expected published alert to be marked as test")
+	}
+	if alert.TargetUserID() != "user-1" {
+		t.Errorf("Expected alert addressed to user-1, got '%s'", alert.TargetUserID())
+	}
+	if alert.Symbol != "AAPL" {
+		t.Errorf("Expected symbol AAPL, got %s", alert.Symbol)
+	}
+}
+
+func TestTestAlertHandler_SendTestAlert_NoBody(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	handler := NewTestAlertHandler(redis, "alerts")
+
+	req := httptest.NewRequest("POST", "/api/v1/alerts/test", nil)
+	w := httptest.NewRecorder()
+
+	handler.SendTestAlert(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d", http.StatusAccepted, w.Code)
+	}
+	if len(redis.StreamData) != 1 {
+		t.Fatalf("Expected 1 message published, got %d", len(redis.StreamData))
+	}
+}
+
+func TestAlertNoteHandler_AddAndListNotes(t *testing.T) {
+	alertStorage := &storage.MockAlertStorage{}
+	alertStorage.WriteAlert(nil, &models.Alert{ID: "alert-1", RuleID: "rule-1", Symbol: "AAPL", Timestamp: time.Now()})
+	noteStorage := &storage.MockAlertNoteStorage{}
+	redis := storage.NewMockRedisClient()
+	handler := NewAlertNoteHandler(noteStorage, alertStorage, redis)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/alerts/{id}/notes", handler.AddNote).Methods("POST")
+	router.HandleFunc("/api/v1/alerts/{id}/notes", handler.ListNotes).Methods("GET")
+
+	body, _ := json.Marshal(AddNoteRequest{Text: "Checking the news"})
+	req := httptest.NewRequest("POST", "/api/v1/alerts/alert-1/notes", bytes.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), "user_id", "user-1"))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	var note models.AlertNote
+	if err := json.Unmarshal(w.Body.Bytes(), &note); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if note.Author != "user-1" || note.AlertID != "alert-1" || note.CreatedAt.IsZero() {
+		t.Errorf("Unexpected note: %+v", note)
+	}
+
+	// Note is announced for WebSocket delivery
+	if len(redis.Published) != 1 || redis.Published[0].Channel != models.AlertNotesChannel {
+		t.Fatalf("Expected note to be published on %s, got %v", models.AlertNotesChannel, redis.Published)
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/alerts/alert-1/notes", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response struct {
+		Notes []models.AlertNote `json:"notes"`
+		Count int                `json:"count"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Count != 1 || response.Notes[0].Text != "Checking the news" {
+		t.Errorf("Unexpected notes response: %+v", response)
+	}
+}
+
+func TestAlertNoteHandler_AddNote_Invalid(t *testing.T) {
+	alertStorage := &storage.MockAlertStorage{}
+	alertStorage.WriteAlert(nil, &models.Alert{ID: "alert-1", RuleID: "rule-1", Symbol: "AAPL", Timestamp: time.Now()})
+	handler := NewAlertNoteHandler(&storage.MockAlertNoteStorage{}, alertStorage, storage.NewMockRedisClient())
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/alerts/{id}/notes", handler.AddNote).Methods("POST")
+
+	tests := []struct {
+		name   string
+		path   string
+		text   string
+		status int
+	}{
+		{"unknown alert", "/api/v1/alerts/missing/notes", "hello", http.StatusNotFound},
+		{"empty text", "/api/v1/alerts/alert-1/notes", "   ", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		body, _ := json.Marshal(AddNoteRequest{Text: tt.text})
+		req := httptest.NewRequest("POST", tt.path, bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, w.Code)
+		}
+	}
+}
+
+// fakeHypertableReader returns fixed hypertable settings
+type fakeHypertableReader struct {
+	settings []storage.HypertableSettings
+	tables   []string
+}
+
+func (f *fakeHypertableReader) GetSettings(ctx context.Context, tables []string) ([]storage.HypertableSettings, error) {
+	f.tables = tables
+	return f.settings, nil
+}
+
+func TestAdminHandler_GetStorageSettings(t *testing.T) {
+	reader := &fakeHypertableReader{
+		settings: []storage.HypertableSettings{
+			{Table: "bars_1m", ChunkInterval: "1 day", CompressionEnabled: true, CompressAfter: "7 days"},
+		},
+	}
+	handler := NewAdminHandler(reader, []storage.HypertablePolicy{
+		{Table: "bars_1m", ChunkInterval: 24 * time.Hour, CompressAfter: 7 * 24 * time.Hour, SegmentBy: "symbol"},
+	})
+
+	req := httptest.NewRequest("GET", "/api/v1/admin/storage", nil)
+	w := httptest.NewRecorder()
+	handler.GetStorageSettings(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if len(reader.tables) != 1 || reader.tables[0] != "bars_1m" {
+		t.Errorf("Expected settings requested for bars_1m, got %v", reader.tables)
+	}
+
+	var response struct {
+		Hypertables []storage.HypertableSettings `json:"hypertables"`
+		Configured  []map[string]string          `json:"configured"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Hypertables) != 1 || !response.Hypertables[0].CompressionEnabled {
+		t.Errorf("Unexpected hypertable settings: %+v", response.Hypertables)
+	}
+	if len(response.Configured) != 1 || response.Configured[0]["chunk_interval"] != "24h0m0s" {
+		t.Errorf("Unexpected configured policies: %+v", response.Configured)
+	}
+}
+
+func TestMuteHandler(t *testing.T) {
+	handler := NewMuteHandler(storage.NewSymbolMuteStore(storage.NewMockRedisClient()))
+
+	// Mute with reason and TTL
+	req := httptest.NewRequest("POST", "/api/v1/admin/mute/aapl", strings.NewReader(`{"reason": "bad prints", "ttl": "15m"}`))
+	req = mux.SetURLVars(req, map[string]string{"symbol": "aapl"})
+	w := httptest.NewRecorder()
+	handler.MuteSymbol(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var mute models.SymbolMute
+	if err := json.Unmarshal(w.Body.Bytes(), &mute); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if mute.Symbol != "AAPL" || mute.Reason != "bad prints" || mute.ExpiresAt == nil {
+		t.Errorf("Unexpected mute: %+v", mute)
+	}
+
+	// Mute without a body
+	req = httptest.NewRequest("POST", "/api/v1/admin/mute/MSFT", nil)
+	req = mux.SetURLVars(req, map[string]string{"symbol": "MSFT"})
+	w = httptest.NewRecorder()
+	handler.MuteSymbol(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	// Invalid TTL
+	req = httptest.NewRequest("POST", "/api/v1/admin/mute/TSLA", strings.NewReader(`{"ttl": "soon"}`))
+	req = mux.SetURLVars(req, map[string]string{"symbol": "TSLA"})
+	w = httptest.NewRecorder()
+	handler.MuteSymbol(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for invalid ttl, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	// Unmute
+	req = httptest.NewRequest("DELETE", "/api/v1/admin/mute/MSFT", nil)
+	req = mux.SetURLVars(req, map[string]string{"symbol": "MSFT"})
+	w = httptest.NewRecorder()
+	handler.UnmuteSymbol(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status %d, got %d", http.StatusNoContent, w.Code)
+	}
+
+	// List
+	req = httptest.NewRequest("GET", "/api/v1/admin/mute", nil)
+	w = httptest.NewRecorder()
+	handler.ListMutes(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var response struct {
+		Mutes []models.SymbolMute `json:"mutes"`
+		Count int                 `json:"count"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Count != 1 || response.Mutes[0].Symbol != "AAPL" {
+		t.Errorf("Expected only AAPL muted, got %+v", response.Mutes)
+	}
+}
+
+func TestRuleHandler_CreateRule_UnknownField(t *testing.T) {
+	ruleStore := rules.NewInMemoryRuleStore()
+	handler := NewRuleHandler(ruleStore, rules.NewCompiler(nil), nil)
+
+	// A misspelled field must not silently create a rule without conditions
+	body := `{"name": "Typo Rule", "conditons": [{"metric": "rsi_14", "operator": "<", "value": 30}], "enabled": true}`
+	req := httptest.NewRequest("POST", "/api/v1/rules", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	handler.CreateRule(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "conditons") {
+		t.Errorf("Expected error naming the unknown field, got %s", w.Body.String())
+	}
+	if rulesList, _ := ruleStore.GetAllRules(); len(rulesList) != 0 {
+		t.Errorf("Expected no rule stored, got %d", len(rulesList))
+	}
+}
+
+func TestRuleHandler_CreateRule_Limits(t *testing.T) {
+	ruleStore := rules.NewInMemoryRuleStore()
+	handler := NewRuleHandler(ruleStore, rules.NewCompiler(nil), nil)
+	handler.SetLimits(rules.RuleLimits{MaxRulesPerUser: 1, MaxConditionsPerRule: 2, MaxNestingDepth: 1})
+
+	createRule := func(userID string, conditionCount int) *httptest.ResponseRecorder {
+		conditions := make([]map[string]interface{}, 0, conditionCount)
+		for i := 0; i < conditionCount; i++ {
+			conditions = append(conditions, map[string]interface{}{"metric": "rsi_14", "operator": "<", "value": 30.0})
+		}
+		body, _ := json.Marshal(map[string]interface{}{
+			"name":       "Limited Rule",
+			"user_id":    userID,
+			"conditions": conditions,
+			"enabled":    true,
+		})
+		req := httptest.NewRequest("POST", "/api/v1/rules", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+		handler.CreateRule(w, req)
+		return w
+	}
+
+	// Too many conditions
+	w := createRule("user-1", 3)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for too many conditions, got %d", http.StatusBadRequest, w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "too many conditions") {
+		t.Errorf("Expected too many conditions error, got %s", w.Body.String())
+	}
+
+	// Within limits
+	w = createRule("user-1", 2)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d within limits, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	// Rule count limit reached for user-1
+	w = createRule("user-1", 1)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d when rule limit reached, got %d", http.StatusForbidden, w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "maximum number of rules") {
+		t.Errorf("Expected rule limit error, got %s", w.Body.String())
+	}
+
+	// Other users have their own quota
+	w = createRule("user-2", 1)
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status %d for another user, got %d", http.StatusCreated, w.Code)
+	}
+}
+
+func TestRuleHandler_UpdateRule_ConditionLimit(t *testing.T) {
+	ruleStore := rules.NewInMemoryRuleStore()
+	handler := NewRuleHandler(ruleStore, rules.NewCompiler(nil), nil)
+	handler.SetLimits(rules.RuleLimits{MaxConditionsPerRule: 1})
+
+	ruleStore.AddRule(&models.Rule{
+		ID:         "rule-1",
+		Name:       "Test Rule",
+		Conditions: []models.Condition{{Metric: "rsi_14", Operator: "<", Value: 30.0}},
+		Enabled:    true,
+	})
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name": "Test Rule",
+		"conditions": []map[string]interface{}{
+			{"metric": "rsi_14", "operator": "<", "value": 30.0},
+			{"metric": "price", "operator": ">", "value": 10.0},
+		},
+		"enabled": true,
+	})
+	req := httptest.NewRequest("PUT", "/api/v1/rules/rule-1", bytes.NewBuffer(body))
+	req = mux.SetURLVars(req, map[string]string{"id": "rule-1"})
+	w := httptest.NewRecorder()
+
+	handler.UpdateRule(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestCustomMetricHandler(t *testing.T) {
+	store := storage.NewCustomMetricStore(storage.NewMockRedisClient())
+	handler := NewCustomMetricHandler(store, []string{"price", "vwap_5m", "high", "low"})
+
+	save := func(name, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PUT", "/api/v1/metrics/custom/"+name, strings.NewReader(body))
+		req = mux.SetURLVars(req, map[string]string{"name": name})
+		w := httptest.NewRecorder()
+		handler.SaveCustomMetric(w, req)
+		return w
+	}
+
+	if w := save("vwap_dist", `{"expression": "price - vwap_5m", "description": "Distance to VWAP"}`); w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// System metrics cannot be redefined and expressions cannot use other custom metrics
+	if w := save("price", `{"expression": "high - low"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a system metric name, got %d", http.StatusBadRequest, w.Code)
+	}
+	if w := save("double_dist", `{"expression": "vwap_dist * 2"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a custom metric reference, got %d", http.StatusBadRequest, w.Code)
+	}
+	if w := save("range", `{"expression": "high -"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an invalid expression, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	// Definitions are stored for the default user, where scanners load them
+	stored, err := store.ListMetrics(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to list stored metrics: %v", err)
+	}
+	if len(stored) != 1 || stored[0].UserID != "default" || stored[0].Name != "vwap_dist" {
+		t.Errorf("Unexpected stored metrics: %+v", stored)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/metrics/custom", nil)
+	w := httptest.NewRecorder()
+	handler.ListCustomMetrics(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "vwap_dist") {
+		t.Errorf("Unexpected list response %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("DELETE", "/api/v1/metrics/custom/vwap_dist", nil)
+	req = mux.SetURLVars(req, map[string]string{"name": "vwap_dist"})
+	w = httptest.NewRecorder()
+	handler.DeleteCustomMetric(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	req = httptest.NewRequest("DELETE", "/api/v1/metrics/custom/vwap_dist", nil)
+	req = mux.SetURLVars(req, map[string]string{"name": "vwap_dist"})
+	w = httptest.NewRecorder()
+	handler.DeleteCustomMetric(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a missing metric, got %d", http.StatusNotFound, w.Code)
+	}
+}
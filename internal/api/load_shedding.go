@@ -0,0 +1,183 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// latencySampleMaxAge is how long the latency average stays a valid overload signal
+// without new samples, so a quiet period can't keep shedding low-priority routes
+const latencySampleMaxAge = 5 * time.Second
+
+// LoadSheddingConfig holds load shedding configuration
+type LoadSheddingConfig struct {
+	MaxInFlight       int           // In-flight requests at which low-priority routes are shed (0 = disabled)
+	MaxLatency        time.Duration // Average request latency above which low-priority routes are shed (0 = disabled)
+	LowPriorityRoutes []string      // Routes shed under overload: "METHOD /path" or "/path" (see parseRoutePattern)
+}
+
+// DefaultLowPriorityRoutes returns the list endpoints shed first under overload
+func DefaultLowPriorityRoutes() []string {
+	return []string{
+		"GET /api/v1/rules",
+		"GET /api/v1/alerts",
+		"GET /api/v1/symbols",
+		"GET /api/v1/toplists",
+		"GET /api/v1/toplists/user",
+		"GET /api/v1/toplists/user/export",
+		"GET /api/v1/alerts/*/notes",
+		"GET /api/v1/symbols/*/alerts",
+		"GET /api/v1/indicators/*",
+	}
+}
+
+// routePattern matches requests by method and path
+type routePattern struct {
+	method string // Empty matches any method
+	path   string
+	prefix bool
+}
+
+// parseRoutePattern parses "METHOD /path" or "/path". A "*" segment matches any single
+// segment ("/api/v1/alerts/*/notes") and "/path*" matches any path with that prefix.
+func parseRoutePattern(s string) routePattern {
+	var p routePattern
+	s = strings.TrimSpace(s)
+	if method, path, ok := strings.Cut(s, " "); ok {
+		p.method = strings.ToUpper(method)
+		s = strings.TrimSpace(path)
+	}
+	if strings.HasSuffix(s, "*") && !strings.HasSuffix(s, "/*") {
+		p.prefix = true
+		s = strings.TrimSuffix(s, "*")
+	}
+	p.path = s
+	return p
+}
+
+// matches returns true if the request matches the pattern. A "*" path segment matches any single segment.
+func (p routePattern) matches(r *http.Request) bool {
+	if p.method != "" && p.method != r.Method {
+		return false
+	}
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	if p.prefix {
+		return strings.HasPrefix(path, p.path)
+	}
+	if !strings.Contains(p.path, "*") {
+		return path == p.path
+	}
+
+	want := strings.Split(p.path, "/")
+	got := strings.Split(path, "/")
+	if len(want) != len(got) {
+		return false
+	}
+	for i := range want {
+		if want[i] != "*" && want[i] != got[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// loadShedder tracks server load and decides which requests to shed
+type loadShedder struct {
+	config      LoadSheddingConfig
+	lowPriority []routePattern
+	inFlight    atomic.Int64
+	latencyAvg  atomic.Int64 // Exponentially weighted average latency (ns)
+	lastSample  atomic.Int64 // Unix nanos of the last latency sample
+	shed        atomic.Int64
+	now         func() time.Time
+}
+
+// newLoadShedder creates a load shedder
+func newLoadShedder(config LoadSheddingConfig) *loadShedder {
+	s := &loadShedder{config: config, now: time.Now}
+	for _, route := range config.LowPriorityRoutes {
+		if strings.TrimSpace(route) != "" {
+			s.lowPriority = append(s.lowPriority, parseRoutePattern(route))
+		}
+	}
+	return s
+}
+
+// isLowPriority returns true if the request may be shed under overload
+func (s *loadShedder) isLowPriority(r *http.Request) bool {
+	for _, p := range s.lowPriority {
+		if p.matches(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// overloaded returns the reason the server is overloaded, or "" if it is not
+func (s *loadShedder) overloaded() string {
+	if s.config.MaxInFlight > 0 && s.inFlight.Load() >= int64(s.config.MaxInFlight) {
+		return "in_flight"
+	}
+	if s.config.MaxLatency > 0 && s.latencyAvg.Load() > int64(s.config.MaxLatency) &&
+		s.now().UnixNano()-s.lastSample.Load() <= int64(latencySampleMaxAge) {
+		return "latency"
+	}
+	return ""
+}
+
+// recordLatency folds a served request's latency into the average (weight 1/5)
+func (s *loadShedder) recordLatency(d time.Duration) {
+	for {
+		old := s.latencyAvg.Load()
+		updated := int64(d)
+		if old > 0 {
+			updated = old + (int64(d)-old)/5
+		}
+		if s.latencyAvg.CompareAndSwap(old, updated) {
+			break
+		}
+	}
+	s.lastSample.Store(s.now().UnixNano())
+}
+
+// middleware sheds low-priority requests with 503 while the server is overloaded
+func (s *loadShedder) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.isLowPriority(r) {
+			if reason := s.overloaded(); reason != "" {
+				s.shed.Add(1)
+				logger.WithContext(r.Context()).Debug("Shedding low-priority request",
+					logger.String("method", r.Method),
+					logger.String("path", r.URL.Path),
+					logger.String("reason", reason),
+				)
+				w.Header().Set("Retry-After", "1")
+				respondWithError(w, http.StatusServiceUnavailable, "Service overloaded, retry later")
+				return
+			}
+		}
+
+		s.inFlight.Add(1)
+		start := s.now()
+		defer func() {
+			s.inFlight.Add(-1)
+			s.recordLatency(s.now().Sub(start))
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LoadSheddingMiddleware returns 503 for low-priority routes (e.g. list endpoints) while
+// in-flight requests or average latency exceed their thresholds, so critical routes
+// (health, rule writes) keep being served and the database is protected
+func LoadSheddingMiddleware(config LoadSheddingConfig) Middleware {
+	if config.MaxInFlight <= 0 && config.MaxLatency <= 0 {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	return newLoadShedder(config).middleware
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRoutePattern_Matches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		method  string
+		path    string
+		want    bool
+	}{
+		{"GET /api/v1/alerts", "GET", "/api/v1/alerts", true},
+		{"GET /api/v1/alerts", "GET", "/api/v1/alerts/", true},
+		{"GET /api/v1/alerts", "POST", "/api/v1/alerts", false},
+		{"GET /api/v1/alerts", "GET", "/api/v1/alerts/alert-1", false},
+		{"/api/v1/alerts", "DELETE", "/api/v1/alerts", true},
+		{"get /api/v1/alerts/*/notes", "GET", "/api/v1/alerts/alert-1/notes", true},
+		{"GET /api/v1/alerts/*/notes", "GET", "/api/v1/alerts/notes", false},
+		{"GET /api/v1/toplists*", "GET", "/api/v1/toplists/user/42/rankings", true},
+		{"GET /api/v1/toplists*", "GET", "/api/v1/rules", false},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, tt.path, nil)
+		if got := parseRoutePattern(tt.pattern).matches(r); got != tt.want {
+			t.Errorf("%q matches %s %s = %v, want %v", tt.pattern, tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLoadShedding_InFlightOverload(t *testing.T) {
+	shedder := newLoadShedder(LoadSheddingConfig{
+		MaxInFlight:       2,
+		LowPriorityRoutes: DefaultLowPriorityRoutes(),
+	})
+
+	// Slow requests hold their slot until released
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	handler := shedder.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("slow") == "1" {
+			started <- struct{}{}
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	// Before overload, low-priority routes are served
+	if rec := serve("GET", "/api/v1/alerts"); rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 before overload, got %d", rec.Code)
+	}
+
+	// Simulate overload with two slow in-flight requests
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serve("POST", "/api/v1/rules?slow=1")
+		}()
+	}
+	<-started
+	<-started
+
+	for _, path := range []string{"/api/v1/alerts", "/api/v1/rules", "/api/v1/toplists", "/api/v1/alerts/alert-1/notes"} {
+		rec := serve("GET", path)
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected low-priority GET %s shed with 503, got %d", path, rec.Code)
+		}
+		if rec.Header().Get("Retry-After") == "" {
+			t.Errorf("Expected Retry-After header on shed GET %s", path)
+		}
+	}
+
+	// Critical routes pass while overloaded
+	for _, req := range [][2]string{{"GET", "/health"}, {"POST", "/api/v1/rules"}, {"PUT", "/api/v1/rules/rule-1"}, {"GET", "/api/v1/alerts/alert-1"}} {
+		if rec := serve(req[0], req[1]); rec.Code != http.StatusOK {
+			t.Errorf("Expected %s %s served under overload, got %d", req[0], req[1], rec.Code)
+		}
+	}
+
+	close(release)
+	wg.Wait()
+
+	if rec := serve("GET", "/api/v1/alerts"); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 after load drops, got %d", rec.Code)
+	}
+	if got := shedder.shed.Load(); got != 4 {
+		t.Errorf("Expected 4 shed requests, got %d", got)
+	}
+}
+
+func TestLoadShedding_LatencyOverload(t *testing.T) {
+	now := time.Now()
+	shedder := newLoadShedder(LoadSheddingConfig{
+		MaxLatency:        100 * time.Millisecond,
+		LowPriorityRoutes: []string{"GET /api/v1/symbols"},
+	})
+	shedder.now = func() time.Time { return now }
+	handler := shedder.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Recent requests have been slow
+	shedder.recordLatency(500 * time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/symbols", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected low-priority route shed on high latency, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected health served on high latency, got %d", rec.Code)
+	}
+
+	// A stale latency average no longer sheds
+	now = now.Add(time.Minute)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/symbols", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected low-priority route served once latency samples are stale, got %d", rec.Code)
+	}
+}
+
+func TestLoadSheddingMiddleware_Disabled(t *testing.T) {
+	handler := LoadSheddingMiddleware(LoadSheddingConfig{LowPriorityRoutes: DefaultLowPriorityRoutes()})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/api/v1/alerts", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected pass-through when no thresholds are set, got %d", rec.Code)
+	}
+}
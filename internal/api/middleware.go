@@ -3,13 +3,22 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/mohamedkhairy/stock-scanner/internal/wsgateway"
 	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+	"go.uber.org/zap"
 )
 
+// RequestIDHeader is the header used to propagate request IDs
+const RequestIDHeader = "X-Request-ID"
+
 // Middleware is a function that wraps an HTTP handler
 type Middleware func(http.Handler) http.Handler
 
@@ -44,39 +53,105 @@ func CORSMiddleware() Middleware {
 	}
 }
 
-// LoggingMiddleware logs HTTP requests
+// LoggingConfig holds request logging configuration
+type LoggingConfig struct {
+	Service    string // Service name included in every request log (optional)
+	SampleRate int    // Log 1 in N successful requests (<= 1 logs every request); errors are always logged
+}
+
+// DefaultLoggingConfig returns logging configuration that logs every request
+func DefaultLoggingConfig() LoggingConfig {
+	return LoggingConfig{
+		SampleRate: 1,
+	}
+}
+
+// requestLogInfo collects request details set by inner middleware (e.g. the authenticated user)
+type requestLogInfo struct {
+	userID string
+}
+
+// LoggingMiddleware logs every HTTP request
 func LoggingMiddleware() Middleware {
+	return LoggingMiddlewareWithConfig(DefaultLoggingConfig())
+}
+
+// LoggingMiddlewareWithConfig logs HTTP requests with sampling.
+// It assigns a request ID (or reuses the incoming X-Request-ID header), echoes it in the
+// response and stores it in the request context so downstream logs can include it.
+func LoggingMiddlewareWithConfig(config LoggingConfig) Middleware {
+	sampleRate := int64(config.SampleRate)
+	if sampleRate < 1 {
+		sampleRate = 1
+	}
+	var successCount atomic.Int64
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 
+			requestID := r.Header.Get(RequestIDHeader)
+			if requestID == "" {
+				requestID = uuid.New().String()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			info := &requestLogInfo{}
+			ctx := logger.WithRequestID(r.Context(), requestID)
+			ctx = context.WithValue(ctx, "request_log", info)
+
 			// Wrap response writer to capture status code
 			wrapped := &responseWriter{
 				ResponseWriter: w,
 				statusCode:     http.StatusOK,
 			}
 
-			next.ServeHTTP(wrapped, r)
+			next.ServeHTTP(wrapped, r.WithContext(ctx))
+
+			isError := wrapped.statusCode >= http.StatusBadRequest
+			if !isError && (successCount.Add(1)-1)%sampleRate != 0 {
+				return
+			}
 
-			duration := time.Since(start)
-			logger.Info("HTTP request",
+			fields := []zap.Field{
+				logger.String("request_id", requestID),
 				logger.String("method", r.Method),
 				logger.String("path", r.URL.Path),
 				logger.String("remote_addr", r.RemoteAddr),
+				logger.String("user_id", info.userID),
 				logger.Int("status", wrapped.statusCode),
-				logger.Duration("duration", duration),
-			)
+				logger.Duration("latency", time.Since(start)),
+			}
+			if config.Service != "" {
+				fields = append(fields, logger.String("service", config.Service))
+			}
+
+			if isError {
+				logger.Warn("HTTP request", fields...)
+				return
+			}
+			if sampleRate > 1 {
+				fields = append(fields, logger.Int64("sample_rate", sampleRate))
+			}
+			logger.Info("HTTP request", fields...)
 		})
 	}
 }
 
+// setRequestUserID records the user ID for request logging
+func setRequestUserID(ctx context.Context, userID string) {
+	if info, ok := ctx.Value("request_log").(*requestLogInfo); ok {
+		info.userID = userID
+	}
+}
+
 // ErrorHandlingMiddleware handles errors and returns JSON responses
 func ErrorHandlingMiddleware() Middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if err := recover(); err != nil {
-					logger.Error("Panic in handler",
+					logger.WithContext(r.Context()).Error("Panic in handler",
 						logger.String("path", r.URL.Path),
 						logger.String("error", err.(string)),
 					)
@@ -89,6 +164,24 @@ func ErrorHandlingMiddleware() Middleware {
 	}
 }
 
+// MaxBodySizeMiddleware limits request bodies to maxBytes (0 = unlimited). Requests declaring
+// a larger Content-Length are rejected with 413; handlers reading past the limit get an error.
+func MaxBodySizeMiddleware(maxBytes int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		if maxBytes <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBytes {
+				respondWithError(w, http.StatusRequestEntityTooLarge, requestBodyTooLargeMessage(maxBytes))
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // RateLimitMiddleware implements simple rate limiting
 func RateLimitMiddleware(requestsPerSecond int) Middleware {
 	type clientInfo struct {
@@ -146,8 +239,28 @@ func RateLimitMiddleware(requestsPerSecond int) Middleware {
 	}
 }
 
+// AuthConfig holds authentication middleware configuration
+type AuthConfig struct {
+	JWTSecret     string        // Tokens are not validated when empty (MVP: default user)
+	FailureLimit  int           // Failed auth attempts allowed per IP per window before rejecting (0 = unlimited)
+	FailureWindow time.Duration // Window for FailureLimit
+
+	// Proxies whose X-Forwarded-For/X-Real-IP headers identify the client (nil = none)
+	TrustedProxies *wsgateway.TrustedProxies
+}
+
 // AuthMiddleware validates JWT tokens and injects user context
 func AuthMiddleware(jwtSecret string) Middleware {
+	return AuthMiddlewareWithConfig(AuthConfig{JWTSecret: jwtSecret})
+}
+
+// AuthMiddlewareWithConfig validates JWT tokens and injects user context.
+// Rejected tokens are counted in auth_failures_total by reason, and IPs that keep
+// failing are rejected with 429 until their failure window expires.
+func AuthMiddlewareWithConfig(config AuthConfig) Middleware {
+	authManager := wsgateway.NewAuthManager(config.JWTSecret)
+	limiter := wsgateway.NewAuthFailureLimiter(config.FailureLimit, config.FailureWindow)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Skip auth for health endpoints
@@ -158,22 +271,65 @@ func AuthMiddleware(jwtSecret string) Middleware {
 
 			// Extract token from Authorization header
 			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
+			if authHeader == "" || config.JWTSecret == "" {
 				// MVP: Allow requests without auth (use default user)
 				// In production, this should be required
 				ctx := context.WithValue(r.Context(), "user_id", "default")
+				setRequestUserID(ctx, "default")
 				next.ServeHTTP(w, r.WithContext(ctx))
 				return
 			}
 
-			// Validate token (reuse auth logic from wsgateway)
-			// For now, MVP allows default user
-			ctx := context.WithValue(r.Context(), "user_id", "default")
+			remoteIP := config.TrustedProxies.ClientIP(r)
+			if !limiter.Allow(remoteIP) {
+				wsgateway.RecordAuthFailure("api", remoteIP, wsgateway.AuthFailureRateLimited, nil)
+				respondWithError(w, http.StatusTooManyRequests, "Too many failed authentication attempts")
+				return
+			}
+
+			userID, err := validateAuthHeader(authManager, authHeader)
+			if err != nil {
+				limiter.RecordFailure(remoteIP)
+				wsgateway.RecordAuthFailure("api", remoteIP, wsgateway.ClassifyAuthError(err), err)
+				respondWithError(w, http.StatusUnauthorized, "Invalid authentication token")
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), "user_id", userID)
+			setRequestUserID(ctx, userID)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// AdminMiddleware only lets the configured admin users through, rejecting everyone else with
+// 403. It runs after AuthMiddleware, which identifies the user.
+func AdminMiddleware(adminUsers []string) Middleware {
+	admins := make(map[string]bool, len(adminUsers))
+	for _, userID := range adminUsers {
+		admins[userID] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !admins[getUserID(r)] {
+				respondWithError(w, http.StatusForbidden, "Admin access required")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// validateAuthHeader extracts and validates the bearer token, returning the user ID
+func validateAuthHeader(authManager *wsgateway.AuthManager, authHeader string) (string, error) {
+	tokenString, err := authManager.ExtractTokenFromHeader(authHeader)
+	if err != nil {
+		return "", err
+	}
+	return authManager.ValidateToken(tokenString)
+}
+
 // Helper functions
 
 type responseWriter struct {
@@ -195,6 +351,34 @@ func respondWithError(w http.ResponseWriter, code int, message string) {
 	})
 }
 
+// decodeStrictJSON decodes a single JSON value from the request body into v, rejecting unknown
+// fields and trailing data. On error it responds with 413 (body over the size limit) or 400
+// and returns false.
+func decodeStrictJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err == nil && decoder.More() {
+		err = errors.New("unexpected data after JSON value")
+	}
+	if err == nil {
+		return true
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		respondWithError(w, http.StatusRequestEntityTooLarge, requestBodyTooLargeMessage(maxBytesErr.Limit))
+		return false
+	}
+	respondWithError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+	return false
+}
+
+func requestBodyTooLargeMessage(maxBytes int64) string {
+	return fmt.Sprintf("Request body too large (max %d bytes)", maxBytes)
+}
+
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
@@ -1,11 +1,30 @@
 package api
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
+// observeLogs replaces the global logger with an observer for the duration of the test
+func observeLogs(t *testing.T) *observer.ObservedLogs {
+	core, logs := observer.New(zapcore.DebugLevel)
+	previous := logger.Get()
+	logger.Set(zap.New(core))
+	t.Cleanup(func() { logger.Set(previous) })
+	return logs
+}
+
 func TestCORSMiddleware(t *testing.T) {
 	handler := CORSMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -51,6 +70,118 @@ func TestLoggingMiddleware(t *testing.T) {
 	}
 }
 
+func TestLoggingMiddleware_Sampling(t *testing.T) {
+	logs := observeLogs(t)
+
+	handler := LoggingMiddlewareWithConfig(LoggingConfig{Service: "api", SampleRate: 5})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+	for i := 0; i < 20; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/test", nil))
+	}
+
+	entries := logs.FilterMessage("HTTP request").All()
+	if len(entries) != 4 {
+		t.Fatalf("Expected 4 sampled request logs (1 in 5 of 20), got %d", len(entries))
+	}
+
+	fields := entries[0].ContextMap()
+	for _, key := range []string{"request_id", "user_id", "status", "latency", "service", "sample_rate"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("Expected field %q in request log", key)
+		}
+	}
+	if fields["service"] != "api" {
+		t.Errorf("Expected service 'api', got %v", fields["service"])
+	}
+}
+
+func TestLoggingMiddleware_ErrorsAlwaysLogged(t *testing.T) {
+	logs := observeLogs(t)
+
+	handler := LoggingMiddlewareWithConfig(LoggingConfig{SampleRate: 1000})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/fail" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+
+	for i := 0; i < 10; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+	}
+
+	entries := logs.FilterMessage("HTTP request").All()
+	errors := 0
+	for _, entry := range entries {
+		if entry.ContextMap()["status"] == int64(http.StatusInternalServerError) {
+			errors++
+			if entry.Level != zapcore.WarnLevel {
+				t.Errorf("Expected error requests to log at warn level, got %s", entry.Level)
+			}
+		}
+	}
+	if errors != 10 {
+		t.Errorf("Expected all 10 error requests logged, got %d", errors)
+	}
+	// Only the first successful request is sampled
+	if len(entries)-errors != 1 {
+		t.Errorf("Expected 1 sampled successful request log, got %d", len(entries)-errors)
+	}
+}
+
+func TestLoggingMiddleware_RequestID(t *testing.T) {
+	logs := observeLogs(t)
+
+	var downstreamID string
+	handler := ChainMiddleware(
+		LoggingMiddleware(),
+		AuthMiddleware(""),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downstreamID = logger.GetRequestID(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// Incoming request ID is propagated
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "req-123")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if downstreamID != "req-123" {
+		t.Errorf("Expected downstream request ID 'req-123', got %q", downstreamID)
+	}
+	if got := w.Header().Get(RequestIDHeader); got != "req-123" {
+		t.Errorf("Expected response header 'req-123', got %q", got)
+	}
+
+	entries := logs.FilterMessage("HTTP request").All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 request log, got %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["request_id"] != "req-123" {
+		t.Errorf("Expected logged request ID 'req-123', got %v", fields["request_id"])
+	}
+	if fields["user_id"] != "default" {
+		t.Errorf("Expected logged user ID 'default', got %v", fields["user_id"])
+	}
+
+	// Missing request ID is generated
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+	if w.Header().Get(RequestIDHeader) == "" {
+		t.Error("Expected generated request ID in response header")
+	}
+	if downstreamID != w.Header().Get(RequestIDHeader) {
+		t.Errorf("Expected downstream request ID %q, got %q", w.Header().Get(RequestIDHeader), downstreamID)
+	}
+}
+
 func TestErrorHandlingMiddleware(t *testing.T) {
 	handler := ErrorHandlingMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		panic("test panic")
@@ -66,6 +197,89 @@ func TestErrorHandlingMiddleware(t *testing.T) {
 	}
 }
 
+func TestMaxBodySizeMiddleware(t *testing.T) {
+	var rule models.Rule
+	handler := MaxBodySizeMiddleware(64)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !decodeStrictJSON(w, r, &rule) {
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	oversized := `{"name": "` + strings.Repeat("x", 100) + `"}`
+
+	// Declared Content-Length over the limit is rejected before the handler runs
+	req := httptest.NewRequest("POST", "/api/v1/rules", strings.NewReader(oversized))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status %d for oversized body, got %d", http.StatusRequestEntityTooLarge, w.Code)
+	}
+
+	// Bodies without a Content-Length are cut off while decoding
+	req = httptest.NewRequest("POST", "/api/v1/rules", strings.NewReader(oversized))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status %d for oversized streamed body, got %d", http.StatusRequestEntityTooLarge, w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "max 64 bytes") {
+		t.Errorf("Expected the limit in the error, got %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest("POST", "/api/v1/rules", strings.NewReader(`{"name": "Small"}`))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || rule.Name != "Small" {
+		t.Errorf("Expected small body to be accepted, got status %d and rule %+v", w.Code, rule)
+	}
+}
+
+func TestMaxBodySizeMiddleware_Disabled(t *testing.T) {
+	handler := MaxBodySizeMiddleware(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("POST", "/test", strings.NewReader(strings.Repeat("x", 1<<16)))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d with no limit, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestDecodeStrictJSON_RejectsMalformedBodies(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"unknown field", `{"name": "Rule", "conditons": []}`, `unknown field \"conditons\"`},
+		{"trailing data", `{"name": "Rule"} {"name": "Other"}`, "unexpected data after JSON value"},
+		{"wrong type", `{"name": 42}`, "Invalid request body"},
+		{"truncated", `{"name": "Rule"`, "Invalid request body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rule models.Rule
+			req := httptest.NewRequest("POST", "/api/v1/rules", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			if decodeStrictJSON(w, req, &rule) {
+				t.Fatal("Expected decoding to fail")
+			}
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("Expected error containing %q, got %s", tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
 func TestRateLimitMiddleware(t *testing.T) {
 	handler := RateLimitMiddleware(2)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -133,6 +347,34 @@ func TestAuthMiddleware_HealthEndpoint(t *testing.T) {
 	}
 }
 
+func TestAdminMiddleware(t *testing.T) {
+	handler := AdminMiddleware([]string{"ops"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		userID string
+		want   int
+	}{
+		{"admin user", "ops", http.StatusOK},
+		{"other user", "alice", http.StatusForbidden},
+		{"default user", "default", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/admin/mute", nil)
+			req = req.WithContext(context.WithValue(req.Context(), "user_id", tt.userID))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
 func TestChainMiddleware(t *testing.T) {
 	handler := ChainMiddleware(
 		CORSMiddleware(),
@@ -155,3 +397,52 @@ func TestChainMiddleware(t *testing.T) {
 	}
 }
 
+
+func TestAuthMiddlewareWithConfig_TokenValidation(t *testing.T) {
+	secret := "test-secret-key"
+	handler := AuthMiddlewareWithConfig(AuthConfig{
+		JWTSecret:     secret,
+		FailureLimit:  2,
+		FailureWindow: time.Minute,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Context().Value("user_id").(string)))
+	}))
+
+	serve := func(token, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.RemoteAddr = ip + ":12345"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	valid, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": "user-1",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("Failed to create token: %v", err)
+	}
+
+	w := serve(valid, "10.0.0.1")
+	if w.Code != http.StatusOK || w.Body.String() != "user-1" {
+		t.Fatalf("Expected 200 for user-1, got %d %q", w.Code, w.Body.String())
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := serve("not-a-jwt", "10.0.0.1"); w.Code != http.StatusUnauthorized {
+			t.Errorf("Attempt %d: expected status %d, got %d", i+1, http.StatusUnauthorized, w.Code)
+		}
+	}
+
+	// Further attempts from the same IP are rejected, even with a valid token
+	if w := serve(valid, "10.0.0.1"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status %d, got %d", http.StatusTooManyRequests, w.Code)
+	}
+
+	// Other IPs are unaffected
+	if w := serve(valid, "10.0.0.2"); w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+}
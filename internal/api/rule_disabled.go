@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// DisabledRuleStore lists and clears the rules scanner workers disabled automatically
+// after repeated evaluation errors
+type DisabledRuleStore interface {
+	ListDisabledRules(ctx context.Context) ([]models.RuleDisableEvent, error)
+	DeleteDisabledRule(ctx context.Context, ruleID string) error
+}
+
+// SetDisabledRuleStore sets the store of automatically disabled rules (nil = unavailable)
+func (h *RuleHandler) SetDisabledRuleStore(store DisabledRuleStore) {
+	h.disabledRules = store
+}
+
+// ListDisabledRules handles GET /api/v1/rules/disabled, returning the caller's rules that
+// were disabled automatically after repeated evaluation errors
+func (h *RuleHandler) ListDisabledRules(w http.ResponseWriter, r *http.Request) {
+	if h.disabledRules == nil {
+		respondWithError(w, http.StatusServiceUnavailable, "Disabled rules unavailable")
+		return
+	}
+
+	events, err := h.disabledRules.ListDisabledRules(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve disabled rules")
+		return
+	}
+
+	userID := getUserID(r)
+	visible := make([]models.RuleDisableEvent, 0, len(events))
+	for _, event := range events {
+		if canManageRule(userID, event.UserID) {
+			visible = append(visible, event)
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"rules": visible,
+		"count": len(visible),
+	})
+}
+
+// EnableRule handles POST /api/v1/rules/:id/enable, re-enabling a rule (e.g. one disabled
+// automatically once it has been fixed). Scanner workers pick the rule up on their next reload.
+func (h *RuleHandler) EnableRule(w http.ResponseWriter, r *http.Request) {
+	ruleID := mux.Vars(r)["id"]
+
+	rule, err := h.ruleStore.GetRule(ruleID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Rule not found")
+		return
+	}
+	if !canManageRule(getUserID(r), rule.UserID) {
+		respondWithError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	if err := h.ruleStore.EnableRule(ruleID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to enable rule")
+		return
+	}
+
+	if h.syncService != nil {
+		if err := h.syncService.SyncRule(ruleID); err != nil {
+			logger.WithContext(r.Context()).Warn("Failed to sync rule to Redis",
+				logger.ErrorField(err),
+				logger.String("rule_id", ruleID),
+			)
+		}
+	}
+	if h.disabledRules != nil {
+		if err := h.disabledRules.DeleteDisabledRule(r.Context(), ruleID); err != nil {
+			logger.WithContext(r.Context()).Warn("Failed to clear disabled rule record",
+				logger.ErrorField(err),
+				logger.String("rule_id", ruleID),
+			)
+		}
+	}
+
+	logger.WithContext(r.Context()).Info("Rule enabled",
+		logger.String("rule_id", ruleID),
+	)
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{"rule_id": ruleID, "enabled": true})
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+// setupDisabledRules creates a rule handler with two disabled rules: one owned by alice and
+// one without an owner
+func setupDisabledRules(t *testing.T) (*RuleHandler, *rules.InMemoryRuleStore, *storage.DisabledRuleStore) {
+	t.Helper()
+	ctx := context.Background()
+	ruleStore := rules.NewInMemoryRuleStore()
+	disabledStore := storage.NewDisabledRuleStore(storage.NewMockRedisClient())
+
+	for _, rule := range []*models.Rule{
+		{ID: "rule-alice", Name: "Alice Rule", UserID: "alice", Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}}},
+		{ID: "rule-shared", Name: "Shared Rule", Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}}},
+	} {
+		if err := ruleStore.AddRule(rule); err != nil {
+			t.Fatalf("Failed to add rule: %v", err)
+		}
+		event := models.RuleDisableEvent{RuleID: rule.ID, RuleName: rule.Name, UserID: rule.UserID, Errors: 5}
+		if err := disabledStore.SaveDisabledRule(ctx, event); err != nil {
+			t.Fatalf("Failed to record disabled rule: %v", err)
+		}
+	}
+
+	handler := NewRuleHandler(ruleStore, rules.NewCompiler(nil), nil)
+	handler.SetDisabledRuleStore(disabledStore)
+	return handler, ruleStore, disabledStore
+}
+
+func asUser(req *http.Request, userID string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), "user_id", userID))
+}
+
+func TestRuleHandler_ListDisabledRules(t *testing.T) {
+	handler, _, _ := setupDisabledRules(t)
+
+	tests := []struct {
+		user string
+		want int
+	}{
+		{"alice", 2},
+		{"bob", 1},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler.ListDisabledRules(w, asUser(httptest.NewRequest("GET", "/api/v1/rules/disabled", nil), tt.user))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+
+		var response struct {
+			Rules []models.RuleDisableEvent `json:"rules"`
+			Count int                       `json:"count"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		if response.Count != tt.want || len(response.Rules) != tt.want {
+			t.Errorf("%s: expected %d disabled rules, got %+v", tt.user, tt.want, response.Rules)
+		}
+	}
+}
+
+func TestRuleHandler_EnableRule(t *testing.T) {
+	handler, ruleStore, disabledStore := setupDisabledRules(t)
+	if err := ruleStore.DisableRule("rule-alice"); err != nil {
+		t.Fatalf("Failed to disable rule: %v", err)
+	}
+
+	enable := func(ruleID, userID string) int {
+		req := mux.SetURLVars(httptest.NewRequest("POST", "/api/v1/rules/"+ruleID+"/enable", nil), map[string]string{"id": ruleID})
+		w := httptest.NewRecorder()
+		handler.EnableRule(w, asUser(req, userID))
+		return w.Code
+	}
+
+	// Only the owner may enable a rule
+	if code := enable("rule-alice", "bob"); code != http.StatusForbidden {
+		t.Errorf("Expected status %d for another user's rule, got %d", http.StatusForbidden, code)
+	}
+	if code := enable("missing", "alice"); code != http.StatusNotFound {
+		t.Errorf("Expected status %d for a missing rule, got %d", http.StatusNotFound, code)
+	}
+	if code := enable("rule-alice", "alice"); code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+	}
+
+	rule, err := ruleStore.GetRule("rule-alice")
+	if err != nil || !rule.Enabled {
+		t.Errorf("Expected rule to be enabled, got %+v (err: %v)", rule, err)
+	}
+	events, err := disabledStore.ListDisabledRules(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to list disabled rules: %v", err)
+	}
+	if len(events) != 1 || events[0].RuleID != "rule-shared" {
+		t.Errorf("Expected only rule-shared to stay recorded as disabled, got %+v", events)
+	}
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// RuleEvaluator evaluates an unsaved rule against live scanner state
+type RuleEvaluator interface {
+	EvaluateRule(ctx context.Context, rule *models.Rule) (*models.RuleEvaluation, error)
+}
+
+// ScannerRuleEvaluator evaluates rules on the scanner workers, which hold the live symbol
+// state, through their POST /rules/evaluate endpoint. Each worker only holds its partition
+// of symbols, so every worker is asked and the matches are merged.
+type ScannerRuleEvaluator struct {
+	urls   []string
+	token  string
+	client *http.Client
+}
+
+// NewScannerRuleEvaluator creates an evaluator for the scanner workers' health server base URLs.
+// token is sent as the bearer token the workers require on /rules/evaluate.
+func NewScannerRuleEvaluator(urls []string, token string, timeout time.Duration) *ScannerRuleEvaluator {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	trimmed := make([]string, 0, len(urls))
+	for _, url := range urls {
+		trimmed = append(trimmed, strings.TrimRight(url, "/"))
+	}
+	return &ScannerRuleEvaluator{
+		urls:   trimmed,
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// EvaluateRule evaluates the rule on every scanner worker in parallel. Any worker failing
+// fails the evaluation, since its symbols would be missing from the result.
+func (e *ScannerRuleEvaluator) EvaluateRule(ctx context.Context, rule *models.Rule) (*models.RuleEvaluation, error) {
+	if len(e.urls) == 0 {
+		return nil, errors.New("no scanner workers configured")
+	}
+
+	body, err := json.Marshal(rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal rule: %w", err)
+	}
+
+	evaluations := make([]*models.RuleEvaluation, len(e.urls))
+	errs := make([]error, len(e.urls))
+	var wg sync.WaitGroup
+	for i, url := range e.urls {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			evaluations[i], errs[i] = e.evaluateOn(ctx, url, body)
+		}(i, url)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	merged := &models.RuleEvaluation{Matches: []models.RuleMatch{}}
+	for _, evaluation := range evaluations {
+		if evaluation.EvaluatedAt.After(merged.EvaluatedAt) {
+			merged.EvaluatedAt = evaluation.EvaluatedAt
+		}
+		merged.SymbolsEvaluated += evaluation.SymbolsEvaluated
+		merged.SymbolsStale += evaluation.SymbolsStale
+		merged.Matches = append(merged.Matches, evaluation.Matches...)
+	}
+	sort.Slice(merged.Matches, func(i, j int) bool {
+		return merged.Matches[i].Symbol < merged.Matches[j].Symbol
+	})
+	return merged, nil
+}
+
+// evaluateOn evaluates the rule on a single scanner worker
+func (e *ScannerRuleEvaluator) evaluateOn(ctx context.Context, url string, body []byte) (*models.RuleEvaluation, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url+"/rules/evaluate", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scanner request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+e.token)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("scanner %s unavailable: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("scanner %s returned status %d: %s", url, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var evaluation models.RuleEvaluation
+	if err := json.NewDecoder(resp.Body).Decode(&evaluation); err != nil {
+		return nil, fmt.Errorf("failed to decode scanner %s response: %w", url, err)
+	}
+	return &evaluation, nil
+}
+
+// SetRuleEvaluator sets the evaluator for dry-run rule evaluations (nil = unavailable)
+func (h *RuleHandler) SetRuleEvaluator(evaluator RuleEvaluator) {
+	h.evaluator = evaluator
+}
+
+// EvaluateRule handles POST /api/v1/rules/evaluate. The body is an unsaved rule, which is
+// validated and compiled like a created rule and then evaluated against live scanner state,
+// returning the symbols it matches right now with the values of the metrics it uses.
+func (h *RuleHandler) EvaluateRule(w http.ResponseWriter, r *http.Request) {
+	if h.evaluator == nil {
+		respondWithError(w, http.StatusServiceUnavailable, "Rule evaluation unavailable: no scanner configured")
+		return
+	}
+
+	var rule models.Rule
+	if !decodeStrictJSON(w, r, &rule) {
+		return
+	}
+	// The ID is never taken from the request, so a dry run cannot share state with a saved rule
+	rule.ID = "dry-run"
+	rule.Enabled = true
+
+	if err := rules.ValidateRule(&rule); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.limits.CheckComplexity(&rule); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := h.compiler.CompileRule(&rule); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Failed to compile rule: "+err.Error())
+		return
+	}
+
+	evaluation, err := h.evaluator.EvaluateRule(r.Context(), &rule)
+	if err != nil {
+		logger.WithContext(r.Context()).Warn("Failed to evaluate rule against live state",
+			logger.ErrorField(err),
+			logger.String("rule_id", rule.ID),
+		)
+		respondWithError(w, http.StatusBadGateway, "Failed to evaluate rule: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, evaluation)
+}
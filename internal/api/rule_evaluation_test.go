@@ -0,0 +1,121 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+)
+
+const evaluateBody = `{"id": "rule-1", "name": "Price Above 100", "conditions": [{"metric": "price", "operator": ">", "value": 100}]}`
+
+// scannerToken is the control token the test scanner workers require
+const scannerToken = "scanner-token"
+
+// newScannerServer serves a fixed evaluation for POST /rules/evaluate. The rule ID must have
+// been replaced with the dry-run ID.
+func newScannerServer(t *testing.T, evaluation models.RuleEvaluation) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/rules/evaluate" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer "+scannerToken {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var rule models.Rule
+		if err := json.NewDecoder(r.Body).Decode(&rule); err != nil || rule.ID != "dry-run" {
+			http.Error(w, "bad rule", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(evaluation)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func evaluateRule(handler *RuleHandler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/v1/rules/evaluate", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	handler.EvaluateRule(w, req)
+	return w
+}
+
+func TestRuleHandler_EvaluateRule(t *testing.T) {
+	evaluatedAt := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+	worker1 := newScannerServer(t, models.RuleEvaluation{
+		EvaluatedAt:      evaluatedAt,
+		SymbolsEvaluated: 2,
+		Matches:          []models.RuleMatch{{Symbol: "TSLA", Metrics: map[string]float64{"price": 200}}},
+	})
+	worker2 := newScannerServer(t, models.RuleEvaluation{
+		EvaluatedAt:      evaluatedAt.Add(time.Second),
+		SymbolsEvaluated: 3,
+		SymbolsStale:     1,
+		Matches:          []models.RuleMatch{{Symbol: "AAPL", Metrics: map[string]float64{"price": 150}}},
+	})
+
+	handler := NewRuleHandler(rules.NewInMemoryRuleStore(), rules.NewCompiler(nil), nil)
+	handler.SetRuleEvaluator(NewScannerRuleEvaluator([]string{worker1.URL, worker2.URL + "/"}, scannerToken, time.Second))
+
+	w := evaluateRule(handler, evaluateBody)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var evaluation models.RuleEvaluation
+	if err := json.Unmarshal(w.Body.Bytes(), &evaluation); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if evaluation.SymbolsEvaluated != 5 || evaluation.SymbolsStale != 1 {
+		t.Errorf("Expected 5 symbols evaluated and 1 stale, got %d and %d", evaluation.SymbolsEvaluated, evaluation.SymbolsStale)
+	}
+	if !evaluation.EvaluatedAt.Equal(evaluatedAt.Add(time.Second)) {
+		t.Errorf("Expected the latest evaluation time, got %v", evaluation.EvaluatedAt)
+	}
+	if len(evaluation.Matches) != 2 || evaluation.Matches[0].Symbol != "AAPL" || evaluation.Matches[1].Symbol != "TSLA" {
+		t.Fatalf("Expected AAPL and TSLA matches, got %+v", evaluation.Matches)
+	}
+	if evaluation.Matches[0].Metrics["price"] != 150 {
+		t.Errorf("Expected AAPL price 150, got %v", evaluation.Matches[0].Metrics["price"])
+	}
+}
+
+func TestRuleHandler_EvaluateRule_Errors(t *testing.T) {
+	handler := NewRuleHandler(rules.NewInMemoryRuleStore(), rules.NewCompiler(nil), nil)
+
+	// No scanner configured
+	if w := evaluateRule(handler, evaluateBody); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d without an evaluator, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	worker := newScannerServer(t, models.RuleEvaluation{})
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	handler.SetRuleEvaluator(NewScannerRuleEvaluator([]string{worker.URL}, scannerToken, time.Second))
+
+	// Invalid rules are rejected before reaching the scanner
+	invalid := `{"name": "Broken", "conditions": [{"metric": "price", "operator": "~", "value": 100}]}`
+	if w := evaluateRule(handler, invalid); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an invalid rule, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	// A failing worker fails the evaluation
+	handler.SetRuleEvaluator(NewScannerRuleEvaluator([]string{worker.URL, down.URL}, scannerToken, time.Second))
+	if w := evaluateRule(handler, evaluateBody); w.Code != http.StatusBadGateway {
+		t.Errorf("Expected status %d with a worker down, got %d", http.StatusBadGateway, w.Code)
+	}
+
+	// A worker rejecting the token fails the evaluation
+	handler.SetRuleEvaluator(NewScannerRuleEvaluator([]string{worker.URL}, "wrong-token", time.Second))
+	if w := evaluateRule(handler, evaluateBody); w.Code != http.StatusBadGateway {
+		t.Errorf("Expected status %d with a rejected token, got %d", http.StatusBadGateway, w.Code)
+	}
+}
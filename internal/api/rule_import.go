@@ -0,0 +1,260 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// RuleImportFailure describes a rule that could not be imported
+type RuleImportFailure struct {
+	Index  int    `json:"index"` // Position of the rule in the imported array
+	RuleID string `json:"rule_id,omitempty"`
+	Error  string `json:"error"`
+}
+
+// RuleImportResult summarizes a rule import
+type RuleImportResult struct {
+	Atomic         bool                `json:"atomic"`
+	Created        []string            `json:"created"`
+	Updated        []string            `json:"updated"`
+	Failed         []RuleImportFailure `json:"failed"`
+	Imported       int                 `json:"imported"`
+	RollbackFailed []string            `json:"rollback_failed,omitempty"` // Rules left as imported because undoing them failed
+}
+
+// importedRule is a validated rule ready to be written, with the rule it replaces (nil = new rule)
+type importedRule struct {
+	index    int
+	rule     *models.Rule
+	existing *models.Rule
+}
+
+// ImportRules handles POST /api/v1/rules/import. The body is a JSON array of rules; rules with
+// the ID of an existing rule replace it, others are created and owned by the caller. Rules
+// owned by another user cannot be replaced. Rules failing validation are reported without
+// aborting the valid ones, unless ?atomic=true is set, in which case any failure rejects the
+// whole import.
+//
+// Every rule is validated before any is written, but the rule store has no transactions: if
+// a write fails part way through an atomic import, the rules already written are undone one
+// by one. Rules whose undo also fails are left as imported and listed in rollback_failed.
+func (h *RuleHandler) ImportRules(w http.ResponseWriter, r *http.Request) {
+	atomic := false
+	if value := r.URL.Query().Get("atomic"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid atomic parameter, must be true or false")
+			return
+		}
+		atomic = parsed
+	}
+
+	var imported []*models.Rule
+	if !decodeStrictJSON(w, r, &imported) {
+		return
+	}
+	if len(imported) == 0 {
+		respondWithError(w, http.StatusBadRequest, "No rules to import")
+		return
+	}
+
+	result := RuleImportResult{
+		Atomic:  atomic,
+		Created: []string{},
+		Updated: []string{},
+		Failed:  []RuleImportFailure{},
+	}
+
+	// Validate every rule before writing any of them
+	userID := getUserID(r)
+	now := time.Now()
+	seen := make(map[string]bool, len(imported))
+	var valid []importedRule
+	for i, rule := range imported {
+		if rule == nil {
+			result.Failed = append(result.Failed, RuleImportFailure{Index: i, Error: "rule cannot be null"})
+			continue
+		}
+		if rule.ID == "" {
+			rule.ID = uuid.New().String()
+		}
+		if seen[rule.ID] {
+			result.Failed = append(result.Failed, RuleImportFailure{Index: i, RuleID: rule.ID, Error: "duplicate rule ID in import"})
+			continue
+		}
+		seen[rule.ID] = true
+
+		// A rule that cannot be found is created
+		existing, err := h.ruleStore.GetRule(rule.ID)
+		if err != nil {
+			existing = nil
+		}
+		if existing != nil && !canManageRule(userID, existing.UserID) {
+			result.Failed = append(result.Failed, RuleImportFailure{Index: i, RuleID: rule.ID, Error: "rule belongs to another user"})
+			continue
+		}
+
+		// Replaced rules keep their owner; new rules belong to the caller
+		if existing != nil {
+			rule.UserID = existing.UserID
+			rule.CreatedAt = existing.CreatedAt
+		} else {
+			rule.UserID = userID
+			if rule.CreatedAt.IsZero() {
+				rule.CreatedAt = now
+			}
+		}
+		rule.UpdatedAt = now
+
+		if err := h.validateImportedRule(rule); err != nil {
+			result.Failed = append(result.Failed, RuleImportFailure{Index: i, RuleID: rule.ID, Error: err.Error()})
+			continue
+		}
+		valid = append(valid, importedRule{index: i, rule: rule, existing: existing})
+	}
+
+	if atomic && len(result.Failed) > 0 {
+		respondWithJSON(w, http.StatusBadRequest, result)
+		return
+	}
+
+	// Write the valid rules, undoing earlier writes if an atomic import fails part way
+	var written []importedRule
+	for _, item := range valid {
+		if err := h.writeImportedRule(item); err != nil {
+			result.Failed = append(result.Failed, RuleImportFailure{Index: item.index, RuleID: item.rule.ID, Error: err.Error()})
+			if atomic {
+				status := http.StatusInternalServerError
+				if errors.Is(err, rules.ErrTooManyRules) {
+					status = http.StatusForbidden
+				}
+				respondWithJSON(w, status, RuleImportResult{
+					Atomic:         true,
+					Created:        []string{},
+					Updated:        []string{},
+					Failed:         result.Failed,
+					RollbackFailed: h.rollbackImport(r, written),
+				})
+				return
+			}
+			continue
+		}
+
+		written = append(written, item)
+		if item.existing != nil {
+			result.Updated = append(result.Updated, item.rule.ID)
+		} else {
+			result.Created = append(result.Created, item.rule.ID)
+		}
+	}
+	result.Imported = len(written)
+
+	// Resync all rules to Redis so the scanner picks up the imported rules
+	if h.syncService != nil && len(written) > 0 {
+		if err := h.syncService.SyncAllRules(); err != nil {
+			logger.WithContext(r.Context()).Warn("Failed to sync imported rules to Redis",
+				logger.ErrorField(err),
+				logger.Int("imported", len(written)),
+			)
+			// Don't fail the request if sync fails
+		}
+	}
+
+	logger.WithContext(r.Context()).Info("Rules imported",
+		logger.Int("created", len(result.Created)),
+		logger.Int("updated", len(result.Updated)),
+		logger.Int("failed", len(result.Failed)),
+		logger.Bool("atomic", atomic),
+	)
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// validateImportedRule applies the checks of CreateRule and UpdateRule to an imported rule
+func (h *RuleHandler) validateImportedRule(rule *models.Rule) error {
+	if err := rules.ValidateRule(rule); err != nil {
+		return err
+	}
+	if err := h.limits.CheckComplexity(rule); err != nil {
+		return err
+	}
+	if _, err := h.compiler.CompileRule(rule); err != nil {
+		return fmt.Errorf("failed to compile rule: %w", err)
+	}
+	return nil
+}
+
+// writeImportedRule creates or replaces a rule, enforcing the per-user rule count for new rules
+func (h *RuleHandler) writeImportedRule(item importedRule) error {
+	if item.existing != nil {
+		if err := h.ruleStore.UpdateRule(item.rule); err != nil {
+			return fmt.Errorf("failed to update rule: %w", err)
+		}
+		return nil
+	}
+
+	if err := h.limits.CheckRuleCount(h.ruleStore, item.rule); err != nil {
+		return err
+	}
+	if err := h.ruleStore.AddRule(item.rule); err != nil {
+		return fmt.Errorf("failed to create rule: %w", err)
+	}
+	return nil
+}
+
+// rollbackImport undoes written rules in reverse order: created rules are deleted and
+// updated rules restored. It returns the IDs of the rules it could not undo.
+func (h *RuleHandler) rollbackImport(r *http.Request, written []importedRule) []string {
+	var failed []string
+	for i := len(written) - 1; i >= 0; i-- {
+		item := written[i]
+		var err error
+		if item.existing != nil {
+			err = h.ruleStore.UpdateRule(item.existing)
+		} else {
+			err = h.ruleStore.DeleteRule(item.rule.ID)
+		}
+		if err != nil {
+			logger.WithContext(r.Context()).Error("Failed to roll back imported rule",
+				logger.ErrorField(err),
+				logger.String("rule_id", item.rule.ID),
+			)
+			failed = append(failed, item.rule.ID)
+		}
+	}
+	return failed
+}
+
+// ExportRules handles GET /api/v1/rules/export, returning the caller's rules and the shared
+// rules as a downloadable JSON array that can be imported again with POST /api/v1/rules/import
+func (h *RuleHandler) ExportRules(w http.ResponseWriter, r *http.Request) {
+	allRules, err := h.ruleStore.GetAllRules()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve rules")
+		return
+	}
+
+	userID := getUserID(r)
+	exported := make([]*models.Rule, 0, len(allRules))
+	for _, rule := range allRules {
+		if canManageRule(userID, rule.UserID) {
+			exported = append(exported, rule)
+		}
+	}
+	sort.Slice(exported, func(i, j int) bool {
+		return exported[i].ID < exported[j].ID
+	})
+
+	filename := fmt.Sprintf("rules-%s.json", time.Now().UTC().Format("20060102-150405"))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	respondWithJSON(w, http.StatusOK, exported)
+}
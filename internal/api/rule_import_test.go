@@ -0,0 +1,235 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+)
+
+// importBody is an import with an update of rule-1, a new rule-2 and an invalid rule-3
+const importBody = `[
+	{"id": "rule-1", "name": "RSI Oversold Updated", "conditions": [{"metric": "rsi_14", "operator": "<", "value": 25}], "enabled": true},
+	{"id": "rule-2", "name": "Volume Spike", "conditions": [{"metric": "volume", "operator": ">", "value": 1000000}], "enabled": true},
+	{"id": "rule-3", "name": "Broken", "conditions": [{"metric": "rsi_14", "operator": "~", "value": 30}], "enabled": true}
+]`
+
+func newImportTestHandler(t *testing.T) (*RuleHandler, rules.RuleStore, time.Time) {
+	t.Helper()
+	ruleStore := rules.NewInMemoryRuleStore()
+	createdAt := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+	if err := ruleStore.AddRule(&models.Rule{
+		ID:         "rule-1",
+		Name:       "RSI Oversold",
+		Conditions: []models.Condition{{Metric: "rsi_14", Operator: "<", Value: 30.0}},
+		Enabled:    true,
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+	}); err != nil {
+		t.Fatalf("AddRule() error = %v", err)
+	}
+	return NewRuleHandler(ruleStore, rules.NewCompiler(nil), nil), ruleStore, createdAt
+}
+
+func importRules(handler *RuleHandler, query string, body string) (*httptest.ResponseRecorder, RuleImportResult) {
+	return importRulesAs(handler, "", query, body)
+}
+
+// importRulesAs imports rules as the given user (empty = no authenticated user)
+func importRulesAs(handler *RuleHandler, userID, query, body string) (*httptest.ResponseRecorder, RuleImportResult) {
+	req := httptest.NewRequest("POST", "/api/v1/rules/import"+query, bytes.NewBufferString(body))
+	if userID != "" {
+		req = req.WithContext(context.WithValue(req.Context(), "user_id", userID))
+	}
+	w := httptest.NewRecorder()
+	handler.ImportRules(w, req)
+
+	var result RuleImportResult
+	json.Unmarshal(w.Body.Bytes(), &result)
+	return w, result
+}
+
+func TestRuleHandler_ImportRules_PartialFailure(t *testing.T) {
+	handler, ruleStore, createdAt := newImportTestHandler(t)
+
+	w, result := importRules(handler, "", importBody)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	if result.Imported != 2 || len(result.Created) != 1 || result.Created[0] != "rule-2" ||
+		len(result.Updated) != 1 || result.Updated[0] != "rule-1" {
+		t.Errorf("Expected rule-2 created and rule-1 updated, got %+v", result)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Index != 2 || result.Failed[0].RuleID != "rule-3" {
+		t.Fatalf("Expected rule-3 at index 2 to fail, got %+v", result.Failed)
+	}
+	if !strings.Contains(result.Failed[0].Error, "operator") {
+		t.Errorf("Expected an invalid operator error, got %q", result.Failed[0].Error)
+	}
+
+	updated, err := ruleStore.GetRule("rule-1")
+	if err != nil {
+		t.Fatalf("GetRule() error = %v", err)
+	}
+	if updated.Name != "RSI Oversold Updated" || !updated.CreatedAt.Equal(createdAt) {
+		t.Errorf("Expected rule-1 updated with its creation time kept, got %+v", updated)
+	}
+	if _, err := ruleStore.GetRule("rule-2"); err != nil {
+		t.Errorf("Expected rule-2 to be created: %v", err)
+	}
+	if _, err := ruleStore.GetRule("rule-3"); err == nil {
+		t.Error("Expected invalid rule-3 not to be stored")
+	}
+}
+
+func TestRuleHandler_ImportRules_Atomic(t *testing.T) {
+	handler, ruleStore, _ := newImportTestHandler(t)
+
+	w, result := importRules(handler, "?atomic=true", importBody)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+	}
+	if result.Imported != 0 || len(result.Failed) != 1 || result.Failed[0].RuleID != "rule-3" {
+		t.Errorf("Expected nothing imported and rule-3 reported, got %+v", result)
+	}
+
+	unchanged, _ := ruleStore.GetRule("rule-1")
+	if unchanged.Name != "RSI Oversold" {
+		t.Errorf("Expected rule-1 unchanged, got %q", unchanged.Name)
+	}
+	if _, err := ruleStore.GetRule("rule-2"); err == nil {
+		t.Error("Expected rule-2 not to be created")
+	}
+}
+
+func TestRuleHandler_ImportRules_AtomicRollback(t *testing.T) {
+	handler, ruleStore, _ := newImportTestHandler(t)
+	handler.SetLimits(rules.RuleLimits{MaxRulesPerUser: 1})
+
+	// Both rules are valid, but the second exceeds user-1's rule limit once the first is written
+	body := `[
+		{"id": "rule-1", "name": "RSI Oversold Updated", "conditions": [{"metric": "rsi_14", "operator": "<", "value": 25}], "enabled": true},
+		{"id": "rule-a", "name": "A", "conditions": [{"metric": "rsi_14", "operator": "<", "value": 30}], "enabled": true},
+		{"id": "rule-b", "name": "B", "conditions": [{"metric": "rsi_14", "operator": ">", "value": 70}], "enabled": true}
+	]`
+	w, result := importRulesAs(handler, "user-1", "?atomic=true", body)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusForbidden, w.Code, w.Body.String())
+	}
+	if len(result.Failed) != 1 || result.Failed[0].RuleID != "rule-b" {
+		t.Errorf("Expected rule-b to fail, got %+v", result.Failed)
+	}
+
+	if _, err := ruleStore.GetRule("rule-a"); err == nil {
+		t.Error("Expected rule-a to be rolled back")
+	}
+	restored, _ := ruleStore.GetRule("rule-1")
+	if restored.Name != "RSI Oversold" {
+		t.Errorf("Expected rule-1 to be restored, got %q", restored.Name)
+	}
+}
+
+func TestRuleHandler_ImportRules_InvalidRequests(t *testing.T) {
+	handler, _, _ := newImportTestHandler(t)
+
+	for name, tc := range map[string]struct{ query, body string }{
+		"empty array":   {"", "[]"},
+		"not an array":  {"", `{"id": "rule-1"}`},
+		"invalid query": {"?atomic=maybe", importBody},
+	} {
+		if w, _ := importRules(handler, tc.query, tc.body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", name, http.StatusBadRequest, w.Code)
+		}
+	}
+
+	// Duplicate IDs within an import are rejected
+	body := `[
+		{"id": "rule-9", "name": "A", "conditions": [{"metric": "rsi_14", "operator": "<", "value": 30}], "enabled": true},
+		{"id": "rule-9", "name": "B", "conditions": [{"metric": "rsi_14", "operator": "<", "value": 20}], "enabled": true}
+	]`
+	_, result := importRules(handler, "", body)
+	if result.Imported != 1 || len(result.Failed) != 1 || result.Failed[0].Index != 1 {
+		t.Errorf("Expected the duplicate rule to fail, got %+v", result)
+	}
+}
+
+func TestRuleHandler_ExportRules(t *testing.T) {
+	handler, _, _ := newImportTestHandler(t)
+	importRules(handler, "", importBody)
+
+	req := httptest.NewRequest("GET", "/api/v1/rules/export", nil)
+	w := httptest.NewRecorder()
+	handler.ExportRules(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if disposition := w.Header().Get("Content-Disposition"); !strings.HasPrefix(disposition, "attachment; filename=\"rules-") {
+		t.Errorf("Expected an attachment, got %q", disposition)
+	}
+
+	var exported []*models.Rule
+	if err := json.Unmarshal(w.Body.Bytes(), &exported); err != nil {
+		t.Fatalf("Failed to unmarshal export: %v", err)
+	}
+	if len(exported) != 2 || exported[0].ID != "rule-1" || exported[1].ID != "rule-2" {
+		t.Fatalf("Expected rule-1 and rule-2 exported, got %d rules", len(exported))
+	}
+
+	// The export imports cleanly as updates
+	_, result := importRules(handler, "?atomic=true", w.Body.String())
+	if result.Imported != 2 || len(result.Updated) != 2 || len(result.Failed) != 0 {
+		t.Errorf("Expected the export to re-import as 2 updates, got %+v", result)
+	}
+}
+
+func TestRuleHandler_ImportRules_Ownership(t *testing.T) {
+	handler, ruleStore, _ := newImportTestHandler(t)
+	if err := ruleStore.AddRule(&models.Rule{
+		ID:         "rule-bob",
+		Name:       "Bob Rule",
+		UserID:     "bob",
+		Conditions: []models.Condition{{Metric: "rsi_14", Operator: "<", Value: 30.0}},
+		Enabled:    true,
+	}); err != nil {
+		t.Fatalf("AddRule() error = %v", err)
+	}
+
+	// Alice cannot replace Bob's rule or create rules on his behalf
+	body := `[
+		{"id": "rule-bob", "name": "Hijacked", "conditions": [{"metric": "rsi_14", "operator": "<", "value": 25}], "enabled": true},
+		{"id": "rule-new", "name": "New", "user_id": "bob", "conditions": [{"metric": "rsi_14", "operator": ">", "value": 70}], "enabled": true}
+	]`
+	_, result := importRulesAs(handler, "alice", "", body)
+	if len(result.Failed) != 1 || result.Failed[0].RuleID != "rule-bob" {
+		t.Errorf("Expected rule-bob to be rejected, got %+v", result.Failed)
+	}
+	if unchanged, _ := ruleStore.GetRule("rule-bob"); unchanged.Name != "Bob Rule" {
+		t.Errorf("Expected rule-bob unchanged, got %q", unchanged.Name)
+	}
+	if created, err := ruleStore.GetRule("rule-new"); err != nil || created.UserID != "alice" {
+		t.Errorf("Expected rule-new owned by alice, got %+v (err=%v)", created, err)
+	}
+
+	// Alice's export has her rules and the shared rule-1, not Bob's
+	req := httptest.NewRequest("GET", "/api/v1/rules/export", nil)
+	req = req.WithContext(context.WithValue(req.Context(), "user_id", "alice"))
+	w := httptest.NewRecorder()
+	handler.ExportRules(w, req)
+
+	var exported []*models.Rule
+	if err := json.Unmarshal(w.Body.Bytes(), &exported); err != nil {
+		t.Fatalf("Failed to unmarshal export: %v", err)
+	}
+	if len(exported) != 2 || exported[0].ID != "rule-1" || exported[1].ID != "rule-new" {
+		t.Errorf("Expected rule-1 and rule-new exported, got %d rules", len(exported))
+	}
+}
@@ -2,8 +2,12 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,6 +15,7 @@ import (
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
 	"github.com/mohamedkhairy/stock-scanner/internal/toplist"
 	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+	"gopkg.in/yaml.v3"
 )
 
 // ToplistHandler handles toplist management endpoints
@@ -141,8 +146,7 @@ func (h *ToplistHandler) CreateUserToplist(w http.ResponseWriter, r *http.Reques
 	userID := getUserID(r)
 
 	var config models.ToplistConfig
-	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeStrictJSON(w, r, &config) {
 		return
 	}
 
@@ -173,7 +177,7 @@ func (h *ToplistHandler) CreateUserToplist(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	logger.Info("Toplist created",
+	logger.WithContext(r.Context()).Info("Toplist created",
 		logger.String("toplist_id", config.ID),
 		logger.String("user_id", userID),
 		logger.String("name", config.Name),
@@ -226,8 +230,7 @@ func (h *ToplistHandler) UpdateUserToplist(w http.ResponseWriter, r *http.Reques
 	}
 
 	var config models.ToplistConfig
-	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+	if !decodeStrictJSON(w, r, &config) {
 		return
 	}
 
@@ -249,7 +252,7 @@ func (h *ToplistHandler) UpdateUserToplist(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	logger.Info("Toplist updated",
+	logger.WithContext(r.Context()).Info("Toplist updated",
 		logger.String("toplist_id", toplistID),
 		logger.String("user_id", userID),
 	)
@@ -257,6 +260,190 @@ func (h *ToplistHandler) UpdateUserToplist(w http.ResponseWriter, r *http.Reques
 	respondWithJSON(w, http.StatusOK, config)
 }
 
+// maxToplistImportSize bounds the size of an import document
+const maxToplistImportSize = 1 << 20
+
+// ToplistExport is the document produced by export and accepted by import
+type ToplistExport struct {
+	Toplists []models.ToplistConfig `json:"toplists"`
+}
+
+// ToplistImportRejection describes a toplist config that failed to import
+type ToplistImportRejection struct {
+	Index int    `json:"index"`
+	ID    string `json:"id,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Error string `json:"error"`
+}
+
+// ToplistImportResult reports the outcome of an import
+type ToplistImportResult struct {
+	Created  []string                 `json:"created"`
+	Updated  []string                 `json:"updated"`
+	Rejected []ToplistImportRejection `json:"rejected"`
+}
+
+// ExportUserToplists handles GET /api/v1/toplists/user/export
+// Returns the user's toplist configs as JSON (default) or YAML (?format=yaml)
+func (h *ToplistHandler) ExportUserToplists(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+
+	toplists, err := h.toplistStore.GetUserToplists(r.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve user toplists")
+		return
+	}
+
+	export := ToplistExport{Toplists: make([]models.ToplistConfig, 0, len(toplists))}
+	for _, config := range toplists {
+		export.Toplists = append(export.Toplists, *config)
+	}
+
+	if !isYAMLRequest(r) {
+		respondWithJSON(w, http.StatusOK, export)
+		return
+	}
+
+	data, err := marshalYAMLViaJSON(export)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to encode toplists")
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// ImportUserToplists handles POST /api/v1/toplists/user/import
+// Accepts an export document as JSON or YAML (Content-Type containing "yaml" or ?format=yaml).
+// Each config is validated independently; configs with an ID the user already owns are
+// updated, others are created. Invalid configs are reported and not persisted.
+func (h *ToplistHandler) ImportUserToplists(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := getUserID(r)
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxToplistImportSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, requestBodyTooLargeMessage(maxBytesErr.Limit))
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	var doc ToplistExport
+	if isYAMLRequest(r) {
+		err = unmarshalYAMLViaJSON(body, &doc)
+	} else {
+		err = json.Unmarshal(body, &doc)
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid import document: "+err.Error())
+		return
+	}
+
+	result := ToplistImportResult{
+		Created:  []string{},
+		Updated:  []string{},
+		Rejected: []ToplistImportRejection{},
+	}
+	reject := func(i int, config *models.ToplistConfig, reason string) {
+		result.Rejected = append(result.Rejected, ToplistImportRejection{
+			Index: i,
+			ID:    config.ID,
+			Name:  config.Name,
+			Error: reason,
+		})
+	}
+
+	now := time.Now()
+	for i := range doc.Toplists {
+		config := doc.Toplists[i]
+		if config.ID == "" {
+			config.ID = uuid.New().String()
+		}
+		config.UserID = userID
+		config.UpdatedAt = now
+
+		existing, err := h.toplistStore.GetToplistConfig(ctx, config.ID)
+		exists := err == nil && existing != nil
+		if exists && existing.UserID != userID {
+			reject(i, &config, "toplist ID belongs to another user")
+			continue
+		}
+		if exists {
+			config.CreatedAt = existing.CreatedAt
+		} else if config.CreatedAt.IsZero() {
+			config.CreatedAt = now
+		}
+
+		if err := config.Validate(); err != nil {
+			reject(i, &config, "Invalid toplist configuration: "+err.Error())
+			continue
+		}
+
+		if exists {
+			if err := h.toplistStore.UpdateToplist(ctx, &config); err != nil {
+				reject(i, &config, "Failed to update toplist")
+				continue
+			}
+			result.Updated = append(result.Updated, config.ID)
+		} else {
+			if err := h.toplistStore.CreateToplist(ctx, &config); err != nil {
+				reject(i, &config, "Failed to create toplist")
+				continue
+			}
+			result.Created = append(result.Created, config.ID)
+		}
+	}
+
+	logger.WithContext(ctx).Info("Toplists imported",
+		logger.String("user_id", userID),
+		logger.Int("created", len(result.Created)),
+		logger.Int("updated", len(result.Updated)),
+		logger.Int("rejected", len(result.Rejected)),
+	)
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// isYAMLRequest returns true if the request asks for YAML via ?format=yaml or a YAML content type
+func isYAMLRequest(r *http.Request) bool {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format != "" {
+		return format == "yaml" || format == "yml"
+	}
+	return strings.Contains(strings.ToLower(r.Header.Get("Content-Type")), "yaml")
+}
+
+// marshalYAMLViaJSON encodes v as YAML using its JSON field names
+func marshalYAMLViaJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(generic)
+}
+
+// unmarshalYAMLViaJSON decodes YAML into v using its JSON field names
+func unmarshalYAMLViaJSON(data []byte, v interface{}) error {
+	var generic interface{}
+	if err := yaml.Unmarshal(data, &generic); err != nil {
+		return fmt.Errorf("invalid YAML: %w", err)
+	}
+	jsonData, err := json.Marshal(generic)
+	if err != nil {
+		return fmt.Errorf("invalid YAML: %w", err)
+	}
+	return json.Unmarshal(jsonData, v)
+}
+
 // DeleteUserToplist handles DELETE /api/v1/toplists/user/:id
 func (h *ToplistHandler) DeleteUserToplist(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -283,7 +470,7 @@ func (h *ToplistHandler) DeleteUserToplist(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	logger.Info("Toplist deleted",
+	logger.WithContext(r.Context()).Info("Toplist deleted",
 		logger.String("toplist_id", toplistID),
 		logger.String("user_id", userID),
 	)
@@ -367,6 +554,52 @@ func (h *ToplistHandler) GetToplistRankings(w http.ResponseWriter, r *http.Reque
 	})
 }
 
+// PreviewToplist handles POST /api/v1/toplists/preview
+// The config is validated but not persisted; rankings come from the live system ZSETs
+func (h *ToplistHandler) PreviewToplist(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var config models.ToplistConfig
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Previews have no identity of their own
+	if config.ID == "" {
+		config.ID = "preview"
+	}
+	if config.Name == "" {
+		config.Name = "Preview"
+	}
+	config.UserID = getUserID(r)
+
+	if err := config.Validate(); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid toplist configuration: "+err.Error())
+		return
+	}
+
+	limit := parseIntQuery(r, "limit", 50, 1, 500)
+
+	rankings, total, err := h.toplistService.PreviewRankings(ctx, &config, limit)
+	if err != nil {
+		if errors.Is(err, toplist.ErrPreviewUnsupported) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Failed to compute preview")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"metric":      config.Metric,
+		"time_window": config.TimeWindow,
+		"sort_order":  config.SortOrder,
+		"rankings":    rankings,
+		"total":       total,
+	})
+}
+
 // Helper functions
 
 func getUserID(r *http.Request) string {
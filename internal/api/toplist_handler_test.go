@@ -55,6 +55,28 @@ func TestToplistHandler_CreateUserToplist(t *testing.T) {
 	}
 }
 
+func TestToplistHandler_CreateUserToplist_UnknownField(t *testing.T) {
+	mockStore := toplist.NewMockToplistStore()
+	mockRedis := storage.NewMockRedisClient()
+	mockUpdater := toplist.NewRedisToplistUpdater(mockRedis)
+	service := toplist.NewToplistService(mockStore, mockRedis, mockUpdater)
+	handler := NewToplistHandler(service, mockStore)
+
+	body := `{"name": "Test Toplist", "metric": "change_pct", "time_window": "5m", "sort_order": "desc", "enabled": true, "normalisation": "zscore"}`
+	req := httptest.NewRequest("POST", "/api/v1/toplists/user", bytes.NewReader([]byte(body)))
+	req = req.WithContext(context.WithValue(req.Context(), "user_id", "user-123"))
+	w := httptest.NewRecorder()
+
+	handler.CreateUserToplist(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("CreateUserToplist() status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte("normalisation")) {
+		t.Errorf("CreateUserToplist() error should name the unknown field, got %s", w.Body.String())
+	}
+}
+
 func TestToplistHandler_GetUserToplist(t *testing.T) {
 	mockStore := toplist.NewMockToplistStore()
 	mockRedis := storage.NewMockRedisClient()
@@ -243,3 +265,220 @@ func TestToplistHandler_GetSystemToplist(t *testing.T) {
 	}
 }
 
+
+func TestToplistHandler_ExportImportRoundTrip(t *testing.T) {
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			sourceStore := toplist.NewMockToplistStore()
+			mockRedis := storage.NewMockRedisClient()
+			source := NewToplistHandler(toplist.NewToplistService(sourceStore, mockRedis, toplist.NewRedisToplistUpdater(mockRedis)), sourceStore)
+
+			now := time.Now()
+			for _, config := range []*models.ToplistConfig{
+				{ID: "gainers", UserID: "user-123", Name: "Gainers", Metric: models.MetricChangePct, TimeWindow: models.Window5m, SortOrder: models.SortOrderDesc, Enabled: true, CreatedAt: now, UpdatedAt: now},
+				{ID: "volume", UserID: "user-123", Name: "Volume", Metric: models.MetricVolume, TimeWindow: models.Window1d, SortOrder: models.SortOrderDesc, Columns: []string{"symbol", "volume"}, MaxSize: 50, Enabled: true, CreatedAt: now, UpdatedAt: now},
+				{ID: "other-user", UserID: "user-456", Name: "Not Mine", Metric: models.MetricVolume, TimeWindow: models.Window1d, SortOrder: models.SortOrderDesc, Enabled: true, CreatedAt: now, UpdatedAt: now},
+			} {
+				sourceStore.CreateToplist(context.Background(), config)
+			}
+
+			// Export
+			req := httptest.NewRequest("GET", "/api/v1/toplists/user/export?format="+format, nil)
+			req = req.WithContext(context.WithValue(req.Context(), "user_id", "user-123"))
+			w := httptest.NewRecorder()
+			source.ExportUserToplists(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("ExportUserToplists() status = %d, want %d", w.Code, http.StatusOK)
+			}
+			exported := w.Body.Bytes()
+
+			// Import into a fresh store as another user: everything is created
+			targetStore := toplist.NewMockToplistStore()
+			target := NewToplistHandler(toplist.NewToplistService(targetStore, mockRedis, toplist.NewRedisToplistUpdater(mockRedis)), targetStore)
+			result := importToplists(t, target, "user-789", format, exported)
+			if len(result.Created) != 2 || len(result.Updated) != 0 || len(result.Rejected) != 0 {
+				t.Fatalf("Import into empty store = %+v, want 2 created", result)
+			}
+
+			imported, err := targetStore.GetToplistConfig(context.Background(), "volume")
+			if err != nil {
+				t.Fatalf("Imported toplist not stored: %v", err)
+			}
+			if imported.UserID != "user-789" || imported.MaxSize != 50 || len(imported.Columns) != 2 || imported.Metric != models.MetricVolume {
+				t.Errorf("Imported toplist = %+v, want round-tripped config owned by user-789", imported)
+			}
+
+			// Re-importing the same document updates
+			result = importToplists(t, target, "user-789", format, exported)
+			if len(result.Created) != 0 || len(result.Updated) != 2 {
+				t.Errorf("Re-import = %+v, want 2 updated", result)
+			}
+		})
+	}
+}
+
+func TestToplistHandler_ImportRejectsInvalid(t *testing.T) {
+	mockStore := toplist.NewMockToplistStore()
+	mockRedis := storage.NewMockRedisClient()
+	handler := NewToplistHandler(toplist.NewToplistService(mockStore, mockRedis, toplist.NewRedisToplistUpdater(mockRedis)), mockStore)
+
+	mockStore.CreateToplist(context.Background(), &models.ToplistConfig{
+		ID: "taken", UserID: "user-456", Name: "Someone Else's", Metric: models.MetricVolume,
+		TimeWindow: models.Window1d, SortOrder: models.SortOrderDesc, CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	})
+
+	doc := `
+toplists:
+  - name: Valid
+    metric: change_pct
+    time_window: 5m
+    sort_order: desc
+    enabled: true
+  - name: Bad Window
+    metric: change_pct
+    time_window: 7m
+    sort_order: desc
+  - id: taken
+    name: Hijack
+    metric: volume
+    time_window: 1d
+    sort_order: desc
+`
+	result := importToplists(t, handler, "user-123", "yaml", []byte(doc))
+
+	if len(result.Created) != 1 {
+		t.Errorf("Expected 1 created toplist, got %v", result.Created)
+	}
+	if len(result.Rejected) != 2 {
+		t.Fatalf("Expected 2 rejected toplists, got %+v", result.Rejected)
+	}
+	if result.Rejected[0].Index != 1 || result.Rejected[0].Name != "Bad Window" {
+		t.Errorf("Expected invalid time window to be rejected, got %+v", result.Rejected[0])
+	}
+	if result.Rejected[1].Index != 2 {
+		t.Errorf("Expected foreign toplist ID to be rejected, got %+v", result.Rejected[1])
+	}
+
+	toplists, _ := mockStore.GetUserToplists(context.Background(), "user-123")
+	if len(toplists) != 1 {
+		t.Errorf("Expected only the valid toplist persisted, got %d", len(toplists))
+	}
+	if taken, _ := mockStore.GetToplistConfig(context.Background(), "taken"); taken.UserID != "user-456" || taken.Name != "Someone Else's" {
+		t.Errorf("Expected other user's toplist untouched, got %+v", taken)
+	}
+
+	// Malformed documents are rejected outright
+	req := httptest.NewRequest("POST", "/api/v1/toplists/user/import", bytes.NewReader([]byte("{not json")))
+	w := httptest.NewRecorder()
+	handler.ImportUserToplists(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("ImportUserToplists() malformed status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	// Oversized documents are rejected, not truncated
+	req = httptest.NewRequest("POST", "/api/v1/toplists/user/import", bytes.NewReader(bytes.Repeat([]byte(" "), maxToplistImportSize+1)))
+	w = httptest.NewRecorder()
+	handler.ImportUserToplists(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("ImportUserToplists() oversized status = %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func importToplists(t *testing.T, handler *ToplistHandler, userID, format string, body []byte) ToplistImportResult {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/api/v1/toplists/user/import?format="+format, bytes.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), "user_id", userID))
+	w := httptest.NewRecorder()
+	handler.ImportUserToplists(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("ImportUserToplists() status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var result ToplistImportResult
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to unmarshal import result: %v", err)
+	}
+	return result
+}
+
+func TestToplistHandler_PreviewToplist(t *testing.T) {
+	mockStore := toplist.NewMockToplistStore()
+	mockRedis := storage.NewMockRedisClient()
+	mockUpdater := toplist.NewRedisToplistUpdater(mockRedis)
+	service := toplist.NewToplistService(mockStore, mockRedis, mockUpdater)
+	handler := NewToplistHandler(service, mockStore)
+
+	key := models.GetSystemToplistRedisKey(models.MetricChangePct, models.Window5m)
+	mockRedis.ZAddBatch(context.Background(), key, map[string]float64{
+		"AAPL": 2.5,
+		"TSLA": 7.1,
+		"MSFT": 4.0,
+		"GME":  1.2,
+	})
+
+	config := models.ToplistConfig{
+		Name:       "Gainers Preview",
+		Metric:     models.MetricChangePct,
+		TimeWindow: models.Window5m,
+		SortOrder:  models.SortOrderDesc,
+	}
+	body, _ := json.Marshal(config)
+	req := httptest.NewRequest("POST", "/api/v1/toplists/preview?limit=3", bytes.NewReader(body))
+	req = req.WithContext(context.WithValue(req.Context(), "user_id", "user-123"))
+	w := httptest.NewRecorder()
+
+	handler.PreviewToplist(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("PreviewToplist() status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var response struct {
+		Rankings []models.ToplistRanking `json:"rankings"`
+		Total    int64                   `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	want := []string{"TSLA", "MSFT", "AAPL"}
+	if len(response.Rankings) != len(want) {
+		t.Fatalf("PreviewToplist() returned %d rankings, want %d", len(response.Rankings), len(want))
+	}
+	for i, symbol := range want {
+		if response.Rankings[i].Symbol != symbol || response.Rankings[i].Rank != i+1 {
+			t.Errorf("Ranking %d = %s (rank %d), want %s (rank %d)", i, response.Rankings[i].Symbol, response.Rankings[i].Rank, symbol, i+1)
+		}
+	}
+	if response.Total != 4 {
+		t.Errorf("PreviewToplist() total = %d, want 4", response.Total)
+	}
+
+	// Preview must not persist the config
+	if toplists, _ := mockStore.GetUserToplists(context.Background(), "user-123"); len(toplists) != 0 {
+		t.Errorf("PreviewToplist() persisted %d toplists", len(toplists))
+	}
+}
+
+func TestToplistHandler_PreviewToplist_Invalid(t *testing.T) {
+	mockStore := toplist.NewMockToplistStore()
+	mockRedis := storage.NewMockRedisClient()
+	mockUpdater := toplist.NewRedisToplistUpdater(mockRedis)
+	service := toplist.NewToplistService(mockStore, mockRedis, mockUpdater)
+	handler := NewToplistHandler(service, mockStore)
+
+	tests := []models.ToplistConfig{
+		{Metric: "bogus", TimeWindow: models.Window5m, SortOrder: models.SortOrderDesc},
+		{Metric: models.MetricCustom, CustomMetric: "my_metric", TimeWindow: models.Window5m, SortOrder: models.SortOrderDesc},
+	}
+	for _, config := range tests {
+		body, _ := json.Marshal(config)
+		req := httptest.NewRequest("POST", "/api/v1/toplists/preview", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.PreviewToplist(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("PreviewToplist(%s) status = %d, want %d", config.Metric, w.Code, http.StatusBadRequest)
+		}
+	}
+}
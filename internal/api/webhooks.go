@@ -0,0 +1,116 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// WebhookHandler handles the endpoints users register webhooks with. The alert service
+// delivers to the stored webhooks, signing each request with the webhook's own secret.
+type WebhookHandler struct {
+	store     *storage.WebhookStore
+	ruleStore rules.RuleStore
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(store *storage.WebhookStore, ruleStore rules.RuleStore) *WebhookHandler {
+	return &WebhookHandler{
+		store:     store,
+		ruleStore: ruleStore,
+	}
+}
+
+// ListWebhooks handles GET /api/v1/webhooks, listing the user's webhooks. Secrets are only
+// returned when a webhook is created.
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.store.ListUserWebhooks(r.Context(), getUserID(r))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to list webhooks: "+err.Error())
+		return
+	}
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"webhooks": webhooks,
+		"count":    len(webhooks),
+	})
+}
+
+// CreateWebhook handles POST /api/v1/webhooks, registering a webhook for one of the user's
+// rules or, without a rule, for the alerts addressed to the user. The response carries the
+// generated signing secret, which is not returned again.
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID := getUserID(r)
+
+	var body struct {
+		URL    string `json:"url"`
+		RuleID string `json:"rule_id"`
+	}
+	if !decodeStrictJSON(w, r, &body) {
+		return
+	}
+
+	if body.RuleID != "" {
+		rule, err := h.ruleStore.GetRule(body.RuleID)
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, "Rule not found")
+			return
+		}
+		if !canManageRule(userID, rule.UserID) {
+			respondWithError(w, http.StatusForbidden, "Not allowed to receive this rule's alerts")
+			return
+		}
+	}
+
+	webhook := &models.Webhook{
+		UserID: userID,
+		URL:    body.URL,
+		RuleID: body.RuleID,
+	}
+	if err := webhook.Validate(); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.store.CreateWebhook(r.Context(), webhook); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create webhook: "+err.Error())
+		return
+	}
+
+	logger.WithContext(r.Context()).Info("Webhook registered",
+		logger.String("webhook_id", webhook.ID),
+		logger.String("user_id", userID),
+		logger.String("rule_id", webhook.RuleID),
+	)
+
+	respondWithJSON(w, http.StatusCreated, webhook)
+}
+
+// DeleteWebhook handles DELETE /api/v1/webhooks/{id}, removing one of the user's webhooks
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	webhook, err := h.store.GetWebhook(r.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get webhook: "+err.Error())
+		return
+	}
+	// Other users' webhooks are reported as missing rather than forbidden, so IDs do not leak
+	if webhook == nil || webhook.UserID != getUserID(r) {
+		respondWithError(w, http.StatusNotFound, "Webhook not found: "+id)
+		return
+	}
+
+	if err := h.store.DeleteWebhook(r.Context(), id); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete webhook: "+err.Error())
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Webhook deleted"})
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+// setupWebhookHandler creates a webhook handler with a rule owned by alice and a rule
+// without an owner
+func setupWebhookHandler(t *testing.T) (*WebhookHandler, *storage.WebhookStore) {
+	t.Helper()
+	ruleStore := rules.NewInMemoryRuleStore()
+	for _, rule := range []*models.Rule{
+		{ID: "rule-alice", Name: "Alice Rule", UserID: "alice", Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}}},
+		{ID: "rule-shared", Name: "Shared Rule", Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}}},
+	} {
+		if err := ruleStore.AddRule(rule); err != nil {
+			t.Fatalf("Failed to add rule: %v", err)
+		}
+	}
+
+	store := storage.NewWebhookStore(storage.NewMockRedisClient())
+	return NewWebhookHandler(store, ruleStore), store
+}
+
+func createWebhook(handler *WebhookHandler, userID, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/v1/webhooks", bytes.NewBufferString(body))
+	handler.CreateWebhook(w, asUser(req, userID))
+	return w
+}
+
+func TestWebhookHandler_CreateWebhook(t *testing.T) {
+	handler, store := setupWebhookHandler(t)
+
+	tests := []struct {
+		name   string
+		user   string
+		body   string
+		status int
+	}{
+		{"own rule", "alice", `{"url": "https://alice.example.com/hook", "rule_id": "rule-alice"}`, http.StatusCreated},
+		{"shared rule", "bob", `{"url": "https://bob.example.com/hook", "rule_id": "rule-shared"}`, http.StatusCreated},
+		{"own alerts", "bob", `{"url": "https://bob.example.com/alerts"}`, http.StatusCreated},
+		{"other user's rule", "bob", `{"url": "https://bob.example.com/hook", "rule_id": "rule-alice"}`, http.StatusForbidden},
+		{"missing rule", "bob", `{"url": "https://bob.example.com/hook", "rule_id": "rule-missing"}`, http.StatusNotFound},
+		{"invalid URL", "bob", `{"url": "ftp://bob.example.com/hook"}`, http.StatusBadRequest},
+		{"unknown field", "bob", `{"url": "https://bob.example.com/hook", "secret": "mine"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := createWebhook(handler, tt.user, tt.body)
+			if w.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusCreated {
+				return
+			}
+
+			var created models.Webhook
+			if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if created.UserID != tt.user {
+				t.Errorf("Expected webhook owned by %s, got %s", tt.user, created.UserID)
+			}
+			if created.Secret == "" {
+				t.Error("Expected the create response to carry the signing secret")
+			}
+		})
+	}
+
+	webhooks, err := store.ListWebhooks(context.Background())
+	if err != nil {
+		t.Fatalf("ListWebhooks() error = %v", err)
+	}
+	if len(webhooks) != 3 {
+		t.Errorf("Expected 3 stored webhooks, got %d", len(webhooks))
+	}
+}
+
+func TestWebhookHandler_ListWebhooks(t *testing.T) {
+	handler, _ := setupWebhookHandler(t)
+	createWebhook(handler, "alice", `{"url": "https://alice.example.com/hook"}`)
+	createWebhook(handler, "bob", `{"url": "https://bob.example.com/hook"}`)
+
+	w := httptest.NewRecorder()
+	handler.ListWebhooks(w, asUser(httptest.NewRequest("GET", "/api/v1/webhooks", nil), "alice"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var resp struct {
+		Webhooks []models.Webhook `json:"webhooks"`
+		Count    int              `json:"count"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Count != 1 || resp.Webhooks[0].UserID != "alice" {
+		t.Fatalf("Expected alice's webhook only, got %+v", resp.Webhooks)
+	}
+	if resp.Webhooks[0].Secret != "" {
+		t.Error("Expected the secret to be omitted from the list")
+	}
+}
+
+func TestWebhookHandler_DeleteWebhook(t *testing.T) {
+	handler, store := setupWebhookHandler(t)
+
+	var created models.Webhook
+	if err := json.NewDecoder(createWebhook(handler, "alice", `{"url": "https://alice.example.com/hook"}`).Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	deleteAs := func(userID string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("DELETE", "/api/v1/webhooks/"+created.ID, nil)
+		req = mux.SetURLVars(req, map[string]string{"id": created.ID})
+		handler.DeleteWebhook(w, asUser(req, userID))
+		return w.Code
+	}
+
+	if code := deleteAs("bob"); code != http.StatusNotFound {
+		t.Errorf("Expected another user's delete to return %d, got %d", http.StatusNotFound, code)
+	}
+	if code := deleteAs("alice"); code != http.StatusOK {
+		t.Errorf("Expected the owner's delete to return %d, got %d", http.StatusOK, code)
+	}
+
+	webhook, err := store.GetWebhook(context.Background(), created.ID)
+	if err != nil {
+		t.Fatalf("GetWebhook() error = %v", err)
+	}
+	if webhook != nil {
+		t.Error("Expected the webhook to be deleted")
+	}
+}
@@ -1,6 +1,7 @@
 package bars
 
 import (
+	"fmt"
 	"sync"
 	"time"
 
@@ -8,19 +9,45 @@ import (
 	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
 )
 
-// Aggregator aggregates ticks into 1-minute bars
+// Aggregator aggregates ticks into 1-minute bars, optionally rolled up into longer timeframes
 type Aggregator struct {
 	mu          sync.RWMutex
 	liveBars    map[string]*models.LiveBar // Map of symbol -> current live bar
 	onBarFinal  func(*models.Bar1m)       // Callback when a bar is finalized
 	onBarUpdate func(*models.LiveBar)      // Callback when a live bar is updated
+	location    *time.Location             // Exchange timezone bar timestamps are expressed in (nil = tick's location)
+	vwapExcluded map[string]bool           // Tick types that update the bar but not its VWAP
+	rollups     []*timeframeRollup         // Longer timeframes finalized bars are rolled up into
 }
 
 // NewAggregator creates a new bar aggregator
 func NewAggregator() *Aggregator {
-	return &Aggregator{
+	return NewAggregatorWithConfig(DefaultAggregatorConfig())
+}
+
+// NewAggregatorWithConfig creates a new bar aggregator rolling finalized bars up into the
+// configured timeframes. Invalid or duplicate timeframes are skipped.
+func NewAggregatorWithConfig(config AggregatorConfig) *Aggregator {
+	a := &Aggregator{
 		liveBars: make(map[string]*models.LiveBar),
 	}
+
+	seen := make(map[time.Duration]bool, len(config.Timeframes))
+	for _, timeframe := range config.Timeframes {
+		if err := validateTimeframe(timeframe); err != nil {
+			logger.Warn("Skipping invalid bar timeframe",
+				logger.ErrorField(err),
+				logger.Duration("timeframe", timeframe),
+			)
+			continue
+		}
+		if !seen[timeframe] {
+			seen[timeframe] = true
+			a.rollups = append(a.rollups, newTimeframeRollup(timeframe))
+		}
+	}
+
+	return a
 }
 
 // SetOnBarFinal sets the callback function to be called when a bar is finalized
@@ -30,6 +57,51 @@ func (a *Aggregator) SetOnBarFinal(callback func(*models.Bar1m)) {
 	a.onBarFinal = callback
 }
 
+// SetOnTimeframeBarFinal sets the callback function to be called when a bar of a configured
+// timeframe is finalized
+func (a *Aggregator) SetOnTimeframeBarFinal(timeframe time.Duration, callback func(*models.Bar1m)) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, rollup := range a.rollups {
+		if rollup.timeframe == timeframe {
+			rollup.onBarFinal = callback
+			return nil
+		}
+	}
+	return fmt.Errorf("timeframe %s is not configured", TimeframeLabel(timeframe))
+}
+
+// Timeframes returns the configured timeframes bars are rolled up into
+func (a *Aggregator) Timeframes() []time.Duration {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	timeframes := make([]time.Duration, 0, len(a.rollups))
+	for _, rollup := range a.rollups {
+		timeframes = append(timeframes, rollup.timeframe)
+	}
+	return timeframes
+}
+
+// SetLocation sets the exchange timezone bar timestamps are aligned to
+func (a *Aggregator) SetLocation(loc *time.Location) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.location = loc
+}
+
+// SetVWAPExcludedTypes sets the tick types (e.g. "quote") that update bar prices and
+// volume but are left out of the size-weighted VWAP
+func (a *Aggregator) SetVWAPExcludedTypes(types []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.vwapExcluded = make(map[string]bool, len(types))
+	for _, tickType := range types {
+		a.vwapExcluded[tickType] = true
+	}
+}
+
 // SetOnBarUpdate sets the callback function to be called when a live bar is updated
 func (a *Aggregator) SetOnBarUpdate(callback func(*models.LiveBar)) {
 	a.mu.Lock()
@@ -51,12 +123,12 @@ func (a *Aggregator) ProcessTick(tick *models.Tick) error {
 		return err
 	}
 
-	// Truncate timestamp to minute boundary
-	minuteStart := tick.Timestamp.Truncate(time.Minute)
-
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	// Align timestamp to the exchange clock's minute boundary
+	minuteStart := models.AlignToMinute(tick.Timestamp, a.location)
+
 	// Get or create live bar for this symbol
 	liveBar, exists := a.liveBars[tick.Symbol]
 
@@ -68,6 +140,7 @@ func (a *Aggregator) ProcessTick(tick *models.Tick) error {
 			// Call callback outside of lock to avoid deadlock
 			go a.onBarFinal(finalizedBar)
 		}
+		a.rollUp(finalizedBar)
 
 		logger.Debug("Bar finalized",
 			logger.String("symbol", tick.Symbol),
@@ -93,7 +166,11 @@ func (a *Aggregator) ProcessTick(tick *models.Tick) error {
 	}
 
 	// Update the live bar with the tick
-	liveBar.Update(tick)
+	if a.vwapExcluded[tick.Type] {
+		liveBar.UpdateExcludingVWAP(tick)
+	} else {
+		liveBar.Update(tick)
+	}
 
 	// Call update callback if set
 	if a.onBarUpdate != nil {
@@ -151,11 +228,21 @@ func (a *Aggregator) FinalizeBar(symbol string) *models.Bar1m {
 	if a.onBarFinal != nil {
 		go a.onBarFinal(finalizedBar)
 	}
+	a.rollUp(finalizedBar)
+
+	// Emit the symbol's partial timeframe bars too, since no later bar will complete them
+	for _, rollup := range a.rollups {
+		if bar := rollup.Flush(symbol); bar != nil {
+			rollup.emit(bar)
+		}
+	}
 
 	return finalizedBar
 }
 
-// FinalizeAllBars finalizes all current live bars (useful for shutdown)
+// FinalizeAllBars finalizes all current live bars (useful for shutdown). Timeframe bars whose
+// bucket has not completed stay open; take them with TakeOpenTimeframeBars to save them for
+// the next start rather than emitting them short.
 func (a *Aggregator) FinalizeAllBars() []*models.Bar1m {
 	a.mu.Lock()
 	defer a.mu.Unlock()
@@ -168,6 +255,7 @@ func (a *Aggregator) FinalizeAllBars() []*models.Bar1m {
 		if a.onBarFinal != nil {
 			go a.onBarFinal(finalizedBar)
 		}
+		a.rollUp(finalizedBar)
 
 		logger.Debug("Bar finalized on shutdown",
 			logger.String("symbol", symbol),
@@ -181,6 +269,43 @@ func (a *Aggregator) FinalizeAllBars() []*models.Bar1m {
 	return finalizedBars
 }
 
+// TakeOpenTimeframeBars removes and returns the timeframe bars whose bucket has not completed
+func (a *Aggregator) TakeOpenTimeframeBars() []OpenTimeframeBar {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var open []OpenTimeframeBar
+	for _, rollup := range a.rollups {
+		open = append(open, rollup.TakeOpen()...)
+	}
+	return open
+}
+
+// RestoreOpenTimeframeBars resumes timeframe bars saved at the last shutdown. Bars of
+// timeframes that are no longer configured are dropped.
+func (a *Aggregator) RestoreOpenTimeframeBars(open []OpenTimeframeBar) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, bar := range open {
+		for _, rollup := range a.rollups {
+			if rollup.timeframe == bar.Timeframe {
+				rollup.Restore(bar)
+			}
+		}
+	}
+}
+
+// rollUp adds a finalized 1m bar to each timeframe, emitting the timeframe bars it completes.
+// Must be called with a.mu held.
+func (a *Aggregator) rollUp(bar *models.Bar1m) {
+	for _, rollup := range a.rollups {
+		for _, completed := range rollup.Add(bar) {
+			rollup.emit(completed)
+		}
+	}
+}
+
 // GetSymbolCount returns the number of symbols with active live bars
 func (a *Aggregator) GetSymbolCount() int {
 	a.mu.RLock()
@@ -1,6 +1,7 @@
 package bars
 
 import (
+	"sort"
 	"sync"
 	"testing"
 	"time"
@@ -143,6 +144,67 @@ func TestAggregator_VWAPCalculation(t *testing.T) {
 	assert.InDelta(t, expectedVWAP, finalizedBar.VWAP, 0.01)
 }
 
+func TestAggregator_VWAPSizeWeighted(t *testing.T) {
+	agg := NewAggregator()
+	agg.SetVWAPExcludedTypes([]string{"quote"})
+	now := time.Now().Truncate(time.Minute)
+
+	ticks := []*models.Tick{
+		{Symbol: "AAPL", Price: 100.00, Size: 1, Timestamp: now, Type: "trade"},
+		{Symbol: "AAPL", Price: 101.50, Size: 999, Timestamp: now.Add(5 * time.Second), Type: "trade"},
+		{Symbol: "AAPL", Price: 120.00, Size: 0, Timestamp: now.Add(10 * time.Second), Type: "trade"},
+		{Symbol: "AAPL", Price: 99.25, Size: 250, Timestamp: now.Add(15 * time.Second), Type: "trade"},
+		{Symbol: "AAPL", Price: 130.00, Size: 5000, Timestamp: now.Add(20 * time.Second), Type: "quote"},
+		{Symbol: "AAPL", Price: 100.75, Size: 50, Timestamp: now.Add(25 * time.Second), Type: "trade"},
+	}
+	for _, tick := range ticks {
+		require.NoError(t, agg.ProcessTick(tick))
+	}
+
+	// Independent calculation: average of eligible trade prices weighted by their share of eligible volume
+	var eligibleVolume float64
+	for _, tick := range ticks {
+		if tick.Type == "trade" && tick.Size > 0 {
+			eligibleVolume += float64(tick.Size)
+		}
+	}
+	var independent float64
+	for _, tick := range ticks {
+		if tick.Type == "trade" && tick.Size > 0 {
+			independent += tick.Price * (float64(tick.Size) / eligibleVolume)
+		}
+	}
+
+	// (100*1 + 101.5*999 + 99.25*250 + 100.75*50) / 1300 = 131348.5 / 1300
+	expectedVWAP := 131348.5 / 1300.0
+
+	bar := agg.GetLiveBar("AAPL").ToBar1m()
+	assert.InDelta(t, expectedVWAP, bar.VWAP, 1e-9)
+	assert.InDelta(t, independent, bar.VWAP, 1e-9)
+
+	// A simple average of trade prices would differ
+	assert.NotEqual(t, (100.00+101.50+120.00+99.25+100.75)/5, bar.VWAP)
+
+	// Zero-size and excluded ticks still move the bar's prices
+	assert.Equal(t, 130.00, bar.High)
+	assert.Equal(t, 100.75, bar.Close)
+	assert.Equal(t, int64(6300), bar.Volume)
+}
+
+func TestAggregator_VWAPWithoutEligibleVolume(t *testing.T) {
+	agg := NewAggregator()
+	agg.SetVWAPExcludedTypes([]string{"quote"})
+	now := time.Now().Truncate(time.Minute)
+
+	require.NoError(t, agg.ProcessTick(&models.Tick{Symbol: "AAPL", Price: 150.0, Size: 0, Timestamp: now, Type: "trade"}))
+	require.NoError(t, agg.ProcessTick(&models.Tick{Symbol: "AAPL", Price: 151.0, Size: 100, Timestamp: now, Type: "quote"}))
+
+	// No weighted trades: VWAP is unset and consumers fall back to the close
+	bar := agg.GetLiveBar("AAPL").ToBar1m()
+	assert.Equal(t, 0.0, bar.VWAP)
+	assert.Equal(t, 151.0, bar.Close)
+}
+
 func TestAggregator_MultipleSymbols(t *testing.T) {
 	agg := NewAggregator()
 	now := time.Now().Truncate(time.Minute)
@@ -302,3 +364,106 @@ func TestAggregator_GetAllLiveBars(t *testing.T) {
 	assert.Equal(t, 150.0, aaplBar.Open) // Original value unchanged
 }
 
+
+// collectFinalizedBars feeds one tick per timestamp through the aggregator and returns the finalized bars
+func collectFinalizedBars(t *testing.T, agg *Aggregator, timestamps []time.Time) []*models.Bar1m {
+	t.Helper()
+
+	var finalizedBars []*models.Bar1m
+	var finalizedMu sync.Mutex
+	agg.SetOnBarFinal(func(bar *models.Bar1m) {
+		finalizedMu.Lock()
+		defer finalizedMu.Unlock()
+		finalizedBars = append(finalizedBars, bar)
+	})
+
+	for _, ts := range timestamps {
+		require.NoError(t, agg.ProcessTick(&models.Tick{
+			Symbol:    "AAPL",
+			Price:     150.0,
+			Size:      100,
+			Timestamp: ts,
+			Type:      "trade",
+		}))
+	}
+	agg.FinalizeAllBars()
+	time.Sleep(50 * time.Millisecond)
+
+	finalizedMu.Lock()
+	defer finalizedMu.Unlock()
+	sort.Slice(finalizedBars, func(i, j int) bool {
+		return finalizedBars[i].Timestamp.Before(finalizedBars[j].Timestamp)
+	})
+	return finalizedBars
+}
+
+func TestAggregator_ExchangeTimezoneAcrossDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	t.Run("fall back", func(t *testing.T) {
+		// 2024-11-03: 01:00-01:59 ET happens twice (EDT, then EST)
+		agg := NewAggregator()
+		agg.SetLocation(newYork)
+
+		firstPass := time.Date(2024, 11, 3, 5, 30, 15, 0, time.UTC)  // 01:30:15 EDT
+		secondPass := time.Date(2024, 11, 3, 6, 30, 45, 0, time.UTC) // 01:30:45 EST
+		bars := collectFinalizedBars(t, agg, []time.Time{
+			firstPass,
+			firstPass.Add(time.Minute),
+			secondPass,
+			secondPass.Add(time.Minute),
+		})
+
+		require.Len(t, bars, 4, "Repeated wall-clock minutes must produce distinct bars")
+		for i, bar := range bars {
+			assert.Equal(t, newYork, bar.Timestamp.Location())
+			assert.Equal(t, 0, bar.Timestamp.Second())
+			if i > 0 && i != 2 {
+				assert.Equal(t, time.Minute, bar.Timestamp.Sub(bars[i-1].Timestamp))
+			}
+		}
+
+		// Both passes show 01:30 on the exchange clock but are an hour apart
+		assert.Equal(t, 1, bars[0].Timestamp.Hour())
+		assert.Equal(t, 30, bars[0].Timestamp.Minute())
+		assert.Equal(t, 1, bars[2].Timestamp.Hour())
+		assert.Equal(t, 30, bars[2].Timestamp.Minute())
+		assert.Equal(t, time.Hour, bars[2].Timestamp.Sub(bars[0].Timestamp))
+	})
+
+	t.Run("spring forward", func(t *testing.T) {
+		// 2024-03-10: 01:59 EST is followed by 03:00 EDT
+		agg := NewAggregator()
+		agg.SetLocation(newYork)
+
+		lastEST := time.Date(2024, 3, 10, 6, 59, 30, 0, time.UTC) // 01:59:30 EST
+		bars := collectFinalizedBars(t, agg, []time.Time{
+			lastEST,
+			lastEST.Add(time.Minute), // 03:00:30 EDT
+		})
+
+		require.Len(t, bars, 2)
+		assert.Equal(t, time.Minute, bars[1].Timestamp.Sub(bars[0].Timestamp))
+		assert.Equal(t, 1, bars[0].Timestamp.Hour())
+		assert.Equal(t, 59, bars[0].Timestamp.Minute())
+		assert.Equal(t, 3, bars[1].Timestamp.Hour())
+		assert.Equal(t, 0, bars[1].Timestamp.Minute())
+	})
+
+	t.Run("hour has sixty bars", func(t *testing.T) {
+		agg := NewAggregator()
+		agg.SetLocation(newYork)
+
+		// One tick per minute for the hour after the fall-back transition (01:00-01:59 EST)
+		start := time.Date(2024, 11, 3, 6, 0, 10, 0, time.UTC)
+		timestamps := make([]time.Time, 0, 60)
+		for i := 0; i < 60; i++ {
+			timestamps = append(timestamps, start.Add(time.Duration(i)*time.Minute))
+		}
+
+		bars := collectFinalizedBars(t, agg, timestamps)
+		require.Len(t, bars, 60)
+		assert.Equal(t, 59*time.Minute, bars[59].Timestamp.Sub(bars[0].Timestamp))
+	})
+}
@@ -0,0 +1,295 @@
+package bars
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// OpenTimeframeBarsKeyPrefix prefixes the Redis key the open timeframe bars of a consumer are
+// saved under at shutdown
+const OpenTimeframeBarsKeyPrefix = "bars:timeframes:open"
+
+// openTimeframeBarsTTL bounds how long saved open timeframe bars wait for a restart
+const openTimeframeBarsTTL = 24 * time.Hour
+
+// AggregatorConfig holds configuration for the bar aggregator
+type AggregatorConfig struct {
+	Timeframes []time.Duration // Longer timeframes finalized 1m bars are rolled up into, e.g. 5m and 15m (default: none)
+}
+
+// DefaultAggregatorConfig returns default configuration
+func DefaultAggregatorConfig() AggregatorConfig {
+	return AggregatorConfig{}
+}
+
+// ParseTimeframes parses timeframes such as "5m", "15m" or "1h". Each must be a whole number
+// of minutes longer than one minute.
+func ParseTimeframes(values []string) ([]time.Duration, error) {
+	timeframes := make([]time.Duration, 0, len(values))
+	seen := make(map[time.Duration]bool, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		timeframe, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeframe %q: %w", value, err)
+		}
+		if err := validateTimeframe(timeframe); err != nil {
+			return nil, fmt.Errorf("invalid timeframe %q: %w", value, err)
+		}
+		if !seen[timeframe] {
+			seen[timeframe] = true
+			timeframes = append(timeframes, timeframe)
+		}
+	}
+	return timeframes, nil
+}
+
+// validateTimeframe checks that a timeframe can be composed of whole 1m bars
+func validateTimeframe(timeframe time.Duration) error {
+	if timeframe <= time.Minute || timeframe%time.Minute != 0 {
+		return fmt.Errorf("must be a whole number of minutes longer than 1m")
+	}
+	return nil
+}
+
+// TimeframeLabel returns the label of a timeframe in minutes, e.g. "5m"
+func TimeframeLabel(timeframe time.Duration) string {
+	return fmt.Sprintf("%dm", int64(timeframe/time.Minute))
+}
+
+// TimeframeStream returns the stream bars of a timeframe are published to, e.g. "bars.finalized.5m"
+func TimeframeStream(finalizedStream string, timeframe time.Duration) string {
+	return finalizedStream + "." + TimeframeLabel(timeframe)
+}
+
+// rollupBar is a timeframe bar being composed from 1m bars
+type rollupBar struct {
+	bar         *models.Bar1m
+	firstMinute time.Time // Earliest constituent, whose open is the bar's open
+	lastMinute  time.Time // Latest constituent, whose close is the bar's close
+	vwapNum     float64   // Sum of constituent VWAP * volume
+	vwapDenom   float64   // Sum of volume of constituents with a VWAP
+	restoredTo  time.Time // Last minute merged before a restart (zero if not restored)
+}
+
+// OpenTimeframeBar is a timeframe bar whose bucket had not completed at shutdown. It is saved
+// and restored on the next start, so the bucket is emitted once, with all its minutes.
+type OpenTimeframeBar struct {
+	Timeframe   time.Duration `json:"timeframe"`
+	Bar         *models.Bar1m `json:"bar"`
+	FirstMinute time.Time     `json:"first_minute"`
+	LastMinute  time.Time     `json:"last_minute"`
+	VWAPNum     float64       `json:"vwap_num"`
+	VWAPDenom   float64       `json:"vwap_denom"`
+}
+
+// OpenTimeframeBarsKey returns the Redis key the open timeframe bars of a consumer are saved
+// under, e.g. "bars:timeframes:open:bars-consumer-host1"
+func OpenTimeframeBarsKey(consumerName string) string {
+	return OpenTimeframeBarsKeyPrefix + ":" + consumerName
+}
+
+// SaveOpenTimeframeBars saves open timeframe bars for the next start to restore
+func SaveOpenTimeframeBars(ctx context.Context, redis storage.RedisClient, key string, open []OpenTimeframeBar) error {
+	if len(open) == 0 {
+		return redis.Delete(ctx, key)
+	}
+	if err := redis.Set(ctx, key, open, openTimeframeBarsTTL); err != nil {
+		return fmt.Errorf("failed to save open timeframe bars: %w", err)
+	}
+	return nil
+}
+
+// LoadOpenTimeframeBars takes the open timeframe bars saved at the last shutdown, deleting them
+// so they are restored once
+func LoadOpenTimeframeBars(ctx context.Context, redis storage.RedisClient, key string) ([]OpenTimeframeBar, error) {
+	var open []OpenTimeframeBar
+	if err := redis.GetJSON(ctx, key, &open); err != nil {
+		return nil, fmt.Errorf("failed to load open timeframe bars: %w", err)
+	}
+	if len(open) == 0 {
+		return nil, nil
+	}
+	if err := redis.Delete(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to delete open timeframe bars: %w", err)
+	}
+	return open, nil
+}
+
+// timeframeRollup rolls finalized 1m bars up into bars of a longer timeframe. Buckets are
+// aligned on absolute time like 1m bars, so 5m bars start on :00, :05, ... Missing 1m bars
+// leave the bucket short: it is finalized once its last minute or a later bucket's bar arrives.
+type timeframeRollup struct {
+	timeframe  time.Duration
+	label      string
+	onBarFinal func(*models.Bar1m)
+	bars       map[string]*rollupBar // Map of symbol -> bar being composed
+	finalized  map[string]time.Time  // Map of symbol -> start of the last bucket completed
+}
+
+// newTimeframeRollup creates a rollup for a timeframe
+func newTimeframeRollup(timeframe time.Duration) *timeframeRollup {
+	return &timeframeRollup{
+		timeframe: timeframe,
+		label:     TimeframeLabel(timeframe),
+		bars:      make(map[string]*rollupBar),
+		finalized: make(map[string]time.Time),
+	}
+}
+
+// Add adds a finalized 1m bar and returns the timeframe bars it completed
+func (r *timeframeRollup) Add(bar *models.Bar1m) []*models.Bar1m {
+	bucketStart := bar.Timestamp.Truncate(r.timeframe)
+	var completed []*models.Bar1m
+
+	current, exists := r.bars[bar.Symbol]
+	last, hasFinalized := r.finalized[bar.Symbol]
+	if (hasFinalized && !bucketStart.After(last)) || (exists && bucketStart.Before(current.bar.Timestamp)) {
+		// The bucket this bar belongs to was already finalized (or passed)
+		logger.Debug("Dropping late 1m bar from timeframe rollup",
+			logger.String("symbol", bar.Symbol),
+			logger.String("timeframe", r.label),
+			logger.Time("timestamp", bar.Timestamp),
+		)
+		return nil
+	}
+	if exists && !current.restoredTo.IsZero() && !bar.Timestamp.After(current.restoredTo) &&
+		current.bar.Timestamp.Equal(bucketStart) {
+		// Minutes up to the restart were merged before it; the bar is the one finalized at shutdown
+		logger.Debug("Dropping 1m bar already merged before restart",
+			logger.String("symbol", bar.Symbol),
+			logger.String("timeframe", r.label),
+			logger.Time("timestamp", bar.Timestamp),
+		)
+		return nil
+	}
+	if exists && !current.bar.Timestamp.Equal(bucketStart) {
+		// A later bucket started, so the current one is missing its remaining minutes
+		completed = append(completed, current.bar)
+		r.finalized[bar.Symbol] = current.bar.Timestamp
+		exists = false
+	}
+
+	if !exists {
+		current = &rollupBar{
+			bar: &models.Bar1m{
+				Symbol:    bar.Symbol,
+				Timestamp: bucketStart,
+				Open:      bar.Open,
+				High:      bar.High,
+				Low:       bar.Low,
+				Close:     bar.Close,
+				Timeframe: r.label,
+			},
+			firstMinute: bar.Timestamp,
+			lastMinute:  bar.Timestamp,
+		}
+		r.bars[bar.Symbol] = current
+	}
+	current.merge(bar)
+
+	// The bucket's last minute completes it without waiting for the next bucket
+	if !bar.Timestamp.Add(time.Minute).Before(bucketStart.Add(r.timeframe)) {
+		completed = append(completed, current.bar)
+		delete(r.bars, bar.Symbol)
+		r.finalized[bar.Symbol] = bucketStart
+	}
+
+	return completed
+}
+
+// Flush removes and returns the symbol's partial bar, if any
+func (r *timeframeRollup) Flush(symbol string) *models.Bar1m {
+	delete(r.finalized, symbol)
+	current, exists := r.bars[symbol]
+	if !exists {
+		return nil
+	}
+	delete(r.bars, symbol)
+	return current.bar
+}
+
+// TakeOpen removes and returns all bars whose bucket has not completed
+func (r *timeframeRollup) TakeOpen() []OpenTimeframeBar {
+	open := make([]OpenTimeframeBar, 0, len(r.bars))
+	for _, current := range r.bars {
+		open = append(open, OpenTimeframeBar{
+			Timeframe:   r.timeframe,
+			Bar:         current.bar,
+			FirstMinute: current.firstMinute,
+			LastMinute:  current.lastMinute,
+			VWAPNum:     current.vwapNum,
+			VWAPDenom:   current.vwapDenom,
+		})
+	}
+	r.bars = make(map[string]*rollupBar)
+	r.finalized = make(map[string]time.Time)
+	return open
+}
+
+// Restore resumes an open bar saved at shutdown, unless the symbol already has a bar
+func (r *timeframeRollup) Restore(open OpenTimeframeBar) {
+	if open.Bar == nil {
+		return
+	}
+	if _, exists := r.bars[open.Bar.Symbol]; exists {
+		return
+	}
+	r.bars[open.Bar.Symbol] = &rollupBar{
+		bar:         open.Bar,
+		firstMinute: open.FirstMinute,
+		lastMinute:  open.LastMinute,
+		vwapNum:     open.VWAPNum,
+		vwapDenom:   open.VWAPDenom,
+		restoredTo:  open.LastMinute,
+	}
+}
+
+// emit calls the timeframe's callback with a finalized bar
+func (r *timeframeRollup) emit(bar *models.Bar1m) {
+	logger.Debug("Timeframe bar finalized",
+		logger.String("symbol", bar.Symbol),
+		logger.String("timeframe", r.label),
+		logger.Time("timestamp", bar.Timestamp),
+	)
+	if r.onBarFinal != nil {
+		// Call callback outside of lock to avoid deadlock
+		go r.onBarFinal(bar)
+	}
+}
+
+// merge adds a 1m bar's OHLCV to the timeframe bar. Constituents may arrive out of order, so
+// open and close follow the earliest and latest minutes. VWAP is the volume-weighted average
+// of the constituents' VWAPs.
+func (rb *rollupBar) merge(bar *models.Bar1m) {
+	if bar.Timestamp.Before(rb.firstMinute) {
+		rb.firstMinute = bar.Timestamp
+		rb.bar.Open = bar.Open
+	}
+	if !bar.Timestamp.Before(rb.lastMinute) {
+		rb.lastMinute = bar.Timestamp
+		rb.bar.Close = bar.Close
+	}
+	if bar.High > rb.bar.High {
+		rb.bar.High = bar.High
+	}
+	if bar.Low < rb.bar.Low {
+		rb.bar.Low = bar.Low
+	}
+	rb.bar.Volume += bar.Volume
+
+	if bar.VWAP > 0 && bar.Volume > 0 {
+		rb.vwapNum += bar.VWAP * float64(bar.Volume)
+		rb.vwapDenom += float64(bar.Volume)
+		rb.bar.VWAP = rb.vwapNum / rb.vwapDenom
+	}
+}
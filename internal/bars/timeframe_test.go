@@ -0,0 +1,240 @@
+package bars
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func minuteBar(start time.Time, minute int, open, high, low, close float64, volume int64, vwap float64) *models.Bar1m {
+	return &models.Bar1m{
+		Symbol:    "AAPL",
+		Timestamp: start.Add(time.Duration(minute) * time.Minute),
+		Open:      open,
+		High:      high,
+		Low:       low,
+		Close:     close,
+		Volume:    volume,
+		VWAP:      vwap,
+	}
+}
+
+func TestTimeframeRollup_ComposesFiveMinuteBar(t *testing.T) {
+	rollup := newTimeframeRollup(5 * time.Minute)
+	start := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+
+	constituents := []*models.Bar1m{
+		minuteBar(start, 0, 150.0, 151.0, 149.5, 150.5, 100, 150.2),
+		minuteBar(start, 1, 150.5, 152.0, 150.0, 151.5, 200, 151.0),
+		minuteBar(start, 2, 151.5, 151.8, 148.0, 149.0, 300, 149.5),
+		minuteBar(start, 3, 149.0, 150.0, 148.5, 149.8, 100, 149.2),
+		minuteBar(start, 4, 149.8, 150.5, 149.0, 150.2, 300, 150.0),
+	}
+
+	for _, bar := range constituents[:4] {
+		assert.Empty(t, rollup.Add(bar))
+	}
+	completed := rollup.Add(constituents[4])
+	require.Len(t, completed, 1, "The bucket's last minute completes the bar")
+
+	bar := completed[0]
+	assert.Equal(t, "AAPL", bar.Symbol)
+	assert.Equal(t, "5m", bar.Timeframe)
+	assert.Equal(t, start, bar.Timestamp)
+	assert.Equal(t, 150.0, bar.Open)
+	assert.Equal(t, 152.0, bar.High)
+	assert.Equal(t, 148.0, bar.Low)
+	assert.Equal(t, 150.2, bar.Close)
+	assert.Equal(t, int64(1000), bar.Volume)
+	expectedVWAP := (150.2*100 + 151.0*200 + 149.5*300 + 149.2*100 + 150.0*300) / 1000
+	assert.InDelta(t, expectedVWAP, bar.VWAP, 1e-9)
+	assert.Empty(t, rollup.bars)
+}
+
+func TestTimeframeRollup_Alignment(t *testing.T) {
+	rollup := newTimeframeRollup(15 * time.Minute)
+	start := time.Date(2024, 1, 2, 14, 37, 0, 0, time.UTC)
+
+	assert.Empty(t, rollup.Add(minuteBar(start, 0, 150.0, 150.0, 150.0, 150.0, 100, 150.0)))
+	completed := rollup.Add(minuteBar(start, 7, 151.0, 151.0, 151.0, 151.0, 100, 151.0)) // 14:44
+
+	require.Len(t, completed, 1)
+	assert.Equal(t, time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC), completed[0].Timestamp)
+	assert.Equal(t, "15m", completed[0].Timeframe)
+}
+
+func TestTimeframeRollup_MissingMinutes(t *testing.T) {
+	rollup := newTimeframeRollup(5 * time.Minute)
+	start := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+
+	// Minutes 2 and 4 have no trades
+	assert.Empty(t, rollup.Add(minuteBar(start, 0, 150.0, 151.0, 149.0, 150.5, 100, 150.0)))
+	assert.Empty(t, rollup.Add(minuteBar(start, 1, 150.5, 153.0, 150.0, 152.0, 100, 152.0)))
+	assert.Empty(t, rollup.Add(minuteBar(start, 3, 152.0, 152.5, 151.0, 151.5, 100, 151.5)))
+
+	// The next bucket's first bar finalizes the short bucket
+	completed := rollup.Add(minuteBar(start, 5, 151.5, 152.0, 151.0, 151.8, 100, 151.6))
+	require.Len(t, completed, 1)
+	assert.Equal(t, start, completed[0].Timestamp)
+	assert.Equal(t, 150.0, completed[0].Open)
+	assert.Equal(t, 153.0, completed[0].High)
+	assert.Equal(t, 149.0, completed[0].Low)
+	assert.Equal(t, 151.5, completed[0].Close)
+	assert.Equal(t, int64(300), completed[0].Volume)
+
+	// A late bar for the finalized bucket is dropped rather than reopening it
+	assert.Empty(t, rollup.Add(minuteBar(start, 4, 151.5, 151.5, 151.5, 151.5, 100, 151.5)))
+
+	partial := rollup.Flush("AAPL")
+	require.NotNil(t, partial)
+	assert.Equal(t, start.Add(5*time.Minute), partial.Timestamp)
+	assert.Equal(t, int64(100), partial.Volume)
+	assert.Nil(t, rollup.Flush("AAPL"))
+}
+
+func TestAggregator_TimeframeBars(t *testing.T) {
+	agg := NewAggregatorWithConfig(AggregatorConfig{
+		Timeframes: []time.Duration{5 * time.Minute, 15 * time.Minute, 30 * time.Second},
+	})
+	assert.Equal(t, []time.Duration{5 * time.Minute, 15 * time.Minute}, agg.Timeframes())
+	assert.Error(t, agg.SetOnTimeframeBarFinal(time.Hour, func(*models.Bar1m) {}))
+
+	var mu sync.Mutex
+	var fiveMinute, fifteenMinute []*models.Bar1m
+	require.NoError(t, agg.SetOnTimeframeBarFinal(5*time.Minute, func(bar *models.Bar1m) {
+		mu.Lock()
+		defer mu.Unlock()
+		fiveMinute = append(fiveMinute, bar)
+	}))
+	require.NoError(t, agg.SetOnTimeframeBarFinal(15*time.Minute, func(bar *models.Bar1m) {
+		mu.Lock()
+		defer mu.Unlock()
+		fifteenMinute = append(fifteenMinute, bar)
+	}))
+
+	// One trade per minute from 14:30 to 14:36; 14:35 finalizes the 14:34 1m bar and the 14:30 5m bar
+	start := time.Date(2024, 1, 2, 14, 30, 10, 0, time.UTC)
+	for i := 0; i < 7; i++ {
+		require.NoError(t, agg.ProcessTick(&models.Tick{
+			Symbol:    "AAPL",
+			Price:     150.0 + float64(i),
+			Size:      100,
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Type:      "trade",
+		}))
+	}
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(fiveMinute) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	// Shutdown finalizes the 14:36 1m bar but leaves the 14:35 5m and 14:30 15m buckets open
+	agg.FinalizeAllBars()
+	open := agg.TakeOpenTimeframeBars()
+	require.Len(t, open, 2)
+	assert.Empty(t, agg.TakeOpenTimeframeBars())
+
+	mu.Lock()
+	require.Len(t, fiveMinute, 1)
+	assert.Empty(t, fifteenMinute)
+	assert.Equal(t, time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC), fiveMinute[0].Timestamp)
+	assert.Equal(t, 150.0, fiveMinute[0].Open)
+	assert.Equal(t, 154.0, fiveMinute[0].High)
+	assert.Equal(t, 150.0, fiveMinute[0].Low)
+	assert.Equal(t, 154.0, fiveMinute[0].Close)
+	assert.Equal(t, int64(500), fiveMinute[0].Volume)
+	mu.Unlock()
+
+	for _, bar := range open {
+		assert.Equal(t, "AAPL", bar.Bar.Symbol)
+		switch bar.Timeframe {
+		case 5 * time.Minute:
+			assert.Equal(t, int64(200), bar.Bar.Volume)
+		case 15 * time.Minute:
+			assert.Equal(t, int64(700), bar.Bar.Volume)
+		default:
+			t.Errorf("unexpected open timeframe %v", bar.Timeframe)
+		}
+	}
+}
+
+func TestAggregator_RestoreOpenTimeframeBars(t *testing.T) {
+	ctx := context.Background()
+	redis := storage.NewMockRedisClient()
+	key := OpenTimeframeBarsKey("bars-consumer-test")
+	start := time.Date(2024, 1, 2, 14, 35, 0, 0, time.UTC)
+
+	// The first run merges 14:35 and 14:36 into the 5m bucket, then shuts down
+	first := NewAggregatorWithConfig(AggregatorConfig{Timeframes: []time.Duration{5 * time.Minute}})
+	for i := 0; i < 2; i++ {
+		require.NoError(t, first.ProcessTick(&models.Tick{
+			Symbol:    "AAPL",
+			Price:     150.0 + float64(i),
+			Size:      100,
+			Timestamp: start.Add(time.Duration(i)*time.Minute + 10*time.Second),
+			Type:      "trade",
+		}))
+	}
+	first.FinalizeAllBars()
+	require.NoError(t, SaveOpenTimeframeBars(ctx, redis, key, first.TakeOpenTimeframeBars()))
+
+	// The next run resumes the bucket and emits it once, with all its minutes
+	second := NewAggregatorWithConfig(AggregatorConfig{Timeframes: []time.Duration{5 * time.Minute}})
+	bars := make(chan *models.Bar1m, 4)
+	require.NoError(t, second.SetOnTimeframeBarFinal(5*time.Minute, func(bar *models.Bar1m) {
+		bars <- bar
+	}))
+	open, err := LoadOpenTimeframeBars(ctx, redis, key)
+	require.NoError(t, err)
+	require.Len(t, open, 1)
+	second.RestoreOpenTimeframeBars(open)
+
+	open, err = LoadOpenTimeframeBars(ctx, redis, key)
+	require.NoError(t, err)
+	assert.Empty(t, open, "open bars are restored once")
+
+	// The rest of 14:36 was already merged at shutdown
+	second.rollUp(minuteBar(start, 1, 151, 151, 151, 151, 50, 0))
+	for minute := 2; minute < 5; minute++ {
+		price := 150.0 + float64(minute)
+		second.rollUp(minuteBar(start, minute, price, price, price, price, 100, 0))
+	}
+
+	select {
+	case bar := <-bars:
+		assert.Equal(t, start, bar.Timestamp)
+		assert.Equal(t, 150.0, bar.Open)
+		assert.Equal(t, 154.0, bar.High)
+		assert.Equal(t, 154.0, bar.Close)
+		assert.Equal(t, int64(500), bar.Volume)
+	case <-time.After(time.Second):
+		t.Fatal("expected the restored 5m bar to be finalized")
+	}
+	select {
+	case bar := <-bars:
+		t.Fatalf("unexpected second 5m bar at %v", bar.Timestamp)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestParseTimeframes(t *testing.T) {
+	timeframes, err := ParseTimeframes([]string{"5m", " 15m", "5m", "1h", ""})
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{5 * time.Minute, 15 * time.Minute, time.Hour}, timeframes)
+
+	for _, invalid := range []string{"1m", "90s", "soon"} {
+		_, err := ParseTimeframes([]string{invalid})
+		assert.Error(t, err, invalid)
+	}
+
+	assert.Equal(t, "60m", TimeframeLabel(time.Hour))
+	assert.Equal(t, "bars.finalized.5m", TimeframeStream("bars.finalized", 5*time.Minute))
+}
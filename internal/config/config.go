@@ -33,6 +33,9 @@ type Config struct {
 	Alert     AlertConfig
 	WSGateway WSGatewayConfig
 	API       APIConfig
+
+	// Toplists
+	Toplist ToplistConfig
 }
 
 // DatabaseConfig holds TimescaleDB configuration
@@ -46,6 +49,13 @@ type DatabaseConfig struct {
 	MaxConnections  int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+
+	// TimescaleDB hypertable management (applied on startup, create-if-absent)
+	ManageHypertables   bool          // Apply chunk interval and compression policies on startup (default: true)
+	BarsChunkInterval   time.Duration // Chunk interval for bars_1m (default: 24h, 0 = unchanged)
+	BarsCompressAfter   time.Duration // Compress bars_1m chunks older than this (default: 168h, 0 = disabled)
+	AlertsChunkInterval time.Duration // Chunk interval for alert_history (default: 168h, 0 = unchanged)
+	AlertsCompressAfter time.Duration // Compress alert_history chunks older than this (default: 720h, 0 = disabled)
 }
 
 // RedisConfig holds Redis configuration
@@ -56,6 +66,13 @@ type RedisConfig struct {
 	DB           int
 	PoolSize     int
 	MinIdleConns int
+
+	// Reconnection strategy for commands failing with connection errors
+	ReconnectInitialBackoff time.Duration // Delay before the first retry (default: 100ms)
+	ReconnectMaxBackoff     time.Duration // Upper bound for a single retry delay (default: 10s)
+	ReconnectJitter         float64       // Fraction of each delay randomized (default: 0.2)
+	ReconnectMaxRetries     int           // Retries per command (default: 5, 0 = until the deadline)
+	ReconnectDeadline       time.Duration // Total retry time per command (default: 30s, 0 = until max retries)
 }
 
 // MarketDataConfig holds market data provider configuration
@@ -66,6 +83,81 @@ type MarketDataConfig struct {
 	BaseURL      string
 	WebSocketURL string
 	Symbols      []string
+	// Channels are the provider feeds each symbol is subscribed to
+	// (e.g. "T" trades or "A" aggregates for polygon). Empty = provider default.
+	Channels []string
+	// ReferenceSymbols are ingested and kept in scanner state for cross-symbol metrics
+	// (e.g. SPY for relative strength) but never produce alerts
+	ReferenceSymbols []string
+	// SymbolProviders routes symbols to a different provider than Provider
+	// (e.g. crypto symbols to a crypto provider). Empty = single provider.
+	SymbolProviders map[string]string
+	// TimestampSources selects the tick timestamp per provider: "exchange" (default) or "received"
+	TimestampSources map[string]string
+	// ProviderAPIKeys, ProviderAPISecrets, ProviderBaseURLs and ProviderWebSocketURLs set the
+	// credentials and endpoints of each provider symbols are routed to (provider:value pairs)
+	ProviderAPIKeys       map[string]string
+	ProviderAPISecrets    map[string]string
+	ProviderBaseURLs      map[string]string
+	ProviderWebSocketURLs map[string]string
+}
+
+// ProviderSettings holds the credentials and endpoints of one provider
+type ProviderSettings struct {
+	APIKey       string
+	APISecret    string
+	BaseURL      string
+	WebSocketURL string
+}
+
+// ProviderSettingsFor returns the credentials and endpoints of a provider. Values listed for
+// the provider win; otherwise Provider gets APIKey, APISecret, BaseURL and WebSocketURL while
+// other providers get nothing, so they never connect with another provider's credentials or
+// URLs (empty endpoints = the provider's default).
+func (c MarketDataConfig) ProviderSettingsFor(provider string) ProviderSettings {
+	var settings ProviderSettings
+	if provider == c.Provider {
+		settings = ProviderSettings{
+			APIKey:       c.APIKey,
+			APISecret:    c.APISecret,
+			BaseURL:      c.BaseURL,
+			WebSocketURL: c.WebSocketURL,
+		}
+	}
+	if value, ok := c.ProviderAPIKeys[provider]; ok {
+		settings.APIKey = value
+	}
+	if value, ok := c.ProviderAPISecrets[provider]; ok {
+		settings.APISecret = value
+	}
+	if value, ok := c.ProviderBaseURLs[provider]; ok {
+		settings.BaseURL = value
+	}
+	if value, ok := c.ProviderWebSocketURLs[provider]; ok {
+		settings.WebSocketURL = value
+	}
+	return settings
+}
+
+// TimestampSourceFor returns the configured tick timestamp source for a provider
+func (c MarketDataConfig) TimestampSourceFor(provider string) string {
+	if source, ok := c.TimestampSources[provider]; ok {
+		return source
+	}
+	return "exchange"
+}
+
+// IngestSymbols returns the tradable symbols plus any reference symbols not already listed
+func (c MarketDataConfig) IngestSymbols() []string {
+	symbols := make([]string, 0, len(c.Symbols)+len(c.ReferenceSymbols))
+	seen := make(map[string]bool, len(c.Symbols))
+	for _, symbol := range append(append([]string{}, c.Symbols...), c.ReferenceSymbols...) {
+		if !seen[symbol] {
+			seen[symbol] = true
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
 }
 
 // IngestConfig holds ingest service configuration
@@ -77,6 +169,9 @@ type IngestConfig struct {
 	BatchTimeout      time.Duration
 	ReconnectDelay    time.Duration
 	MaxReconnectDelay time.Duration
+	MaxClockSkew      time.Duration // Max allowed provider/server clock skew (0 = disabled)
+	ClockSkewAction   string        // "flag" or "correct" (default: "flag")
+	DataQualityWindow time.Duration // Window for provider tick rate and latency metrics (0 = data quality monitoring disabled)
 }
 
 // BarsConfig holds bar aggregator configuration
@@ -92,14 +187,38 @@ type BarsConfig struct {
 	DBWriteQueueSize int
 	DBMaxRetries     int
 	DBRetryDelay     time.Duration
+	// Consumer offset persistence
+	ConsumerName          string // Stable consumer name (required to recover pending messages across restarts)
+	PersistConsumerOffsets bool
+	ConsumerDrainTimeout   time.Duration // On shutdown, how long to process ticks already read before leaving them pending (0 = no limit)
+	ConsumerClaimMinIdle   time.Duration // Claim ticks pending this long for any consumer, e.g. a dead replica (0 = never)
+	// Stream replay: reprocess ticks from ReplayFrom once, on the first start (only when ReplayEnabled is set)
+	ReplayEnabled bool
+	ReplayFrom    string // RFC3339 timestamp
+	// Exchange timezone bar timestamps are aligned to (IANA name, e.g. "America/New_York")
+	ExchangeTimezone string
+	// Tick types left out of bar VWAP (they still update OHLC and volume)
+	VWAPExcludedTickTypes []string
+	// Longer timeframes finalized 1m bars are rolled up into (e.g. "5m", "15m"), each published to its own stream
+	Timeframes []string
 }
 
 // IndicatorConfig holds indicator engine configuration
 type IndicatorConfig struct {
-	Port            int
-	HealthCheckPort int
-	ConsumerGroup   string
-	UpdateInterval  time.Duration
+	Port               int
+	HealthCheckPort    int
+	ConsumerGroup      string
+	UpdateInterval     time.Duration
+	ParameterOverrides string // Per-symbol indicator periods "SYMBOL[|SYMBOL]:rsi=7;ema=5,..." (default: none)
+	MACDPeriods        string // MACD "fast,slow,signal" EMA periods (default: "12,26,9")
+	RehydrateBars      int    // Stored bars replayed per symbol on startup to seed indicators (0 = disabled, default: 200)
+	// Historical indicator persistence to TimescaleDB
+	DBPersistEnabled bool
+	DBWriteBatchSize int
+	DBWriteInterval  time.Duration
+	DBWriteQueueSize int
+	DBMaxRetries     int
+	DBRetryDelay     time.Duration
 }
 
 // ScannerConfig holds scanner worker configuration
@@ -110,12 +229,40 @@ type ScannerConfig struct {
 	WorkerCount       int
 	ScanInterval      time.Duration
 	SymbolUniverse    []string
-	CooldownDefault   time.Duration
 	BufferSize        int
 	RuleStoreType     string        // "memory" or "redis" (default: "memory")
 	RuleReloadInterval time.Duration // How often to reload rules from store (default: 30s)
 	EnableToplists    bool          // Enable toplist updates (default: true)
 	ToplistUpdateInterval time.Duration // Interval for toplist updates (default: 1s)
+	LULDTiers         string        // LULD band tiers "min_price:band_pct[:max_band],..." (default: Tier 2 bands)
+	TrackedSymbols    []string      // Symbols exporting per-symbol Prometheus metrics (default: none)
+	MaxDataStaleness  time.Duration // Skip rule evaluation for symbols not updated within this duration (0 = disabled)
+	PartitionKey      string        // Partition symbols across workers by "symbol" (default) or "group"
+	PartitionGroups   string        // Symbol groups for group partitioning: "GROUP:SYM|SYM,..." (e.g. sectors)
+	PartitionStrategy string        // Map partition keys to workers by "modulo" hashing (default) or "consistent" hashing
+	PartitionVirtualNodes int       // Ring points per worker with consistent hashing (default: 150)
+	PartitionWeights  string        // Per-symbol load weights "SYM:WEIGHT,..." balanced across workers (default: none)
+	MaxAlertMetrics   int           // Max metrics attached to an alert's metadata (0 = unlimited, default: 100)
+	AlertCoalesceCycles int         // Suppress re-emitting a (rule, symbol) alert within this many scan cycles (0 = disabled)
+	AlertExplain      bool          // Attach per-condition actual vs threshold values to alerts (default: false)
+	BreadthRules      string        // Breadth alerts for system rules "rule_id:threshold:window,..." (default: none)
+	BreadthTopSymbols int           // Top contributing symbols listed in breadth alerts (0 = none, default: 10)
+	SymbolTiers       string        // Per-tier scan intervals "name:min_daily_volume:interval,..." (default: none)
+	StatsPersistEnabled  bool          // Persist scan loop statistics to TimescaleDB (default: false)
+	StatsPersistInterval time.Duration // Interval between persisted scan statistics samples (default: 1m)
+	ReplayMode        bool          // Drive cooldowns, staleness and alert timestamps from event time instead of the wall clock (default: false)
+	OutOfOrderBarPolicy string      // Late finalized bar handling: "insert" or "reject" (default: "insert")
+	RuleHealthNeverFiringAfter  time.Duration // Flag rules that have not matched for this long (default: 168h)
+	RuleHealthAlwaysFiringRatio float64       // Flag rules matching at least this fraction of evaluations (default: 0.95)
+	RuleHealthMinEvaluations    int           // Evaluations required before flagging always-firing rules (default: 100)
+	RuleHealthCheckInterval     time.Duration // How often the rules health report is refreshed (default: 1m)
+	MuteRefreshInterval         time.Duration // How often symbol mutes are reloaded from Redis (default: 1s)
+	RuleErrorLimit              int           // Disable a rule after this many consecutive evaluation errors (0 = never, default: 0)
+	RuleErrorWindow             time.Duration // Window the consecutive evaluation errors must occur within (default: 5m)
+	DebugDumpEnabled            bool          // Expose GET /debug/dump on the health server (default: false)
+	DebugDumpToken              string        // Bearer token required by /debug/dump (required when enabled)
+	DebugDumpMaxSymbols         int           // Max symbols per dump (0 = unbounded, default: 10000)
+	ControlToken                string        // Bearer token required by the health server's control endpoints (empty = rejected)
 }
 
 // WSGatewayConfig holds WebSocket gateway configuration
@@ -129,6 +276,21 @@ type WSGatewayConfig struct {
 	JWTSecret       string
 	AlertStream     string
 	ConsumerGroup   string
+	MaxSubscriptionsPerConnection int    // Max symbols a single connection can subscribe to (0 = unlimited)
+	SubscriptionLimitPolicy       string // "reject" (default) or "truncate" when a subscribe exceeds the limit
+	AuthFailureLimit              int           // Failed auth attempts allowed per IP per window before rejecting (0 = unlimited)
+	AuthFailureWindow             time.Duration // Window for AuthFailureLimit
+	TrustedProxies                []string      // Proxy IPs/CIDRs whose X-Forwarded-For and X-Real-IP headers are honored (empty = none)
+	AlertReorderWindow            time.Duration // Hold alerts this long to deliver each symbol's alerts in sequence order (0 = stream order)
+	UserPreferencesTTL            time.Duration // How long user preferences are cached when applied to alerts (0 = preferences not applied)
+	ShutdownGracePeriod           time.Duration // How long clients get to disconnect after the shutdown message before connections are closed
+	ShutdownReconnectAfter        time.Duration // Delay clients are told to wait before reconnecting (reconnect_after_ms)
+	DefaultAlertScope             string        // Alert metadata scope of tokens without an alert_scope claim: "restricted" (default) or "full"
+	AlertRedactFields             []string      // Alert metadata fields withheld from restricted connections (default: models.DefaultRedactedAlertFields)
+	MaxMessagesPerSecond          int           // Messages written to each connection per second (0 = unlimited)
+	SendBufferSize                int           // Messages queued per connection; the oldest is dropped when full
+	SymbolUniverse                []string      // Symbols clients can subscribe to (empty = any symbol)
+	UnsubscribedAlerts            string        // Alerts sent before a client subscribes: "all" (default) or "none"
 }
 
 // AlertConfig holds alert service configuration
@@ -146,6 +308,25 @@ type AlertConfig struct {
 	DBWriteQueueSize  int
 	DBMaxRetries      int
 	DBRetryDelay      time.Duration
+	DBAggregateWindow time.Duration     // Collapse persisted alerts per rule and symbol within this window (0 = disabled)
+	DefaultLocale     string            // Locale used for users without one (e.g. "en-US")
+	UserLocales       map[string]string // User ID -> locale
+	ShutdownTimeout   time.Duration     // Max time to drain in-flight alerts on shutdown (default: 30s)
+	SinkBreakerFailureThreshold int           // Consecutive sink failures that open its circuit breaker (0 = disabled, default: 5)
+	SinkBreakerOpenDuration     time.Duration // Time a sink's breaker stays open before probing (default: 30s)
+	AlertmanagerURL              string        // Also post filtered alerts to this Alertmanager (empty = disabled)
+	AlertmanagerTimeout          time.Duration // Alertmanager request timeout (default: 5s)
+	SymbolBudget                 int           // Max alerts delivered per symbol per window across all rules and users (0 = unlimited)
+	SymbolBudgetWindow           time.Duration // Window of the per-symbol alert budget (default: 1m)
+	RestrictedSinks              []string      // Sinks (e.g. "alertmanager") receiving alerts without internal metadata
+	AlertRedactFields            []string      // Alert metadata fields withheld from restricted sinks (default: models.DefaultRedactedAlertFields)
+	WebhookURL                   string            // Webhook receiving every filtered alert (empty = none)
+	WebhookSecret                string            // HMAC-SHA256 secret signing webhook requests (empty = unsigned)
+	WebhookTimeout               time.Duration     // Webhook request timeout (default: 5s)
+	WebhookMaxRetries            int               // Retries of a failed webhook request (default: 3)
+	WebhookRetryDelay            time.Duration     // Delay before the first retry, doubled per retry (default: 500ms)
+	WebhookDeadLetterStream      string            // Stream undeliverable webhook alerts are written to (default: "alerts.webhook.dead_letter")
+	WebhookRefreshInterval       time.Duration     // How often user-registered webhooks are reloaded (default: 30s)
 }
 
 // APIConfig holds REST API configuration
@@ -155,6 +336,30 @@ type APIConfig struct {
 	JWTSecret       string
 	JWTExpiry       time.Duration
 	RateLimitRPS    int
+	LogSampleRate   int // Log 1 in N successful requests; errors are always logged (default: 1)
+	AuthFailureLimit  int           // Failed auth attempts allowed per IP per window before rejecting (0 = unlimited)
+	AuthFailureWindow time.Duration // Window for AuthFailureLimit
+	TrustedProxies    []string      // Proxy IPs/CIDRs whose X-Forwarded-For and X-Real-IP headers are honored (empty = none)
+	AdminUsers        []string      // User IDs allowed on admin endpoints (empty = nobody)
+	MaxRulesPerUser      int // Maximum rules a single user may own (0 = unlimited)
+	MaxConditionsPerRule int // Maximum entry + exit conditions per rule (0 = unlimited)
+	MaxRuleNestingDepth  int // Maximum condition nesting depth per rule (0 = unlimited)
+	LoadShedMaxInFlight       int           // In-flight requests at which low-priority routes get 503 (0 = disabled)
+	LoadShedMaxLatency        time.Duration // Average latency above which low-priority routes get 503 (0 = disabled)
+	LoadShedLowPriorityRoutes []string      // Routes shed first under overload ("METHOD /path", "*" wildcards)
+	MaxRequestBodyBytes       int           // Max request body size in bytes; larger bodies get 413 (0 = unlimited, default: 1MB)
+	ScannerURLs               []string      // Scanner worker health server URLs for dry-run rule evaluation (empty = disabled)
+	ScannerTimeout            time.Duration // Timeout for dry-run rule evaluation requests to scanner workers
+	ScannerToken              string        // Bearer token sent to scanner workers (their SCANNER_CONTROL_TOKEN)
+}
+
+// ToplistConfig holds toplist configuration shared by services that update toplists
+type ToplistConfig struct {
+	DefaultMaxSize int           // Max entries kept per toplist ZSET when the toplist doesn't set its own (0 = unbounded)
+	EvictAfter     time.Duration // Evict toplist members not updated within this window (0 = disabled)
+	FlushChunkSize   int // Max toplist updates per Redis pipeline when the scanner flushes (0 = single pipeline)
+	FlushConcurrency int // Max toplist update chunks flushed concurrently (default: 4)
+	NormalizationWindow int // Recent values per symbol that normalized toplists compare against (default: 300)
 }
 
 // Load loads configuration from environment variables
@@ -176,6 +381,11 @@ func Load() (*Config, error) {
 			MaxConnections:  getEnvAsInt("DB_MAX_CONNECTIONS", 25),
 			MaxIdleConns:    getEnvAsInt("DB_MAX_IDLE_CONNS", 5),
 			ConnMaxLifetime: getEnvAsDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute),
+			ManageHypertables:   getEnvAsBool("DB_MANAGE_HYPERTABLES", true),
+			BarsChunkInterval:   getEnvAsDuration("DB_BARS_CHUNK_INTERVAL", 24*time.Hour),
+			BarsCompressAfter:   getEnvAsDuration("DB_BARS_COMPRESS_AFTER", 7*24*time.Hour),
+			AlertsChunkInterval: getEnvAsDuration("DB_ALERTS_CHUNK_INTERVAL", 7*24*time.Hour),
+			AlertsCompressAfter: getEnvAsDuration("DB_ALERTS_COMPRESS_AFTER", 30*24*time.Hour),
 		},
 		Redis: RedisConfig{
 			Host:         getEnv("REDIS_HOST", "localhost"),
@@ -184,6 +394,11 @@ func Load() (*Config, error) {
 			DB:           getEnvAsInt("REDIS_DB", 0),
 			PoolSize:     getEnvAsInt("REDIS_POOL_SIZE", 10),
 			MinIdleConns: getEnvAsInt("REDIS_MIN_IDLE_CONNS", 5),
+			ReconnectInitialBackoff: getEnvAsDuration("REDIS_RECONNECT_INITIAL_BACKOFF", 100*time.Millisecond),
+			ReconnectMaxBackoff:     getEnvAsDuration("REDIS_RECONNECT_MAX_BACKOFF", 10*time.Second),
+			ReconnectJitter:         getEnvAsFloat("REDIS_RECONNECT_JITTER", 0.2),
+			ReconnectMaxRetries:     getEnvAsInt("REDIS_RECONNECT_MAX_RETRIES", 5),
+			ReconnectDeadline:       getEnvAsDuration("REDIS_RECONNECT_DEADLINE", 30*time.Second),
 		},
 		MarketData: MarketDataConfig{
 			Provider:     getEnv("MARKET_DATA_PROVIDER", "alpaca"),
@@ -192,6 +407,14 @@ func Load() (*Config, error) {
 			BaseURL:      getEnv("MARKET_DATA_BASE_URL", ""),
 			WebSocketURL: getEnv("MARKET_DATA_WS_URL", ""),
 			Symbols:      getEnvAsStringSlice("MARKET_DATA_SYMBOLS", []string{}),
+			Channels:     getEnvAsStringSlice("MARKET_DATA_CHANNELS", []string{}),
+			ReferenceSymbols: getEnvAsStringSlice("MARKET_DATA_REFERENCE_SYMBOLS", []string{}),
+			SymbolProviders: getEnvAsStringMap("MARKET_DATA_SYMBOL_PROVIDERS", map[string]string{}),
+			TimestampSources: getEnvAsStringMap("MARKET_DATA_TIMESTAMP_SOURCES", map[string]string{}),
+			ProviderAPIKeys:       getEnvAsStringMap("MARKET_DATA_PROVIDER_API_KEYS", map[string]string{}),
+			ProviderAPISecrets:    getEnvAsStringMap("MARKET_DATA_PROVIDER_API_SECRETS", map[string]string{}),
+			ProviderBaseURLs:      getEnvAsStringMap("MARKET_DATA_PROVIDER_BASE_URLS", map[string]string{}),
+			ProviderWebSocketURLs: getEnvAsStringMap("MARKET_DATA_PROVIDER_WS_URLS", map[string]string{}),
 		},
 		Ingest: IngestConfig{
 			Port:              getEnvAsInt("INGEST_PORT", 8080),
@@ -201,6 +424,9 @@ func Load() (*Config, error) {
 			BatchTimeout:      getEnvAsDuration("INGEST_BATCH_TIMEOUT", 100*time.Millisecond),
 			ReconnectDelay:    getEnvAsDuration("INGEST_RECONNECT_DELAY", 1*time.Second),
 			MaxReconnectDelay: getEnvAsDuration("INGEST_MAX_RECONNECT_DELAY", 30*time.Second),
+			MaxClockSkew:      getEnvAsDuration("INGEST_MAX_CLOCK_SKEW", 5*time.Second),
+			ClockSkewAction:   getEnv("INGEST_CLOCK_SKEW_ACTION", "flag"),
+			DataQualityWindow: getEnvAsDuration("INGEST_DATA_QUALITY_WINDOW", 10*time.Second),
 		},
 		Bars: BarsConfig{
 			Port:            getEnvAsInt("BARS_PORT", 8082),
@@ -214,12 +440,31 @@ func Load() (*Config, error) {
 			DBWriteQueueSize: getEnvAsInt("BARS_DB_WRITE_QUEUE_SIZE", 10000),
 			DBMaxRetries:     getEnvAsInt("BARS_DB_MAX_RETRIES", 3),
 			DBRetryDelay:     getEnvAsDuration("BARS_DB_RETRY_DELAY", 100*time.Millisecond),
+			// Consumer offset persistence
+			ConsumerName:           getEnv("BARS_CONSUMER_NAME", ""),
+			PersistConsumerOffsets: getEnvAsBool("BARS_PERSIST_CONSUMER_OFFSETS", false),
+			ConsumerDrainTimeout:   getEnvAsDuration("BARS_CONSUMER_DRAIN_TIMEOUT", 10*time.Second),
+			ConsumerClaimMinIdle:   getEnvAsDuration("BARS_CONSUMER_CLAIM_MIN_IDLE", time.Minute),
+			ReplayEnabled:          getEnvAsBool("BARS_REPLAY_ENABLED", false),
+			ReplayFrom:             getEnv("BARS_REPLAY_FROM", ""),
+			ExchangeTimezone:       getEnv("BARS_EXCHANGE_TIMEZONE", "America/New_York"),
+			VWAPExcludedTickTypes:  getEnvAsStringSlice("BARS_VWAP_EXCLUDED_TICK_TYPES", []string{"quote"}),
+			Timeframes:             getEnvAsStringSlice("BARS_TIMEFRAMES", nil),
 		},
 		Indicator: IndicatorConfig{
-			Port:            getEnvAsInt("INDICATOR_PORT", 8084),
-			HealthCheckPort: getEnvAsInt("INDICATOR_HEALTH_PORT", 8085),
-			ConsumerGroup:   getEnv("INDICATOR_CONSUMER_GROUP", "indicator-engine"),
-			UpdateInterval:  getEnvAsDuration("INDICATOR_UPDATE_INTERVAL", 1*time.Second),
+			Port:               getEnvAsInt("INDICATOR_PORT", 8084),
+			HealthCheckPort:    getEnvAsInt("INDICATOR_HEALTH_PORT", 8085),
+			ConsumerGroup:      getEnv("INDICATOR_CONSUMER_GROUP", "indicator-engine"),
+			UpdateInterval:     getEnvAsDuration("INDICATOR_UPDATE_INTERVAL", 1*time.Second),
+			ParameterOverrides: getEnv("INDICATOR_PARAMETER_OVERRIDES", ""),
+			MACDPeriods:        getEnv("INDICATOR_MACD_PERIODS", "12,26,9"),
+			RehydrateBars:      getEnvAsInt("INDICATOR_REHYDRATE_BARS", 200),
+			DBPersistEnabled:   getEnvAsBool("INDICATOR_DB_PERSIST_ENABLED", false),
+			DBWriteBatchSize:   getEnvAsInt("INDICATOR_DB_WRITE_BATCH_SIZE", 1000),
+			DBWriteInterval:    getEnvAsDuration("INDICATOR_DB_WRITE_INTERVAL", 1*time.Second),
+			DBWriteQueueSize:   getEnvAsInt("INDICATOR_DB_WRITE_QUEUE_SIZE", 10000),
+			DBMaxRetries:       getEnvAsInt("INDICATOR_DB_MAX_RETRIES", 3),
+			DBRetryDelay:       getEnvAsDuration("INDICATOR_DB_RETRY_DELAY", 100*time.Millisecond),
 		},
 		Scanner: ScannerConfig{
 			Port:              getEnvAsInt("SCANNER_PORT", 8086),
@@ -228,12 +473,40 @@ func Load() (*Config, error) {
 			WorkerCount:       getEnvAsInt("SCANNER_WORKER_COUNT", 1),
 			ScanInterval:      getEnvAsDuration("SCANNER_SCAN_INTERVAL", 1*time.Second),
 			SymbolUniverse:    getEnvAsStringSlice("SCANNER_SYMBOL_UNIVERSE", []string{}),
-			CooldownDefault:   getEnvAsDuration("SCANNER_COOLDOWN_DEFAULT", 10*time.Second),
 			BufferSize:        getEnvAsInt("SCANNER_BUFFER_SIZE", 1000),
 			RuleStoreType:     getEnv("SCANNER_RULE_STORE_TYPE", "memory"), // "memory" or "redis"
 			RuleReloadInterval: getEnvAsDuration("SCANNER_RULE_RELOAD_INTERVAL", 30*time.Second),
 			EnableToplists:    getEnvAsBool("SCANNER_ENABLE_TOPLISTS", true),
 			ToplistUpdateInterval: getEnvAsDuration("SCANNER_TOPLIST_UPDATE_INTERVAL", 1*time.Second),
+			LULDTiers:             getEnv("SCANNER_LULD_TIERS", ""),
+			TrackedSymbols:        getEnvAsStringSlice("SCANNER_TRACKED_SYMBOLS", []string{}),
+			OutOfOrderBarPolicy:   getEnv("SCANNER_OUT_OF_ORDER_BAR_POLICY", "insert"),
+			RuleHealthNeverFiringAfter:  getEnvAsDuration("SCANNER_RULE_HEALTH_NEVER_FIRING_AFTER", 168*time.Hour),
+			RuleHealthAlwaysFiringRatio: getEnvAsFloat("SCANNER_RULE_HEALTH_ALWAYS_FIRING_RATIO", 0.95),
+			RuleHealthMinEvaluations:    getEnvAsInt("SCANNER_RULE_HEALTH_MIN_EVALUATIONS", 100),
+			RuleHealthCheckInterval:     getEnvAsDuration("SCANNER_RULE_HEALTH_CHECK_INTERVAL", 1*time.Minute),
+			MuteRefreshInterval:         getEnvAsDuration("SCANNER_MUTE_REFRESH_INTERVAL", 1*time.Second),
+			RuleErrorLimit:              getEnvAsInt("SCANNER_RULE_ERROR_LIMIT", 0),
+			RuleErrorWindow:             getEnvAsDuration("SCANNER_RULE_ERROR_WINDOW", 5*time.Minute),
+			DebugDumpEnabled:            getEnvAsBool("SCANNER_DEBUG_DUMP_ENABLED", false),
+			DebugDumpToken:              getEnv("SCANNER_DEBUG_DUMP_TOKEN", ""),
+			DebugDumpMaxSymbols:         getEnvAsInt("SCANNER_DEBUG_DUMP_MAX_SYMBOLS", 10000),
+			ControlToken:                getEnv("SCANNER_CONTROL_TOKEN", ""),
+			MaxDataStaleness:            getEnvAsDuration("SCANNER_MAX_DATA_STALENESS", 0),
+			PartitionKey:                getEnv("SCANNER_PARTITION_KEY", "symbol"),
+			PartitionGroups:             getEnv("SCANNER_PARTITION_GROUPS", ""),
+			PartitionStrategy:           getEnv("SCANNER_PARTITION_STRATEGY", "modulo"),
+			PartitionVirtualNodes:       getEnvAsInt("SCANNER_PARTITION_VIRTUAL_NODES", 150),
+			PartitionWeights:            getEnv("SCANNER_PARTITION_WEIGHTS", ""),
+			MaxAlertMetrics:             getEnvAsInt("SCANNER_MAX_ALERT_METRICS", 100),
+			ReplayMode:                  getEnvAsBool("SCANNER_REPLAY_MODE", false),
+			AlertCoalesceCycles:         getEnvAsInt("SCANNER_ALERT_COALESCE_CYCLES", 0),
+			AlertExplain:                getEnvAsBool("SCANNER_ALERT_EXPLAIN", false),
+			BreadthRules:                getEnv("SCANNER_BREADTH_RULES", ""),
+			BreadthTopSymbols:           getEnvAsInt("SCANNER_BREADTH_TOP_SYMBOLS", 10),
+			SymbolTiers:                 getEnv("SCANNER_SYMBOL_TIERS", ""),
+			StatsPersistEnabled:         getEnvAsBool("SCANNER_STATS_PERSIST_ENABLED", false),
+			StatsPersistInterval:        getEnvAsDuration("SCANNER_STATS_PERSIST_INTERVAL", 1*time.Minute),
 		},
 		Alert: AlertConfig{
 			Port:              getEnvAsInt("ALERT_PORT", 8092),
@@ -249,6 +522,25 @@ func Load() (*Config, error) {
 			DBWriteQueueSize:   getEnvAsInt("ALERT_DB_WRITE_QUEUE_SIZE", 1000),
 			DBMaxRetries:       getEnvAsInt("ALERT_DB_MAX_RETRIES", 3),
 			DBRetryDelay:       getEnvAsDuration("ALERT_DB_RETRY_DELAY", 1*time.Second),
+			DBAggregateWindow:  getEnvAsDuration("ALERT_DB_AGGREGATE_WINDOW", 0),
+			DefaultLocale:      getEnv("ALERT_DEFAULT_LOCALE", "en-US"),
+			UserLocales:        getEnvAsStringMap("ALERT_USER_LOCALES", map[string]string{}),
+			ShutdownTimeout:    getEnvAsDuration("ALERT_SHUTDOWN_TIMEOUT", 30*time.Second),
+			SinkBreakerFailureThreshold: getEnvAsInt("ALERT_SINK_BREAKER_FAILURE_THRESHOLD", 5),
+			SinkBreakerOpenDuration:     getEnvAsDuration("ALERT_SINK_BREAKER_OPEN_DURATION", 30*time.Second),
+			AlertmanagerURL:              getEnv("ALERT_ALERTMANAGER_URL", ""),
+			AlertmanagerTimeout:          getEnvAsDuration("ALERT_ALERTMANAGER_TIMEOUT", 5*time.Second),
+			SymbolBudget:                 getEnvAsInt("ALERT_SYMBOL_BUDGET", 0),
+			SymbolBudgetWindow:           getEnvAsDuration("ALERT_SYMBOL_BUDGET_WINDOW", 1*time.Minute),
+			RestrictedSinks:              getEnvAsStringSlice("ALERT_RESTRICTED_SINKS", []string{}),
+			AlertRedactFields:            getEnvAsStringSlice("ALERT_REDACT_FIELDS", []string{}),
+			WebhookURL:                   getEnv("ALERT_WEBHOOK_URL", ""),
+			WebhookSecret:                getEnv("ALERT_WEBHOOK_SECRET", ""),
+			WebhookTimeout:               getEnvAsDuration("ALERT_WEBHOOK_TIMEOUT", 5*time.Second),
+			WebhookMaxRetries:            getEnvAsInt("ALERT_WEBHOOK_MAX_RETRIES", 3),
+			WebhookRetryDelay:            getEnvAsDuration("ALERT_WEBHOOK_RETRY_DELAY", 500*time.Millisecond),
+			WebhookDeadLetterStream:      getEnv("ALERT_WEBHOOK_DEAD_LETTER_STREAM", "alerts.webhook.dead_letter"),
+			WebhookRefreshInterval:       getEnvAsDuration("ALERT_WEBHOOK_REFRESH_INTERVAL", 30*time.Second),
 		},
 		WSGateway: WSGatewayConfig{
 			Port:            getEnvAsInt("WS_GATEWAY_PORT", 8088),
@@ -260,6 +552,21 @@ func Load() (*Config, error) {
 			JWTSecret:       getEnv("WS_GATEWAY_JWT_SECRET", ""),
 			AlertStream:     getEnv("WS_GATEWAY_ALERT_STREAM", "alerts.filtered"),
 			ConsumerGroup:   getEnv("WS_GATEWAY_CONSUMER_GROUP", "ws-gateway"),
+			MaxSubscriptionsPerConnection: getEnvAsInt("WS_GATEWAY_MAX_SUBSCRIPTIONS_PER_CONNECTION", 500),
+			SubscriptionLimitPolicy:       getEnv("WS_GATEWAY_SUBSCRIPTION_LIMIT_POLICY", "reject"),
+			AuthFailureLimit:              getEnvAsInt("WS_GATEWAY_AUTH_FAILURE_LIMIT", 10),
+			AuthFailureWindow:             getEnvAsDuration("WS_GATEWAY_AUTH_FAILURE_WINDOW", time.Minute),
+			TrustedProxies:                getEnvAsStringSlice("WS_GATEWAY_TRUSTED_PROXIES", []string{}),
+			AlertReorderWindow:            getEnvAsDuration("WS_GATEWAY_ALERT_REORDER_WINDOW", 0),
+			UserPreferencesTTL:            getEnvAsDuration("WS_GATEWAY_USER_PREFERENCES_TTL", 30*time.Second),
+			ShutdownGracePeriod:           getEnvAsDuration("WS_GATEWAY_SHUTDOWN_GRACE_PERIOD", 5*time.Second),
+			ShutdownReconnectAfter:        getEnvAsDuration("WS_GATEWAY_SHUTDOWN_RECONNECT_AFTER", 2*time.Second),
+			DefaultAlertScope:             getEnv("WS_GATEWAY_DEFAULT_ALERT_SCOPE", "restricted"),
+			AlertRedactFields:             getEnvAsStringSlice("WS_GATEWAY_ALERT_REDACT_FIELDS", []string{}),
+			MaxMessagesPerSecond:          getEnvAsInt("WS_GATEWAY_MAX_MESSAGES_PER_SECOND", 100),
+			SendBufferSize:                getEnvAsInt("WS_GATEWAY_SEND_BUFFER_SIZE", 256),
+			SymbolUniverse:                getEnvAsStringSlice("WS_GATEWAY_SYMBOL_UNIVERSE", getEnvAsStringSlice("SCANNER_SYMBOL_UNIVERSE", []string{})),
+			UnsubscribedAlerts:            getEnv("WS_GATEWAY_UNSUBSCRIBED_ALERTS", "all"),
 		},
 		API: APIConfig{
 			Port:            getEnvAsInt("API_PORT", 8090),
@@ -267,6 +574,28 @@ func Load() (*Config, error) {
 			JWTSecret:       getEnv("API_JWT_SECRET", ""),
 			JWTExpiry:       getEnvAsDuration("API_JWT_EXPIRY", 24*time.Hour),
 			RateLimitRPS:    getEnvAsInt("API_RATE_LIMIT_RPS", 100),
+			LogSampleRate:   getEnvAsInt("API_LOG_SAMPLE_RATE", 1),
+			AuthFailureLimit:  getEnvAsInt("API_AUTH_FAILURE_LIMIT", 10),
+			AuthFailureWindow: getEnvAsDuration("API_AUTH_FAILURE_WINDOW", time.Minute),
+			TrustedProxies:    getEnvAsStringSlice("API_TRUSTED_PROXIES", []string{}),
+			AdminUsers:        getEnvAsStringSlice("API_ADMIN_USERS", []string{}),
+			MaxRulesPerUser:      getEnvAsInt("API_MAX_RULES_PER_USER", 100),
+			MaxConditionsPerRule: getEnvAsInt("API_MAX_CONDITIONS_PER_RULE", 20),
+			MaxRuleNestingDepth:  getEnvAsInt("API_MAX_RULE_NESTING_DEPTH", 5),
+			LoadShedMaxInFlight:       getEnvAsInt("API_LOAD_SHED_MAX_IN_FLIGHT", 0),
+			LoadShedMaxLatency:        getEnvAsDuration("API_LOAD_SHED_MAX_LATENCY", 0),
+			LoadShedLowPriorityRoutes: getEnvAsStringSlice("API_LOAD_SHED_LOW_PRIORITY_ROUTES", []string{}),
+			MaxRequestBodyBytes:       getEnvAsInt("API_MAX_REQUEST_BODY_BYTES", 1<<20),
+			ScannerURLs:               getEnvAsStringSlice("API_SCANNER_URLS", []string{"http://localhost:8087"}),
+			ScannerTimeout:            getEnvAsDuration("API_SCANNER_TIMEOUT", 5*time.Second),
+			ScannerToken:              getEnv("API_SCANNER_TOKEN", ""),
+		},
+		Toplist: ToplistConfig{
+			DefaultMaxSize: getEnvAsInt("TOPLIST_DEFAULT_MAX_SIZE", 500),
+			EvictAfter:     getEnvAsDuration("TOPLIST_EVICT_AFTER", 0),
+			FlushChunkSize:   getEnvAsInt("TOPLIST_FLUSH_CHUNK_SIZE", 1000),
+			FlushConcurrency: getEnvAsInt("TOPLIST_FLUSH_CONCURRENCY", 4),
+			NormalizationWindow: getEnvAsInt("TOPLIST_NORMALIZATION_WINDOW", 300),
 		},
 	}
 
@@ -327,6 +656,18 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	return boolValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	floatValue, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return floatValue
+}
+
 func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	value := os.Getenv(key)
 	if value == "" {
@@ -361,3 +702,24 @@ func getEnvAsStringSlice(key string, defaultValue []string) []string {
 	}
 	return result
 }
+
+// getEnvAsStringMap parses a comma-separated list of key:value pairs
+func getEnvAsStringMap(key string, defaultValue map[string]string) map[string]string {
+	pairs := getEnvAsStringSlice(key, nil)
+	if len(pairs) == 0 {
+		return defaultValue
+	}
+	result := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		k, v, ok := strings.Cut(pair, ":")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !ok || k == "" || v == "" {
+			continue
+		}
+		result[k] = v
+	}
+	if len(result) == 0 {
+		return defaultValue
+	}
+	return result
+}
@@ -0,0 +1,189 @@
+package data
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Metrics for provider clock skew
+	clockSkewSeconds = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ingest_clock_skew_seconds",
+			Help: "Most recent observed skew between provider tick timestamps and server time (positive = provider ahead)",
+		},
+		[]string{"provider"},
+	)
+
+	clockSkewEvents = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ingest_clock_skew_events_total",
+			Help: "Total number of ticks whose timestamp exceeded the configured max skew",
+		},
+		[]string{"provider", "action"},
+	)
+)
+
+// ClockSkewAction determines what happens to a tick whose timestamp is skewed beyond the limit
+type ClockSkewAction string
+
+const (
+	// ClockSkewActionCorrect replaces the skewed tick timestamp with server time
+	ClockSkewActionCorrect ClockSkewAction = "correct"
+	// ClockSkewActionFlag keeps the provider timestamp but flags the symbol
+	ClockSkewActionFlag ClockSkewAction = "flag"
+)
+
+// ClockSkewConfig holds configuration for clock skew detection
+type ClockSkewConfig struct {
+	MaxSkew time.Duration   // Maximum allowed skew (0 = detection disabled)
+	Action  ClockSkewAction // What to do when MaxSkew is exceeded
+}
+
+// DefaultClockSkewConfig returns default clock skew configuration
+func DefaultClockSkewConfig() ClockSkewConfig {
+	return ClockSkewConfig{
+		MaxSkew: 5 * time.Second,
+		Action:  ClockSkewActionFlag,
+	}
+}
+
+// ClockSkewStats holds clock skew statistics
+type ClockSkewStats struct {
+	TicksChecked    int64              `json:"ticks_checked"`
+	TicksSkewed     int64              `json:"ticks_skewed"`
+	TicksCorrected  int64              `json:"ticks_corrected"`
+	LastSkew        time.Duration      `json:"last_skew"`
+	MaxObservedSkew time.Duration      `json:"max_observed_skew"`
+	FlaggedSymbols  []string           `json:"flagged_symbols"`
+	SymbolSkew      map[string]float64 `json:"symbol_skew_seconds"` // Last skew per symbol in seconds
+}
+
+// ClockSkewDetector compares provider tick timestamps against server time
+// and corrects or flags ticks whose skew exceeds the configured maximum
+type ClockSkewDetector struct {
+	config       ClockSkewConfig
+	providerName string
+	now          func() time.Time // Server clock (overridable for testing)
+
+	mu              sync.RWMutex
+	ticksChecked    int64
+	ticksSkewed     int64
+	ticksCorrected  int64
+	lastSkew        time.Duration
+	maxObservedSkew time.Duration
+	symbolSkew      map[string]time.Duration
+	flagged         map[string]bool
+}
+
+// NewClockSkewDetector creates a new clock skew detector
+func NewClockSkewDetector(config ClockSkewConfig, providerName string) *ClockSkewDetector {
+	if config.Action == "" {
+		config.Action = ClockSkewActionFlag
+	}
+
+	return &ClockSkewDetector{
+		config:       config,
+		providerName: providerName,
+		now:          time.Now,
+		symbolSkew:   make(map[string]time.Duration),
+		flagged:      make(map[string]bool),
+	}
+}
+
+// Check measures the skew of a tick against server time and applies the configured action.
+// The tick is modified in place when the action is "correct". Returns the measured skew.
+func (d *ClockSkewDetector) Check(tick *models.Tick) time.Duration {
+	if tick == nil {
+		return 0
+	}
+
+	serverTime := d.now().UTC()
+	skew := tick.Timestamp.Sub(serverTime)
+	exceeded := d.config.MaxSkew > 0 && absDuration(skew) > d.config.MaxSkew
+
+	d.mu.Lock()
+	d.ticksChecked++
+	d.lastSkew = skew
+	d.symbolSkew[tick.Symbol] = skew
+	if absDuration(skew) > absDuration(d.maxObservedSkew) {
+		d.maxObservedSkew = skew
+	}
+
+	if !exceeded {
+		// Skew back within limits - clear any flag for the symbol
+		delete(d.flagged, tick.Symbol)
+		d.mu.Unlock()
+		clockSkewSeconds.WithLabelValues(d.providerName).Set(skew.Seconds())
+		return skew
+	}
+
+	d.ticksSkewed++
+	switch d.config.Action {
+	case ClockSkewActionFlag:
+		if !d.flagged[tick.Symbol] {
+			logger.Warn("Provider clock skew exceeded, flagging symbol",
+				logger.String("provider", d.providerName),
+				logger.String("symbol", tick.Symbol),
+				logger.Duration("skew", skew),
+				logger.Duration("max_skew", d.config.MaxSkew),
+			)
+		}
+		d.flagged[tick.Symbol] = true
+	default:
+		tick.Timestamp = serverTime
+		d.ticksCorrected++
+	}
+	d.mu.Unlock()
+
+	clockSkewSeconds.WithLabelValues(d.providerName).Set(skew.Seconds())
+	clockSkewEvents.WithLabelValues(d.providerName, string(d.config.Action)).Inc()
+
+	return skew
+}
+
+// IsFlagged returns whether a symbol is currently flagged for clock skew
+func (d *ClockSkewDetector) IsFlagged(symbol string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.flagged[symbol]
+}
+
+// GetStats returns current clock skew statistics
+func (d *ClockSkewDetector) GetStats() ClockSkewStats {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	flagged := make([]string, 0, len(d.flagged))
+	for symbol := range d.flagged {
+		flagged = append(flagged, symbol)
+	}
+
+	symbolSkew := make(map[string]float64, len(d.symbolSkew))
+	for symbol, skew := range d.symbolSkew {
+		symbolSkew[symbol] = skew.Seconds()
+	}
+
+	return ClockSkewStats{
+		TicksChecked:    d.ticksChecked,
+		TicksSkewed:     d.ticksSkewed,
+		TicksCorrected:  d.ticksCorrected,
+		LastSkew:        d.lastSkew,
+		MaxObservedSkew: d.maxObservedSkew,
+		FlaggedSymbols:  flagged,
+		SymbolSkew:      symbolSkew,
+	}
+}
+
+// absDuration returns the absolute value of a duration
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
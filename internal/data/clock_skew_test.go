@@ -0,0 +1,102 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSkewDetector(config ClockSkewConfig, serverTime time.Time) *ClockSkewDetector {
+	detector := NewClockSkewDetector(config, "mock")
+	detector.now = func() time.Time { return serverTime }
+	return detector
+}
+
+func TestClockSkewDetector_WithinLimit(t *testing.T) {
+	serverTime := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	detector := newTestSkewDetector(ClockSkewConfig{MaxSkew: 5 * time.Second, Action: ClockSkewActionCorrect}, serverTime)
+
+	tickTime := serverTime.Add(-2 * time.Second)
+	tick := &models.Tick{Symbol: "AAPL", Price: 150.0, Size: 100, Timestamp: tickTime}
+
+	skew := detector.Check(tick)
+	assert.Equal(t, -2*time.Second, skew)
+	assert.Equal(t, tickTime, tick.Timestamp, "timestamp within limit should be untouched")
+	assert.False(t, detector.IsFlagged("AAPL"))
+
+	stats := detector.GetStats()
+	assert.Equal(t, int64(1), stats.TicksChecked)
+	assert.Equal(t, int64(0), stats.TicksSkewed)
+	assert.InDelta(t, -2.0, stats.SymbolSkew["AAPL"], 0.001)
+}
+
+func TestClockSkewDetector_CorrectsSkewedTimestamp(t *testing.T) {
+	serverTime := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	detector := newTestSkewDetector(ClockSkewConfig{MaxSkew: 5 * time.Second, Action: ClockSkewActionCorrect}, serverTime)
+
+	// Provider clock is 2 minutes ahead - would land the tick in the wrong bar
+	tick := &models.Tick{Symbol: "AAPL", Price: 150.0, Size: 100, Timestamp: serverTime.Add(2 * time.Minute)}
+
+	skew := detector.Check(tick)
+	assert.Equal(t, 2*time.Minute, skew)
+	assert.Equal(t, serverTime, tick.Timestamp, "skewed timestamp should be replaced with server time")
+	assert.False(t, detector.IsFlagged("AAPL"), "correct mode should not flag the symbol")
+
+	stats := detector.GetStats()
+	assert.Equal(t, int64(1), stats.TicksSkewed)
+	assert.Equal(t, int64(1), stats.TicksCorrected)
+	assert.Equal(t, 2*time.Minute, stats.MaxObservedSkew)
+}
+
+func TestClockSkewDetector_FlagsSkewedSymbol(t *testing.T) {
+	serverTime := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	detector := newTestSkewDetector(ClockSkewConfig{MaxSkew: 5 * time.Second, Action: ClockSkewActionFlag}, serverTime)
+
+	tickTime := serverTime.Add(-30 * time.Second)
+	tick := &models.Tick{Symbol: "MSFT", Price: 300.0, Size: 50, Timestamp: tickTime}
+
+	detector.Check(tick)
+	assert.Equal(t, tickTime, tick.Timestamp, "flag mode should keep provider timestamp")
+	assert.True(t, detector.IsFlagged("MSFT"))
+	assert.False(t, detector.IsFlagged("AAPL"))
+
+	stats := detector.GetStats()
+	require.Len(t, stats.FlaggedSymbols, 1)
+	assert.Equal(t, "MSFT", stats.FlaggedSymbols[0])
+	assert.Equal(t, int64(0), stats.TicksCorrected)
+
+	// Flag clears once the provider clock is back within limits
+	detector.Check(&models.Tick{Symbol: "MSFT", Price: 300.0, Size: 50, Timestamp: serverTime})
+	assert.False(t, detector.IsFlagged("MSFT"))
+}
+
+func TestClockSkewDetector_Disabled(t *testing.T) {
+	serverTime := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	detector := newTestSkewDetector(ClockSkewConfig{MaxSkew: 0}, serverTime)
+
+	tickTime := serverTime.Add(time.Hour)
+	tick := &models.Tick{Symbol: "AAPL", Price: 150.0, Size: 100, Timestamp: tickTime}
+
+	skew := detector.Check(tick)
+	assert.Equal(t, time.Hour, skew, "skew should still be measured when disabled")
+	assert.Equal(t, tickTime, tick.Timestamp)
+	assert.Equal(t, int64(0), detector.GetStats().TicksSkewed)
+}
+
+func TestClockSkewDetector_DefaultsToFlag(t *testing.T) {
+	assert.Equal(t, ClockSkewActionFlag, DefaultClockSkewConfig().Action)
+
+	serverTime := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	detector := newTestSkewDetector(ClockSkewConfig{MaxSkew: 5 * time.Second}, serverTime)
+
+	// Without an action, skewed timestamps are kept and the symbol is flagged
+	tickTime := serverTime.Add(-10 * time.Second)
+	tick := &models.Tick{Symbol: "AAPL", Price: 150.0, Size: 100, Timestamp: tickTime}
+	detector.Check(tick)
+	assert.Equal(t, tickTime, tick.Timestamp)
+	assert.True(t, detector.IsFlagged("AAPL"))
+	assert.Equal(t, int64(0), detector.GetStats().TicksCorrected)
+}
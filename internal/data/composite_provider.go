@@ -0,0 +1,373 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// CompositeProviderConfig holds configuration for a composite provider
+type CompositeProviderConfig struct {
+	SymbolProviders map[string]string // Symbol -> provider name mapping
+	DefaultProvider string            // Provider used for symbols without a mapping
+	BufferSize      int               // Size of the merged tick channel
+	Reconnect       ReconnectConfig   // Backoff used to resubscribe a provider whose tick channel closed
+}
+
+// CompositeProvider runs several providers concurrently, routing subscriptions
+// per symbol and merging their tick channels into a single channel. When a provider closes
+// its tick channel, it is connected and subscribed again on its own, following the Provider
+// reconnect contract, while the others keep running.
+type CompositeProvider struct {
+	config    CompositeProviderConfig
+	providers map[string]Provider // Keyed by provider name
+
+	mu         sync.RWMutex
+	tickChan   chan *models.Tick
+	forwarding map[string]bool            // Providers whose tick channel is being merged
+	subscribed map[string]map[string]bool // Provider name -> symbols subscribed on it
+	closed     bool
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// NewCompositeProvider creates a new composite provider from named providers
+func NewCompositeProvider(providers map[string]Provider, config CompositeProviderConfig) (*CompositeProvider, error) {
+	if len(providers) == 0 {
+		return nil, errors.New("composite provider requires at least one provider")
+	}
+	if config.DefaultProvider != "" {
+		if _, exists := providers[config.DefaultProvider]; !exists {
+			return nil, fmt.Errorf("unknown default provider: %s", config.DefaultProvider)
+		}
+	}
+	for symbol, name := range config.SymbolProviders {
+		if _, exists := providers[name]; !exists {
+			return nil, fmt.Errorf("unknown provider %s for symbol %s", name, symbol)
+		}
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 1000
+	}
+	defaults := DefaultReconnectConfig()
+	if config.Reconnect.InitialDelay <= 0 {
+		config.Reconnect.InitialDelay = defaults.InitialDelay
+	}
+	if config.Reconnect.MaxDelay < config.Reconnect.InitialDelay {
+		config.Reconnect.MaxDelay = config.Reconnect.InitialDelay
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &CompositeProvider{
+		config:     config,
+		providers:  providers,
+		tickChan:   make(chan *models.Tick, config.BufferSize),
+		forwarding: make(map[string]bool),
+		subscribed: make(map[string]map[string]bool),
+		ctx:        ctx,
+		cancel:     cancel,
+	}, nil
+}
+
+// Connect connects every underlying provider. A provider that fails to connect
+// is logged and skipped; an error is only returned if no provider is connected.
+// Connecting a closed composite provider starts over with a new merged channel.
+func (c *CompositeProvider) Connect(ctx context.Context) error {
+	c.mu.Lock()
+	if c.closed {
+		c.ctx, c.cancel = context.WithCancel(context.Background())
+		c.tickChan = make(chan *models.Tick, c.config.BufferSize)
+		c.forwarding = make(map[string]bool)
+		c.subscribed = make(map[string]map[string]bool)
+		c.closed = false
+	}
+	c.mu.Unlock()
+
+	var errs []error
+	connected := 0
+
+	for _, name := range c.providerNames() {
+		provider := c.providers[name]
+		if provider.IsConnected() {
+			connected++
+			continue
+		}
+		if err := provider.Connect(ctx); err != nil {
+			logger.Warn("Failed to connect provider",
+				logger.String("provider", name),
+				logger.ErrorField(err),
+			)
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		connected++
+	}
+
+	if connected == 0 {
+		return fmt.Errorf("no providers connected: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Subscribe routes each symbol to its provider and returns the merged tick channel
+func (c *CompositeProvider) Subscribe(ctx context.Context, symbols []string) (<-chan *models.Tick, error) {
+	groups, err := c.groupSymbols(symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, group := range groups {
+		provider := c.providers[name]
+		if !provider.IsConnected() {
+			return nil, fmt.Errorf("provider %s: %w", name, ErrProviderNotConnected)
+		}
+
+		ch, err := provider.Subscribe(ctx, group)
+		if err != nil {
+			return nil, fmt.Errorf("provider %s: %w", name, err)
+		}
+
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			return nil, ErrProviderNotConnected
+		}
+		if c.subscribed[name] == nil {
+			c.subscribed[name] = make(map[string]bool, len(group))
+		}
+		for _, symbol := range group {
+			c.subscribed[name][symbol] = true
+		}
+		if !c.forwarding[name] {
+			c.forwarding[name] = true
+			c.wg.Add(1)
+			go c.forward(c.ctx, name, ch, c.tickChan)
+		}
+		c.mu.Unlock()
+
+		logger.Info("Routed symbols to provider",
+			logger.String("provider", name),
+			logger.Int("count", len(group)),
+		)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tickChan, nil
+}
+
+// Unsubscribe unsubscribes symbols from the providers they are routed to
+func (c *CompositeProvider) Unsubscribe(ctx context.Context, symbols []string) error {
+	groups, err := c.groupSymbols(symbols)
+	if err != nil {
+		return err
+	}
+
+	for name, group := range groups {
+		if err := c.providers[name].Unsubscribe(ctx, group); err != nil {
+			return fmt.Errorf("provider %s: %w", name, err)
+		}
+
+		c.mu.Lock()
+		for _, symbol := range group {
+			delete(c.subscribed[name], symbol)
+		}
+		c.mu.Unlock()
+	}
+
+	return nil
+}
+
+// Close closes every underlying provider and the merged tick channel
+func (c *CompositeProvider) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	cancel := c.cancel
+	tickChan := c.tickChan
+	c.mu.Unlock()
+
+	// Stop forwarding first, so the providers' channels closing below aren't taken for drops
+	cancel()
+
+	var errs []error
+	for _, name := range c.providerNames() {
+		if err := c.providers[name].Close(); err != nil {
+			logger.Warn("Failed to close provider",
+				logger.String("provider", name),
+				logger.ErrorField(err),
+			)
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	c.wg.Wait()
+	close(tickChan)
+
+	return errors.Join(errs...)
+}
+
+// IsConnected returns whether at least one underlying provider is connected
+func (c *CompositeProvider) IsConnected() bool {
+	for _, provider := range c.providers {
+		if provider.IsConnected() {
+			return true
+		}
+	}
+	return false
+}
+
+// GetName returns the provider name
+func (c *CompositeProvider) GetName() string {
+	return "composite"
+}
+
+// ProviderFor returns the name of the provider a symbol is routed to
+func (c *CompositeProvider) ProviderFor(symbol string) (string, bool) {
+	if name, exists := c.config.SymbolProviders[symbol]; exists {
+		return name, true
+	}
+	if c.config.DefaultProvider != "" {
+		return c.config.DefaultProvider, true
+	}
+	return "", false
+}
+
+// ProviderStatus returns the connection status of each underlying provider
+func (c *CompositeProvider) ProviderStatus() map[string]bool {
+	status := make(map[string]bool, len(c.providers))
+	for name, provider := range c.providers {
+		status[name] = provider.IsConnected()
+	}
+	return status
+}
+
+// groupSymbols groups symbols by the provider they are routed to
+func (c *CompositeProvider) groupSymbols(symbols []string) (map[string][]string, error) {
+	groups := make(map[string][]string)
+	for _, symbol := range symbols {
+		if symbol == "" {
+			return nil, ErrInvalidSymbol
+		}
+		name, ok := c.ProviderFor(symbol)
+		if !ok {
+			return nil, fmt.Errorf("no provider configured for symbol %s", symbol)
+		}
+		groups[name] = append(groups[name], symbol)
+	}
+	return groups, nil
+}
+
+// forward copies ticks from a provider channel into the merged channel until ctx is done,
+// resubscribing the provider whenever its channel closes
+func (c *CompositeProvider) forward(ctx context.Context, name string, ch <-chan *models.Tick, merged chan<- *models.Tick) {
+	defer c.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case tick, ok := <-ch:
+			if !ok {
+				// Provider closed its channel - other providers keep running
+				logger.Warn("Provider tick channel closed, reconnecting provider",
+					logger.String("provider", name),
+				)
+				ch = c.resubscribe(ctx, name)
+				if ch == nil {
+					c.mu.Lock()
+					delete(c.forwarding, name)
+					c.mu.Unlock()
+					return
+				}
+				continue
+			}
+			select {
+			case merged <- tick:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// resubscribe connects a provider and subscribes its symbols again until it succeeds,
+// doubling the delay between attempts. Returns nil if ctx is done first or the provider has
+// no symbols left to subscribe.
+func (c *CompositeProvider) resubscribe(ctx context.Context, name string) <-chan *models.Tick {
+	provider := c.providers[name]
+	delay := c.config.Reconnect.InitialDelay
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+		}
+
+		symbols := c.subscribedSymbols(name)
+		if len(symbols) == 0 {
+			return nil
+		}
+
+		err := provider.Connect(ctx)
+		if err == nil || errors.Is(err, ErrProviderAlreadyConnected) {
+			var ch <-chan *models.Tick
+			if ch, err = provider.Subscribe(ctx, symbols); err == nil {
+				providerReconnectsTotal.WithLabelValues(name).Inc()
+				logger.Info("Provider reconnected",
+					logger.String("provider", name),
+					logger.Int("attempts", attempt),
+				)
+				return ch
+			}
+		}
+
+		delay *= 2
+		if delay > c.config.Reconnect.MaxDelay {
+			delay = c.config.Reconnect.MaxDelay
+		}
+		logger.Warn("Failed to reconnect provider",
+			logger.ErrorField(err),
+			logger.String("provider", name),
+			logger.Int("attempt", attempt),
+			logger.Duration("next_delay", delay),
+		)
+		timer.Reset(delay)
+	}
+}
+
+// subscribedSymbols returns the symbols subscribed on a provider, sorted
+func (c *CompositeProvider) subscribedSymbols(name string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	symbols := make([]string, 0, len(c.subscribed[name]))
+	for symbol := range c.subscribed[name] {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// providerNames returns provider names in a stable order
+func (c *CompositeProvider) providerNames() []string {
+	names := make([]string, 0, len(c.providers))
+	for name := range c.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
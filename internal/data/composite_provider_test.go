@@ -0,0 +1,300 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubProvider is a controllable Provider for composite provider tests
+type stubProvider struct {
+	name       string
+	connectErr error
+
+	mu           sync.Mutex
+	connected    bool
+	subscribed   []string
+	unsubscribed []string
+	tickChan     chan *models.Tick
+}
+
+func newStubProvider(name string) *stubProvider {
+	return &stubProvider{name: name, tickChan: make(chan *models.Tick, 10)}
+}
+
+func (s *stubProvider) Connect(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.connectErr != nil {
+		return s.connectErr
+	}
+	s.connected = true
+	return nil
+}
+
+func (s *stubProvider) Subscribe(ctx context.Context, symbols []string) (<-chan *models.Tick, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.connected {
+		return nil, ErrProviderNotConnected
+	}
+	s.subscribed = append(s.subscribed, symbols...)
+	return s.tickChan, nil
+}
+
+func (s *stubProvider) Unsubscribe(ctx context.Context, symbols []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unsubscribed = append(s.unsubscribed, symbols...)
+	return nil
+}
+
+func (s *stubProvider) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.connected {
+		s.connected = false
+		close(s.tickChan)
+		s.tickChan = make(chan *models.Tick, 10)
+	}
+	return nil
+}
+
+func (s *stubProvider) IsConnected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connected
+}
+
+func (s *stubProvider) GetName() string {
+	return s.name
+}
+
+func (s *stubProvider) Subscribed() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.subscribed...)
+}
+
+func (s *stubProvider) Unsubscribed() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.unsubscribed...)
+}
+
+func TestCompositeProvider_RoutesSymbols(t *testing.T) {
+	equities := newStubProvider("equities")
+	crypto := newStubProvider("crypto")
+
+	composite, err := NewCompositeProvider(map[string]Provider{
+		"equities": equities,
+		"crypto":   crypto,
+	}, CompositeProviderConfig{
+		SymbolProviders: map[string]string{"BTCUSD": "crypto", "ETHUSD": "crypto"},
+		DefaultProvider: "equities",
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, composite.Connect(ctx))
+
+	_, err = composite.Subscribe(ctx, []string{"AAPL", "BTCUSD", "MSFT", "ETHUSD"})
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"AAPL", "MSFT"}, equities.Subscribed())
+	assert.ElementsMatch(t, []string{"BTCUSD", "ETHUSD"}, crypto.Subscribed())
+
+	require.NoError(t, composite.Close())
+}
+
+func TestCompositeProvider_MergesTicks(t *testing.T) {
+	equities := newStubProvider("equities")
+	crypto := newStubProvider("crypto")
+
+	composite, err := NewCompositeProvider(map[string]Provider{
+		"equities": equities,
+		"crypto":   crypto,
+	}, CompositeProviderConfig{
+		SymbolProviders: map[string]string{"BTCUSD": "crypto"},
+		DefaultProvider: "equities",
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, composite.Connect(ctx))
+
+	tickChan, err := composite.Subscribe(ctx, []string{"AAPL", "BTCUSD"})
+	require.NoError(t, err)
+
+	equities.tickChan <- &models.Tick{Symbol: "AAPL", Price: 150.0, Size: 100, Timestamp: time.Now()}
+	crypto.tickChan <- &models.Tick{Symbol: "BTCUSD", Price: 60000.0, Size: 1, Timestamp: time.Now()}
+
+	received := make(map[string]bool)
+	for len(received) < 2 {
+		select {
+		case tick := <-tickChan:
+			received[tick.Symbol] = true
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for merged ticks, got %v", received)
+		}
+	}
+	assert.True(t, received["AAPL"])
+	assert.True(t, received["BTCUSD"])
+
+	require.NoError(t, composite.Close())
+
+	// Merged channel is closed after Close
+	_, ok := <-tickChan
+	assert.False(t, ok)
+}
+
+func TestCompositeProvider_IndependentConnect(t *testing.T) {
+	equities := newStubProvider("equities")
+	crypto := newStubProvider("crypto")
+	crypto.connectErr = errors.New("connection refused")
+
+	composite, err := NewCompositeProvider(map[string]Provider{
+		"equities": equities,
+		"crypto":   crypto,
+	}, CompositeProviderConfig{
+		SymbolProviders: map[string]string{"BTCUSD": "crypto"},
+		DefaultProvider: "equities",
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	// One failing provider does not prevent the others from connecting
+	require.NoError(t, composite.Connect(ctx))
+	assert.True(t, composite.IsConnected())
+	assert.Equal(t, map[string]bool{"equities": true, "crypto": false}, composite.ProviderStatus())
+
+	_, err = composite.Subscribe(ctx, []string{"BTCUSD"})
+	assert.ErrorIs(t, err, ErrProviderNotConnected)
+
+	// Closing one provider leaves the other connected
+	require.NoError(t, crypto.Close())
+	assert.True(t, equities.IsConnected())
+
+	require.NoError(t, composite.Close())
+	assert.False(t, composite.IsConnected())
+}
+
+func TestCompositeProvider_ConnectAfterClose(t *testing.T) {
+	equities := newStubProvider("equities")
+	composite, err := NewCompositeProvider(map[string]Provider{"equities": equities}, CompositeProviderConfig{
+		DefaultProvider: "equities",
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, composite.Connect(ctx))
+	first, err := composite.Subscribe(ctx, []string{"AAPL"})
+	require.NoError(t, err)
+	require.NoError(t, composite.Close())
+	_, ok := <-first
+	assert.False(t, ok)
+
+	// Reconnecting merges the providers' new channels into a new merged channel
+	require.NoError(t, composite.Connect(ctx))
+	second, err := composite.Subscribe(ctx, []string{"AAPL"})
+	require.NoError(t, err)
+	equities.send(&models.Tick{Symbol: "AAPL", Price: 150.0})
+	select {
+	case tick := <-second:
+		assert.Equal(t, 150.0, tick.Price)
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for tick after reconnect")
+	}
+
+	require.NoError(t, composite.Close())
+}
+
+func TestCompositeProvider_UnroutedSymbol(t *testing.T) {
+	crypto := newStubProvider("crypto")
+
+	composite, err := NewCompositeProvider(map[string]Provider{"crypto": crypto}, CompositeProviderConfig{
+		SymbolProviders: map[string]string{"BTCUSD": "crypto"},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, composite.Connect(ctx))
+
+	_, err = composite.Subscribe(ctx, []string{"AAPL"})
+	assert.Error(t, err)
+
+	// Unknown provider in the mapping is rejected up front
+	_, err = NewCompositeProvider(map[string]Provider{"crypto": crypto}, CompositeProviderConfig{
+		SymbolProviders: map[string]string{"AAPL": "equities"},
+	})
+	assert.Error(t, err)
+
+	require.NoError(t, composite.Close())
+}
+
+func TestCompositeProvider_ReconnectsClosedProvider(t *testing.T) {
+	equities := newStubProvider("equities")
+	crypto := newStubProvider("crypto")
+
+	composite, err := NewCompositeProvider(map[string]Provider{
+		"equities": equities,
+		"crypto":   crypto,
+	}, CompositeProviderConfig{
+		SymbolProviders: map[string]string{"BTCUSD": "crypto"},
+		DefaultProvider: "equities",
+		Reconnect:       ReconnectConfig{InitialDelay: 5 * time.Millisecond, MaxDelay: 10 * time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, composite.Connect(ctx))
+	tickChan, err := composite.Subscribe(ctx, []string{"AAPL", "BTCUSD"})
+	require.NoError(t, err)
+
+	// The crypto connection drops: it is connected and subscribed again on its own
+	require.NoError(t, crypto.Close())
+	require.Eventually(t, func() bool {
+		return crypto.IsConnected() && len(crypto.Subscribed()) == 2
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"BTCUSD", "BTCUSD"}, crypto.Subscribed())
+	assert.Equal(t, []string{"AAPL"}, equities.Subscribed())
+
+	crypto.send(&models.Tick{Symbol: "BTCUSD", Price: 60000.0})
+	select {
+	case tick := <-tickChan:
+		assert.Equal(t, "BTCUSD", tick.Symbol)
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for tick after provider reconnect")
+	}
+
+	require.NoError(t, composite.Close())
+}
+
+func TestCompositeProvider_NoReconnectWithoutSymbols(t *testing.T) {
+	crypto := newStubProvider("crypto")
+	composite, err := NewCompositeProvider(map[string]Provider{"crypto": crypto}, CompositeProviderConfig{
+		DefaultProvider: "crypto",
+		Reconnect:       ReconnectConfig{InitialDelay: 5 * time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, composite.Connect(ctx))
+	_, err = composite.Subscribe(ctx, []string{"BTCUSD"})
+	require.NoError(t, err)
+	require.NoError(t, composite.Unsubscribe(ctx, []string{"BTCUSD"}))
+
+	// Nothing is left to subscribe, so the provider isn't reconnected
+	require.NoError(t, crypto.Close())
+	time.Sleep(30 * time.Millisecond)
+	assert.False(t, crypto.IsConnected())
+
+	require.NoError(t, composite.Close())
+}
@@ -41,6 +41,10 @@ func (m *MockProvider) Connect(ctx context.Context) error {
 		return ErrProviderAlreadyConnected
 	}
 
+	// A closed provider starts a new connection with a new tick channel
+	if m.tickChan == nil {
+		m.tickChan = make(chan *models.Tick, 100)
+	}
 	m.connected = true
 	return nil
 }
@@ -62,16 +66,13 @@ func (m *MockProvider) Subscribe(ctx context.Context, symbols []string) (<-chan
 		m.subscribed[symbol] = true
 	}
 
-	// Start generating mock ticks if not already running
+	// Start generating mock ticks if not already running; the generator picks up
+	// symbols subscribed later
 	if m.cancel == nil {
 		ctx, cancel := context.WithCancel(ctx)
 		m.cancel = cancel
 		m.wg.Add(1)
-		go m.generateTicks(ctx, symbols)
-	} else {
-		// Update subscribed symbols
-		m.wg.Add(1)
-		go m.generateTicks(ctx, symbols)
+		go m.generateTicks(ctx, m.tickChan)
 	}
 
 	return m.tickChan, nil
@@ -93,23 +94,29 @@ func (m *MockProvider) Unsubscribe(ctx context.Context, symbols []string) error
 	return nil
 }
 
-// Close closes the connection
+// Close closes the connection and the tick channel
 func (m *MockProvider) Close() error {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	if !m.connected {
+		m.mu.Unlock()
 		return nil
 	}
+	cancel := m.cancel
+	m.cancel = nil
+	m.connected = false
+	m.mu.Unlock()
 
-	if m.cancel != nil {
-		m.cancel()
-		m.cancel = nil
+	// The generator takes the lock while running, so it is stopped without holding it and
+	// before the channel it sends on is closed
+	if cancel != nil {
+		cancel()
 	}
+	m.wg.Wait()
 
-	m.connected = false
+	m.mu.Lock()
 	close(m.tickChan)
-	m.wg.Wait()
+	m.tickChan = nil
+	m.mu.Unlock()
 
 	return nil
 }
@@ -126,18 +133,15 @@ func (m *MockProvider) GetName() string {
 	return m.name
 }
 
-// generateTicks generates mock tick data for subscribed symbols
-func (m *MockProvider) generateTicks(ctx context.Context, symbols []string) {
+// generateTicks generates mock tick data for subscribed symbols on tickChan
+func (m *MockProvider) generateTicks(ctx context.Context, tickChan chan<- *models.Tick) {
 	defer m.wg.Done()
 
 	ticker := time.NewTicker(100 * time.Millisecond) // Generate ticks every 100ms
 	defer ticker.Stop()
 
-	// Initialize base prices for each symbol
+	// Base prices are initialized when a symbol is first seen
 	basePrices := make(map[string]float64)
-	for _, symbol := range symbols {
-		basePrices[symbol] = 100.0 + rand.Float64()*200.0 // Random price between 100-300
-	}
 
 	for {
 		select {
@@ -154,7 +158,10 @@ func (m *MockProvider) generateTicks(ctx context.Context, symbols []string) {
 			// Generate a tick for each subscribed symbol
 			for _, symbol := range subscribed {
 				// Update price with small random walk
-				basePrice := basePrices[symbol]
+				basePrice, ok := basePrices[symbol]
+				if !ok {
+					basePrice = 100.0 + rand.Float64()*200.0 // Random price between 100-300
+				}
 				change := (rand.Float64() - 0.5) * 2.0 // -1 to +1
 				newPrice := basePrice + change
 				if newPrice < 1.0 {
@@ -178,7 +185,7 @@ func (m *MockProvider) generateTicks(ctx context.Context, symbols []string) {
 
 				// Send tick (non-blocking)
 				select {
-				case m.tickChan <- tick:
+				case tickChan <- tick:
 				case <-ctx.Done():
 					return
 				default:
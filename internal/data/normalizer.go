@@ -28,18 +28,62 @@ type Normalizer interface {
 	GetProviderName() string
 }
 
+// TimestampSource selects which provider timestamp becomes models.Tick.Timestamp
+type TimestampSource string
+
+const (
+	// TimestampSourceExchange uses the exchange timestamp, falling back to the received time when absent
+	TimestampSourceExchange TimestampSource = "exchange"
+	// TimestampSourceReceived uses the time the feed handler received the message
+	TimestampSourceReceived TimestampSource = "received"
+)
+
+// receivedTimestampFields are message fields carrying the feed handler receive time.
+// When absent, the local time of normalization is used.
+var receivedTimestampFields = []string{"received_at", "recv_ts"}
+
+// NormalizerConfig holds configuration for a normalizer
+type NormalizerConfig struct {
+	TimestampSource TimestampSource // Source of Tick.Timestamp (default: exchange)
+}
+
+// DefaultNormalizerConfig returns default configuration
+func DefaultNormalizerConfig() NormalizerConfig {
+	return NormalizerConfig{
+		TimestampSource: TimestampSourceExchange,
+	}
+}
+
 // DefaultNormalizer is a flexible normalizer that can handle multiple formats
 type DefaultNormalizer struct {
-	providerName string
+	providerName    string
+	timestampSource TimestampSource
+	now             func() time.Time // Clock used for received time (for testing)
 }
 
 // NewNormalizer creates a new normalizer for the given provider
 func NewNormalizer(providerName string) Normalizer {
+	return NewNormalizerWithConfig(providerName, DefaultNormalizerConfig())
+}
+
+// NewNormalizerWithConfig creates a new normalizer for the given provider with configuration
+func NewNormalizerWithConfig(providerName string, config NormalizerConfig) Normalizer {
+	if config.TimestampSource != TimestampSourceReceived {
+		config.TimestampSource = TimestampSourceExchange
+	}
+
 	return &DefaultNormalizer{
-		providerName: providerName,
+		providerName:    providerName,
+		timestampSource: config.TimestampSource,
+		now:             time.Now,
 	}
 }
 
+// GetTimestampSource returns the configured timestamp source
+func (n *DefaultNormalizer) GetTimestampSource() TimestampSource {
+	return n.timestampSource
+}
+
 // GetProviderName returns the provider name
 func (n *DefaultNormalizer) GetProviderName() string {
 	return n.providerName
@@ -153,32 +197,8 @@ func (n *DefaultNormalizer) normalizeAlpaca(data map[string]interface{}) (*model
 		}
 	}
 
-	// Timestamp
-	if ts, ok := data["t"].(string); ok {
-		parsedTime, err := time.Parse(time.RFC3339, ts)
-		if err != nil {
-			// Try other formats
-			parsedTime, err = time.Parse(time.RFC3339Nano, ts)
-			if err != nil {
-				parsedTime, err = time.Parse("2006-01-02T15:04:05.999999999Z07:00", ts)
-			}
-		}
-		if err == nil {
-			tick.Timestamp = parsedTime.UTC()
-		} else {
-			// Fallback to current time
-			tick.Timestamp = time.Now().UTC()
-		}
-	} else if ts, ok := data["t"].(float64); ok {
-		// Unix timestamp in nanoseconds
-		tick.Timestamp = time.Unix(0, int64(ts)).UTC()
-	} else if ts, ok := data["t"].(int64); ok {
-		// Unix timestamp in nanoseconds
-		tick.Timestamp = time.Unix(0, ts).UTC()
-	} else {
-		// Fallback to current time
-		tick.Timestamp = time.Now().UTC()
-	}
+	// Timestamp (exchange time in "t")
+	tick.Timestamp = n.selectTimestamp(data, "t")
 
 	// Validate tick
 	if err := tick.Validate(); err != nil {
@@ -199,6 +219,8 @@ func (n *DefaultNormalizer) normalizePolygon(data map[string]interface{}) (*mode
 			tick.Type = "trade"
 		} else if ev == "Q" { // Quote
 			tick.Type = "quote"
+		} else if ev == "A" { // Per-second aggregate
+			return n.normalizePolygonAggregate(data)
 		}
 	}
 
@@ -229,13 +251,50 @@ func (n *DefaultNormalizer) normalizePolygon(data map[string]interface{}) (*mode
 		tick.Size = size
 	}
 
-	// Timestamp (nanoseconds)
-	if ts, ok := data["t"].(float64); ok {
-		tick.Timestamp = time.Unix(0, int64(ts)).UTC()
-	} else if ts, ok := data["t"].(int64); ok {
-		tick.Timestamp = time.Unix(0, ts).UTC()
+	// Timestamp (exchange time in "t", nanoseconds)
+	tick.Timestamp = n.selectTimestamp(data, "t")
+
+	// Sequence number ("q")
+	tick.Sequence = parseSequenceFields(data, "q")
+
+	// Validate tick
+	if err := tick.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+
+	return tick, nil
+}
+
+// normalizePolygonAggregate normalizes a Polygon.io per-second aggregate into a trade tick
+// carrying the aggregate's close price and volume
+// Example: {"ev":"A","sym":"AAPL","v":4110,"o":150.1,"c":150.5,"h":150.6,"l":150.0,"s":1672574399000,"e":1672574400000}
+func (n *DefaultNormalizer) normalizePolygonAggregate(data map[string]interface{}) (*models.Tick, error) {
+	tick := &models.Tick{Type: "trade"}
+
+	// Symbol
+	if symbol, ok := data["sym"].(string); ok {
+		tick.Symbol = strings.ToUpper(symbol)
+	} else {
+		return nil, fmt.Errorf("%w: missing symbol", ErrInvalidMessage)
+	}
+
+	// Close price
+	if price, ok := data["c"].(float64); ok {
+		tick.Price = price
+	} else {
+		return nil, fmt.Errorf("%w: missing close price", ErrInvalidMessage)
+	}
+
+	// Volume
+	if volume, ok := data["v"].(float64); ok {
+		tick.Size = int64(volume)
+	}
+
+	// Timestamp (aggregate window end in "e", milliseconds)
+	if end, ok := data["e"].(float64); ok && n.timestampSource == TimestampSourceExchange {
+		tick.Timestamp = time.UnixMilli(int64(end)).UTC()
 	} else {
-		tick.Timestamp = time.Now().UTC()
+		tick.Timestamp = n.selectTimestamp(data)
 	}
 
 	// Validate tick
@@ -269,16 +328,8 @@ func (n *DefaultNormalizer) normalizeMock(data map[string]interface{}) (*models.
 		tick.Size = size
 	}
 
-	if ts, ok := data["timestamp"].(string); ok {
-		parsedTime, err := time.Parse(time.RFC3339, ts)
-		if err == nil {
-			tick.Timestamp = parsedTime.UTC()
-		} else {
-			tick.Timestamp = time.Now().UTC()
-		}
-	} else {
-		tick.Timestamp = time.Now().UTC()
-	}
+	tick.Timestamp = n.selectTimestamp(data, "timestamp")
+	tick.Sequence = parseSequenceFields(data, "sequence")
 
 	if tickType, ok := data["type"].(string); ok {
 		tick.Type = tickType
@@ -340,26 +391,10 @@ func (n *DefaultNormalizer) normalizeGeneric(data map[string]interface{}) (*mode
 	}
 
 	// Timestamp fields
-	timestampFields := []string{"timestamp", "t", "time", "ts", "datetime"}
-	for _, field := range timestampFields {
-		if ts, ok := data[field].(string); ok {
-			parsedTime, err := time.Parse(time.RFC3339, ts)
-			if err == nil {
-				tick.Timestamp = parsedTime.UTC()
-				break
-			}
-		} else if ts, ok := data[field].(float64); ok {
-			tick.Timestamp = time.Unix(0, int64(ts)).UTC()
-			break
-		} else if ts, ok := data[field].(int64); ok {
-			tick.Timestamp = time.Unix(0, ts).UTC()
-			break
-		}
-	}
+	tick.Timestamp = n.selectTimestamp(data, "timestamp", "t", "time", "ts", "datetime")
 
-	if tick.Timestamp.IsZero() {
-		tick.Timestamp = time.Now().UTC()
-	}
+	// Sequence number fields
+	tick.Sequence = parseSequenceFields(data, "sequence", "seq")
 
 	// Type
 	if tickType, ok := data["type"].(string); ok {
@@ -374,6 +409,61 @@ func (n *DefaultNormalizer) normalizeGeneric(data map[string]interface{}) (*mode
 	return tick, nil
 }
 
+// selectTimestamp returns the tick timestamp from the configured source.
+// The exchange timestamp is read from the first parseable field; when it is absent the
+// received time is used. The received time falls back to the local clock.
+func (n *DefaultNormalizer) selectTimestamp(data map[string]interface{}, exchangeFields ...string) time.Time {
+	if n.timestampSource == TimestampSourceExchange {
+		if ts, ok := parseTimestampFields(data, exchangeFields); ok {
+			return ts
+		}
+	}
+
+	if ts, ok := parseTimestampFields(data, receivedTimestampFields); ok {
+		return ts
+	}
+
+	now := time.Now
+	if n.now != nil {
+		now = n.now
+	}
+	return now().UTC()
+}
+
+// parseTimestampFields parses the first field holding an RFC3339 string or Unix nanoseconds
+func parseTimestampFields(data map[string]interface{}, fields []string) (time.Time, bool) {
+	for _, field := range fields {
+		switch ts := data[field].(type) {
+		case string:
+			if parsedTime, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+				return parsedTime.UTC(), true
+			}
+		case float64:
+			return time.Unix(0, int64(ts)).UTC(), true
+		case int64:
+			return time.Unix(0, ts).UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseSequenceFields parses the first field holding a positive sequence number (0 if none)
+func parseSequenceFields(data map[string]interface{}, fields ...string) int64 {
+	for _, field := range fields {
+		switch seq := data[field].(type) {
+		case float64:
+			if seq > 0 {
+				return int64(seq)
+			}
+		case int64:
+			if seq > 0 {
+				return seq
+			}
+		}
+	}
+	return 0
+}
+
 // NormalizeBatch normalizes multiple messages in batch
 func NormalizeBatch(normalizer Normalizer, messages [][]byte) ([]*models.Tick, []error) {
 	ticks := make([]*models.Tick, 0, len(messages))
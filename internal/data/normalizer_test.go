@@ -87,6 +87,25 @@ func TestNormalizer_PolygonTrade(t *testing.T) {
 	assert.NoError(t, tick.Validate())
 }
 
+func TestNormalizer_PolygonAggregate(t *testing.T) {
+	normalizer := NewNormalizer("polygon")
+
+	// Polygon per-second aggregate (window start "s" and end "e" in milliseconds)
+	message := []byte(`{"ev":"A","sym":"msft","v":4110,"o":375.1,"c":375.5,"h":375.6,"l":375.0,"s":1672574399000,"e":1672574400000}`)
+
+	tick, err := normalizer.Normalize(message)
+	require.NoError(t, err)
+
+	assert.Equal(t, "MSFT", tick.Symbol)
+	assert.Equal(t, 375.5, tick.Price)
+	assert.Equal(t, int64(4110), tick.Size)
+	assert.Equal(t, "trade", tick.Type)
+	assert.Equal(t, time.UnixMilli(1672574400000).UTC(), tick.Timestamp)
+
+	_, err = normalizer.Normalize([]byte(`{"ev":"A","sym":"MSFT","v":4110}`))
+	assert.ErrorIs(t, err, ErrInvalidMessage)
+}
+
 func TestNormalizer_MockFormat(t *testing.T) {
 	normalizer := NewNormalizer("mock")
 
@@ -248,3 +267,98 @@ func TestNormalizer_GetProviderName(t *testing.T) {
 	assert.Equal(t, "polygon", normalizer2.GetProviderName())
 }
 
+
+func newTestNormalizer(provider string, source TimestampSource, now time.Time) *DefaultNormalizer {
+	normalizer := NewNormalizerWithConfig(provider, NormalizerConfig{TimestampSource: source}).(*DefaultNormalizer)
+	normalizer.now = func() time.Time { return now }
+	return normalizer
+}
+
+func TestNormalizer_TimestampSource(t *testing.T) {
+	exchangeTime := time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC)
+	receivedTime := exchangeTime.Add(250 * time.Millisecond)
+	localTime := exchangeTime.Add(2 * time.Second)
+
+	tests := []struct {
+		name     string
+		provider string
+		source   TimestampSource
+		message  map[string]interface{}
+		expected time.Time
+	}{
+		{
+			name:     "exchange source uses exchange timestamp",
+			provider: "alpaca",
+			source:   TimestampSourceExchange,
+			message:  map[string]interface{}{"T": "t", "S": "AAPL", "p": 150.0, "s": 100, "t": exchangeTime.Format(time.RFC3339Nano), "received_at": receivedTime.Format(time.RFC3339Nano)},
+			expected: exchangeTime,
+		},
+		{
+			name:     "received source uses provider receive timestamp",
+			provider: "alpaca",
+			source:   TimestampSourceReceived,
+			message:  map[string]interface{}{"T": "t", "S": "AAPL", "p": 150.0, "s": 100, "t": exchangeTime.Format(time.RFC3339Nano), "received_at": receivedTime.Format(time.RFC3339Nano)},
+			expected: receivedTime,
+		},
+		{
+			name:     "received source uses local receive time without receive timestamp",
+			provider: "polygon",
+			source:   TimestampSourceReceived,
+			message:  map[string]interface{}{"ev": "T", "sym": "AAPL", "p": 150.0, "s": 100, "t": exchangeTime.UnixNano()},
+			expected: localTime,
+		},
+		{
+			name:     "exchange source falls back to receive timestamp",
+			provider: "polygon",
+			source:   TimestampSourceExchange,
+			message:  map[string]interface{}{"ev": "T", "sym": "AAPL", "p": 150.0, "s": 100, "recv_ts": receivedTime.Format(time.RFC3339Nano)},
+			expected: receivedTime,
+		},
+		{
+			name:     "exchange source falls back to local time",
+			provider: "mock",
+			source:   TimestampSourceExchange,
+			message:  map[string]interface{}{"symbol": "AAPL", "price": 150.0, "size": 100, "timestamp": "not-a-time"},
+			expected: localTime,
+		},
+		{
+			name:     "generic format exchange source",
+			provider: "unknown",
+			source:   TimestampSourceExchange,
+			message:  map[string]interface{}{"symbol": "AAPL", "price": 150.0, "ts": exchangeTime.UnixNano()},
+			expected: exchangeTime,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalizer := newTestNormalizer(tt.provider, tt.source, localTime)
+			msgBytes, err := json.Marshal(tt.message)
+			require.NoError(t, err)
+
+			tick, err := normalizer.Normalize(msgBytes)
+			require.NoError(t, err)
+			assert.True(t, tick.Timestamp.Equal(tt.expected), "expected %v, got %v", tt.expected, tick.Timestamp)
+		})
+	}
+}
+
+func TestNormalizer_TimestampSourceDefault(t *testing.T) {
+	normalizer := NewNormalizer("alpaca").(*DefaultNormalizer)
+	assert.Equal(t, TimestampSourceExchange, normalizer.GetTimestampSource())
+
+	invalid := NewNormalizerWithConfig("alpaca", NormalizerConfig{TimestampSource: "bogus"}).(*DefaultNormalizer)
+	assert.Equal(t, TimestampSourceExchange, invalid.GetTimestampSource())
+}
+
+func TestNormalizer_Sequence(t *testing.T) {
+	polygon := NewNormalizer("polygon")
+	tick, err := polygon.Normalize([]byte(`{"ev":"T","sym":"AAPL","p":150.5,"s":100,"t":1672574400000000000,"q":4242}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(4242), tick.Sequence)
+
+	generic := NewNormalizer("other")
+	tick, err = generic.Normalize([]byte(`{"symbol":"AAPL","price":150.5,"timestamp":1672574400000000000}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), tick.Sequence, "sequence is optional")
+}
@@ -0,0 +1,380 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+const (
+	// DefaultPolygonWSURL is Polygon.io's real-time stocks WebSocket feed
+	DefaultPolygonWSURL = "wss://socket.polygon.io/stocks"
+
+	// polygonAuthTimeout bounds how long Connect waits for the first authentication
+	polygonAuthTimeout = 30 * time.Second
+)
+
+var (
+	// ErrPolygonAuthFailed is returned when Polygon rejects the API key
+	ErrPolygonAuthFailed = errors.New("polygon authentication failed")
+
+	// defaultPolygonChannels are subscribed when ProviderConfig.Channels is empty. Trades and
+	// aggregates both carry volume, so subscribing to both double counts it.
+	defaultPolygonChannels = []string{"T"}
+)
+
+// polygonAction is a client control message (auth, subscribe, unsubscribe)
+type polygonAction struct {
+	Action string `json:"action"`
+	Params string `json:"params"`
+}
+
+// polygonEvent holds the fields common to every Polygon feed message
+type polygonEvent struct {
+	Event   string `json:"ev"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// PolygonProvider streams trades ("T") and per-second aggregates ("A") from Polygon.io's
+// WebSocket feed. The connection is re-established with exponential backoff; every new
+// connection is re-authenticated and re-subscribed to the current symbols. Since drops are
+// recovered internally, the tick channel is only closed by Close; Connect after Close starts
+// a new connection with a new channel.
+type PolygonProvider struct {
+	config     ProviderConfig
+	channels   []string
+	normalizer Normalizer
+	client     *WebSocketClient
+	tickChan   chan *models.Tick
+
+	mu            sync.RWMutex
+	connected     bool
+	authenticated bool
+	subscribed    map[string]bool
+	authResult    chan error // First authentication outcome, read by Connect
+
+	sendMu sync.Mutex // Serializes writes to the WebSocket
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPolygonProvider creates a Polygon.io provider
+func NewPolygonProvider(config ProviderConfig) (Provider, error) {
+	if config.APIKey == "" {
+		return nil, errors.New("polygon provider requires an API key")
+	}
+
+	channels := defaultPolygonChannels
+	if len(config.Channels) > 0 {
+		channels = make([]string, 0, len(config.Channels))
+		for _, channel := range config.Channels {
+			channel = strings.ToUpper(strings.TrimSpace(channel))
+			if channel != "T" && channel != "A" {
+				return nil, fmt.Errorf("unsupported polygon channel %q (expected T or A)", channel)
+			}
+			channels = append(channels, channel)
+		}
+	}
+
+	return &PolygonProvider{
+		config:     config,
+		channels:   channels,
+		normalizer: NewNormalizerWithConfig("polygon", NormalizerConfig{TimestampSource: config.TimestampSource}),
+		subscribed: make(map[string]bool),
+	}, nil
+}
+
+// Connect connects to the feed and waits for the API key to be accepted. The connection
+// stays up (reconnecting as needed) until Close is called or ctx is done.
+func (p *PolygonProvider) Connect(ctx context.Context) error {
+	p.mu.Lock()
+	if p.connected {
+		p.mu.Unlock()
+		return ErrProviderAlreadyConnected
+	}
+
+	url := p.config.WSURL
+	if url == "" {
+		url = DefaultPolygonWSURL
+	}
+	wsConfig := DefaultWebSocketConfig(url)
+	if p.config.ReconnectDelay > 0 {
+		wsConfig.ReconnectDelay = time.Duration(p.config.ReconnectDelay) * time.Second
+	}
+	if p.config.MaxReconnectDelay > 0 {
+		wsConfig.MaxReconnectDelay = time.Duration(p.config.MaxReconnectDelay) * time.Second
+	}
+
+	p.client = NewWebSocketClient(wsConfig)
+	p.client.SetOnDisconnect(func(err error) {
+		p.mu.Lock()
+		p.authenticated = false
+		p.mu.Unlock()
+		logger.Warn("Polygon feed disconnected", logger.ErrorField(err))
+	})
+	if err := p.client.Connect(); err != nil {
+		p.mu.Unlock()
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	p.cancel = cancel
+	p.tickChan = make(chan *models.Tick, 1000)
+	p.authResult = make(chan error, 1)
+	authResult := p.authResult
+	p.connected = true
+	p.wg.Add(1)
+	go p.readLoop(runCtx, p.client, p.tickChan)
+	p.mu.Unlock()
+
+	timeout := time.NewTimer(polygonAuthTimeout)
+	defer timeout.Stop()
+
+	select {
+	case err := <-authResult:
+		if err != nil {
+			p.Close()
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		p.Close()
+		return ctx.Err()
+	case <-timeout.C:
+		p.Close()
+		return fmt.Errorf("timed out waiting for polygon authentication after %s", polygonAuthTimeout)
+	}
+}
+
+// Subscribe subscribes to the configured channels for the given symbols
+func (p *PolygonProvider) Subscribe(ctx context.Context, symbols []string) (<-chan *models.Tick, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.connected {
+		return nil, ErrProviderNotConnected
+	}
+
+	// Validate symbols
+	for _, symbol := range symbols {
+		if symbol == "" {
+			return nil, ErrInvalidSymbol
+		}
+	}
+
+	added := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(symbol)
+		if !p.subscribed[symbol] {
+			p.subscribed[symbol] = true
+			added = append(added, symbol)
+		}
+	}
+
+	// Until the (re)connection is authenticated, symbols are subscribed once the API key is accepted
+	if p.authenticated && len(added) > 0 {
+		if err := p.sendAction("subscribe", p.channelParams(added)); err != nil {
+			logger.Warn("Failed to subscribe to polygon feed, retrying on reconnect",
+				logger.ErrorField(err),
+				logger.Int("symbols", len(added)),
+			)
+		}
+	}
+
+	return p.tickChan, nil
+}
+
+// Unsubscribe unsubscribes from market data for the given symbols
+func (p *PolygonProvider) Unsubscribe(ctx context.Context, symbols []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.connected {
+		return ErrProviderNotConnected
+	}
+
+	removed := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(symbol)
+		if p.subscribed[symbol] {
+			delete(p.subscribed, symbol)
+			removed = append(removed, symbol)
+		}
+	}
+
+	// A reconnection only subscribes to the remaining symbols
+	if p.authenticated && len(removed) > 0 {
+		if err := p.sendAction("unsubscribe", p.channelParams(removed)); err != nil {
+			logger.Warn("Failed to unsubscribe from polygon feed",
+				logger.ErrorField(err),
+				logger.Int("symbols", len(removed)),
+			)
+		}
+	}
+
+	return nil
+}
+
+// Close closes the connection and the tick channel
+func (p *PolygonProvider) Close() error {
+	p.mu.Lock()
+	if !p.connected {
+		p.mu.Unlock()
+		return nil
+	}
+	cancel := p.cancel
+	client := p.client
+	tickChan := p.tickChan
+	p.connected = false
+	p.authenticated = false
+	p.mu.Unlock()
+
+	cancel()
+	client.Close()
+	p.wg.Wait()
+	close(tickChan)
+	return nil
+}
+
+// IsConnected returns whether the feed is connected and authenticated
+func (p *PolygonProvider) IsConnected() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.connected && p.authenticated && p.client.IsConnected()
+}
+
+// GetName returns the provider name
+func (p *PolygonProvider) GetName() string {
+	return "polygon"
+}
+
+// ContiguousSequences returns false: Polygon's "q" sequence numbers increase but are not
+// contiguous, so a skipped number is not a lost tick
+func (p *PolygonProvider) ContiguousSequences() bool {
+	return false
+}
+
+// readLoop handles the connection's feed messages, sending ticks on tickChan, until ctx is done
+func (p *PolygonProvider) readLoop(ctx context.Context, client *WebSocketClient, tickChan chan<- *models.Tick) {
+	defer p.wg.Done()
+
+	messages := client.GetMessageChan()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message := <-messages:
+			p.handleMessage(ctx, message, tickChan)
+		}
+	}
+}
+
+// handleMessage handles a feed message, a JSON array of events
+func (p *PolygonProvider) handleMessage(ctx context.Context, message []byte, tickChan chan<- *models.Tick) {
+	var events []json.RawMessage
+	if err := json.Unmarshal(message, &events); err != nil {
+		logger.Warn("Failed to parse polygon message", logger.ErrorField(err))
+		return
+	}
+
+	for _, raw := range events {
+		var event polygonEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			logger.Warn("Failed to parse polygon event", logger.ErrorField(err))
+			continue
+		}
+
+		switch event.Event {
+		case "status":
+			p.handleStatus(event)
+		case "T", "A":
+			tick, err := p.normalizer.Normalize(raw)
+			if err != nil {
+				logger.Debug("Failed to normalize polygon event",
+					logger.ErrorField(err),
+					logger.String("event", event.Event),
+				)
+				continue
+			}
+			select {
+			case tickChan <- tick:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// handleStatus authenticates new connections and subscribes once authenticated
+func (p *PolygonProvider) handleStatus(event polygonEvent) {
+	switch event.Status {
+	case "connected":
+		if err := p.sendAction("auth", p.config.APIKey); err != nil {
+			logger.Error("Failed to send polygon authentication", logger.ErrorField(err))
+		}
+
+	case "auth_success":
+		p.mu.Lock()
+		p.authenticated = true
+		symbols := make([]string, 0, len(p.subscribed))
+		for symbol := range p.subscribed {
+			symbols = append(symbols, symbol)
+		}
+		if len(symbols) > 0 {
+			if err := p.sendAction("subscribe", p.channelParams(symbols)); err != nil {
+				logger.Error("Failed to resubscribe to polygon feed", logger.ErrorField(err))
+			}
+		}
+		p.mu.Unlock()
+		p.reportAuth(nil)
+		logger.Info("Authenticated with polygon feed", logger.Int("symbols", len(symbols)))
+
+	case "auth_failed":
+		err := fmt.Errorf("%w: %s", ErrPolygonAuthFailed, event.Message)
+		p.reportAuth(err)
+		logger.Error("Polygon authentication failed", logger.ErrorField(err))
+	}
+}
+
+// reportAuth hands the authentication outcome to a waiting Connect (later outcomes are dropped)
+func (p *PolygonProvider) reportAuth(err error) {
+	p.mu.RLock()
+	authResult := p.authResult
+	p.mu.RUnlock()
+
+	select {
+	case authResult <- err:
+	default:
+	}
+}
+
+// channelParams returns the subscription params for the symbols, e.g. "T.AAPL,T.MSFT"
+func (p *PolygonProvider) channelParams(symbols []string) string {
+	params := make([]string, 0, len(symbols)*len(p.channels))
+	for _, symbol := range symbols {
+		for _, channel := range p.channels {
+			params = append(params, channel+"."+symbol)
+		}
+	}
+	return strings.Join(params, ",")
+}
+
+// sendAction sends a control message to the feed
+func (p *PolygonProvider) sendAction(action, params string) error {
+	data, err := json.Marshal(polygonAction{Action: action, Params: params})
+	if err != nil {
+		return err
+	}
+
+	p.sendMu.Lock()
+	defer p.sendMu.Unlock()
+	return p.client.SendMessage(data)
+}
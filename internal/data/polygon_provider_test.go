@@ -0,0 +1,279 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePolygonServer emulates Polygon's WebSocket feed: it authenticates with apiKey and
+// answers each subscription with a trade and an aggregate per subscribed channel
+type fakePolygonServer struct {
+	*httptest.Server
+	apiKey string
+
+	mu            sync.Mutex
+	connections   int
+	subscriptions []string
+	dropFirst     bool // Close the first connection right after authenticating
+}
+
+func newFakePolygonServer(t *testing.T, apiKey string) *fakePolygonServer {
+	f := &fakePolygonServer{apiKey: apiKey}
+	upgrader := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Logf("Upgrade error: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		f.mu.Lock()
+		f.connections++
+		drop := f.dropFirst && f.connections == 1
+		f.mu.Unlock()
+
+		conn.WriteMessage(websocket.TextMessage, []byte(`[{"ev":"status","status":"connected","message":"Connected Successfully"}]`))
+
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var action polygonAction
+			if err := json.Unmarshal(message, &action); err != nil {
+				return
+			}
+
+			switch action.Action {
+			case "auth":
+				if action.Params != f.apiKey {
+					conn.WriteMessage(websocket.TextMessage, []byte(`[{"ev":"status","status":"auth_failed","message":"authentication failed"}]`))
+					return
+				}
+				conn.WriteMessage(websocket.TextMessage, []byte(`[{"ev":"status","status":"auth_success","message":"authenticated"}]`))
+				if drop {
+					return
+				}
+			case "subscribe":
+				f.mu.Lock()
+				f.subscriptions = append(f.subscriptions, action.Params)
+				f.mu.Unlock()
+				for _, param := range strings.Split(action.Params, ",") {
+					channel, symbol, _ := strings.Cut(param, ".")
+					var event string
+					if channel == "A" {
+						event = `{"ev":"A","sym":"` + symbol + `","v":4110,"o":150.1,"c":150.5,"h":150.6,"l":150.0,"s":1672574399000,"e":1672574400000}`
+					} else {
+						event = `{"ev":"T","sym":"` + symbol + `","p":150.25,"s":100,"t":1672574400000000000,"q":42}`
+					}
+					conn.WriteMessage(websocket.TextMessage, []byte("["+event+"]"))
+				}
+			}
+		}
+	}))
+	return f
+}
+
+func (f *fakePolygonServer) wsURL() string {
+	return "ws" + strings.TrimPrefix(f.URL, "http")
+}
+
+func (f *fakePolygonServer) getSubscriptions() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.subscriptions...)
+}
+
+func receiveTick(t *testing.T, ticks <-chan *models.Tick) *models.Tick {
+	t.Helper()
+	select {
+	case tick := <-ticks:
+		require.NotNil(t, tick)
+		return tick
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for tick")
+		return nil
+	}
+}
+
+func TestPolygonProvider_TradesAndAggregates(t *testing.T) {
+	server := newFakePolygonServer(t, "test-key")
+	defer server.Close()
+
+	provider, err := NewPolygonProvider(ProviderConfig{
+		APIKey:   "test-key",
+		WSURL:    server.wsURL(),
+		Channels: []string{"T", "A"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "polygon", provider.GetName())
+	assert.False(t, provider.IsConnected())
+
+	ctx := context.Background()
+	require.NoError(t, provider.Connect(ctx))
+	defer provider.Close()
+	assert.True(t, provider.IsConnected())
+	assert.ErrorIs(t, provider.Connect(ctx), ErrProviderAlreadyConnected)
+
+	ticks, err := provider.Subscribe(ctx, []string{"aapl"})
+	require.NoError(t, err)
+
+	trade := receiveTick(t, ticks)
+	assert.Equal(t, "AAPL", trade.Symbol)
+	assert.Equal(t, 150.25, trade.Price)
+	assert.Equal(t, int64(100), trade.Size)
+	assert.Equal(t, int64(42), trade.Sequence)
+
+	aggregate := receiveTick(t, ticks)
+	assert.Equal(t, "AAPL", aggregate.Symbol)
+	assert.Equal(t, 150.5, aggregate.Price)
+	assert.Equal(t, int64(4110), aggregate.Size)
+	assert.Equal(t, time.UnixMilli(1672574400000).UTC(), aggregate.Timestamp)
+
+	assert.Equal(t, []string{"T.AAPL,A.AAPL"}, server.getSubscriptions())
+}
+
+func TestPolygonProvider_AuthFailure(t *testing.T) {
+	server := newFakePolygonServer(t, "test-key")
+	defer server.Close()
+
+	provider, err := NewPolygonProvider(ProviderConfig{
+		APIKey: "wrong-key",
+		WSURL:  server.wsURL(),
+	})
+	require.NoError(t, err)
+
+	err = provider.Connect(context.Background())
+	assert.ErrorIs(t, err, ErrPolygonAuthFailed)
+	assert.False(t, provider.IsConnected())
+}
+
+func TestPolygonProvider_ReconnectResubscribes(t *testing.T) {
+	server := newFakePolygonServer(t, "test-key")
+	server.dropFirst = true
+	defer server.Close()
+
+	provider, err := NewPolygonProvider(ProviderConfig{
+		APIKey:         "test-key",
+		WSURL:          server.wsURL(),
+		ReconnectDelay: 1,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, provider.Connect(ctx))
+	defer provider.Close()
+
+	ticks, err := provider.Subscribe(ctx, []string{"MSFT"})
+	require.NoError(t, err)
+
+	// The first connection drops after authentication; the symbol is subscribed on reconnect
+	tick := receiveTick(t, ticks)
+	assert.Equal(t, "MSFT", tick.Symbol)
+
+	server.mu.Lock()
+	connections := server.connections
+	server.mu.Unlock()
+	assert.GreaterOrEqual(t, connections, 2)
+	assert.Contains(t, server.getSubscriptions(), "T.MSFT")
+}
+
+func TestPolygonProvider_ContextCancellation(t *testing.T) {
+	server := newFakePolygonServer(t, "test-key")
+	defer server.Close()
+
+	provider, err := NewPolygonProvider(ProviderConfig{
+		APIKey: "test-key",
+		WSURL:  server.wsURL(),
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, provider.Connect(ctx))
+
+	ticks, err := provider.Subscribe(ctx, []string{"AAPL"})
+	require.NoError(t, err)
+	receiveTick(t, ticks)
+
+	cancel()
+	require.NoError(t, provider.Close())
+
+	_, open := <-ticks
+	assert.False(t, open)
+}
+
+func TestPolygonProvider_ConnectAfterClose(t *testing.T) {
+	server := newFakePolygonServer(t, "test-key")
+	defer server.Close()
+
+	provider, err := NewPolygonProvider(ProviderConfig{
+		APIKey: "test-key",
+		WSURL:  server.wsURL(),
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, provider.Connect(ctx))
+	first, err := provider.Subscribe(ctx, []string{"AAPL"})
+	require.NoError(t, err)
+	receiveTick(t, first)
+	require.NoError(t, provider.Close())
+
+	// A new connection delivers on a new channel instead of the closed one
+	require.NoError(t, provider.Connect(ctx))
+	defer provider.Close()
+	second, err := provider.Subscribe(ctx, []string{"AAPL"})
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, "AAPL", receiveTick(t, second).Symbol)
+}
+
+func TestPolygonProvider_ReceivedTimestampSource(t *testing.T) {
+	server := newFakePolygonServer(t, "test-key")
+	defer server.Close()
+
+	provider, err := NewPolygonProvider(ProviderConfig{
+		APIKey:          "test-key",
+		WSURL:           server.wsURL(),
+		Channels:        []string{"A"},
+		TimestampSource: TimestampSourceReceived,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, provider.Connect(ctx))
+	defer provider.Close()
+
+	before := time.Now()
+	ticks, err := provider.Subscribe(ctx, []string{"AAPL"})
+	require.NoError(t, err)
+
+	// The aggregate's 2023 end time is replaced by the time it was received
+	aggregate := receiveTick(t, ticks)
+	assert.False(t, aggregate.Timestamp.Before(before.Add(-time.Second)), "expected received time, got %v", aggregate.Timestamp)
+}
+
+func TestPolygonProvider_Config(t *testing.T) {
+	_, err := NewPolygonProvider(ProviderConfig{})
+	assert.Error(t, err)
+
+	_, err = NewPolygonProvider(ProviderConfig{APIKey: "key", Channels: []string{"Q"}})
+	assert.Error(t, err)
+
+	provider, err := NewProviderFactory().CreateProvider("polygon", ProviderConfig{APIKey: "key"})
+	require.NoError(t, err)
+	assert.Equal(t, "polygon", provider.GetName())
+}
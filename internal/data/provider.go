@@ -16,7 +16,13 @@ var (
 	ErrInvalidSymbol = errors.New("invalid symbol")
 )
 
-// Provider defines the interface for market data providers
+// Provider defines the interface for market data providers.
+//
+// Reconnect contract: every Subscribe within one connection returns the same tick channel.
+// A provider either recovers a dropped upstream connection itself, keeping the channel open,
+// or closes the channel. The channel is also closed by Close. Once it is closed, Connect starts
+// a new connection and the next Subscribe returns a new channel, so callers such as
+// ReconnectingFeed can reconnect by calling Connect and Subscribe again.
 type Provider interface {
 	// Connect establishes a connection to the market data provider
 	Connect(ctx context.Context) error
@@ -58,6 +64,13 @@ type ProviderConfig struct {
 	BaseURL   string
 	WSURL     string
 
+	// Feeds to subscribe each symbol to, e.g. "T" (trades) or "A" (aggregates)
+	// for Polygon (empty = provider default)
+	Channels []string
+
+	// Source of normalized tick timestamps (empty = exchange)
+	TimestampSource TimestampSource
+
 	// Connection settings
 	ReconnectDelay    int // in seconds
 	MaxReconnectDelay int // in seconds
@@ -77,9 +90,9 @@ func NewProviderFactory() *DefaultProviderFactory {
 
 	// Register built-in providers
 	factory.RegisterProvider("mock", NewMockProvider)
+	factory.RegisterProvider("polygon", NewPolygonProvider)
 	// TODO: Register other providers as they are implemented
 	// factory.RegisterProvider("alpaca", NewAlpacaProvider)
-	// factory.RegisterProvider("polygon", NewPolygonProvider)
 
 	return factory
 }
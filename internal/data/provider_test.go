@@ -199,5 +199,6 @@ func TestProviderFactory_ListProviders(t *testing.T) {
 
 	providers := factory.ListProviders()
 	assert.Contains(t, providers, "mock")
+	assert.Contains(t, providers, "polygon")
 	assert.GreaterOrEqual(t, len(providers), 1)
 }
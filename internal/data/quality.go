@@ -0,0 +1,307 @@
+package data
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Metrics for provider data quality
+	qualityTicksPerSecond = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ingest_provider_ticks_per_second",
+			Help: "Provider tick rate over the last data quality window",
+		},
+		[]string{"provider"},
+	)
+
+	qualityLatencySeconds = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ingest_provider_latency_seconds",
+			Help: "Average provider-to-server latency (server receive time minus tick timestamp) over the last data quality window",
+		},
+		[]string{"provider"},
+	)
+
+	qualitySequenceGaps = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ingest_provider_sequence_gaps_total",
+			Help: "Total number of gaps in provider sequence numbers",
+		},
+		[]string{"provider"},
+	)
+
+	qualityMissingSequences = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ingest_provider_missing_sequences_total",
+			Help: "Total number of sequence numbers skipped by the provider",
+		},
+		[]string{"provider"},
+	)
+
+	qualityOutOfOrderTicks = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ingest_provider_out_of_order_ticks_total",
+			Help: "Total number of ticks older than the previous tick of the same symbol",
+		},
+		[]string{"provider"},
+	)
+)
+
+// DataQualityConfig holds configuration for provider data quality monitoring
+type DataQualityConfig struct {
+	Window time.Duration // Window the tick rate and average latency are computed over (0 = monitoring disabled)
+}
+
+// DefaultDataQualityConfig returns default data quality configuration
+func DefaultDataQualityConfig() DataQualityConfig {
+	return DataQualityConfig{
+		Window: 10 * time.Second,
+	}
+}
+
+// DataQualityStats holds provider data quality statistics
+type DataQualityStats struct {
+	TicksObserved    int64         `json:"ticks_observed"`
+	TicksPerSecond   float64       `json:"ticks_per_second"` // Over the last complete window
+	AvgLatency       time.Duration `json:"avg_latency"`      // Over the last complete window with ticks
+	SequenceGaps     int64         `json:"sequence_gaps"`
+	MissingSequences int64         `json:"missing_sequences"`
+	OutOfOrderTicks  int64         `json:"out_of_order_ticks"`
+}
+
+// ContiguousSequencer is implemented by providers that report whether their per-symbol
+// sequence numbers increase by exactly one per tick. Only then is a skipped number a lost
+// tick: Polygon's "q", for example, increases but skips numbers, so for it (and providers
+// not implementing this interface) sequence numbers only order ticks.
+type ContiguousSequencer interface {
+	ContiguousSequences() bool
+}
+
+// qualitySource is the provider a tick came from, for labeling and gap detection
+type qualitySource struct {
+	name       string
+	contiguous bool // Sequence gaps are lost ticks
+}
+
+// qualityWindow accumulates a provider's ticks in the current window
+type qualityWindow struct {
+	ticks   int64
+	latency time.Duration // Sum of latencies
+}
+
+// DataQualityMonitor tracks the quality of a provider's tick feed: tick rate, gaps in
+// provider sequence numbers, provider-to-server latency and out-of-order ticks.
+// Ticks are ordered by sequence number when the provider supplies one, else by timestamp.
+// Metrics are labeled by provider; behind a composite provider, by the provider each
+// symbol is routed to.
+type DataQualityMonitor struct {
+	config       DataQualityConfig
+	providerName string
+	now          func() time.Time // Server clock (overridable for testing)
+
+	mu            sync.Mutex
+	sourceFor     func(symbol string) qualitySource // Set by SetProvider (nil = providerName, no gap detection)
+	windowStart   time.Time                         // Start of the current window
+	windows       map[string]*qualityWindow         // Provider name -> current window
+	lastSequence  map[string]int64
+	lastTimestamp map[string]time.Time
+	stats         DataQualityStats
+}
+
+// NewDataQualityMonitor creates a data quality monitor for a provider.
+// Returns nil if monitoring is disabled; a nil monitor ignores ticks.
+func NewDataQualityMonitor(config DataQualityConfig, providerName string) *DataQualityMonitor {
+	if config.Window <= 0 {
+		return nil
+	}
+
+	return &DataQualityMonitor{
+		config:        config,
+		providerName:  providerName,
+		now:           time.Now,
+		windowStart:   time.Now(),
+		windows:       map[string]*qualityWindow{providerName: {}},
+		lastSequence:  make(map[string]int64),
+		lastTimestamp: make(map[string]time.Time),
+	}
+}
+
+// SetProvider sets the provider whose ticks are observed, labeling metrics with each tick's
+// provider (the routed provider of a CompositeProvider) and detecting sequence gaps only
+// for providers with contiguous sequence numbers
+func (m *DataQualityMonitor) SetProvider(provider Provider) {
+	if m == nil || provider == nil {
+		return
+	}
+
+	sources := make(map[string]qualitySource)
+	var sourceFor func(symbol string) qualitySource
+	if composite, ok := provider.(*CompositeProvider); ok {
+		for name, child := range composite.providers {
+			sources[name] = qualitySource{name: name, contiguous: hasContiguousSequences(child)}
+		}
+		fallback := qualitySource{name: m.providerName}
+		sourceFor = func(symbol string) qualitySource {
+			if name, ok := composite.ProviderFor(symbol); ok {
+				return sources[name]
+			}
+			return fallback
+		}
+	} else {
+		source := qualitySource{name: provider.GetName(), contiguous: hasContiguousSequences(provider)}
+		sources[source.name] = source
+		sourceFor = func(string) qualitySource { return source }
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sourceFor = sourceFor
+	// Every provider reports a rate each window, including silent ones
+	m.windows = make(map[string]*qualityWindow, len(sources))
+	for name := range sources {
+		m.windows[name] = &qualityWindow{}
+	}
+}
+
+// hasContiguousSequences returns whether a provider's sequence gaps are lost ticks
+func hasContiguousSequences(provider Provider) bool {
+	sequencer, ok := provider.(ContiguousSequencer)
+	return ok && sequencer.ContiguousSequences()
+}
+
+// Run publishes the tick rate and average latency once per window until ctx is done,
+// so a silent feed reports a zero tick rate
+func (m *DataQualityMonitor) Run(ctx context.Context) {
+	if m == nil {
+		return
+	}
+
+	ticker := time.NewTicker(m.config.Window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Flush()
+		}
+	}
+}
+
+// Observe records a tick as received from the provider. It must be called before the tick
+// timestamp is corrected for clock skew.
+func (m *DataQualityMonitor) Observe(tick *models.Tick) {
+	if m == nil || tick == nil {
+		return
+	}
+
+	serverTime := m.now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	source := qualitySource{name: m.providerName}
+	if m.sourceFor != nil {
+		source = m.sourceFor(tick.Symbol)
+	}
+
+	window, ok := m.windows[source.name]
+	if !ok {
+		window = &qualityWindow{}
+		m.windows[source.name] = window
+	}
+	m.stats.TicksObserved++
+	window.ticks++
+	window.latency += serverTime.Sub(tick.Timestamp)
+
+	if tick.Sequence > 0 {
+		m.observeSequence(tick, source)
+	} else {
+		m.observeTimestamp(tick, source)
+	}
+}
+
+// observeSequence detects regressions in a symbol's provider sequence numbers and, for
+// providers with contiguous sequences, gaps
+func (m *DataQualityMonitor) observeSequence(tick *models.Tick, source qualitySource) {
+	last, seen := m.lastSequence[tick.Symbol]
+	switch {
+	case !seen:
+	case tick.Sequence <= last:
+		m.stats.OutOfOrderTicks++
+		qualityOutOfOrderTicks.WithLabelValues(source.name).Inc()
+		return
+	case tick.Sequence > last+1 && source.contiguous:
+		missing := tick.Sequence - last - 1
+		m.stats.SequenceGaps++
+		m.stats.MissingSequences += missing
+		qualitySequenceGaps.WithLabelValues(source.name).Inc()
+		qualityMissingSequences.WithLabelValues(source.name).Add(float64(missing))
+	}
+	m.lastSequence[tick.Symbol] = tick.Sequence
+}
+
+// observeTimestamp detects ticks older than the symbol's latest tick
+func (m *DataQualityMonitor) observeTimestamp(tick *models.Tick, source qualitySource) {
+	last, seen := m.lastTimestamp[tick.Symbol]
+	if seen && tick.Timestamp.Before(last) {
+		m.stats.OutOfOrderTicks++
+		qualityOutOfOrderTicks.WithLabelValues(source.name).Inc()
+		return
+	}
+	m.lastTimestamp[tick.Symbol] = tick.Timestamp
+}
+
+// Flush ends the current window and publishes each provider's tick rate and average latency
+func (m *DataQualityMonitor) Flush() {
+	if m == nil {
+		return
+	}
+
+	serverTime := m.now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elapsed := serverTime.Sub(m.windowStart)
+	if elapsed <= 0 {
+		return
+	}
+
+	var ticks int64
+	var latency time.Duration
+	for name, window := range m.windows {
+		qualityTicksPerSecond.WithLabelValues(name).Set(float64(window.ticks) / elapsed.Seconds())
+		// Latency keeps its last value through windows without ticks
+		if window.ticks > 0 {
+			qualityLatencySeconds.WithLabelValues(name).Set((window.latency / time.Duration(window.ticks)).Seconds())
+		}
+		ticks += window.ticks
+		latency += window.latency
+		*window = qualityWindow{}
+	}
+
+	m.stats.TicksPerSecond = float64(ticks) / elapsed.Seconds()
+	if ticks > 0 {
+		m.stats.AvgLatency = latency / time.Duration(ticks)
+	}
+	m.windowStart = serverTime
+}
+
+// GetStats returns current data quality statistics across providers
+func (m *DataQualityMonitor) GetStats() DataQualityStats {
+	if m == nil {
+		return DataQualityStats{}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
@@ -0,0 +1,159 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestQualityMonitor returns a monitor whose clock is read from *serverTime
+func newTestQualityMonitor(t *testing.T, provider string, serverTime *time.Time) *DataQualityMonitor {
+	monitor := NewDataQualityMonitor(DataQualityConfig{Window: 10 * time.Second}, provider)
+	require.NotNil(t, monitor)
+	monitor.now = func() time.Time { return *serverTime }
+	monitor.windowStart = *serverTime
+	return monitor
+}
+
+// sequencedProvider is a provider with contiguous (or not) sequence numbers
+type sequencedProvider struct {
+	*MockProvider
+	name       string
+	contiguous bool
+}
+
+func (p *sequencedProvider) GetName() string           { return p.name }
+func (p *sequencedProvider) ContiguousSequences() bool { return p.contiguous }
+
+func newSequencedProvider(t *testing.T, name string, contiguous bool) *sequencedProvider {
+	mock, err := NewMockProvider(ProviderConfig{})
+	require.NoError(t, err)
+	return &sequencedProvider{MockProvider: mock.(*MockProvider), name: name, contiguous: contiguous}
+}
+
+func TestDataQualityMonitor_SequencedTicks(t *testing.T) {
+	serverTime := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	monitor := newTestQualityMonitor(t, "quality-seq", &serverTime)
+	monitor.SetProvider(newSequencedProvider(t, "quality-seq", true))
+
+	for _, seq := range []int64{1, 2, 3, 7, 5, 8} {
+		monitor.Observe(&models.Tick{Symbol: "AAPL", Price: 150, Timestamp: serverTime.Add(-50 * time.Millisecond), Sequence: seq})
+	}
+	// Sequences are tracked per symbol
+	monitor.Observe(&models.Tick{Symbol: "MSFT", Price: 300, Timestamp: serverTime.Add(-50 * time.Millisecond), Sequence: 100})
+
+	stats := monitor.GetStats()
+	assert.Equal(t, int64(7), stats.TicksObserved)
+	assert.Equal(t, int64(1), stats.SequenceGaps, "3 -> 7 is one gap")
+	assert.Equal(t, int64(3), stats.MissingSequences, "4, 5 and 6 were skipped")
+	assert.Equal(t, int64(1), stats.OutOfOrderTicks, "5 after 7 is out of order")
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(qualitySequenceGaps.WithLabelValues("quality-seq")))
+	assert.Equal(t, 3.0, testutil.ToFloat64(qualityMissingSequences.WithLabelValues("quality-seq")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(qualityOutOfOrderTicks.WithLabelValues("quality-seq")))
+}
+
+func TestDataQualityMonitor_NonContiguousSequences(t *testing.T) {
+	serverTime := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	monitor := newTestQualityMonitor(t, "quality-noncontig", &serverTime)
+	monitor.SetProvider(newSequencedProvider(t, "quality-noncontig", false))
+
+	for _, seq := range []int64{10, 25, 40, 30} {
+		monitor.Observe(&models.Tick{Symbol: "AAPL", Price: 150, Timestamp: serverTime, Sequence: seq})
+	}
+
+	stats := monitor.GetStats()
+	assert.Equal(t, int64(0), stats.SequenceGaps, "skipped numbers are not lost ticks")
+	assert.Equal(t, int64(0), stats.MissingSequences)
+	assert.Equal(t, int64(1), stats.OutOfOrderTicks, "30 after 40 is still out of order")
+}
+
+func TestDataQualityMonitor_CompositeProviderLabels(t *testing.T) {
+	serverTime := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	monitor := newTestQualityMonitor(t, "composite", &serverTime)
+
+	composite, err := NewCompositeProvider(map[string]Provider{
+		"quality-primary":   newSequencedProvider(t, "quality-primary", true),
+		"quality-secondary": newSequencedProvider(t, "quality-secondary", false),
+	}, CompositeProviderConfig{
+		SymbolProviders: map[string]string{"MSFT": "quality-secondary"},
+		DefaultProvider: "quality-primary",
+	})
+	require.NoError(t, err)
+	monitor.SetProvider(composite)
+
+	for _, seq := range []int64{1, 3} {
+		monitor.Observe(&models.Tick{Symbol: "AAPL", Price: 150, Timestamp: serverTime.Add(-100 * time.Millisecond), Sequence: seq})
+		monitor.Observe(&models.Tick{Symbol: "MSFT", Price: 300, Timestamp: serverTime.Add(-300 * time.Millisecond), Sequence: seq})
+	}
+	serverTime = serverTime.Add(10 * time.Second)
+	monitor.Flush()
+
+	assert.Equal(t, int64(1), monitor.GetStats().SequenceGaps, "only the contiguous provider's gap counts")
+	assert.Equal(t, 1.0, testutil.ToFloat64(qualitySequenceGaps.WithLabelValues("quality-primary")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(qualitySequenceGaps.WithLabelValues("quality-secondary")))
+	assert.InDelta(t, 0.2, testutil.ToFloat64(qualityTicksPerSecond.WithLabelValues("quality-primary")), 0.001)
+	assert.InDelta(t, 0.1, testutil.ToFloat64(qualityLatencySeconds.WithLabelValues("quality-primary")), 0.001)
+	assert.InDelta(t, 0.3, testutil.ToFloat64(qualityLatencySeconds.WithLabelValues("quality-secondary")), 0.001)
+	assert.InDelta(t, 0.4, monitor.GetStats().TicksPerSecond, 0.001)
+}
+
+func TestDataQualityMonitor_UnsequencedOutOfOrderTick(t *testing.T) {
+	serverTime := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	monitor := newTestQualityMonitor(t, "quality-ts", &serverTime)
+
+	base := serverTime.Add(-time.Second)
+	for _, offset := range []time.Duration{0, 200, 100, 300} {
+		monitor.Observe(&models.Tick{Symbol: "AAPL", Price: 150, Timestamp: base.Add(offset * time.Millisecond)})
+	}
+
+	stats := monitor.GetStats()
+	assert.Equal(t, int64(1), stats.OutOfOrderTicks, "the +100ms tick arrived after the +200ms tick")
+	assert.Equal(t, int64(0), stats.SequenceGaps)
+	assert.Equal(t, 1.0, testutil.ToFloat64(qualityOutOfOrderTicks.WithLabelValues("quality-ts")))
+}
+
+func TestDataQualityMonitor_RateAndLatency(t *testing.T) {
+	serverTime := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	monitor := newTestQualityMonitor(t, "quality-rate", &serverTime)
+
+	// 20 ticks over a 10s window, 100ms and 300ms behind server time
+	for i := 0; i < 20; i++ {
+		serverTime = serverTime.Add(500 * time.Millisecond)
+		latency := 100 * time.Millisecond
+		if i%2 == 1 {
+			latency = 300 * time.Millisecond
+		}
+		monitor.Observe(&models.Tick{Symbol: "AAPL", Price: 150, Timestamp: serverTime.Add(-latency)})
+	}
+	monitor.Flush()
+
+	stats := monitor.GetStats()
+	assert.InDelta(t, 2.0, stats.TicksPerSecond, 0.001)
+	assert.Equal(t, 200*time.Millisecond, stats.AvgLatency)
+	assert.InDelta(t, 2.0, testutil.ToFloat64(qualityTicksPerSecond.WithLabelValues("quality-rate")), 0.001)
+	assert.InDelta(t, 0.2, testutil.ToFloat64(qualityLatencySeconds.WithLabelValues("quality-rate")), 0.001)
+
+	// A silent window drops the rate to zero and keeps the last latency
+	serverTime = serverTime.Add(10 * time.Second)
+	monitor.Flush()
+
+	stats = monitor.GetStats()
+	assert.Equal(t, 0.0, stats.TicksPerSecond)
+	assert.Equal(t, 200*time.Millisecond, stats.AvgLatency)
+	assert.Equal(t, 0.0, testutil.ToFloat64(qualityTicksPerSecond.WithLabelValues("quality-rate")))
+}
+
+func TestDataQualityMonitor_Disabled(t *testing.T) {
+	monitor := NewDataQualityMonitor(DataQualityConfig{}, "quality-disabled")
+	assert.Nil(t, monitor)
+
+	// A nil monitor ignores ticks
+	monitor.Observe(&models.Tick{Symbol: "AAPL", Price: 150, Timestamp: time.Now()})
+	monitor.Flush()
+	assert.Equal(t, DataQualityStats{}, monitor.GetStats())
+}
@@ -0,0 +1,226 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// providerReconnectsTotal counts successful resubscriptions after a provider's tick channel closed
+var providerReconnectsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "ingest_provider_reconnects_total",
+		Help: "Total number of times the provider was reconnected after its tick channel closed",
+	},
+	[]string{"provider"},
+)
+
+// ReconnectConfig holds the backoff used to resubscribe to a provider
+type ReconnectConfig struct {
+	InitialDelay time.Duration // Delay before the first attempt (default: 1s)
+	MaxDelay     time.Duration // Upper bound for the doubling delay (default: 30s)
+}
+
+// DefaultReconnectConfig returns default reconnect configuration
+func DefaultReconnectConfig() ReconnectConfig {
+	return ReconnectConfig{
+		InitialDelay: 1 * time.Second,
+		MaxDelay:     30 * time.Second,
+	}
+}
+
+// ReconnectStats holds provider reconnect statistics
+type ReconnectStats struct {
+	Reconnects       int64     `json:"reconnects"`
+	LastReconnect    time.Time `json:"last_reconnect,omitempty"`
+	Reconnecting     bool      `json:"reconnecting"`
+	FailedAttempts   int64     `json:"failed_attempts"`
+	LastAttemptError string    `json:"last_attempt_error,omitempty"`
+}
+
+// ReconnectingFeed delivers a provider's ticks and, when the tick channel closes (e.g. the
+// upstream connection dropped), connects and subscribes again with capped exponential backoff,
+// relying on the Provider reconnect contract for a new channel.
+// Ticks are handled on the goroutine calling Run, so reconnects start no goroutines.
+type ReconnectingFeed struct {
+	provider Provider
+	config   ReconnectConfig
+
+	mu      sync.RWMutex
+	symbols []string // Symbols subscribed on every (re)connect
+	stats   ReconnectStats
+}
+
+// NewReconnectingFeed creates a feed for a provider subscribed to symbols
+func NewReconnectingFeed(provider Provider, symbols []string, config ReconnectConfig) *ReconnectingFeed {
+	defaults := DefaultReconnectConfig()
+	if config.InitialDelay <= 0 {
+		config.InitialDelay = defaults.InitialDelay
+	}
+	if config.MaxDelay < config.InitialDelay {
+		config.MaxDelay = config.InitialDelay
+	}
+
+	return &ReconnectingFeed{
+		provider: provider,
+		symbols:  symbols,
+		config:   config,
+	}
+}
+
+// Run passes every tick from tickChan (the channel returned by the initial Subscribe) to
+// handle, resubscribing whenever the channel closes, until ctx is done
+func (f *ReconnectingFeed) Run(ctx context.Context, tickChan <-chan *models.Tick, handle func(*models.Tick)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case tick, ok := <-tickChan:
+			if !ok {
+				logger.Warn("Tick channel closed, reconnecting provider",
+					logger.String("provider", f.provider.GetName()),
+				)
+				tickChan = f.resubscribe(ctx)
+				if tickChan == nil {
+					return // Context done during backoff
+				}
+				continue
+			}
+
+			if tick != nil {
+				handle(tick)
+			}
+		}
+	}
+}
+
+// resubscribe connects and subscribes until it succeeds, doubling the delay between attempts.
+// Returns nil if ctx is done first.
+func (f *ReconnectingFeed) resubscribe(ctx context.Context) <-chan *models.Tick {
+	f.mu.Lock()
+	f.stats.Reconnecting = true
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.stats.Reconnecting = false
+		f.mu.Unlock()
+	}()
+
+	delay := f.config.InitialDelay
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+		}
+
+		tickChan, err := f.connect(ctx)
+		if err == nil {
+			f.mu.Lock()
+			f.stats.Reconnects++
+			f.stats.LastReconnect = time.Now().UTC()
+			f.mu.Unlock()
+			providerReconnectsTotal.WithLabelValues(f.provider.GetName()).Inc()
+
+			logger.Info("Provider reconnected",
+				logger.String("provider", f.provider.GetName()),
+				logger.Int("attempts", attempt),
+			)
+			return tickChan
+		}
+
+		f.mu.Lock()
+		f.stats.FailedAttempts++
+		f.stats.LastAttemptError = err.Error()
+		f.mu.Unlock()
+
+		delay *= 2
+		if delay > f.config.MaxDelay {
+			delay = f.config.MaxDelay
+		}
+		logger.Warn("Failed to reconnect provider",
+			logger.ErrorField(err),
+			logger.String("provider", f.provider.GetName()),
+			logger.Int("attempt", attempt),
+			logger.Duration("next_delay", delay),
+		)
+		timer.Reset(delay)
+	}
+}
+
+// connect connects the provider (unless it still reports a connection) and subscribes again
+func (f *ReconnectingFeed) connect(ctx context.Context) (<-chan *models.Tick, error) {
+	if err := f.provider.Connect(ctx); err != nil && !errors.Is(err, ErrProviderAlreadyConnected) {
+		return nil, err
+	}
+	return f.provider.Subscribe(ctx, f.Symbols())
+}
+
+// Symbols returns the symbols the feed subscribes to
+func (f *ReconnectingFeed) Symbols() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return append([]string(nil), f.symbols...)
+}
+
+// Resubscribe changes the feed's symbols at runtime: symbols no longer wanted are
+// unsubscribed and new ones subscribed on the running provider, whose ticks keep arriving on
+// the tick channel Run is reading (Subscribe returns the same channel within a connection).
+// Returns the symbols added and removed.
+func (f *ReconnectingFeed) Resubscribe(ctx context.Context, symbols []string) (added, removed []string, err error) {
+	f.mu.Lock()
+	wanted := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		wanted[symbol] = true
+	}
+	current := make(map[string]bool, len(f.symbols))
+	for _, symbol := range f.symbols {
+		current[symbol] = true
+		if !wanted[symbol] {
+			removed = append(removed, symbol)
+		}
+	}
+	for symbol := range wanted {
+		if !current[symbol] {
+			added = append(added, symbol)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	// Reconnects converge on the new symbols even if updating the running subscription fails
+	f.symbols = append([]string(nil), symbols...)
+	f.mu.Unlock()
+
+	if len(removed) > 0 {
+		if err := f.provider.Unsubscribe(ctx, removed); err != nil {
+			return nil, nil, fmt.Errorf("failed to unsubscribe: %w", err)
+		}
+	}
+	if len(added) > 0 {
+		if _, err := f.provider.Subscribe(ctx, added); err != nil {
+			return nil, nil, fmt.Errorf("failed to subscribe: %w", err)
+		}
+	}
+
+	return added, removed, nil
+}
+
+// GetStats returns current reconnect statistics
+func (f *ReconnectingFeed) GetStats() ReconnectStats {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.stats
+}
@@ -0,0 +1,210 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drop simulates an upstream disconnect: the tick channel closes and the next
+// Subscribe returns a new one
+func (s *stubProvider) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connected = false
+	close(s.tickChan)
+	s.tickChan = make(chan *models.Tick, 10)
+}
+
+func (s *stubProvider) setConnectErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connectErr = err
+}
+
+func (s *stubProvider) send(tick *models.Tick) {
+	s.mu.Lock()
+	ch := s.tickChan
+	s.mu.Unlock()
+	ch <- tick
+}
+
+// runFeed runs the feed in the background, returning the received ticks and a channel
+// closed when Run returns
+func runFeed(ctx context.Context, feed *ReconnectingFeed, tickChan <-chan *models.Tick) (<-chan *models.Tick, <-chan struct{}) {
+	received := make(chan *models.Tick, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		feed.Run(ctx, tickChan, func(tick *models.Tick) {
+			received <- tick
+		})
+	}()
+	return received, done
+}
+
+func TestReconnectingFeed_ResubscribesAfterChannelCloses(t *testing.T) {
+	provider := newStubProvider("stub")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, provider.Connect(ctx))
+	tickChan, err := provider.Subscribe(ctx, []string{"AAPL"})
+	require.NoError(t, err)
+
+	feed := NewReconnectingFeed(provider, []string{"AAPL"}, ReconnectConfig{
+		InitialDelay: 10 * time.Millisecond,
+		MaxDelay:     40 * time.Millisecond,
+	})
+	received, done := runFeed(ctx, feed, tickChan)
+
+	provider.send(&models.Tick{Symbol: "AAPL", Price: 150.0})
+	assert.Equal(t, 150.0, (<-received).Price)
+
+	// Fail the first reconnect attempts, then let the provider come back
+	provider.setConnectErr(errors.New("connection refused"))
+	provider.drop()
+	time.Sleep(50 * time.Millisecond)
+	provider.setConnectErr(nil)
+
+	require.Eventually(t, func() bool {
+		return feed.GetStats().Reconnects == 1
+	}, 2*time.Second, 5*time.Millisecond)
+
+	provider.send(&models.Tick{Symbol: "AAPL", Price: 151.0})
+	select {
+	case tick := <-received:
+		assert.Equal(t, 151.0, tick.Price)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for tick after reconnect")
+	}
+
+	stats := feed.GetStats()
+	assert.False(t, stats.LastReconnect.IsZero())
+	assert.False(t, stats.Reconnecting)
+	assert.GreaterOrEqual(t, stats.FailedAttempts, int64(1))
+	assert.Equal(t, "connection refused", stats.LastAttemptError)
+	assert.Equal(t, []string{"AAPL", "AAPL"}, provider.Subscribed())
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
+
+func TestReconnectingFeed_MockProviderReconnect(t *testing.T) {
+	provider, err := NewMockProvider(ProviderConfig{})
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, provider.Connect(ctx))
+	tickChan, err := provider.Subscribe(ctx, []string{"AAPL"})
+	require.NoError(t, err)
+
+	feed := NewReconnectingFeed(provider, []string{"AAPL"}, ReconnectConfig{
+		InitialDelay: 10 * time.Millisecond,
+		MaxDelay:     40 * time.Millisecond,
+	})
+	received, done := runFeed(ctx, feed, tickChan)
+	<-received
+
+	// Closing the provider closes its channel; the feed connects and subscribes again
+	require.NoError(t, provider.Close())
+	require.Eventually(t, func() bool {
+		return feed.GetStats().Reconnects == 1
+	}, 2*time.Second, 5*time.Millisecond)
+
+	// Drain ticks sent before the close, then expect fresh ones from the new connection
+	for len(received) > 0 {
+		<-received
+	}
+	select {
+	case tick := <-received:
+		assert.Equal(t, "AAPL", tick.Symbol)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for tick after reconnect")
+	}
+
+	cancel()
+	<-done
+	require.NoError(t, provider.Close())
+}
+
+func TestReconnectingFeed_CancelDuringBackoff(t *testing.T) {
+	provider := newStubProvider("stub")
+	provider.setConnectErr(errors.New("connection refused"))
+	ctx, cancel := context.WithCancel(context.Background())
+
+	tickChan := make(chan *models.Tick)
+	close(tickChan)
+
+	feed := NewReconnectingFeed(provider, []string{"AAPL"}, ReconnectConfig{
+		InitialDelay: time.Hour,
+		MaxDelay:     time.Hour,
+	})
+	_, done := runFeed(ctx, feed, tickChan)
+
+	require.Eventually(t, func() bool {
+		return feed.GetStats().Reconnecting
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return when cancelled during backoff")
+	}
+	assert.Equal(t, int64(0), feed.GetStats().Reconnects)
+}
+
+func TestReconnectingFeed_Defaults(t *testing.T) {
+	feed := NewReconnectingFeed(newStubProvider("stub"), nil, ReconnectConfig{MaxDelay: time.Millisecond})
+	assert.Equal(t, time.Second, feed.config.InitialDelay)
+	assert.Equal(t, time.Second, feed.config.MaxDelay)
+}
+
+func TestReconnectingFeed_Resubscribe(t *testing.T) {
+	provider := newStubProvider("stub")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, provider.Connect(ctx))
+	tickChan, err := provider.Subscribe(ctx, []string{"AAPL", "MSFT"})
+	require.NoError(t, err)
+
+	feed := NewReconnectingFeed(provider, []string{"AAPL", "MSFT"}, ReconnectConfig{
+		InitialDelay: 10 * time.Millisecond,
+		MaxDelay:     40 * time.Millisecond,
+	})
+	received, done := runFeed(ctx, feed, tickChan)
+
+	added, removed, err := feed.Resubscribe(ctx, []string{"AAPL", "TSLA", "NVDA"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"NVDA", "TSLA"}, added)
+	assert.Equal(t, []string{"MSFT"}, removed)
+	assert.Equal(t, []string{"AAPL", "MSFT", "NVDA", "TSLA"}, provider.Subscribed())
+	assert.Equal(t, []string{"MSFT"}, provider.Unsubscribed())
+	assert.Equal(t, []string{"AAPL", "TSLA", "NVDA"}, feed.Symbols())
+
+	// Ticks for the new symbols arrive on the channel the feed is already reading
+	provider.send(&models.Tick{Symbol: "TSLA", Price: 200.0})
+	assert.Equal(t, "TSLA", (<-received).Symbol)
+
+	// Reconnects subscribe to the new universe
+	provider.drop()
+	require.Eventually(t, func() bool {
+		return feed.GetStats().Reconnects == 1
+	}, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"AAPL", "TSLA", "NVDA"}, provider.Subscribed()[4:])
+
+	cancel()
+	<-done
+}
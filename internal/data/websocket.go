@@ -169,6 +169,11 @@ func (w *WebSocketClient) connectLoop() {
 		// Attempt connection
 		err := w.attemptConnection()
 		if err == nil {
+			// Capture the close channel before the pumps can close and replace it
+			w.mu.RLock()
+			closeChan := w.closeChan
+			w.mu.RUnlock()
+
 			// Connection successful, start message handling
 			w.wg.Add(2)
 			go w.readPump()
@@ -183,7 +188,7 @@ func (w *WebSocketClient) connectLoop() {
 			}
 
 			// Wait for connection to close
-			<-w.closeChan
+			<-closeChan
 
 			// Notify disconnection
 			w.mu.RLock()
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
 	indicatorpkg "github.com/mohamedkhairy/stock-scanner/pkg/indicator"
 	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
 )
@@ -13,24 +15,32 @@ import (
 // CalculatorFactory is a function that creates a new calculator instance
 type CalculatorFactory func() (indicatorpkg.Calculator, error)
 
-// OnIndicatorsUpdated is a callback function called after indicators are updated
-type OnIndicatorsUpdated func(symbol string, indicators map[string]float64)
+// CalculatorGroupFactory creates calculators that share state (e.g. the MACD line, signal
+// and histogram over the same EMAs), keyed by indicator name
+type CalculatorGroupFactory func() (map[string]indicatorpkg.Calculator, error)
+
+// OnIndicatorsUpdated is a callback function called after indicators are updated, with the
+// timestamp of the bar they were computed from
+type OnIndicatorsUpdated func(symbol string, timestamp time.Time, indicators map[string]float64)
 
 // Engine processes finalized bars and computes indicators
 type Engine struct {
 	indicatorRegistry   *IndicatorRegistry // Registry of all available indicators
 	requiredIndicators  map[string]bool    // Set of required indicator names (empty = all)
 	symbolStates        map[string]*indicatorpkg.SymbolState
-	onIndicatorsUpdated OnIndicatorsUpdated // Callback after indicators are updated
+	rehydratedThrough   map[string]time.Time // Newest rehydrated bar per symbol, until a later bar is processed
+	onIndicatorsUpdated OnIndicatorsUpdated  // Callback after indicators are updated
 	mu                  sync.RWMutex
 	ctx                 context.Context
 	cancel              context.CancelFunc
-	maxBars             int // Maximum bars to keep per symbol
+	maxBars             int                // Maximum bars to keep per symbol
+	overrides           ParameterOverrides // Per-symbol indicator period overrides
 }
 
 // EngineConfig holds configuration for the indicator engine
 type EngineConfig struct {
-	MaxBars int // Maximum number of bars to keep per symbol (default: 200)
+	MaxBars            int                // Maximum number of bars to keep per symbol (default: 200)
+	ParameterOverrides ParameterOverrides // Per-symbol indicator period overrides (computed in addition to defaults)
 }
 
 // DefaultEngineConfig returns default configuration
@@ -48,9 +58,11 @@ func NewEngine(config EngineConfig, registry *IndicatorRegistry) *Engine {
 		indicatorRegistry:  registry,
 		requiredIndicators: make(map[string]bool), // Empty = all indicators
 		symbolStates:       make(map[string]*indicatorpkg.SymbolState),
+		rehydratedThrough:  make(map[string]time.Time),
 		ctx:                ctx,
 		cancel:             cancel,
 		maxBars:            config.MaxBars,
+		overrides:          config.ParameterOverrides,
 	}
 }
 
@@ -86,40 +98,24 @@ func (e *Engine) ProcessBar(bar *models.Bar1m) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	// Bars the rehydration already replayed (e.g. still in the stream after a restart) would
+	// be counted twice by incremental calculators such as EMAs
+	if through, ok := e.rehydratedThrough[bar.Symbol]; ok {
+		if !bar.Timestamp.After(through) {
+			logger.Debug("Skipping bar already rehydrated",
+				logger.String("symbol", bar.Symbol),
+				logger.Time("timestamp", bar.Timestamp),
+			)
+			return nil
+		}
+		delete(e.rehydratedThrough, bar.Symbol)
+	}
+
 	// Get or create symbol state
 	state, exists := e.symbolStates[bar.Symbol]
 	if !exists {
-		state = indicatorpkg.NewSymbolState(bar.Symbol, e.maxBars)
+		state = e.newSymbolState(bar.Symbol)
 		e.symbolStates[bar.Symbol] = state
-
-		// Create calculator instances for this symbol using registry
-		// If requiredIndicators is empty, create all indicators
-		// Otherwise, only create required ones
-		allIndicators := len(e.requiredIndicators) == 0
-		availableIndicators := e.indicatorRegistry.ListAvailable()
-
-		for _, name := range availableIndicators {
-			// Skip if we have required indicators and this one is not required
-			if !allIndicators && !e.requiredIndicators[name] {
-				continue
-			}
-
-			factory, exists := e.indicatorRegistry.GetFactory(name)
-			if !exists {
-				continue
-			}
-
-			calc, err := factory()
-			if err != nil {
-				logger.Warn("Failed to create calculator",
-					logger.String("name", name),
-					logger.String("symbol", bar.Symbol),
-					logger.ErrorField(err),
-				)
-				continue
-			}
-			state.AddCalculator(calc)
-		}
 	}
 
 	// Update symbol state with the new bar
@@ -132,13 +128,134 @@ func (e *Engine) ProcessBar(bar *models.Bar1m) error {
 	if e.onIndicatorsUpdated != nil {
 		indicators := state.GetAllValues()
 		if len(indicators) > 0 {
-			e.onIndicatorsUpdated(bar.Symbol, indicators)
+			e.onIndicatorsUpdated(bar.Symbol, bar.Timestamp, indicators)
+		}
+	}
+
+	return nil
+}
+
+// newSymbolState creates a symbol's state with its calculators; the caller holds e.mu
+func (e *Engine) newSymbolState(symbol string) *indicatorpkg.SymbolState {
+	state := indicatorpkg.NewSymbolState(symbol, e.maxBars)
+
+	// Create calculator instances for this symbol using registry
+	// If requiredIndicators is empty, create all indicators
+	// Otherwise, only create required ones
+	allIndicators := len(e.requiredIndicators) == 0
+	availableIndicators := e.indicatorRegistry.ListAvailable()
+	factories := make(map[string]CalculatorFactory, len(availableIndicators))
+
+	for _, name := range availableIndicators {
+		// Skip if we have required indicators and this one is not required
+		if !allIndicators && !e.requiredIndicators[name] {
+			continue
+		}
+
+		factory, exists := e.indicatorRegistry.GetFactory(name)
+		if !exists {
+			continue
+		}
+		factories[name] = factory
+	}
+
+	// Symbol-specific periods are always computed, under their parameterized names
+	for name, factory := range e.overrides.factories(symbol) {
+		factories[name] = factory
+	}
+
+	// Members of a calculator group share one group instance per symbol
+	groups := make(map[string]map[string]indicatorpkg.Calculator)
+	for name, factory := range factories {
+		var calc indicatorpkg.Calculator
+		var err error
+		if groupFactory, key, ok := e.indicatorRegistry.GetGroupFactory(name); ok {
+			members, created := groups[key]
+			if !created {
+				members, err = groupFactory()
+				groups[key] = members
+			}
+			if err == nil && members[name] == nil {
+				err = fmt.Errorf("calculator group %s has no %s calculator", key, name)
+			}
+			calc = members[name]
+		} else {
+			calc, err = factory()
+		}
+		if err != nil {
+			logger.Warn("Failed to create calculator",
+				logger.String("name", name),
+				logger.String("symbol", symbol),
+				logger.ErrorField(err),
+			)
+			continue
 		}
+		state.AddCalculator(calc)
+	}
+
+	return state
+}
+
+// Rehydrate rebuilds a symbol's indicator state from historical bars (oldest first), for
+// when the engine starts mid-session. Every calculator is reset and replays the bars, so
+// incremental state such as EMAs ends up as if the bars had been processed live. Nothing
+// is published; the next processed bar publishes as usual. Bars at or before the newest
+// rehydrated bar are then skipped by ProcessBar.
+func (e *Engine) Rehydrate(symbol string, bars []*models.Bar1m) error {
+	if symbol == "" {
+		return fmt.Errorf("symbol cannot be empty")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	state, exists := e.symbolStates[symbol]
+	if !exists {
+		state = e.newSymbolState(symbol)
+		e.symbolStates[symbol] = state
 	}
 
+	if err := state.Rehydrate(bars); err != nil {
+		return err
+	}
+
+	delete(e.rehydratedThrough, symbol)
+	for _, bar := range bars {
+		if bar != nil && bar.Symbol == symbol && bar.Timestamp.After(e.rehydratedThrough[symbol]) {
+			e.rehydratedThrough[symbol] = bar.Timestamp
+		}
+	}
 	return nil
 }
 
+// RehydrateFromStorage rehydrates symbols from their latest maxBars stored bars, returning
+// how many symbols had bars. Symbols that fail to load are logged and skipped.
+func (e *Engine) RehydrateFromStorage(ctx context.Context, barStorage storage.BarStorage, symbols []string, maxBars int) int {
+	rehydrated := 0
+	for _, symbol := range symbols {
+		bars, err := barStorage.GetLatestBars(ctx, symbol, maxBars)
+		if err != nil {
+			logger.Warn("Failed to load bars for indicator rehydration",
+				logger.String("symbol", symbol),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+		if len(bars) == 0 {
+			continue
+		}
+		if err := e.Rehydrate(symbol, bars); err != nil {
+			logger.Warn("Failed to rehydrate indicators",
+				logger.String("symbol", symbol),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+		rehydrated++
+	}
+	return rehydrated
+}
+
 // GetIndicators returns all indicator values for a symbol
 func (e *Engine) GetIndicators(symbol string) (map[string]float64, error) {
 	e.mu.RLock()
@@ -7,15 +7,32 @@ import (
 	indicatorpkg "github.com/mohamedkhairy/stock-scanner/pkg/indicator"
 )
 
+// RegistrationConfig holds the parameters of configurable indicators
+type RegistrationConfig struct {
+	MACD indicatorpkg.MACDParams // Periods of macd, macd_signal and macd_hist (default: 12/26/9)
+}
+
+// DefaultRegistrationConfig returns default configuration
+func DefaultRegistrationConfig() RegistrationConfig {
+	return RegistrationConfig{
+		MACD: indicatorpkg.DefaultMACDParams(),
+	}
+}
+
 // RegisterAllIndicators registers all available indicators (Techan + Custom)
 func RegisterAllIndicators(registry *IndicatorRegistry) error {
+	return RegisterAllIndicatorsWithConfig(registry, DefaultRegistrationConfig())
+}
+
+// RegisterAllIndicatorsWithConfig registers all available indicators with configured parameters
+func RegisterAllIndicatorsWithConfig(registry *IndicatorRegistry, config RegistrationConfig) error {
 	// Register Techan indicators
 	if err := registerTechanIndicators(registry); err != nil {
 		return err
 	}
 
 	// Register custom indicators (not in Techan)
-	if err := registerCustomIndicators(registry); err != nil {
+	if err := registerCustomIndicators(registry, config); err != nil {
 		return err
 	}
 
@@ -153,7 +170,7 @@ func registerTechanIndicators(registry *IndicatorRegistry) error {
 }
 
 // registerCustomIndicators registers custom indicators (not in Techan)
-func registerCustomIndicators(registry *IndicatorRegistry) error {
+func registerCustomIndicators(registry *IndicatorRegistry, config RegistrationConfig) error {
 	// VWAP indicators
 	vwapWindows := []time.Duration{
 		5 * time.Minute,
@@ -204,6 +221,78 @@ func registerCustomIndicators(registry *IndicatorRegistry) error {
 		}
 	}
 
+	// Bollinger Bands (the bands, not just the middle band, for rules like close > bb_upper_20)
+	bollingerPeriods := []int{20}
+	bollingerBands := []indicatorpkg.BollingerBandType{
+		indicatorpkg.BollingerUpper,
+		indicatorpkg.BollingerMiddle,
+		indicatorpkg.BollingerLower,
+		indicatorpkg.BollingerPercentB,
+	}
+	for _, period := range bollingerPeriods {
+		for _, band := range bollingerBands {
+			name := fmt.Sprintf("bb_%s_%d", band, period)
+			period, band := period, band
+			if err := registry.Register(name,
+				func() (indicatorpkg.Calculator, error) {
+					return indicatorpkg.NewBollingerBand(band, period, 2.0)
+				},
+				IndicatorMetadata{
+					Name:        name,
+					Type:        "custom",
+					Description: fmt.Sprintf("Bollinger Bands %s (%d period, 2.0 std dev)", band, period),
+					Category:    "volatility",
+					Parameters: map[string]interface{}{
+						"period":     period,
+						"multiplier": 2.0,
+						"band":       string(band),
+					},
+				},
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	// MACD line, signal and histogram over one incremental EMA state per symbol
+	if err := config.MACD.Validate(); err != nil {
+		return err
+	}
+	macdParams := config.MACD
+	macdNames := make([]string, 0, 3)
+	macdMetadata := make(map[string]IndicatorMetadata, 3)
+	for _, output := range []indicatorpkg.MACDOutput{
+		indicatorpkg.MACDLine,
+		indicatorpkg.MACDSignal,
+		indicatorpkg.MACDHistogram,
+	} {
+		name := string(output)
+		macdNames = append(macdNames, name)
+		macdMetadata[name] = IndicatorMetadata{
+			Name:        name,
+			Type:        "custom",
+			Description: fmt.Sprintf("MACD %s (%d, %d, %d)", output, macdParams.Fast, macdParams.Slow, macdParams.Signal),
+			Category:    "trend",
+			Parameters: map[string]interface{}{
+				"fast_period":   macdParams.Fast,
+				"slow_period":   macdParams.Slow,
+				"signal_period": macdParams.Signal,
+			},
+		}
+	}
+	if err := registry.RegisterGroup(macdNames,
+		func() (map[string]indicatorpkg.Calculator, error) {
+			series, err := indicatorpkg.NewMACDSeries(macdParams)
+			if err != nil {
+				return nil, err
+			}
+			return series.Calculators(), nil
+		},
+		macdMetadata,
+	); err != nil {
+		return err
+	}
+
 	// Price change indicators
 	priceChangeWindows := []time.Duration{
 		1 * time.Minute,
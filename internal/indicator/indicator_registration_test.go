@@ -0,0 +1,180 @@
+package indicator
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+	indicatorpkg "github.com/mohamedkhairy/stock-scanner/pkg/indicator"
+)
+
+func TestEngine_BollingerBands(t *testing.T) {
+	registry := NewIndicatorRegistry()
+	if err := RegisterAllIndicators(registry); err != nil {
+		t.Fatalf("RegisterAllIndicators() error = %v", err)
+	}
+
+	engine := NewEngine(DefaultEngineConfig(), registry)
+	engine.SetRequiredIndicators(map[string]bool{
+		"bb_upper_20":  true,
+		"bb_middle_20": true,
+		"bb_lower_20":  true,
+		"bb_pctb_20":   true,
+	})
+
+	var published []map[string]float64
+	engine.SetOnIndicatorsUpdated(func(symbol string, _ time.Time, indicators map[string]float64) {
+		published = append(published, indicators)
+	})
+
+	// Closes 1..20: nothing is published until the 20th bar
+	base := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	for i := 1; i <= 20; i++ {
+		price := float64(i)
+		bar := &models.Bar1m{
+			Symbol:    "AAPL",
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+			Open:      price,
+			High:      price,
+			Low:       price,
+			Close:     price,
+			Volume:    1000,
+		}
+		if err := engine.ProcessBar(bar); err != nil {
+			t.Fatalf("ProcessBar() error = %v", err)
+		}
+		if i < 20 && len(published) != 0 {
+			t.Fatalf("Expected nothing published during warm-up, got %v after %d bars", published, i)
+		}
+	}
+
+	if len(published) != 1 {
+		t.Fatalf("Expected one publication after warm-up, got %d", len(published))
+	}
+	indicators := published[0]
+	for _, name := range []string{"bb_upper_20", "bb_middle_20", "bb_lower_20", "bb_pctb_20"} {
+		if _, ok := indicators[name]; !ok {
+			t.Errorf("Expected %s to be published, got %v", name, indicators)
+		}
+	}
+
+	// Mean 10.5, population variance (20^2-1)/12 = 33.25
+	if indicators["bb_middle_20"] != 10.5 {
+		t.Errorf("Expected bb_middle_20 = 10.5, got %v", indicators["bb_middle_20"])
+	}
+	if indicators["bb_upper_20"] <= 20 || indicators["bb_lower_20"] >= 1 {
+		t.Errorf("Expected the bands to contain the series, got %v", indicators)
+	}
+}
+
+func TestEngine_MACDRehydration(t *testing.T) {
+	newEngine := func() *Engine {
+		registry := NewIndicatorRegistry()
+		config := DefaultRegistrationConfig()
+		config.MACD = indicatorpkg.MACDParams{Fast: 5, Slow: 10, Signal: 4}
+		if err := RegisterAllIndicatorsWithConfig(registry, config); err != nil {
+			t.Fatalf("RegisterAllIndicatorsWithConfig() error = %v", err)
+		}
+		engine := NewEngine(DefaultEngineConfig(), registry)
+		engine.SetRequiredIndicators(map[string]bool{"macd": true, "macd_signal": true, "macd_hist": true})
+		return engine
+	}
+
+	base := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	bars := make([]*models.Bar1m, 60)
+	for i := range bars {
+		price := 100 + 0.2*float64(i) + 3*math.Sin(float64(i)/3)
+		bars[i] = &models.Bar1m{
+			Symbol:    "AAPL",
+			Timestamp: base.Add(time.Duration(i) * time.Minute),
+			Open:      price,
+			High:      price,
+			Low:       price,
+			Close:     price,
+			Volume:    1000,
+		}
+	}
+
+	// live processes every bar; restarted starts mid-session, seeded from the first 45 bars
+	live := newEngine()
+	for _, bar := range bars {
+		if err := live.ProcessBar(bar); err != nil {
+			t.Fatalf("ProcessBar() error = %v", err)
+		}
+	}
+
+	restarted := newEngine()
+	barStorage := &storage.MockBarStorage{Bars: bars[:45]}
+	if n := restarted.RehydrateFromStorage(context.Background(), barStorage, []string{"AAPL", "MSFT"}, 200); n != 1 {
+		t.Errorf("Expected 1 symbol rehydrated, got %d", n)
+	}
+
+	var published map[string]float64
+	restarted.SetOnIndicatorsUpdated(func(symbol string, _ time.Time, indicators map[string]float64) {
+		published = indicators
+	})
+	// The stream still holds bars the database already had; they must not be counted twice
+	for _, bar := range bars[40:] {
+		if err := restarted.ProcessBar(bar); err != nil {
+			t.Fatalf("ProcessBar() error = %v", err)
+		}
+	}
+
+	want, _ := live.GetIndicators("AAPL")
+	for _, name := range []string{"macd", "macd_signal", "macd_hist"} {
+		if _, ok := want[name]; !ok {
+			t.Fatalf("Expected %s from the live engine, got %v", name, want)
+		}
+		if math.Abs(published[name]-want[name]) > 1e-9 {
+			t.Errorf("%s after rehydration = %v, live = %v", name, published[name], want[name])
+		}
+	}
+}
+
+func TestEngine_CalculatorGroupSharedPerSymbol(t *testing.T) {
+	registry := NewIndicatorRegistry()
+	groups := 0
+	err := registry.RegisterGroup([]string{"macd", "macd_signal", "macd_hist"},
+		func() (map[string]indicatorpkg.Calculator, error) {
+			groups++
+			series, err := indicatorpkg.NewMACDSeries(indicatorpkg.MACDParams{Fast: 2, Slow: 3, Signal: 2})
+			if err != nil {
+				return nil, err
+			}
+			return series.Calculators(), nil
+		},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("RegisterGroup() error = %v", err)
+	}
+	if err := registry.RegisterGroup([]string{"macd"}, nil, nil); err == nil {
+		t.Error("Expected an error registering macd twice")
+	}
+
+	engine := NewEngine(DefaultEngineConfig(), registry)
+	base := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	for _, symbol := range []string{"AAPL", "MSFT"} {
+		for i := 0; i < 5; i++ {
+			price := 100 + float64(i*i)
+			bar := &models.Bar1m{Symbol: symbol, Timestamp: base.Add(time.Duration(i) * time.Minute), Open: price, High: price, Low: price, Close: price, Volume: 100}
+			if err := engine.ProcessBar(bar); err != nil {
+				t.Fatalf("ProcessBar() error = %v", err)
+			}
+		}
+	}
+
+	if groups != 2 {
+		t.Errorf("Expected one MACD group per symbol, got %d", groups)
+	}
+	values, _ := engine.GetIndicators("AAPL")
+	if len(values) != 3 {
+		t.Fatalf("Expected the three MACD values, got %v", values)
+	}
+	if math.Abs(values["macd_hist"]-(values["macd"]-values["macd_signal"])) > 1e-9 {
+		t.Errorf("Expected macd_hist = macd - macd_signal, got %v", values)
+	}
+}
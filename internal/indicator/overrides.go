@@ -0,0 +1,90 @@
+package indicator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	indicatorpkg "github.com/mohamedkhairy/stock-scanner/pkg/indicator"
+)
+
+// ParameterOverrides maps a symbol to indicator period overrides (indicator family -> period).
+// Overridden indicators are published under parameterized names (e.g. "rsi_7") that rules can reference.
+type ParameterOverrides map[string]map[string]int
+
+// overridableIndicators creates single-period calculators by indicator family
+var overridableIndicators = map[string]func(period int) CalculatorFactory{
+	"rsi": func(period int) CalculatorFactory { return indicatorpkg.CreateTechanRSI(period) },
+	"ema": func(period int) CalculatorFactory { return indicatorpkg.CreateTechanEMA(period) },
+	"sma": func(period int) CalculatorFactory { return indicatorpkg.CreateTechanSMA(period) },
+	"atr": func(period int) CalculatorFactory { return indicatorpkg.CreateTechanATR(period) },
+}
+
+// ParseParameterOverrides parses overrides in the format
+// "SYMBOL[|SYMBOL...]:family=period[;family=period...],..." e.g. "TSLA|GME:rsi=7;ema=5,AAPL:rsi=21"
+func ParseParameterOverrides(spec string) (ParameterOverrides, error) {
+	overrides := make(ParameterOverrides)
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return overrides, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("invalid indicator override %q (expected SYMBOLS:family=period)", entry)
+		}
+
+		periods := make(map[string]int)
+		for _, param := range strings.Split(parts[1], ";") {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid indicator override parameter %q (expected family=period)", param)
+			}
+			family := strings.ToLower(strings.TrimSpace(kv[0]))
+			if _, ok := overridableIndicators[family]; !ok {
+				return nil, fmt.Errorf("indicator %q does not support overrides", family)
+			}
+			period, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+			if err != nil || period < 2 {
+				return nil, fmt.Errorf("invalid period %q for indicator %s (must be >= 2)", kv[1], family)
+			}
+			periods[family] = period
+		}
+
+		for _, symbol := range strings.Split(parts[0], "|") {
+			symbol = strings.ToUpper(strings.TrimSpace(symbol))
+			if symbol == "" {
+				return nil, fmt.Errorf("invalid indicator override %q (empty symbol)", entry)
+			}
+			if overrides[symbol] == nil {
+				overrides[symbol] = make(map[string]int)
+			}
+			for family, period := range periods {
+				overrides[symbol][family] = period
+			}
+		}
+	}
+
+	return overrides, nil
+}
+
+// IndicatorNames returns the parameterized indicator names overridden for a symbol (sorted)
+func (o ParameterOverrides) IndicatorNames(symbol string) []string {
+	names := make([]string, 0, len(o[symbol]))
+	for family, period := range o[symbol] {
+		names = append(names, fmt.Sprintf("%s_%d", family, period))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// factories returns calculator factories for a symbol's overrides keyed by indicator name
+func (o ParameterOverrides) factories(symbol string) map[string]CalculatorFactory {
+	factories := make(map[string]CalculatorFactory, len(o[symbol]))
+	for family, period := range o[symbol] {
+		factories[fmt.Sprintf("%s_%d", family, period)] = overridableIndicators[family](period)
+	}
+	return factories
+}
@@ -0,0 +1,144 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+	indicatorpkg "github.com/mohamedkhairy/stock-scanner/pkg/indicator"
+)
+
+func TestParseParameterOverrides(t *testing.T) {
+	overrides, err := ParseParameterOverrides(" tsla|GME:rsi=7;EMA=5 , AAPL:rsi=21")
+	if err != nil {
+		t.Fatalf("ParseParameterOverrides() error = %v", err)
+	}
+
+	if overrides["TSLA"]["rsi"] != 7 || overrides["TSLA"]["ema"] != 5 {
+		t.Errorf("TSLA overrides = %v, want rsi=7 ema=5", overrides["TSLA"])
+	}
+	if overrides["GME"]["rsi"] != 7 {
+		t.Errorf("GME overrides = %v, want rsi=7", overrides["GME"])
+	}
+	if overrides["AAPL"]["rsi"] != 21 {
+		t.Errorf("AAPL overrides = %v, want rsi=21", overrides["AAPL"])
+	}
+
+	names := overrides.IndicatorNames("TSLA")
+	if len(names) != 2 || names[0] != "ema_5" || names[1] != "rsi_7" {
+		t.Errorf("IndicatorNames(TSLA) = %v, want [ema_5 rsi_7]", names)
+	}
+
+	empty, err := ParseParameterOverrides("")
+	if err != nil || len(empty) != 0 {
+		t.Errorf("ParseParameterOverrides(\"\") = %v, %v, want empty", empty, err)
+	}
+}
+
+func TestParseParameterOverrides_Invalid(t *testing.T) {
+	specs := []string{
+		"TSLA",
+		"TSLA:",
+		":rsi=7",
+		"TSLA:rsi",
+		"TSLA:macd=7",
+		"TSLA:rsi=abc",
+		"TSLA:rsi=1",
+		"TSLA||GME:rsi=7",
+	}
+	for _, spec := range specs {
+		if _, err := ParseParameterOverrides(spec); err == nil {
+			t.Errorf("ParseParameterOverrides(%q) expected error", spec)
+		}
+	}
+}
+
+func TestEngine_ParameterOverrides(t *testing.T) {
+	registry := NewIndicatorRegistry()
+	if err := registry.Register("rsi_14", indicatorpkg.CreateTechanRSI(14), IndicatorMetadata{Name: "rsi_14"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	overrides, err := ParseParameterOverrides("TSLA:rsi=7")
+	if err != nil {
+		t.Fatalf("ParseParameterOverrides() error = %v", err)
+	}
+
+	config := DefaultEngineConfig()
+	config.ParameterOverrides = overrides
+	engine := NewEngine(config, registry)
+	engine.SetRequiredIndicators(map[string]bool{"rsi_14": true})
+
+	// Alternating moves so RSI is neither 0 nor 100 and differs between periods
+	base := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	price := 100.0
+	for i := 0; i < 30; i++ {
+		if i%3 == 0 {
+			price -= 1.5
+		} else {
+			price += 1.0
+		}
+		for _, symbol := range []string{"TSLA", "AAPL"} {
+			bar := &models.Bar1m{
+				Symbol:    symbol,
+				Timestamp: base.Add(time.Duration(i) * time.Minute),
+				Open:      price,
+				High:      price + 0.5,
+				Low:       price - 0.5,
+				Close:     price,
+				Volume:    1000,
+			}
+			if err := engine.ProcessBar(bar); err != nil {
+				t.Fatalf("ProcessBar() error = %v", err)
+			}
+		}
+	}
+
+	tsla, err := engine.GetIndicators("TSLA")
+	if err != nil {
+		t.Fatalf("GetIndicators(TSLA) error = %v", err)
+	}
+	aapl, err := engine.GetIndicators("AAPL")
+	if err != nil {
+		t.Fatalf("GetIndicators(AAPL) error = %v", err)
+	}
+
+	rsi7, ok := tsla["rsi_7"]
+	if !ok {
+		t.Fatalf("TSLA indicators = %v, want rsi_7", tsla)
+	}
+	if _, ok := tsla["rsi_14"]; !ok {
+		t.Errorf("TSLA indicators = %v, want default rsi_14 as well", tsla)
+	}
+	if _, ok := aapl["rsi_7"]; ok {
+		t.Errorf("AAPL indicators = %v, should not include override rsi_7", aapl)
+	}
+	if _, ok := aapl["rsi_14"]; !ok {
+		t.Errorf("AAPL indicators = %v, want rsi_14", aapl)
+	}
+	if math.Abs(rsi7-tsla["rsi_14"]) < 1e-9 {
+		t.Errorf("rsi_7 = rsi_14 = %v, expected different periods to produce different values", rsi7)
+	}
+
+	// Rules reference the parameterized name directly
+	compiler := rules.NewCompiler(rules.NewMetricResolver())
+	compiled, err := compiler.CompileRule(&models.Rule{
+		ID:         "rule-rsi-7",
+		Name:       "Fast RSI",
+		Conditions: []models.Condition{{Metric: "rsi_7", Operator: ">", Value: 0.0}},
+		Enabled:    true,
+	})
+	if err != nil {
+		t.Fatalf("CompileRule() error = %v", err)
+	}
+
+	matched, err := compiled("TSLA", tsla)
+	if err != nil || !matched {
+		t.Errorf("rule on TSLA = %v, %v, want match", matched, err)
+	}
+	if matched, _ := compiled("AAPL", aapl); matched {
+		t.Error("rule on AAPL matched without rsi_7")
+	}
+}
@@ -20,6 +20,7 @@ type Publisher struct {
 	toplistStore       toplist.ToplistStore   // Toplist store for loading configs
 	mapper             *toplist.MetricMapper  // Metric mapper
 	toplistEnabled     bool
+	indicatorStorage   storage.IndicatorStorage // Optional historical indicator storage
 	ctx                context.Context
 	cancel             context.CancelFunc
 	wg                 sync.WaitGroup
@@ -87,6 +88,13 @@ func (p *Publisher) SetToplistStore(store toplist.ToplistStore) {
 	p.toplistStore = store
 }
 
+// SetIndicatorStorage sets the storage published indicator values are persisted to (nil = disabled)
+func (p *Publisher) SetIndicatorStorage(indicatorStorage storage.IndicatorStorage) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.indicatorStorage = indicatorStorage
+}
+
 // Start starts the publisher
 func (p *Publisher) Start() error {
 	p.mu.Lock()
@@ -126,18 +134,20 @@ func (p *Publisher) Stop() {
 	logger.Info("Indicator publisher stopped")
 }
 
-// PublishIndicators publishes indicator values for a symbol
-func (p *Publisher) PublishIndicators(symbol string, indicators map[string]float64) error {
+// PublishIndicators publishes indicator values for a symbol, stamped with the timestamp of
+// the bar they were computed from so replayed and backfilled bars produce the right history
+func (p *Publisher) PublishIndicators(symbol string, timestamp time.Time, indicators map[string]float64) error {
 	if len(indicators) == 0 {
 		return nil // Nothing to publish
 	}
 
 	key := fmt.Sprintf("%s%s", p.config.IndicatorKeyPrefix, symbol)
+	timestamp = timestamp.UTC()
 
 	// Create indicator data structure
 	indicatorData := map[string]interface{}{
 		"symbol":    symbol,
-		"timestamp": time.Now().UTC(),
+		"timestamp": timestamp,
 		"values":    indicators,
 	}
 
@@ -159,7 +169,7 @@ func (p *Publisher) PublishIndicators(symbol string, indicators map[string]float
 	// Pass the map directly - Publish will handle JSON marshaling
 	updateMsg := map[string]interface{}{
 		"symbol":    symbol,
-		"timestamp": time.Now().UTC(),
+		"timestamp": timestamp,
 	}
 	err = p.redis.Publish(ctx, p.config.UpdateChannel, updateMsg)
 	if err != nil {
@@ -176,6 +186,8 @@ func (p *Publisher) PublishIndicators(symbol string, indicators map[string]float
 		logger.Int("indicator_count", len(indicators)),
 	)
 
+	p.persistIndicators(ctx, symbol, timestamp, indicators)
+
 	// Update toplists for complex metrics (RSI, Relative Volume, VWAP Distance)
 	if p.toplistEnabled && p.toplistUpdater != nil {
 		p.updateToplists(ctx, symbol, indicators)
@@ -184,6 +196,34 @@ func (p *Publisher) PublishIndicators(symbol string, indicators map[string]float
 	return nil
 }
 
+// persistIndicators queues published values for historical storage, if configured.
+// Failures are logged only: history is best-effort and must not fail publishing.
+func (p *Publisher) persistIndicators(ctx context.Context, symbol string, timestamp time.Time, indicators map[string]float64) {
+	p.mu.RLock()
+	indicatorStorage := p.indicatorStorage
+	p.mu.RUnlock()
+	if indicatorStorage == nil {
+		return
+	}
+
+	values := make([]*models.IndicatorValue, 0, len(indicators))
+	for name, value := range indicators {
+		values = append(values, &models.IndicatorValue{
+			Symbol:    symbol,
+			Timestamp: timestamp,
+			Name:      name,
+			Value:     value,
+		})
+	}
+
+	if err := indicatorStorage.WriteIndicators(ctx, values); err != nil {
+		logger.Warn("Failed to persist indicators",
+			logger.ErrorField(err),
+			logger.String("symbol", symbol),
+		)
+	}
+}
+
 // reloadToplists reloads enabled toplists from the store
 func (p *Publisher) reloadToplists(ctx context.Context) error {
 	if p.toplistStore == nil {
@@ -254,9 +294,11 @@ func (p *Publisher) updateToplists(ctx context.Context, symbol string, indicator
 		// Get Redis key for this toplist
 		key := p.mapper.GetToplistRedisKey(config)
 		p.toplistUpdates = append(p.toplistUpdates, toplist.ToplistUpdate{
-			Key:    key,
-			Symbol: symbol,
-			Value:  value,
+			Key:       key,
+			Symbol:    symbol,
+			Value:     value,
+			MaxSize:   config.MaxSize,
+			SortOrder: config.SortOrder,
 		})
 	}
 
@@ -0,0 +1,42 @@
+package indicator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+func TestPublisher_PersistsIndicators(t *testing.T) {
+	indicatorStorage := &storage.MockIndicatorStorage{}
+	publisher := NewPublisher(storage.NewMockRedisClient(), DefaultPublisherConfig())
+	publisher.SetIndicatorStorage(indicatorStorage)
+
+	barTime := time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC)
+	if err := publisher.PublishIndicators("AAPL", barTime, map[string]float64{"rsi_14": 55.5, "ema_20": 150.2}); err != nil {
+		t.Fatalf("PublishIndicators() error = %v", err)
+	}
+
+	if len(indicatorStorage.Values) != 2 {
+		t.Fatalf("Expected 2 persisted values, got %d", len(indicatorStorage.Values))
+	}
+	for _, value := range indicatorStorage.Values {
+		if value.Symbol != "AAPL" || !value.Timestamp.Equal(barTime) {
+			t.Errorf("Unexpected persisted value %+v", value)
+		}
+		if want := map[string]float64{"rsi_14": 55.5, "ema_20": 150.2}[value.Name]; value.Value != want {
+			t.Errorf("Persisted %s = %v, want %v", value.Name, value.Value, want)
+		}
+	}
+}
+
+func TestPublisher_PersistFailureDoesNotFailPublish(t *testing.T) {
+	indicatorStorage := &storage.MockIndicatorStorage{WriteErr: errors.New("database unavailable")}
+	publisher := NewPublisher(storage.NewMockRedisClient(), DefaultPublisherConfig())
+	publisher.SetIndicatorStorage(indicatorStorage)
+
+	if err := publisher.PublishIndicators("AAPL", time.Now(), map[string]float64{"rsi_14": 55.5}); err != nil {
+		t.Errorf("Expected publish to succeed when persistence fails, got %v", err)
+	}
+}
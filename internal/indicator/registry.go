@@ -2,7 +2,10 @@ package indicator
 
 import (
 	"fmt"
+	"strings"
 	"sync"
+
+	indicatorpkg "github.com/mohamedkhairy/stock-scanner/pkg/indicator"
 )
 
 // IndicatorRegistry manages all available indicators (Techan + Custom)
@@ -10,6 +13,13 @@ type IndicatorRegistry struct {
 	mu        sync.RWMutex
 	factories map[string]CalculatorFactory
 	metadata  map[string]IndicatorMetadata
+	groups    map[string]*calculatorGroup // Indicator name -> its group (RegisterGroup)
+}
+
+// calculatorGroup is a set of indicators computed together from shared state
+type calculatorGroup struct {
+	key     string // Member names joined with ","
+	factory CalculatorGroupFactory
 }
 
 // IndicatorMetadata contains information about an indicator
@@ -26,6 +36,7 @@ func NewIndicatorRegistry() *IndicatorRegistry {
 	return &IndicatorRegistry{
 		factories: make(map[string]CalculatorFactory),
 		metadata:  make(map[string]IndicatorMetadata),
+		groups:    make(map[string]*calculatorGroup),
 	}
 }
 
@@ -47,6 +58,55 @@ func (r *IndicatorRegistry) Register(
 	return nil
 }
 
+// RegisterGroup registers indicators computed together from shared state. Each name is listed
+// and described on its own (metadata is keyed by name), and its factory creates the whole
+// group for that one calculator; the engine creates one group per symbol for all members.
+func (r *IndicatorRegistry) RegisterGroup(
+	names []string,
+	factory CalculatorGroupFactory,
+	metadata map[string]IndicatorMetadata,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, name := range names {
+		if _, exists := r.factories[name]; exists {
+			return fmt.Errorf("indicator %q already registered", name)
+		}
+	}
+
+	group := &calculatorGroup{key: strings.Join(names, ","), factory: factory}
+	for _, name := range names {
+		name := name
+		r.factories[name] = func() (indicatorpkg.Calculator, error) {
+			calcs, err := factory()
+			if err != nil {
+				return nil, err
+			}
+			calc, ok := calcs[name]
+			if !ok {
+				return nil, fmt.Errorf("calculator group %s has no %s calculator", group.key, name)
+			}
+			return calc, nil
+		}
+		r.metadata[name] = metadata[name]
+		r.groups[name] = group
+	}
+	return nil
+}
+
+// GetGroupFactory returns the group factory of an indicator registered with RegisterGroup,
+// with a key identifying the group
+func (r *IndicatorRegistry) GetGroupFactory(name string) (CalculatorGroupFactory, string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	group, exists := r.groups[name]
+	if !exists {
+		return nil, "", false
+	}
+	return group.factory, group.key, true
+}
+
 // GetFactory returns a factory for an indicator
 func (r *IndicatorRegistry) GetFactory(name string) (CalculatorFactory, bool) {
 	r.mu.RLock()
@@ -0,0 +1,216 @@
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// TickPublisher publishes ticks (implemented by pubsub.StreamPublisher)
+type TickPublisher interface {
+	Publish(tick *models.Tick) error
+	Flush() error
+}
+
+// Config holds load generator configuration
+type Config struct {
+	Symbols      int           // Number of synthetic symbols
+	Rate         int           // Target ticks per second after ramp-up
+	RampUp       time.Duration // Time to ramp linearly from 0 to Rate (0 = start at full rate)
+	Duration     time.Duration // Total run time including ramp-up (0 = until context is cancelled)
+	TickInterval time.Duration // Pacing granularity
+	ReportEvery  time.Duration // Interval between progress reports (0 = disabled)
+}
+
+// DefaultConfig returns default load generator configuration
+func DefaultConfig() Config {
+	return Config{
+		Symbols:      100,
+		Rate:         1000,
+		RampUp:       10 * time.Second,
+		Duration:     time.Minute,
+		TickInterval: 10 * time.Millisecond,
+		ReportEvery:  5 * time.Second,
+	}
+}
+
+// Stats holds load generator statistics
+type Stats struct {
+	TicksPublished int64         `json:"ticks_published"`
+	PublishErrors  int64         `json:"publish_errors"`
+	Elapsed        time.Duration `json:"elapsed"`
+	Throughput     float64       `json:"throughput"` // Ticks per second over the run
+}
+
+// Generator publishes synthetic ticks at a controlled rate
+type Generator struct {
+	config    Config
+	publisher TickPublisher
+	symbols   []string
+	prices    []float64
+	rng       *rand.Rand
+	next      int // Next symbol index (round-robin)
+
+	published atomic.Int64
+	errors    atomic.Int64
+}
+
+// NewGenerator creates a new load generator
+func NewGenerator(config Config, publisher TickPublisher) (*Generator, error) {
+	if config.Symbols <= 0 {
+		return nil, fmt.Errorf("symbols must be > 0, got %d", config.Symbols)
+	}
+	if config.Rate <= 0 {
+		return nil, fmt.Errorf("rate must be > 0, got %d", config.Rate)
+	}
+	if config.TickInterval <= 0 {
+		config.TickInterval = 10 * time.Millisecond
+	}
+
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	symbols := make([]string, config.Symbols)
+	prices := make([]float64, config.Symbols)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("LOAD%04d", i)
+		prices[i] = 10.0 + rng.Float64()*490.0 // Random price between 10-500
+	}
+
+	return &Generator{
+		config:    config,
+		publisher: publisher,
+		symbols:   symbols,
+		prices:    prices,
+		rng:       rng,
+	}, nil
+}
+
+// Run publishes ticks until the configured duration elapses or the context is cancelled
+func (g *Generator) Run(ctx context.Context) Stats {
+	if g.config.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.config.Duration)
+		defer cancel()
+	}
+
+	logger.Info("Starting load generator",
+		logger.Int("symbols", g.config.Symbols),
+		logger.Int("rate", g.config.Rate),
+		logger.Duration("ramp_up", g.config.RampUp),
+		logger.Duration("duration", g.config.Duration),
+	)
+
+	ticker := time.NewTicker(g.config.TickInterval)
+	defer ticker.Stop()
+
+	var reportC <-chan time.Time
+	if g.config.ReportEvery > 0 {
+		reportTicker := time.NewTicker(g.config.ReportEvery)
+		defer reportTicker.Stop()
+		reportC = reportTicker.C
+	}
+
+	start := time.Now()
+	var sent int64
+	for {
+		select {
+		case <-ctx.Done():
+			if err := g.publisher.Flush(); err != nil {
+				g.errors.Add(1)
+			}
+			stats := g.stats(time.Since(start))
+			logger.Info("Load generator finished",
+				logger.Int64("ticks_published", stats.TicksPublished),
+				logger.Int64("publish_errors", stats.PublishErrors),
+				logger.Duration("elapsed", stats.Elapsed),
+				logger.Float64("throughput", stats.Throughput),
+			)
+			return stats
+
+		case <-reportC:
+			stats := g.stats(time.Since(start))
+			logger.Info("Load generator progress",
+				logger.Int64("ticks_published", stats.TicksPublished),
+				logger.Float64("target_rate", g.targetRate(stats.Elapsed)),
+				logger.Float64("throughput", stats.Throughput),
+			)
+
+		case <-ticker.C:
+			// Publish enough ticks to catch up with the expected total
+			due := g.expectedTicks(time.Since(start))
+			for ; sent < due; sent++ {
+				g.publishTick()
+			}
+		}
+	}
+}
+
+// targetRate returns the target tick rate at a point in the run
+func (g *Generator) targetRate(elapsed time.Duration) float64 {
+	rate := float64(g.config.Rate)
+	if g.config.RampUp <= 0 || elapsed >= g.config.RampUp {
+		return rate
+	}
+	return rate * elapsed.Seconds() / g.config.RampUp.Seconds()
+}
+
+// expectedTicks returns the number of ticks that should have been sent after elapsed,
+// i.e. the integral of the (linearly ramped) target rate
+func (g *Generator) expectedTicks(elapsed time.Duration) int64 {
+	rate := float64(g.config.Rate)
+	t := elapsed.Seconds()
+	ramp := g.config.RampUp.Seconds()
+
+	if ramp <= 0 {
+		return int64(rate * t)
+	}
+	if t < ramp {
+		return int64(rate * t * t / (2 * ramp))
+	}
+	return int64(rate*ramp/2 + rate*(t-ramp))
+}
+
+// publishTick publishes a tick for the next symbol with a small random walk
+func (g *Generator) publishTick() {
+	i := g.next
+	g.next = (g.next + 1) % len(g.symbols)
+
+	price := g.prices[i] * (1 + (g.rng.Float64()-0.5)*0.002) // +/-0.1%
+	if price < 1.0 {
+		price = 1.0
+	}
+	g.prices[i] = price
+
+	tick := &models.Tick{
+		Symbol:    g.symbols[i],
+		Price:     price,
+		Size:      int64(g.rng.Intn(1000) + 1),
+		Timestamp: time.Now().UTC(),
+		Type:      "trade",
+	}
+
+	if err := g.publisher.Publish(tick); err != nil {
+		g.errors.Add(1)
+		return
+	}
+	g.published.Add(1)
+}
+
+// stats builds statistics for the given elapsed time
+func (g *Generator) stats(elapsed time.Duration) Stats {
+	published := g.published.Load()
+	throughput := 0.0
+	if elapsed > 0 {
+		throughput = float64(published) / elapsed.Seconds()
+	}
+	return Stats{
+		TicksPublished: published,
+		PublishErrors:  g.errors.Load(),
+		Elapsed:        elapsed,
+		Throughput:     throughput,
+	}
+}
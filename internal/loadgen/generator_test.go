@@ -0,0 +1,104 @@
+package loadgen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/pubsub"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+func TestNewGenerator_InvalidConfig(t *testing.T) {
+	if _, err := NewGenerator(Config{Symbols: 0, Rate: 100}, nil); err == nil {
+		t.Error("Expected error for zero symbols")
+	}
+	if _, err := NewGenerator(Config{Symbols: 10, Rate: 0}, nil); err == nil {
+		t.Error("Expected error for zero rate")
+	}
+}
+
+func TestGenerator_ExpectedTicks(t *testing.T) {
+	g := &Generator{config: Config{Rate: 1000, RampUp: 2 * time.Second}}
+
+	tests := []struct {
+		elapsed time.Duration
+		want    int64
+	}{
+		{0, 0},
+		{time.Second, 250},      // Half way through ramp: 1000 * 1^2 / (2*2)
+		{2 * time.Second, 1000}, // End of ramp: average rate 500 for 2s
+		{3 * time.Second, 2000}, // Full rate after ramp
+	}
+
+	for _, tt := range tests {
+		if got := g.expectedTicks(tt.elapsed); got != tt.want {
+			t.Errorf("expectedTicks(%s) = %d, want %d", tt.elapsed, got, tt.want)
+		}
+	}
+
+	noRamp := &Generator{config: Config{Rate: 1000}}
+	if got := noRamp.expectedTicks(1500 * time.Millisecond); got != 1500 {
+		t.Errorf("expectedTicks without ramp = %d, want 1500", got)
+	}
+}
+
+func TestGenerator_PublishesAtRequestedRate(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	publisher := pubsub.NewStreamPublisher(redis, pubsub.DefaultStreamPublisherConfig("ticks"))
+	publisher.Start()
+	defer publisher.Close()
+
+	generator, err := NewGenerator(Config{
+		Symbols:      20,
+		Rate:         500,
+		Duration:     time.Second,
+		TickInterval: 10 * time.Millisecond,
+	}, publisher)
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+
+	stats := generator.Run(context.Background())
+
+	if stats.PublishErrors != 0 {
+		t.Errorf("Expected no publish errors, got %d", stats.PublishErrors)
+	}
+	if stats.TicksPublished < 400 || stats.TicksPublished > 600 {
+		t.Errorf("Expected ~500 ticks published, got %d", stats.TicksPublished)
+	}
+	if stats.Throughput < 400 || stats.Throughput > 600 {
+		t.Errorf("Expected throughput ~500 ticks/s, got %.1f", stats.Throughput)
+	}
+
+	// Every published tick reached the stream
+	streamed := len(redis.StreamData)
+	if int64(streamed) != stats.TicksPublished {
+		t.Errorf("Expected %d ticks in stream, got %d", stats.TicksPublished, streamed)
+	}
+}
+
+func TestGenerator_RampUp(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	publisher := pubsub.NewStreamPublisher(redis, pubsub.DefaultStreamPublisherConfig("ticks"))
+	publisher.Start()
+	defer publisher.Close()
+
+	// Ramp over the whole run: expected total is half of rate*duration
+	generator, err := NewGenerator(Config{
+		Symbols:      5,
+		Rate:         1000,
+		RampUp:       time.Second,
+		Duration:     time.Second,
+		TickInterval: 10 * time.Millisecond,
+	}, publisher)
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+
+	stats := generator.Run(context.Background())
+
+	if stats.TicksPublished < 400 || stats.TicksPublished > 600 {
+		t.Errorf("Expected ~500 ticks published during ramp-up, got %d", stats.TicksPublished)
+	}
+}
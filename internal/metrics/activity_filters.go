@@ -45,6 +45,76 @@ func (c *TradeCountComputer) Compute(snapshot *SymbolStateSnapshot) (float64, bo
 	return 0, false
 }
 
+// TradesPerMinuteAvgComputer computes the average trades per minute over the last N bars (tape speed)
+// Metric name format: trades_per_min_{timeframe}_avg (e.g., trades_per_min_5m_avg)
+type TradesPerMinuteAvgComputer struct {
+	name      string
+	barOffset int // Number of bars to average over
+}
+
+// NewTradesPerMinuteAvgComputer creates a new trades per minute average computer
+func NewTradesPerMinuteAvgComputer(name string, barOffset int) *TradesPerMinuteAvgComputer {
+	return &TradesPerMinuteAvgComputer{
+		name:      name,
+		barOffset: barOffset,
+	}
+}
+
+func (c *TradesPerMinuteAvgComputer) Name() string { return c.name }
+
+func (c *TradesPerMinuteAvgComputer) Dependencies() []string { return nil }
+
+func (c *TradesPerMinuteAvgComputer) Compute(snapshot *SymbolStateSnapshot) (float64, bool) {
+	if c.barOffset <= 0 || len(snapshot.TradeCountHistory) < c.barOffset {
+		return 0, false
+	}
+
+	var total int64
+	for _, count := range snapshot.TradeCountHistory[len(snapshot.TradeCountHistory)-c.barOffset:] {
+		total += count
+	}
+	return float64(total) / float64(c.barOffset), true
+}
+
+// TapeAccelerationComputer computes the ratio of the last minute's trade count to the average
+// trades per minute over the preceding N bars (> 1 means the tape is speeding up)
+// Metric name format: tape_acceleration
+type TapeAccelerationComputer struct {
+	name         string
+	baselineBars int // Number of bars before the last one used as the baseline
+}
+
+// NewTapeAccelerationComputer creates a new tape acceleration computer
+func NewTapeAccelerationComputer(name string, baselineBars int) *TapeAccelerationComputer {
+	return &TapeAccelerationComputer{
+		name:         name,
+		baselineBars: baselineBars,
+	}
+}
+
+func (c *TapeAccelerationComputer) Name() string { return c.name }
+
+func (c *TapeAccelerationComputer) Dependencies() []string { return nil }
+
+func (c *TapeAccelerationComputer) Compute(snapshot *SymbolStateSnapshot) (float64, bool) {
+	history := snapshot.TradeCountHistory
+	if c.baselineBars <= 0 || len(history) < c.baselineBars+1 {
+		return 0, false
+	}
+
+	last := history[len(history)-1]
+	var baselineTotal int64
+	for _, count := range history[len(history)-1-c.baselineBars : len(history)-1] {
+		baselineTotal += count
+	}
+	if baselineTotal == 0 {
+		return 0, false // No baseline activity, acceleration is undefined
+	}
+
+	baselineAvg := float64(baselineTotal) / float64(c.baselineBars)
+	return float64(last) / baselineAvg, true
+}
+
 // ConsecutiveCandlesComputer computes consecutive candles of the same direction
 // Metric name format: consecutive_candles_{timeframe} (e.g., consecutive_candles_1m, consecutive_candles_5m)
 // Returns positive number for consecutive green candles, negative for consecutive red candles
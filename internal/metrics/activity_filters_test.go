@@ -68,6 +68,114 @@ func TestTradeCountComputer(t *testing.T) {
 	}
 }
 
+func TestTradesPerMinuteAvgComputer(t *testing.T) {
+	tests := []struct {
+		name              string
+		tradeCountHistory []int64
+		wantValue         float64
+		wantOk            bool
+	}{
+		{
+			name:              "no history",
+			tradeCountHistory: nil,
+			wantOk:            false,
+		},
+		{
+			name:              "insufficient history",
+			tradeCountHistory: []int64{10, 20, 30, 40},
+			wantOk:            false,
+		},
+		{
+			name:              "exactly 5 minutes",
+			tradeCountHistory: []int64{10, 20, 30, 40, 50},
+			wantValue:         30.0,
+			wantOk:            true,
+		},
+		{
+			name:              "uses only the last 5 minutes",
+			tradeCountHistory: []int64{1000, 1000, 5, 5, 10, 10, 20},
+			wantValue:         10.0, // (5+5+10+10+20)/5
+			wantOk:            true,
+		},
+	}
+
+	computer := NewTradesPerMinuteAvgComputer("trades_per_min_5m_avg", 5)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok := computer.Compute(&SymbolStateSnapshot{TradeCountHistory: tt.tradeCountHistory})
+			if ok != tt.wantOk {
+				t.Errorf("Compute() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if ok && value != tt.wantValue {
+				t.Errorf("Compute() value = %v, want %v", value, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestTapeAccelerationComputer(t *testing.T) {
+	tests := []struct {
+		name              string
+		tradeCountHistory []int64
+		wantValue         float64
+		wantOk            bool
+	}{
+		{
+			name:              "insufficient history",
+			tradeCountHistory: []int64{10, 10, 10, 10, 10},
+			wantOk:            false,
+		},
+		{
+			name:              "accelerating tape",
+			tradeCountHistory: []int64{10, 10, 10, 10, 10, 30},
+			wantValue:         3.0,
+			wantOk:            true,
+		},
+		{
+			name:              "slowing tape",
+			tradeCountHistory: []int64{500, 20, 20, 20, 20, 20, 5},
+			wantValue:         0.25, // 5 / avg(20,20,20,20,20)
+			wantOk:            true,
+		},
+		{
+			name:              "steady tape",
+			tradeCountHistory: []int64{12, 8, 10, 11, 9, 10},
+			wantValue:         1.0,
+			wantOk:            true,
+		},
+		{
+			name:              "no baseline activity",
+			tradeCountHistory: []int64{0, 0, 0, 0, 0, 15},
+			wantOk:            false,
+		},
+	}
+
+	computer := NewTapeAccelerationComputer("tape_acceleration", 5)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok := computer.Compute(&SymbolStateSnapshot{TradeCountHistory: tt.tradeCountHistory})
+			if ok != tt.wantOk {
+				t.Errorf("Compute() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if ok && value != tt.wantValue {
+				t.Errorf("Compute() value = %v, want %v", value, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestTapeSpeedMetricsRegistered(t *testing.T) {
+	registered := make(map[string]bool)
+	for _, name := range NewRegistry().Names() {
+		registered[name] = true
+	}
+	for _, name := range []string{"trade_count_1m", "trades_per_min_5m_avg", "tape_acceleration"} {
+		if !registered[name] {
+			t.Errorf("Expected %s to be registered", name)
+		}
+	}
+}
+
 func TestConsecutiveCandlesComputer(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"math"
 	"time"
 
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
@@ -30,12 +31,20 @@ type SymbolStateSnapshot struct {
 	MarketVolume    int64
 	PostmarketVolume int64
 
+	// Session-anchored VWAP accumulators over the current session's finalized bars
+	SessionVWAPNum    float64 // Sum of bar VWAP * volume
+	SessionVWAPVolume int64   // Sum of bar volume
+
 	// Trade count tracking
 	TradeCount       int64
 	TradeCountHistory []int64
 
 	// Candle direction tracking
 	CandleDirections map[string][]bool // timeframe -> []bool
+
+	// Provider-supplied LULD bands (0 = not available)
+	LULDUpper float64
+	LULDLower float64
 }
 
 // MetricComputer computes a metric value from symbol state
@@ -52,3 +61,29 @@ type MetricComputer interface {
 	Dependencies() []string
 }
 
+// AliasComputer exposes an existing computer under another metric name, so the same value
+// can be addressed by either name without duplicating its computation
+type AliasComputer struct {
+	name     string
+	computer MetricComputer
+}
+
+// NewAliasComputer creates a computer named name that delegates to computer
+func NewAliasComputer(name string, computer MetricComputer) *AliasComputer {
+	return &AliasComputer{name: name, computer: computer}
+}
+
+func (c *AliasComputer) Name() string { return c.name }
+
+func (c *AliasComputer) Dependencies() []string { return c.computer.Dependencies() }
+
+func (c *AliasComputer) Compute(snapshot *SymbolStateSnapshot) (float64, bool) {
+	return c.computer.Compute(snapshot)
+}
+
+// insufficientHistory is returned by change metrics whose lookback reaches past the symbol's
+// finalized bars (e.g. a symbol that just started trading). The NaN marks the metric as not
+// computable: the registry omits it rather than reporting 0, so a flat price isn't implied.
+func insufficientHistory() (float64, bool) {
+	return math.NaN(), true
+}
@@ -1,5 +1,10 @@
 package metrics
 
+import (
+	"strconv"
+	"strings"
+)
+
 // Indicator filter computers implement distance calculations from technical indicators
 
 // ATRPComputer computes ATR Percentage (ATR / Close * 100)
@@ -182,3 +187,40 @@ func (c *MADistanceComputer) Compute(snapshot *SymbolStateSnapshot) (float64, bo
 	return distancePct, true
 }
 
+// Moving average distance metrics are named dist_to_{ma}_pct after the MA indicator,
+// e.g. dist_to_ema_20_pct or dist_to_sma_50_pct
+const (
+	maDistancePrefix = "dist_to_"
+	maDistanceSuffix = "_pct"
+)
+
+// movingAverageTypes are the indicator prefixes treated as moving averages
+var movingAverageTypes = []string{"ema_", "sma_"}
+
+// MADistanceMetricName returns the distance metric name for a moving average indicator
+func MADistanceMetricName(maKey string) string {
+	return maDistancePrefix + maKey + maDistanceSuffix
+}
+
+// IsMovingAverageIndicator returns true for moving average indicator keys (ema_N, sma_N)
+func IsMovingAverageIndicator(key string) bool {
+	for _, prefix := range movingAverageTypes {
+		if period, ok := strings.CutPrefix(key, prefix); ok {
+			n, err := strconv.Atoi(period)
+			return err == nil && n > 0
+		}
+	}
+	return false
+}
+
+// ParseMADistanceMetric returns the moving average indicator a dist_to_{ma}_pct metric refers to
+func ParseMADistanceMetric(name string) (string, bool) {
+	if !strings.HasPrefix(name, maDistancePrefix) || !strings.HasSuffix(name, maDistanceSuffix) {
+		return "", false
+	}
+	maKey := strings.TrimSuffix(strings.TrimPrefix(name, maDistancePrefix), maDistanceSuffix)
+	if !IsMovingAverageIndicator(maKey) {
+		return "", false
+	}
+	return maKey, true
+}
@@ -292,3 +292,66 @@ func createBarsForIndicator(count int, basePrice float64) []*models.Bar1m {
 	return bars
 }
 
+
+func TestMADistanceMetrics(t *testing.T) {
+	registry := NewRegistry()
+
+	tests := []struct {
+		name       string
+		metric     string
+		price      float64
+		indicators map[string]float64
+		wantValue  float64
+		wantOk     bool
+	}{
+		{"above ema_20", "dist_to_ema_20_pct", 102.0, map[string]float64{"ema_20": 100.0}, 2.0, true},
+		{"below sma_50", "dist_to_sma_50_pct", 95.0, map[string]float64{"sma_50": 100.0}, -5.0, true},
+		{"at ema_9", "dist_to_ema_9_pct", 100.0, map[string]float64{"ema_9": 100.0}, 0, true},
+		{"unregistered period", "dist_to_ema_5_pct", 110.0, map[string]float64{"ema_5": 100.0}, 10.0, true},
+		{"missing MA", "dist_to_ema_20_pct", 100.0, map[string]float64{}, 0, false},
+		{"not a moving average", "dist_to_rsi_14_pct", 100.0, map[string]float64{"rsi_14": 50.0}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := &SymbolStateSnapshot{
+				LiveBar:    &models.LiveBar{Close: tt.price},
+				Indicators: tt.indicators,
+			}
+
+			for source, metrics := range map[string]map[string]float64{
+				"ComputeMetrics": registry.ComputeMetrics(snapshot, map[string]bool{tt.metric: true}),
+				"ComputeAll":     registry.ComputeAll(snapshot),
+			} {
+				value, ok := metrics[tt.metric]
+				if ok != tt.wantOk {
+					t.Errorf("%s: %s ok = %v, want %v", source, tt.metric, ok, tt.wantOk)
+				}
+				if ok && value != tt.wantValue {
+					t.Errorf("%s: %s = %v, want %v", source, tt.metric, value, tt.wantValue)
+				}
+			}
+		})
+	}
+}
+
+func TestParseMADistanceMetric(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantKey string
+		wantOk  bool
+	}{
+		{"dist_to_ema_20_pct", "ema_20", true},
+		{"dist_to_sma_200_pct", "sma_200", true},
+		{"dist_to_ema_pct", "", false},
+		{"dist_to_vwap_5m_pct", "", false},
+		{"ma_dist_ema9_5m_pct", "", false},
+	}
+
+	for _, tt := range tests {
+		key, ok := ParseMADistanceMetric(tt.name)
+		if ok != tt.wantOk || key != tt.wantKey {
+			t.Errorf("ParseMADistanceMetric(%q) = (%q, %v), want (%q, %v)", tt.name, key, ok, tt.wantKey, tt.wantOk)
+		}
+	}
+}
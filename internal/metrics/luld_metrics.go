@@ -0,0 +1,214 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LULDReferenceBars is the number of finalized 1m bars averaged for the LULD reference price
+// (the exchange reference price is the mean price over the preceding five minutes)
+const LULDReferenceBars = 5
+
+// LULDTier defines the limit-up/limit-down band for reference prices at or above MinPrice
+type LULDTier struct {
+	MinPrice      float64 // Tier applies to reference prices >= MinPrice
+	BandPct       float64 // Band percentage around the reference price
+	MaxBandAmount float64 // Optional cap on the band in dollars (0 = no cap)
+}
+
+// DefaultLULDTiers returns the Tier 2 LULD bands (most small-caps):
+// 10% above $3.00, 20% between $0.75 and $3.00, and the lesser of $0.15 or 75% below $0.75
+func DefaultLULDTiers() []LULDTier {
+	return []LULDTier{
+		{MinPrice: 3.00, BandPct: 10},
+		{MinPrice: 0.75, BandPct: 20},
+		{MinPrice: 0, BandPct: 75, MaxBandAmount: 0.15},
+	}
+}
+
+// ParseLULDTiers parses tiers from a comma-separated list of "min_price:band_pct[:max_band]"
+// entries, e.g. "3:10,0.75:20,0:75:0.15"
+func ParseLULDTiers(spec string) ([]LULDTier, error) {
+	var tiers []LULDTier
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid LULD tier %q (expected min_price:band_pct[:max_band])", entry)
+		}
+
+		values := make([]float64, len(parts))
+		for i, part := range parts {
+			value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil || value < 0 {
+				return nil, fmt.Errorf("invalid LULD tier %q: %q is not a non-negative number", entry, part)
+			}
+			values[i] = value
+		}
+
+		tier := LULDTier{MinPrice: values[0], BandPct: values[1]}
+		if len(values) == 3 {
+			tier.MaxBandAmount = values[2]
+		}
+		if tier.BandPct <= 0 {
+			return nil, fmt.Errorf("invalid LULD tier %q: band percentage must be > 0", entry)
+		}
+		tiers = append(tiers, tier)
+	}
+
+	if len(tiers) == 0 {
+		return nil, fmt.Errorf("no LULD tiers configured")
+	}
+
+	sortLULDTiers(tiers)
+	if tiers[len(tiers)-1].MinPrice != 0 {
+		return nil, fmt.Errorf("LULD tiers must include a tier with min_price 0")
+	}
+
+	return tiers, nil
+}
+
+// sortLULDTiers sorts tiers by descending minimum price
+func sortLULDTiers(tiers []LULDTier) {
+	sort.Slice(tiers, func(i, j int) bool {
+		return tiers[i].MinPrice > tiers[j].MinPrice
+	})
+}
+
+// luldReferencePrice returns the average close of the most recent finalized bars,
+// falling back to the live bar close when no bars are finalized yet
+func luldReferencePrice(snapshot *SymbolStateSnapshot) (float64, bool) {
+	bars := snapshot.LastFinalBars
+	if len(bars) > LULDReferenceBars {
+		bars = bars[len(bars)-LULDReferenceBars:]
+	}
+
+	if len(bars) > 0 {
+		sum := 0.0
+		for _, bar := range bars {
+			sum += bar.Close
+		}
+		return sum / float64(len(bars)), true
+	}
+
+	if snapshot.LiveBar != nil && snapshot.LiveBar.Close > 0 {
+		return snapshot.LiveBar.Close, true
+	}
+
+	return 0, false
+}
+
+// LULDBands returns the upper and lower LULD bands for a snapshot.
+// Provider-supplied bands take precedence; otherwise bands are computed from the reference price.
+func LULDBands(snapshot *SymbolStateSnapshot, tiers []LULDTier) (float64, float64, bool) {
+	if snapshot.LULDUpper > 0 && snapshot.LULDLower > 0 {
+		return snapshot.LULDUpper, snapshot.LULDLower, true
+	}
+
+	reference, ok := luldReferencePrice(snapshot)
+	if !ok || reference <= 0 {
+		return 0, 0, false
+	}
+
+	for _, tier := range tiers {
+		if reference < tier.MinPrice {
+			continue
+		}
+
+		band := reference * tier.BandPct / 100.0
+		if tier.MaxBandAmount > 0 {
+			band = math.Min(band, tier.MaxBandAmount)
+		}
+		return reference + band, math.Max(reference-band, 0), true
+	}
+
+	return 0, 0, false
+}
+
+// LULDUpperComputer computes the limit-up band price
+type LULDUpperComputer struct {
+	tiers []LULDTier
+}
+
+// NewLULDUpperComputer creates a new LULD upper band computer
+func NewLULDUpperComputer(tiers []LULDTier) *LULDUpperComputer {
+	return &LULDUpperComputer{tiers: tiers}
+}
+
+func (c *LULDUpperComputer) Name() string { return "luld_upper" }
+
+func (c *LULDUpperComputer) Dependencies() []string { return nil }
+
+func (c *LULDUpperComputer) Compute(snapshot *SymbolStateSnapshot) (float64, bool) {
+	upper, _, ok := LULDBands(snapshot, c.tiers)
+	return upper, ok
+}
+
+// LULDLowerComputer computes the limit-down band price
+type LULDLowerComputer struct {
+	tiers []LULDTier
+}
+
+// NewLULDLowerComputer creates a new LULD lower band computer
+func NewLULDLowerComputer(tiers []LULDTier) *LULDLowerComputer {
+	return &LULDLowerComputer{tiers: tiers}
+}
+
+func (c *LULDLowerComputer) Name() string { return "luld_lower" }
+
+func (c *LULDLowerComputer) Dependencies() []string { return nil }
+
+func (c *LULDLowerComputer) Compute(snapshot *SymbolStateSnapshot) (float64, bool) {
+	_, lower, ok := LULDBands(snapshot, c.tiers)
+	return lower, ok
+}
+
+// NearLULDPctComputer computes the distance from the current price to the nearest LULD band
+// as a percentage of the current price (0 = at a band, negative = outside the bands)
+type NearLULDPctComputer struct {
+	tiers []LULDTier
+}
+
+// NewNearLULDPctComputer creates a new LULD proximity computer
+func NewNearLULDPctComputer(tiers []LULDTier) *NearLULDPctComputer {
+	return &NearLULDPctComputer{tiers: tiers}
+}
+
+func (c *NearLULDPctComputer) Name() string { return "near_luld_pct" }
+
+func (c *NearLULDPctComputer) Dependencies() []string { return nil }
+
+func (c *NearLULDPctComputer) Compute(snapshot *SymbolStateSnapshot) (float64, bool) {
+	if snapshot.LiveBar == nil || snapshot.LiveBar.Close <= 0 {
+		return 0, false
+	}
+
+	upper, lower, ok := LULDBands(snapshot, c.tiers)
+	if !ok {
+		return 0, false
+	}
+
+	price := snapshot.LiveBar.Close
+	distance := math.Min(upper-price, price-lower)
+	return (distance / price) * 100.0, true
+}
+
+// NewLULDComputers returns the LULD metric computers for the given tiers
+func NewLULDComputers(tiers []LULDTier) []MetricComputer {
+	sorted := make([]LULDTier, len(tiers))
+	copy(sorted, tiers)
+	sortLULDTiers(sorted)
+
+	return []MetricComputer{
+		NewLULDUpperComputer(sorted),
+		NewLULDLowerComputer(sorted),
+		NewNearLULDPctComputer(sorted),
+	}
+}
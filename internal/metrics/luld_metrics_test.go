@@ -0,0 +1,203 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+func luldSnapshot(price float64, closes ...float64) *SymbolStateSnapshot {
+	bars := make([]*models.Bar1m, len(closes))
+	for i, c := range closes {
+		bars[i] = &models.Bar1m{Close: c}
+	}
+	return &SymbolStateSnapshot{
+		Symbol:        "TEST",
+		LiveBar:       &models.LiveBar{Close: price},
+		LastFinalBars: bars,
+	}
+}
+
+func TestLULDBands_Tiers(t *testing.T) {
+	tiers := DefaultLULDTiers()
+
+	tests := []struct {
+		name      string
+		snapshot  *SymbolStateSnapshot
+		wantUpper float64
+		wantLower float64
+	}{
+		{
+			name:      "Above $3.00 uses 10% band",
+			snapshot:  luldSnapshot(100, 100, 100, 100, 100, 100),
+			wantUpper: 110,
+			wantLower: 90,
+		},
+		{
+			name:      "Between $0.75 and $3.00 uses 20% band",
+			snapshot:  luldSnapshot(2, 2, 2, 2, 2, 2),
+			wantUpper: 2.4,
+			wantLower: 1.6,
+		},
+		{
+			name:      "Below $0.75 is capped at $0.15",
+			snapshot:  luldSnapshot(0.5, 0.5, 0.5, 0.5, 0.5, 0.5),
+			wantUpper: 0.65,
+			wantLower: 0.35,
+		},
+		{
+			name:      "Below $0.75 uses 75% when smaller than cap",
+			snapshot:  luldSnapshot(0.1, 0.1),
+			wantUpper: 0.175,
+			wantLower: 0.025,
+		},
+		{
+			name: "Reference averages the last five bars only",
+			// Oldest bar (1000) is ignored; average of the last five is 20
+			snapshot:  luldSnapshot(20, 1000, 10, 15, 20, 25, 30),
+			wantUpper: 22,
+			wantLower: 18,
+		},
+		{
+			name:      "Falls back to live price without finalized bars",
+			snapshot:  luldSnapshot(50),
+			wantUpper: 55,
+			wantLower: 45,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upper, lower, ok := LULDBands(tt.snapshot, tiers)
+			if !ok {
+				t.Fatal("Expected bands to be computed")
+			}
+			if math.Abs(upper-tt.wantUpper) > 1e-9 {
+				t.Errorf("Expected upper %.4f, got %.4f", tt.wantUpper, upper)
+			}
+			if math.Abs(lower-tt.wantLower) > 1e-9 {
+				t.Errorf("Expected lower %.4f, got %.4f", tt.wantLower, lower)
+			}
+		})
+	}
+}
+
+func TestLULDBands_ProviderSupplied(t *testing.T) {
+	snapshot := luldSnapshot(100, 100, 100, 100, 100, 100)
+	snapshot.LULDUpper = 104.5
+	snapshot.LULDLower = 95.5
+
+	upper, lower, ok := LULDBands(snapshot, DefaultLULDTiers())
+	if !ok {
+		t.Fatal("Expected bands to be available")
+	}
+	if upper != 104.5 || lower != 95.5 {
+		t.Errorf("Expected provider bands 104.5/95.5, got %.2f/%.2f", upper, lower)
+	}
+}
+
+func TestLULDBands_NoPrice(t *testing.T) {
+	if _, _, ok := LULDBands(&SymbolStateSnapshot{Symbol: "TEST"}, DefaultLULDTiers()); ok {
+		t.Error("Expected no bands without a reference price")
+	}
+}
+
+func TestNearLULDPctComputer(t *testing.T) {
+	computer := NewNearLULDPctComputer(DefaultLULDTiers())
+
+	tests := []struct {
+		name     string
+		snapshot *SymbolStateSnapshot
+		expected float64
+	}{
+		{
+			name:     "At reference price",
+			snapshot: luldSnapshot(100, 100, 100, 100, 100, 100),
+			expected: 10.0, // 10 away from both bands
+		},
+		{
+			name:     "Near upper band",
+			snapshot: luldSnapshot(108, 100, 100, 100, 100, 100),
+			expected: 2.0 / 108.0 * 100.0,
+		},
+		{
+			name:     "Near lower band",
+			snapshot: luldSnapshot(91, 100, 100, 100, 100, 100),
+			expected: 1.0 / 91.0 * 100.0,
+		},
+		{
+			name:     "Outside bands is negative",
+			snapshot: luldSnapshot(112, 100, 100, 100, 100, 100),
+			expected: -2.0 / 112.0 * 100.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok := computer.Compute(tt.snapshot)
+			if !ok {
+				t.Fatal("Expected value to be computed")
+			}
+			if math.Abs(value-tt.expected) > 1e-9 {
+				t.Errorf("Expected %.4f, got %.4f", tt.expected, value)
+			}
+		})
+	}
+
+	if _, ok := computer.Compute(&SymbolStateSnapshot{Symbol: "TEST"}); ok {
+		t.Error("Expected no value without a live bar")
+	}
+}
+
+func TestParseLULDTiers(t *testing.T) {
+	tiers, err := ParseLULDTiers("0:75:0.15, 3:5, 0.75:20")
+	if err != nil {
+		t.Fatalf("ParseLULDTiers() error = %v", err)
+	}
+	if len(tiers) != 3 {
+		t.Fatalf("Expected 3 tiers, got %d", len(tiers))
+	}
+	// Sorted by descending min price
+	if tiers[0].MinPrice != 3 || tiers[0].BandPct != 5 {
+		t.Errorf("Unexpected first tier: %+v", tiers[0])
+	}
+	if tiers[2].MinPrice != 0 || tiers[2].MaxBandAmount != 0.15 {
+		t.Errorf("Unexpected last tier: %+v", tiers[2])
+	}
+
+	upper, lower, ok := LULDBands(luldSnapshot(100, 100), tiers)
+	if !ok || upper != 105 || lower != 95 {
+		t.Errorf("Expected custom 5%% bands 105/95, got %.2f/%.2f", upper, lower)
+	}
+
+	invalid := []string{"", "3", "3:abc", "3:10", "0:0", "0:-5", "1:2:3:4"}
+	for _, spec := range invalid {
+		if _, err := ParseLULDTiers(spec); err == nil {
+			t.Errorf("Expected error for spec %q", spec)
+		}
+	}
+}
+
+func TestRegistry_LULDMetricsRegistered(t *testing.T) {
+	registry := NewRegistry()
+	defaults := registry.ComputeAll(luldSnapshot(100, 100))
+	for _, name := range []string{"luld_upper", "luld_lower", "near_luld_pct"} {
+		if _, ok := defaults[name]; !ok {
+			t.Errorf("Expected %s to be registered", name)
+		}
+	}
+
+	// Replace swaps in computers with custom tiers
+	custom := []LULDTier{{MinPrice: 0, BandPct: 5}}
+	for _, computer := range NewLULDComputers(custom) {
+		if err := registry.Replace(computer); err != nil {
+			t.Fatalf("Replace() error = %v", err)
+		}
+	}
+
+	values := registry.ComputeAll(luldSnapshot(100, 100))
+	if values["luld_upper"] != 105 || values["luld_lower"] != 95 {
+		t.Errorf("Expected replaced bands 105/95, got %.2f/%.2f", values["luld_upper"], values["luld_lower"])
+	}
+}
@@ -2,6 +2,7 @@ package metrics
 
 // PriceChangeComputer computes price change percentage over N minutes
 // barOffset is the number of bars to look back (e.g., 2 for 1m, 6 for 5m, 16 for 15m)
+// The value is NaN until the symbol has barOffset finalized bars
 type PriceChangeComputer struct {
 	name      string
 	barOffset int
@@ -25,14 +26,14 @@ func (c *PriceChangeComputer) Dependencies() []string {
 
 func (c *PriceChangeComputer) Compute(snapshot *SymbolStateSnapshot) (float64, bool) {
 	if len(snapshot.LastFinalBars) < c.barOffset {
-		return 0, false
+		return insufficientHistory()
 	}
 
 	currentBar := snapshot.LastFinalBars[len(snapshot.LastFinalBars)-1]
 	pastBar := snapshot.LastFinalBars[len(snapshot.LastFinalBars)-c.barOffset]
 
 	if pastBar.Close <= 0 {
-		return 0, false
+		return insufficientHistory()
 	}
 
 	changePct := ((currentBar.Close - pastBar.Close) / pastBar.Close) * 100.0
@@ -4,6 +4,7 @@ package metrics
 
 // ChangeComputer computes absolute price change over N minutes
 // Metric name format: change_{timeframe} (e.g., change_1m, change_5m)
+// The value is NaN until the symbol has barOffset finalized bars
 type ChangeComputer struct {
 	name      string
 	barOffset int // Number of bars to look back
@@ -23,11 +24,14 @@ func (c *ChangeComputer) Dependencies() []string { return nil }
 
 func (c *ChangeComputer) Compute(snapshot *SymbolStateSnapshot) (float64, bool) {
 	if len(snapshot.LastFinalBars) < c.barOffset {
-		return 0, false
+		return insufficientHistory()
 	}
 
 	currentBar := snapshot.LastFinalBars[len(snapshot.LastFinalBars)-1]
 	pastBar := snapshot.LastFinalBars[len(snapshot.LastFinalBars)-c.barOffset]
+	if pastBar.Close <= 0 {
+		return insufficientHistory()
+	}
 
 	change := currentBar.Close - pastBar.Close
 	return change, true
@@ -284,4 +288,3 @@ func (c *GapFromClosePctComputer) Compute(snapshot *SymbolStateSnapshot) (float6
 	gapPct := ((snapshot.TodayOpen - snapshot.YesterdayClose) / snapshot.YesterdayClose) * 100.0
 	return gapPct, true
 }
-
@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"math"
 	"testing"
 
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
@@ -31,8 +32,8 @@ func TestChangeComputer(t *testing.T) {
 		{
 			name:     "Insufficient bars",
 			bars:     []*models.Bar1m{{Close: 100.0}},
-			expected: 0,
-			ok:       false,
+			expected: math.NaN(),
+			ok:       true,
 		},
 	}
 
@@ -46,6 +47,12 @@ func TestChangeComputer(t *testing.T) {
 			if ok != tt.ok {
 				t.Errorf("Compute() ok = %v, want %v", ok, tt.ok)
 			}
+			if math.IsNaN(tt.expected) {
+				if !math.IsNaN(result) {
+					t.Errorf("Compute() = %v, want NaN", result)
+				}
+				return
+			}
 			if ok && result != tt.expected {
 				t.Errorf("Compute() = %v, want %v", result, tt.expected)
 			}
@@ -242,3 +249,54 @@ func TestGapFromCloseComputer(t *testing.T) {
 	}
 }
 
+func TestGapPctComputers(t *testing.T) {
+	gap := NewAliasComputer("gap_pct", &GapFromClosePctComputer{})
+	fromClose := NewAliasComputer("gap_from_prev_close_pct", &ChangeFromClosePctComputer{})
+
+	if gap.Name() != "gap_pct" || fromClose.Name() != "gap_from_prev_close_pct" {
+		t.Fatalf("alias names = %q, %q", gap.Name(), fromClose.Name())
+	}
+
+	tests := []struct {
+		name           string
+		yesterdayClose float64
+		todayOpen      float64
+		price          float64
+		wantGap        float64
+		gapOK          bool
+		wantFromClose  float64
+		fromCloseOK    bool
+	}{
+		{"Gap up", 100.0, 105.0, 110.0, 5.0, true, 10.0, true},
+		{"Gap down", 100.0, 95.0, 92.0, -5.0, true, -8.0, true},
+		{"Yesterday's close unset", 0, 105.0, 110.0, 0, false, 0, false},
+		{"Today's open unset", 100.0, 0, 110.0, 0, false, 10.0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshot := &SymbolStateSnapshot{
+				YesterdayClose: tt.yesterdayClose,
+				TodayOpen:      tt.todayOpen,
+				LiveBar:        &models.LiveBar{Close: tt.price},
+			}
+
+			for name, check := range map[string]struct {
+				computer MetricComputer
+				want     float64
+				ok       bool
+			}{
+				"gap_pct":                 {gap, tt.wantGap, tt.gapOK},
+				"gap_from_prev_close_pct": {fromClose, tt.wantFromClose, tt.fromCloseOK},
+			} {
+				result, ok := check.computer.Compute(snapshot)
+				if ok != check.ok {
+					t.Errorf("%s: ok = %v, want %v", name, ok, check.ok)
+				}
+				if ok && math.Abs(result-check.want) > 1e-9 {
+					t.Errorf("%s = %v, want %v", name, result, check.want)
+				}
+			}
+		})
+	}
+}
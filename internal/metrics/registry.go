@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"fmt"
+	"math"
 	"sync"
 )
 
@@ -50,7 +51,47 @@ func (r *Registry) Register(computer MetricComputer) error {
 	return nil
 }
 
-// ComputeAll computes all registered metrics from a snapshot
+// Replace registers a metric computer, replacing any computer with the same name
+func (r *Registry) Replace(computer MetricComputer) error {
+	if computer == nil {
+		return fmt.Errorf("computer cannot be nil")
+	}
+
+	name := computer.Name()
+	if name == "" {
+		return fmt.Errorf("computer name cannot be empty")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.computers[name] = computer
+	r.rebuildOrdered()
+
+	return nil
+}
+
+// Names returns the names of all registered metrics
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.computers))
+	for name := range r.computers {
+		names = append(names, name)
+	}
+	return names
+}
+
+// computable returns whether a computed metric has a value. Metrics that can't be computed
+// (ok = false, or NaN for insufficient history) are omitted from the computed metrics rather
+// than reported as 0, so rules see them as absent.
+func computable(value float64, ok bool) bool {
+	return ok && !math.IsNaN(value)
+}
+
+// ComputeAll computes all registered metrics from a snapshot. Metrics that can't be computed
+// are omitted.
 func (r *Registry) ComputeAll(snapshot *SymbolStateSnapshot) map[string]float64 {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -59,22 +100,39 @@ func (r *Registry) ComputeAll(snapshot *SymbolStateSnapshot) map[string]float64
 
 	// First, copy indicators (they're already computed)
 	for key, value := range snapshot.Indicators {
-		metrics[key] = value
+		if !math.IsNaN(value) {
+			metrics[key] = value
+		}
 	}
 
 	// Compute metrics in order
 	// Note: For now, we use registration order. In the future, we could
 	// implement topological sort based on dependencies
 	for _, computer := range r.ordered {
-		if value, ok := computer.Compute(snapshot); ok {
+		if value, ok := computer.Compute(snapshot); computable(value, ok) {
 			metrics[computer.Name()] = value
 		}
 	}
 
+	// Distance to any other moving average the indicator engine computed (e.g. per-symbol overrides)
+	for key := range snapshot.Indicators {
+		if !IsMovingAverageIndicator(key) {
+			continue
+		}
+		name := MADistanceMetricName(key)
+		if _, exists := metrics[name]; exists {
+			continue
+		}
+		if value, ok := NewMADistanceComputer(name, key).Compute(snapshot); computable(value, ok) {
+			metrics[name] = value
+		}
+	}
+
 	return metrics
 }
 
-// ComputeMetrics computes only the specified metrics from a snapshot
+// ComputeMetrics computes only the specified metrics from a snapshot, omitting those that
+// can't be computed
 // It also computes any dependencies of the requested metrics
 func (r *Registry) ComputeMetrics(snapshot *SymbolStateSnapshot, metricNames map[string]bool) map[string]float64 {
 	r.mu.RLock()
@@ -85,7 +143,7 @@ func (r *Registry) ComputeMetrics(snapshot *SymbolStateSnapshot, metricNames map
 	// First, copy indicators (they're already computed)
 	for key, value := range snapshot.Indicators {
 		// Only include if requested
-		if metricNames == nil || metricNames[key] {
+		if (metricNames == nil || metricNames[key]) && !math.IsNaN(value) {
 			metrics[key] = value
 		}
 	}
@@ -101,7 +159,19 @@ func (r *Registry) ComputeMetrics(snapshot *SymbolStateSnapshot, metricNames map
 	for _, computer := range r.ordered {
 		name := computer.Name()
 		if metricNames[name] {
-			if value, ok := computer.Compute(snapshot); ok {
+			if value, ok := computer.Compute(snapshot); computable(value, ok) {
+				metrics[name] = value
+			}
+		}
+	}
+
+	// Distance metrics for moving averages without a registered computer
+	for name := range metricNames {
+		if _, registered := r.computers[name]; registered {
+			continue
+		}
+		if maKey, ok := ParseMADistanceMetric(name); ok {
+			if value, ok := NewMADistanceComputer(name, maKey).Compute(snapshot); computable(value, ok) {
 				metrics[name] = value
 			}
 		}
@@ -169,6 +239,8 @@ func (r *Registry) registerBuiltInMetrics() {
 	// Price filters - Gap from Close
 	r.Register(&GapFromCloseComputer{})
 	r.Register(&GapFromClosePctComputer{})
+	r.Register(NewAliasComputer("gap_pct", &GapFromClosePctComputer{}))
+	r.Register(NewAliasComputer("gap_from_prev_close_pct", &ChangeFromClosePctComputer{}))
 
 	// Volume filters - Session-specific
 	r.Register(&PostmarketVolumeComputer{})
@@ -236,6 +308,9 @@ func (r *Registry) registerBuiltInMetrics() {
 	r.Register(NewVWAPDistancePctComputer("vwap_dist_15m_pct", "vwap_15m"))
 	r.Register(NewVWAPDistancePctComputer("vwap_dist_1h_pct", "vwap_1h"))
 
+	// Distance from the session-anchored VWAP (%)
+	r.Register(&SessionVWAPDistancePctComputer{})
+
 	// Indicator filters - MA Distance (%)
 	// SMA daily
 	r.Register(NewMADistanceComputer("ma_dist_sma20_daily_pct", "sma_20"))
@@ -256,6 +331,11 @@ func (r *Registry) registerBuiltInMetrics() {
 	// EMA daily
 	r.Register(NewMADistanceComputer("ma_dist_ema50_daily_pct", "ema_50"))
 
+	// Indicator filters - Distance to MA (%), named after the indicator (dist_to_ema_20_pct)
+	for _, maKey := range []string{"ema_9", "ema_12", "ema_20", "ema_21", "ema_26", "ema_50", "ema_200", "sma_10", "sma_20", "sma_50", "sma_200"} {
+		r.Register(NewMADistanceComputer(MADistanceMetricName(maKey), maKey))
+	}
+
 	// Activity filters - Trade Count with timeframes
 	r.Register(NewTradeCountComputer("trade_count_1m", 1))
 	r.Register(NewTradeCountComputer("trade_count_2m", 2))
@@ -263,6 +343,10 @@ func (r *Registry) registerBuiltInMetrics() {
 	r.Register(NewTradeCountComputer("trade_count_15m", 15))
 	r.Register(NewTradeCountComputer("trade_count_60m", 60))
 
+	// Activity filters - Tape speed
+	r.Register(NewTradesPerMinuteAvgComputer("trades_per_min_5m_avg", 5))
+	r.Register(NewTapeAccelerationComputer("tape_acceleration", 5))
+
 	// Activity filters - Consecutive Candles with timeframes
 	r.Register(NewConsecutiveCandlesComputer("consecutive_candles_1m", "1m"))
 	r.Register(NewConsecutiveCandlesComputer("consecutive_candles_2m", "1m")) // Uses 1m candles for 2m timeframe
@@ -285,6 +369,11 @@ func (r *Registry) registerBuiltInMetrics() {
 	// Advanced volume filters - Relative Volume at Same Time
 	r.Register(&RelativeVolumeSameTimeComputer{})
 
+	// LULD band metrics (default tiers, can be replaced via Replace)
+	for _, computer := range NewLULDComputers(DefaultLULDTiers()) {
+		r.Register(computer)
+	}
+
 	// Time-based filters
 	r.Register(&MinutesInMarketComputer{})
 	r.Register(&MinutesSinceNewsComputer{})
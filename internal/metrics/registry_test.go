@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"math"
 	"testing"
 	"time"
 
@@ -94,6 +95,54 @@ func TestRegistry_ComputeAll(t *testing.T) {
 	}
 }
 
+func TestRegistry_NewSymbolWithOnlyLiveBar(t *testing.T) {
+	registry := NewRegistry()
+
+	// A symbol that just started trading has a live bar but no finalized bars
+	snapshot := &SymbolStateSnapshot{
+		Symbol: "NEWCO",
+		LiveBar: &models.LiveBar{
+			Symbol:    "NEWCO",
+			Open:      10.0,
+			High:      10.5,
+			Low:       9.8,
+			Close:     10.2,
+			Volume:    500,
+			VWAPNum:   5100.0,
+			VWAPDenom: 500.0,
+		},
+	}
+
+	result := registry.ComputeAll(snapshot)
+
+	changeMetrics := []string{
+		"price_change_1m_pct",
+		"price_change_5m_pct",
+		"price_change_60m_pct",
+		"change_1m",
+		"change_15m",
+	}
+	for _, name := range changeMetrics {
+		if value, ok := result[name]; ok {
+			t.Errorf("Expected %s to be omitted without finalized bars, got %v", name, value)
+		}
+	}
+
+	// Requested metrics that can't be computed are omitted too, rather than reported as 0
+	requested := registry.ComputeMetrics(snapshot, map[string]bool{"price": true, "price_change_5m_pct": true})
+	if _, ok := requested["price_change_5m_pct"]; ok {
+		t.Errorf("Expected price_change_5m_pct to be omitted, got %v", requested)
+	}
+	if requested["price"] != 10.2 {
+		t.Errorf("Expected price=10.2, got %v", requested["price"])
+	}
+
+	// Live bar metrics are unaffected
+	if result["price"] != 10.2 {
+		t.Errorf("Expected price=10.2, got %v", result["price"])
+	}
+}
+
 func TestPriceChangeComputer(t *testing.T) {
 	computer := NewPriceChangeComputer("price_change_5m_pct", 6)
 
@@ -108,9 +157,9 @@ func TestPriceChangeComputer(t *testing.T) {
 		},
 	}
 
-	_, ok := computer.Compute(snapshot)
-	if ok {
-		t.Error("Expected false when insufficient bars, got true")
+	value, ok := computer.Compute(snapshot)
+	if !ok || !math.IsNaN(value) {
+		t.Errorf("Expected NaN when insufficient bars, got %v (ok=%v)", value, ok)
 	}
 
 	// Test with sufficient bars
@@ -137,7 +186,7 @@ func TestPriceChangeComputer(t *testing.T) {
 		{Close: 152.0}, // Current bar (last)
 	}
 
-	value, ok := computer.Compute(snapshot)
+	value, ok = computer.Compute(snapshot)
 	if !ok {
 		t.Error("Expected true when sufficient bars, got false")
 	}
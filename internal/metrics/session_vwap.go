@@ -0,0 +1,62 @@
+package metrics
+
+import "time"
+
+// SessionVWAPDistancePctComputer computes the distance (%) of the current price from the VWAP
+// anchored at the start of the current session (premarket, market or postmarket). The VWAP
+// accumulates price * volume since the session started instead of over a fixed window.
+// Metric name: vwap_session_dist_pct
+type SessionVWAPDistancePctComputer struct{}
+
+func (c *SessionVWAPDistancePctComputer) Name() string { return "vwap_session_dist_pct" }
+
+func (c *SessionVWAPDistancePctComputer) Dependencies() []string { return nil }
+
+func (c *SessionVWAPDistancePctComputer) Compute(snapshot *SymbolStateSnapshot) (float64, bool) {
+	if snapshot.CurrentSession == "" || snapshot.CurrentSession == "closed" {
+		return 0, false
+	}
+
+	vwap, ok := SessionVWAP(snapshot)
+	if !ok {
+		// A session without volume yet has no VWAP (e.g. it just started)
+		return insufficientHistory()
+	}
+
+	var currentPrice float64
+	if snapshot.LiveBar != nil {
+		currentPrice = snapshot.LiveBar.Close
+	} else if len(snapshot.LastFinalBars) > 0 {
+		currentPrice = snapshot.LastFinalBars[len(snapshot.LastFinalBars)-1].Close
+	} else {
+		return 0, false
+	}
+
+	distancePct := ((currentPrice - vwap) / vwap) * 100.0
+	if distancePct < 0 {
+		distancePct = -distancePct // Absolute percentage, like vwap_dist_{timeframe}_pct
+	}
+	return distancePct, true
+}
+
+// SessionVWAP returns the VWAP since the start of the current session: the session's finalized
+// bars plus the live bar, when the live bar is in the session and not yet finalized.
+// ok is false while the session has no volume.
+func SessionVWAP(snapshot *SymbolStateSnapshot) (vwap float64, ok bool) {
+	num := snapshot.SessionVWAPNum
+	volume := float64(snapshot.SessionVWAPVolume)
+
+	if lb := snapshot.LiveBar; lb != nil && lb.VWAPDenom > 0 &&
+		lb.Timestamp.Add(time.Minute).After(snapshot.SessionStartTime) {
+		n := len(snapshot.LastFinalBars)
+		if n == 0 || snapshot.LastFinalBars[n-1].Timestamp.Before(lb.Timestamp) {
+			num += lb.VWAPNum
+			volume += lb.VWAPDenom
+		}
+	}
+
+	if volume <= 0 || num <= 0 {
+		return 0, false
+	}
+	return num / volume, true
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+func TestSessionVWAPDistancePct(t *testing.T) {
+	sessionStart := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+	computer := &SessionVWAPDistancePctComputer{}
+
+	// Finalized bars: 100 shares at 100 and 300 shares at 104, so the session VWAP is 103
+	snapshot := &SymbolStateSnapshot{
+		CurrentSession:    "market",
+		SessionStartTime:  sessionStart,
+		SessionVWAPNum:    100*100 + 300*104,
+		SessionVWAPVolume: 400,
+		LastFinalBars: []*models.Bar1m{
+			{Timestamp: sessionStart, Close: 100, Volume: 100, VWAP: 100},
+			{Timestamp: sessionStart.Add(time.Minute), Close: 104, Volume: 300, VWAP: 104},
+		},
+	}
+
+	value, ok := computer.Compute(snapshot)
+	if !ok || math.Abs(value-(1.0/103*100)) > 1e-9 {
+		t.Errorf("Expected %.4f%% from the last close, got %v (ok=%v)", 1.0/103*100, value, ok)
+	}
+
+	// The live bar counts toward the VWAP until it is finalized: 400 shares at 107 move it to 105
+	snapshot.LiveBar = &models.LiveBar{
+		Timestamp: sessionStart.Add(2 * time.Minute),
+		Close:     107,
+		VWAPNum:   400 * 107,
+		VWAPDenom: 400,
+	}
+	if vwap, _ := SessionVWAP(snapshot); vwap != 105 {
+		t.Errorf("Expected session VWAP 105 with the live bar, got %v", vwap)
+	}
+	value, _ = computer.Compute(snapshot)
+	if math.Abs(value-(2.0/105*100)) > 1e-9 {
+		t.Errorf("Expected %.4f%%, got %v", 2.0/105*100, value)
+	}
+
+	// A live bar from before the session started is not part of its VWAP
+	snapshot.LiveBar.Timestamp = sessionStart.Add(-2 * time.Minute)
+	if vwap, _ := SessionVWAP(snapshot); vwap != 103 {
+		t.Errorf("Expected session VWAP 103 without the previous session's live bar, got %v", vwap)
+	}
+}
+
+func TestSessionVWAPDistancePct_SessionJustStarted(t *testing.T) {
+	computer := &SessionVWAPDistancePctComputer{}
+	snapshot := &SymbolStateSnapshot{
+		CurrentSession:   "market",
+		SessionStartTime: time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC),
+		LiveBar:          &models.LiveBar{Close: 100},
+	}
+
+	value, ok := computer.Compute(snapshot)
+	if !ok || !math.IsNaN(value) {
+		t.Errorf("Expected NaN before the session has volume, got %v (ok=%v)", value, ok)
+	}
+
+	snapshot.CurrentSession = "closed"
+	if _, ok := computer.Compute(snapshot); ok {
+		t.Error("Expected no value while the market is closed")
+	}
+}
@@ -4,6 +4,7 @@ import "errors"
 
 var (
 	ErrInvalidSymbol            = errors.New("invalid symbol")
+	ErrEmptySymbolUniverse      = errors.New("symbol universe must have at least one symbol")
 	ErrInvalidPrice             = errors.New("invalid price")
 	ErrInvalidTimestamp         = errors.New("invalid timestamp")
 	ErrInvalidBar               = errors.New("invalid bar (high < low)")
@@ -11,14 +12,36 @@ var (
 	ErrInvalidRuleID            = errors.New("invalid rule ID")
 	ErrInvalidRuleName          = errors.New("invalid rule name")
 	ErrNoConditions             = errors.New("rule must have at least one condition")
+	ErrInvalidLogicOperator     = errors.New("invalid logic operator (must be 'AND' or 'OR')")
+	ErrEmptyConditionGroup      = errors.New("condition group must have at least one condition or group")
+	ErrInvalidEvaluateOn        = errors.New("invalid evaluate_on (must be 'tick' or 'bar_close')")
+	ErrInvalidEvaluationInterval = errors.New("invalid evaluation_interval (must be >= 0 seconds)")
 	ErrInvalidMetric            = errors.New("invalid metric")
 	ErrInvalidOperator          = errors.New("invalid operator")
 	ErrInvalidAlertID           = errors.New("invalid alert ID")
+	ErrInvalidNoteAuthor        = errors.New("invalid note author")
+	ErrInvalidNoteText          = errors.New("invalid note text")
 	ErrInvalidToplistID         = errors.New("invalid toplist ID")
 	ErrInvalidToplistName       = errors.New("invalid toplist name")
 	ErrInvalidToplistMetric      = errors.New("invalid toplist metric")
 	ErrInvalidToplistTimeWindow  = errors.New("invalid toplist time window")
 	ErrInvalidToplistSortOrder   = errors.New("invalid toplist sort order")
 	ErrInvalidToplistType        = errors.New("invalid toplist type (must be 'system' or 'user')")
+	ErrInvalidToplistMaxSize     = errors.New("invalid toplist max size (must be >= 0)")
+	ErrInvalidToplistCustomMetric = errors.New("invalid toplist custom metric (user toplists only, name required)")
+	ErrInvalidToplistChangeUnit  = errors.New("invalid toplist change unit (must be 'pct' or 'points', change_pct only)")
+	ErrInvalidToplistNormalization = errors.New("invalid toplist normalization (must be 'none', 'zscore' or 'percentile')")
+	ErrInvalidToplistTieBreaker  = errors.New("invalid toplist tie breaker (must be a non-custom metric or 'symbol')")
+	ErrInvalidToplistCompoundWindows = errors.New("invalid toplist compound windows (user toplists only, at least two distinct windows, non-custom metric)")
+	ErrInvalidCustomMetricUser       = errors.New("invalid custom metric user ID")
+	ErrInvalidCustomMetricName       = errors.New("invalid custom metric name")
+	ErrInvalidCustomMetricExpression = errors.New("invalid custom metric expression")
+	ErrInvalidDedupKey               = errors.New("invalid dedup key")
+	ErrInvalidDeliveryPolicy         = errors.New("invalid delivery policy")
+	ErrInvalidHysteresisBand         = errors.New("invalid hysteresis band (must be >= 0, ordered comparison operators only)")
+	ErrInvalidSeverityBands          = errors.New("invalid severity bands (warning must be >= 0 and critical >= warning)")
+	ErrInvalidUserPreferences        = errors.New("invalid user preferences")
+	ErrInvalidWebhookUser            = errors.New("invalid webhook user ID")
+	ErrInvalidWebhookURL             = errors.New("invalid webhook URL (must be an absolute http or https URL)")
 )
 
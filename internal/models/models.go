@@ -1,6 +1,9 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/url"
 	"time"
 )
 
@@ -13,6 +16,9 @@ type Tick struct {
 	Type      string    `json:"type"` // "trade" or "quote"
 	Bid       float64   `json:"bid,omitempty"`
 	Ask       float64   `json:"ask,omitempty"`
+	LULDUpper float64   `json:"luld_upper,omitempty"` // Provider-supplied limit-up band (if available)
+	LULDLower float64   `json:"luld_lower,omitempty"` // Provider-supplied limit-down band (if available)
+	Sequence  int64     `json:"sequence,omitempty"`   // Provider-supplied per-symbol sequence number (0 = not provided)
 }
 
 // Validate validates a Tick
@@ -29,7 +35,8 @@ func (t *Tick) Validate() error {
 	return nil
 }
 
-// Bar1m represents a finalized 1-minute bar
+// Bar1m represents a finalized 1-minute bar, or a longer bar rolled up from 1-minute bars
+// when Timeframe is set
 type Bar1m struct {
 	Symbol    string    `json:"symbol"`
 	Timestamp time.Time `json:"timestamp"`
@@ -39,6 +46,7 @@ type Bar1m struct {
 	Close     float64   `json:"close"`
 	Volume    int64     `json:"volume"`
 	VWAP      float64   `json:"vwap"`
+	Timeframe string    `json:"timeframe,omitempty"` // Rolled-up bar duration (e.g. "5m"), empty for 1-minute bars
 }
 
 // Validate validates a Bar1m
@@ -58,6 +66,17 @@ func (b *Bar1m) Validate() error {
 	return nil
 }
 
+// AlignToMinute returns the start of the minute containing t, expressed in loc (nil keeps t's location).
+// Alignment is done on absolute time rather than wall-clock fields, so DST transitions never
+// produce short or long bars and repeated wall-clock minutes stay distinct.
+func AlignToMinute(t time.Time, loc *time.Location) time.Time {
+	aligned := t.Truncate(time.Minute)
+	if loc != nil {
+		aligned = aligned.In(loc)
+	}
+	return aligned
+}
+
 // LiveBar represents a bar that is currently being built (not yet finalized)
 type LiveBar struct {
 	Symbol    string    `json:"symbol"`
@@ -89,8 +108,20 @@ func (lb *LiveBar) ToBar1m() *Bar1m {
 	}
 }
 
-// Update updates the live bar with a new tick
+// Update updates the live bar with a new tick. VWAP is size-weighted:
+// sum(price*size) / sum(size) over the bar's trades with a positive size.
 func (lb *LiveBar) Update(tick *Tick) {
+	lb.update(tick, true)
+}
+
+// UpdateExcludingVWAP updates the live bar's prices and volume with a tick that
+// must not contribute to VWAP (e.g. quotes or excluded trade types)
+func (lb *LiveBar) UpdateExcludingVWAP(tick *Tick) {
+	lb.update(tick, false)
+}
+
+// update applies a tick to the live bar, optionally accumulating it into VWAP
+func (lb *LiveBar) update(tick *Tick, includeVWAP bool) {
 	if lb.Open == 0 {
 		lb.Open = tick.Price
 		lb.High = tick.Price
@@ -103,9 +134,16 @@ func (lb *LiveBar) Update(tick *Tick) {
 		lb.Low = tick.Price
 	}
 	lb.Close = tick.Price
+
+	// Zero-size prints carry no weight and negative sizes are invalid
+	if tick.Size <= 0 {
+		return
+	}
 	lb.Volume += tick.Size
-	lb.VWAPNum += tick.Price * float64(tick.Size)
-	lb.VWAPDenom += float64(tick.Size)
+	if includeVWAP {
+		lb.VWAPNum += tick.Price * float64(tick.Size)
+		lb.VWAPDenom += float64(tick.Size)
+	}
 }
 
 // SymbolState represents the current state of a symbol for scanning
@@ -124,29 +162,341 @@ type Indicator struct {
 	Values    map[string]interface{} `json:"values"` // e.g., {"rsi_14": 65.5, "ema_20": 150.2}
 }
 
+// IndicatorValue is a single published indicator value, as stored in indicator history
+type IndicatorValue struct {
+	Symbol    string    `json:"symbol"`
+	Timestamp time.Time `json:"timestamp"`
+	Name      string    `json:"indicator"`
+	Value     float64   `json:"value"`
+}
+
+// ScanStatsSample is a scanner worker's scan loop activity over one persistence interval,
+// as stored in scan statistics history. Counters are deltas over the interval.
+type ScanStatsSample struct {
+	WorkerID            string    `json:"worker_id"`
+	Timestamp           time.Time `json:"timestamp"` // End of the interval
+	ScanCycles          int64     `json:"scan_cycles"`
+	SymbolsScanned      int64     `json:"symbols_scanned"`
+	SymbolsSkippedStale int64     `json:"symbols_skipped_stale"`
+	RulesEvaluated      int64     `json:"rules_evaluated"`
+	RulesMatched        int64     `json:"rules_matched"`
+	AlertsEmitted       int64     `json:"alerts_emitted"`
+	AvgCycleTimeMs      float64   `json:"avg_cycle_time_ms"`  // Average scan cycle time within the interval
+	LastCycleTimeMs     float64   `json:"last_cycle_time_ms"` // Latest scan cycle time at the end of the interval
+}
+
 // Rule represents a trading rule definition
 type Rule struct {
-	ID          string      `json:"id"`
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	Conditions  []Condition `json:"conditions"`
-	Cooldown    int         `json:"cooldown,omitempty"` // Deprecated: Cooldown is now global via SCANNER_COOLDOWN_DEFAULT env var
-	Enabled     bool        `json:"enabled"`
-	CreatedAt   time.Time   `json:"created_at"`
-	UpdatedAt   time.Time   `json:"updated_at"`
+	ID             string      `json:"id"`
+	UserID         string      `json:"user_id,omitempty"` // Owner; user custom metrics resolve for this user (empty = system rule)
+	Name           string      `json:"name"`
+	Description    string      `json:"description"`
+	Conditions     []Condition `json:"conditions"`
+	ConditionGroups []ConditionGroup `json:"condition_groups,omitempty"` // Optional: nested AND/OR groups combined with Conditions
+	LogicOperator  string      `json:"logic_operator,omitempty"`  // How Conditions and ConditionGroups combine: "AND" (default) or "OR"
+	ExitConditions []Condition `json:"exit_conditions,omitempty"` // Optional: clears the active alert for a symbol when matched
+	EvaluateOn     string      `json:"evaluate_on,omitempty"`     // "tick" (default) or "bar_close"
+	EvaluationInterval int     `json:"evaluation_interval,omitempty"` // Optional: minimum seconds between evaluations (0 = every scan cycle)
+	Cooldown       int         `json:"cooldown,omitempty"`        // Seconds between alerts of the rule for a symbol (0 = no cooldown)
+	DedupKey       *DedupKey   `json:"dedup_key,omitempty"`       // Optional: alert deduplication key composition (default: rule, symbol, timestamp)
+	Priority       int         `json:"priority,omitempty"`        // Alert delivery priority (higher is delivered first, default: 0)
+	MatchOnMissing bool        `json:"match_on_missing,omitempty"` // Whether a condition whose metric has no value (e.g. not enough history) matches (default: no match)
+	Delivery       *DeliveryPolicy `json:"delivery,omitempty"`    // Optional: how alerts are delivered across channels (default: all channels)
+	Enabled        bool        `json:"enabled"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// Rule evaluation modes
+const (
+	RuleEvaluateOnTick     = "tick"      // Evaluate on every scan cycle (live bar updates)
+	RuleEvaluateOnBarClose = "bar_close" // Evaluate only in the scan cycle after a bar is finalized
+)
+
+// EvaluatesOnBarClose returns whether the rule is only evaluated after bar close
+func (r *Rule) EvaluatesOnBarClose() bool {
+	return r.EvaluateOn == RuleEvaluateOnBarClose
+}
+
+// Condition logic operators
+const (
+	LogicAnd = "AND" // Every condition and group must match (default)
+	LogicOr  = "OR"  // At least one condition or group must match
+)
+
+// ConditionGroup combines conditions and nested groups with AND or OR logic,
+// e.g. {"operator": "OR", "conditions": [rsi_14 < 30, price_change_5m_pct > 5]}
+type ConditionGroup struct {
+	Operator   string           `json:"operator,omitempty"` // "AND" (default) or "OR"
+	Conditions []Condition      `json:"conditions,omitempty"`
+	Groups     []ConditionGroup `json:"groups,omitempty"`
+}
+
+// IsOr returns whether the group matches when any of its members matches
+func (g *ConditionGroup) IsOr() bool {
+	return g.Operator == LogicOr
+}
+
+// AllConditions returns the conditions of the group and its nested groups, depth first
+func (g *ConditionGroup) AllConditions() []Condition {
+	if len(g.Groups) == 0 {
+		return g.Conditions
+	}
+	conditions := append([]Condition(nil), g.Conditions...)
+	for i := range g.Groups {
+		conditions = append(conditions, g.Groups[i].AllConditions()...)
+	}
+	return conditions
+}
+
+// Depth returns the nesting depth of the group (a group without nested groups has depth 1)
+func (g *ConditionGroup) Depth() int {
+	depth := 0
+	for i := range g.Groups {
+		if d := g.Groups[i].Depth(); d > depth {
+			depth = d
+		}
+	}
+	return depth + 1
+}
+
+// Validate validates a ConditionGroup and its nested groups
+func (g *ConditionGroup) Validate() error {
+	if g.Operator != "" && g.Operator != LogicAnd && g.Operator != LogicOr {
+		return ErrInvalidLogicOperator
+	}
+	if len(g.Conditions) == 0 && len(g.Groups) == 0 {
+		return ErrEmptyConditionGroup
+	}
+	for _, cond := range g.Conditions {
+		if err := cond.Validate(); err != nil {
+			return err
+		}
+	}
+	for i := range g.Groups {
+		if err := g.Groups[i].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EntryGroup returns the rule's entry conditions as a single group. A rule with only
+// a flat Conditions slice evaluates as AND.
+func (r *Rule) EntryGroup() ConditionGroup {
+	return ConditionGroup{
+		Operator:   r.LogicOperator,
+		Conditions: r.Conditions,
+		Groups:     r.ConditionGroups,
+	}
+}
+
+// EntryConditions returns every entry condition, including those in condition groups
+func (r *Rule) EntryConditions() []Condition {
+	group := r.EntryGroup()
+	return group.AllConditions()
+}
+
+// HasExitConditions returns whether the rule tracks active alerts with exit conditions
+func (r *Rule) HasExitConditions() bool {
+	return len(r.ExitConditions) > 0
+}
+
+// Alert deduplication key fields
+const (
+	DedupFieldRule      = "rule"      // Rule ID
+	DedupFieldSymbol    = "symbol"    // Symbol
+	DedupFieldTimestamp = "timestamp" // Alert timestamp rounded to the second
+	DedupFieldBar       = "bar"       // Start of the 1-minute bar the alert triggered in
+	DedupFieldMetric    = "metric"    // Metric value rounded down to a multiple of BucketSize
+)
+
+// DedupKey configures which fields the alert service combines into a rule's
+// deduplication key. Alerts with equal keys within the dedupe TTL are dropped,
+// e.g. {"fields": ["rule", "symbol", "bar"]} alerts at most once per bar.
+type DedupKey struct {
+	Fields     []string `json:"fields"`
+	Metric     string   `json:"metric,omitempty"`      // Metric bucketed into the key (requires the "metric" field)
+	BucketSize float64  `json:"bucket_size,omitempty"` // Bucket width for Metric
+}
+
+// DefaultDedupKey returns the key composition used for rules without a DedupKey
+func DefaultDedupKey() *DedupKey {
+	return &DedupKey{Fields: []string{DedupFieldRule, DedupFieldSymbol, DedupFieldTimestamp}}
+}
+
+// HasField returns true if the key includes the given field
+func (k *DedupKey) HasField(field string) bool {
+	for _, f := range k.Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates a DedupKey
+func (k *DedupKey) Validate() error {
+	if len(k.Fields) == 0 {
+		return fmt.Errorf("%w: at least one field is required", ErrInvalidDedupKey)
+	}
+
+	seen := make(map[string]bool, len(k.Fields))
+	for _, field := range k.Fields {
+		switch field {
+		case DedupFieldRule, DedupFieldSymbol, DedupFieldTimestamp, DedupFieldBar, DedupFieldMetric:
+		default:
+			return fmt.Errorf("%w: unknown field %q", ErrInvalidDedupKey, field)
+		}
+		if seen[field] {
+			return fmt.Errorf("%w: duplicate field %q", ErrInvalidDedupKey, field)
+		}
+		seen[field] = true
+	}
+
+	if seen[DedupFieldMetric] {
+		if k.Metric == "" {
+			return fmt.Errorf("%w: metric is required with the metric field", ErrInvalidDedupKey)
+		}
+		if k.BucketSize <= 0 {
+			return fmt.Errorf("%w: bucket_size must be positive", ErrInvalidDedupKey)
+		}
+	} else if k.Metric != "" || k.BucketSize != 0 {
+		return fmt.Errorf("%w: metric and bucket_size require the metric field", ErrInvalidDedupKey)
+	}
+
+	return nil
+}
+
+// Delivery modes
+const (
+	DeliveryModeAll             = "all"              // Deliver to every channel (default)
+	DeliveryModePrimaryFallback = "primary_fallback" // Deliver to the primary channel, falling back to others only if it fails
+)
+
+// DeliveryPolicy configures how a rule's alerts are delivered across notification
+// channels (the WebSocket stream and alert sinks such as Kafka), e.g.
+// {"mode": "primary_fallback", "primary": "websocket", "fallbacks": ["kafka"]}
+type DeliveryPolicy struct {
+	Mode      string   `json:"mode"`
+	Primary   string   `json:"primary,omitempty"`   // Channel tried first in primary_fallback mode
+	Fallbacks []string `json:"fallbacks,omitempty"` // Channels tried in order when the primary fails (default: all other channels)
+}
+
+// IsPrimaryFallback returns true if secondary channels only deliver when the primary fails
+func (p *DeliveryPolicy) IsPrimaryFallback() bool {
+	return p != nil && p.Mode == DeliveryModePrimaryFallback
+}
+
+// Validate validates a DeliveryPolicy
+func (p *DeliveryPolicy) Validate() error {
+	switch p.Mode {
+	case "", DeliveryModeAll:
+		if p.Primary != "" || len(p.Fallbacks) > 0 {
+			return fmt.Errorf("%w: primary and fallbacks require the %s mode", ErrInvalidDeliveryPolicy, DeliveryModePrimaryFallback)
+		}
+	case DeliveryModePrimaryFallback:
+		if p.Primary == "" {
+			return fmt.Errorf("%w: primary channel is required", ErrInvalidDeliveryPolicy)
+		}
+		seen := map[string]bool{p.Primary: true}
+		for _, channel := range p.Fallbacks {
+			if channel == "" || seen[channel] {
+				return fmt.Errorf("%w: invalid or duplicate fallback channel %q", ErrInvalidDeliveryPolicy, channel)
+			}
+			seen[channel] = true
+		}
+	default:
+		return fmt.Errorf("%w: unknown mode %q", ErrInvalidDeliveryPolicy, p.Mode)
+	}
+	return nil
+}
+
+// CustomMetric is a user-defined derived metric computed from other metrics,
+// e.g. {"name": "range_vs_atr", "expression": "(high - low) / atr_14 * 100"}
+type CustomMetric struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"user_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Expression  string    `json:"expression"` // Arithmetic over metric names and numbers: + - * / and parentheses
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Validate validates a CustomMetric
+func (m *CustomMetric) Validate() error {
+	if m.UserID == "" {
+		return ErrInvalidCustomMetricUser
+	}
+	if m.Name == "" {
+		return ErrInvalidCustomMetricName
+	}
+	if m.Expression == "" {
+		return ErrInvalidCustomMetricExpression
+	}
+	return nil
+}
+
+// Webhook is an HTTP endpoint a user registered to receive alerts: those of one of their
+// rules, or without a rule those addressed to the user. Each webhook has its own signing
+// secret, so a receiver can only verify (and forge) its own payloads.
+type Webhook struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	URL       string    `json:"url"`
+	RuleID    string    `json:"rule_id,omitempty"` // Only this rule's alerts (empty = alerts addressed to the user)
+	Secret    string    `json:"secret,omitempty"`  // HMAC-SHA256 signing secret, generated at registration
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate validates a Webhook
+func (w *Webhook) Validate() error {
+	if w.UserID == "" {
+		return ErrInvalidWebhookUser
+	}
+	parsed, err := url.Parse(w.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	return nil
 }
 
 // Condition represents a single condition in a rule
 type Condition struct {
 	Metric   string      `json:"metric"`   // e.g., "rsi_14", "price_change_5m_pct"
-	Operator string      `json:"operator"` // ">", "<", ">=", "<=", "==", "!="
-	Value    interface{} `json:"value"`     // Comparison value
+	Operator string      `json:"operator"` // ">", "<", ">=", "<=", "==", "!=", "crosses_above", "crosses_below"
+	Value    interface{} `json:"value"`     // Comparison value (a metric name for crossing operators, e.g. "vwap_5m")
 
 	// Filter configuration options
 	VolumeThreshold *int64  `json:"volume_threshold,omitempty"` // Minimum volume required (default: 0)
+	SessionVolumeThresholds map[string]int64 `json:"session_volume_thresholds,omitempty"` // Per-session minimum volume ("premarket", "market", "postmarket"), overrides volume_threshold
 	CalculatedDuring string  `json:"calculated_during,omitempty"` // Session filter: "premarket", "market", "postmarket", "all" (default: "all")
 	Timeframe        string  `json:"timeframe,omitempty"`        // Timeframe override (e.g., "5m", "15m") - extracted from metric name if not specified
 	ValueType        string  `json:"value_type,omitempty"`        // Value type: "$" or "%" - extracted from metric name if not specified
+	HysteresisBand   *float64 `json:"hysteresis_band,omitempty"` // Once matched, keeps matching until the metric moves back past value ± band (>, >=, <, <= only)
+	SeverityBands    *SeverityBands `json:"severity_bands,omitempty"` // How far past the value a match is "warning" or "critical" (default: DefaultSeverityBands)
+}
+
+// SeverityBands classifies how far a metric went past a condition's value, as a percentage of
+// the value (in metric units when the value is 0). Matches short of Warning are "info".
+type SeverityBands struct {
+	Warning  float64 `json:"warning"`  // Distance past the value for "warning"
+	Critical float64 `json:"critical"` // Distance past the value for "critical" (>= warning)
+}
+
+// DefaultSeverityBands returns the bands for conditions without severity_bands: warning 10% and
+// critical 25% past the value, so "rsi_14 < 30" is critical at 15 and info at 29
+func DefaultSeverityBands() SeverityBands {
+	return SeverityBands{Warning: 10, Critical: 25}
+}
+
+// Validate validates severity bands
+func (b *SeverityBands) Validate() error {
+	if b.Warning < 0 || b.Critical < b.Warning {
+		return ErrInvalidSeverityBands
+	}
+	return nil
 }
 
 // Validate validates a Rule
@@ -157,17 +507,58 @@ func (r *Rule) Validate() error {
 	if r.Name == "" {
 		return ErrInvalidRuleName
 	}
-	if len(r.Conditions) == 0 {
+	if len(r.Conditions) == 0 && len(r.ConditionGroups) == 0 {
 		return ErrNoConditions
 	}
+	if r.LogicOperator != "" && r.LogicOperator != LogicAnd && r.LogicOperator != LogicOr {
+		return ErrInvalidLogicOperator
+	}
+	if r.EvaluateOn != "" && r.EvaluateOn != RuleEvaluateOnTick && r.EvaluateOn != RuleEvaluateOnBarClose {
+		return ErrInvalidEvaluateOn
+	}
+	if r.EvaluationInterval < 0 {
+		return ErrInvalidEvaluationInterval
+	}
 	for _, cond := range r.Conditions {
 		if err := cond.Validate(); err != nil {
 			return err
 		}
 	}
+	for i := range r.ConditionGroups {
+		if err := r.ConditionGroups[i].Validate(); err != nil {
+			return err
+		}
+	}
+	for _, cond := range r.ExitConditions {
+		if err := cond.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.DedupKey != nil {
+		if err := r.DedupKey.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.Delivery != nil {
+		if err := r.Delivery.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// Crossing operators match in the scan cycle where the metric moves from one side of the
+// comparison value to the other, compared with the symbol's previous scan cycle
+const (
+	OperatorCrossesAbove = "crosses_above"
+	OperatorCrossesBelow = "crosses_below"
+)
+
+// IsCrossing returns whether the condition uses a crossing operator
+func (c *Condition) IsCrossing() bool {
+	return c.Operator == OperatorCrossesAbove || c.Operator == OperatorCrossesBelow
+}
+
 // Validate validates a Condition
 func (c *Condition) Validate() error {
 	if c.Metric == "" {
@@ -175,24 +566,258 @@ func (c *Condition) Validate() error {
 	}
 	validOps := map[string]bool{
 		">": true, "<": true, ">=": true, "<=": true, "==": true, "!=": true,
+		OperatorCrossesAbove: true, OperatorCrossesBelow: true,
 	}
 	if !validOps[c.Operator] {
 		return ErrInvalidOperator
 	}
+	if c.HysteresisBand != nil {
+		if *c.HysteresisBand < 0 || c.Operator == "==" || c.Operator == "!=" || c.IsCrossing() {
+			return ErrInvalidHysteresisBand
+		}
+	}
+	if c.SeverityBands != nil {
+		if err := c.SeverityBands.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// RuleMatch is a symbol matched by a rule evaluated against live scanner state
+type RuleMatch struct {
+	Symbol  string             `json:"symbol"`
+	Metrics map[string]float64 `json:"metrics"` // Current values of the metrics the rule uses
+}
+
+// RuleEvaluation is the result of a dry-run evaluation of a rule against live scanner state
+type RuleEvaluation struct {
+	EvaluatedAt      time.Time   `json:"evaluated_at"`
+	SymbolsEvaluated int         `json:"symbols_evaluated"`
+	SymbolsStale     int         `json:"symbols_stale"` // Symbols skipped because their data is stale
+	Matches          []RuleMatch `json:"matches"`
+}
+
+// RuleDisableEvent records why a rule was disabled automatically
+type RuleDisableEvent struct {
+	RuleID     string    `json:"rule_id"`
+	RuleName   string    `json:"rule_name"`
+	UserID     string    `json:"user_id,omitempty"` // Owner of the rule (empty = no owner)
+	Reason     string    `json:"reason"`
+	Errors     int64     `json:"errors"` // Consecutive evaluation errors that triggered the disable
+	LastError  string    `json:"last_error"`
+	DisabledAt time.Time `json:"disabled_at"`
+}
+
 // Alert represents a generated alert
 type Alert struct {
-	ID        string    `json:"id"`
-	RuleID    string    `json:"rule_id"`
-	RuleName  string    `json:"rule_name"`
-	Symbol    string    `json:"symbol"`
-	Timestamp time.Time `json:"timestamp"`
-	Price     float64   `json:"price"`
-	Message   string    `json:"message"`
+	ID        string                 `json:"id"`
+	RuleID    string                 `json:"rule_id"`
+	RuleName  string                 `json:"rule_name"`
+	Symbol    string                 `json:"symbol"`
+	Timestamp time.Time              `json:"timestamp"`
+	Price     float64                `json:"price"`
+	Message   string                 `json:"message"`
+	Messages  map[string]string      `json:"messages,omitempty"` // Localized messages keyed by locale
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
-	TraceID   string    `json:"trace_id,omitempty"`
+	TraceID   string                 `json:"trace_id,omitempty"`
+	Type      string                 `json:"type,omitempty"` // "entry" (default) or "exit"
+	Priority  int                    `json:"priority,omitempty"` // Delivery priority from the rule (higher is delivered first)
+	Delivery  *DeliveryPolicy        `json:"delivery,omitempty"` // Channel delivery policy from the rule (nil = all channels)
+	Occurrences int                  `json:"occurrences,omitempty"` // Alerts collapsed into this persisted record (0 or 1 = single alert)
+	AlertIDs  []string               `json:"alert_ids,omitempty"` // IDs of the alerts collapsed into this persisted record, oldest first (empty = single alert)
+	Sequence  int64                  `json:"sequence,omitempty"` // Per-symbol emission order, strictly increasing per symbol (0 = unsequenced)
+	Severity  string                 `json:"severity,omitempty"` // How far the conditions were exceeded: "info", "warning" or "critical" (empty = info)
+}
+
+// Alert severities, from how far a rule's conditions were exceeded
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// SeverityRank orders severities from 0 (info) to 2 (critical). Empty is info; unknown
+// severities return -1.
+func SeverityRank(severity string) int {
+	switch severity {
+	case "", SeverityInfo:
+		return 0
+	case SeverityWarning:
+		return 1
+	case SeverityCritical:
+		return 2
+	default:
+		return -1
+	}
+}
+
+// Alert types
+const (
+	AlertTypeEntry   = "entry"   // Rule conditions matched
+	AlertTypeExit    = "exit"    // Rule exit conditions matched for a symbol with an active alert
+	AlertTypeBreadth = "breadth" // Enough symbols matched a system rule within a window (market-wide)
+)
+
+// BreadthAlertSymbol is the symbol of breadth alerts, which cover many symbols
+const BreadthAlertSymbol = "MARKET"
+
+// IsExit returns true if the alert signals that a rule's exit conditions matched
+func (a *Alert) IsExit() bool {
+	return a.Type == AlertTypeExit
+}
+
+// IsBreadth returns true if the alert is a market-wide breadth alert
+func (a *Alert) IsBreadth() bool {
+	return a.Type == AlertTypeBreadth
+}
+
+// BreadthSymbols returns the symbols that matched a breadth alert's rule (the
+// "breadth_symbols" metadata, a []string or, once decoded from JSON, a []interface{})
+func (a *Alert) BreadthSymbols() []string {
+	if a.Metadata == nil {
+		return nil
+	}
+	switch symbols := a.Metadata["breadth_symbols"].(type) {
+	case []string:
+		return symbols
+	case []interface{}:
+		result := make([]string, 0, len(symbols))
+		for _, symbol := range symbols {
+			if s, ok := symbol.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// Alert metadata keys with special meaning in the delivery pipeline
+const (
+	// AlertMetadataTest marks a synthetic alert used to exercise delivery (never persisted)
+	AlertMetadataTest = "test"
+	// AlertMetadataUserID restricts delivery of an alert to a single user: the owner of the
+	// user rule that matched, or the user who requested a test alert
+	AlertMetadataUserID = "user_id"
+	// AlertMetadataDedupKey carries the rule's DedupKey to the alert service
+	AlertMetadataDedupKey = "dedup_key"
+)
+
+// IsTest returns true if the alert is a synthetic test alert
+func (a *Alert) IsTest() bool {
+	if a.Metadata == nil {
+		return false
+	}
+	isTest, _ := a.Metadata[AlertMetadataTest].(bool)
+	return isTest
+}
+
+// TargetUserID returns the user the alert is addressed to (empty for broadcast alerts)
+func (a *Alert) TargetUserID() string {
+	if a.Metadata == nil {
+		return ""
+	}
+	userID, _ := a.Metadata[AlertMetadataUserID].(string)
+	return userID
+}
+
+// DedupKeyConfig returns the deduplication key composition carried by the alert,
+// or nil if the alert uses the default key
+func (a *Alert) DedupKeyConfig() (*DedupKey, error) {
+	if a.Metadata == nil {
+		return nil, nil
+	}
+
+	var key *DedupKey
+	switch value := a.Metadata[AlertMetadataDedupKey].(type) {
+	case nil:
+		return nil, nil
+	case *DedupKey:
+		key = value
+	default:
+		// Decoded from JSON as a generic map
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDedupKey, err)
+		}
+		key = &DedupKey{}
+		if err := json.Unmarshal(data, key); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDedupKey, err)
+		}
+	}
+
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// MessageFor returns the alert message for a locale, falling back to Message
+func (a *Alert) MessageFor(locale string) string {
+	if message, exists := a.Messages[locale]; exists {
+		return message
+	}
+	return a.Message
+}
+
+// Alert metadata scopes of recipients
+const (
+	// AlertScopeFull recipients receive all alert metadata
+	AlertScopeFull = "full"
+	// AlertScopeRestricted recipients receive alerts without internal metadata
+	AlertScopeRestricted = "restricted"
+)
+
+// DefaultRedactedAlertFields are the metadata fields withheld from restricted recipients:
+// computed metrics and condition details, and internal delivery fields
+var DefaultRedactedAlertFields = []string{
+	"metrics",
+	"metrics_truncated",
+	"conditions",
+	"explanation",
+	AlertMetadataDedupKey,
+	AlertMetadataUserID,
+}
+
+// AlertRedactor strips internal metadata from alerts delivered to restricted recipients
+type AlertRedactor struct {
+	fields map[string]bool
+}
+
+// NewAlertRedactor creates a redactor withholding the given metadata fields
+// (DefaultRedactedAlertFields if none)
+func NewAlertRedactor(fields []string) *AlertRedactor {
+	if len(fields) == 0 {
+		fields = DefaultRedactedAlertFields
+	}
+	redactor := &AlertRedactor{fields: make(map[string]bool, len(fields))}
+	for _, field := range fields {
+		redactor.fields[field] = true
+	}
+	return redactor
+}
+
+// Redact returns the alert as delivered to a recipient with the given scope. Full scope
+// recipients get the alert itself; any other scope gets a copy without the redacted
+// metadata fields and trace ID. Safe to call on a nil redactor (no redaction).
+func (r *AlertRedactor) Redact(alert *Alert, scope string) *Alert {
+	if r == nil || alert == nil || scope == AlertScopeFull {
+		return alert
+	}
+
+	redacted := *alert
+	redacted.TraceID = ""
+	if alert.Metadata != nil {
+		redacted.Metadata = make(map[string]interface{}, len(alert.Metadata))
+		for key, value := range alert.Metadata {
+			if !r.fields[key] {
+				redacted.Metadata[key] = value
+			}
+		}
+	}
+	return &redacted
 }
 
 // Validate validates an Alert
@@ -212,3 +837,207 @@ func (a *Alert) Validate() error {
 	return nil
 }
 
+
+// SymbolMute stops all alerts for a symbol across rules and users, e.g. during
+// maintenance or a known bad-data event
+type SymbolMute struct {
+	Symbol    string     `json:"symbol"`
+	Reason    string     `json:"reason,omitempty"`
+	MutedAt   time.Time  `json:"muted_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Nil mutes until explicitly unmuted
+}
+
+// IsExpired returns true if the mute has a TTL that has elapsed at now
+func (m *SymbolMute) IsExpired(now time.Time) bool {
+	return m.ExpiresAt != nil && !now.Before(*m.ExpiresAt)
+}
+
+// SymbolUniverseChannel is the pub/sub channel on which symbol universe changes are announced
+const SymbolUniverseChannel = "symbols.universe"
+
+// SymbolUniverseUpdate announces a new symbol universe. Added and Removed are relative to the
+// previously stored universe (every symbol is added when none was stored).
+type SymbolUniverseUpdate struct {
+	Symbols   []string  `json:"symbols"`
+	Added     []string  `json:"added"`
+	Removed   []string  `json:"removed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AlertNotesChannel is the pub/sub channel on which new alert notes are announced
+const AlertNotesChannel = "alerts.notes"
+
+// AlertNote is a comment attached to an alert while triaging it
+type AlertNote struct {
+	ID        string    `json:"id"`
+	AlertID   string    `json:"alert_id"`
+	Author    string    `json:"author"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate validates an AlertNote
+func (n *AlertNote) Validate() error {
+	if n.AlertID == "" {
+		return ErrInvalidAlertID
+	}
+	if n.Author == "" {
+		return ErrInvalidNoteAuthor
+	}
+	if n.Text == "" {
+		return ErrInvalidNoteText
+	}
+	return nil
+}
+
+// UserPreferences holds a user's alert delivery settings
+type UserPreferences struct {
+	UserID          string        `json:"user_id"`
+	DefaultChannels []string      `json:"default_channels,omitempty"` // Channel order for the user's alerts when the rule sets no delivery policy (first = primary)
+	QuietHours      *QuietHours   `json:"quiet_hours,omitempty"`      // Daily window in which the user's alerts are suppressed
+	Locale          string        `json:"locale,omitempty"`           // Locale for alert messages (empty = service default)
+	Snoozes         []AlertSnooze `json:"snoozes,omitempty"`          // Temporarily suppressed symbols and rules
+	Priority        int           `json:"priority,omitempty"`         // Added to the rule priority of the user's alerts (-MaxUserPriority to MaxUserPriority)
+	UpdatedAt       time.Time     `json:"updated_at"`
+}
+
+// MaxUserPriority bounds the delivery priority users can add to their alerts
+const MaxUserPriority = 100
+
+// QuietHours is a daily time window, e.g. 22:00-07:00. Windows may span midnight.
+type QuietHours struct {
+	Start    string `json:"start"`              // HH:MM
+	End      string `json:"end"`                // HH:MM
+	Timezone string `json:"timezone,omitempty"` // IANA time zone (default: UTC)
+}
+
+// AlertSnooze suppresses a user's alerts for a symbol, a rule, or both until a time.
+// An empty Symbol or RuleID matches any symbol or rule.
+type AlertSnooze struct {
+	Symbol string    `json:"symbol,omitempty"`
+	RuleID string    `json:"rule_id,omitempty"`
+	Until  time.Time `json:"until"`
+}
+
+// parseClock parses an HH:MM time of day into minutes after midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid time of day %q (expected HH:MM)", ErrInvalidUserPreferences, value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Validate validates QuietHours
+func (q *QuietHours) Validate() error {
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return err
+	}
+	end, err := parseClock(q.End)
+	if err != nil {
+		return err
+	}
+	if start == end {
+		return fmt.Errorf("%w: quiet hours start and end must differ", ErrInvalidUserPreferences)
+	}
+	if _, err := time.LoadLocation(q.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown time zone %q", ErrInvalidUserPreferences, q.Timezone)
+	}
+	return nil
+}
+
+// Contains returns true if now falls inside the quiet hours window
+func (q *QuietHours) Contains(now time.Time) bool {
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(q.End)
+	if err != nil {
+		return false
+	}
+	if loc, err := time.LoadLocation(q.Timezone); err == nil {
+		now = now.In(loc)
+	}
+
+	minute := now.Hour()*60 + now.Minute()
+	if start < end {
+		return minute >= start && minute < end
+	}
+	// Window spans midnight
+	return minute >= start || minute < end
+}
+
+// Matches returns true if the snooze covers the alert at now
+func (s *AlertSnooze) Matches(alert *Alert, now time.Time) bool {
+	if !now.Before(s.Until) {
+		return false
+	}
+	if s.Symbol != "" && s.Symbol != alert.Symbol {
+		return false
+	}
+	return s.RuleID == "" || s.RuleID == alert.RuleID
+}
+
+// Validate validates UserPreferences
+func (p *UserPreferences) Validate() error {
+	if p.UserID == "" {
+		return fmt.Errorf("%w: user ID is required", ErrInvalidUserPreferences)
+	}
+
+	seen := make(map[string]bool, len(p.DefaultChannels))
+	for _, channel := range p.DefaultChannels {
+		if channel == "" || seen[channel] {
+			return fmt.Errorf("%w: invalid or duplicate default channel %q", ErrInvalidUserPreferences, channel)
+		}
+		seen[channel] = true
+	}
+
+	if p.QuietHours != nil {
+		if err := p.QuietHours.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for i, snooze := range p.Snoozes {
+		if snooze.Until.IsZero() {
+			return fmt.Errorf("%w: snooze %d requires an until time", ErrInvalidUserPreferences, i)
+		}
+	}
+
+	if p.Priority < -MaxUserPriority || p.Priority > MaxUserPriority {
+		return fmt.Errorf("%w: priority must be between %d and %d", ErrInvalidUserPreferences, -MaxUserPriority, MaxUserPriority)
+	}
+	return nil
+}
+
+// SuppressesAlert returns true if the user's quiet hours or snoozes suppress the alert at now.
+// Test alerts are never suppressed so users can always verify delivery.
+func (p *UserPreferences) SuppressesAlert(alert *Alert, now time.Time) bool {
+	if alert.IsTest() {
+		return false
+	}
+	if p.QuietHours != nil && p.QuietHours.Contains(now) {
+		return true
+	}
+	for i := range p.Snoozes {
+		if p.Snoozes[i].Matches(alert, now) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultDelivery returns the delivery policy implied by the user's default channels
+// (nil if none are set): the first channel is primary, the rest are fallbacks in order
+func (p *UserPreferences) DefaultDelivery() *DeliveryPolicy {
+	if len(p.DefaultChannels) == 0 {
+		return nil
+	}
+	return &DeliveryPolicy{
+		Mode:      DeliveryModePrimaryFallback,
+		Primary:   p.DefaultChannels[0],
+		Fallbacks: append([]string(nil), p.DefaultChannels[1:]...),
+	}
+}
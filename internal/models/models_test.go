@@ -185,6 +185,27 @@ func TestLiveBar_Update(t *testing.T) {
 	}
 }
 
+func TestLiveBar_UpdateVWAPWeighting(t *testing.T) {
+	lb := &LiveBar{Symbol: "AAPL", Timestamp: time.Now().Truncate(time.Minute)}
+
+	lb.Update(&Tick{Symbol: "AAPL", Price: 10.0, Size: 300, Timestamp: time.Now()})
+	lb.Update(&Tick{Symbol: "AAPL", Price: 20.0, Size: 100, Timestamp: time.Now()})
+	lb.Update(&Tick{Symbol: "AAPL", Price: 50.0, Size: 0, Timestamp: time.Now()})
+	lb.Update(&Tick{Symbol: "AAPL", Price: 60.0, Size: -100, Timestamp: time.Now()})
+	lb.UpdateExcludingVWAP(&Tick{Symbol: "AAPL", Price: 40.0, Size: 100, Timestamp: time.Now()})
+
+	// (10*300 + 20*100) / 400 = 12.5; zero/negative sizes and excluded ticks carry no weight
+	if vwap := lb.ToBar1m().VWAP; vwap != 12.5 {
+		t.Errorf("VWAP = %f, want 12.5", vwap)
+	}
+	if lb.Volume != 500 {
+		t.Errorf("Volume = %d, want 500", lb.Volume)
+	}
+	if lb.High != 60.0 || lb.Close != 40.0 {
+		t.Errorf("High=%f, Close=%f, want 60 and 40", lb.High, lb.Close)
+	}
+}
+
 func TestLiveBar_ToBar1m(t *testing.T) {
 	lb := &LiveBar{
 		Symbol:    "AAPL",
@@ -268,6 +289,16 @@ func TestRule_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "negative evaluation interval",
+			rule: &Rule{
+				ID:                 "rule-1",
+				Name:               "Test Rule",
+				Conditions:         []Condition{{Metric: "rsi_14", Operator: ">", Value: 70.0}},
+				EvaluationInterval: -5,
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -280,6 +311,69 @@ func TestRule_Validate(t *testing.T) {
 	}
 }
 
+func TestDedupKey_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     *DedupKey
+		wantErr bool
+	}{
+		{"default", DefaultDedupKey(), false},
+		{"per bar", &DedupKey{Fields: []string{DedupFieldRule, DedupFieldSymbol, DedupFieldBar}}, false},
+		{"metric bucket", &DedupKey{Fields: []string{DedupFieldRule, DedupFieldMetric}, Metric: "rsi_14", BucketSize: 5}, false},
+		{"no fields", &DedupKey{}, true},
+		{"unknown field", &DedupKey{Fields: []string{DedupFieldRule, "price"}}, true},
+		{"duplicate field", &DedupKey{Fields: []string{DedupFieldRule, DedupFieldRule}}, true},
+		{"metric without name", &DedupKey{Fields: []string{DedupFieldMetric}, BucketSize: 5}, true},
+		{"metric without bucket size", &DedupKey{Fields: []string{DedupFieldMetric}, Metric: "rsi_14"}, true},
+		{"metric name without field", &DedupKey{Fields: []string{DedupFieldRule}, Metric: "rsi_14", BucketSize: 5}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.key.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DedupKey.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	rule := &Rule{
+		ID:         "rule-1",
+		Name:       "Test Rule",
+		Conditions: []Condition{{Metric: "rsi_14", Operator: ">", Value: 70.0}},
+		DedupKey:   &DedupKey{Fields: []string{"unknown"}},
+	}
+	if err := rule.Validate(); err == nil {
+		t.Error("Expected rule with invalid dedup key to fail validation")
+	}
+}
+
+func TestDeliveryPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *DeliveryPolicy
+		wantErr bool
+	}{
+		{"all", &DeliveryPolicy{Mode: DeliveryModeAll}, false},
+		{"empty mode", &DeliveryPolicy{}, false},
+		{"primary only", &DeliveryPolicy{Mode: DeliveryModePrimaryFallback, Primary: "websocket"}, false},
+		{"primary with fallbacks", &DeliveryPolicy{Mode: DeliveryModePrimaryFallback, Primary: "webhook", Fallbacks: []string{"websocket"}}, false},
+		{"missing primary", &DeliveryPolicy{Mode: DeliveryModePrimaryFallback}, true},
+		{"primary repeated as fallback", &DeliveryPolicy{Mode: DeliveryModePrimaryFallback, Primary: "webhook", Fallbacks: []string{"webhook"}}, true},
+		{"primary in all mode", &DeliveryPolicy{Mode: DeliveryModeAll, Primary: "webhook"}, true},
+		{"unknown mode", &DeliveryPolicy{Mode: "round_robin"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeliveryPolicy.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestCondition_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -392,3 +486,190 @@ func TestAlert_Validate(t *testing.T) {
 	}
 }
 
+
+func TestCondition_Validate_HysteresisBand(t *testing.T) {
+	band := 1.5
+	negative := -1.0
+
+	tests := []struct {
+		name    string
+		cond    Condition
+		wantErr error
+	}{
+		{"ordered operator", Condition{Metric: "rsi_14", Operator: ">", Value: 70.0, HysteresisBand: &band}, nil},
+		{"zero band", Condition{Metric: "rsi_14", Operator: "<=", Value: 30.0, HysteresisBand: new(float64)}, nil},
+		{"negative band", Condition{Metric: "rsi_14", Operator: ">", Value: 70.0, HysteresisBand: &negative}, ErrInvalidHysteresisBand},
+		{"equality operator", Condition{Metric: "rsi_14", Operator: "==", Value: 70.0, HysteresisBand: &band}, ErrInvalidHysteresisBand},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cond.Validate(); err != tt.wantErr {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCondition_Validate_SeverityBands(t *testing.T) {
+	tests := []struct {
+		name    string
+		bands   SeverityBands
+		wantErr error
+	}{
+		{"ordered bands", SeverityBands{Warning: 5, Critical: 20}, nil},
+		{"equal bands", SeverityBands{Warning: 10, Critical: 10}, nil},
+		{"negative warning", SeverityBands{Warning: -1, Critical: 10}, ErrInvalidSeverityBands},
+		{"critical below warning", SeverityBands{Warning: 20, Critical: 10}, ErrInvalidSeverityBands},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond := Condition{Metric: "rsi_14", Operator: "<", Value: 30.0, SeverityBands: &tt.bands}
+			if err := cond.Validate(); err != tt.wantErr {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserPreferences_SuppressesAlert(t *testing.T) {
+	prefs := &UserPreferences{
+		UserID:     "user-1",
+		QuietHours: &QuietHours{Start: "22:00", End: "07:00", Timezone: "UTC"},
+		Snoozes: []AlertSnooze{
+			{Symbol: "AAPL", Until: time.Date(2024, 3, 15, 16, 0, 0, 0, time.UTC)},
+			{RuleID: "rule-2", Until: time.Date(2024, 3, 15, 16, 0, 0, 0, time.UTC)},
+		},
+	}
+	afternoon := time.Date(2024, 3, 15, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		alert *Alert
+		now   time.Time
+		want  bool
+	}{
+		{"unmatched alert", &Alert{Symbol: "MSFT", RuleID: "rule-1"}, afternoon, false},
+		{"snoozed symbol", &Alert{Symbol: "AAPL", RuleID: "rule-1"}, afternoon, true},
+		{"snoozed rule", &Alert{Symbol: "MSFT", RuleID: "rule-2"}, afternoon, true},
+		{"snooze expired", &Alert{Symbol: "AAPL", RuleID: "rule-1"}, afternoon.Add(2 * time.Hour), false},
+		{"quiet hours before midnight", &Alert{Symbol: "MSFT", RuleID: "rule-1"}, time.Date(2024, 3, 15, 23, 0, 0, 0, time.UTC), true},
+		{"quiet hours after midnight", &Alert{Symbol: "MSFT", RuleID: "rule-1"}, time.Date(2024, 3, 16, 6, 59, 0, 0, time.UTC), true},
+		{"quiet hours end", &Alert{Symbol: "MSFT", RuleID: "rule-1"}, time.Date(2024, 3, 16, 7, 0, 0, 0, time.UTC), false},
+		{"test alert", &Alert{Symbol: "AAPL", RuleID: "test", Metadata: map[string]interface{}{AlertMetadataTest: true}}, afternoon, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := prefs.SuppressesAlert(tt.alert, tt.now); got != tt.want {
+				t.Errorf("SuppressesAlert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietHours_Timezone(t *testing.T) {
+	quiet := &QuietHours{Start: "09:00", End: "17:00", Timezone: "America/New_York"}
+
+	// 14:00 UTC is 10:00 in New York (EDT)
+	if !quiet.Contains(time.Date(2024, 6, 3, 14, 0, 0, 0, time.UTC)) {
+		t.Error("Expected 10:00 New York time to be inside quiet hours")
+	}
+	if quiet.Contains(time.Date(2024, 6, 3, 22, 0, 0, 0, time.UTC)) {
+		t.Error("Expected 18:00 New York time to be outside quiet hours")
+	}
+}
+
+func TestUserPreferences_Validate(t *testing.T) {
+	until := time.Date(2024, 3, 15, 16, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		prefs   *UserPreferences
+		wantErr bool
+	}{
+		{"empty", &UserPreferences{UserID: "user-1"}, false},
+		{"full", &UserPreferences{
+			UserID:          "user-1",
+			DefaultChannels: []string{"websocket", "kafka"},
+			QuietHours:      &QuietHours{Start: "22:00", End: "07:00", Timezone: "Europe/Berlin"},
+			Snoozes:         []AlertSnooze{{Symbol: "AAPL", Until: until}},
+			Priority:        -MaxUserPriority,
+		}, false},
+		{"missing user", &UserPreferences{}, true},
+		{"priority out of range", &UserPreferences{UserID: "user-1", Priority: MaxUserPriority + 1}, true},
+		{"duplicate channel", &UserPreferences{UserID: "user-1", DefaultChannels: []string{"kafka", "kafka"}}, true},
+		{"invalid quiet hours", &UserPreferences{UserID: "user-1", QuietHours: &QuietHours{Start: "10pm", End: "07:00"}}, true},
+		{"empty quiet hours window", &UserPreferences{UserID: "user-1", QuietHours: &QuietHours{Start: "07:00", End: "07:00"}}, true},
+		{"unknown time zone", &UserPreferences{UserID: "user-1", QuietHours: &QuietHours{Start: "22:00", End: "07:00", Timezone: "Mars/Olympus"}}, true},
+		{"snooze without until", &UserPreferences{UserID: "user-1", Snoozes: []AlertSnooze{{Symbol: "AAPL"}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.prefs.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UserPreferences.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAlertRedactor_Redact(t *testing.T) {
+	alert := &Alert{
+		ID:      "alert-1",
+		RuleID:  "rule-1",
+		Symbol:  "AAPL",
+		TraceID: "trace-1",
+		Metadata: map[string]interface{}{
+			"metrics":           map[string]float64{"price": 150.0},
+			"conditions":        []string{"price > 100"},
+			AlertMetadataUserID: "user-1",
+			"alert_type":        AlertTypeExit,
+		},
+	}
+	redactor := NewAlertRedactor(nil)
+
+	if full := redactor.Redact(alert, AlertScopeFull); full != alert {
+		t.Error("Expected full scope to receive the alert unchanged")
+	}
+
+	for _, scope := range []string{AlertScopeRestricted, "", "unknown"} {
+		redacted := redactor.Redact(alert, scope)
+		if redacted == alert {
+			t.Fatalf("Expected scope %q to receive a redacted copy", scope)
+		}
+		for _, field := range []string{"metrics", "conditions", AlertMetadataUserID} {
+			if _, ok := redacted.Metadata[field]; ok {
+				t.Errorf("Expected %q to be redacted for scope %q", field, scope)
+			}
+		}
+		if redacted.Metadata["alert_type"] != AlertTypeExit {
+			t.Errorf("Expected non-sensitive metadata to be kept for scope %q", scope)
+		}
+		if redacted.TraceID != "" {
+			t.Errorf("Expected trace ID to be redacted for scope %q", scope)
+		}
+		if redacted.Symbol != "AAPL" {
+			t.Errorf("Expected alert fields to be kept for scope %q", scope)
+		}
+	}
+
+	// The original alert is never modified
+	if _, ok := alert.Metadata["metrics"]; !ok || alert.TraceID != "trace-1" {
+		t.Error("Expected original alert to keep its metadata")
+	}
+
+	custom := NewAlertRedactor([]string{"alert_type"}).Redact(alert, AlertScopeRestricted)
+	if _, ok := custom.Metadata["alert_type"]; ok {
+		t.Error("Expected configured field to be redacted")
+	}
+	if _, ok := custom.Metadata["metrics"]; !ok {
+		t.Error("Expected fields outside the configured list to be kept")
+	}
+
+	var nilRedactor *AlertRedactor
+	if nilRedactor.Redact(alert, AlertScopeRestricted) != alert {
+		t.Error("Expected nil redactor not to redact")
+	}
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"math"
 	"time"
 )
 
@@ -14,6 +15,7 @@ const (
 	MetricRSI            ToplistMetric = "rsi"
 	MetricRelativeVolume ToplistMetric = "relative_volume"
 	MetricVWAPDist       ToplistMetric = "vwap_dist"
+	MetricCustom         ToplistMetric = "custom" // User-defined custom metric (see CustomMetric)
 )
 
 // ToplistTimeWindow represents the time window for metric calculation
@@ -27,6 +29,31 @@ const (
 	Window1d  ToplistTimeWindow = "1d"
 )
 
+// ToplistChangeUnit selects how change-based toplists measure price change
+type ToplistChangeUnit string
+
+const (
+	ChangeUnitPercent ToplistChangeUnit = "pct"    // Percentage change (default)
+	ChangeUnitPoints  ToplistChangeUnit = "points" // Absolute price change in dollars
+)
+
+// ToplistNormalization selects how metric values are normalized before ranking
+type ToplistNormalization string
+
+const (
+	NormalizationNone       ToplistNormalization = "none"       // Rank by raw metric values (default)
+	NormalizationZScore     ToplistNormalization = "zscore"     // Standard deviations from the symbol's recent mean
+	NormalizationPercentile ToplistNormalization = "percentile" // Percentile (0-100) within the symbol's recent values
+)
+
+// ToplistTieBreaker names a secondary sort key applied when ranking scores tie.
+// Any non-custom metric can be used (higher values rank first); "symbol" sorts alphabetically.
+type ToplistTieBreaker string
+
+const (
+	TieBreakSymbol ToplistTieBreaker = "symbol" // Alphabetical by symbol
+)
+
 // ToplistSortOrder represents the sort order for rankings
 type ToplistSortOrder string
 
@@ -47,6 +74,13 @@ type ToplistFilter struct {
 	MarketCapMax *int64 `json:"market_cap_max,omitempty"`
 }
 
+// ToplistWindowThreshold is one window of a compound toplist: the toplist metric over Window
+// must exceed Threshold (be above it for descending toplists, below it for ascending ones)
+type ToplistWindowThreshold struct {
+	Window    ToplistTimeWindow `json:"window"`
+	Threshold float64           `json:"threshold"`
+}
+
 // ToplistColorScheme represents color coding configuration
 type ToplistColorScheme struct {
 	Positive string `json:"positive,omitempty"` // Color for positive values (e.g., "#00ff00")
@@ -61,11 +95,17 @@ type ToplistConfig struct {
 	Name        string             `json:"name"`
 	Description string             `json:"description,omitempty"`
 	Metric      ToplistMetric      `json:"metric"`
+	CustomMetric string            `json:"custom_metric,omitempty"` // Custom metric name when Metric is "custom"
+	ChangeUnit  ToplistChangeUnit   `json:"change_unit,omitempty"` // "pct" (default) or "points" for change_pct toplists
+	Normalization ToplistNormalization `json:"normalization,omitempty"` // "none" (default), "zscore" or "percentile"
+	TieBreakers []ToplistTieBreaker `json:"tie_breakers,omitempty"` // Secondary sort keys for tied scores, e.g. ["volume", "symbol"]
+	CompoundWindows []ToplistWindowThreshold `json:"compound_windows,omitempty"` // Windows the metric must exceed its threshold in simultaneously, e.g. sustained gainers over 1m and 5m
 	TimeWindow  ToplistTimeWindow   `json:"time_window"`
 	SortOrder   ToplistSortOrder    `json:"sort_order"`
 	Filters     *ToplistFilter      `json:"filters,omitempty"`
 	Columns     []string            `json:"columns,omitempty"` // Display columns
 	ColorScheme *ToplistColorScheme `json:"color_scheme,omitempty"`
+	MaxSize     int                 `json:"max_size,omitempty"` // Max entries kept in the ZSET (0 = service default)
 	Enabled     bool                `json:"enabled"`
 	CreatedAt   time.Time           `json:"created_at"`
 	UpdatedAt   time.Time           `json:"updated_at"`
@@ -96,10 +136,39 @@ func (tc *ToplistConfig) Validate() error {
 		MetricRSI:            true,
 		MetricRelativeVolume: true,
 		MetricVWAPDist:       true,
+		MetricCustom:         true,
 	}
 	if !validMetrics[tc.Metric] {
 		return ErrInvalidToplistMetric
 	}
+	if tc.Metric == MetricCustom && (tc.CustomMetric == "" || tc.IsSystemToplist()) {
+		return ErrInvalidToplistCustomMetric
+	}
+	if tc.ChangeUnit != "" {
+		if tc.ChangeUnit != ChangeUnitPercent && tc.ChangeUnit != ChangeUnitPoints {
+			return ErrInvalidToplistChangeUnit
+		}
+		if tc.Metric != MetricChangePct {
+			return ErrInvalidToplistChangeUnit
+		}
+	}
+	switch tc.Normalization {
+	case "", NormalizationNone, NormalizationZScore, NormalizationPercentile:
+	default:
+		return ErrInvalidToplistNormalization
+	}
+	for _, tb := range tc.TieBreakers {
+		if tb == TieBreakSymbol {
+			continue
+		}
+		if metric := ToplistMetric(tb); metric == MetricCustom || !validMetrics[metric] {
+			return ErrInvalidToplistTieBreaker
+		}
+	}
+	
+	if err := tc.validateCompoundWindows(); err != nil {
+		return err
+	}
 	
 	// Validate time window
 	validWindows := map[ToplistTimeWindow]bool{
@@ -121,15 +190,58 @@ func (tc *ToplistConfig) Validate() error {
 	if !validSortOrders[tc.SortOrder] {
 		return ErrInvalidToplistSortOrder
 	}
+
+	if tc.MaxSize < 0 {
+		return ErrInvalidToplistMaxSize
+	}
 	
 	return nil
 }
 
+// validateCompoundWindows validates the windows of a compound toplist
+func (tc *ToplistConfig) validateCompoundWindows() error {
+	if len(tc.CompoundWindows) == 0 {
+		return nil
+	}
+	if len(tc.CompoundWindows) < 2 || tc.IsSystemToplist() || tc.Metric == MetricCustom {
+		return ErrInvalidToplistCompoundWindows
+	}
+
+	seen := make(map[ToplistTimeWindow]bool, len(tc.CompoundWindows))
+	for _, w := range tc.CompoundWindows {
+		switch w.Window {
+		case Window1m, Window5m, Window15m, Window1h, Window1d:
+		default:
+			return ErrInvalidToplistCompoundWindows
+		}
+		if seen[w.Window] || math.IsNaN(w.Threshold) || math.IsInf(w.Threshold, 0) {
+			return ErrInvalidToplistCompoundWindows
+		}
+		seen[w.Window] = true
+	}
+	return nil
+}
+
 // IsSystemToplist returns true if this is a system toplist (no user_id)
 func (tc *ToplistConfig) IsSystemToplist() bool {
 	return tc.UserID == ""
 }
 
+// UsesPoints returns true if this is a change toplist ranked by absolute price change
+func (tc *ToplistConfig) UsesPoints() bool {
+	return tc.Metric == MetricChangePct && tc.ChangeUnit == ChangeUnitPoints
+}
+
+// IsCompound returns true if symbols must exceed thresholds across several windows to be ranked
+func (tc *ToplistConfig) IsCompound() bool {
+	return len(tc.CompoundWindows) > 0
+}
+
+// IsNormalized returns true if metric values are normalized before ranking
+func (tc *ToplistConfig) IsNormalized() bool {
+	return tc.Normalization != "" && tc.Normalization != NormalizationNone
+}
+
 // ToplistRanking represents a single symbol ranking entry
 type ToplistRanking struct {
 	Symbol   string                 `json:"symbol"`
@@ -160,6 +272,22 @@ func (tu *ToplistUpdate) Validate() error {
 	return nil
 }
 
+// RedisKey returns the Redis key holding this toplist's rankings
+// System points and normalized toplists get their own keys so they don't share a ZSET with the raw variant
+func (tc *ToplistConfig) RedisKey() string {
+	if !tc.IsSystemToplist() {
+		return GetUserToplistRedisKey(tc.UserID, tc.ID)
+	}
+	key := GetSystemToplistRedisKey(tc.Metric, tc.TimeWindow)
+	if tc.UsesPoints() {
+		key = GetSystemToplistRedisKey("change_points", tc.TimeWindow)
+	}
+	if tc.IsNormalized() {
+		key += ":" + string(tc.Normalization)
+	}
+	return key
+}
+
 // GetRedisKey returns the Redis key for a system toplist
 func GetSystemToplistRedisKey(metric ToplistMetric, window ToplistTimeWindow) string {
 	return "toplist:" + string(metric) + ":" + string(window)
@@ -51,6 +51,45 @@ func TestToplistConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errType: ErrInvalidToplistName,
 		},
+		{
+			name: "custom metric",
+			config: &ToplistConfig{
+				ID:           "test-1",
+				UserID:       "user-123",
+				Name:         "Test Toplist",
+				Metric:       MetricCustom,
+				CustomMetric: "range_pct",
+				TimeWindow:   Window5m,
+				SortOrder:    SortOrderDesc,
+			},
+			wantErr: false,
+		},
+		{
+			name: "custom metric without name",
+			config: &ToplistConfig{
+				ID:         "test-1",
+				UserID:     "user-123",
+				Name:       "Test Toplist",
+				Metric:     MetricCustom,
+				TimeWindow: Window5m,
+				SortOrder:  SortOrderDesc,
+			},
+			wantErr: true,
+			errType: ErrInvalidToplistCustomMetric,
+		},
+		{
+			name: "custom metric on system toplist",
+			config: &ToplistConfig{
+				ID:           "test-1",
+				Name:         "Test Toplist",
+				Metric:       MetricCustom,
+				CustomMetric: "range_pct",
+				TimeWindow:   Window5m,
+				SortOrder:    SortOrderDesc,
+			},
+			wantErr: true,
+			errType: ErrInvalidToplistCustomMetric,
+		},
 		{
 			name: "invalid metric",
 			config: &ToplistConfig{
@@ -90,6 +129,164 @@ func TestToplistConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errType: ErrInvalidToplistSortOrder,
 		},
+		{
+			name: "points change unit",
+			config: &ToplistConfig{
+				ID:         "test-1",
+				UserID:     "user-123",
+				Name:       "Dollar Movers",
+				Metric:     MetricChangePct,
+				ChangeUnit: ChangeUnitPoints,
+				TimeWindow: Window5m,
+				SortOrder:  SortOrderDesc,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid change unit",
+			config: &ToplistConfig{
+				ID:         "test-1",
+				UserID:     "user-123",
+				Name:       "Test Toplist",
+				Metric:     MetricChangePct,
+				ChangeUnit: ToplistChangeUnit("bps"),
+				TimeWindow: Window5m,
+				SortOrder:  SortOrderDesc,
+			},
+			wantErr: true,
+			errType: ErrInvalidToplistChangeUnit,
+		},
+		{
+			name: "change unit on non-change metric",
+			config: &ToplistConfig{
+				ID:         "test-1",
+				UserID:     "user-123",
+				Name:       "Test Toplist",
+				Metric:     MetricVolume,
+				ChangeUnit: ChangeUnitPoints,
+				TimeWindow: Window5m,
+				SortOrder:  SortOrderDesc,
+			},
+			wantErr: true,
+			errType: ErrInvalidToplistChangeUnit,
+		},
+		{
+			name: "valid normalization",
+			config: &ToplistConfig{
+				ID:            "test-1",
+				UserID:        "user-123",
+				Name:          "Test Toplist",
+				Metric:        MetricChangePct,
+				Normalization: NormalizationZScore,
+				TimeWindow:    Window5m,
+				SortOrder:     SortOrderDesc,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid normalization",
+			config: &ToplistConfig{
+				ID:            "test-1",
+				UserID:        "user-123",
+				Name:          "Test Toplist",
+				Metric:        MetricChangePct,
+				Normalization: ToplistNormalization("minmax"),
+				TimeWindow:    Window5m,
+				SortOrder:     SortOrderDesc,
+			},
+			wantErr: true,
+			errType: ErrInvalidToplistNormalization,
+		},
+		{
+			name: "valid tie breakers",
+			config: &ToplistConfig{
+				ID:          "test-1",
+				UserID:      "user-123",
+				Name:        "Test Toplist",
+				Metric:      MetricChangePct,
+				TieBreakers: []ToplistTieBreaker{"volume", TieBreakSymbol},
+				TimeWindow:  Window5m,
+				SortOrder:   SortOrderDesc,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid tie breaker",
+			config: &ToplistConfig{
+				ID:          "test-1",
+				UserID:      "user-123",
+				Name:        "Test Toplist",
+				Metric:      MetricChangePct,
+				TieBreakers: []ToplistTieBreaker{"custom"},
+				TimeWindow:  Window5m,
+				SortOrder:   SortOrderDesc,
+			},
+			wantErr: true,
+			errType: ErrInvalidToplistTieBreaker,
+		},
+		{
+			name: "valid compound windows",
+			config: &ToplistConfig{
+				ID:         "test-1",
+				UserID:     "user-123",
+				Name:       "Sustained Gainers",
+				Metric:     MetricChangePct,
+				TimeWindow: Window1m,
+				SortOrder:  SortOrderDesc,
+				CompoundWindows: []ToplistWindowThreshold{
+					{Window: Window1m, Threshold: 1},
+					{Window: Window5m, Threshold: 2},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "single compound window",
+			config: &ToplistConfig{
+				ID:              "test-1",
+				UserID:          "user-123",
+				Name:            "Sustained Gainers",
+				Metric:          MetricChangePct,
+				TimeWindow:      Window1m,
+				SortOrder:       SortOrderDesc,
+				CompoundWindows: []ToplistWindowThreshold{{Window: Window1m, Threshold: 1}},
+			},
+			wantErr: true,
+			errType: ErrInvalidToplistCompoundWindows,
+		},
+		{
+			name: "duplicate compound window",
+			config: &ToplistConfig{
+				ID:         "test-1",
+				UserID:     "user-123",
+				Name:       "Sustained Gainers",
+				Metric:     MetricChangePct,
+				TimeWindow: Window1m,
+				SortOrder:  SortOrderDesc,
+				CompoundWindows: []ToplistWindowThreshold{
+					{Window: Window1m, Threshold: 1},
+					{Window: Window1m, Threshold: 2},
+				},
+			},
+			wantErr: true,
+			errType: ErrInvalidToplistCompoundWindows,
+		},
+		{
+			name: "compound windows on system toplist",
+			config: &ToplistConfig{
+				ID:         "system-sustained",
+				Name:       "Sustained Gainers",
+				Metric:     MetricChangePct,
+				TimeWindow: Window1m,
+				SortOrder:  SortOrderDesc,
+				CompoundWindows: []ToplistWindowThreshold{
+					{Window: Window1m, Threshold: 1},
+					{Window: Window5m, Threshold: 2},
+				},
+			},
+			wantErr: true,
+			errType: ErrInvalidToplistCompoundWindows,
+		},
 		{
 			name: "system toplist (no user_id)",
 			config: &ToplistConfig{
@@ -331,3 +528,22 @@ func TestToplistConfig_ToJSON(t *testing.T) {
 	}
 }
 
+func TestToplistConfig_RedisKey_Normalization(t *testing.T) {
+	raw := &ToplistConfig{Metric: MetricChangePct, TimeWindow: Window5m}
+	zscore := &ToplistConfig{Metric: MetricChangePct, TimeWindow: Window5m, Normalization: NormalizationZScore}
+	none := &ToplistConfig{Metric: MetricChangePct, TimeWindow: Window5m, Normalization: NormalizationNone}
+	user := &ToplistConfig{UserID: "user-1", ID: "toplist-1", Metric: MetricChangePct, TimeWindow: Window5m, Normalization: NormalizationPercentile}
+
+	if got := raw.RedisKey(); got != "toplist:change_pct:5m" {
+		t.Errorf("raw RedisKey() = %v", got)
+	}
+	if got := zscore.RedisKey(); got != "toplist:change_pct:5m:zscore" {
+		t.Errorf("zscore RedisKey() = %v", got)
+	}
+	if got := none.RedisKey(); got != raw.RedisKey() {
+		t.Errorf("Expected explicit none normalization to share the raw key, got %v", got)
+	}
+	if got := user.RedisKey(); got != "toplist:user:user-1:toplist-1" {
+		t.Errorf("user RedisKey() = %v", got)
+	}
+}
@@ -0,0 +1,260 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// ReconnectConfig controls how Redis commands are retried after connection errors
+type ReconnectConfig struct {
+	InitialBackoff time.Duration // Delay before the first retry
+	MaxBackoff     time.Duration // Upper bound for a single delay
+	Jitter         float64       // Fraction of each delay randomized in both directions (0-1)
+	MaxRetries     int           // Retries per command before giving up (0 = until the deadline)
+	Deadline       time.Duration // Total time spent retrying a command (0 = until max retries)
+}
+
+// DefaultReconnectConfig returns the default reconnection strategy
+func DefaultReconnectConfig() ReconnectConfig {
+	return ReconnectConfig{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Jitter:         0.2,
+		MaxRetries:     5,
+		Deadline:       30 * time.Second,
+	}
+}
+
+// ReconnectConfigFromRedis builds a reconnect config from the Redis configuration,
+// falling back to the defaults when none of the reconnect settings are set
+func ReconnectConfigFromRedis(cfg config.RedisConfig) ReconnectConfig {
+	rc := ReconnectConfig{
+		InitialBackoff: cfg.ReconnectInitialBackoff,
+		MaxBackoff:     cfg.ReconnectMaxBackoff,
+		Jitter:         cfg.ReconnectJitter,
+		MaxRetries:     cfg.ReconnectMaxRetries,
+		Deadline:       cfg.ReconnectDeadline,
+	}
+	if rc == (ReconnectConfig{}) {
+		return DefaultReconnectConfig()
+	}
+	if rc.InitialBackoff <= 0 {
+		rc.InitialBackoff = DefaultReconnectConfig().InitialBackoff
+	}
+	return rc
+}
+
+// RedisHealth is a snapshot of the Redis connection health
+type RedisHealth struct {
+	Degraded      bool      `json:"degraded"`
+	DegradedAt    time.Time `json:"degraded_at,omitempty"`
+	LastError     string    `json:"last_error,omitempty"`
+	Reconnects    int64     `json:"reconnects"`     // Recoveries after a degraded period
+	RetryAttempts int64     `json:"retry_attempts"` // Commands retried after connection errors
+}
+
+// Reconnector retries Redis operations with exponential backoff and jitter,
+// and tracks whether the connection is currently degraded
+type Reconnector struct {
+	config ReconnectConfig
+	health RedisHealth
+	mu     sync.Mutex
+	rand   func() float64
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// NewReconnector creates a reconnector with the given strategy
+func NewReconnector(cfg ReconnectConfig) *Reconnector {
+	return &Reconnector{
+		config: cfg,
+		rand:   rand.Float64,
+		sleep:  sleepContext,
+	}
+}
+
+// Do runs an idempotent op, retrying with backoff while it fails with connection errors.
+// Other errors are returned immediately.
+func (r *Reconnector) Do(ctx context.Context, op func() error) error {
+	return r.do(ctx, op, IsConnectionError)
+}
+
+// DoNonIdempotent runs an op that must not run twice, such as XADD or PUBLISH. It is only
+// retried after errors showing the command never reached Redis (see IsUnsentError): after a
+// timeout or a reset connection Redis may have executed it, and a retry would duplicate it.
+func (r *Reconnector) DoNonIdempotent(ctx context.Context, op func() error) error {
+	return r.do(ctx, op, IsUnsentError)
+}
+
+// do runs op, retrying with backoff while it fails with errors retryable accepts
+func (r *Reconnector) do(ctx context.Context, op func() error, retryable func(error) bool) error {
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		err := op()
+		if err == nil || !IsConnectionError(err) {
+			if err == nil {
+				r.MarkHealthy()
+			}
+			return err
+		}
+
+		r.MarkDegraded(err)
+		if !retryable(err) {
+			return err
+		}
+
+		if r.config.MaxRetries > 0 && attempt >= r.config.MaxRetries {
+			return fmt.Errorf("redis unavailable after %d retries: %w", attempt, err)
+		}
+		delay := r.Backoff(attempt)
+		if r.config.Deadline > 0 && time.Since(start)+delay > r.config.Deadline {
+			return fmt.Errorf("redis unavailable after %s: %w", r.config.Deadline, err)
+		}
+
+		r.mu.Lock()
+		r.health.RetryAttempts++
+		r.mu.Unlock()
+
+		if sleepErr := r.sleep(ctx, delay); sleepErr != nil {
+			return err
+		}
+	}
+}
+
+// Backoff returns the delay before retry number attempt (0-based)
+func (r *Reconnector) Backoff(attempt int) time.Duration {
+	delay := r.config.InitialBackoff
+	for i := 0; i < attempt && delay < r.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if r.config.MaxBackoff > 0 && delay > r.config.MaxBackoff {
+		delay = r.config.MaxBackoff
+	}
+	if r.config.Jitter > 0 {
+		// Spread the delay uniformly over [delay*(1-jitter), delay*(1+jitter)]
+		delay = time.Duration(float64(delay) * (1 + r.config.Jitter*(2*r.rand()-1)))
+	}
+	return delay
+}
+
+// MarkDegraded records a connection error
+func (r *Reconnector) MarkDegraded(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.health.Degraded {
+		r.health.Degraded = true
+		r.health.DegradedAt = time.Now()
+		logger.Warn("Redis connection degraded",
+			logger.ErrorField(err),
+		)
+	}
+	r.health.LastError = err.Error()
+}
+
+// MarkHealthy records a successful command, ending any degraded period
+func (r *Reconnector) MarkHealthy() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.health.Degraded {
+		return
+	}
+	logger.Info("Redis connection recovered",
+		logger.Duration("degraded_for", time.Since(r.health.DegradedAt)),
+	)
+	r.health.Degraded = false
+	r.health.DegradedAt = time.Time{}
+	r.health.Reconnects++
+}
+
+// Health returns the current connection health
+func (r *Reconnector) Health() RedisHealth {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.health
+}
+
+// IsConnectionError reports whether err indicates Redis is unreachable, as
+// opposed to a command error that retrying won't fix
+func IsConnectionError(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, redis.ErrClosed) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, redis.ErrPoolTimeout) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "connection refused") ||
+		strings.Contains(msg, "connection reset") ||
+		strings.Contains(msg, "broken pipe") ||
+		strings.HasPrefix(msg, "LOADING")
+}
+
+// IsUnsentError reports whether err shows a command never reached Redis, so that even a
+// non-idempotent command can be retried: the connection could not be established (refused or
+// failed dial), no pooled connection was available, or Redis rejected it while loading
+func IsUnsentError(err error) bool {
+	if !IsConnectionError(err) {
+		return false
+	}
+	if errors.Is(err, redis.ErrPoolTimeout) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "connection refused") || strings.HasPrefix(msg, "LOADING")
+}
+
+// RedisHealthCheck returns a health check entry for the client, reporting
+// "degraded" while Redis commands are failing with connection errors
+func RedisHealthCheck(client storage.RedisClient) map[string]interface{} {
+	impl, ok := client.(*RedisClientImpl)
+	if !ok {
+		return map[string]interface{}{"status": "ok"}
+	}
+	health := impl.Health()
+	status := "ok"
+	if health.Degraded {
+		status = "degraded"
+	}
+	return map[string]interface{}{
+		"status": status,
+		"health": health,
+	}
+}
+
+// sleepContext sleeps for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,230 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyRedis simulates a Redis server that refuses connections until it has restarted
+type flakyRedis struct {
+	failuresLeft int
+	calls        int
+}
+
+func (f *flakyRedis) Ping() error {
+	f.calls++
+	if f.failuresLeft > 0 {
+		f.failuresLeft--
+		return &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	}
+	return nil
+}
+
+// newTestReconnector returns a reconnector without jitter that records delays instead of sleeping
+func newTestReconnector(cfg ReconnectConfig) (*Reconnector, *[]time.Duration) {
+	var delays []time.Duration
+	r := NewReconnector(cfg)
+	r.rand = func() float64 { return 0.5 }
+	r.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return ctx.Err()
+	}
+	return r, &delays
+}
+
+func TestReconnector_RecoversAfterFailures(t *testing.T) {
+	r, delays := newTestReconnector(ReconnectConfig{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Jitter:         0.2,
+		MaxRetries:     5,
+	})
+	server := &flakyRedis{failuresLeft: 3}
+
+	var degradedDuringOutage bool
+	err := r.Do(context.Background(), func() error {
+		if server.calls > 0 {
+			degradedDuringOutage = degradedDuringOutage || r.Health().Degraded
+		}
+		return server.Ping()
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, server.calls)
+	assert.True(t, degradedDuringOutage, "health should report degraded while retrying")
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond}, *delays)
+
+	health := r.Health()
+	assert.False(t, health.Degraded)
+	assert.Equal(t, int64(1), health.Reconnects)
+	assert.Equal(t, int64(3), health.RetryAttempts)
+}
+
+func TestReconnector_GivesUpAfterMaxRetries(t *testing.T) {
+	r, delays := newTestReconnector(ReconnectConfig{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		MaxRetries:     2,
+	})
+	server := &flakyRedis{failuresLeft: 10}
+
+	err := r.Do(context.Background(), server.Ping)
+
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, syscall.ECONNREFUSED))
+	assert.Equal(t, 3, server.calls)
+	assert.Len(t, *delays, 2)
+	assert.True(t, r.Health().Degraded)
+}
+
+func TestReconnector_GivesUpAtDeadline(t *testing.T) {
+	r, delays := newTestReconnector(ReconnectConfig{
+		InitialBackoff: 400 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Deadline:       500 * time.Millisecond,
+	})
+	server := &flakyRedis{failuresLeft: 10}
+
+	err := r.Do(context.Background(), server.Ping)
+
+	require.Error(t, err)
+	// The first 400ms delay fits the deadline, the following 800ms one doesn't
+	assert.Equal(t, []time.Duration{400 * time.Millisecond}, *delays)
+	assert.Equal(t, 2, server.calls)
+}
+
+func TestReconnector_DoesNotRetryCommandErrors(t *testing.T) {
+	r, delays := newTestReconnector(DefaultReconnectConfig())
+
+	calls := 0
+	err := r.Do(context.Background(), func() error {
+		calls++
+		return redis.Nil
+	})
+
+	assert.Equal(t, redis.Nil, err)
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, *delays)
+	assert.False(t, r.Health().Degraded)
+}
+
+func TestReconnector_DoNonIdempotent(t *testing.T) {
+	// Refused connections never reached Redis, so the write is retried
+	r, _ := newTestReconnector(DefaultReconnectConfig())
+	server := &flakyRedis{failuresLeft: 2}
+	require.NoError(t, r.DoNonIdempotent(context.Background(), server.Ping))
+	assert.Equal(t, 3, server.calls)
+
+	// A reset connection may have executed the write, so it is not retried
+	r, delays := newTestReconnector(DefaultReconnectConfig())
+	calls := 0
+	err := r.DoNonIdempotent(context.Background(), func() error {
+		calls++
+		return fmt.Errorf("write: %w", syscall.ECONNRESET)
+	})
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, *delays)
+	assert.True(t, r.Health().Degraded)
+}
+
+func TestReconnector_StopsWhenContextCancelled(t *testing.T) {
+	r, _ := newTestReconnector(DefaultReconnectConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	server := &flakyRedis{failuresLeft: 10}
+
+	err := r.Do(ctx, server.Ping)
+
+	require.Error(t, err)
+	assert.Equal(t, 1, server.calls)
+}
+
+func TestReconnector_BackoffCapAndJitter(t *testing.T) {
+	r := NewReconnector(ReconnectConfig{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Jitter:         0.2,
+	})
+
+	r.rand = func() float64 { return 0.5 }
+	assert.Equal(t, 100*time.Millisecond, r.Backoff(0))
+	assert.Equal(t, 800*time.Millisecond, r.Backoff(3))
+	assert.Equal(t, time.Second, r.Backoff(4))
+	assert.Equal(t, time.Second, r.Backoff(50))
+
+	r.rand = func() float64 { return 0 }
+	assert.Equal(t, 80*time.Millisecond, r.Backoff(0))
+	r.rand = func() float64 { return 1 }
+	assert.Equal(t, 120*time.Millisecond, r.Backoff(0))
+}
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{redis.Nil, false},
+		{redis.ErrClosed, false},
+		{context.Canceled, false},
+		{errors.New("WRONGTYPE Operation against a key holding the wrong kind of value"), false},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, true},
+		{fmt.Errorf("failed to publish: %w", syscall.ECONNRESET), true},
+		{redis.ErrPoolTimeout, true},
+		{errors.New("LOADING Redis is loading the dataset in memory"), true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsConnectionError(tt.err), "IsConnectionError(%v)", tt.err)
+	}
+}
+
+func TestIsUnsentError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{redis.Nil, false},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, true},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("i/o timeout")}, true},
+		{redis.ErrPoolTimeout, true},
+		{errors.New("LOADING Redis is loading the dataset in memory"), true},
+		{fmt.Errorf("failed to publish: %w", syscall.ECONNRESET), false},
+		{&net.OpError{Op: "read", Net: "tcp", Err: errors.New("i/o timeout")}, false},
+		{errors.New("unexpected EOF"), false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsUnsentError(tt.err), "IsUnsentError(%v)", tt.err)
+	}
+}
+
+func TestReconnectConfigFromRedis(t *testing.T) {
+	assert.Equal(t, DefaultReconnectConfig(), ReconnectConfigFromRedis(config.RedisConfig{}))
+
+	rc := ReconnectConfigFromRedis(config.RedisConfig{
+		ReconnectMaxBackoff: 5 * time.Second,
+		ReconnectMaxRetries: 3,
+	})
+	assert.Equal(t, 100*time.Millisecond, rc.InitialBackoff)
+	assert.Equal(t, 5*time.Second, rc.MaxBackoff)
+	assert.Equal(t, 3, rc.MaxRetries)
+}
+
+func TestRedisHealthCheck_NonRedisClient(t *testing.T) {
+	check := RedisHealthCheck(storage.NewMockRedisClient())
+	assert.Equal(t, "ok", check["status"])
+}
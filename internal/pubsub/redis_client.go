@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,7 +17,8 @@ import (
 
 // RedisClientImpl implements the storage.RedisClient interface
 type RedisClientImpl struct {
-	client *redis.Client
+	client      *redis.Client
+	reconnector *Reconnector
 }
 
 // NewRedisClient creates a new Redis client
@@ -28,11 +31,15 @@ func NewRedisClient(cfg config.RedisConfig) (storage.RedisClient, error) {
 		MinIdleConns: cfg.MinIdleConns,
 	})
 
-	// Test connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	reconnector := NewReconnector(ReconnectConfigFromRedis(cfg))
 
-	if err := rdb.Ping(ctx).Err(); err != nil {
+	// Test connection, tolerating a Redis instance that is still starting up
+	err := reconnector.Do(context.Background(), func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return rdb.Ping(ctx).Err()
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
@@ -41,7 +48,12 @@ func NewRedisClient(cfg config.RedisConfig) (storage.RedisClient, error) {
 		logger.Int("port", cfg.Port),
 	)
 
-	return &RedisClientImpl{client: rdb}, nil
+	return &RedisClientImpl{client: rdb, reconnector: reconnector}, nil
+}
+
+// Health returns the connection health tracked across retried commands
+func (r *RedisClientImpl) Health() RedisHealth {
+	return r.reconnector.Health()
 }
 
 // PublishToStream publishes a message to a Redis stream
@@ -52,13 +64,16 @@ func (r *RedisClientImpl) PublishToStream(ctx context.Context, stream string, ke
 		return fmt.Errorf("failed to marshal value: %w", err)
 	}
 
-	// Publish to stream with key as field name
-	err = r.client.XAdd(ctx, &redis.XAddArgs{
-		Stream: stream,
-		Values: map[string]interface{}{
-			key: string(jsonData),
-		},
-	}).Err()
+	// Publish to stream with key as field name. XADD is not idempotent, so it is only retried
+	// when it never reached Redis
+	err = r.reconnector.DoNonIdempotent(ctx, func() error {
+		return r.client.XAdd(ctx, &redis.XAddArgs{
+			Stream: stream,
+			Values: map[string]interface{}{
+				key: string(jsonData),
+			},
+		}).Err()
+	})
 
 	if err != nil {
 		return fmt.Errorf("failed to publish to stream %s: %w", stream, err)
@@ -73,17 +88,21 @@ func (r *RedisClientImpl) PublishBatchToStream(ctx context.Context, stream strin
 		return nil
 	}
 
-	// Use pipeline for batch operations
-	pipe := r.client.Pipeline()
+	// Use pipeline for batch operations (retried only when it never reached Redis, since
+	// re-running a partially applied pipeline would duplicate entries)
+	err := r.reconnector.DoNonIdempotent(ctx, func() error {
+		pipe := r.client.Pipeline()
 
-	for _, msg := range messages {
-		pipe.XAdd(ctx, &redis.XAddArgs{
-			Stream: stream,
-			Values: msg,
-		})
-	}
+		for _, msg := range messages {
+			pipe.XAdd(ctx, &redis.XAddArgs{
+				Stream: stream,
+				Values: msg,
+			})
+		}
 
-	_, err := pipe.Exec(ctx)
+		_, err := pipe.Exec(ctx)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("failed to publish batch to stream %s: %w", stream, err)
 	}
@@ -138,6 +157,9 @@ func (r *RedisClientImpl) ConsumeFromStream(ctx context.Context, stream string,
 	go func() {
 		defer close(messageChan)
 
+		// Consecutive connection failures, used to back off between reads
+		failures := 0
+
 		for {
 			select {
 			case <-ctx.Done():
@@ -156,6 +178,23 @@ func (r *RedisClientImpl) ConsumeFromStream(ctx context.Context, stream string,
 
 			if err != nil {
 				if err == redis.Nil {
+					r.reconnector.MarkHealthy()
+					failures = 0
+					continue
+				}
+
+				if IsConnectionError(err) {
+					r.reconnector.MarkDegraded(err)
+					delay := r.reconnector.Backoff(failures)
+					failures++
+					logger.Warn("Redis unavailable while reading from stream, backing off",
+						logger.ErrorField(err),
+						logger.String("stream", stream),
+						logger.Duration("backoff", delay),
+					)
+					if sleepContext(ctx, delay) != nil {
+						return
+					}
 					continue
 				}
 				
@@ -188,6 +227,9 @@ func (r *RedisClientImpl) ConsumeFromStream(ctx context.Context, stream string,
 				continue
 			}
 
+			r.reconnector.MarkHealthy()
+			failures = 0
+
 			for _, stream := range streams {
 				for _, message := range stream.Messages {
 					msg := storage.StreamMessage{
@@ -210,7 +252,152 @@ func (r *RedisClientImpl) ConsumeFromStream(ctx context.Context, stream string,
 
 // AcknowledgeMessage acknowledges a message in a Redis stream
 func (r *RedisClientImpl) AcknowledgeMessage(ctx context.Context, stream string, group string, id string) error {
-	return r.client.XAck(ctx, stream, group, id).Err()
+	return r.reconnector.Do(ctx, func() error {
+		return r.client.XAck(ctx, stream, group, id).Err()
+	})
+}
+
+// ReadPendingFromStream returns messages delivered to the consumer but not yet acknowledged
+// (the consumer's pending entries list), with IDs greater than afterID
+func (r *RedisClientImpl) ReadPendingFromStream(ctx context.Context, stream string, group string, consumer string, afterID string, count int64) ([]storage.StreamMessage, error) {
+	if afterID == "" {
+		afterID = "0"
+	}
+
+	// Reading with an explicit ID (instead of ">") returns the consumer's pending history
+	var streams []redis.XStream
+	err := r.reconnector.Do(ctx, func() error {
+		var err error
+		streams, err = r.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    group,
+			Consumer: consumer,
+			Streams:  []string{stream, afterID},
+			Count:    count,
+			Block:    -1, // History reads never block
+		}).Result()
+		return err
+	})
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending messages: %w", err)
+	}
+
+	var messages []storage.StreamMessage
+	for _, s := range streams {
+		for _, message := range s.Messages {
+			messages = append(messages, storage.StreamMessage{
+				ID:     message.ID,
+				Stream: s.Stream,
+				Values: message.Values,
+			})
+		}
+	}
+	return messages, nil
+}
+
+// ClaimPendingFromStream claims entries pending for at least minIdle from any consumer of the
+// group with XAUTOCLAIM. Claiming is idempotent (re-claiming moves the entries again), so it
+// is retried like a read.
+func (r *RedisClientImpl) ClaimPendingFromStream(ctx context.Context, stream string, group string, consumer string, minIdle time.Duration, start string, count int64) ([]storage.StreamMessage, string, error) {
+	if start == "" {
+		start = "0-0"
+	}
+
+	var (
+		claimed []redis.XMessage
+		next    string
+	)
+	err := r.reconnector.Do(ctx, func() error {
+		var err error
+		claimed, next, err = r.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   stream,
+			Group:    group,
+			Consumer: consumer,
+			MinIdle:  minIdle,
+			Start:    start,
+			Count:    count,
+		}).Result()
+		return err
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to claim pending messages: %w", err)
+	}
+
+	messages := make([]storage.StreamMessage, 0, len(claimed))
+	for _, message := range claimed {
+		messages = append(messages, storage.StreamMessage{
+			ID:     message.ID,
+			Stream: stream,
+			Values: message.Values,
+		})
+	}
+	return messages, next, nil
+}
+
+// FirstStreamIDAtOrAfter returns the ID of the first stream entry added at or after t,
+// using XRANGE from the millisecond timestamp (empty if there is none)
+func (r *RedisClientImpl) FirstStreamIDAtOrAfter(ctx context.Context, stream string, t time.Time) (string, error) {
+	var messages []redis.XMessage
+	err := r.reconnector.Do(ctx, func() error {
+		var err error
+		messages, err = r.client.XRangeN(ctx, stream, fmt.Sprintf("%d-0", t.UnixMilli()), "+", 1).Result()
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to range stream %s: %w", stream, err)
+	}
+	if len(messages) == 0 {
+		return "", nil
+	}
+	return messages[0].ID, nil
+}
+
+// SetConsumerGroupID moves the consumer group's last delivered ID, creating the group if needed
+func (r *RedisClientImpl) SetConsumerGroupID(ctx context.Context, stream string, group string, id string) error {
+	err := r.reconnector.Do(ctx, func() error {
+		err := r.client.XGroupCreateMkStream(ctx, stream, group, id).Err()
+		if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return r.client.XGroupSetID(ctx, stream, group, id).Err()
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set consumer group %s ID on stream %s: %w", group, stream, err)
+	}
+	return nil
+}
+
+// StreamLength returns the number of entries in the stream using XLEN
+func (r *RedisClientImpl) StreamLength(ctx context.Context, stream string) (int64, error) {
+	var length int64
+	err := r.reconnector.Do(ctx, func() error {
+		var err error
+		length, err = r.client.XLen(ctx, stream).Result()
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get length of stream %s: %w", stream, err)
+	}
+	return length, nil
+}
+
+// StreamPending summarizes the consumer group's pending entries list using XPENDING
+func (r *RedisClientImpl) StreamPending(ctx context.Context, stream string, group string) (storage.StreamPendingSummary, error) {
+	var pending *redis.XPending
+	err := r.reconnector.Do(ctx, func() error {
+		var err error
+		pending, err = r.client.XPending(ctx, stream, group).Result()
+		return err
+	})
+	if err != nil && strings.HasPrefix(err.Error(), "NOGROUP") {
+		return storage.StreamPendingSummary{}, nil // Stream or group not created yet
+	}
+	if err != nil {
+		return storage.StreamPendingSummary{}, fmt.Errorf("failed to get pending entries of group %s on stream %s: %w", group, stream, err)
+	}
+	return storage.StreamPendingSummary{Count: pending.Count, OldestID: pending.Lower}, nil
 }
 
 // Set sets a key-value pair with TTL
@@ -223,6 +410,27 @@ func (r *RedisClientImpl) Set(ctx context.Context, key string, value interface{}
 	return r.client.Set(ctx, key, jsonData, ttl).Err()
 }
 
+// SetNX sets a key-value pair with TTL only if the key does not exist
+func (r *RedisClientImpl) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	return r.client.SetNX(ctx, key, jsonData, ttl).Result()
+}
+
+// IncrWithTTL increments a counter and refreshes its TTL in one round trip
+func (r *RedisClientImpl) IncrWithTTL(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	pipe := r.client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
 // Get gets a value by key
 func (r *RedisClientImpl) Get(ctx context.Context, key string) (string, error) {
 	result, err := r.client.Get(ctx, key).Result()
@@ -270,6 +478,31 @@ func (r *RedisClientImpl) SetRemove(ctx context.Context, key string, members ...
 	return r.client.SRem(ctx, key, members).Err()
 }
 
+// HSetBatch sets multiple fields in a hash
+func (r *RedisClientImpl) HSetBatch(ctx context.Context, key string, fields map[string]string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	values := make(map[string]interface{}, len(fields))
+	for field, value := range fields {
+		values[field] = value
+	}
+	return r.client.HSet(ctx, key, values).Err()
+}
+
+// HGetAll returns all fields and values of a hash
+func (r *RedisClientImpl) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return r.client.HGetAll(ctx, key).Result()
+}
+
+// HDel removes fields from a hash
+func (r *RedisClientImpl) HDel(ctx context.Context, key string, fields ...string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return r.client.HDel(ctx, key, fields...).Err()
+}
+
 // Publish publishes a message to a pub/sub channel
 func (r *RedisClientImpl) Publish(ctx context.Context, channel string, message interface{}) error {
 	jsonData, err := json.Marshal(message)
@@ -277,7 +510,9 @@ func (r *RedisClientImpl) Publish(ctx context.Context, channel string, message i
 		return fmt.Errorf("failed to marshal message: %w", err)
 	}
 
-	return r.client.Publish(ctx, channel, jsonData).Err()
+	return r.reconnector.DoNonIdempotent(ctx, func() error {
+		return r.client.Publish(ctx, channel, jsonData).Err()
+	})
 }
 
 // Subscribe subscribes to pub/sub channels
@@ -358,6 +593,27 @@ func (r *RedisClientImpl) ZRevRange(ctx context.Context, key string, start, stop
 	return members, nil
 }
 
+// ZRangeByScore returns the members of a sorted set with min <= score <= max, ascending by
+// score (infinite bounds are open-ended)
+func (r *RedisClientImpl) ZRangeByScore(ctx context.Context, key string, min, max float64) ([]string, error) {
+	return r.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: formatScoreBound(min),
+		Max: formatScoreBound(max),
+	}).Result()
+}
+
+// formatScoreBound formats a sorted set score bound for ZRANGEBYSCORE
+func formatScoreBound(score float64) string {
+	switch {
+	case math.IsInf(score, -1):
+		return "-inf"
+	case math.IsInf(score, 1):
+		return "+inf"
+	default:
+		return strconv.FormatFloat(score, 'f', -1, 64)
+	}
+}
+
 // ZRem removes members from a sorted set
 func (r *RedisClientImpl) ZRem(ctx context.Context, key string, members ...string) error {
 	if len(members) == 0 {
@@ -366,6 +622,12 @@ func (r *RedisClientImpl) ZRem(ctx context.Context, key string, members ...strin
 	return r.client.ZRem(ctx, key, members).Err()
 }
 
+// ZRemRangeByRank removes members in the given rank range (ascending by score)
+// and returns the number of members removed
+func (r *RedisClientImpl) ZRemRangeByRank(ctx context.Context, key string, start, stop int64) (int64, error) {
+	return r.client.ZRemRangeByRank(ctx, key, start, stop).Result()
+}
+
 // ZCard returns the number of members in a sorted set
 func (r *RedisClientImpl) ZCard(ctx context.Context, key string) (int64, error) {
 	return r.client.ZCard(ctx, key).Result()
@@ -380,6 +642,15 @@ func (r *RedisClientImpl) ZScore(ctx context.Context, key string, member string)
 	return score, err
 }
 
+// ZMScore returns the scores of members in a sorted set in one round trip, in the order
+// given (0 for members not in the set)
+func (r *RedisClientImpl) ZMScore(ctx context.Context, key string, members ...string) ([]float64, error) {
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return r.client.ZMScore(ctx, key, members...).Result()
+}
+
 // Close closes the Redis connection
 func (r *RedisClientImpl) Close() error {
 	return r.client.Close()
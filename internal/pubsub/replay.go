@@ -0,0 +1,55 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// ReplayMarkerKeyPrefix prefixes the keys recording which replays a consumer group performed
+const ReplayMarkerKeyPrefix = "stream:replay"
+
+// SeekConsumerGroup moves a consumer group so that it next delivers the first stream entry
+// added at or after from. Returns that entry's ID, or empty if the stream has no entry that
+// recent, in which case the group is moved to the end of the stream.
+func SeekConsumerGroup(ctx context.Context, redis storage.RedisClient, stream, group string, from time.Time) (string, error) {
+	firstID, err := redis.FirstStreamIDAtOrAfter(ctx, stream, from)
+	if err != nil {
+		return "", fmt.Errorf("failed to find replay start: %w", err)
+	}
+
+	lastDelivered := "$"
+	if firstID != "" {
+		lastDelivered = storage.StreamIDBefore(firstID)
+	}
+	if err := redis.SetConsumerGroupID(ctx, stream, group, lastDelivered); err != nil {
+		return "", err
+	}
+
+	logger.Warn("Consumer group seeked for replay",
+		logger.String("stream", stream),
+		logger.String("group", group),
+		logger.Time("from", from),
+		logger.String("first_id", firstID),
+	)
+	return firstID, nil
+}
+
+// ParseReplayFrom parses the replay start time. The timestamp only takes effect when replay
+// is explicitly enabled; a zero time means no replay.
+func ParseReplayFrom(enabled bool, from string) (time.Time, error) {
+	if !enabled {
+		return time.Time{}, nil
+	}
+	if from == "" {
+		return time.Time{}, fmt.Errorf("replay is enabled but no replay start time is set")
+	}
+	t, err := time.Parse(time.RFC3339, from)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid replay start time %q: must be an RFC3339 timestamp", from)
+	}
+	return t, nil
+}
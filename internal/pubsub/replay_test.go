@@ -0,0 +1,132 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReplayTestMessages creates one tick message per ID, named after the ID
+func newReplayTestMessages(t *testing.T, stream string, ids ...string) []storage.StreamMessage {
+	messages := make([]storage.StreamMessage, len(ids))
+	for i, id := range ids {
+		tickJSON, err := json.Marshal(&models.Tick{Symbol: "SYM" + id, Price: 100.0, Size: 100, Timestamp: time.Now(), Type: "trade"})
+		require.NoError(t, err)
+		messages[i] = storage.StreamMessage{ID: id, Stream: stream, Values: map[string]interface{}{"tick": string(tickJSON)}}
+	}
+	return messages
+}
+
+func TestStreamConsumer_ReplayFromTimestamp(t *testing.T) {
+	mockRedis := storage.NewMockRedisClient()
+	mockRedis.StreamData = newReplayTestMessages(t, "ticks", "1000-0", "1999-3", "2000-0", "2000-1", "3000-0")
+
+	// An offset persisted past the replay start is moved back with the group
+	require.NoError(t, mockRedis.Set(context.Background(), "stream:offset:ticks:bars:bars-consumer-1", "5000-0", 0))
+
+	agg := &MockAggregator{}
+	config := DefaultStreamConsumerConfig("ticks", "bars", "bars-consumer-1")
+	config.BatchSize = 1
+	config.PersistOffsets = true
+	config.ReplayFrom = time.UnixMilli(2000)
+	consumer := NewStreamConsumer(mockRedis, config)
+	consumer.SetAggregator(agg)
+	require.NoError(t, consumer.Start())
+	require.Eventually(t, func() bool { return len(agg.GetTicks()) == 3 }, time.Second, 5*time.Millisecond)
+	consumer.Stop()
+
+	// Consumption starts at the first entry at or after the requested time
+	ticks := agg.GetTicks()
+	var symbols []string
+	for _, tick := range ticks {
+		symbols = append(symbols, tick.Symbol)
+	}
+	assert.Equal(t, []string{"SYM2000-0", "SYM2000-1", "SYM3000-0"}, symbols)
+	assert.Equal(t, "1999-18446744073709551615", mockRedis.GroupIDs["ticks:bars"])
+
+	var offset string
+	require.NoError(t, mockRedis.GetJSON(context.Background(), consumer.offsetKey("ticks"), &offset))
+	assert.Equal(t, "3000-0", offset)
+}
+
+func TestStreamConsumer_ReplayIsOneShot(t *testing.T) {
+	mockRedis := storage.NewMockRedisClient()
+	mockRedis.StreamData = newReplayTestMessages(t, "ticks", "1000-0", "2000-0", "3000-0")
+
+	config := DefaultStreamConsumerConfig("ticks", "bars", "bars-consumer-1")
+	config.ReplayFrom = time.UnixMilli(2000)
+	consumer := NewStreamConsumer(mockRedis, config)
+
+	require.NoError(t, consumer.seekReplay("ticks"))
+	assert.Equal(t, "1999-18446744073709551615", mockRedis.GroupIDs["ticks:bars"])
+
+	// A restart or another replica with the same replay start does not rewind the group again
+	mockRedis.GroupIDs["ticks:bars"] = "3000-0"
+	other := NewStreamConsumer(mockRedis, DefaultStreamConsumerConfig("ticks", "bars", "bars-consumer-2"))
+	other.config.ReplayFrom = config.ReplayFrom
+	require.NoError(t, other.seekReplay("ticks"))
+	assert.Equal(t, "3000-0", mockRedis.GroupIDs["ticks:bars"])
+
+	// A new replay start replays again
+	other.config.ReplayFrom = time.UnixMilli(1000)
+	require.NoError(t, other.seekReplay("ticks"))
+	assert.Equal(t, "999-18446744073709551615", mockRedis.GroupIDs["ticks:bars"])
+
+	// A failed seek releases the marker so the next start retries
+	mockRedis.ConsumeErr = fmt.Errorf("connection refused")
+	other.config.ReplayFrom = time.UnixMilli(3000)
+	require.Error(t, other.seekReplay("ticks"))
+	mockRedis.ConsumeErr = nil
+	require.NoError(t, other.seekReplay("ticks"))
+	assert.Equal(t, "2999-18446744073709551615", mockRedis.GroupIDs["ticks:bars"])
+}
+
+func TestSeekConsumerGroup(t *testing.T) {
+	mockRedis := storage.NewMockRedisClient()
+	mockRedis.StreamData = newReplayTestMessages(t, "ticks", "1000-0", "2000-5", "3000-0")
+	mockRedis.StreamData = append(mockRedis.StreamData, newReplayTestMessages(t, "other", "2500-0")...)
+	ctx := context.Background()
+
+	// Between entries: the next entry of the stream is the start
+	firstID, err := SeekConsumerGroup(ctx, mockRedis, "ticks", "bars", time.UnixMilli(1500))
+	require.NoError(t, err)
+	assert.Equal(t, "2000-5", firstID)
+	assert.Equal(t, "2000-4", mockRedis.GroupIDs["ticks:bars"])
+
+	// Past the last entry: the group moves to the end of the stream
+	firstID, err = SeekConsumerGroup(ctx, mockRedis, "ticks", "bars", time.UnixMilli(5000))
+	require.NoError(t, err)
+	assert.Empty(t, firstID)
+	assert.Equal(t, "$", mockRedis.GroupIDs["ticks:bars"])
+
+	messages, err := mockRedis.ConsumeFromStream(ctx, "ticks", "bars", "bars-consumer-1")
+	require.NoError(t, err)
+	assert.Empty(t, messages)
+
+	mockRedis.ConsumeErr = fmt.Errorf("connection refused")
+	_, err = SeekConsumerGroup(ctx, mockRedis, "ticks", "bars", time.UnixMilli(1500))
+	assert.Error(t, err)
+}
+
+func TestParseReplayFrom(t *testing.T) {
+	// The timestamp is ignored unless replay is explicitly enabled
+	from, err := ParseReplayFrom(false, "2024-01-02T14:30:00Z")
+	require.NoError(t, err)
+	assert.True(t, from.IsZero())
+
+	from, err = ParseReplayFrom(true, "2024-01-02T14:30:00Z")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC), from.UTC())
+
+	_, err = ParseReplayFrom(true, "")
+	assert.Error(t, err)
+	_, err = ParseReplayFrom(true, "yesterday")
+	assert.Error(t, err)
+}
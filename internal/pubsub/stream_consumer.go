@@ -24,6 +24,12 @@ type StreamConsumerConfig struct {
 	MaxRetries      int
 	RetryDelay      time.Duration
 	BlockTime       time.Duration // Block time for XReadGroup
+	PersistOffsets  bool          // Persist the last processed ID to Redis and recover pending messages on start
+	OffsetKeyPrefix string        // Key prefix for persisted offsets
+	ReplayFrom      time.Time     // Reprocess each stream from its first entry at or after this time, once per group and time (zero = resume normally)
+	BacklogInterval time.Duration // How often pending entries and lag are refreshed for stats and metrics (0 = never)
+	DrainTimeout    time.Duration // How long Stop waits for messages already read to be processed and acknowledged (0 = no limit)
+	ClaimMinIdle    time.Duration // Claim and reprocess entries pending this long for any consumer of the group, checked as often (0 = never)
 }
 
 // DefaultStreamConsumerConfig returns default configuration
@@ -39,6 +45,11 @@ func DefaultStreamConsumerConfig(streamName, consumerGroup, consumerName string)
 		MaxRetries:     3,
 		RetryDelay:     1 * time.Second,
 		BlockTime:      1 * time.Second,
+		PersistOffsets:  false,
+		OffsetKeyPrefix: "stream:offset",
+		BacklogInterval: 15 * time.Second,
+		DrainTimeout:    10 * time.Second,
+		ClaimMinIdle:    time.Minute,
 	}
 }
 
@@ -47,12 +58,18 @@ type StreamConsumer struct {
 	config     StreamConsumerConfig
 	redis      storage.RedisClient
 	aggregator AggregatorInterface // Interface to avoid circular dependency
-	ctx        context.Context
+	ctx        context.Context    // Cancelled when the consumer stops reading new messages
 	cancel     context.CancelFunc
+	drainCtx   context.Context    // Cancelled when draining on stop times out
+	abortDrain context.CancelFunc
 	wg         sync.WaitGroup
 	mu         sync.RWMutex
 	running    bool
 	stats      ConsumerStats
+
+	// Last committed (contiguously processed) message ID per stream
+	offsets   map[string]string
+	offsetsMu sync.Mutex
 }
 
 // AggregatorInterface defines the interface for the bar aggregator
@@ -68,19 +85,29 @@ type ConsumerStats struct {
 	MessagesFailed   int64
 	LastMessageTime  time.Time
 	Lag              int64 // Approximate lag in messages
+	StreamLength     int64 // Entries in the consumed streams (XLEN)
+	PendingCount     int64 // Entries delivered to the consumer group but not yet acknowledged (XPENDING)
+	LagMs            int64 // Age of the consumer group's oldest pending entry
+	MessagesDrained  int64 // Messages already read when stopping that were processed before returning
+	MessagesAbandoned int64 // Messages already read when stopping that were left pending because draining timed out
+	MessagesClaimed  int64 // Idle pending messages claimed from the group (e.g. from dead consumers) and reprocessed
 	mu               sync.RWMutex
 }
 
 // NewStreamConsumer creates a new stream consumer
 func NewStreamConsumer(redis storage.RedisClient, config StreamConsumerConfig) *StreamConsumer {
 	ctx, cancel := context.WithCancel(context.Background())
+	drainCtx, abortDrain := context.WithCancel(context.Background())
 
 	return &StreamConsumer{
 		config: config,
 		redis:  redis,
 		ctx:    ctx,
-		cancel: cancel,
-		stats:  ConsumerStats{},
+		cancel:  cancel,
+		drainCtx:   drainCtx,
+		abortDrain: abortDrain,
+		stats:   ConsumerStats{},
+		offsets: make(map[string]string),
 	}
 }
 
@@ -118,23 +145,106 @@ func (c *StreamConsumer) Start() error {
 		go c.consumeStream(stream)
 	}
 
+	if c.config.BacklogInterval > 0 {
+		c.wg.Add(1)
+		go c.monitorBacklog()
+	}
+
 	return nil
 }
 
-// Stop stops the consumer
+// monitorBacklog periodically refreshes the consumer group's pending entries and lag, so
+// GetStats (and the health checks calling it) never wait on Redis
+func (c *StreamConsumer) monitorBacklog() {
+	defer c.wg.Done()
+
+	c.refreshBacklog()
+
+	ticker := time.NewTicker(c.config.BacklogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			c.refreshBacklog()
+		}
+	}
+}
+
+// refreshBacklog queries the consumer group's backlog and caches it in the stats
+func (c *StreamConsumer) refreshBacklog() {
+	backlog := StreamBacklog(c.redis, c.getStreams(), c.config.ConsumerGroup)
+
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	c.stats.StreamLength = backlog.StreamLength
+	c.stats.PendingCount = backlog.PendingCount
+	c.stats.LagMs = backlog.LagMs
+}
+
+// Stop stops the consumer, draining messages already read for up to the configured DrainTimeout
 func (c *StreamConsumer) Stop() {
+	_ = c.StopGraceful(c.config.DrainTimeout)
+}
+
+// StopGraceful stops reading new messages, then waits for the current batch and the messages
+// already read from the stream to be processed and acknowledged. If that takes longer than
+// timeout (0 = no limit), the drain is aborted once the batch being processed finishes: the
+// remaining messages are left pending in the consumer group (reprocessed on restart when
+// offsets are persisted) and an error is returned.
+func (c *StreamConsumer) StopGraceful(timeout time.Duration) error {
 	c.mu.Lock()
 	if !c.running {
 		c.mu.Unlock()
-		return
+		return nil
 	}
 	c.running = false
 	c.mu.Unlock()
 
-	logger.Info("Stopping stream consumer")
+	logger.Info("Stopping stream consumer",
+		logger.Duration("drain_timeout", timeout),
+	)
 	c.cancel()
-	c.wg.Wait()
-	logger.Info("Stream consumer stopped")
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case <-done:
+		c.stats.mu.RLock()
+		drained := c.stats.MessagesDrained
+		c.stats.mu.RUnlock()
+		logger.Info("Stream consumer stopped",
+			logger.Int64("drained", drained),
+		)
+		return nil
+	case <-expired:
+	}
+
+	c.abortDrain()
+	<-done
+
+	c.stats.mu.RLock()
+	drained, abandoned := c.stats.MessagesDrained, c.stats.MessagesAbandoned
+	c.stats.mu.RUnlock()
+	logger.Warn("Stream consumer drain timed out, unprocessed messages left pending",
+		logger.Duration("drain_timeout", timeout),
+		logger.Int64("drained", drained),
+		logger.Int64("abandoned", abandoned),
+	)
+	return fmt.Errorf("stream consumer drain timed out after %s: %d messages abandoned", timeout, abandoned)
 }
 
 // getStreams returns the list of streams to consume from
@@ -165,6 +275,37 @@ func (c *StreamConsumer) consumeStream(stream string) {
 		return
 	}
 
+	// Resume deterministically from the persisted offset before reading new messages
+	if c.config.PersistOffsets {
+		if err := c.recoverPending(stream); err != nil {
+			logger.Error("Failed to recover pending messages",
+				logger.ErrorField(err),
+				logger.String("stream", stream),
+			)
+		}
+	}
+
+	// Take over messages left pending by consumers that died or were renamed, now and then
+	// periodically (a nil channel never fires when claiming is disabled)
+	var claimTick <-chan time.Time
+	if c.config.ClaimMinIdle > 0 {
+		c.claimIdle(stream)
+		claimTicker := time.NewTicker(c.config.ClaimMinIdle)
+		defer claimTicker.Stop()
+		claimTick = claimTicker.C
+	}
+
+	// Reprocess from the requested time instead of resuming after the last delivered message
+	if !c.config.ReplayFrom.IsZero() {
+		if err := c.seekReplay(stream); err != nil {
+			logger.Error("Failed to seek consumer group for replay",
+				logger.ErrorField(err),
+				logger.String("stream", stream),
+			)
+			return
+		}
+	}
+
 	messageChan, err := c.redis.ConsumeFromStream(c.ctx, stream, c.config.ConsumerGroup, c.config.ConsumerName)
 	if err != nil {
 		logger.Error("Failed to start consuming from stream",
@@ -181,14 +322,16 @@ func (c *StreamConsumer) consumeStream(stream string) {
 	for {
 		select {
 		case <-c.ctx.Done():
-			// Process remaining batch before exiting
-			if len(batch) > 0 {
-				c.processBatch(stream, batch)
-			}
+			// Stopping: process the remaining batch and messages already read before exiting
+			c.drain(stream, messageChan, batch)
 			return
 
 		case msg, ok := <-messageChan:
 			if !ok {
+				if c.ctx.Err() != nil {
+					c.drain(stream, messageChan, batch)
+					return
+				}
 				logger.Warn("Message channel closed",
 					logger.String("stream", stream),
 				)
@@ -197,18 +340,61 @@ func (c *StreamConsumer) consumeStream(stream string) {
 
 			batch = append(batch, msg)
 
-			// Process batch if it's full
-			if len(batch) >= c.config.BatchSize {
+			// Process batch if it's full (when stopping, the drain processes it instead)
+			if len(batch) >= c.config.BatchSize && c.ctx.Err() == nil {
 				c.processBatch(stream, batch)
 				batch = batch[:0] // Clear batch
 			}
 
 		case <-ticker.C:
 			// Process batch on timeout
-			if len(batch) > 0 {
+			if len(batch) > 0 && c.ctx.Err() == nil {
 				c.processBatch(stream, batch)
 				batch = batch[:0] // Clear batch
 			}
+
+		case <-claimTick:
+			if c.ctx.Err() == nil {
+				c.claimIdle(stream)
+			}
+		}
+	}
+}
+
+// drain processes the remaining batch and the messages already buffered in messageChan in
+// batches, acknowledging each, until none are left or the drain is aborted. Messages left when
+// it is aborted stay pending in the consumer group and are counted as abandoned.
+func (c *StreamConsumer) drain(stream string, messageChan <-chan storage.StreamMessage, batch []storage.StreamMessage) {
+	batch = drainDelivered(messageChan, batch)
+
+	batchSize := c.config.BatchSize
+	if batchSize <= 0 {
+		batchSize = len(batch)
+	}
+
+	for len(batch) > 0 {
+		if c.drainCtx.Err() != nil {
+			c.incrementAbandoned(int64(len(batch)))
+			return
+		}
+		n := min(batchSize, len(batch))
+		c.processBatch(stream, batch[:n])
+		c.incrementDrained(int64(n))
+		batch = batch[n:]
+	}
+}
+
+// drainDelivered appends messages already buffered in messageChan to batch without blocking
+func drainDelivered(messageChan <-chan storage.StreamMessage, batch []storage.StreamMessage) []storage.StreamMessage {
+	for {
+		select {
+		case msg, ok := <-messageChan:
+			if !ok {
+				return batch
+			}
+			batch = append(batch, msg)
+		default:
+			return batch
 		}
 	}
 }
@@ -220,14 +406,22 @@ func (c *StreamConsumer) createConsumerGroup(stream string) error {
 	return nil
 }
 
-// processBatch processes a batch of messages
+// processBatch processes a batch of messages read from the stream, committing the offset
 func (c *StreamConsumer) processBatch(stream string, messages []storage.StreamMessage) {
+	c.processMessages(stream, messages, c.config.PersistOffsets)
+}
+
+// processMessages processes and acknowledges messages. The offset is only committed for
+// messages delivered to this consumer in order: claimed messages may lie past entries of its
+// own that are still pending, which a moved offset would skip after a restart.
+func (c *StreamConsumer) processMessages(stream string, messages []storage.StreamMessage, commit bool) {
 	if len(messages) == 0 {
 		return
 	}
 
 	processed := make([]string, 0, len(messages)) // Message IDs to acknowledge
 	failed := make([]string, 0)                  // Message IDs that failed
+	committed := ""                              // Last ID of the contiguously processed prefix
 
 	for _, msg := range messages {
 		tick, err := c.deserializeTick(msg)
@@ -269,6 +463,14 @@ func (c *StreamConsumer) processBatch(stream string, messages []storage.StreamMe
 
 		processed = append(processed, msg.ID)
 		c.incrementProcessed()
+		if len(failed) == 0 {
+			committed = msg.ID
+		}
+	}
+
+	// Persist the offset before acknowledging, so a crash in between never loses messages
+	if commit && committed != "" {
+		c.commitOffset(stream, committed)
 	}
 
 	// Acknowledge successfully processed messages
@@ -277,7 +479,7 @@ func (c *StreamConsumer) processBatch(stream string, messages []storage.StreamMe
 		c.incrementAcked(int64(len(processed)))
 	}
 
-	// Log failed messages (they will be retried by consumer group)
+	// Log failed messages (they stay pending and are claimed again once idle for ClaimMinIdle)
 	if len(failed) > 0 {
 		logger.Warn("Some messages failed to process",
 			logger.Int("failed_count", len(failed)),
@@ -286,6 +488,195 @@ func (c *StreamConsumer) processBatch(stream string, messages []storage.StreamMe
 	}
 }
 
+// offsetKey returns the Redis key holding the persisted offset for a stream
+func (c *StreamConsumer) offsetKey(stream string) string {
+	return fmt.Sprintf("%s:%s:%s:%s", c.config.OffsetKeyPrefix, stream, c.config.ConsumerGroup, c.config.ConsumerName)
+}
+
+// loadOffset loads the persisted offset for a stream (empty if none)
+func (c *StreamConsumer) loadOffset(stream string) (string, error) {
+	var offset string
+	if err := c.redis.GetJSON(c.ctx, c.offsetKey(stream), &offset); err != nil {
+		return "", fmt.Errorf("failed to load offset: %w", err)
+	}
+
+	c.offsetsMu.Lock()
+	c.offsets[stream] = offset
+	c.offsetsMu.Unlock()
+
+	return offset, nil
+}
+
+// commitOffset persists the last processed message ID for a stream (offsets never move backwards)
+func (c *StreamConsumer) commitOffset(stream string, id string) {
+	c.offsetsMu.Lock()
+	if storage.CompareStreamIDs(id, c.offsets[stream]) <= 0 {
+		c.offsetsMu.Unlock()
+		return
+	}
+	c.offsets[stream] = id
+	c.offsetsMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.AckTimeout)
+	defer cancel()
+
+	if err := c.redis.Set(ctx, c.offsetKey(stream), id, 0); err != nil {
+		logger.Error("Failed to persist consumer offset",
+			logger.ErrorField(err),
+			logger.String("stream", stream),
+			logger.String("message_id", id),
+		)
+	}
+}
+
+// replayMarkerKey returns the Redis key recording that the group was seeked to the replay start
+func (c *StreamConsumer) replayMarkerKey(stream string) string {
+	return fmt.Sprintf("%s:%s:%s:%d", ReplayMarkerKeyPrefix, stream, c.config.ConsumerGroup, c.config.ReplayFrom.UnixMilli())
+}
+
+// seekReplay moves the consumer group to the replay start. The seek is one-shot: the first
+// consumer to start claims a marker in Redis, so restarts, rollouts and other replicas of the
+// group resume normally instead of rewinding the group again. A persisted offset is moved back
+// with the group, since offsets otherwise never move backwards and replayed messages would not
+// commit.
+func (c *StreamConsumer) seekReplay(stream string) error {
+	claimed, err := c.redis.SetNX(c.ctx, c.replayMarkerKey(stream), time.Now().UTC(), 0)
+	if err != nil {
+		return fmt.Errorf("failed to claim replay marker: %w", err)
+	}
+	if !claimed {
+		logger.Info("Replay already performed for this group, resuming normally",
+			logger.String("stream", stream),
+			logger.String("group", c.config.ConsumerGroup),
+			logger.Time("from", c.config.ReplayFrom),
+		)
+		return nil
+	}
+
+	firstID, err := SeekConsumerGroup(c.ctx, c.redis, stream, c.config.ConsumerGroup, c.config.ReplayFrom)
+	if err != nil {
+		// Release the marker so the next start retries the replay
+		if delErr := c.redis.Delete(context.Background(), c.replayMarkerKey(stream)); delErr != nil {
+			logger.Error("Failed to release replay marker",
+				logger.ErrorField(delErr),
+				logger.String("stream", stream),
+			)
+		}
+		return err
+	}
+	if !c.config.PersistOffsets || firstID == "" {
+		return nil
+	}
+
+	offset := storage.StreamIDBefore(firstID)
+	c.offsetsMu.Lock()
+	c.offsets[stream] = offset
+	c.offsetsMu.Unlock()
+
+	if err := c.redis.Set(c.ctx, c.offsetKey(stream), offset, 0); err != nil {
+		return fmt.Errorf("failed to reset offset for replay: %w", err)
+	}
+	return nil
+}
+
+// recoverPending resumes after a restart under the same name: its pending messages at or
+// before the persisted offset were already processed (only the ack was lost) and are
+// acknowledged; the rest are reprocessed. Messages pending for other consumers are left to
+// claimIdle.
+func (c *StreamConsumer) recoverPending(stream string) error {
+	offset, err := c.loadOffset(stream)
+	if err != nil {
+		return err
+	}
+
+	pageSize := int64(c.config.BatchSize)
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+
+	recovered := 0
+	skipped := 0
+	afterID := "0"
+	for {
+		pending, err := c.redis.ReadPendingFromStream(c.ctx, stream, c.config.ConsumerGroup, c.config.ConsumerName, afterID, pageSize)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			break
+		}
+
+		alreadyProcessed := make([]string, 0)
+		toProcess := make([]storage.StreamMessage, 0, len(pending))
+		for _, msg := range pending {
+			if offset != "" && storage.CompareStreamIDs(msg.ID, offset) <= 0 {
+				alreadyProcessed = append(alreadyProcessed, msg.ID)
+			} else {
+				toProcess = append(toProcess, msg)
+			}
+		}
+
+		if len(alreadyProcessed) > 0 {
+			c.acknowledgeMessages(stream, alreadyProcessed)
+			skipped += len(alreadyProcessed)
+		}
+		if len(toProcess) > 0 {
+			c.processBatch(stream, toProcess)
+			recovered += len(toProcess)
+		}
+
+		afterID = pending[len(pending)-1].ID
+	}
+
+	logger.Info("Recovered pending stream messages",
+		logger.String("stream", stream),
+		logger.String("offset", offset),
+		logger.Int("reprocessed", recovered),
+		logger.Int("acknowledged_without_processing", skipped),
+	)
+
+	return nil
+}
+
+// claimIdle claims the group's entries pending for at least ClaimMinIdle, whichever consumer
+// they were delivered to, and reprocesses them. Pending entries of a consumer that died, or
+// that restarted under another name, are otherwise never delivered again.
+func (c *StreamConsumer) claimIdle(stream string) {
+	pageSize := int64(c.config.BatchSize)
+	if pageSize <= 0 {
+		pageSize = 100
+	}
+
+	claimed := 0
+	start := "0-0"
+	for c.ctx.Err() == nil {
+		messages, next, err := c.redis.ClaimPendingFromStream(c.ctx, stream, c.config.ConsumerGroup, c.config.ConsumerName, c.config.ClaimMinIdle, start, pageSize)
+		if err != nil {
+			logger.Error("Failed to claim idle pending messages",
+				logger.ErrorField(err),
+				logger.String("stream", stream),
+			)
+			break
+		}
+		c.processMessages(stream, messages, false)
+		claimed += len(messages)
+		if next == "" || next == "0-0" {
+			break
+		}
+		start = next
+	}
+
+	if claimed > 0 {
+		c.incrementClaimed(int64(claimed))
+		logger.Warn("Claimed idle pending stream messages",
+			logger.String("stream", stream),
+			logger.String("consumer", c.config.ConsumerName),
+			logger.Int("claimed", claimed),
+			logger.Duration("min_idle", c.config.ClaimMinIdle),
+		)
+	}
+}
+
 // deserializeTick deserializes a stream message into a Tick
 func (c *StreamConsumer) deserializeTick(msg storage.StreamMessage) (*models.Tick, error) {
 	// The stream publisher stores ticks with key "tick"
@@ -351,11 +742,45 @@ func (c *StreamConsumer) incrementFailed() {
 	c.stats.MessagesFailed++
 }
 
-// GetStats returns current consumer statistics
+// incrementClaimed increments the claimed message counter
+func (c *StreamConsumer) incrementClaimed(count int64) {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	c.stats.MessagesClaimed += count
+}
+
+// incrementDrained increments the drained message counter
+func (c *StreamConsumer) incrementDrained(count int64) {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	c.stats.MessagesDrained += count
+}
+
+// incrementAbandoned increments the abandoned message counter
+func (c *StreamConsumer) incrementAbandoned(count int64) {
+	c.stats.mu.Lock()
+	defer c.stats.mu.Unlock()
+	c.stats.MessagesAbandoned += count
+}
+
+// GetStats returns current consumer statistics, including the consumer group's backlog as
+// of the last refresh
 func (c *StreamConsumer) GetStats() ConsumerStats {
 	c.stats.mu.RLock()
 	defer c.stats.mu.RUnlock()
-	return c.stats
+	return ConsumerStats{
+		MessagesProcessed: c.stats.MessagesProcessed,
+		MessagesAcked:     c.stats.MessagesAcked,
+		MessagesFailed:    c.stats.MessagesFailed,
+		LastMessageTime:   c.stats.LastMessageTime,
+		Lag:               c.stats.Lag,
+		StreamLength:      c.stats.StreamLength,
+		PendingCount:      c.stats.PendingCount,
+		LagMs:             c.stats.LagMs,
+		MessagesDrained:   c.stats.MessagesDrained,
+		MessagesAbandoned: c.stats.MessagesAbandoned,
+		MessagesClaimed:   c.stats.MessagesClaimed,
+	}
 }
 
 // IsRunning returns whether the consumer is running
@@ -1,6 +1,7 @@
 package pubsub
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"sync"
@@ -10,6 +11,7 @@ import (
 	"github.com/mohamedkhairy/stock-scanner/internal/bars"
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
 	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -227,6 +229,107 @@ func TestStreamConsumer_Stats(t *testing.T) {
 	assert.Equal(t, int64(1), stats.MessagesFailed)
 }
 
+func TestStreamConsumer_StatsBacklog(t *testing.T) {
+	now := time.Now()
+	streamID := func(age time.Duration, seq int) string {
+		return fmt.Sprintf("%d-%d", now.Add(-age).UnixMilli(), seq)
+	}
+
+	mockRedis := storage.NewMockRedisClient()
+	mockRedis.StreamData = []storage.StreamMessage{
+		{ID: streamID(time.Minute, 0), Stream: "lag-ticks.p0"},
+		{ID: streamID(30*time.Second, 0), Stream: "lag-ticks.p0"},
+		{ID: streamID(10*time.Second, 0), Stream: "lag-ticks.p0"},
+		{ID: streamID(20*time.Second, 0), Stream: "lag-ticks.p1"},
+		{ID: streamID(5*time.Second, 0), Stream: "lag-ticks.p1"},
+		{ID: streamID(time.Hour, 0), Stream: "other"},
+	}
+	// Pending entries are those delivered to the group and not yet acknowledged
+	for _, stream := range []string{"lag-ticks.p0", "lag-ticks.p1"} {
+		_, err := mockRedis.ConsumeFromStream(context.Background(), stream, "bars", "bars-consumer-1")
+		require.NoError(t, err)
+	}
+	require.NoError(t, mockRedis.AcknowledgeMessage(nil, "lag-ticks.p0", "bars", streamID(time.Minute, 0)))
+	require.NoError(t, mockRedis.AcknowledgeMessage(nil, "lag-ticks.p1", "bars", streamID(5*time.Second, 0)))
+
+	config := DefaultStreamConsumerConfig("lag-ticks", "bars", "bars-consumer-1")
+	config.Partitions = 2
+	consumer := NewStreamConsumer(mockRedis, config)
+
+	// Stats serve the backlog cached by the last refresh
+	stats := consumer.GetStats()
+	assert.Equal(t, int64(0), stats.StreamLength)
+
+	consumer.refreshBacklog()
+	stats = consumer.GetStats()
+	assert.Equal(t, int64(5), stats.StreamLength)
+	assert.Equal(t, int64(3), stats.PendingCount)
+	assert.InDelta(t, 30000, stats.LagMs, 1000) // Oldest pending entry across partitions
+
+	// Per-stream gauges
+	assert.Equal(t, 2.0, testutil.ToFloat64(consumerPendingEntries.WithLabelValues("lag-ticks.p0", "bars")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(consumerPendingEntries.WithLabelValues("lag-ticks.p1", "bars")))
+	assert.InDelta(t, 20000, testutil.ToFloat64(consumerLagMs.WithLabelValues("lag-ticks.p1", "bars")), 1000)
+
+	// Nothing pending once everything is acknowledged
+	for _, msg := range mockRedis.StreamData {
+		require.NoError(t, mockRedis.AcknowledgeMessage(nil, msg.Stream, "bars", msg.ID))
+	}
+	consumer.refreshBacklog()
+	stats = consumer.GetStats()
+	assert.Equal(t, int64(0), stats.PendingCount)
+	assert.Equal(t, int64(0), stats.LagMs)
+
+	// Streams that cannot be queried are left out
+	mockRedis.ConsumeErr = fmt.Errorf("connection lost")
+	consumer.refreshBacklog()
+	stats = consumer.GetStats()
+	assert.Equal(t, int64(0), stats.StreamLength)
+	assert.Equal(t, int64(0), stats.PendingCount)
+}
+
+func TestStreamConsumer_StatsBacklog_NoStream(t *testing.T) {
+	consumer := NewStreamConsumer(storage.NewMockRedisClient(), DefaultStreamConsumerConfig("missing", "bars", "bars-consumer-1"))
+
+	consumer.refreshBacklog()
+	stats := consumer.GetStats()
+	assert.Equal(t, int64(0), stats.StreamLength)
+	assert.Equal(t, int64(0), stats.PendingCount)
+	assert.Equal(t, int64(0), stats.LagMs)
+}
+
+func TestStreamConsumer_StatsBacklog_PerGroup(t *testing.T) {
+	mockRedis := storage.NewMockRedisClient()
+	mockRedis.StreamData = []storage.StreamMessage{
+		{ID: "1-0", Stream: "ticks"},
+		{ID: "2-0", Stream: "ticks"},
+		{ID: "3-0", Stream: "ticks"},
+	}
+	bars := NewStreamConsumer(mockRedis, DefaultStreamConsumerConfig("ticks", "bars", "bars-consumer-1"))
+	audit := NewStreamConsumer(mockRedis, DefaultStreamConsumerConfig("ticks", "audit", "audit-consumer-1"))
+
+	// Only entries delivered to a group count as its pending entries
+	_, err := mockRedis.ConsumeFromStream(context.Background(), "ticks", "bars", "bars-consumer-1")
+	require.NoError(t, err)
+	require.NoError(t, mockRedis.AcknowledgeMessage(nil, "ticks", "bars", "1-0"))
+
+	bars.refreshBacklog()
+	audit.refreshBacklog()
+	assert.Equal(t, int64(2), bars.GetStats().PendingCount)
+	assert.Equal(t, int64(0), audit.GetStats().PendingCount, "group never consumed the stream")
+	assert.Equal(t, int64(3), audit.GetStats().StreamLength)
+
+	// Acknowledgements of one group leave the other's entries pending
+	_, err = mockRedis.ConsumeFromStream(context.Background(), "ticks", "audit", "audit-consumer-1")
+	require.NoError(t, err)
+	audit.refreshBacklog()
+	assert.Equal(t, int64(3), audit.GetStats().PendingCount)
+
+	pending, err := mockRedis.StreamPending(context.Background(), "ticks", "unknown")
+	require.NoError(t, err)
+	assert.Equal(t, storage.StreamPendingSummary{}, pending)
+}
+
 func TestStreamConsumer_IntegrationWithAggregator(t *testing.T) {
 	// Skip if running in short mode
 	if testing.Short() {
@@ -280,3 +383,241 @@ func TestStreamConsumer_IntegrationWithAggregator(t *testing.T) {
 	// For now, we test the deserialization and processing logic separately.
 }
 
+
+// crashingAggregator records ticks and fails every tick after the first crashAfter ticks
+type crashingAggregator struct {
+	MockAggregator
+	crashAfter int
+}
+
+func (a *crashingAggregator) ProcessTick(tick *models.Tick) error {
+	if len(a.GetTicks()) >= a.crashAfter {
+		return fmt.Errorf("simulated crash")
+	}
+	return a.MockAggregator.ProcessTick(tick)
+}
+
+func newOffsetTestMessages(t *testing.T, stream string, count int) []storage.StreamMessage {
+	messages := make([]storage.StreamMessage, count)
+	for i := range messages {
+		tickJSON, err := json.Marshal(&models.Tick{
+			Symbol:    fmt.Sprintf("SYM%d", i+1),
+			Price:     100.0,
+			Size:      100,
+			Timestamp: time.Now(),
+			Type:      "trade",
+		})
+		require.NoError(t, err)
+		messages[i] = storage.StreamMessage{
+			ID:     fmt.Sprintf("1000-%d", i+1),
+			Stream: stream,
+			Values: map[string]interface{}{"tick": string(tickJSON)},
+		}
+	}
+	return messages
+}
+
+func newOffsetTestConsumer(redis storage.RedisClient, aggregator AggregatorInterface) *StreamConsumer {
+	config := DefaultStreamConsumerConfig("ticks", "bars", "bars-consumer-1")
+	config.PersistOffsets = true
+	consumer := NewStreamConsumer(redis, config)
+	consumer.SetAggregator(aggregator)
+	return consumer
+}
+
+func TestStreamConsumer_PersistOffsets_CrashMidBatch(t *testing.T) {
+	mockRedis := storage.NewMockRedisClient()
+	messages := newOffsetTestMessages(t, "ticks", 6)
+	mockRedis.StreamData = messages
+
+	// First run: four ticks are processed and the offset persisted, but the process
+	// crashes before acknowledging and before processing the rest of the batch
+	mockRedis.AckErr = fmt.Errorf("connection lost")
+	firstAgg := &crashingAggregator{crashAfter: 4}
+	first := newOffsetTestConsumer(mockRedis, firstAgg)
+	first.processBatch("ticks", messages)
+
+	require.Len(t, firstAgg.GetTicks(), 4)
+	var offset string
+	require.NoError(t, mockRedis.GetJSON(nil, first.offsetKey("ticks"), &offset))
+	assert.Equal(t, "1000-4", offset)
+
+	// Restart: pending messages up to the offset are acknowledged without reprocessing,
+	// the remaining ones are processed
+	mockRedis.AckErr = nil
+	secondAgg := &MockAggregator{}
+	second := newOffsetTestConsumer(mockRedis, secondAgg)
+	require.NoError(t, second.recoverPending("ticks"))
+
+	recovered := secondAgg.GetTicks()
+	require.Len(t, recovered, 2)
+	assert.Equal(t, "SYM5", recovered[0].Symbol)
+	assert.Equal(t, "SYM6", recovered[1].Symbol)
+
+	// No message lost: every message was processed once and acknowledged
+	for _, msg := range messages {
+		assert.True(t, mockRedis.Acked[msg.ID], "message %s not acknowledged", msg.ID)
+	}
+	require.NoError(t, mockRedis.GetJSON(nil, second.offsetKey("ticks"), &offset))
+	assert.Equal(t, "1000-6", offset)
+}
+
+func TestStreamConsumer_PersistOffsets_NoOffsetReprocessesPending(t *testing.T) {
+	mockRedis := storage.NewMockRedisClient()
+	messages := newOffsetTestMessages(t, "ticks", 3)
+	mockRedis.StreamData = messages
+
+	// Crash before anything was committed: everything pending is reprocessed (at-least-once)
+	agg := &MockAggregator{}
+	consumer := newOffsetTestConsumer(mockRedis, agg)
+	require.NoError(t, consumer.recoverPending("ticks"))
+
+	assert.Len(t, agg.GetTicks(), 3)
+	assert.Len(t, mockRedis.Acked, 3)
+}
+
+func TestStreamConsumer_ClaimIdle(t *testing.T) {
+	mockRedis := storage.NewMockRedisClient()
+	messages := newOffsetTestMessages(t, "ticks", 3)
+	mockRedis.StreamData = messages
+
+	// Two ticks are left pending by a consumer that died under another name
+	mockRedis.PendingOwners = map[string]string{
+		messages[0].ID: "bars-consumer-old-host",
+		messages[2].ID: "bars-consumer-old-host",
+	}
+	require.NoError(t, mockRedis.Set(context.Background(), "stream:offset:ticks:bars:bars-consumer-1", "1000-1", 0))
+
+	agg := &MockAggregator{}
+	consumer := newOffsetTestConsumer(mockRedis, agg)
+	consumer.claimIdle("ticks")
+
+	ticks := agg.GetTicks()
+	require.Len(t, ticks, 2)
+	assert.Equal(t, "SYM1", ticks[0].Symbol)
+	assert.Equal(t, "SYM3", ticks[1].Symbol)
+	assert.True(t, mockRedis.Acked[messages[0].ID])
+	assert.True(t, mockRedis.Acked[messages[2].ID])
+	assert.Equal(t, "bars-consumer-1", mockRedis.PendingOwners[messages[2].ID])
+	assert.Equal(t, int64(2), consumer.GetStats().MessagesClaimed)
+
+	// Claimed messages never move the consumer's own offset
+	var offset string
+	require.NoError(t, mockRedis.GetJSON(context.Background(), consumer.offsetKey("ticks"), &offset))
+	assert.Equal(t, "1000-1", offset)
+}
+
+func TestStreamConsumer_PersistOffsets_StopsAtFirstFailure(t *testing.T) {
+	mockRedis := storage.NewMockRedisClient()
+	messages := newOffsetTestMessages(t, "ticks", 4)
+
+	// Second message fails: the committed offset must not move past it
+	messages[1].Values = map[string]interface{}{"tick": "not json"}
+
+	consumer := newOffsetTestConsumer(mockRedis, &MockAggregator{})
+	consumer.processBatch("ticks", messages)
+
+	var offset string
+	require.NoError(t, mockRedis.GetJSON(nil, consumer.offsetKey("ticks"), &offset))
+	assert.Equal(t, "1000-1", offset)
+
+	// Offsets never move backwards
+	consumer.commitOffset("ticks", "999-1")
+	require.NoError(t, mockRedis.GetJSON(nil, consumer.offsetKey("ticks"), &offset))
+	assert.Equal(t, "1000-1", offset)
+}
+
+// openStreamRedis delivers its stream messages on a channel that, like the real client's,
+// stays open until the consumer stops reading
+type openStreamRedis struct {
+	*storage.MockRedisClient
+	messages []storage.StreamMessage
+}
+
+func (r *openStreamRedis) ConsumeFromStream(ctx context.Context, stream string, group string, consumer string) (<-chan storage.StreamMessage, error) {
+	ch := make(chan storage.StreamMessage, len(r.messages))
+	for _, msg := range r.messages {
+		ch <- msg
+	}
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch, nil
+}
+
+// blockingAggregator records ticks and blocks on the tick at index blockAt until released
+type blockingAggregator struct {
+	MockAggregator
+	blockAt int
+	blocked chan struct{}
+	release chan struct{}
+}
+
+func (a *blockingAggregator) ProcessTick(tick *models.Tick) error {
+	if len(a.GetTicks()) == a.blockAt {
+		close(a.blocked)
+		<-a.release
+	}
+	return a.MockAggregator.ProcessTick(tick)
+}
+
+func newDrainTestConsumer(redis storage.RedisClient, aggregator AggregatorInterface, batchSize int) *StreamConsumer {
+	config := DefaultStreamConsumerConfig("ticks", "bars", "bars-consumer-1")
+	config.BatchSize = batchSize
+	config.AckTimeout = time.Minute // Only full batches are processed while running
+	config.BacklogInterval = 0
+	consumer := NewStreamConsumer(redis, config)
+	consumer.SetAggregator(aggregator)
+	return consumer
+}
+
+func TestStreamConsumer_StopGraceful_DrainsMidBatch(t *testing.T) {
+	messages := newOffsetTestMessages(t, "ticks", 6)
+	mockRedis := &openStreamRedis{MockRedisClient: storage.NewMockRedisClient(), messages: messages}
+	agg := &MockAggregator{}
+
+	// Batches of 4: the first is processed, the last two messages wait for a full batch
+	consumer := newDrainTestConsumer(mockRedis, agg, 4)
+	require.NoError(t, consumer.Start())
+	require.Eventually(t, func() bool { return len(agg.GetTicks()) == 4 }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, consumer.StopGraceful(time.Second))
+	assert.False(t, consumer.IsRunning())
+
+	// The partial batch was processed and acknowledged before returning
+	assert.Len(t, agg.GetTicks(), 6)
+	for _, msg := range messages {
+		assert.True(t, mockRedis.Acked[msg.ID], "message %s not acknowledged", msg.ID)
+	}
+	stats := consumer.GetStats()
+	assert.Equal(t, int64(2), stats.MessagesDrained)
+	assert.Equal(t, int64(0), stats.MessagesAbandoned)
+	assert.Equal(t, int64(6), stats.MessagesAcked)
+}
+
+func TestStreamConsumer_StopGraceful_TimeoutAbandons(t *testing.T) {
+	messages := newOffsetTestMessages(t, "ticks", 6)
+	mockRedis := &openStreamRedis{MockRedisClient: storage.NewMockRedisClient(), messages: messages}
+	agg := &blockingAggregator{blockAt: 2, blocked: make(chan struct{}), release: make(chan struct{})}
+
+	// Batches of 2: processing blocks in the second batch
+	consumer := newDrainTestConsumer(mockRedis, agg, 2)
+	require.NoError(t, consumer.Start())
+	<-agg.blocked
+
+	// The batch in progress finishes once released, after the drain timed out
+	time.AfterFunc(100*time.Millisecond, func() { close(agg.release) })
+	err := consumer.StopGraceful(20 * time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 messages abandoned")
+
+	// The batch in progress is acknowledged, the messages not yet processed are left pending
+	assert.Len(t, agg.GetTicks(), 4)
+	for i, msg := range messages {
+		assert.Equal(t, i < 4, mockRedis.Acked[msg.ID], "message %s", msg.ID)
+	}
+	stats := consumer.GetStats()
+	assert.Equal(t, int64(0), stats.MessagesDrained)
+	assert.Equal(t, int64(2), stats.MessagesAbandoned)
+}
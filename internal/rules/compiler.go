@@ -1,6 +1,7 @@
 package rules
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
@@ -8,7 +9,11 @@ import (
 
 // Compiler compiles rules into executable functions
 type Compiler struct {
-	resolver MetricResolver
+	resolver        MetricResolver
+	customMetrics   *CustomMetricRegistry // Optional user-scoped custom metrics
+	hysteresis      *HysteresisTracker    // Latched state of conditions with a hysteresis band
+	previousMetrics PreviousMetricsFunc   // Optional previous scan cycle metrics for crossing conditions
+	session         SessionFunc           // Optional market session source for condition filters
 }
 
 // NewCompiler creates a new rule compiler
@@ -18,10 +23,38 @@ func NewCompiler(resolver MetricResolver) *Compiler {
 	}
 
 	return &Compiler{
-		resolver: resolver,
+		resolver:   resolver,
+		hysteresis: NewHysteresisTracker(),
 	}
 }
 
+// Detached returns a compiler with the same resolver, custom metrics and state sources but
+// its own hysteresis state, for evaluating rules that are not active (e.g. dry runs) without
+// touching the latches of active rules
+func (c *Compiler) Detached() *Compiler {
+	detached := *c
+	detached.hysteresis = NewHysteresisTracker()
+	return &detached
+}
+
+// SetCustomMetricRegistry enables resolution of user custom metrics for rules owned by a user
+func (c *Compiler) SetCustomMetricRegistry(registry *CustomMetricRegistry) {
+	c.customMetrics = registry
+}
+
+// CustomMetricRegistry returns the custom metric registry (nil if not configured)
+func (c *Compiler) CustomMetricRegistry() *CustomMetricRegistry {
+	return c.customMetrics
+}
+
+// resolverFor returns the resolver for a rule, including its owner's custom metrics
+func (c *Compiler) resolverFor(rule *models.Rule) MetricResolver {
+	if c.customMetrics == nil {
+		return c.resolver
+	}
+	return c.customMetrics.ResolverFor(rule.UserID, c.resolver)
+}
+
 // CompileRule compiles a rule into a CompiledRule function
 func (c *Compiler) CompileRule(rule *models.Rule) (CompiledRule, error) {
 	if rule == nil {
@@ -33,20 +66,57 @@ func (c *Compiler) CompileRule(rule *models.Rule) (CompiledRule, error) {
 		return nil, fmt.Errorf("invalid rule: %w", err)
 	}
 
-	// Store conditions for the compiled function
-	conditions := rule.Conditions
+	return c.compileGroup(rule.ID+"|entry", rule.EntryGroup(), c.resolverFor(rule), rule.MatchOnMissing), nil
+}
+
+// CompileExitConditions compiles a rule's exit conditions into a CompiledRule function
+// Returns nil if the rule has no exit conditions
+func (c *Compiler) CompileExitConditions(rule *models.Rule) (CompiledRule, error) {
+	if rule == nil {
+		return nil, fmt.Errorf("rule cannot be nil")
+	}
+	if !rule.HasExitConditions() {
+		return nil, nil
+	}
+
+	if err := ValidateRule(rule); err != nil {
+		return nil, fmt.Errorf("invalid rule: %w", err)
+	}
 
-	// Create compiled function
-	compiled := func(symbol string, metrics map[string]float64) (bool, error) {
+	return c.compileConditions(rule.ID+"|exit", rule.ExitConditions, c.resolverFor(rule), rule.MatchOnMissing), nil
+}
+
+// compileConditions compiles conditions into a CompiledRule function (AND logic)
+// statePrefix identifies the rule and condition set in the hysteresis tracker
+func (c *Compiler) compileConditions(statePrefix string, conditions []models.Condition, resolver MetricResolver, matchOnMissing bool) CompiledRule {
+	ids := make([]string, len(conditions))
+	for i := range conditions {
+		ids[i] = conditionID(&conditions[i])
+	}
+
+	// settle evaluates the hysteresis conditions from start on once the result is known, so
+	// their latches follow the metric
+	settle := func(start int, symbol string, metrics map[string]float64) {
+		for i := start; i < len(conditions); i++ {
+			if isLatched(&conditions[i]) {
+				_, _ = c.evaluateCondition(&conditions[i], symbol, hysteresisKey(statePrefix, ids[i], symbol), resolver, metrics, matchOnMissing)
+			}
+		}
+	}
+
+	return func(symbol string, metrics map[string]float64) (bool, error) {
 		// Evaluate all conditions (AND logic - all must be true)
-		for i, cond := range conditions {
-			matched, err := EvaluateCondition(&cond, c.resolver, metrics)
+		for i := range conditions {
+			cond := &conditions[i]
+			matched, err := c.evaluateCondition(cond, symbol, hysteresisKey(statePrefix, ids[i], symbol), resolver, metrics, matchOnMissing)
 			if err != nil {
+				settle(i+1, symbol, metrics)
 				return false, fmt.Errorf("condition %d (metric: %s): %w", i, cond.Metric, err)
 			}
 
 			// If any condition fails, the rule doesn't match
 			if !matched {
+				settle(i+1, symbol, metrics)
 				return false, nil
 			}
 		}
@@ -54,13 +124,140 @@ func (c *Compiler) CompileRule(rule *models.Rule) (CompiledRule, error) {
 		// All conditions matched
 		return true, nil
 	}
+}
 
-	return compiled, nil
+// groupMember is a compiled condition or nested group of a condition group
+type groupMember struct {
+	eval    CompiledRule
+	latched bool // Holds hysteresis latches, so it is evaluated even once the group's result is known
+}
+
+// compileGroup compiles a condition group into a CompiledRule function. AND groups are decided
+// by the first member that does not match, OR groups by the first member that matches; the
+// remaining members are only evaluated if they hold hysteresis latches, so every latch follows
+// its metric. An error in an OR member only fails the group if no other member matches. A
+// condition failing its volume threshold or session filter does not match, so in an OR group
+// only its own branch fails.
+// statePrefix identifies the rule and condition set in the hysteresis tracker; conditions are
+// keyed by conditionID, so nested groups share it
+func (c *Compiler) compileGroup(statePrefix string, group models.ConditionGroup, resolver MetricResolver, matchOnMissing bool) CompiledRule {
+	members := make([]groupMember, 0, len(group.Conditions)+len(group.Groups))
+	for i := range group.Conditions {
+		cond := group.Conditions[i]
+		index := i
+		id := conditionID(&cond)
+		members = append(members, groupMember{
+			latched: isLatched(&cond),
+			eval: func(symbol string, metrics map[string]float64) (bool, error) {
+				if !c.conditionFiltersPass(&cond, symbol, metrics) {
+					return false, nil
+				}
+				matched, err := c.evaluateCondition(&cond, symbol, hysteresisKey(statePrefix, id, symbol), resolver, metrics, matchOnMissing)
+				if err != nil {
+					return false, fmt.Errorf("condition %d (metric: %s): %w", index, cond.Metric, err)
+				}
+				return matched, nil
+			},
+		})
+	}
+	for i := range group.Groups {
+		nested := group.Groups[i]
+		index := i
+		compiled := c.compileGroup(statePrefix, nested, resolver, matchOnMissing)
+		members = append(members, groupMember{
+			latched: hasHysteresis(&nested),
+			eval: func(symbol string, metrics map[string]float64) (bool, error) {
+				matched, err := compiled(symbol, metrics)
+				if err != nil {
+					return false, fmt.Errorf("group %d: %w", index, err)
+				}
+				return matched, nil
+			},
+		})
+	}
+
+	// settle evaluates the latched members from start on once the group's result is known
+	settle := func(start int, symbol string, metrics map[string]float64) {
+		for _, member := range members[start:] {
+			if member.latched {
+				_, _ = member.eval(symbol, metrics)
+			}
+		}
+	}
+
+	if !group.IsOr() {
+		return func(symbol string, metrics map[string]float64) (bool, error) {
+			for i, member := range members {
+				matched, err := member.eval(symbol, metrics)
+				if err != nil || !matched {
+					settle(i+1, symbol, metrics)
+					return false, err
+				}
+			}
+			return true, nil
+		}
+	}
+
+	return func(symbol string, metrics map[string]float64) (bool, error) {
+		var firstErr error
+		for i, member := range members {
+			matched, err := member.eval(symbol, metrics)
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			if matched {
+				settle(i+1, symbol, metrics)
+				return true, nil
+			}
+		}
+		return false, firstErr
+	}
+}
+
+// evaluateCondition evaluates a condition, applying the rule's missing metric policy: a
+// condition whose metric has no value matches only if matchOnMissing is set
+func (c *Compiler) evaluateCondition(cond *models.Condition, symbol, key string, resolver MetricResolver, metrics map[string]float64, matchOnMissing bool) (bool, error) {
+	matched, err := c.evaluateConditionValue(cond, symbol, key, resolver, metrics)
+	if errors.Is(err, ErrMetricNotFound) {
+		return matchOnMissing, nil
+	}
+	return matched, err
+}
+
+// evaluateConditionValue evaluates a condition, applying its hysteresis band while it is matched
+func (c *Compiler) evaluateConditionValue(cond *models.Condition, symbol, key string, resolver MetricResolver, metrics map[string]float64) (bool, error) {
+	if cond.IsCrossing() {
+		return c.evaluateCrossing(cond, symbol, resolver, metrics)
+	}
+	if cond.HysteresisBand == nil {
+		return EvaluateCondition(cond, resolver, metrics)
+	}
+
+	effective := cond
+	if c.hysteresis.isMatched(key) {
+		released, err := releaseCondition(cond)
+		if err != nil {
+			return false, fmt.Errorf("invalid comparison value: %w", err)
+		}
+		effective = released
+	}
+
+	matched, err := EvaluateCondition(effective, resolver, metrics)
+	if err != nil {
+		return false, err
+	}
+	c.hysteresis.setMatched(key, matched)
+	return matched, nil
 }
 
 // CompileRules compiles multiple rules into CompiledRule functions
+// Hysteresis state of rules not in the set is dropped
 func (c *Compiler) CompileRules(rules []*models.Rule) (map[string]CompiledRule, error) {
 	compiled := make(map[string]CompiledRule)
+	ruleIDs := make(map[string]bool, len(rules))
 
 	for _, rule := range rules {
 		compiledRule, err := c.CompileRule(rule)
@@ -69,6 +266,28 @@ func (c *Compiler) CompileRules(rules []*models.Rule) (map[string]CompiledRule,
 		}
 
 		compiled[rule.ID] = compiledRule
+		ruleIDs[rule.ID] = true
+	}
+
+	c.hysteresis.Retain(ruleIDs)
+	return compiled, nil
+}
+
+// CompileExitRules compiles the exit conditions of rules that define them
+func (c *Compiler) CompileExitRules(rules []*models.Rule) (map[string]CompiledRule, error) {
+	compiled := make(map[string]CompiledRule)
+
+	for _, rule := range rules {
+		if !rule.Enabled || !rule.HasExitConditions() {
+			continue
+		}
+
+		compiledExit, err := c.CompileExitConditions(rule)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile exit conditions for rule %s: %w", rule.ID, err)
+		}
+
+		compiled[rule.ID] = compiledExit
 	}
 
 	return compiled, nil
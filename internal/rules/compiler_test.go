@@ -149,15 +149,35 @@ func TestCompiler_CompileRule_MissingMetric(t *testing.T) {
 		t.Fatalf("CompileRule() error = %v", err)
 	}
 
-	// Test with missing metric
-	metrics := map[string]float64{} // Empty metrics
-	matched, err := compiled("AAPL", metrics)
-	if err == nil {
-		t.Error("Expected error when metric is missing")
+	// A metric that is present is compared, even when it is 0
+	matched, err := compiled("AAPL", map[string]float64{"rsi_14": 0})
+	if err != nil || !matched {
+		t.Errorf("Expected rule to match rsi_14=0, got matched=%v err=%v", matched, err)
+	}
+
+	// A missing metric (e.g. not enough history) is a non-match, not an error
+	matched, err = compiled("AAPL", map[string]float64{})
+	if err != nil {
+		t.Errorf("Expected no error when metric is missing, got %v", err)
 	}
 	if matched {
 		t.Error("Expected rule not to match when metric is missing")
 	}
+
+	// Rules can opt into treating a missing metric as a match
+	rule.MatchOnMissing = true
+	compiled, err = compiler.CompileRule(rule)
+	if err != nil {
+		t.Fatalf("CompileRule() error = %v", err)
+	}
+	matched, err = compiled("AAPL", map[string]float64{})
+	if err != nil || !matched {
+		t.Errorf("Expected rule to match a missing metric with MatchOnMissing, got matched=%v err=%v", matched, err)
+	}
+	// Present metrics are still compared
+	if matched, _ := compiled("AAPL", map[string]float64{"rsi_14": 50}); matched {
+		t.Error("Expected rule not to match rsi_14=50 with MatchOnMissing")
+	}
 }
 
 func TestCompiler_CompileRules(t *testing.T) {
@@ -254,6 +274,53 @@ func TestCompiler_CompileEnabledRules(t *testing.T) {
 	}
 }
 
+func TestCompiler_CompileExitRules(t *testing.T) {
+	compiler := NewCompiler(nil)
+
+	withExit := &models.Rule{
+		ID:   "rule-exit",
+		Name: "RSI Round Trip",
+		Conditions: []models.Condition{
+			{Metric: "rsi_14", Operator: "<", Value: 30.0},
+		},
+		ExitConditions: []models.Condition{
+			{Metric: "rsi_14", Operator: ">", Value: 50.0},
+		},
+		Enabled: true,
+	}
+	withoutExit := &models.Rule{
+		ID:   "rule-no-exit",
+		Name: "RSI Oversold",
+		Conditions: []models.Condition{
+			{Metric: "rsi_14", Operator: "<", Value: 30.0},
+		},
+		Enabled: true,
+	}
+
+	compiled, err := compiler.CompileExitRules([]*models.Rule{withExit, withoutExit})
+	if err != nil {
+		t.Fatalf("CompileExitRules() error = %v", err)
+	}
+	if len(compiled) != 1 || compiled["rule-exit"] == nil {
+		t.Fatalf("Expected only rule-exit to have compiled exit conditions, got %d", len(compiled))
+	}
+
+	matched, err := compiled["rule-exit"]("AAPL", map[string]float64{"rsi_14": 55.0})
+	if err != nil || !matched {
+		t.Errorf("Expected exit conditions to match, matched=%v err=%v", matched, err)
+	}
+	matched, err = compiled["rule-exit"]("AAPL", map[string]float64{"rsi_14": 25.0})
+	if err != nil || matched {
+		t.Errorf("Expected exit conditions not to match, matched=%v err=%v", matched, err)
+	}
+
+	// Invalid exit conditions fail compilation
+	withExit.ExitConditions[0].Operator = "~"
+	if _, err := compiler.CompileExitRules([]*models.Rule{withExit}); err == nil {
+		t.Error("Expected error for invalid exit condition")
+	}
+}
+
 func TestNewCompiler(t *testing.T) {
 	// Test with nil resolver (should create default)
 	compiler := NewCompiler(nil)
@@ -272,3 +339,137 @@ func TestNewCompiler(t *testing.T) {
 	}
 }
 
+
+func TestCompiler_CompileRule_OrLogic(t *testing.T) {
+	compiler := NewCompiler(nil)
+
+	rule := &models.Rule{
+		ID:            "rule-or",
+		Name:          "Oversold or Momentum",
+		LogicOperator: models.LogicOr,
+		Conditions: []models.Condition{
+			{Metric: "rsi_14", Operator: "<", Value: 30.0},
+			{Metric: "price_change_5m_pct", Operator: ">", Value: 5.0},
+		},
+		Enabled: true,
+	}
+
+	compiled, err := compiler.CompileRule(rule)
+	if err != nil {
+		t.Fatalf("CompileRule() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		metrics map[string]float64
+		want    bool
+	}{
+		{"first branch", map[string]float64{"rsi_14": 25.0, "price_change_5m_pct": 1.0}, true},
+		{"second branch", map[string]float64{"rsi_14": 50.0, "price_change_5m_pct": 6.0}, true},
+		{"neither branch", map[string]float64{"rsi_14": 50.0, "price_change_5m_pct": 1.0}, false},
+		// Short-circuits on the first matching branch, the missing metric is never resolved
+		{"short-circuit", map[string]float64{"rsi_14": 25.0}, true},
+		// A branch with a missing metric does not match, another branch can
+		{"missing metric branch", map[string]float64{"price_change_5m_pct": 6.0}, true},
+		{"missing metric, no branch", map[string]float64{"price_change_5m_pct": 1.0}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, err := compiled("AAPL", tt.metrics)
+			if err != nil {
+				t.Fatalf("compiled rule evaluation error = %v", err)
+			}
+			if matched != tt.want {
+				t.Errorf("matched = %v, want %v", matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompiler_CompileRule_NestedGroups(t *testing.T) {
+	compiler := NewCompiler(nil)
+
+	// volume_daily > 1M AND (rsi_14 < 30 OR (price_change_5m_pct > 5 AND relative_volume > 2))
+	rule := &models.Rule{
+		ID:   "rule-nested",
+		Name: "Liquid Setups",
+		Conditions: []models.Condition{
+			{Metric: "volume_daily", Operator: ">", Value: 1000000.0},
+		},
+		ConditionGroups: []models.ConditionGroup{
+			{
+				Operator: models.LogicOr,
+				Conditions: []models.Condition{
+					{Metric: "rsi_14", Operator: "<", Value: 30.0},
+				},
+				Groups: []models.ConditionGroup{
+					{
+						Conditions: []models.Condition{
+							{Metric: "price_change_5m_pct", Operator: ">", Value: 5.0},
+							{Metric: "relative_volume", Operator: ">", Value: 2.0},
+						},
+					},
+				},
+			},
+		},
+		Enabled: true,
+	}
+
+	compiled, err := compiler.CompileRule(rule)
+	if err != nil {
+		t.Fatalf("CompileRule() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		metrics map[string]float64
+		want    bool
+	}{
+		{"oversold", map[string]float64{"volume_daily": 2e6, "rsi_14": 25, "price_change_5m_pct": 0, "relative_volume": 1}, true},
+		{"momentum", map[string]float64{"volume_daily": 2e6, "rsi_14": 50, "price_change_5m_pct": 6, "relative_volume": 3}, true},
+		{"momentum without volume", map[string]float64{"volume_daily": 2e6, "rsi_14": 50, "price_change_5m_pct": 6, "relative_volume": 1}, false},
+		{"illiquid", map[string]float64{"volume_daily": 5e5, "rsi_14": 25, "price_change_5m_pct": 6, "relative_volume": 3}, false},
+		// The AND short-circuits before the group's metrics are resolved
+		{"illiquid short-circuit", map[string]float64{"volume_daily": 5e5}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, err := compiled("AAPL", tt.metrics)
+			if err != nil {
+				t.Fatalf("compiled rule evaluation error = %v", err)
+			}
+			if matched != tt.want {
+				t.Errorf("matched = %v, want %v", matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompiler_CompileRule_InvalidGroups(t *testing.T) {
+	compiler := NewCompiler(nil)
+	condition := models.Condition{Metric: "rsi_14", Operator: "<", Value: 30.0}
+
+	rules := map[string]*models.Rule{
+		"invalid rule operator": {ID: "r", Name: "r", LogicOperator: "XOR", Conditions: []models.Condition{condition}},
+		"invalid group operator": {ID: "r", Name: "r", ConditionGroups: []models.ConditionGroup{
+			{Operator: "NOT", Conditions: []models.Condition{condition}},
+		}},
+		"empty group": {ID: "r", Name: "r", Conditions: []models.Condition{condition}, ConditionGroups: []models.ConditionGroup{{}}},
+		"invalid nested condition": {ID: "r", Name: "r", ConditionGroups: []models.ConditionGroup{
+			{Groups: []models.ConditionGroup{{Conditions: []models.Condition{{Metric: "rsi_14", Operator: "~", Value: 1.0}}}}},
+		}},
+	}
+	for name, rule := range rules {
+		if _, err := compiler.CompileRule(rule); err == nil {
+			t.Errorf("%s: expected CompileRule() error", name)
+		}
+	}
+
+	// Groups alone are enough conditions
+	groupsOnly := &models.Rule{ID: "r", Name: "r", ConditionGroups: []models.ConditionGroup{
+		{Operator: models.LogicOr, Conditions: []models.Condition{condition}},
+	}}
+	if _, err := compiler.CompileRule(groupsOnly); err != nil {
+		t.Errorf("CompileRule() error = %v for a rule with only condition groups", err)
+	}
+}
@@ -0,0 +1,78 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// PreviousMetricsFunc returns a symbol's metric values from its previous scan cycle
+// (nil if the symbol has not been scanned before)
+type PreviousMetricsFunc func(symbol string) map[string]float64
+
+// SetPreviousMetrics sets the source of previous scan cycle metrics for crossing conditions.
+// Without it crossing conditions never match.
+func (c *Compiler) SetPreviousMetrics(fn PreviousMetricsFunc) {
+	c.previousMetrics = fn
+}
+
+// UsesCrossing returns whether any of the rule's entry or exit conditions uses a crossing operator
+func UsesCrossing(rule *models.Rule) bool {
+	for _, cond := range ruleConditions(rule) {
+		if cond.IsCrossing() {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionThreshold returns the value a condition compares against: its numeric value, or
+// for crossing conditions with a metric name value, that metric's value
+func conditionThreshold(cond *models.Condition, resolver MetricResolver, metrics map[string]float64) (float64, error) {
+	if name, ok := cond.Value.(string); ok && cond.IsCrossing() {
+		return resolver.ResolveMetric(name, metrics)
+	}
+	return getNumericValue(cond.Value)
+}
+
+// evaluateCrossing evaluates a crossing condition against the symbol's previous scan cycle.
+// It does not match on a symbol's first scan, or when the metric or threshold was missing
+// or NaN in either cycle.
+func (c *Compiler) evaluateCrossing(cond *models.Condition, symbol string, resolver MetricResolver, metrics map[string]float64) (bool, error) {
+	current, err := resolver.ResolveMetric(cond.Metric, metrics)
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve metric '%s': %w", cond.Metric, err)
+	}
+	threshold, err := conditionThreshold(cond, resolver, metrics)
+	if err != nil {
+		return false, fmt.Errorf("invalid comparison value: %w", err)
+	}
+	if math.IsNaN(current) || math.IsNaN(threshold) {
+		return false, nil
+	}
+
+	if c.previousMetrics == nil {
+		return false, nil
+	}
+	previous := c.previousMetrics(symbol)
+	if previous == nil {
+		return false, nil // First scan of the symbol
+	}
+	prevValue, err := resolver.ResolveMetric(cond.Metric, previous)
+	if err != nil {
+		return false, nil
+	}
+	prevThreshold, err := conditionThreshold(cond, resolver, previous)
+	if err != nil {
+		return false, nil
+	}
+	if math.IsNaN(prevValue) || math.IsNaN(prevThreshold) {
+		return false, nil
+	}
+
+	if cond.Operator == models.OperatorCrossesAbove {
+		return prevValue <= prevThreshold && current > threshold, nil
+	}
+	return prevValue >= prevThreshold && current < threshold, nil
+}
@@ -0,0 +1,119 @@
+package rules
+
+import (
+	"math"
+	"testing"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// crossingTestCompiler returns a compiler whose previous metrics are read from prev
+func crossingTestCompiler(prev map[string]map[string]float64) *Compiler {
+	compiler := NewCompiler(nil)
+	compiler.SetPreviousMetrics(func(symbol string) map[string]float64 {
+		return prev[symbol]
+	})
+	return compiler
+}
+
+func TestCompiler_CrossingConditions(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator string
+		value    interface{}
+		previous map[string]float64
+		current  map[string]float64
+		want     bool
+	}{
+		{"upward cross of a metric", models.OperatorCrossesAbove, "vwap_5m",
+			map[string]float64{"ema_20": 99, "vwap_5m": 100}, map[string]float64{"ema_20": 101, "vwap_5m": 100}, true},
+		{"upward cross of a number", models.OperatorCrossesAbove, 100.0,
+			map[string]float64{"ema_20": 100}, map[string]float64{"ema_20": 100.5}, true},
+		{"downward cross", models.OperatorCrossesBelow, "vwap_5m",
+			map[string]float64{"ema_20": 101, "vwap_5m": 100}, map[string]float64{"ema_20": 99, "vwap_5m": 100}, true},
+		{"downward cross by the threshold rising", models.OperatorCrossesBelow, "vwap_5m",
+			map[string]float64{"ema_20": 100, "vwap_5m": 99}, map[string]float64{"ema_20": 100, "vwap_5m": 101}, true},
+		{"staying above", models.OperatorCrossesAbove, "vwap_5m",
+			map[string]float64{"ema_20": 101, "vwap_5m": 100}, map[string]float64{"ema_20": 102, "vwap_5m": 100}, false},
+		{"staying below", models.OperatorCrossesBelow, 100.0,
+			map[string]float64{"ema_20": 98}, map[string]float64{"ema_20": 97}, false},
+		{"crossing the other way", models.OperatorCrossesAbove, 100.0,
+			map[string]float64{"ema_20": 101}, map[string]float64{"ema_20": 99}, false},
+		{"first scan", models.OperatorCrossesAbove, 100.0,
+			nil, map[string]float64{"ema_20": 101}, false},
+		{"metric missing in previous scan", models.OperatorCrossesAbove, 100.0,
+			map[string]float64{"volume": 1}, map[string]float64{"ema_20": 101}, false},
+		{"metric NaN in previous scan", models.OperatorCrossesAbove, 100.0,
+			map[string]float64{"ema_20": math.NaN()}, map[string]float64{"ema_20": 101}, false},
+		{"metric NaN in current scan", models.OperatorCrossesBelow, 100.0,
+			map[string]float64{"ema_20": 101}, map[string]float64{"ema_20": math.NaN()}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiler := crossingTestCompiler(map[string]map[string]float64{"AAPL": tt.previous})
+			rule := &models.Rule{
+				ID:         "rule-cross",
+				Name:       "EMA Cross",
+				Conditions: []models.Condition{{Metric: "ema_20", Operator: tt.operator, Value: tt.value}},
+				Enabled:    true,
+			}
+
+			compiled, err := compiler.CompileRule(rule)
+			if err != nil {
+				t.Fatalf("CompileRule() error = %v", err)
+			}
+			matched, err := compiled("AAPL", tt.current)
+			if err != nil {
+				t.Fatalf("compiled rule evaluation error = %v", err)
+			}
+			if matched != tt.want {
+				t.Errorf("matched = %v, want %v", matched, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompiler_CrossingWithoutPreviousMetrics(t *testing.T) {
+	rule := &models.Rule{
+		ID:         "rule-cross",
+		Name:       "EMA Cross",
+		Conditions: []models.Condition{{Metric: "ema_20", Operator: models.OperatorCrossesAbove, Value: 100.0}},
+		Enabled:    true,
+	}
+
+	compiled, err := NewCompiler(nil).CompileRule(rule)
+	if err != nil {
+		t.Fatalf("CompileRule() error = %v", err)
+	}
+	if matched, err := compiled("AAPL", map[string]float64{"ema_20": 101}); err != nil || matched {
+		t.Errorf("Expected no match without previous metrics, got %v (err %v)", matched, err)
+	}
+}
+
+func TestValidateRule_CrossingConditions(t *testing.T) {
+	band := 1.0
+	invalid := map[string]models.Condition{
+		"invalid metric name value": {Metric: "ema_20", Operator: models.OperatorCrossesAbove, Value: "vwap-5m"},
+		"hysteresis band":           {Metric: "ema_20", Operator: models.OperatorCrossesAbove, Value: 100.0, HysteresisBand: &band},
+	}
+	for name, cond := range invalid {
+		rule := &models.Rule{ID: "r", Name: "r", Conditions: []models.Condition{cond}}
+		if err := ValidateRule(rule); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+
+	rule := &models.Rule{ID: "r", Name: "r", Enabled: true, Conditions: []models.Condition{
+		{Metric: "ema_20", Operator: models.OperatorCrossesBelow, Value: "vwap_5m"},
+	}}
+	if err := ValidateRule(rule); err != nil {
+		t.Errorf("ValidateRule() error = %v", err)
+	}
+	if !UsesCrossing(rule) {
+		t.Error("Expected UsesCrossing to report the crossing condition")
+	}
+	if required := ExtractRequiredMetricsFromRule(rule); !required["ema_20"] || !required["vwap_5m"] {
+		t.Errorf("Expected both crossing metrics to be required, got %v", required)
+	}
+}
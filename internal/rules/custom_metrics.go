@@ -0,0 +1,462 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// MetricExpression is a parsed custom metric expression
+// Supports + - * / with the usual precedence, parentheses, unary minus,
+// numeric literals and metric names.
+type MetricExpression struct {
+	source  string
+	root    exprNode
+	metrics []string
+}
+
+// exprNode is a node of a parsed metric expression
+type exprNode interface {
+	eval(resolve func(name string) (float64, error)) (float64, error)
+}
+
+type numberNode float64
+
+func (n numberNode) eval(func(string) (float64, error)) (float64, error) {
+	return float64(n), nil
+}
+
+type metricNode string
+
+func (n metricNode) eval(resolve func(string) (float64, error)) (float64, error) {
+	return resolve(string(n))
+}
+
+type negateNode struct {
+	operand exprNode
+}
+
+func (n negateNode) eval(resolve func(string) (float64, error)) (float64, error) {
+	value, err := n.operand.eval(resolve)
+	return -value, err
+}
+
+type binaryNode struct {
+	op          byte
+	left, right exprNode
+}
+
+func (n binaryNode) eval(resolve func(string) (float64, error)) (float64, error) {
+	left, err := n.left.eval(resolve)
+	if err != nil {
+		return 0, err
+	}
+	right, err := n.right.eval(resolve)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case '+':
+		return left + right, nil
+	case '-':
+		return left - right, nil
+	case '*':
+		return left * right, nil
+	default:
+		if right == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return left / right, nil
+	}
+}
+
+// ParseMetricExpression parses a custom metric expression
+func ParseMetricExpression(expression string) (*MetricExpression, error) {
+	p := &exprParser{input: expression, seen: make(map[string]bool)}
+
+	root, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected character %q at position %d", p.input[p.pos], p.pos)
+	}
+
+	return &MetricExpression{source: expression, root: root, metrics: p.metrics}, nil
+}
+
+// String returns the source expression
+func (e *MetricExpression) String() string {
+	return e.source
+}
+
+// Metrics returns the metric names referenced by the expression
+func (e *MetricExpression) Metrics() []string {
+	return e.metrics
+}
+
+// Evaluate evaluates the expression, resolving metric names with resolve
+func (e *MetricExpression) Evaluate(resolve func(name string) (float64, error)) (float64, error) {
+	return e.root.eval(resolve)
+}
+
+// exprParser is a recursive descent parser for metric expressions
+type exprParser struct {
+	input   string
+	pos     int
+	metrics []string
+	seen    map[string]bool
+}
+
+func (p *exprParser) skipSpaces() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// parseSum parses term (('+' | '-') term)*
+func (p *exprParser) parseSum() (exprNode, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.input) || (p.input[p.pos] != '+' && p.input[p.pos] != '-') {
+			return left, nil
+		}
+		op := p.input[p.pos]
+		p.pos++
+
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+// parseProduct parses factor (('*' | '/') factor)*
+func (p *exprParser) parseProduct() (exprNode, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		p.skipSpaces()
+		if p.pos >= len(p.input) || (p.input[p.pos] != '*' && p.input[p.pos] != '/') {
+			return left, nil
+		}
+		op := p.input[p.pos]
+		p.pos++
+
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+// parseFactor parses a number, metric name, parenthesized expression or unary minus
+func (p *exprParser) parseFactor() (exprNode, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	c := p.input[p.pos]
+	switch {
+	case c == '(':
+		p.pos++
+		node, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if p.pos >= len(p.input) || p.input[p.pos] != ')' {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return node, nil
+
+	case c == '-':
+		p.pos++
+		operand, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return negateNode{operand: operand}, nil
+
+	case (c >= '0' && c <= '9') || c == '.':
+		start := p.pos
+		for p.pos < len(p.input) && ((p.input[p.pos] >= '0' && p.input[p.pos] <= '9') || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", p.input[start:p.pos])
+		}
+		return numberNode(value), nil
+
+	case isMetricNameChar(c):
+		start := p.pos
+		for p.pos < len(p.input) && isMetricNameChar(p.input[p.pos]) {
+			p.pos++
+		}
+		name := p.input[start:p.pos]
+		if !p.seen[name] {
+			p.seen[name] = true
+			p.metrics = append(p.metrics, name)
+		}
+		return metricNode(name), nil
+	}
+
+	return nil, fmt.Errorf("unexpected character %q at position %d", c, p.pos)
+}
+
+func isMetricNameChar(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_'
+}
+
+// customMetricDef is a registered custom metric with its parsed expression
+type customMetricDef struct {
+	metric     *models.CustomMetric
+	expression *MetricExpression
+}
+
+// CustomMetricRegistry holds user-scoped custom metric definitions.
+// A user's rules and toplists resolve only that user's definitions, and custom
+// metrics can never shadow system metrics.
+type CustomMetricRegistry struct {
+	mu            sync.RWMutex
+	systemMetrics map[string]bool
+	users         map[string]map[string]*customMetricDef // user ID -> name -> definition
+}
+
+// NewCustomMetricRegistry creates a registry; systemMetrics are names users cannot redefine
+func NewCustomMetricRegistry(systemMetrics []string) *CustomMetricRegistry {
+	system := make(map[string]bool, len(systemMetrics))
+	for _, name := range systemMetrics {
+		system[name] = true
+	}
+
+	return &CustomMetricRegistry{
+		systemMetrics: system,
+		users:         make(map[string]map[string]*customMetricDef),
+	}
+}
+
+// Register adds or replaces a user's custom metric definition
+func (r *CustomMetricRegistry) Register(metric *models.CustomMetric) error {
+	if metric == nil {
+		return fmt.Errorf("custom metric cannot be nil")
+	}
+	if err := metric.Validate(); err != nil {
+		return err
+	}
+	if err := ValidateMetricName(metric.Name); err != nil {
+		return fmt.Errorf("%w: %v", models.ErrInvalidCustomMetricName, err)
+	}
+
+	expression, err := ParseMetricExpression(metric.Expression)
+	if err != nil {
+		return fmt.Errorf("%w: %v", models.ErrInvalidCustomMetricExpression, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.systemMetrics[metric.Name] {
+		return fmt.Errorf("%w: %s is a system metric", models.ErrInvalidCustomMetricName, metric.Name)
+	}
+
+	// Expressions are computed from system metrics only, which rules out cycles
+	userMetrics := r.users[metric.UserID]
+	for _, name := range expression.Metrics() {
+		if name == metric.Name || userMetrics[name] != nil {
+			return fmt.Errorf("%w: cannot reference custom metric %s", models.ErrInvalidCustomMetricExpression, name)
+		}
+	}
+
+	if userMetrics == nil {
+		userMetrics = make(map[string]*customMetricDef)
+		r.users[metric.UserID] = userMetrics
+	}
+
+	metricCopy := *metric
+	userMetrics[metric.Name] = &customMetricDef{metric: &metricCopy, expression: expression}
+
+	return nil
+}
+
+// Load replaces every definition with metrics (e.g. from storage.CustomMetricStore).
+// Invalid definitions are skipped and reported in the returned error; the valid ones are
+// still loaded.
+func (r *CustomMetricRegistry) Load(metrics []*models.CustomMetric) error {
+	loaded := NewCustomMetricRegistry(nil)
+	loaded.systemMetrics = r.systemMetrics
+
+	var errs []error
+	for _, metric := range metrics {
+		if err := loaded.Register(metric); err != nil {
+			errs = append(errs, fmt.Errorf("custom metric %s of user %s: %w", metric.Name, metric.UserID, err))
+		}
+	}
+
+	r.mu.Lock()
+	r.users = loaded.users
+	r.mu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// Remove deletes a user's custom metric definition
+func (r *CustomMetricRegistry) Remove(userID, name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	userMetrics := r.users[userID]
+	if userMetrics[name] == nil {
+		return false
+	}
+	delete(userMetrics, name)
+	if len(userMetrics) == 0 {
+		delete(r.users, userID)
+	}
+	return true
+}
+
+// Get returns a user's custom metric definition
+func (r *CustomMetricRegistry) Get(userID, name string) (*models.CustomMetric, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	def := r.users[userID][name]
+	if def == nil {
+		return nil, false
+	}
+	metricCopy := *def.metric
+	return &metricCopy, true
+}
+
+// List returns a user's custom metric definitions sorted by name
+func (r *CustomMetricRegistry) List(userID string) []*models.CustomMetric {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*models.CustomMetric, 0, len(r.users[userID]))
+	for _, def := range r.users[userID] {
+		metricCopy := *def.metric
+		result = append(result, &metricCopy)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// userDefinitions returns a snapshot of a user's definitions
+func (r *CustomMetricRegistry) userDefinitions(userID string) map[string]*MetricExpression {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	userMetrics := r.users[userID]
+	if len(userMetrics) == 0 {
+		return nil
+	}
+
+	defs := make(map[string]*MetricExpression, len(userMetrics))
+	for name, def := range userMetrics {
+		defs[name] = def.expression
+	}
+	return defs
+}
+
+// ResolverFor returns a resolver that also resolves the user's custom metrics.
+// Definitions are captured when called, so compiled rules keep the definitions
+// they were compiled with. Returns base when the user has no custom metrics.
+func (r *CustomMetricRegistry) ResolverFor(userID string, base MetricResolver) MetricResolver {
+	if userID == "" {
+		return base
+	}
+
+	defs := r.userDefinitions(userID)
+	if len(defs) == 0 {
+		return base
+	}
+
+	return &userMetricResolver{base: base, defs: defs}
+}
+
+// ResolveUserMetric computes a user's custom metric from the given metrics
+func (r *CustomMetricRegistry) ResolveUserMetric(userID, name string, metrics map[string]float64) (float64, bool) {
+	r.mu.RLock()
+	def := r.users[userID][name]
+	r.mu.RUnlock()
+
+	if def == nil {
+		return 0, false
+	}
+
+	value, err := def.expression.Evaluate(func(metric string) (float64, error) {
+		if v, ok := metrics[metric]; ok {
+			return v, nil
+		}
+		return 0, fmt.Errorf("metric '%s' not found", metric)
+	})
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// ExpandRequiredMetrics adds the metrics referenced by a user's custom metrics in required
+func (r *CustomMetricRegistry) ExpandRequiredMetrics(userID string, required map[string]bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	userMetrics := r.users[userID]
+	if len(userMetrics) == 0 {
+		return
+	}
+
+	for name := range required {
+		if def := userMetrics[name]; def != nil {
+			for _, dependency := range def.expression.Metrics() {
+				required[dependency] = true
+			}
+		}
+	}
+}
+
+// userMetricResolver resolves system metrics first, then the user's custom metrics
+type userMetricResolver struct {
+	base MetricResolver
+	defs map[string]*MetricExpression
+}
+
+// ResolveMetric resolves a metric name to its numeric value
+func (r *userMetricResolver) ResolveMetric(metric string, metrics map[string]float64) (float64, error) {
+	value, err := r.base.ResolveMetric(metric, metrics)
+	if err == nil {
+		return value, nil
+	}
+
+	expression, ok := r.defs[metric]
+	if !ok {
+		return 0, err
+	}
+
+	return expression.Evaluate(func(name string) (float64, error) {
+		return r.base.ResolveMetric(name, metrics)
+	})
+}
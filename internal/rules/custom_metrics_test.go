@@ -0,0 +1,223 @@
+package rules
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+func TestParseMetricExpression(t *testing.T) {
+	metrics := map[string]float64{"price": 110, "vwap_5m": 100, "volume_1m": 4}
+	resolve := func(name string) (float64, error) {
+		return metrics[name], nil
+	}
+
+	tests := []struct {
+		expression string
+		want       float64
+		metrics    int
+	}{
+		{"price - vwap_5m", 10, 2},
+		{"(price - vwap_5m) / vwap_5m * 100", 10, 2},
+		{"price - vwap_5m * 2", -90, 2},
+		{"-price + 2.5", -107.5, 1},
+		{"volume_1m * volume_1m", 16, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			expr, err := ParseMetricExpression(tt.expression)
+			if err != nil {
+				t.Fatalf("ParseMetricExpression() error = %v", err)
+			}
+			got, err := expr.Evaluate(resolve)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Evaluate() = %f, want %f", got, tt.want)
+			}
+			if len(expr.Metrics()) != tt.metrics {
+				t.Errorf("Expected %d referenced metrics, got %v", tt.metrics, expr.Metrics())
+			}
+		})
+	}
+
+	for _, invalid := range []string{"", "price +", "(price", "price $ 2", "price vwap_5m"} {
+		if _, err := ParseMetricExpression(invalid); err == nil {
+			t.Errorf("Expected error parsing %q", invalid)
+		}
+	}
+}
+
+func newTestCustomMetricRegistry(t *testing.T) *CustomMetricRegistry {
+	t.Helper()
+
+	registry := NewCustomMetricRegistry([]string{"price", "vwap_5m", "volume_1m"})
+	for _, metric := range []*models.CustomMetric{
+		{UserID: "user-a", Name: "vwap_spread", Expression: "price - vwap_5m"},
+		{UserID: "user-b", Name: "vwap_spread", Expression: "(price - vwap_5m) * 10"},
+	} {
+		if err := registry.Register(metric); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+	return registry
+}
+
+func TestCustomMetricRegistry_UserScoping(t *testing.T) {
+	registry := newTestCustomMetricRegistry(t)
+	compiler := NewCompiler(nil)
+	compiler.SetCustomMetricRegistry(registry)
+
+	newRule := func(id, userID string) *models.Rule {
+		return &models.Rule{
+			ID:         id,
+			Name:       "VWAP Spread",
+			UserID:     userID,
+			Conditions: []models.Condition{{Metric: "vwap_spread", Operator: ">", Value: 50.0}},
+			Enabled:    true,
+		}
+	}
+
+	metrics := map[string]float64{"price": 110, "vwap_5m": 100}
+
+	// user-a: spread is 10, user-b: spread is 100
+	ruleA, err := compiler.CompileRule(newRule("rule-a", "user-a"))
+	if err != nil {
+		t.Fatalf("CompileRule() error = %v", err)
+	}
+	if matched, err := ruleA("AAPL", metrics); err != nil || matched {
+		t.Errorf("Expected user-a rule not to match, got matched=%v err=%v", matched, err)
+	}
+
+	ruleB, err := compiler.CompileRule(newRule("rule-b", "user-b"))
+	if err != nil {
+		t.Fatalf("CompileRule() error = %v", err)
+	}
+	if matched, err := ruleB("AAPL", metrics); err != nil || !matched {
+		t.Errorf("Expected user-b rule to match, got matched=%v err=%v", matched, err)
+	}
+
+	// Rules of other users and system rules don't see the definitions
+	for _, userID := range []string{"user-c", ""} {
+		compiled, err := compiler.CompileRule(newRule("rule-other", userID))
+		if err != nil {
+			t.Fatalf("CompileRule() error = %v", err)
+		}
+		if matched, _ := compiled("AAPL", metrics); matched {
+			t.Errorf("Expected rule of user %q not to resolve vwap_spread", userID)
+		}
+	}
+
+	// Definitions are captured at compile time
+	if err := registry.Register(&models.CustomMetric{UserID: "user-a", Name: "vwap_spread", Expression: "price"}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if matched, err := ruleA("AAPL", metrics); err != nil || matched {
+		t.Errorf("Expected compiled rule to keep its definition, got matched=%v err=%v", matched, err)
+	}
+
+	if value, ok := registry.ResolveUserMetric("user-b", "vwap_spread", metrics); !ok || value != 100 {
+		t.Errorf("ResolveUserMetric() = %f, %v, want 100, true", value, ok)
+	}
+	if _, ok := registry.ResolveUserMetric("user-c", "vwap_spread", metrics); ok {
+		t.Error("Expected no custom metric for user-c")
+	}
+}
+
+func TestCustomMetricRegistry_Isolation(t *testing.T) {
+	registry := newTestCustomMetricRegistry(t)
+
+	// System metrics can't be shadowed
+	err := registry.Register(&models.CustomMetric{UserID: "user-a", Name: "price", Expression: "vwap_5m * 2"})
+	if !errors.Is(err, models.ErrInvalidCustomMetricName) {
+		t.Errorf("Expected ErrInvalidCustomMetricName registering a system metric name, got %v", err)
+	}
+
+	// Custom metrics can't reference other custom metrics
+	err = registry.Register(&models.CustomMetric{UserID: "user-a", Name: "double_spread", Expression: "vwap_spread * 2"})
+	if !errors.Is(err, models.ErrInvalidCustomMetricExpression) {
+		t.Errorf("Expected ErrInvalidCustomMetricExpression referencing a custom metric, got %v", err)
+	}
+
+	err = registry.Register(&models.CustomMetric{UserID: "user-a", Name: "broken", Expression: "price +"})
+	if !errors.Is(err, models.ErrInvalidCustomMetricExpression) {
+		t.Errorf("Expected ErrInvalidCustomMetricExpression for invalid expression, got %v", err)
+	}
+
+	err = registry.Register(&models.CustomMetric{Name: "orphan", Expression: "price"})
+	if !errors.Is(err, models.ErrInvalidCustomMetricUser) {
+		t.Errorf("Expected ErrInvalidCustomMetricUser without user, got %v", err)
+	}
+
+	// Even if a computed value is present, system metrics resolve first
+	resolver := registry.ResolverFor("user-a", NewMetricResolver())
+	value, err := resolver.ResolveMetric("price", map[string]float64{"price": 110, "vwap_5m": 100})
+	if err != nil || value != 110 {
+		t.Errorf("ResolveMetric(price) = %f, %v, want 110", value, err)
+	}
+
+	if list := registry.List("user-a"); len(list) != 1 || list[0].Name != "vwap_spread" {
+		t.Errorf("Expected only user-a's metric, got %v", list)
+	}
+	if !registry.Remove("user-a", "vwap_spread") {
+		t.Error("Expected Remove() to delete user-a's metric")
+	}
+	if _, ok := registry.Get("user-b", "vwap_spread"); !ok {
+		t.Error("Expected removing user-a's metric to keep user-b's")
+	}
+}
+
+func TestExtractRequiredMetricsWithCustom(t *testing.T) {
+	registry := newTestCustomMetricRegistry(t)
+
+	ruleList := []*models.Rule{
+		{
+			ID:         "rule-a",
+			UserID:     "user-a",
+			Conditions: []models.Condition{{Metric: "vwap_spread", Operator: ">", Value: 0.0}},
+			Enabled:    true,
+		},
+		{
+			ID:         "rule-system",
+			Conditions: []models.Condition{{Metric: "volume_1m", Operator: ">", Value: 0.0}},
+			Enabled:    true,
+		},
+	}
+
+	required := ExtractRequiredMetricsWithCustom(ruleList, registry)
+	for _, name := range []string{"price", "vwap_5m", "volume_1m"} {
+		if !required[name] {
+			t.Errorf("Expected %s to be required, got %v", name, required)
+		}
+	}
+}
+
+func TestCustomMetricRegistry_Load(t *testing.T) {
+	registry := NewCustomMetricRegistry([]string{"price", "vwap_5m"})
+	if err := registry.Register(&models.CustomMetric{UserID: "alice", Name: "stale", Expression: "price * 2"}); err != nil {
+		t.Fatalf("Failed to register metric: %v", err)
+	}
+
+	err := registry.Load([]*models.CustomMetric{
+		{UserID: "alice", Name: "vwap_dist", Expression: "price - vwap_5m"},
+		{UserID: "bob", Name: "price", Expression: "vwap_5m"}, // Shadows a system metric
+	})
+	if err == nil {
+		t.Error("Expected an error for the invalid definition")
+	}
+
+	// Loading replaces the previous definitions and keeps the valid new ones
+	if _, ok := registry.Get("alice", "stale"); ok {
+		t.Error("Expected definitions missing from the load to be removed")
+	}
+	value, ok := registry.ResolveUserMetric("alice", "vwap_dist", map[string]float64{"price": 110, "vwap_5m": 100})
+	if !ok || value != 10 {
+		t.Errorf("Expected vwap_dist=10, got %v (ok=%v)", value, ok)
+	}
+	if len(registry.List("bob")) != 0 {
+		t.Error("Expected the invalid definition to be skipped")
+	}
+}
@@ -69,22 +69,33 @@ func NewDatabaseRuleStore(dbConfig config.DatabaseConfig) (*DatabaseRuleStore, e
 // GetRule retrieves a rule by ID
 func (s *DatabaseRuleStore) GetRule(id string) (*models.Rule, error) {
 	query := `
-		SELECT id, name, description, conditions, enabled, created_at, updated_at, version
+		SELECT id, COALESCE(user_id, ''), name, description, conditions, exit_conditions, dedup_key, delivery, evaluate_on, evaluation_interval, condition_groups, logic_operator, match_on_missing, cooldown, enabled, priority, created_at, updated_at, version
 		FROM rules
 		WHERE id = $1
 	`
 
 	var rule models.Rule
-	var conditionsJSON []byte
+	var conditionsJSON, exitConditionsJSON, dedupKeyJSON, deliveryJSON, conditionGroupsJSON []byte
 	var createdAt, updatedAt time.Time
 	var version int
 
 	err := s.db.QueryRow(query, id).Scan(
 		&rule.ID,
+		&rule.UserID,
 		&rule.Name,
 		&rule.Description,
 		&conditionsJSON,
+		&exitConditionsJSON,
+		&dedupKeyJSON,
+		&deliveryJSON,
+		&rule.EvaluateOn,
+		&rule.EvaluationInterval,
+		&conditionGroupsJSON,
+		&rule.LogicOperator,
+		&rule.MatchOnMissing,
+		&rule.Cooldown,
 		&rule.Enabled,
+		&rule.Priority,
 		&createdAt,
 		&updatedAt,
 		&version,
@@ -100,6 +111,18 @@ func (s *DatabaseRuleStore) GetRule(id string) (*models.Rule, error) {
 	if err := json.Unmarshal(conditionsJSON, &rule.Conditions); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal conditions: %w", err)
 	}
+	if err := unmarshalExitConditions(exitConditionsJSON, &rule); err != nil {
+		return nil, err
+	}
+	if err := unmarshalConditionGroups(conditionGroupsJSON, &rule); err != nil {
+		return nil, err
+	}
+	if err := unmarshalDedupKey(dedupKeyJSON, &rule); err != nil {
+		return nil, err
+	}
+	if err := unmarshalDelivery(deliveryJSON, &rule); err != nil {
+		return nil, err
+	}
 
 	rule.CreatedAt = createdAt
 	rule.UpdatedAt = updatedAt
@@ -110,7 +133,7 @@ func (s *DatabaseRuleStore) GetRule(id string) (*models.Rule, error) {
 // GetAllRules retrieves all rules
 func (s *DatabaseRuleStore) GetAllRules() ([]*models.Rule, error) {
 	query := `
-		SELECT id, name, description, conditions, enabled, created_at, updated_at, version
+		SELECT id, COALESCE(user_id, ''), name, description, conditions, exit_conditions, dedup_key, delivery, evaluate_on, evaluation_interval, condition_groups, logic_operator, match_on_missing, cooldown, enabled, priority, created_at, updated_at, version
 		FROM rules
 		ORDER BY created_at DESC
 	`
@@ -124,16 +147,27 @@ func (s *DatabaseRuleStore) GetAllRules() ([]*models.Rule, error) {
 	var rules []*models.Rule
 	for rows.Next() {
 		var rule models.Rule
-		var conditionsJSON []byte
+		var conditionsJSON, exitConditionsJSON, dedupKeyJSON, deliveryJSON, conditionGroupsJSON []byte
 		var createdAt, updatedAt time.Time
 		var version int
 
 		if err := rows.Scan(
 			&rule.ID,
+			&rule.UserID,
 			&rule.Name,
 			&rule.Description,
 			&conditionsJSON,
+			&exitConditionsJSON,
+			&dedupKeyJSON,
+			&deliveryJSON,
+			&rule.EvaluateOn,
+			&rule.EvaluationInterval,
+			&conditionGroupsJSON,
+			&rule.LogicOperator,
+			&rule.MatchOnMissing,
+			&rule.Cooldown,
 			&rule.Enabled,
+			&rule.Priority,
 			&createdAt,
 			&updatedAt,
 			&version,
@@ -145,6 +179,18 @@ func (s *DatabaseRuleStore) GetAllRules() ([]*models.Rule, error) {
 		if err := json.Unmarshal(conditionsJSON, &rule.Conditions); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal conditions: %w", err)
 		}
+		if err := unmarshalExitConditions(exitConditionsJSON, &rule); err != nil {
+			return nil, err
+		}
+		if err := unmarshalConditionGroups(conditionGroupsJSON, &rule); err != nil {
+			return nil, err
+		}
+		if err := unmarshalDedupKey(dedupKeyJSON, &rule); err != nil {
+			return nil, err
+		}
+		if err := unmarshalDelivery(deliveryJSON, &rule); err != nil {
+			return nil, err
+		}
 
 		rule.CreatedAt = createdAt
 		rule.UpdatedAt = updatedAt
@@ -166,14 +212,41 @@ func (s *DatabaseRuleStore) AddRule(rule *models.Rule) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal conditions: %w", err)
 	}
+	exitConditionsJSON, err := marshalExitConditions(rule)
+	if err != nil {
+		return err
+	}
+	conditionGroupsJSON, err := marshalConditionGroups(rule)
+	if err != nil {
+		return err
+	}
+	dedupKeyJSON, err := marshalDedupKey(rule)
+	if err != nil {
+		return err
+	}
+	deliveryJSON, err := marshalDelivery(rule)
+	if err != nil {
+		return err
+	}
 
 	query := `
-		INSERT INTO rules (id, name, description, conditions, enabled, created_at, updated_at, version)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, 1)
+		INSERT INTO rules (id, name, description, conditions, evaluate_on, enabled, created_at, updated_at, version, exit_conditions, user_id, dedup_key, priority, delivery, evaluation_interval, condition_groups, logic_operator, match_on_missing, cooldown)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 1, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 		ON CONFLICT (id) DO UPDATE
 		SET name = EXCLUDED.name,
+		    user_id = EXCLUDED.user_id,
 		    description = EXCLUDED.description,
 		    conditions = EXCLUDED.conditions,
+		    exit_conditions = EXCLUDED.exit_conditions,
+		    dedup_key = EXCLUDED.dedup_key,
+		    priority = EXCLUDED.priority,
+		    delivery = EXCLUDED.delivery,
+		    evaluate_on = EXCLUDED.evaluate_on,
+		    evaluation_interval = EXCLUDED.evaluation_interval,
+		    condition_groups = EXCLUDED.condition_groups,
+		    logic_operator = EXCLUDED.logic_operator,
+		    match_on_missing = EXCLUDED.match_on_missing,
+		    cooldown = EXCLUDED.cooldown,
 		    enabled = EXCLUDED.enabled,
 		    updated_at = EXCLUDED.updated_at,
 		    version = rules.version + 1
@@ -184,9 +257,20 @@ func (s *DatabaseRuleStore) AddRule(rule *models.Rule) error {
 		rule.Name,
 		rule.Description,
 		conditionsJSON,
+		evaluateOnParam(rule),
 		rule.Enabled,
 		rule.CreatedAt,
 		rule.UpdatedAt,
+		exitConditionsJSON,
+		userIDParam(rule),
+		dedupKeyJSON,
+		rule.Priority,
+		deliveryJSON,
+		rule.EvaluationInterval,
+		conditionGroupsJSON,
+		logicOperatorParam(rule),
+		rule.MatchOnMissing,
+		rule.Cooldown,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert rule: %w", err)
@@ -202,14 +286,40 @@ func (s *DatabaseRuleStore) UpdateRule(rule *models.Rule) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal conditions: %w", err)
 	}
+	exitConditionsJSON, err := marshalExitConditions(rule)
+	if err != nil {
+		return err
+	}
+	conditionGroupsJSON, err := marshalConditionGroups(rule)
+	if err != nil {
+		return err
+	}
+	dedupKeyJSON, err := marshalDedupKey(rule)
+	if err != nil {
+		return err
+	}
+	deliveryJSON, err := marshalDelivery(rule)
+	if err != nil {
+		return err
+	}
 
 	query := `
 		UPDATE rules
 		SET name = $2,
 		    description = $3,
 		    conditions = $4,
-		    enabled = $5,
-		    updated_at = $6,
+		    evaluate_on = $5,
+		    enabled = $6,
+		    updated_at = $7,
+		    exit_conditions = $8,
+		    dedup_key = $9,
+		    priority = $10,
+		    delivery = $11,
+		    evaluation_interval = $12,
+		    condition_groups = $13,
+		    logic_operator = $14,
+		    match_on_missing = $15,
+		    cooldown = $16,
 		    version = version + 1
 		WHERE id = $1
 	`
@@ -219,8 +329,18 @@ func (s *DatabaseRuleStore) UpdateRule(rule *models.Rule) error {
 		rule.Name,
 		rule.Description,
 		conditionsJSON,
+		evaluateOnParam(rule),
 		rule.Enabled,
 		rule.UpdatedAt,
+		exitConditionsJSON,
+		dedupKeyJSON,
+		rule.Priority,
+		deliveryJSON,
+		rule.EvaluationInterval,
+		conditionGroupsJSON,
+		logicOperatorParam(rule),
+		rule.MatchOnMissing,
+		rule.Cooldown,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update rule: %w", err)
@@ -306,3 +426,126 @@ func (s *DatabaseRuleStore) Close() error {
 	return s.db.Close()
 }
 
+// evaluateOnParam returns the evaluate_on column value, defaulting to every tick
+func evaluateOnParam(rule *models.Rule) string {
+	if rule.EvaluateOn == "" {
+		return models.RuleEvaluateOnTick
+	}
+	return rule.EvaluateOn
+}
+
+// userIDParam returns the user_id column value for a rule (NULL for system rules)
+func userIDParam(rule *models.Rule) interface{} {
+	if rule.UserID == "" {
+		return nil
+	}
+	return rule.UserID
+}
+
+// marshalExitConditions returns the exit_conditions column value for a rule
+func marshalExitConditions(rule *models.Rule) ([]byte, error) {
+	if len(rule.ExitConditions) == 0 {
+		return []byte("[]"), nil
+	}
+	data, err := json.Marshal(rule.ExitConditions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal exit conditions: %w", err)
+	}
+	return data, nil
+}
+
+// unmarshalExitConditions decodes the exit_conditions column into a rule
+func unmarshalExitConditions(data []byte, rule *models.Rule) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, &rule.ExitConditions); err != nil {
+		return fmt.Errorf("failed to unmarshal exit conditions: %w", err)
+	}
+	if len(rule.ExitConditions) == 0 {
+		rule.ExitConditions = nil
+	}
+	return nil
+}
+
+// logicOperatorParam returns the logic_operator column value, defaulting to AND
+func logicOperatorParam(rule *models.Rule) string {
+	if rule.LogicOperator == "" {
+		return models.LogicAnd
+	}
+	return rule.LogicOperator
+}
+
+// marshalConditionGroups returns the condition_groups column value for a rule
+func marshalConditionGroups(rule *models.Rule) ([]byte, error) {
+	if len(rule.ConditionGroups) == 0 {
+		return []byte("[]"), nil
+	}
+	data, err := json.Marshal(rule.ConditionGroups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal condition groups: %w", err)
+	}
+	return data, nil
+}
+
+// unmarshalConditionGroups decodes the condition_groups column into a rule
+func unmarshalConditionGroups(data []byte, rule *models.Rule) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, &rule.ConditionGroups); err != nil {
+		return fmt.Errorf("failed to unmarshal condition groups: %w", err)
+	}
+	if len(rule.ConditionGroups) == 0 {
+		rule.ConditionGroups = nil
+	}
+	return nil
+}
+
+// marshalDedupKey returns the dedup_key column value for a rule (NULL for the default key)
+func marshalDedupKey(rule *models.Rule) ([]byte, error) {
+	if rule.DedupKey == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(rule.DedupKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal dedup key: %w", err)
+	}
+	return data, nil
+}
+
+// unmarshalDedupKey decodes the dedup_key column into a rule
+func unmarshalDedupKey(data []byte, rule *models.Rule) error {
+	if len(data) == 0 {
+		return nil
+	}
+	rule.DedupKey = &models.DedupKey{}
+	if err := json.Unmarshal(data, rule.DedupKey); err != nil {
+		return fmt.Errorf("failed to unmarshal dedup key: %w", err)
+	}
+	return nil
+}
+
+// marshalDelivery returns the delivery column value for a rule (NULL for delivery to all channels)
+func marshalDelivery(rule *models.Rule) ([]byte, error) {
+	if rule.Delivery == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(rule.Delivery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal delivery policy: %w", err)
+	}
+	return data, nil
+}
+
+// unmarshalDelivery decodes the delivery column into a rule
+func unmarshalDelivery(data []byte, rule *models.Rule) error {
+	if len(data) == 0 {
+		return nil
+	}
+	rule.Delivery = &models.DeliveryPolicy{}
+	if err := json.Unmarshal(data, rule.Delivery); err != nil {
+		return fmt.Errorf("failed to unmarshal delivery policy: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// ConditionMatch describes a condition's actual metric value against its threshold
+type ConditionMatch struct {
+	Metric    string  `json:"metric"`
+	Operator  string  `json:"operator"`
+	Actual    float64 `json:"actual"`
+	Threshold float64 `json:"threshold"`
+	Missing   bool    `json:"missing,omitempty"` // The metric (or the metric compared against) was unavailable
+}
+
+// String formats the match as "metric=actual op threshold", e.g. "rsi_14=25.0 < 30.0", or
+// "metric=n/a op threshold" when the metric was unavailable
+func (m ConditionMatch) String() string {
+	actual := "n/a"
+	if !m.Missing {
+		actual = formatExplainValue(m.Actual)
+	}
+	return fmt.Sprintf("%s=%s %s %s", m.Metric, actual, m.Operator, formatExplainValue(m.Threshold))
+}
+
+// GroupExplanation explains a condition group: its operator with the matches of its
+// conditions and the explanations of its nested groups
+type GroupExplanation struct {
+	Operator   string             `json:"operator"`
+	Conditions []ConditionMatch   `json:"conditions,omitempty"`
+	Groups     []GroupExplanation `json:"groups,omitempty"`
+}
+
+// String formats the group as its members joined by the group's operator, with nested
+// groups in parentheses, e.g. "price=150.0 > 100.0 AND (rsi_14=25.0 < 30.0 OR gap_pct=n/a > 5.0)"
+func (g GroupExplanation) String() string {
+	if len(g.Conditions) == 0 && len(g.Groups) == 1 {
+		return g.Groups[0].String()
+	}
+
+	parts := make([]string, 0, len(g.Conditions)+len(g.Groups))
+	for _, match := range g.Conditions {
+		parts = append(parts, match.String())
+	}
+	for _, group := range g.Groups {
+		part := group.String()
+		if len(group.Conditions)+len(group.Groups) > 1 {
+			part = "(" + part + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " "+g.Operator+" ")
+}
+
+// ExplainConditions returns per-condition match details for conditions against the metrics
+// they were evaluated with. Thresholds are the configured values; hysteresis bands are not
+// applied. A condition whose metric is unavailable is marked missing rather than failing
+// the explanation, since it may sit in an OR branch that did not need it.
+func (c *Compiler) ExplainConditions(rule *models.Rule, conditions []models.Condition, metrics map[string]float64) []ConditionMatch {
+	resolver := c.resolverFor(rule)
+
+	matches := make([]ConditionMatch, 0, len(conditions))
+	for i := range conditions {
+		matches = append(matches, explainCondition(&conditions[i], resolver, metrics))
+	}
+	return matches
+}
+
+// ExplainGroup explains a condition group (a rule's EntryGroup, or its exit conditions as an
+// AND group) and its nested groups, keeping the group structure
+func (c *Compiler) ExplainGroup(rule *models.Rule, group models.ConditionGroup, metrics map[string]float64) GroupExplanation {
+	operator := models.LogicAnd
+	if group.IsOr() {
+		operator = models.LogicOr
+	}
+
+	explanation := GroupExplanation{
+		Operator:   operator,
+		Conditions: c.ExplainConditions(rule, group.Conditions, metrics),
+	}
+	for i := range group.Groups {
+		explanation.Groups = append(explanation.Groups, c.ExplainGroup(rule, group.Groups[i], metrics))
+	}
+	return explanation
+}
+
+// explainCondition resolves a condition's actual value and threshold
+func explainCondition(cond *models.Condition, resolver MetricResolver, metrics map[string]float64) ConditionMatch {
+	match := ConditionMatch{Metric: cond.Metric, Operator: cond.Operator}
+
+	actual, err := resolver.ResolveMetric(cond.Metric, metrics)
+	if err != nil {
+		match.Missing = true
+	} else {
+		match.Actual = actual
+	}
+	threshold, err := conditionThreshold(cond, resolver, metrics)
+	if err != nil {
+		match.Missing = true
+	} else {
+		match.Threshold = threshold
+	}
+	return match
+}
+
+// formatExplainValue formats a value with up to 4 decimals, keeping at least one
+func formatExplainValue(v float64) string {
+	s := strings.TrimRight(strconv.FormatFloat(v, 'f', 4, 64), "0")
+	if strings.HasSuffix(s, ".") {
+		s += "0"
+	}
+	return s
+}
@@ -0,0 +1,112 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+func TestCompiler_ExplainConditions(t *testing.T) {
+	compiler := NewCompiler(nil)
+	rule := &models.Rule{
+		ID:   "rule-1",
+		Name: "Oversold on volume",
+		Conditions: []models.Condition{
+			{Metric: "rsi_14", Operator: "<", Value: 30.0},
+			{Metric: "volume", Operator: ">=", Value: 100000},
+		},
+	}
+	metrics := map[string]float64{"rsi_14": 25.0, "volume": 150000.0, "price": 12.3456789}
+
+	matches := compiler.ExplainConditions(rule, rule.Conditions, metrics)
+
+	want := []ConditionMatch{
+		{Metric: "rsi_14", Operator: "<", Actual: 25.0, Threshold: 30.0},
+		{Metric: "volume", Operator: ">=", Actual: 150000.0, Threshold: 100000.0},
+	}
+	if len(matches) != len(want) {
+		t.Fatalf("Expected %d matches, got %d", len(want), len(matches))
+	}
+	for i := range want {
+		if matches[i] != want[i] {
+			t.Errorf("Match %d = %+v, want %+v", i, matches[i], want[i])
+		}
+	}
+
+}
+
+func TestCompiler_ExplainGroup(t *testing.T) {
+	compiler := NewCompiler(nil)
+	rule := &models.Rule{
+		ID:         "rule-1",
+		Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+		ConditionGroups: []models.ConditionGroup{{
+			Operator: models.LogicOr,
+			Conditions: []models.Condition{
+				{Metric: "rsi_14", Operator: "<", Value: 30.0},
+				{Metric: "gap_pct", Operator: ">", Value: 5.0},
+			},
+		}},
+	}
+	metrics := map[string]float64{"price": 150.0, "rsi_14": 25.0}
+
+	explanation := compiler.ExplainGroup(rule, rule.EntryGroup(), metrics)
+
+	want := "price=150.0 > 100.0 AND (rsi_14=25.0 < 30.0 OR gap_pct=n/a > 5.0)"
+	if got := explanation.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if len(explanation.Groups) != 1 || explanation.Groups[0].Operator != models.LogicOr {
+		t.Fatalf("Expected one nested OR group, got %+v", explanation.Groups)
+	}
+	if !explanation.Groups[0].Conditions[1].Missing {
+		t.Error("Expected the gap_pct condition to be marked missing")
+	}
+}
+
+func TestGroupExplanation_String_SingleMemberGroup(t *testing.T) {
+	explanation := GroupExplanation{
+		Operator: models.LogicAnd,
+		Groups: []GroupExplanation{{
+			Operator:   models.LogicOr,
+			Conditions: []ConditionMatch{{Metric: "rsi_14", Operator: "<", Actual: 25, Threshold: 30}},
+		}},
+	}
+
+	if got := explanation.String(); got != "rsi_14=25.0 < 30.0" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestCompiler_ExplainConditions_MissingMetric(t *testing.T) {
+	compiler := NewCompiler(nil)
+	rule := &models.Rule{
+		ID:         "rule-1",
+		Conditions: []models.Condition{{Metric: "rsi_14", Operator: "<", Value: 30.0}},
+	}
+
+	matches := compiler.ExplainConditions(rule, rule.Conditions, map[string]float64{})
+	if len(matches) != 1 || !matches[0].Missing || matches[0].Threshold != 30.0 {
+		t.Fatalf("Expected the condition marked missing with its threshold, got %+v", matches)
+	}
+	if got := matches[0].String(); got != "rsi_14=n/a < 30.0" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestConditionMatch_String(t *testing.T) {
+	tests := []struct {
+		match ConditionMatch
+		want  string
+	}{
+		{ConditionMatch{Metric: "rsi_14", Operator: "<", Actual: 25, Threshold: 30}, "rsi_14=25.0 < 30.0"},
+		{ConditionMatch{Metric: "price", Operator: ">", Actual: 12.3456789, Threshold: 10.5}, "price=12.3457 > 10.5"},
+		{ConditionMatch{Metric: "change_pct", Operator: "<=", Actual: -2.25, Threshold: -2}, "change_pct=-2.25 <= -2.0"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.match.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
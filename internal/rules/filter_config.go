@@ -141,6 +141,28 @@ func CheckVolumeThreshold(metrics map[string]float64, threshold *int64) bool {
 	return false
 }
 
+// SelectVolumeThreshold returns the volume threshold for the current session.
+// A per-session threshold takes precedence; otherwise the condition's single threshold is used.
+func SelectVolumeThreshold(cond *models.Condition, currentSession string) *int64 {
+	if threshold, ok := cond.SessionVolumeThresholds[currentSession]; ok {
+		return &threshold
+	}
+	return cond.VolumeThreshold
+}
+
+// HasVolumeThreshold returns true if the condition requires volume in any session
+func HasVolumeThreshold(cond *models.Condition) bool {
+	if cond.VolumeThreshold != nil && *cond.VolumeThreshold > 0 {
+		return true
+	}
+	for _, threshold := range cond.SessionVolumeThresholds {
+		if threshold > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // CheckSessionFilter checks if current session matches the filter requirement
 // currentSession should be one of: "premarket", "market", "postmarket", "closed"
 func CheckSessionFilter(currentSession string, calculatedDuring string) bool {
@@ -161,6 +183,53 @@ func CheckSessionFilter(currentSession string, calculatedDuring string) bool {
 	}
 }
 
+// CheckConditionFilters checks a condition's volume threshold (per-session threshold if
+// configured) and session filter
+func CheckConditionFilters(cond *models.Condition, metrics map[string]float64, currentSession string) bool {
+	threshold := SelectVolumeThreshold(cond, currentSession)
+	if threshold != nil && *threshold > 0 && !CheckVolumeThreshold(metrics, threshold) {
+		return false
+	}
+	if cond.CalculatedDuring != "" && cond.CalculatedDuring != "all" && !CheckSessionFilter(currentSession, cond.CalculatedDuring) {
+		return false
+	}
+	return true
+}
+
+// SessionFunc returns a symbol's current market session ("premarket", "market",
+// "postmarket" or "closed")
+type SessionFunc func(symbol string) string
+
+// SetSession sets the source of the market session for condition filters. Entry conditions
+// are then only matched when their volume threshold and session filter pass. Without it
+// condition filters are not applied.
+func (c *Compiler) SetSession(fn SessionFunc) {
+	c.session = fn
+}
+
+// conditionFiltersPass checks a condition's volume threshold and session filter for a symbol
+func (c *Compiler) conditionFiltersPass(cond *models.Condition, symbol string, metrics map[string]float64) bool {
+	if c.session == nil || !HasConditionFilters(cond) {
+		return true
+	}
+	return CheckConditionFilters(cond, metrics, c.session(symbol))
+}
+
+// HasConditionFilters returns true if the condition has a volume threshold or session filter
+func HasConditionFilters(cond *models.Condition) bool {
+	return HasVolumeThreshold(cond) || (cond.CalculatedDuring != "" && cond.CalculatedDuring != "all")
+}
+
+// EnrichGroup enriches every condition of a condition group and its nested groups
+func EnrichGroup(group *models.ConditionGroup) {
+	for i := range group.Conditions {
+		EnrichCondition(&group.Conditions[i])
+	}
+	for i := range group.Groups {
+		EnrichGroup(&group.Groups[i])
+	}
+}
+
 // EnrichCondition enriches a condition with extracted timeframe and value type if not specified
 func EnrichCondition(cond *models.Condition) {
 	// Extract timeframe from metric name if not specified
@@ -222,6 +291,16 @@ func ValidateFilterConfig(cond *models.Condition) error {
 		return fmt.Errorf("volume_threshold must be >= 0, got %d", *cond.VolumeThreshold)
 	}
 
+	// Validate session_volume_thresholds (known sessions, values >= 0)
+	for session, threshold := range cond.SessionVolumeThresholds {
+		if session != "premarket" && session != "market" && session != "postmarket" {
+			return fmt.Errorf("invalid session_volume_thresholds session: %s (must be one of: premarket, market, postmarket)", session)
+		}
+		if threshold < 0 {
+			return fmt.Errorf("session_volume_thresholds[%s] must be >= 0, got %d", session, threshold)
+		}
+	}
+
 	return nil
 }
 
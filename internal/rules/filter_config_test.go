@@ -117,6 +117,56 @@ func TestCheckVolumeThreshold(t *testing.T) {
 	}
 }
 
+func TestSelectVolumeThreshold(t *testing.T) {
+	cond := &models.Condition{
+		Metric:          "rsi_14",
+		VolumeThreshold: int64Ptr(100000),
+		SessionVolumeThresholds: map[string]int64{
+			"premarket": 10000,
+			"market":    500000,
+		},
+	}
+
+	tests := []struct {
+		session string
+		want    int64
+	}{
+		{"premarket", 10000},
+		{"market", 500000},
+		{"postmarket", 100000}, // Falls back to single threshold
+	}
+
+	for _, tt := range tests {
+		got := SelectVolumeThreshold(cond, tt.session)
+		if got == nil || *got != tt.want {
+			t.Errorf("SelectVolumeThreshold(%s) = %v, want %d", tt.session, got, tt.want)
+		}
+	}
+
+	// Premarket liquidity passes the premarket threshold but not the regular one
+	metrics := map[string]float64{"premarket_volume": 50000}
+	if !CheckVolumeThreshold(metrics, SelectVolumeThreshold(cond, "premarket")) {
+		t.Error("Expected premarket threshold to pass during premarket")
+	}
+	if CheckVolumeThreshold(metrics, SelectVolumeThreshold(cond, "market")) {
+		t.Error("Expected market threshold to fail during market")
+	}
+
+	// No thresholds at all
+	if got := SelectVolumeThreshold(&models.Condition{Metric: "rsi_14"}, "market"); got != nil {
+		t.Errorf("Expected nil threshold, got %d", *got)
+	}
+}
+
+func TestHasVolumeThreshold(t *testing.T) {
+	if HasVolumeThreshold(&models.Condition{VolumeThreshold: int64Ptr(0)}) {
+		t.Error("Expected zero threshold not to require volume")
+	}
+	if !HasVolumeThreshold(&models.Condition{SessionVolumeThresholds: map[string]int64{"premarket": 1000}}) {
+		t.Error("Expected session threshold to require volume")
+	}
+}
+
 func TestCheckSessionFilter(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -255,6 +305,23 @@ func TestValidateFilterConfig(t *testing.T) {
 }
 
 // Helper function
+func TestValidateFilterConfig_SessionVolumeThresholds(t *testing.T) {
+	valid := &models.Condition{SessionVolumeThresholds: map[string]int64{"premarket": 1000, "market": 0}}
+	if err := ValidateFilterConfig(valid); err != nil {
+		t.Errorf("ValidateFilterConfig() error = %v", err)
+	}
+
+	badSession := &models.Condition{SessionVolumeThresholds: map[string]int64{"overnight": 1000}}
+	if err := ValidateFilterConfig(badSession); err == nil {
+		t.Error("Expected error for unknown session")
+	}
+
+	negative := &models.Condition{SessionVolumeThresholds: map[string]int64{"market": -1}}
+	if err := ValidateFilterConfig(negative); err == nil {
+		t.Error("Expected error for negative threshold")
+	}
+}
+
 func int64Ptr(v int64) *int64 {
 	return &v
 }
@@ -0,0 +1,117 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// HysteresisTracker remembers which hysteresis conditions are currently matched per symbol.
+// It is owned by the compiler so latched state survives rule recompilation on reload.
+type HysteresisTracker struct {
+	mu      sync.Mutex
+	matched map[string]bool // "ruleID|kind|conditionID|symbol" -> matched
+}
+
+// NewHysteresisTracker creates a new hysteresis tracker
+func NewHysteresisTracker() *HysteresisTracker {
+	return &HysteresisTracker{
+		matched: make(map[string]bool),
+	}
+}
+
+// hysteresisKey returns the tracker key for a condition of a rule and a symbol
+func hysteresisKey(prefix, conditionID, symbol string) string {
+	return prefix + "|" + conditionID + "|" + symbol
+}
+
+// conditionID identifies a condition by what it matches (metric, operator, value, filters and
+// band) rather than by its position, so a latch survives edits that add, remove or reorder the
+// rule's other conditions, and is reset when the condition itself changes. Identical conditions
+// of a rule share a latch, which is harmless since they always evaluate alike.
+func conditionID(cond *models.Condition) string {
+	keyed := *cond
+	keyed.SeverityBands = nil // Does not affect matching
+	data, err := json.Marshal(&keyed)
+	if err != nil {
+		return fmt.Sprintf("%+v", keyed)
+	}
+	return string(data)
+}
+
+// isLatched returns whether a condition keeps a hysteresis latch
+func isLatched(cond *models.Condition) bool {
+	return cond.HysteresisBand != nil && !cond.IsCrossing()
+}
+
+// hasHysteresis returns whether a group or its nested groups contain a hysteresis condition
+func hasHysteresis(group *models.ConditionGroup) bool {
+	for i := range group.Conditions {
+		if isLatched(&group.Conditions[i]) {
+			return true
+		}
+	}
+	for i := range group.Groups {
+		if hasHysteresis(&group.Groups[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMatched returns whether the condition matched on its last evaluation
+func (h *HysteresisTracker) isMatched(key string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.matched[key]
+}
+
+// setMatched records the result of the condition's latest evaluation
+func (h *HysteresisTracker) setMatched(key string, matched bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if matched {
+		h.matched[key] = true
+	} else {
+		delete(h.matched, key)
+	}
+}
+
+// Retain drops state for rules not in ruleIDs (deleted or disabled rules)
+func (h *HysteresisTracker) Retain(ruleIDs map[string]bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for key := range h.matched {
+		if !ruleIDs[key[:strings.Index(key, "|")]] {
+			delete(h.matched, key)
+		}
+	}
+}
+
+// Len returns the number of currently matched hysteresis conditions
+func (h *HysteresisTracker) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.matched)
+}
+
+// releaseCondition returns the condition a latched hysteresis condition is evaluated with:
+// the threshold is relaxed by the band so the metric must move back past value ± band to stop matching
+func releaseCondition(cond *models.Condition) (*models.Condition, error) {
+	threshold, err := getNumericValue(cond.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	released := *cond
+	switch cond.Operator {
+	case ">", ">=":
+		released.Value = threshold - *cond.HysteresisBand
+	case "<", "<=":
+		released.Value = threshold + *cond.HysteresisBand
+	}
+	return &released, nil
+}
@@ -0,0 +1,236 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+func hysteresisRule(id string, band *float64) *models.Rule {
+	return &models.Rule{
+		ID:   id,
+		Name: "RSI Overbought",
+		Conditions: []models.Condition{
+			{Metric: "rsi_14", Operator: ">", Value: 70.0, HysteresisBand: band},
+		},
+		Enabled: true,
+	}
+}
+
+func TestCompiler_HysteresisSuppressesFlicker(t *testing.T) {
+	band := 2.0
+	// RSI oscillating around the 70 threshold
+	series := []float64{71, 69.5, 70.5, 69, 70.2, 67.9, 69, 70.1}
+
+	compiler := NewCompiler(nil)
+	plain, err := compiler.CompileRule(hysteresisRule("plain", nil))
+	if err != nil {
+		t.Fatalf("CompileRule() error = %v", err)
+	}
+	banded, err := compiler.CompileRule(hysteresisRule("banded", &band))
+	if err != nil {
+		t.Fatalf("CompileRule() error = %v", err)
+	}
+
+	countTransitions := func(results []bool) int {
+		transitions := 0
+		for i := 1; i < len(results); i++ {
+			if results[i] != results[i-1] {
+				transitions++
+			}
+		}
+		return transitions
+	}
+
+	var plainResults, bandedResults []bool
+	for _, rsi := range series {
+		metrics := map[string]float64{"rsi_14": rsi}
+
+		matched, err := plain("AAPL", metrics)
+		if err != nil {
+			t.Fatalf("plain rule error = %v", err)
+		}
+		plainResults = append(plainResults, matched)
+
+		matched, err = banded("AAPL", metrics)
+		if err != nil {
+			t.Fatalf("banded rule error = %v", err)
+		}
+		bandedResults = append(bandedResults, matched)
+	}
+
+	// Without a band every crossing flips the result
+	if got := countTransitions(plainResults); got != 6 {
+		t.Errorf("Expected 6 transitions without hysteresis, got %d (%v)", got, plainResults)
+	}
+
+	// With a band: matched until RSI drops to 68 or below, then must exceed 70 again
+	want := []bool{true, true, true, true, true, false, false, true}
+	for i := range want {
+		if bandedResults[i] != want[i] {
+			t.Errorf("RSI %.1f: expected matched=%v, got %v", series[i], want[i], bandedResults[i])
+		}
+	}
+	if got := countTransitions(bandedResults); got != 2 {
+		t.Errorf("Expected 2 transitions with hysteresis, got %d", got)
+	}
+}
+
+func TestCompiler_HysteresisLessThan(t *testing.T) {
+	band := 1.0
+	rule := &models.Rule{
+		ID:   "oversold",
+		Name: "RSI Oversold",
+		Conditions: []models.Condition{
+			{Metric: "rsi_14", Operator: "<", Value: 30.0, HysteresisBand: &band},
+		},
+		Enabled: true,
+	}
+
+	compiled, err := NewCompiler(nil).CompileRule(rule)
+	if err != nil {
+		t.Fatalf("CompileRule() error = %v", err)
+	}
+
+	steps := []struct {
+		rsi  float64
+		want bool
+	}{
+		{30.5, false}, // Not yet matched
+		{29.5, true},  // Crosses below threshold
+		{30.8, true},  // Within band above threshold
+		{31.0, false}, // Moved back past threshold + band
+		{30.5, false}, // Must cross below threshold again
+	}
+	for _, step := range steps {
+		matched, err := compiled("AAPL", map[string]float64{"rsi_14": step.rsi})
+		if err != nil {
+			t.Fatalf("compiled rule error = %v", err)
+		}
+		if matched != step.want {
+			t.Errorf("RSI %.1f: expected matched=%v, got %v", step.rsi, step.want, matched)
+		}
+	}
+}
+
+func TestCompiler_HysteresisStatePerSymbolAndAcrossReload(t *testing.T) {
+	band := 2.0
+	compiler := NewCompiler(nil)
+	rule := hysteresisRule("rule-1", &band)
+
+	compiled, err := compiler.CompileRules([]*models.Rule{rule})
+	if err != nil {
+		t.Fatalf("CompileRules() error = %v", err)
+	}
+	if matched, _ := compiled["rule-1"]("AAPL", map[string]float64{"rsi_14": 71}); !matched {
+		t.Fatal("Expected AAPL to match above threshold")
+	}
+
+	// Another symbol inside the band has not matched yet
+	if matched, _ := compiled["rule-1"]("MSFT", map[string]float64{"rsi_14": 69}); matched {
+		t.Error("Expected MSFT not to match inside the band before crossing")
+	}
+
+	// Recompiling (rule reload) keeps latched state
+	compiled, err = compiler.CompileRules([]*models.Rule{rule})
+	if err != nil {
+		t.Fatalf("CompileRules() error = %v", err)
+	}
+	if matched, _ := compiled["rule-1"]("AAPL", map[string]float64{"rsi_14": 69}); !matched {
+		t.Error("Expected AAPL to stay matched inside the band after reload")
+	}
+
+	// Removing the rule drops its state
+	if _, err := compiler.CompileRules(nil); err != nil {
+		t.Fatalf("CompileRules() error = %v", err)
+	}
+	if compiler.hysteresis.Len() != 0 {
+		t.Errorf("Expected hysteresis state to be dropped, got %d entries", compiler.hysteresis.Len())
+	}
+}
+
+func TestCompiler_DetachedHysteresisState(t *testing.T) {
+	band := 2.0
+	compiler := NewCompiler(nil)
+	active, err := compiler.CompileRule(hysteresisRule("rule-1", &band))
+	if err != nil {
+		t.Fatalf("CompileRule() error = %v", err)
+	}
+	dryRun, err := compiler.Detached().CompileRule(hysteresisRule("rule-1", &band))
+	if err != nil {
+		t.Fatalf("CompileRule() error = %v", err)
+	}
+
+	// A dry run under the same rule ID does not latch the active rule's condition
+	if matched, _ := dryRun("AAPL", map[string]float64{"rsi_14": 71}); !matched {
+		t.Fatal("Expected the dry run to match")
+	}
+	if compiler.hysteresis.Len() != 0 {
+		t.Errorf("Expected no latched conditions on the active compiler, got %d", compiler.hysteresis.Len())
+	}
+	if matched, _ := active("AAPL", map[string]float64{"rsi_14": 69}); matched {
+		t.Error("Expected the active rule not to be released by the dry run's latch")
+	}
+}
+
+func TestCompiler_HysteresisLatchesSettleAfterShortCircuit(t *testing.T) {
+	band := 2.0
+	rule := &models.Rule{
+		ID:   "rule-1",
+		Name: "Breakout",
+		Conditions: []models.Condition{
+			{Metric: "price", Operator: ">", Value: 100.0},
+			{Metric: "rsi_14", Operator: ">", Value: 70.0, HysteresisBand: &band},
+		},
+		Enabled: true,
+	}
+	compiler := NewCompiler(nil)
+	compiled, err := compiler.CompileRule(rule)
+	if err != nil {
+		t.Fatalf("CompileRule() error = %v", err)
+	}
+
+	// Latch the RSI condition
+	if matched, _ := compiled("AAPL", map[string]float64{"price": 101, "rsi_14": 71}); !matched {
+		t.Fatal("Expected the rule to match")
+	}
+	// The price condition decides the AND, but the RSI drop below the band still releases the latch
+	if matched, _ := compiled("AAPL", map[string]float64{"price": 99, "rsi_14": 60}); matched {
+		t.Fatal("Expected the rule not to match")
+	}
+	if compiler.hysteresis.Len() != 0 {
+		t.Fatalf("Expected the RSI latch to be released, got %d latched conditions", compiler.hysteresis.Len())
+	}
+	// Inside the band, an unlatched condition does not match
+	if matched, _ := compiled("AAPL", map[string]float64{"price": 101, "rsi_14": 69}); matched {
+		t.Error("Expected a stale latch not to keep the rule matched")
+	}
+}
+
+func TestCompiler_HysteresisLatchSurvivesRuleEdits(t *testing.T) {
+	band := 2.0
+	rsi := models.Condition{Metric: "rsi_14", Operator: ">", Value: 70.0, HysteresisBand: &band}
+	rule := &models.Rule{ID: "rule-1", Name: "RSI", Conditions: []models.Condition{rsi}, Enabled: true}
+
+	compiler := NewCompiler(nil)
+	compiled, err := compiler.CompileRules([]*models.Rule{rule})
+	if err != nil {
+		t.Fatalf("CompileRules() error = %v", err)
+	}
+	if matched, _ := compiled["rule-1"]("AAPL", map[string]float64{"rsi_14": 71}); !matched {
+		t.Fatal("Expected the rule to match")
+	}
+
+	// A condition inserted before the latched one does not shift its latch
+	edited := &models.Rule{ID: "rule-1", Name: "RSI", Enabled: true, Conditions: []models.Condition{
+		{Metric: "price", Operator: ">", Value: 1.0},
+		rsi,
+	}}
+	compiled, err = compiler.CompileRules([]*models.Rule{edited})
+	if err != nil {
+		t.Fatalf("CompileRules() error = %v", err)
+	}
+	if matched, _ := compiled["rule-1"]("AAPL", map[string]float64{"price": 10, "rsi_14": 69}); !matched {
+		t.Error("Expected the RSI latch to survive the edit")
+	}
+}
@@ -0,0 +1,71 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+var (
+	ErrTooManyRules      = errors.New("maximum number of rules per user reached")
+	ErrTooManyConditions = errors.New("rule has too many conditions")
+	ErrNestingTooDeep    = errors.New("rule conditions are nested too deeply")
+)
+
+// RuleLimits bounds rule count and complexity (0 = unlimited)
+type RuleLimits struct {
+	MaxRulesPerUser      int // Maximum rules owned by a single user (system rules are not counted)
+	MaxConditionsPerRule int // Maximum entry + exit conditions in one rule
+	MaxNestingDepth      int // Maximum condition nesting depth
+}
+
+// CheckComplexity returns an error if the rule exceeds the condition or nesting limits
+func (l RuleLimits) CheckComplexity(rule *models.Rule) error {
+	conditionCount := len(rule.EntryConditions()) + len(rule.ExitConditions)
+	if l.MaxConditionsPerRule > 0 && conditionCount > l.MaxConditionsPerRule {
+		return fmt.Errorf("%w: %d conditions, limit is %d", ErrTooManyConditions, conditionCount, l.MaxConditionsPerRule)
+	}
+
+	depth := ConditionDepth(rule)
+	if l.MaxNestingDepth > 0 && depth > l.MaxNestingDepth {
+		return fmt.Errorf("%w: depth %d, limit is %d", ErrNestingTooDeep, depth, l.MaxNestingDepth)
+	}
+
+	return nil
+}
+
+// CheckRuleCount returns an error if creating the rule would exceed its owner's rule limit
+func (l RuleLimits) CheckRuleCount(store RuleStore, rule *models.Rule) error {
+	if l.MaxRulesPerUser <= 0 || rule.UserID == "" {
+		return nil
+	}
+
+	allRules, err := store.GetAllRules()
+	if err != nil {
+		return fmt.Errorf("failed to count rules: %w", err)
+	}
+
+	count := 0
+	for _, existing := range allRules {
+		if existing.UserID == rule.UserID && existing.ID != rule.ID {
+			count++
+		}
+	}
+
+	if count >= l.MaxRulesPerUser {
+		return fmt.Errorf("%w: user %s has %d rules, limit is %d", ErrTooManyRules, rule.UserID, count, l.MaxRulesPerUser)
+	}
+
+	return nil
+}
+
+// ConditionDepth returns the nesting depth of a rule's conditions (flat condition lists have
+// depth 1, each level of condition groups adds 1)
+func ConditionDepth(rule *models.Rule) int {
+	if len(rule.Conditions) == 0 && len(rule.ConditionGroups) == 0 && len(rule.ExitConditions) == 0 {
+		return 0
+	}
+	group := rule.EntryGroup()
+	return group.Depth()
+}
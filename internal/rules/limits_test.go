@@ -0,0 +1,95 @@
+package rules
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+func limitsTestRule(id, userID string, conditions int) *models.Rule {
+	rule := &models.Rule{ID: id, UserID: userID, Name: "Rule " + id, Enabled: true}
+	for i := 0; i < conditions; i++ {
+		rule.Conditions = append(rule.Conditions, models.Condition{Metric: "price", Operator: ">", Value: float64(i)})
+	}
+	return rule
+}
+
+func TestRuleLimits_CheckComplexity(t *testing.T) {
+	limits := RuleLimits{MaxConditionsPerRule: 3, MaxNestingDepth: 1}
+
+	if err := limits.CheckComplexity(limitsTestRule("ok", "", 3)); err != nil {
+		t.Errorf("Expected rule within limits to pass, got %v", err)
+	}
+
+	if err := limits.CheckComplexity(limitsTestRule("too-many", "", 4)); !errors.Is(err, ErrTooManyConditions) {
+		t.Errorf("Expected ErrTooManyConditions, got %v", err)
+	}
+
+	// Exit conditions count towards the limit
+	rule := limitsTestRule("with-exit", "", 2)
+	rule.ExitConditions = []models.Condition{
+		{Metric: "price", Operator: "<", Value: 1.0},
+		{Metric: "price", Operator: "<", Value: 2.0},
+	}
+	if err := limits.CheckComplexity(rule); !errors.Is(err, ErrTooManyConditions) {
+		t.Errorf("Expected exit conditions to count towards the limit, got %v", err)
+	}
+
+	// Zero limits are unlimited
+	if err := (RuleLimits{}).CheckComplexity(limitsTestRule("unlimited", "", 50)); err != nil {
+		t.Errorf("Expected zero limits to be unlimited, got %v", err)
+	}
+}
+
+func TestConditionDepth(t *testing.T) {
+	if depth := ConditionDepth(limitsTestRule("flat", "", 2)); depth != 1 {
+		t.Errorf("Expected flat conditions to have depth 1, got %d", depth)
+	}
+	if depth := ConditionDepth(&models.Rule{}); depth != 0 {
+		t.Errorf("Expected empty rule to have depth 0, got %d", depth)
+	}
+
+	nested := limitsTestRule("nested", "", 1)
+	nested.ConditionGroups = []models.ConditionGroup{{
+		Operator: models.LogicOr,
+		Groups:   []models.ConditionGroup{{Conditions: nested.Conditions}},
+	}}
+	if depth := ConditionDepth(nested); depth != 3 {
+		t.Errorf("Expected two levels of groups to have depth 3, got %d", depth)
+	}
+	if err := (RuleLimits{MaxNestingDepth: 2}).CheckComplexity(nested); !errors.Is(err, ErrNestingTooDeep) {
+		t.Errorf("Expected ErrNestingTooDeep, got %v", err)
+	}
+	if err := (RuleLimits{MaxConditionsPerRule: 1}).CheckComplexity(nested); !errors.Is(err, ErrTooManyConditions) {
+		t.Errorf("Expected grouped conditions to count towards the limit, got %v", err)
+	}
+}
+
+func TestRuleLimits_CheckRuleCount(t *testing.T) {
+	store := NewInMemoryRuleStore()
+	for _, rule := range []*models.Rule{
+		limitsTestRule("a1", "alice", 1),
+		limitsTestRule("a2", "alice", 1),
+		limitsTestRule("b1", "bob", 1),
+		limitsTestRule("s1", "", 1),
+		limitsTestRule("s2", "", 1),
+	} {
+		if err := store.AddRule(rule); err != nil {
+			t.Fatalf("Failed to add rule: %v", err)
+		}
+	}
+
+	limits := RuleLimits{MaxRulesPerUser: 2}
+
+	if err := limits.CheckRuleCount(store, limitsTestRule("a3", "alice", 1)); !errors.Is(err, ErrTooManyRules) {
+		t.Errorf("Expected ErrTooManyRules for alice, got %v", err)
+	}
+	if err := limits.CheckRuleCount(store, limitsTestRule("b2", "bob", 1)); err != nil {
+		t.Errorf("Expected bob to be within limit, got %v", err)
+	}
+	// System rules are not limited
+	if err := limits.CheckRuleCount(store, limitsTestRule("s3", "", 1)); err != nil {
+		t.Errorf("Expected system rules to be unlimited, got %v", err)
+	}
+}
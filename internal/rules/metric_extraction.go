@@ -14,14 +14,17 @@ func ExtractRequiredMetrics(rules []*models.Rule) map[string]bool {
 			continue
 		}
 
-		for _, cond := range rule.Conditions {
+		for _, cond := range ruleConditions(rule) {
 			// Add the metric name from the condition
 			if cond.Metric != "" {
 				requiredMetrics[cond.Metric] = true
 			}
+			if name, ok := cond.Value.(string); ok && cond.IsCrossing() {
+				requiredMetrics[name] = true
+			}
 
 			// For volume threshold checks, we need volume metrics
-			if cond.VolumeThreshold != nil && *cond.VolumeThreshold > 0 {
+			if HasVolumeThreshold(&cond) {
 				// Add common volume metrics that might be checked
 				requiredMetrics["volume_daily"] = true
 				requiredMetrics["premarket_volume"] = true
@@ -29,6 +32,26 @@ func ExtractRequiredMetrics(rules []*models.Rule) map[string]bool {
 				requiredMetrics["market_volume"] = true
 			}
 		}
+		addDedupKeyMetric(rule, requiredMetrics)
+	}
+
+	return requiredMetrics
+}
+
+// ExtractRequiredMetricsWithCustom extracts required metrics, replacing each rule owner's
+// custom metrics with the metrics their expressions reference
+func ExtractRequiredMetricsWithCustom(rules []*models.Rule, customMetrics *CustomMetricRegistry) map[string]bool {
+	if customMetrics == nil {
+		return ExtractRequiredMetrics(rules)
+	}
+
+	requiredMetrics := make(map[string]bool)
+	for _, rule := range rules {
+		ruleMetrics := ExtractRequiredMetricsFromRule(rule)
+		customMetrics.ExpandRequiredMetrics(rule.UserID, ruleMetrics)
+		for name := range ruleMetrics {
+			requiredMetrics[name] = true
+		}
 	}
 
 	return requiredMetrics
@@ -42,20 +65,43 @@ func ExtractRequiredMetricsFromRule(rule *models.Rule) map[string]bool {
 
 	requiredMetrics := make(map[string]bool)
 
-	for _, cond := range rule.Conditions {
+	for _, cond := range ruleConditions(rule) {
 		if cond.Metric != "" {
 			requiredMetrics[cond.Metric] = true
 		}
+		if name, ok := cond.Value.(string); ok && cond.IsCrossing() {
+			requiredMetrics[name] = true
+		}
 
 		// For volume threshold checks, we need volume metrics
-		if cond.VolumeThreshold != nil && *cond.VolumeThreshold > 0 {
+		if HasVolumeThreshold(&cond) {
 			requiredMetrics["volume_daily"] = true
 			requiredMetrics["premarket_volume"] = true
 			requiredMetrics["postmarket_volume"] = true
 			requiredMetrics["market_volume"] = true
 		}
 	}
+	addDedupKeyMetric(rule, requiredMetrics)
 
 	return requiredMetrics
 }
 
+// addDedupKeyMetric adds the metric bucketed into the rule's dedup key, which the alert
+// service keys as "na" when the alert doesn't carry it
+func addDedupKeyMetric(rule *models.Rule, requiredMetrics map[string]bool) {
+	if rule.DedupKey != nil && rule.DedupKey.Metric != "" {
+		requiredMetrics[rule.DedupKey.Metric] = true
+	}
+}
+
+
+// ruleConditions returns a rule's trigger (including grouped) and exit conditions
+func ruleConditions(rule *models.Rule) []models.Condition {
+	entry := rule.EntryConditions()
+	if len(rule.ExitConditions) == 0 {
+		return entry
+	}
+	conditions := make([]models.Condition, 0, len(entry)+len(rule.ExitConditions))
+	conditions = append(conditions, entry...)
+	return append(conditions, rule.ExitConditions...)
+}
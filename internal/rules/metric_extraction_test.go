@@ -154,6 +154,23 @@ func TestExtractRequiredMetricsFromRule(t *testing.T) {
 			wantSize: 2,
 			wantKeys: []string{"price_change_5m_pct", "volume_daily"},
 		},
+		{
+			name: "rule with dedup key metric",
+			rule: &models.Rule{
+				ID:      "rule1",
+				Enabled: true,
+				Conditions: []models.Condition{
+					{Metric: "price_change_5m_pct", Operator: ">", Value: 5.0},
+				},
+				DedupKey: &models.DedupKey{
+					Fields:     []string{models.DedupFieldRule, models.DedupFieldMetric},
+					Metric:     "rsi_14",
+					BucketSize: 10,
+				},
+			},
+			wantSize: 2,
+			wantKeys: []string{"price_change_5m_pct", "rsi_14"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1,12 +1,18 @@
 package rules
 
 import (
+	"errors"
 	"fmt"
 	"math"
 
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
 )
 
+// ErrMetricNotFound is returned (wrapped) when a metric has no value for a symbol. The metrics
+// registry omits metrics it cannot compute (e.g. a change metric without enough history), so
+// a rule tells "metric absent" apart from "metric equals zero" by this error.
+var ErrMetricNotFound = errors.New("metric not found")
+
 // MetricResolver resolves metric names to their values
 type MetricResolver interface {
 	// ResolveMetric resolves a metric name to its numeric value
@@ -67,7 +73,7 @@ func (r *DefaultMetricResolver) ResolveMetric(metric string, metrics map[string]
 	}
 
 	// Metric not found
-	return 0, fmt.Errorf("metric '%s' not found", metric)
+	return 0, fmt.Errorf("%w: %s", ErrMetricNotFound, metric)
 }
 
 // registerBuiltInMetrics registers built-in computed metrics
@@ -93,6 +99,11 @@ func EvaluateCondition(cond *models.Condition, resolver MetricResolver, metrics
 		return false, fmt.Errorf("failed to resolve metric '%s': %w", cond.Metric, err)
 	}
 
+	// NaN means the metric has no value yet (e.g. a change metric without enough history)
+	if math.IsNaN(metricValue) {
+		return false, nil
+	}
+
 	// Get comparison value
 	comparisonValue, err := getNumericValue(cond.Value)
 	if err != nil {
@@ -2,6 +2,7 @@ package rules
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
@@ -168,6 +169,22 @@ func TestEvaluateCondition(t *testing.T) {
 		},
 	}
 
+	// NaN (no value yet) never matches, whatever the operator
+	for _, op := range []string{">", "<", ">=", "<=", "==", "!="} {
+		tests = append(tests, struct {
+			name    string
+			cond    *models.Condition
+			metrics map[string]float64
+			want    bool
+			wantErr bool
+		}{
+			name:    "NaN " + op,
+			cond:    &models.Condition{Metric: "price_change_5m_pct", Operator: op, Value: 0.0},
+			metrics: map[string]float64{"price_change_5m_pct": math.NaN()},
+			want:    false,
+		})
+	}
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result, err := EvaluateCondition(tt.cond, resolver, tt.metrics)
@@ -29,6 +29,9 @@ func ParseRule(data []byte) (*models.Rule, error) {
 	for i := range rule.Conditions {
 		EnrichCondition(&rule.Conditions[i])
 	}
+	for i := range rule.ConditionGroups {
+		EnrichGroup(&rule.ConditionGroups[i])
+	}
 
 	// Validate the parsed rule
 	if err := ValidateRule(&rule); err != nil {
@@ -75,6 +78,9 @@ func ParseRules(data []byte) ([]*models.Rule, error) {
 		for j := range rule.Conditions {
 			EnrichCondition(&rule.Conditions[j])
 		}
+		for j := range rule.ConditionGroups {
+			EnrichGroup(&rule.ConditionGroups[j])
+		}
 
 		if err := ValidateRule(rule); err != nil {
 			return nil, fmt.Errorf("invalid rule at index %d: %w", i, err)
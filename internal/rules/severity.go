@@ -0,0 +1,76 @@
+package rules
+
+import (
+	"math"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// ConditionSeverity classifies how far a matched condition's metric went past its threshold
+// using the condition's severity bands. Only ordered comparisons (>, >=, <, <=) have a
+// distance; other operators are always "info".
+func ConditionSeverity(cond *models.Condition, match ConditionMatch) string {
+	var distance float64
+	switch cond.Operator {
+	case ">", ">=":
+		distance = match.Actual - match.Threshold
+	case "<", "<=":
+		distance = match.Threshold - match.Actual
+	default:
+		return models.SeverityInfo
+	}
+
+	// Relative to the threshold, or in metric units for a threshold of 0
+	if match.Threshold != 0 {
+		distance = distance / math.Abs(match.Threshold) * 100
+	}
+
+	bands := models.DefaultSeverityBands()
+	if cond.SeverityBands != nil {
+		bands = *cond.SeverityBands
+	}
+
+	switch {
+	case distance >= bands.Critical:
+		return models.SeverityCritical
+	case distance >= bands.Warning:
+		return models.SeverityWarning
+	default:
+		return models.SeverityInfo
+	}
+}
+
+// AlertSeverity returns the highest severity of a rule's conditions that matched the metrics
+// for a symbol. Conditions that did not match (an untaken OR branch), whose metric is missing
+// (a MatchOnMissing rule) or that fail their volume or session filter do not count; with none
+// left the severity is "info".
+func (c *Compiler) AlertSeverity(rule *models.Rule, conditions []models.Condition, symbol string, metrics map[string]float64) string {
+	resolver := c.resolverFor(rule)
+
+	severity := models.SeverityInfo
+	for i := range conditions {
+		cond := &conditions[i]
+		// Crossings have no distance past their threshold, so they are always "info"
+		if cond.IsCrossing() || !c.conditionFiltersPass(cond, symbol, metrics) {
+			continue
+		}
+		matched, err := EvaluateCondition(cond, resolver, metrics)
+		if err != nil || !matched {
+			continue
+		}
+		actual, err := resolver.ResolveMetric(cond.Metric, metrics)
+		if err != nil {
+			continue
+		}
+		threshold, err := conditionThreshold(cond, resolver, metrics)
+		if err != nil {
+			continue
+		}
+
+		match := ConditionMatch{Metric: cond.Metric, Operator: cond.Operator, Actual: actual, Threshold: threshold}
+		if s := ConditionSeverity(cond, match); models.SeverityRank(s) > models.SeverityRank(severity) {
+			severity = s
+		}
+	}
+	return severity
+}
@@ -0,0 +1,93 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+func TestConditionSeverity(t *testing.T) {
+	oversold := models.Condition{Metric: "rsi_14", Operator: "<", Value: 30.0}
+	volume := models.Condition{Metric: "volume", Operator: ">", Value: 1000000.0}
+	change := models.Condition{Metric: "price_change_5m_pct", Operator: ">=", Value: 0.0}
+	crossing := models.Condition{Metric: "price", Operator: models.OperatorCrossesAbove, Value: "vwap_5m"}
+	custom := models.Condition{Metric: "rsi_14", Operator: "<", Value: 30.0,
+		SeverityBands: &models.SeverityBands{Warning: 1, Critical: 5}}
+
+	tests := []struct {
+		name   string
+		cond   models.Condition
+		actual float64
+		want   string
+	}{
+		{"marginal breach", oversold, 29, models.SeverityInfo},
+		{"moderate breach", oversold, 26, models.SeverityWarning},
+		{"deep breach", oversold, 15, models.SeverityCritical},
+		{"greater than", volume, 1300000, models.SeverityCritical},
+		{"zero threshold in metric units", change, 12, models.SeverityWarning},
+		{"crossing is info", crossing, 1000, models.SeverityInfo},
+		{"custom bands", custom, 29, models.SeverityWarning},
+	}
+
+	for _, tt := range tests {
+		threshold, _ := tt.cond.Value.(float64)
+		match := ConditionMatch{Metric: tt.cond.Metric, Operator: tt.cond.Operator, Actual: tt.actual, Threshold: threshold}
+		if got := ConditionSeverity(&tt.cond, match); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestAlertSeverity_HighestCondition(t *testing.T) {
+	compiler := NewCompiler(nil)
+	rule := &models.Rule{
+		ID:   "rule-1",
+		Name: "Oversold On Volume",
+		Conditions: []models.Condition{
+			{Metric: "rsi_14", Operator: "<", Value: 30.0},
+			{Metric: "volume", Operator: ">", Value: 1000000.0},
+		},
+	}
+
+	severity := func(metrics map[string]float64) string {
+		return compiler.AlertSeverity(rule, rule.Conditions, "AAPL", metrics)
+	}
+
+	marginal := severity(map[string]float64{"rsi_14": 29, "volume": 1050000})
+	deep := severity(map[string]float64{"rsi_14": 15, "volume": 1050000})
+	if marginal != models.SeverityInfo || deep != models.SeverityCritical {
+		t.Errorf("Expected info for a marginal breach and critical for a deep one, got %s and %s", marginal, deep)
+	}
+	if models.SeverityRank(deep) <= models.SeverityRank(marginal) {
+		t.Errorf("Expected a deeply breached condition to rank above a marginal one")
+	}
+}
+
+func TestAlertSeverity_MatchedConditionsOnly(t *testing.T) {
+	compiler := NewCompiler(nil)
+	rule := &models.Rule{
+		ID:   "rule-1",
+		Name: "Oversold Or Volume Spike",
+		ConditionGroups: []models.ConditionGroup{{
+			Operator: models.LogicOr,
+			Conditions: []models.Condition{
+				{Metric: "rsi_14", Operator: "<", Value: 30.0},
+				{Metric: "volume", Operator: ">", Value: 1000000.0},
+			},
+		}},
+		MatchOnMissing: true,
+	}
+	conditions := rule.EntryConditions()
+
+	// The untaken branch (rsi_14 deep below 30 would be critical) does not count
+	if got := compiler.AlertSeverity(rule, conditions, "AAPL", map[string]float64{"rsi_14": 50, "volume": 1150000}); got != models.SeverityWarning {
+		t.Errorf("Expected warning from the volume branch, got %s", got)
+	}
+	// A missing metric is skipped rather than failing the classification
+	if got := compiler.AlertSeverity(rule, conditions, "AAPL", map[string]float64{"rsi_14": 15}); got != models.SeverityCritical {
+		t.Errorf("Expected critical from the RSI branch, got %s", got)
+	}
+	if got := compiler.AlertSeverity(rule, conditions, "AAPL", map[string]float64{}); got != models.SeverityInfo {
+		t.Errorf("Expected info without matched conditions, got %s", got)
+	}
+}
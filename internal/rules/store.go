@@ -201,24 +201,57 @@ func copyRule(rule *models.Rule) *models.Rule {
 
 	copied := &models.Rule{
 		ID:          rule.ID,
+		UserID:      rule.UserID,
 		Name:        rule.Name,
 		Description: rule.Description,
 		Conditions:  make([]models.Condition, len(rule.Conditions)),
+		LogicOperator: rule.LogicOperator,
+		EvaluateOn:  rule.EvaluateOn,
+		EvaluationInterval: rule.EvaluationInterval,
 		Cooldown:    rule.Cooldown,
+		Priority:    rule.Priority,
+		MatchOnMissing: rule.MatchOnMissing,
 		Enabled:     rule.Enabled,
 		CreatedAt:   rule.CreatedAt,
 		UpdatedAt:   rule.UpdatedAt,
 	}
 
-	// Copy conditions
-	for i, cond := range rule.Conditions {
-		copied.Conditions[i] = models.Condition{
-			Metric:   cond.Metric,
-			Operator: cond.Operator,
-			Value:    cond.Value, // Value is interface{}, so this is a shallow copy
-		}
+	// Copy conditions (including filter configuration)
+	// Value is interface{}, so this is a shallow copy
+	copy(copied.Conditions, rule.Conditions)
+	if len(rule.ConditionGroups) > 0 {
+		copied.ConditionGroups = copyConditionGroups(rule.ConditionGroups)
+	}
+	if len(rule.ExitConditions) > 0 {
+		copied.ExitConditions = make([]models.Condition, len(rule.ExitConditions))
+		copy(copied.ExitConditions, rule.ExitConditions)
+	}
+	if rule.DedupKey != nil {
+		dedupKey := *rule.DedupKey
+		dedupKey.Fields = append([]string(nil), rule.DedupKey.Fields...)
+		copied.DedupKey = &dedupKey
 	}
+	if rule.Delivery != nil {
+		delivery := *rule.Delivery
+		delivery.Fallbacks = append([]string(nil), rule.Delivery.Fallbacks...)
+		copied.Delivery = &delivery
+	}
+
+	return copied
+}
 
+// copyConditionGroups creates a deep copy of condition groups
+func copyConditionGroups(groups []models.ConditionGroup) []models.ConditionGroup {
+	copied := make([]models.ConditionGroup, len(groups))
+	for i, group := range groups {
+		copied[i] = models.ConditionGroup{
+			Operator:   group.Operator,
+			Conditions: append([]models.Condition(nil), group.Conditions...),
+		}
+		if len(group.Groups) > 0 {
+			copied[i].Groups = copyConditionGroups(group.Groups)
+		}
+	}
 	return copied
 }
 
@@ -42,6 +42,7 @@ func TestInMemoryRuleStore_GetRule(t *testing.T) {
 		Name:        "Test Rule",
 		Conditions:  []models.Condition{{Metric: "rsi_14", Operator: "<", Value: 30.0}},
 		Cooldown:    300,
+		Priority:    5,
 		Enabled:     true,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
@@ -66,6 +67,10 @@ func TestInMemoryRuleStore_GetRule(t *testing.T) {
 		t.Errorf("Expected Name %s, got %s", rule.Name, retrieved.Name)
 	}
 
+	if retrieved.Priority != rule.Priority {
+		t.Errorf("Expected Priority %d, got %d", rule.Priority, retrieved.Priority)
+	}
+
 	// Try to get non-existent rule
 	_, err = store.GetRule("nonexistent")
 	if err == nil {
@@ -73,6 +78,40 @@ func TestInMemoryRuleStore_GetRule(t *testing.T) {
 	}
 }
 
+func TestInMemoryRuleStore_DeliveryPolicy(t *testing.T) {
+	store := NewInMemoryRuleStore()
+
+	rule := &models.Rule{
+		ID:         "rule-1",
+		Name:       "Test Rule",
+		Conditions: []models.Condition{{Metric: "rsi_14", Operator: "<", Value: 30.0}},
+		Delivery: &models.DeliveryPolicy{
+			Mode:      models.DeliveryModePrimaryFallback,
+			Primary:   "websocket",
+			Fallbacks: []string{"kafka"},
+		},
+		Enabled: true,
+	}
+	if err := store.AddRule(rule); err != nil {
+		t.Fatalf("AddRule() error = %v", err)
+	}
+
+	retrieved, err := store.GetRule("rule-1")
+	if err != nil {
+		t.Fatalf("GetRule() error = %v", err)
+	}
+	if retrieved.Delivery == nil || retrieved.Delivery.Primary != "websocket" || len(retrieved.Delivery.Fallbacks) != 1 {
+		t.Fatalf("Expected the delivery policy to be kept, got %+v", retrieved.Delivery)
+	}
+
+	// The stored policy is a copy
+	retrieved.Delivery.Fallbacks[0] = "webhook"
+	again, _ := store.GetRule("rule-1")
+	if again.Delivery.Fallbacks[0] != "kafka" {
+		t.Errorf("Expected the stored delivery policy to be unaffected, got %v", again.Delivery.Fallbacks)
+	}
+}
+
 func TestInMemoryRuleStore_GetAllRules(t *testing.T) {
 	store := NewInMemoryRuleStore()
 
@@ -15,9 +15,7 @@ func ValidateRule(rule *models.Rule) error {
 	}
 
 	// Additional validations
-	// Note: Cooldown is now global and configured via SCANNER_COOLDOWN_DEFAULT env var
-	// Per-rule cooldown field is deprecated and ignored, but we still validate it's non-negative if provided
-	// This allows backward compatibility with API clients that may still send cooldown in requests
+	// Cooldown is in seconds; 0 means no cooldown
 	if rule.Cooldown < 0 {
 		return fmt.Errorf("cooldown must be non-negative, got %d", rule.Cooldown)
 	}
@@ -29,6 +27,35 @@ func ValidateRule(rule *models.Rule) error {
 		}
 	}
 
+	// Validate each grouped condition
+	for i := range rule.ConditionGroups {
+		if err := validateGroup(&rule.ConditionGroups[i]); err != nil {
+			return fmt.Errorf("condition group %d: %w", i, err)
+		}
+	}
+
+	// Validate each exit condition
+	for i, cond := range rule.ExitConditions {
+		if err := ValidateCondition(&cond); err != nil {
+			return fmt.Errorf("exit condition %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// validateGroup validates the conditions of a condition group and its nested groups
+func validateGroup(group *models.ConditionGroup) error {
+	for i, cond := range group.Conditions {
+		if err := ValidateCondition(&cond); err != nil {
+			return fmt.Errorf("condition %d: %w", i, err)
+		}
+	}
+	for i := range group.Groups {
+		if err := validateGroup(&group.Groups[i]); err != nil {
+			return fmt.Errorf("group %d: %w", i, err)
+		}
+	}
 	return nil
 }
 
@@ -58,7 +85,11 @@ func ValidateCondition(cond *models.Condition) error {
 		// Valid numeric type
 		return nil
 	case reflect.String:
-		// String values are only valid for == and != operators
+		// Crossing operators compare against another metric named by the value
+		if cond.IsCrossing() {
+			return ValidateMetricName(cond.Value.(string))
+		}
+		// Other string values are only valid for == and != operators
 		if cond.Operator != "==" && cond.Operator != "!=" {
 			return fmt.Errorf("string values only support == and != operators, got %s", cond.Operator)
 		}
@@ -94,10 +125,12 @@ func ValidateOperator(op string) error {
 		"<=": true,
 		"==": true,
 		"!=": true,
+		models.OperatorCrossesAbove: true,
+		models.OperatorCrossesBelow: true,
 	}
 
 	if !validOps[op] {
-		return fmt.Errorf("unsupported operator: %s (supported: >, <, >=, <=, ==, !=)", op)
+		return fmt.Errorf("unsupported operator: %s (supported: >, <, >=, <=, ==, !=, crosses_above, crosses_below)", op)
 	}
 
 	return nil
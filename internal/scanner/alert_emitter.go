@@ -36,6 +36,11 @@ type AlertEmitterImpl struct {
 	mu      sync.RWMutex
 	running bool
 	stats   AlertEmitterStats
+
+	// Last sequence assigned per symbol, so gateways can order alerts for a symbol
+	sequences  map[string]int64
+	sequenceMu sync.Mutex
+	now        func() time.Time
 }
 
 // AlertEmitterStats holds statistics about alert emission
@@ -54,9 +59,11 @@ func NewAlertEmitter(redis storage.RedisClient, config AlertEmitterConfig) *Aler
 	}
 
 	return &AlertEmitterImpl{
-		config: config,
-		redis:  redis,
-		stats:  AlertEmitterStats{},
+		config:    config,
+		redis:     redis,
+		stats:     AlertEmitterStats{},
+		sequences: make(map[string]int64),
+		now:       time.Now,
 	}
 }
 
@@ -81,6 +88,11 @@ func (ae *AlertEmitterImpl) EmitAlert(alert *models.Alert) error {
 		alert.TraceID = ae.generateTraceID()
 	}
 
+	// Assign the per-symbol sequence used by gateways to order delivery
+	if alert.Sequence == 0 {
+		alert.Sequence = ae.nextSequence(alert.Symbol)
+	}
+
 	// Validate alert (after setting required fields)
 	if err := alert.Validate(); err != nil {
 		return fmt.Errorf("invalid alert: %w", err)
@@ -137,6 +149,21 @@ func (ae *AlertEmitterImpl) generateTraceID() string {
 	return uuid.New().String()
 }
 
+// nextSequence returns the next sequence number for a symbol. Sequences are based on
+// the emission time in microseconds so they keep increasing across scanner restarts,
+// and are bumped past the previous value when several alerts share a microsecond.
+func (ae *AlertEmitterImpl) nextSequence(symbol string) int64 {
+	ae.sequenceMu.Lock()
+	defer ae.sequenceMu.Unlock()
+
+	seq := ae.now().UnixMicro()
+	if last := ae.sequences[symbol]; seq <= last {
+		seq = last + 1
+	}
+	ae.sequences[symbol] = seq
+	return seq
+}
+
 // incrementEmitted increments the emitted alert counter
 func (ae *AlertEmitterImpl) incrementEmitted() {
 	ae.stats.mu.Lock()
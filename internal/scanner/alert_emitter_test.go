@@ -266,3 +266,42 @@ func TestAlertEmitterImpl_Concurrency(t *testing.T) {
 	}
 }
 
+
+func TestAlertEmitterImpl_SequencePerSymbol(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	ae := NewAlertEmitter(redis, DefaultAlertEmitterConfig())
+	frozen := time.Date(2024, 3, 5, 14, 0, 0, 0, time.UTC)
+	ae.now = func() time.Time { return frozen }
+
+	emit := func(ruleID, symbol string) int64 {
+		t.Helper()
+		alert := &models.Alert{RuleID: ruleID, RuleName: "Test Rule", Symbol: symbol, Timestamp: frozen, Price: 150.0}
+		if err := ae.EmitAlert(alert); err != nil {
+			t.Fatalf("Failed to emit alert: %v", err)
+		}
+		return alert.Sequence
+	}
+
+	// Alerts from different rules within the same microsecond still get distinct, increasing sequences
+	first := emit("rule-1", "AAPL")
+	second := emit("rule-2", "AAPL")
+	if first != frozen.UnixMicro() || second != first+1 {
+		t.Errorf("Expected AAPL sequences %d, %d, got %d, %d", frozen.UnixMicro(), frozen.UnixMicro()+1, first, second)
+	}
+
+	// Symbols are sequenced independently
+	if got := emit("rule-1", "MSFT"); got != frozen.UnixMicro() {
+		t.Errorf("Expected MSFT sequence %d, got %d", frozen.UnixMicro(), got)
+	}
+
+	// A restarted emitter continues above the previous sequences
+	restarted := NewAlertEmitter(redis, DefaultAlertEmitterConfig())
+	restarted.now = func() time.Time { return frozen.Add(time.Second) }
+	alert := &models.Alert{RuleID: "rule-1", RuleName: "Test Rule", Symbol: "AAPL", Timestamp: frozen, Price: 150.0}
+	if err := restarted.EmitAlert(alert); err != nil {
+		t.Fatalf("Failed to emit alert: %v", err)
+	}
+	if alert.Sequence <= second {
+		t.Errorf("Expected sequence after restart above %d, got %d", second, alert.Sequence)
+	}
+}
@@ -0,0 +1,164 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+)
+
+// scanOnce runs a single scan cycle for a rule against AAPL trading at 150 with rsi_14 = 25
+func scanOnce(t *testing.T, rule *models.Rule, explain bool) *models.Alert {
+	t.Helper()
+
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+	if err := ruleStore.AddRule(rule); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	config := DefaultScanLoopConfig()
+	config.ExplainAlerts = explain
+	sl := NewScanLoop(config, sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	tick := &models.Tick{Symbol: "AAPL", Price: 150.0, Size: 100, Timestamp: time.Now(), Type: "trade"}
+	if err := sm.UpdateLiveBar("AAPL", tick); err != nil {
+		t.Fatalf("Failed to update live bar: %v", err)
+	}
+	if err := sm.UpdateIndicators("AAPL", map[string]float64{"rsi_14": 25.0}); err != nil {
+		t.Fatalf("Failed to update indicators: %v", err)
+	}
+
+	sl.Scan()
+	if len(emitter.alerts) != 1 {
+		t.Fatalf("Expected 1 alert, got %d", len(emitter.alerts))
+	}
+	return emitter.alerts[0]
+}
+
+func TestScanLoop_ExplainAlert_SingleCondition(t *testing.T) {
+	alert := scanOnce(t, &models.Rule{
+		ID:         "rule-price",
+		Name:       "Price Above 100",
+		Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+		Enabled:    true,
+	}, true)
+
+	if got := alert.Metadata["explanation"]; got != "price=150.0 > 100.0" {
+		t.Errorf("Expected explanation %q, got %v", "price=150.0 > 100.0", got)
+	}
+
+	conditions, ok := alert.Metadata["conditions"].(rules.GroupExplanation)
+	if !ok || len(conditions.Conditions) != 1 {
+		t.Fatalf("Expected 1 condition match in metadata, got %v", alert.Metadata["conditions"])
+	}
+	if match := conditions.Conditions[0]; match.Actual != 150.0 || match.Threshold != 100.0 {
+		t.Errorf("Unexpected condition match %+v", match)
+	}
+}
+
+func TestScanLoop_ExplainAlert_MultiCondition(t *testing.T) {
+	alert := scanOnce(t, &models.Rule{
+		ID:   "rule-oversold",
+		Name: "Oversold Above 100",
+		Conditions: []models.Condition{
+			{Metric: "price", Operator: ">", Value: 100.0},
+			{Metric: "rsi_14", Operator: "<", Value: 30},
+		},
+		Enabled: true,
+	}, true)
+
+	want := "price=150.0 > 100.0 AND rsi_14=25.0 < 30.0"
+	if got := alert.Metadata["explanation"]; got != want {
+		t.Errorf("Expected explanation %q, got %v", want, got)
+	}
+}
+
+func TestScanLoop_ExplainAlert_OrGroupWithMissingMetric(t *testing.T) {
+	alert := scanOnce(t, &models.Rule{
+		ID:   "rule-or",
+		Name: "Oversold Or Gap",
+		ConditionGroups: []models.ConditionGroup{{
+			Operator: models.LogicOr,
+			Conditions: []models.Condition{
+				{Metric: "rsi_14", Operator: "<", Value: 30.0},
+				{Metric: "gap_pct", Operator: ">", Value: 5.0},
+			},
+		}},
+		Enabled: true,
+	}, true)
+
+	want := "rsi_14=25.0 < 30.0 OR gap_pct=n/a > 5.0"
+	if got := alert.Metadata["explanation"]; got != want {
+		t.Errorf("Expected explanation %q, got %v", want, got)
+	}
+}
+
+func TestScanLoop_ExplainAlert_Disabled(t *testing.T) {
+	alert := scanOnce(t, &models.Rule{
+		ID:         "rule-price",
+		Name:       "Price Above 100",
+		Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+		Enabled:    true,
+	}, false)
+
+	if _, ok := alert.Metadata["explanation"]; ok {
+		t.Error("Expected no explanation when explain mode is disabled")
+	}
+}
+
+func TestScanLoop_AlertSeverity(t *testing.T) {
+	// rsi_14 = 25 is 13.8% past 29 (warning) but only 7.4% past 27 (info)
+	deep := scanOnce(t, &models.Rule{
+		ID:         "rule-oversold",
+		Name:       "RSI Below 29",
+		Conditions: []models.Condition{{Metric: "rsi_14", Operator: "<", Value: 29.0}},
+		Enabled:    true,
+	}, false)
+	marginal := scanOnce(t, &models.Rule{
+		ID:         "rule-oversold",
+		Name:       "RSI Below 27",
+		Conditions: []models.Condition{{Metric: "rsi_14", Operator: "<", Value: 27.0}},
+		Enabled:    true,
+	}, false)
+
+	if deep.Severity != models.SeverityWarning || marginal.Severity != models.SeverityInfo {
+		t.Errorf("Expected warning and info severities, got %q and %q", deep.Severity, marginal.Severity)
+	}
+
+	// price = 150 is 50% past 100
+	critical := scanOnce(t, &models.Rule{
+		ID:         "rule-price",
+		Name:       "Price Above 100",
+		Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+		Enabled:    true,
+	}, false)
+	if critical.Severity != models.SeverityCritical {
+		t.Errorf("Expected critical severity, got %q", critical.Severity)
+	}
+}
+
+func TestScanLoop_AlertSeverity_UntakenOrBranch(t *testing.T) {
+	// The untaken branch's metric is missing; the taken one (price = 150, 50% past 100) decides
+	alert := scanOnce(t, &models.Rule{
+		ID:   "rule-or",
+		Name: "Price Or Gap",
+		ConditionGroups: []models.ConditionGroup{{
+			Operator: models.LogicOr,
+			Conditions: []models.Condition{
+				{Metric: "gap_pct", Operator: ">", Value: 5.0},
+				{Metric: "price", Operator: ">", Value: 100.0},
+			},
+		}},
+		Enabled: true,
+	}, false)
+
+	if alert.Severity != models.SeverityCritical {
+		t.Errorf("Expected critical severity from the matched branch, got %q", alert.Severity)
+	}
+}
@@ -0,0 +1,83 @@
+package scanner
+
+import (
+	"math"
+	"sort"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+)
+
+// standardAlertMetrics are attached to capped alerts (after the rule's own metrics) when available
+var standardAlertMetrics = []string{
+	"price",
+	"change_from_close_pct",
+	"volume_daily",
+	"vwap",
+}
+
+// capAlertMetrics selects the metrics attached to an alert when there are more than maxMetrics.
+// Metrics referenced by the rule, including the one bucketed into its dedup key, are always
+// included (even beyond the cap), then the standard
+// set, then the remaining metrics in name order. Returns the selection and the number dropped.
+// Metrics without a value yet (NaN) are never attached and don't count as dropped.
+func capAlertMetrics(rule *models.Rule, metrics map[string]float64, maxMetrics int) (map[string]float64, int) {
+	metrics = availableMetrics(metrics)
+	if maxMetrics <= 0 || len(metrics) <= maxMetrics {
+		return metrics, 0
+	}
+
+	selected := make(map[string]float64, maxMetrics)
+	referenced := rules.ExtractRequiredMetricsFromRule(rule)
+	for name := range referenced {
+		if value, ok := metrics[name]; ok {
+			selected[name] = value
+		}
+	}
+
+	for _, name := range standardAlertMetrics {
+		if len(selected) >= maxMetrics {
+			break
+		}
+		if value, ok := metrics[name]; ok {
+			selected[name] = value
+		}
+	}
+
+	if len(selected) < maxMetrics {
+		remaining := make([]string, 0, len(metrics))
+		for name := range metrics {
+			if _, ok := selected[name]; !ok {
+				remaining = append(remaining, name)
+			}
+		}
+		sort.Strings(remaining)
+		for _, name := range remaining {
+			if len(selected) >= maxMetrics {
+				break
+			}
+			selected[name] = metrics[name]
+		}
+	}
+
+	return selected, len(metrics) - len(selected)
+}
+
+// availableMetrics returns metrics without NaN values (which can't be encoded in the alert
+// payload), copying only when there is something to drop
+func availableMetrics(metrics map[string]float64) map[string]float64 {
+	for _, value := range metrics {
+		if !math.IsNaN(value) {
+			continue
+		}
+
+		available := make(map[string]float64, len(metrics))
+		for name, value := range metrics {
+			if !math.IsNaN(value) {
+				available[name] = value
+			}
+		}
+		return available
+	}
+	return metrics
+}
@@ -0,0 +1,116 @@
+package scanner
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+)
+
+func manyMetrics(n int) map[string]float64 {
+	metrics := make(map[string]float64, n)
+	for i := 0; i < n; i++ {
+		metrics[fmt.Sprintf("custom_metric_%03d", i)] = float64(i)
+	}
+	return metrics
+}
+
+func TestCapAlertMetrics(t *testing.T) {
+	rule := &models.Rule{
+		ID:      "rule-1",
+		Enabled: true,
+		Conditions: []models.Condition{
+			{Metric: "custom_metric_150", Operator: ">", Value: 1.0},
+			{Metric: "rsi_14", Operator: "<", Value: 30.0},
+		},
+	}
+
+	metrics := manyMetrics(200)
+	metrics["rsi_14"] = 25
+	metrics["price"] = 150
+	metrics["volume_daily"] = 1000000
+
+	capped, truncated := capAlertMetrics(rule, metrics, 10)
+	if len(capped) != 10 {
+		t.Errorf("Expected 10 metrics, got %d", len(capped))
+	}
+	if truncated != len(metrics)-10 {
+		t.Errorf("Expected %d truncated, got %d", len(metrics)-10, truncated)
+	}
+	for _, name := range []string{"custom_metric_150", "rsi_14", "price", "volume_daily"} {
+		if _, ok := capped[name]; !ok {
+			t.Errorf("Expected %s to be included", name)
+		}
+	}
+	if capped["rsi_14"] != 25 {
+		t.Errorf("Expected rsi_14 value 25, got %v", capped["rsi_14"])
+	}
+
+	// Referenced metrics are kept even when they exceed the cap
+	capped, _ = capAlertMetrics(rule, metrics, 1)
+	if len(capped) != 2 {
+		t.Errorf("Expected both referenced metrics beyond a cap of 1, got %v", capped)
+	}
+
+	// The dedup key's metric is kept too, so the alert service doesn't key it as "na"
+	rule.DedupKey = &models.DedupKey{
+		Fields:     []string{models.DedupFieldRule, models.DedupFieldMetric},
+		Metric:     "custom_metric_007",
+		BucketSize: 1,
+	}
+	capped, _ = capAlertMetrics(rule, metrics, 1)
+	if _, ok := capped["custom_metric_007"]; !ok || len(capped) != 3 {
+		t.Errorf("Expected the dedup key metric kept beyond the cap, got %v", capped)
+	}
+	rule.DedupKey = nil
+
+	// Under the cap (or unlimited) the map is unchanged
+	if capped, truncated := capAlertMetrics(rule, metrics, 0); len(capped) != len(metrics) || truncated != 0 {
+		t.Errorf("Expected unlimited cap to keep all metrics, got %d (%d truncated)", len(capped), truncated)
+	}
+	small := map[string]float64{"price": 1, "rsi_14": 2}
+	if capped, truncated := capAlertMetrics(rule, small, 10); len(capped) != 2 || truncated != 0 {
+		t.Errorf("Expected small map unchanged, got %v (%d truncated)", capped, truncated)
+	}
+}
+
+func TestScanLoop_AlertMetricsCapped(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	rule := &models.Rule{
+		ID:   "rule-capped",
+		Name: "Price Above 100",
+		Conditions: []models.Condition{
+			{Metric: "price", Operator: ">", Value: 100.0},
+		},
+		Enabled: true,
+	}
+	if err := ruleStore.AddRule(rule); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	config := DefaultScanLoopConfig()
+	config.MaxAlertMetrics = 3
+	sl := NewScanLoop(config, sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+
+	metrics := manyMetrics(50)
+	metrics["price"] = 150
+	alert := sl.createAlert(rule, rule.EntryGroup(), "AAPL", metrics, &SymbolStateSnapshot{})
+
+	attached, ok := alert.Metadata["metrics"].(map[string]float64)
+	if !ok {
+		t.Fatalf("Expected metrics map in alert metadata, got %T", alert.Metadata["metrics"])
+	}
+	if len(attached) != 3 {
+		t.Errorf("Expected 3 attached metrics, got %d", len(attached))
+	}
+	if attached["price"] != 150 {
+		t.Errorf("Expected referenced metric price to be attached, got %v", attached)
+	}
+	if alert.Metadata["metrics_truncated"] != 48 {
+		t.Errorf("Expected 48 truncated metrics, got %v", alert.Metadata["metrics_truncated"])
+	}
+}
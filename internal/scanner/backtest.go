@@ -0,0 +1,242 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// BacktestIndicatorEngine computes indicators from replayed bars. *indicator.Engine satisfies it.
+type BacktestIndicatorEngine interface {
+	// ProcessBar adds a finalized bar to the symbol's indicator state
+	ProcessBar(bar *models.Bar1m) error
+	// GetIndicators returns the symbol's current indicator values
+	GetIndicators(symbol string) (map[string]float64, error)
+}
+
+// BacktestConfig holds configuration for backtests
+type BacktestConfig struct {
+	ScanLoop     ScanLoopConfig // Scan loop settings the replayed bars are scanned with
+	MaxFinalBars int            // Finalized bars kept per symbol (default: 200)
+	MaxRange     time.Duration  // Longest start-end range a backtest may replay (default: 31 days)
+}
+
+// DefaultBacktestConfig returns default configuration
+func DefaultBacktestConfig() BacktestConfig {
+	return BacktestConfig{
+		ScanLoop:     DefaultScanLoopConfig(),
+		MaxFinalBars: 200,
+		MaxRange:     31 * 24 * time.Hour,
+	}
+}
+
+// BacktestRequest describes the history and rules to backtest
+type BacktestRequest struct {
+	Symbols []string       `json:"symbols"`
+	Start   time.Time      `json:"start"`
+	End     time.Time      `json:"end"`
+	Rules   []*models.Rule `json:"rules"`
+}
+
+// BacktestResult holds the alerts that would have fired during a backtest
+type BacktestResult struct {
+	Start        time.Time       `json:"start"`
+	End          time.Time       `json:"end"`
+	BarsReplayed int             `json:"bars_replayed"`
+	ScanCycles   int             `json:"scan_cycles"`
+	Alerts       []*models.Alert `json:"alerts"`    // Simulated alerts, timestamped with bar time
+	RuleHits     map[string]int  `json:"rule_hits"` // Alert count per rule ID
+}
+
+// Backtester replays historical bars through a scan loop to show when rules would have
+// fired. Metrics are computed by the scan loop's metric registry, so results match live scans.
+type Backtester struct {
+	config     BacktestConfig
+	barStorage storage.BarStorage
+
+	mu               sync.RWMutex
+	indicatorFactory func() BacktestIndicatorEngine // Optional, creates a fresh engine per run
+}
+
+// NewBacktester creates a backtester reading bars from barStorage
+func NewBacktester(barStorage storage.BarStorage, config BacktestConfig) *Backtester {
+	if barStorage == nil {
+		panic("barStorage cannot be nil")
+	}
+
+	defaults := DefaultBacktestConfig()
+	if config.MaxFinalBars <= 0 {
+		config.MaxFinalBars = defaults.MaxFinalBars
+	}
+	if config.MaxRange <= 0 {
+		config.MaxRange = defaults.MaxRange
+	}
+
+	return &Backtester{
+		config:     config,
+		barStorage: barStorage,
+	}
+}
+
+// SetIndicatorEngineFactory sets the factory for the indicator engine replayed bars are fed
+// to, so indicator-based rules (e.g. rsi_14) can be backtested. Without it, only metrics
+// computed from bars are available.
+func (b *Backtester) SetIndicatorEngineFactory(factory func() BacktestIndicatorEngine) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.indicatorFactory = factory
+}
+
+// Run replays the request's bars in chronological order, scanning after each bar close.
+// Bars of different symbols closing in the same minute are scanned together, as the live
+// scanner would see them.
+func (b *Backtester) Run(ctx context.Context, req BacktestRequest) (*BacktestResult, error) {
+	if len(req.Symbols) == 0 {
+		return nil, errors.New("backtest requires at least one symbol")
+	}
+	if len(req.Rules) == 0 {
+		return nil, errors.New("backtest requires at least one rule")
+	}
+	if !req.End.After(req.Start) {
+		return nil, errors.New("backtest end must be after start")
+	}
+	if req.End.Sub(req.Start) > b.config.MaxRange {
+		return nil, fmt.Errorf("backtest range %s exceeds maximum of %s", req.End.Sub(req.Start), b.config.MaxRange)
+	}
+
+	bars, err := b.loadBars(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each run scans with its own state, rules and clock, driven by bar time
+	clock := NewSimulationClock(req.Start)
+	stateManager := NewStateManager(b.config.MaxFinalBars)
+	stateManager.SetClock(clock)
+
+	ruleStore := rules.NewInMemoryRuleStore()
+	for _, rule := range req.Rules {
+		backtestRule := *rule
+		backtestRule.Enabled = true
+		if err := ruleStore.AddRule(&backtestRule); err != nil {
+			return nil, fmt.Errorf("failed to add rule %s: %w", rule.ID, err)
+		}
+	}
+
+	// Rule cooldowns run in bar time
+	cooldownTracker := NewCooldownTracker(0)
+	cooldownTracker.SetClock(clock)
+
+	collector := &backtestCollector{}
+	scanLoop := NewScanLoop(b.config.ScanLoop, stateManager, ruleStore, rules.NewCompiler(nil), cooldownTracker, collector, nil)
+	scanLoop.SetClock(clock)
+	if err := scanLoop.ReloadRules(); err != nil {
+		return nil, fmt.Errorf("failed to compile rules: %w", err)
+	}
+
+	b.mu.RLock()
+	indicatorFactory := b.indicatorFactory
+	b.mu.RUnlock()
+	var indicators BacktestIndicatorEngine
+	if indicatorFactory != nil {
+		indicators = indicatorFactory()
+	}
+
+	result := &BacktestResult{
+		Start:    req.Start,
+		End:      req.End,
+		RuleHits: make(map[string]int),
+	}
+
+	for i, bar := range bars {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if err := stateManager.UpdateFinalizedBar(bar); err != nil {
+			return nil, fmt.Errorf("failed to apply bar for %s: %w", bar.Symbol, err)
+		}
+		if indicators != nil {
+			if err := b.updateIndicators(stateManager, indicators, bar); err != nil {
+				logger.Debug("Backtest indicators unavailable for bar",
+					logger.ErrorField(err),
+					logger.String("symbol", bar.Symbol),
+					logger.Time("timestamp", bar.Timestamp),
+				)
+			}
+		}
+		result.BarsReplayed++
+
+		// Scan once every bar closing in this minute has been applied
+		if i+1 < len(bars) && bars[i+1].Timestamp.Equal(bar.Timestamp) {
+			continue
+		}
+		scanLoop.Scan()
+		result.ScanCycles++
+	}
+
+	result.Alerts = collector.alerts
+	for _, alert := range result.Alerts {
+		result.RuleHits[alert.RuleID]++
+	}
+
+	logger.Info("Backtest complete",
+		logger.Int("symbols", len(req.Symbols)),
+		logger.Int("rules", len(req.Rules)),
+		logger.Int("bars", result.BarsReplayed),
+		logger.Int("alerts", len(result.Alerts)),
+	)
+
+	return result, nil
+}
+
+// loadBars loads the request's bars and sorts them chronologically (by symbol within a minute)
+func (b *Backtester) loadBars(ctx context.Context, req BacktestRequest) ([]*models.Bar1m, error) {
+	var bars []*models.Bar1m
+	for _, symbol := range req.Symbols {
+		symbolBars, err := b.barStorage.GetBars(ctx, symbol, req.Start, req.End)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load bars for %s: %w", symbol, err)
+		}
+		bars = append(bars, symbolBars...)
+	}
+
+	sort.SliceStable(bars, func(i, j int) bool {
+		if !bars[i].Timestamp.Equal(bars[j].Timestamp) {
+			return bars[i].Timestamp.Before(bars[j].Timestamp)
+		}
+		return bars[i].Symbol < bars[j].Symbol
+	})
+	return bars, nil
+}
+
+// updateIndicators feeds a bar to the indicator engine and stores the symbol's indicators
+func (b *Backtester) updateIndicators(stateManager *StateManager, indicators BacktestIndicatorEngine, bar *models.Bar1m) error {
+	if err := indicators.ProcessBar(bar); err != nil {
+		return err
+	}
+	values, err := indicators.GetIndicators(bar.Symbol)
+	if err != nil {
+		return err
+	}
+	return stateManager.UpdateIndicators(bar.Symbol, values)
+}
+
+// backtestCollector collects the alerts a backtest scan loop emits
+type backtestCollector struct {
+	alerts []*models.Alert
+}
+
+// EmitAlert records the alert
+func (c *backtestCollector) EmitAlert(alert *models.Alert) error {
+	c.alerts = append(c.alerts, alert)
+	return nil
+}
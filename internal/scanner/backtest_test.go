@@ -0,0 +1,185 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// stubRSIEngine computes a simple (unsmoothed) 14-period RSI from bar closes
+type stubRSIEngine struct {
+	closes map[string][]float64
+}
+
+func newStubRSIEngine() BacktestIndicatorEngine {
+	return &stubRSIEngine{closes: make(map[string][]float64)}
+}
+
+func (e *stubRSIEngine) ProcessBar(bar *models.Bar1m) error {
+	e.closes[bar.Symbol] = append(e.closes[bar.Symbol], bar.Close)
+	return nil
+}
+
+func (e *stubRSIEngine) GetIndicators(symbol string) (map[string]float64, error) {
+	closes := e.closes[symbol]
+	if len(closes) < 15 {
+		return map[string]float64{}, nil
+	}
+
+	gains, losses := 0.0, 0.0
+	for i := len(closes) - 14; i < len(closes); i++ {
+		if change := closes[i] - closes[i-1]; change > 0 {
+			gains += change
+		} else {
+			losses -= change
+		}
+	}
+	if losses == 0 {
+		return map[string]float64{"rsi_14": 100}, nil
+	}
+	return map[string]float64{"rsi_14": 100 - 100/(1+gains/losses)}, nil
+}
+
+// setupBacktest stores 40 bars per symbol: closes falling by 1 for 20 minutes, then rising
+func setupBacktest(start time.Time, symbols ...string) *mockBarStorage {
+	barStorage := newMockBarStorage()
+	for _, symbol := range symbols {
+		bars := make([]*models.Bar1m, 0, 40)
+		for i := 0; i < 40; i++ {
+			close := 100.0 - float64(i)
+			if i >= 20 {
+				close = 80.0 + float64(i-19)
+			}
+			bars = append(bars, &models.Bar1m{
+				Symbol:    symbol,
+				Timestamp: start.Add(time.Duration(i) * time.Minute),
+				Open:      close,
+				High:      close + 0.5,
+				Low:       close - 0.5,
+				Close:     close,
+				Volume:    1000,
+			})
+		}
+		barStorage.WriteBars(context.Background(), bars)
+	}
+	return barStorage
+}
+
+func oversoldRules() []*models.Rule {
+	return []*models.Rule{
+		{
+			ID:         "rule-oversold",
+			Name:       "RSI Oversold",
+			Conditions: []models.Condition{{Metric: "rsi_14", Operator: "<", Value: 30.0}},
+			Enabled:    true,
+		},
+		{
+			ID:         "rule-never",
+			Name:       "Price Above 1000",
+			Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 1000.0}},
+			Enabled:    true,
+		},
+	}
+}
+
+func TestBacktester_RSIRule(t *testing.T) {
+	start := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+	backtester := NewBacktester(setupBacktest(start, "AAPL"), DefaultBacktestConfig())
+	backtester.SetIndicatorEngineFactory(newStubRSIEngine)
+
+	result, err := backtester.Run(context.Background(), BacktestRequest{
+		Symbols: []string{"AAPL"},
+		Start:   start,
+		End:     start.Add(time.Hour),
+		Rules:   oversoldRules(),
+	})
+	if err != nil {
+		t.Fatalf("Backtest failed: %v", err)
+	}
+
+	if result.BarsReplayed != 40 || result.ScanCycles != 40 {
+		t.Errorf("Expected 40 bars and scan cycles, got %d and %d", result.BarsReplayed, result.ScanCycles)
+	}
+
+	// RSI is 0 from the 15th bar through the decline and stays below 30 for the
+	// first 4 rising bars: bars 14 through 23
+	if len(result.Alerts) != 10 {
+		t.Fatalf("Expected 10 alerts, got %d", len(result.Alerts))
+	}
+	if result.RuleHits["rule-oversold"] != 10 {
+		t.Errorf("Expected 10 hits for rule-oversold, got %d", result.RuleHits["rule-oversold"])
+	}
+	if hits, ok := result.RuleHits["rule-never"]; ok {
+		t.Errorf("Expected no hits for rule-never, got %d", hits)
+	}
+
+	// Alerts are timestamped with the close of the bar that triggered them
+	for i, alert := range result.Alerts {
+		expected := start.Add(time.Duration(15+i) * time.Minute)
+		if !alert.Timestamp.Equal(expected) {
+			t.Errorf("Alert %d: expected timestamp %v, got %v", i, expected, alert.Timestamp)
+		}
+		if alert.Symbol != "AAPL" || alert.RuleID != "rule-oversold" {
+			t.Errorf("Alert %d: unexpected alert %s/%s", i, alert.RuleID, alert.Symbol)
+		}
+	}
+}
+
+func TestBacktester_CooldownFollowsBarTime(t *testing.T) {
+	start := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+	backtester := NewBacktester(setupBacktest(start, "AAPL", "MSFT"), DefaultBacktestConfig())
+	backtester.SetIndicatorEngineFactory(newStubRSIEngine)
+
+	oversold := oversoldRules()
+	oversold[0].Cooldown = 300
+	result, err := backtester.Run(context.Background(), BacktestRequest{
+		Symbols: []string{"AAPL", "MSFT"},
+		Start:   start,
+		End:     start.Add(time.Hour),
+		Rules:   oversold,
+	})
+	if err != nil {
+		t.Fatalf("Backtest failed: %v", err)
+	}
+
+	// Both symbols close bars in the same minutes, so they are scanned together
+	if result.BarsReplayed != 80 || result.ScanCycles != 40 {
+		t.Errorf("Expected 80 bars over 40 scan cycles, got %d and %d", result.BarsReplayed, result.ScanCycles)
+	}
+
+	// Each symbol fires at bar 14 and again once the 5 minute cooldown ends at bar 19
+	perSymbol := make(map[string]int)
+	for _, alert := range result.Alerts {
+		perSymbol[alert.Symbol]++
+	}
+	if perSymbol["AAPL"] != 2 || perSymbol["MSFT"] != 2 {
+		t.Errorf("Expected 2 alerts per symbol, got %v", perSymbol)
+	}
+	if result.RuleHits["rule-oversold"] != 4 {
+		t.Errorf("Expected 4 hits for rule-oversold, got %d", result.RuleHits["rule-oversold"])
+	}
+}
+
+func TestBacktester_InvalidRequests(t *testing.T) {
+	start := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+	backtester := NewBacktester(newMockBarStorage(), DefaultBacktestConfig())
+
+	requests := map[string]BacktestRequest{
+		"no symbols":    {Start: start, End: start.Add(time.Hour), Rules: oversoldRules()},
+		"no rules":      {Symbols: []string{"AAPL"}, Start: start, End: start.Add(time.Hour)},
+		"end not after": {Symbols: []string{"AAPL"}, Start: start, End: start, Rules: oversoldRules()},
+		"range too long": {
+			Symbols: []string{"AAPL"},
+			Start:   start,
+			End:     start.Add(60 * 24 * time.Hour),
+			Rules:   oversoldRules(),
+		},
+	}
+	for name, req := range requests {
+		if _, err := backtester.Run(context.Background(), req); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
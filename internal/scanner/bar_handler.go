@@ -24,6 +24,7 @@ type BarFinalizationHandler struct {
 	mu           sync.RWMutex
 	running      bool
 	stats        BarHandlerStats
+	filter       SymbolFilter // Symbols whose bars are applied (nil = all)
 }
 
 // BarHandlerStats holds statistics about the bar handler
@@ -31,6 +32,7 @@ type BarHandlerStats struct {
 	BarsProcessed int64
 	BarsAcked     int64
 	BarsFailed    int64
+	BarsSkipped   int64 // Bars of symbols owned by other workers, acknowledged unapplied
 	LastBarTime   time.Time
 	Lag           int64
 	mu            sync.RWMutex
@@ -112,6 +114,11 @@ func (bh *BarFinalizationHandler) IsRunning() bool {
 	return bh.running
 }
 
+// SetSymbolFilter sets which symbols' bars are applied; the rest are acknowledged and skipped (call before Start)
+func (bh *BarFinalizationHandler) SetSymbolFilter(filter SymbolFilter) {
+	bh.filter = filter
+}
+
 // GetStats returns current handler statistics
 func (bh *BarFinalizationHandler) GetStats() BarHandlerStats {
 	bh.stats.mu.RLock()
@@ -122,6 +129,7 @@ func (bh *BarFinalizationHandler) GetStats() BarHandlerStats {
 		BarsProcessed: bh.stats.BarsProcessed,
 		BarsAcked:     bh.stats.BarsAcked,
 		BarsFailed:    bh.stats.BarsFailed,
+		BarsSkipped:   bh.stats.BarsSkipped,
 		LastBarTime:   bh.stats.LastBarTime,
 		Lag:           bh.stats.Lag,
 	}
@@ -231,6 +239,12 @@ func (bh *BarFinalizationHandler) processBatch(stream string, messages []storage
 			continue
 		}
 
+		if bh.filter != nil && !bh.filter(bar.Symbol) {
+			processed = append(processed, msg.ID)
+			bh.incrementSkipped()
+			continue
+		}
+
 		// Update state manager with finalized bar
 		err = bh.stateManager.UpdateFinalizedBar(bar)
 		if err != nil {
@@ -321,6 +335,13 @@ func (bh *BarFinalizationHandler) incrementAcked(count int64) {
 	bh.stats.BarsAcked += count
 }
 
+// incrementSkipped increments the skipped bar counter
+func (bh *BarFinalizationHandler) incrementSkipped() {
+	bh.stats.mu.Lock()
+	defer bh.stats.mu.Unlock()
+	bh.stats.BarsSkipped++
+}
+
 // incrementFailed increments the failed bar counter
 func (bh *BarFinalizationHandler) incrementFailed() {
 	bh.stats.mu.Lock()
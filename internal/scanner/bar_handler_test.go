@@ -233,3 +233,45 @@ func TestBarFinalizationHandler_UpdateFinalizedBar(t *testing.T) {
 	}
 }
 
+
+func TestBarFinalizationHandler_SymbolFilter(t *testing.T) {
+	sm := NewStateManager(10)
+	config := pubsub.DefaultStreamConsumerConfig("bars.finalized", "scanner-group-0", "scanner-1")
+	redis := storage.NewMockRedisClient()
+	bh := NewBarFinalizationHandler(redis, config, sm)
+	bh.SetSymbolFilter(func(symbol string) bool { return symbol == "AAPL" })
+
+	messages := make([]storage.StreamMessage, 0, 2)
+	for i, symbol := range []string{"AAPL", "GOOGL"} {
+		barJSON, _ := json.Marshal(&models.Bar1m{
+			Symbol:    symbol,
+			Timestamp: time.Now(),
+			Open:      100.0,
+			High:      101.0,
+			Low:       99.0,
+			Close:     100.5,
+			Volume:    1000,
+			VWAP:      100.2,
+		})
+		messages = append(messages, storage.StreamMessage{
+			ID:     fmt.Sprintf("%d-0", i),
+			Values: map[string]interface{}{"bar": string(barJSON)},
+		})
+	}
+	bh.processBatch("bars.finalized", messages)
+
+	if sm.GetState("AAPL") == nil {
+		t.Error("Expected AAPL state to exist")
+	}
+	if sm.GetState("GOOGL") != nil {
+		t.Error("Expected GOOGL bar to be skipped")
+	}
+
+	stats := bh.GetStats()
+	if stats.BarsProcessed != 1 || stats.BarsSkipped != 1 {
+		t.Errorf("Expected 1 processed and 1 skipped bar, got %d and %d", stats.BarsProcessed, stats.BarsSkipped)
+	}
+	if stats.BarsAcked != 2 {
+		t.Errorf("Expected skipped bars to be acknowledged, got %d acked", stats.BarsAcked)
+	}
+}
@@ -0,0 +1,396 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// BreadthKeyPrefix prefixes the Redis keys through which scanner workers share breadth
+// matches: <prefix>:<rule_id> (sorted set of symbols by latest match time, in ms),
+// <prefix>:<rule_id>:values (hash of symbol -> triggering metric value) and
+// <prefix>:<rule_id>:fired (set while the threshold is crossed and the alert was emitted)
+const BreadthKeyPrefix = "scanner:breadth"
+
+// BreadthRule emits one market breadth alert when at least Threshold distinct symbols
+// match a system rule within Window
+type BreadthRule struct {
+	RuleID    string
+	Threshold int
+	Window    time.Duration
+}
+
+// ParseBreadthRules parses breadth rules from "rule_id:threshold:window,..." (e.g. "gap-up:50:5m")
+func ParseBreadthRules(spec string) ([]BreadthRule, error) {
+	var breadthRules []BreadthRule
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid breadth rule %q (expected rule_id:threshold:window)", entry)
+		}
+
+		ruleID := strings.TrimSpace(parts[0])
+		if ruleID == "" {
+			return nil, fmt.Errorf("invalid breadth rule %q: rule ID is required", entry)
+		}
+		if seen[ruleID] {
+			return nil, fmt.Errorf("duplicate breadth rule for %q", ruleID)
+		}
+
+		threshold, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || threshold <= 0 {
+			return nil, fmt.Errorf("invalid breadth rule %q: threshold must be a positive integer", entry)
+		}
+
+		window, err := time.ParseDuration(strings.TrimSpace(parts[2]))
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf("invalid breadth rule %q: window must be a positive duration", entry)
+		}
+
+		seen[ruleID] = true
+		breadthRules = append(breadthRules, BreadthRule{RuleID: ruleID, Threshold: threshold, Window: window})
+	}
+	return breadthRules, nil
+}
+
+// BreadthContributor is a symbol contributing to a breadth alert, with its value of the
+// rule's triggering metric at its latest match
+type BreadthContributor struct {
+	Symbol string  `json:"symbol"`
+	Value  float64 `json:"value"`
+}
+
+// breadthCrossing is a breadth rule whose threshold was crossed, with the matching symbols
+type breadthCrossing struct {
+	rule    BreadthRule
+	symbols []string           // Sorted
+	values  map[string]float64 // Symbol -> triggering metric value (NaN if unavailable)
+}
+
+// breadthMatch is a symbol's latest match of a breadth rule
+type breadthMatch struct {
+	at    time.Time
+	value float64 // Triggering metric value (NaN if unavailable)
+}
+
+// breadthRankMetric returns the metric breadth contributors are ranked by: the rule's
+// first condition, highest first unless the condition looks for low values
+func breadthRankMetric(rule *models.Rule) (metric string, ascending bool) {
+	if rule == nil {
+		return "", false
+	}
+	conditions := rule.EntryConditions()
+	if len(conditions) == 0 {
+		return "", false
+	}
+	condition := conditions[0]
+	return condition.Metric, condition.Operator == "<" || condition.Operator == "<=" || condition.Operator == models.OperatorCrossesBelow
+}
+
+// breadthMetricValue returns a matching symbol's value of the rule's triggering metric,
+// or NaN if the rule has none or it was not computed
+func breadthMetricValue(rule *models.Rule, metrics map[string]float64) float64 {
+	metric, _ := breadthRankMetric(rule)
+	if value, ok := metrics[metric]; ok && metric != "" {
+		return value
+	}
+	return math.NaN()
+}
+
+// topBreadthContributors returns up to k contributors of a crossing ranked by the rule's
+// triggering metric, ties broken by symbol. Symbols without a value are left out.
+func topBreadthContributors(rule *models.Rule, crossing breadthCrossing, k int) []BreadthContributor {
+	metric, ascending := breadthRankMetric(rule)
+	if k <= 0 || metric == "" {
+		return nil
+	}
+
+	contributors := make([]BreadthContributor, 0, len(crossing.symbols))
+	for _, symbol := range crossing.symbols {
+		value, ok := crossing.values[symbol]
+		if !ok || math.IsNaN(value) {
+			continue
+		}
+		contributors = append(contributors, BreadthContributor{Symbol: symbol, Value: value})
+	}
+
+	sort.SliceStable(contributors, func(i, j int) bool {
+		if contributors[i].Value != contributors[j].Value {
+			if ascending {
+				return contributors[i].Value < contributors[j].Value
+			}
+			return contributors[i].Value > contributors[j].Value
+		}
+		return contributors[i].Symbol < contributors[j].Symbol
+	})
+
+	if len(contributors) > k {
+		contributors = contributors[:k]
+	}
+	return contributors
+}
+
+// breadthAggregator counts the distinct symbols matching each breadth rule within its
+// window. A rule fires once when its count reaches the threshold and re-arms when the
+// count drops below it again.
+//
+// Each worker only scans its partition of the symbols, so with a Redis client the workers
+// pool their matches in Redis and the threshold applies to the whole market; the first
+// worker to see it crossed emits the alert. Without one, only this worker's matches count.
+type breadthAggregator struct {
+	rules   map[string]BreadthRule
+	redis   storage.RedisClient // Shared match store (nil = this worker's matches only)
+	mu      sync.Mutex
+	matches map[string]map[string]breadthMatch // Rule ID -> symbol -> latest match (not yet shared, with Redis)
+	fired   map[string]bool                    // Rule ID -> threshold crossed and not yet re-armed
+}
+
+// newBreadthAggregator creates an aggregator for the given breadth rules (nil = disabled)
+func newBreadthAggregator(breadthRules []BreadthRule) *breadthAggregator {
+	if len(breadthRules) == 0 {
+		return nil
+	}
+	rules := make(map[string]BreadthRule, len(breadthRules))
+	for _, rule := range breadthRules {
+		rules[rule.RuleID] = rule
+	}
+	return &breadthAggregator{
+		rules:   rules,
+		matches: make(map[string]map[string]breadthMatch),
+		fired:   make(map[string]bool),
+	}
+}
+
+// SetBreadthStore makes breadth rules count the matches of every scanner worker, shared
+// through Redis, instead of only the symbols of this worker's partition
+func (sl *ScanLoop) SetBreadthStore(redis storage.RedisClient) {
+	if sl.breadth != nil {
+		sl.breadth.redis = redis
+	}
+}
+
+// Record records that symbol matched the rule at now with value of the triggering metric
+// (NaN if unavailable). Rules without a breadth rule are ignored.
+func (b *breadthAggregator) Record(ruleID, symbol string, value float64, now time.Time) {
+	if b == nil {
+		return
+	}
+	if _, ok := b.rules[ruleID]; !ok {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	symbols, ok := b.matches[ruleID]
+	if !ok {
+		symbols = make(map[string]breadthMatch)
+		b.matches[ruleID] = symbols
+	}
+	symbols[symbol] = breadthMatch{at: now, value: value}
+}
+
+// Evaluate drops matches older than each rule's window and returns the rules whose
+// threshold was crossed since they were last armed
+func (b *breadthAggregator) Evaluate(ctx context.Context, now time.Time) []breadthCrossing {
+	if b == nil {
+		return nil
+	}
+	if b.redis != nil {
+		return b.evaluateShared(ctx, now)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var crossings []breadthCrossing
+	for ruleID, rule := range b.rules {
+		symbols := b.matches[ruleID]
+		for symbol, match := range symbols {
+			if now.Sub(match.at) > rule.Window {
+				delete(symbols, symbol)
+			}
+		}
+
+		if len(symbols) < rule.Threshold {
+			b.fired[ruleID] = false
+			continue
+		}
+		if b.fired[ruleID] {
+			continue
+		}
+
+		b.fired[ruleID] = true
+		crossing := breadthCrossing{
+			rule:    rule,
+			symbols: make([]string, 0, len(symbols)),
+			values:  make(map[string]float64, len(symbols)),
+		}
+		for symbol, match := range symbols {
+			crossing.symbols = append(crossing.symbols, symbol)
+			crossing.values[symbol] = match.value
+		}
+		sort.Strings(crossing.symbols)
+		crossings = append(crossings, crossing)
+	}
+
+	sort.Slice(crossings, func(i, j int) bool {
+		return crossings[i].rule.RuleID < crossings[j].rule.RuleID
+	})
+	return crossings
+}
+
+// evaluateShared adds this worker's matches since the last cycle to Redis and evaluates
+// each rule against the matches of every worker. A rule whose Redis operations fail is
+// skipped this cycle; its unshared matches are kept for the next one.
+func (b *breadthAggregator) evaluateShared(ctx context.Context, now time.Time) []breadthCrossing {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	b.mu.Lock()
+	pending := b.matches
+	b.matches = make(map[string]map[string]breadthMatch)
+	b.mu.Unlock()
+
+	var crossings []breadthCrossing
+	for ruleID, rule := range b.rules {
+		crossing, crossed, err := b.evaluateSharedRule(ctx, rule, pending[ruleID], now)
+		if err != nil {
+			logger.Warn("Failed to evaluate breadth rule",
+				logger.ErrorField(err),
+				logger.String("rule_id", ruleID),
+			)
+			b.restorePending(ruleID, pending[ruleID])
+			continue
+		}
+		if crossed {
+			crossings = append(crossings, crossing)
+		}
+	}
+
+	sort.Slice(crossings, func(i, j int) bool {
+		return crossings[i].rule.RuleID < crossings[j].rule.RuleID
+	})
+	return crossings
+}
+
+// evaluateSharedRule shares a rule's new matches and returns its crossing if this worker
+// is the one to emit it
+func (b *breadthAggregator) evaluateSharedRule(ctx context.Context, rule BreadthRule, matches map[string]breadthMatch, now time.Time) (breadthCrossing, bool, error) {
+	key := fmt.Sprintf("%s:%s", BreadthKeyPrefix, rule.RuleID)
+	valuesKey := key + ":values"
+	firedKey := key + ":fired"
+
+	if len(matches) > 0 {
+		scores := make(map[string]float64, len(matches))
+		values := make(map[string]string, len(matches))
+		for symbol, match := range matches {
+			scores[symbol] = float64(match.at.UnixMilli())
+			values[symbol] = strconv.FormatFloat(match.value, 'g', -1, 64)
+		}
+		if err := b.redis.HSetBatch(ctx, valuesKey, values); err != nil {
+			return breadthCrossing{}, false, fmt.Errorf("failed to share match values: %w", err)
+		}
+		if err := b.redis.ZAddBatch(ctx, key, scores); err != nil {
+			return breadthCrossing{}, false, fmt.Errorf("failed to share matches: %w", err)
+		}
+	}
+
+	members, err := b.redis.ZRevRange(ctx, key, 0, -1)
+	if err != nil {
+		return breadthCrossing{}, false, fmt.Errorf("failed to read matches: %w", err)
+	}
+	cutoff := float64(now.Add(-rule.Window).UnixMilli())
+	symbols := make([]string, 0, len(members))
+	var expired []string
+	for _, member := range members {
+		if member.Score < cutoff {
+			expired = append(expired, member.Member)
+		} else {
+			symbols = append(symbols, member.Member)
+		}
+	}
+	if len(expired) > 0 {
+		if err := b.redis.ZRem(ctx, key, expired...); err != nil {
+			return breadthCrossing{}, false, fmt.Errorf("failed to drop expired matches: %w", err)
+		}
+		if err := b.redis.HDel(ctx, valuesKey, expired...); err != nil {
+			return breadthCrossing{}, false, fmt.Errorf("failed to drop expired match values: %w", err)
+		}
+	}
+
+	if len(symbols) < rule.Threshold {
+		if err := b.redis.Delete(ctx, firedKey); err != nil {
+			return breadthCrossing{}, false, fmt.Errorf("failed to re-arm: %w", err)
+		}
+		return breadthCrossing{}, false, nil
+	}
+
+	// Only the first worker to see the threshold crossed emits the alert
+	claimed, err := b.redis.SetNX(ctx, firedKey, now.UnixMilli(), 0)
+	if err != nil {
+		return breadthCrossing{}, false, fmt.Errorf("failed to claim the alert: %w", err)
+	}
+	if !claimed {
+		return breadthCrossing{}, false, nil
+	}
+
+	values, err := b.redis.HGetAll(ctx, valuesKey)
+	if err != nil {
+		// The alert is claimed, so emit it without the values rather than not at all
+		logger.Warn("Failed to read breadth match values",
+			logger.ErrorField(err),
+			logger.String("rule_id", rule.RuleID),
+		)
+	}
+	crossing := breadthCrossing{
+		rule:    rule,
+		symbols: symbols,
+		values:  make(map[string]float64, len(symbols)),
+	}
+	for _, symbol := range symbols {
+		value, err := strconv.ParseFloat(values[symbol], 64)
+		if err != nil {
+			value = math.NaN()
+		}
+		crossing.values[symbol] = value
+	}
+	sort.Strings(crossing.symbols)
+	return crossing, true, nil
+}
+
+// restorePending puts back matches that could not be shared, unless the symbol matched again since
+func (b *breadthAggregator) restorePending(ruleID string, matches map[string]breadthMatch) {
+	if len(matches) == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	symbols, ok := b.matches[ruleID]
+	if !ok {
+		symbols = make(map[string]breadthMatch, len(matches))
+		b.matches[ruleID] = symbols
+	}
+	for symbol, match := range matches {
+		if _, ok := symbols[symbol]; !ok {
+			symbols[symbol] = match
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+func TestParseBreadthRules(t *testing.T) {
+	got, err := ParseBreadthRules("gap-up:50:5m, rsi-oversold:20:1m30s,")
+	if err != nil {
+		t.Fatalf("ParseBreadthRules() error = %v", err)
+	}
+	want := []BreadthRule{
+		{RuleID: "gap-up", Threshold: 50, Window: 5 * time.Minute},
+		{RuleID: "rsi-oversold", Threshold: 20, Window: 90 * time.Second},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseBreadthRules() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Rule %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if rules, err := ParseBreadthRules(""); err != nil || len(rules) != 0 {
+		t.Errorf("ParseBreadthRules(\"\") = %+v, %v, want no rules", rules, err)
+	}
+
+	for _, spec := range []string{
+		"gap-up:50",
+		":50:5m",
+		"gap-up:0:5m",
+		"gap-up:many:5m",
+		"gap-up:50:0s",
+		"gap-up:50:soon",
+		"gap-up:50:5m,gap-up:10:1m",
+	} {
+		if _, err := ParseBreadthRules(spec); err == nil {
+			t.Errorf("ParseBreadthRules(%q) expected error", spec)
+		}
+	}
+}
+
+func TestBreadthAggregator_FiresOnceAndRearms(t *testing.T) {
+	b := newBreadthAggregator([]BreadthRule{{RuleID: "rule-1", Threshold: 3, Window: time.Minute}})
+	start := time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC)
+
+	b.Record("rule-1", "AAPL", 0, start)
+	b.Record("rule-1", "MSFT", 0, start)
+	b.Record("rule-1", "MSFT", 0, start.Add(10*time.Second)) // Repeated matches count once
+	b.Record("rule-2", "TSLA", 0, start)                     // Not a breadth rule
+	if crossings := b.Evaluate(context.Background(), start.Add(10*time.Second)); len(crossings) != 0 {
+		t.Fatalf("Expected no crossing below the threshold, got %+v", crossings)
+	}
+
+	b.Record("rule-1", "NVDA", 0, start.Add(20*time.Second))
+	crossings := b.Evaluate(context.Background(), start.Add(20*time.Second))
+	if len(crossings) != 1 {
+		t.Fatalf("Expected 1 crossing at the threshold, got %d", len(crossings))
+	}
+	if got := fmt.Sprint(crossings[0].symbols); got != "[AAPL MSFT NVDA]" {
+		t.Errorf("Expected sorted matching symbols, got %s", got)
+	}
+
+	// Further matches while above the threshold don't fire again
+	b.Record("rule-1", "AMD", 0, start.Add(30*time.Second))
+	if crossings := b.Evaluate(context.Background(), start.Add(30*time.Second)); len(crossings) != 0 {
+		t.Errorf("Expected no repeat crossing, got %+v", crossings)
+	}
+
+	// AAPL falls out of the window (MSFT's latest match is still inside it)
+	if crossings := b.Evaluate(context.Background(), start.Add(65*time.Second)); len(crossings) != 0 {
+		t.Errorf("Expected no crossing while above the threshold, got %+v", crossings)
+	}
+
+	// Dropping below the threshold re-arms the rule
+	if crossings := b.Evaluate(context.Background(), start.Add(85*time.Second)); len(crossings) != 0 {
+		t.Errorf("Expected no crossing below the threshold, got %+v", crossings)
+	}
+	b.Record("rule-1", "AAPL", 0, start.Add(90*time.Second))
+	b.Record("rule-1", "META", 0, start.Add(90*time.Second))
+	if crossings := b.Evaluate(context.Background(), start.Add(90*time.Second)); len(crossings) != 1 {
+		t.Errorf("Expected the re-armed rule to fire again, got %d crossings", len(crossings))
+	}
+}
+
+func TestBreadthAggregator_SharedAcrossWorkers(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	breadthRules := []BreadthRule{{RuleID: "rule-1", Threshold: 3, Window: time.Minute}}
+	worker1, worker2 := newBreadthAggregator(breadthRules), newBreadthAggregator(breadthRules)
+	worker1.redis, worker2.redis = redis, redis
+	ctx := context.Background()
+	start := time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC)
+
+	// Each worker scans its own partition; neither reaches the threshold alone
+	worker1.Record("rule-1", "AAPL", 5, start)
+	worker1.Record("rule-1", "MSFT", 7, start)
+	worker2.Record("rule-1", "TSLA", 9, start)
+	if crossings := worker1.Evaluate(ctx, start); len(crossings) != 0 {
+		t.Fatalf("Expected no crossing with 2 of 3 symbols shared, got %+v", crossings)
+	}
+
+	// The first worker to see the market-wide count cross emits the alert
+	crossings := worker2.Evaluate(ctx, start.Add(time.Second))
+	if len(crossings) != 1 {
+		t.Fatalf("Expected 1 crossing across workers, got %d", len(crossings))
+	}
+	if got := fmt.Sprint(crossings[0].symbols); got != "[AAPL MSFT TSLA]" {
+		t.Errorf("Expected every worker's symbols, got %s", got)
+	}
+	if crossings[0].values["AAPL"] != 5 || crossings[0].values["TSLA"] != 9 {
+		t.Errorf("Expected shared metric values, got %v", crossings[0].values)
+	}
+	if crossings := worker1.Evaluate(ctx, start.Add(2*time.Second)); len(crossings) != 0 {
+		t.Errorf("Expected only one worker to emit, got %+v", crossings)
+	}
+
+	// Once the matches expire the rule re-arms for every worker
+	if crossings := worker1.Evaluate(ctx, start.Add(2*time.Minute)); len(crossings) != 0 {
+		t.Errorf("Expected no crossing after the window, got %+v", crossings)
+	}
+	for _, symbol := range []string{"AAPL", "MSFT", "NVDA"} {
+		worker1.Record("rule-1", symbol, 1, start.Add(3*time.Minute))
+	}
+	if crossings := worker1.Evaluate(ctx, start.Add(3*time.Minute)); len(crossings) != 1 {
+		t.Errorf("Expected the re-armed rule to fire again, got %d crossings", len(crossings))
+	}
+}
+
+func TestBreadthAggregator_Disabled(t *testing.T) {
+	b := newBreadthAggregator(nil)
+	if b != nil {
+		t.Fatal("Expected nil aggregator when no breadth rules are configured")
+	}
+	b.Record("rule-1", "AAPL", 0, time.Now())
+	if crossings := b.Evaluate(context.Background(), time.Now()); crossings != nil {
+		t.Errorf("Disabled aggregator should never fire, got %+v", crossings)
+	}
+}
+
+func TestScanLoop_BreadthAlert(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	for _, rule := range []*models.Rule{
+		{
+			ID:         "rule-system",
+			Name:       "Price Above 100",
+			Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+			Enabled:    true,
+		},
+		{
+			ID:         "rule-user",
+			UserID:     "user-1",
+			Name:       "My Price Above 100",
+			Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+			Enabled:    true,
+		},
+	} {
+		if err := ruleStore.AddRule(rule); err != nil {
+			t.Fatalf("Failed to add rule: %v", err)
+		}
+	}
+
+	// User rules never produce breadth alerts, even when configured
+	config := DefaultScanLoopConfig()
+	config.BreadthRules = []BreadthRule{
+		{RuleID: "rule-system", Threshold: 5, Window: 5 * time.Minute},
+		{RuleID: "rule-user", Threshold: 1, Window: 5 * time.Minute},
+	}
+	sl := NewScanLoop(config, sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	breadthAlerts := func() []*models.Alert {
+		var alerts []*models.Alert
+		for _, alert := range emitter.alerts {
+			if alert.Type == models.AlertTypeBreadth {
+				alerts = append(alerts, alert)
+			}
+		}
+		return alerts
+	}
+
+	// One more symbol matches each cycle
+	for i := 1; i <= 20; i++ {
+		symbol := fmt.Sprintf("SYM%02d", i)
+		tick := &models.Tick{Symbol: symbol, Price: 150.0, Size: 100, Timestamp: time.Now(), Type: "trade"}
+		if err := sm.UpdateLiveBar(symbol, tick); err != nil {
+			t.Fatalf("Failed to update live bar: %v", err)
+		}
+		sl.Scan()
+
+		want := 0
+		if i >= 5 {
+			want = 1
+		}
+		if got := len(breadthAlerts()); got != want {
+			t.Fatalf("After %d matching symbols: expected %d breadth alerts, got %d", i, want, got)
+		}
+	}
+
+	alert := breadthAlerts()[0]
+	if alert.RuleID != "rule-system" || alert.Symbol != models.BreadthAlertSymbol {
+		t.Errorf("Unexpected breadth alert: %+v", alert)
+	}
+	if got := alert.Metadata["breadth_count"]; got != 5 {
+		t.Errorf("Expected breadth_count 5, got %v", got)
+	}
+	if got := alert.Metadata["breadth_threshold"]; got != 5 {
+		t.Errorf("Expected breadth_threshold 5, got %v", got)
+	}
+	if got := fmt.Sprint(alert.Metadata["breadth_symbols"]); got != "[SYM01 SYM02 SYM03 SYM04 SYM05]" {
+		t.Errorf("Expected the matching symbols, got %s", got)
+	}
+	if got := sl.GetStats().BreadthAlertsEmitted; got != 1 {
+		t.Errorf("Expected 1 breadth alert in stats, got %d", got)
+	}
+}
+
+func TestTopBreadthContributors(t *testing.T) {
+	crossing := breadthCrossing{
+		rule:    BreadthRule{RuleID: "rule-1", Threshold: 5, Window: time.Minute},
+		symbols: []string{"AAPL", "AMD", "META", "MSFT", "NVDA"},
+		values: map[string]float64{
+			"AAPL": 25,
+			"AMD":  12,
+			"META": 25,
+			"MSFT": math.NaN(), // Metric unavailable
+			"NVDA": 18,
+		},
+	}
+
+	oversold := &models.Rule{ID: "rule-1", Conditions: []models.Condition{{Metric: "rsi_14", Operator: "<", Value: 30.0}}}
+	if got := fmt.Sprint(topBreadthContributors(oversold, crossing, 3)); got != "[{AMD 12} {NVDA 18} {AAPL 25}]" {
+		t.Errorf("Expected lowest values first for '<' conditions, got %s", got)
+	}
+
+	overbought := &models.Rule{ID: "rule-1", Conditions: []models.Condition{{Metric: "rsi_14", Operator: ">", Value: 10.0}}}
+	if got := fmt.Sprint(topBreadthContributors(overbought, crossing, 10)); got != "[{AAPL 25} {META 25} {NVDA 18} {AMD 12}]" {
+		t.Errorf("Expected highest values first with ties by symbol, got %s", got)
+	}
+
+	if got := topBreadthContributors(overbought, crossing, 0); got != nil {
+		t.Errorf("Expected no contributors when disabled, got %v", got)
+	}
+	if got := topBreadthContributors(nil, crossing, 3); got != nil {
+		t.Errorf("Expected no contributors for a deleted rule, got %v", got)
+	}
+}
+
+func TestScanLoop_BreadthAlertListsTopSymbols(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	rule := &models.Rule{
+		ID:         "rule-system",
+		Name:       "Price Above 100",
+		Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+		Enabled:    true,
+	}
+	if err := ruleStore.AddRule(rule); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	config := DefaultScanLoopConfig()
+	config.BreadthRules = []BreadthRule{{RuleID: "rule-system", Threshold: 4, Window: 5 * time.Minute}}
+	config.BreadthTopSymbols = 3
+	sl := NewScanLoop(config, sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	prices := map[string]float64{"AAPL": 150, "MSFT": 410, "TSLA": 240, "AMD": 120, "F": 12}
+	for symbol, price := range prices {
+		tick := &models.Tick{Symbol: symbol, Price: price, Size: 100, Timestamp: time.Now(), Type: "trade"}
+		if err := sm.UpdateLiveBar(symbol, tick); err != nil {
+			t.Fatalf("Failed to update live bar: %v", err)
+		}
+	}
+	sl.Scan()
+
+	var breadthAlert *models.Alert
+	for _, alert := range emitter.alerts {
+		if alert.Type == models.AlertTypeBreadth {
+			breadthAlert = alert
+		}
+	}
+	if breadthAlert == nil {
+		t.Fatal("Expected a breadth alert")
+	}
+	if got := breadthAlert.Metadata["breadth_top_metric"]; got != "price" {
+		t.Errorf("Expected breadth_top_metric price, got %v", got)
+	}
+	if got := fmt.Sprint(breadthAlert.Metadata["breadth_top_symbols"]); got != "[{MSFT 410} {TSLA 240} {AAPL 150}]" {
+		t.Errorf("Expected top 3 symbols by price, got %s", got)
+	}
+	if got := fmt.Sprint(breadthAlert.Metadata["breadth_symbols"]); got != "[AAPL AMD MSFT TSLA]" {
+		t.Errorf("Expected all matching symbols, got %s", got)
+	}
+}
@@ -0,0 +1,55 @@
+package scanner
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock supplies the current time to time-dependent scanner logic (cooldowns,
+// staleness, alert timestamps). Production uses the wall clock; replays use a
+// SimulationClock driven by the timestamps of the data being replayed.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// Observe reports the timestamp of an event being processed
+	Observe(t time.Time)
+}
+
+// RealClock is the wall clock
+type RealClock struct{}
+
+// Now returns time.Now()
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// Observe is a no-op; the wall clock is not driven by events
+func (RealClock) Observe(time.Time) {}
+
+// SimulationClock is a clock driven by event timestamps for backtests and replays.
+// It only moves forward, so late or out-of-order events never rewind it.
+type SimulationClock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// NewSimulationClock creates a simulation clock starting at start (zero until the first event if unset)
+func NewSimulationClock(start time.Time) *SimulationClock {
+	return &SimulationClock{now: start}
+}
+
+// Now returns the latest observed event time
+func (c *SimulationClock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now
+}
+
+// Observe advances the clock to t if it is later than the current time
+func (c *SimulationClock) Observe(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t.After(c.now) {
+		c.now = t
+	}
+}
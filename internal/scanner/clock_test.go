@@ -0,0 +1,133 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+)
+
+func TestSimulationClock_OnlyMovesForward(t *testing.T) {
+	start := time.Date(2024, 3, 5, 14, 0, 0, 0, time.UTC)
+	clock := NewSimulationClock(start)
+
+	clock.Observe(start.Add(time.Minute))
+	if got := clock.Now(); !got.Equal(start.Add(time.Minute)) {
+		t.Errorf("Expected clock at %v, got %v", start.Add(time.Minute), got)
+	}
+
+	// Late events never rewind the clock
+	clock.Observe(start)
+	if got := clock.Now(); !got.Equal(start.Add(time.Minute)) {
+		t.Errorf("Expected clock to stay at %v, got %v", start.Add(time.Minute), got)
+	}
+}
+
+func TestCooldownTracker_SimulationClock(t *testing.T) {
+	start := time.Date(2024, 3, 5, 14, 0, 0, 0, time.UTC)
+	clock := NewSimulationClock(start)
+	ct := NewCooldownTracker(time.Minute)
+	ct.SetClock(clock)
+
+	ct.RecordCooldown("rule-1", "AAPL", 300)
+	if end := ct.GetCooldownEnd("rule-1", "AAPL"); !end.Equal(start.Add(5 * time.Minute)) {
+		t.Errorf("Expected cooldown to end at %v, got %v", start.Add(5*time.Minute), end)
+	}
+	if !ct.IsOnCooldown("rule-1", "AAPL") {
+		t.Error("Expected cooldown active at event time")
+	}
+
+	clock.Observe(start.Add(6 * time.Minute))
+	if ct.IsOnCooldown("rule-1", "AAPL") {
+		t.Error("Expected cooldown expired once event time passed its end")
+	}
+	ct.cleanupExpired()
+	if got := ct.GetCooldownCount(); got != 0 {
+		t.Errorf("Expected expired cooldown cleaned up, got %d", got)
+	}
+}
+
+func TestScanLoop_ReplayUsesEventTime(t *testing.T) {
+	// Tuesday 09:00 ET (pre-market), years before the wall clock
+	start := time.Date(2024, 3, 5, 14, 0, 0, 0, time.UTC)
+	clock := NewSimulationClock(time.Time{})
+
+	sm := NewStateManager(10)
+	sm.SetClock(clock)
+	cooldown := NewCooldownTracker(time.Minute)
+	cooldown.SetClock(clock)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	rule := &models.Rule{
+		ID:   "rule-price",
+		Name: "Price Above 100",
+		Conditions: []models.Condition{
+			{Metric: "price", Operator: ">", Value: 100.0},
+		},
+		Cooldown: 300,
+		Enabled:  true,
+	}
+	if err := ruleStore.AddRule(rule); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	config := DefaultScanLoopConfig()
+	config.MaxDataStaleness = 30 * time.Second
+	sl := NewScanLoop(config, sm, ruleStore, rules.NewCompiler(nil), cooldown, emitter, nil)
+	sl.SetClock(clock)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	replay := func(symbol string, at time.Time) {
+		t.Helper()
+		tick := &models.Tick{Symbol: symbol, Price: 150.0, Size: 100, Timestamp: at, Type: "trade"}
+		if err := sm.UpdateLiveBar(symbol, tick); err != nil {
+			t.Fatalf("Failed to update live bar: %v", err)
+		}
+		sl.Scan()
+	}
+
+	// Historical data is not stale when time follows the replay
+	replay("AAPL", start)
+	if len(emitter.alerts) != 1 {
+		t.Fatalf("Expected 1 alert, got %d", len(emitter.alerts))
+	}
+	if got := emitter.alerts[0].Timestamp; !got.Equal(start) {
+		t.Errorf("Expected alert timestamp %v, got %v", start, got)
+	}
+	if session := sm.GetState("AAPL").CurrentSession; session != SessionPreMarket {
+		t.Errorf("Expected premarket session, got %s", session)
+	}
+
+	// Two minutes of event time later the 5 minute cooldown is still active
+	replay("AAPL", start.Add(2*time.Minute))
+	if len(emitter.alerts) != 1 {
+		t.Fatalf("Expected cooldown to suppress the alert, got %d alerts", len(emitter.alerts))
+	}
+
+	// After the cooldown has elapsed in event time the rule fires again
+	replay("AAPL", start.Add(6*time.Minute))
+	if len(emitter.alerts) != 2 {
+		t.Fatalf("Expected alert after cooldown expired, got %d alerts", len(emitter.alerts))
+	}
+	if got := emitter.alerts[1].Timestamp; !got.Equal(start.Add(6 * time.Minute)) {
+		t.Errorf("Expected alert timestamp %v, got %v", start.Add(6*time.Minute), got)
+	}
+
+	// MSFT's last update falls behind the replay, so it is skipped as stale
+	replay("MSFT", start.Add(6*time.Minute))
+	skipped := sl.GetStats().SymbolsSkippedStale
+	replay("AAPL", start.Add(7*time.Minute))
+	if got := sl.GetStats().SymbolsSkippedStale; got != skipped+1 {
+		t.Errorf("Expected MSFT skipped as stale in event time, got %d skips (was %d)", got, skipped)
+	}
+
+	// Session follows the replayed timestamps: 10:00 ET is the regular session
+	replay("AAPL", start.Add(time.Hour))
+	if session := sm.GetState("AAPL").CurrentSession; session != SessionMarket {
+		t.Errorf("Expected market session, got %s", session)
+	}
+}
@@ -0,0 +1,67 @@
+package scanner
+
+import (
+	"sync"
+)
+
+// alertCoalescer suppresses re-emitting an alert for the same (rule, symbol) within a
+// number of scan cycles of the previous one. It smooths rapid re-matches across adjacent
+// cycles independently of the time-based cooldown.
+type alertCoalescer struct {
+	cycles      int64
+	mu          sync.Mutex
+	lastEmitted map[string]int64 // "ruleID|symbol" -> scan cycle of the last emitted alert
+}
+
+// newAlertCoalescer creates a coalescer for the given number of cycles (nil = disabled)
+func newAlertCoalescer(cycles int) *alertCoalescer {
+	if cycles <= 0 {
+		return nil
+	}
+	return &alertCoalescer{
+		cycles:      int64(cycles),
+		lastEmitted: make(map[string]int64),
+	}
+}
+
+// Suppress returns true if an alert for the rule and symbol was emitted within the
+// coalescing window before cycle
+func (c *alertCoalescer) Suppress(ruleID, symbol string, cycle int64) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	last, ok := c.lastEmitted[ruleID+"|"+symbol]
+	return ok && cycle-last <= c.cycles
+}
+
+// Record records that an alert for the rule and symbol was emitted in cycle
+func (c *alertCoalescer) Record(ruleID, symbol string, cycle int64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastEmitted[ruleID+"|"+symbol] = cycle
+}
+
+// Prune drops entries whose window ended before cycle
+func (c *alertCoalescer) Prune(cycle int64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, last := range c.lastEmitted {
+		if cycle-last > c.cycles {
+			delete(c.lastEmitted, key)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+)
+
+func TestAlertCoalescer_Window(t *testing.T) {
+	c := newAlertCoalescer(2)
+	c.Record("rule-1", "AAPL", 10)
+
+	for cycle, want := range map[int64]bool{11: true, 12: true, 13: false} {
+		if got := c.Suppress("rule-1", "AAPL", cycle); got != want {
+			t.Errorf("Suppress() at cycle %d = %v, want %v", cycle, got, want)
+		}
+	}
+
+	// Other rules and symbols are independent
+	if c.Suppress("rule-2", "AAPL", 11) || c.Suppress("rule-1", "MSFT", 11) {
+		t.Error("Expected only the recorded (rule, symbol) to be suppressed")
+	}
+
+	c.Prune(13)
+	if len(c.lastEmitted) != 0 {
+		t.Errorf("Expected expired entries pruned, got %d", len(c.lastEmitted))
+	}
+}
+
+func TestAlertCoalescer_Disabled(t *testing.T) {
+	c := newAlertCoalescer(0)
+	if c != nil {
+		t.Fatal("Expected nil coalescer when disabled")
+	}
+	c.Record("rule-1", "AAPL", 1)
+	if c.Suppress("rule-1", "AAPL", 2) {
+		t.Error("Disabled coalescer should never suppress")
+	}
+}
+
+func TestScanLoop_CoalescesRematchesWithinCycles(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	rule := &models.Rule{
+		ID:   "rule-price",
+		Name: "Price Above 100",
+		Conditions: []models.Condition{
+			{Metric: "price", Operator: ">", Value: 100.0},
+		},
+		Enabled: true,
+	}
+	if err := ruleStore.AddRule(rule); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	// No cooldown tracker: only coalescing suppresses re-matches
+	config := DefaultScanLoopConfig()
+	config.CoalesceCycles = 2
+	sl := NewScanLoop(config, sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	tick := &models.Tick{Symbol: "AAPL", Price: 150.0, Size: 100, Timestamp: time.Now(), Type: "trade"}
+	if err := sm.UpdateLiveBar("AAPL", tick); err != nil {
+		t.Fatalf("Failed to update live bar: %v", err)
+	}
+
+	// The rule matches every cycle; alerts are emitted in cycles 1 and 4
+	want := []int{1, 1, 1, 2, 2, 2, 3}
+	for i, expected := range want {
+		sl.Scan()
+		if got := len(emitter.alerts); got != expected {
+			t.Fatalf("After cycle %d: expected %d alerts, got %d", i+1, expected, got)
+		}
+	}
+
+	if got := sl.GetStats().AlertsCoalesced; got != 4 {
+		t.Errorf("Expected 4 coalesced alerts, got %d", got)
+	}
+}
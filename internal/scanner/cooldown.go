@@ -13,16 +13,17 @@ import (
 type InMemoryCooldownTracker struct {
 	mu              sync.RWMutex
 	cooldowns       map[string]time.Time // Key: "ruleID|symbol", Value: cooldown end time
-	globalCooldown  time.Duration        // Global cooldown duration for all rules
 	cleanupInterval time.Duration
+	clock           Clock // Time source for cooldown expiry (event time in replays)
 	ctx             context.Context
 	cancel          context.CancelFunc
 	wg              sync.WaitGroup
 	running         bool
 }
 
-// NewCooldownTracker creates a new in-memory cooldown tracker with global cooldown
-func NewCooldownTracker(globalCooldown, cleanupInterval time.Duration) *InMemoryCooldownTracker {
+// NewCooldownTracker creates a new in-memory cooldown tracker. Cooldown durations are
+// per rule, passed to RecordCooldown when the rule fires.
+func NewCooldownTracker(cleanupInterval time.Duration) *InMemoryCooldownTracker {
 	if cleanupInterval <= 0 {
 		cleanupInterval = 5 * time.Minute // Default: cleanup every 5 minutes
 	}
@@ -31,13 +32,24 @@ func NewCooldownTracker(globalCooldown, cleanupInterval time.Duration) *InMemory
 
 	return &InMemoryCooldownTracker{
 		cooldowns:       make(map[string]time.Time),
-		globalCooldown:  globalCooldown,
 		cleanupInterval: cleanupInterval,
+		clock:           RealClock{},
 		ctx:             ctx,
 		cancel:          cancel,
 	}
 }
 
+// SetClock sets the time source used for cooldown start and expiry.
+// Must be called before cooldowns are recorded.
+func (ct *InMemoryCooldownTracker) SetClock(clock Clock) {
+	if clock == nil {
+		clock = RealClock{}
+	}
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.clock = clock
+}
+
 // Start starts the cooldown tracker (starts cleanup goroutine)
 func (ct *InMemoryCooldownTracker) Start() error {
 	ct.mu.Lock()
@@ -87,31 +99,23 @@ func (ct *InMemoryCooldownTracker) IsOnCooldown(ruleID, symbol string) bool {
 	}
 
 	// Check if cooldown has expired
-	return time.Now().Before(cooldownEnd)
+	return ct.clock.Now().Before(cooldownEnd)
 }
 
-// RecordCooldown records that a rule fired for a symbol (starts cooldown using global cooldown)
+// RecordCooldown records that a rule fired for a symbol, starting a cooldown of cooldownSeconds
+// (the rule's cooldown). A cooldown of 0 records nothing, so the rule can fire again next scan.
+// The duration is taken at each fire, so a changed rule cooldown applies from its next alert.
 func (ct *InMemoryCooldownTracker) RecordCooldown(ruleID, symbol string, cooldownSeconds int) {
-	if ruleID == "" || symbol == "" {
+	if ruleID == "" || symbol == "" || cooldownSeconds <= 0 {
 		return
 	}
 
-	// Use global cooldown instead of per-rule cooldown
-	ct.mu.RLock()
-	cooldownDuration := ct.globalCooldown
-	ct.mu.RUnlock()
-
-	if cooldownDuration <= 0 {
-		return // No cooldown configured
-	}
-
 	key := ruleID + "|" + symbol
-	cooldownEnd := time.Now().Add(cooldownDuration)
 
 	ct.mu.Lock()
 	defer ct.mu.Unlock()
 
-	ct.cooldowns[key] = cooldownEnd
+	ct.cooldowns[key] = ct.clock.Now().Add(time.Duration(cooldownSeconds) * time.Second)
 }
 
 // GetCooldownEnd returns when the cooldown ends for a rule-symbol pair
@@ -181,7 +185,7 @@ func (ct *InMemoryCooldownTracker) cleanupExpired() {
 	ct.mu.Lock()
 	defer ct.mu.Unlock()
 
-	now := time.Now()
+	now := ct.clock.Now()
 	expired := make([]string, 0)
 
 	for key, cooldownEnd := range ct.cooldowns {
@@ -6,36 +6,32 @@ import (
 )
 
 func TestNewCooldownTracker(t *testing.T) {
-	ct := NewCooldownTracker(10*time.Second, 5*time.Minute)
+	ct := NewCooldownTracker(5 * time.Minute)
 	if ct == nil {
 		t.Fatal("Expected cooldown tracker to be created")
 	}
 
-	if ct.globalCooldown != 10*time.Second {
-		t.Errorf("Expected global cooldown 10s, got %v", ct.globalCooldown)
-	}
-
 	if ct.cleanupInterval != 5*time.Minute {
 		t.Errorf("Expected cleanup interval 5m, got %v", ct.cleanupInterval)
 	}
 
 	// Test default cleanup interval
-	ct2 := NewCooldownTracker(10*time.Second, 0)
+	ct2 := NewCooldownTracker(0)
 	if ct2.cleanupInterval != 5*time.Minute {
 		t.Errorf("Expected default cleanup interval 5m, got %v", ct2.cleanupInterval)
 	}
 }
 
 func TestCooldownTracker_IsOnCooldown(t *testing.T) {
-	ct := NewCooldownTracker(10*time.Second, 5*time.Minute)
+	ct := NewCooldownTracker(5 * time.Minute)
 
 	// Not on cooldown initially
 	if ct.IsOnCooldown("rule-1", "AAPL") {
 		t.Error("Expected not to be on cooldown initially")
 	}
 
-	// Record cooldown (cooldownSeconds parameter is ignored, uses global cooldown)
-	ct.RecordCooldown("rule-1", "AAPL", 0)
+	// Record cooldown
+	ct.RecordCooldown("rule-1", "AAPL", 10)
 
 	// Should be on cooldown
 	if !ct.IsOnCooldown("rule-1", "AAPL") {
@@ -53,10 +49,10 @@ func TestCooldownTracker_IsOnCooldown(t *testing.T) {
 }
 
 func TestCooldownTracker_RecordCooldown(t *testing.T) {
-	ct := NewCooldownTracker(10*time.Second, 5*time.Minute)
+	ct := NewCooldownTracker(5 * time.Minute)
 
-	// Record cooldown (uses global cooldown, parameter ignored)
-	ct.RecordCooldown("rule-1", "AAPL", 0)
+	// Record a 10 second cooldown
+	ct.RecordCooldown("rule-1", "AAPL", 10)
 
 	// Verify cooldown end time
 	cooldownEnd := ct.GetCooldownEnd("rule-1", "AAPL")
@@ -70,7 +66,7 @@ func TestCooldownTracker_RecordCooldown(t *testing.T) {
 		t.Error("Expected cooldown end time to be in the future")
 	}
 
-	// Cooldown should be approximately 10 seconds from now (global cooldown)
+	// Cooldown should be approximately 10 seconds from now
 	expectedEnd := now.Add(10 * time.Second)
 	diff := cooldownEnd.Sub(expectedEnd)
 	if diff < -1*time.Second || diff > 1*time.Second {
@@ -79,7 +75,7 @@ func TestCooldownTracker_RecordCooldown(t *testing.T) {
 }
 
 func TestCooldownTracker_CooldownExpires(t *testing.T) {
-	ct := NewCooldownTracker(10*time.Second, 5*time.Minute)
+	ct := NewCooldownTracker(5 * time.Minute)
 
 	// Record a very short cooldown
 	ct.RecordCooldown("rule-1", "AAPL", 1) // 1 second
@@ -99,10 +95,10 @@ func TestCooldownTracker_CooldownExpires(t *testing.T) {
 }
 
 func TestCooldownTracker_ClearCooldown(t *testing.T) {
-	ct := NewCooldownTracker(10*time.Second, 5*time.Minute)
+	ct := NewCooldownTracker(5 * time.Minute)
 
 	// Record cooldown
-	ct.RecordCooldown("rule-1", "AAPL", 0)
+	ct.RecordCooldown("rule-1", "AAPL", 10)
 
 	// Verify on cooldown
 	if !ct.IsOnCooldown("rule-1", "AAPL") {
@@ -119,10 +115,10 @@ func TestCooldownTracker_ClearCooldown(t *testing.T) {
 }
 
 func TestCooldownTracker_ClearAllCooldowns(t *testing.T) {
-	ct := NewCooldownTracker(10*time.Second, 5*time.Minute)
+	ct := NewCooldownTracker(5 * time.Minute)
 
 	// Record multiple cooldowns
-	ct.RecordCooldown("rule-1", "AAPL", 0)
+	ct.RecordCooldown("rule-1", "AAPL", 10)
 	ct.RecordCooldown("rule-1", "GOOGL", 10)
 	ct.RecordCooldown("rule-2", "AAPL", 10)
 
@@ -152,14 +148,14 @@ func TestCooldownTracker_ClearAllCooldowns(t *testing.T) {
 }
 
 func TestCooldownTracker_GetCooldownCount(t *testing.T) {
-	ct := NewCooldownTracker(10*time.Second, 5*time.Minute)
+	ct := NewCooldownTracker(5 * time.Minute)
 
 	if ct.GetCooldownCount() != 0 {
 		t.Errorf("Expected 0 cooldowns initially, got %d", ct.GetCooldownCount())
 	}
 
 	// Add cooldowns
-	ct.RecordCooldown("rule-1", "AAPL", 0)
+	ct.RecordCooldown("rule-1", "AAPL", 10)
 	ct.RecordCooldown("rule-1", "GOOGL", 10)
 
 	if ct.GetCooldownCount() != 2 {
@@ -175,12 +171,12 @@ func TestCooldownTracker_GetCooldownCount(t *testing.T) {
 }
 
 func TestCooldownTracker_InvalidInputs(t *testing.T) {
-	ct := NewCooldownTracker(10*time.Second, 5*time.Minute)
+	ct := NewCooldownTracker(5 * time.Minute)
 
-	// Empty rule ID or symbol should not record cooldown
+	// Empty rule ID or symbol, or no cooldown, should not record cooldown
 	ct.RecordCooldown("", "AAPL", 10)
 	ct.RecordCooldown("rule-1", "", 10)
-	ct.RecordCooldown("rule-1", "AAPL", 0) // Parameter ignored, uses global cooldown
+	ct.RecordCooldown("rule-1", "AAPL", 0) // 0 = no cooldown
 
 	if ct.GetCooldownCount() != 0 {
 		t.Errorf("Expected 0 cooldowns with invalid inputs, got %d", ct.GetCooldownCount())
@@ -197,7 +193,7 @@ func TestCooldownTracker_InvalidInputs(t *testing.T) {
 }
 
 func TestCooldownTracker_Concurrency(t *testing.T) {
-	ct := NewCooldownTracker(10*time.Second, 5*time.Minute)
+	ct := NewCooldownTracker(5 * time.Minute)
 
 	// Test concurrent writes and reads
 	done := make(chan bool)
@@ -239,7 +235,7 @@ func TestCooldownTracker_Concurrency(t *testing.T) {
 }
 
 func TestCooldownTracker_StartStop(t *testing.T) {
-	ct := NewCooldownTracker(10*time.Second, 100*time.Millisecond) // Short cleanup interval for testing
+	ct := NewCooldownTracker(100 * time.Millisecond) // Short cleanup interval for testing
 
 	// Start tracker
 	err := ct.Start()
@@ -266,7 +262,7 @@ func TestCooldownTracker_StartStop(t *testing.T) {
 }
 
 func TestCooldownTracker_CleanupExpired(t *testing.T) {
-	ct := NewCooldownTracker(10*time.Second, 100*time.Millisecond)
+	ct := NewCooldownTracker(100 * time.Millisecond)
 
 	// Start tracker
 	ct.Start()
@@ -302,10 +298,10 @@ func TestCooldownTracker_CleanupExpired(t *testing.T) {
 }
 
 func TestCooldownTracker_MultipleRulesSameSymbol(t *testing.T) {
-	ct := NewCooldownTracker(10*time.Second, 5*time.Minute)
+	ct := NewCooldownTracker(5 * time.Minute)
 
 	// Record cooldowns for different rules on same symbol
-	ct.RecordCooldown("rule-1", "AAPL", 0)
+	ct.RecordCooldown("rule-1", "AAPL", 10)
 	ct.RecordCooldown("rule-2", "AAPL", 20)
 	ct.RecordCooldown("rule-3", "AAPL", 30)
 
@@ -328,10 +324,10 @@ func TestCooldownTracker_MultipleRulesSameSymbol(t *testing.T) {
 }
 
 func TestCooldownTracker_SameRuleDifferentSymbols(t *testing.T) {
-	ct := NewCooldownTracker(10*time.Second, 5*time.Minute)
+	ct := NewCooldownTracker(5 * time.Minute)
 
 	// Record cooldowns for same rule on different symbols
-	ct.RecordCooldown("rule-1", "AAPL", 0)
+	ct.RecordCooldown("rule-1", "AAPL", 10)
 	ct.RecordCooldown("rule-1", "GOOGL", 20)
 	ct.RecordCooldown("rule-1", "MSFT", 30)
 
@@ -354,17 +350,17 @@ func TestCooldownTracker_SameRuleDifferentSymbols(t *testing.T) {
 }
 
 func TestCooldownTracker_OverwriteCooldown(t *testing.T) {
-	ct := NewCooldownTracker(10*time.Second, 5*time.Minute)
+	ct := NewCooldownTracker(5 * time.Minute)
 
 	// Record initial cooldown
-	ct.RecordCooldown("rule-1", "AAPL", 0)
+	ct.RecordCooldown("rule-1", "AAPL", 10)
 	firstEnd := ct.GetCooldownEnd("rule-1", "AAPL")
 
 	// Wait a bit
 	time.Sleep(100 * time.Millisecond)
 
 	// Record new cooldown (should overwrite)
-	ct.RecordCooldown("rule-1", "AAPL", 0)
+	ct.RecordCooldown("rule-1", "AAPL", 10)
 	secondEnd := ct.GetCooldownEnd("rule-1", "AAPL")
 
 	// Second end should be later than first
@@ -377,3 +373,39 @@ func TestCooldownTracker_OverwriteCooldown(t *testing.T) {
 		t.Errorf("Expected 1 cooldown after overwrite, got %d", ct.GetCooldownCount())
 	}
 }
+
+func TestCooldownTracker_PerRuleCooldowns(t *testing.T) {
+	start := time.Date(2024, 3, 5, 14, 0, 0, 0, time.UTC)
+	clock := NewSimulationClock(start)
+	ct := NewCooldownTracker(time.Minute)
+	ct.SetClock(clock)
+
+	// Two rules with different cooldowns on the same symbol
+	ct.RecordCooldown("rule-short", "AAPL", 60)
+	ct.RecordCooldown("rule-long", "AAPL", 300)
+
+	clock.Observe(start.Add(2 * time.Minute))
+	if ct.IsOnCooldown("rule-short", "AAPL") {
+		t.Error("Expected the 60s cooldown to have expired")
+	}
+	if !ct.IsOnCooldown("rule-long", "AAPL") {
+		t.Error("Expected the 300s cooldown to still be active")
+	}
+
+	clock.Observe(start.Add(6 * time.Minute))
+	if ct.IsOnCooldown("rule-long", "AAPL") {
+		t.Error("Expected the 300s cooldown to have expired")
+	}
+
+	// A changed cooldown applies from the rule's next fire
+	ct.RecordCooldown("rule-short", "AAPL", 600)
+	if end := ct.GetCooldownEnd("rule-short", "AAPL"); !end.Equal(start.Add(16 * time.Minute)) {
+		t.Errorf("Expected the new cooldown to end at %v, got %v", start.Add(16*time.Minute), end)
+	}
+
+	// A cooldown of 0 records nothing
+	ct.RecordCooldown("rule-none", "AAPL", 0)
+	if ct.IsOnCooldown("rule-none", "AAPL") {
+		t.Error("Expected no cooldown for a rule with cooldown 0")
+	}
+}
@@ -0,0 +1,90 @@
+package scanner
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+)
+
+// DefaultVirtualNodes is the number of ring points per worker. More points even out the
+// share of the ring each worker owns at the cost of a larger ring.
+const DefaultVirtualNodes = 150
+
+// hashRing is a consistent hash ring with virtual nodes: every worker owns many points on
+// the ring and a key belongs to the worker owning the first point at or after the key's
+// hash. Adding or removing a worker only moves the keys next to its points, about 1/N of them.
+type hashRing struct {
+	points []ringPoint // Sorted by hash
+}
+
+// ringPoint is one virtual node of a worker on the ring
+type ringPoint struct {
+	hash   uint64
+	worker int
+}
+
+// newHashRing creates a ring for workers with virtualNodes points each
+func newHashRing(workers, virtualNodes int) *hashRing {
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultVirtualNodes
+	}
+
+	points := make([]ringPoint, 0, workers*virtualNodes)
+	for worker := 0; worker < workers; worker++ {
+		for node := 0; node < virtualNodes; node++ {
+			points = append(points, ringPoint{
+				hash:   ringHash(fmt.Sprintf("worker-%d#%d", worker, node)),
+				worker: worker,
+			})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].hash != points[j].hash {
+			return points[i].hash < points[j].hash
+		}
+		return points[i].worker < points[j].worker
+	})
+
+	return &hashRing{points: points}
+}
+
+// owner returns the worker owning key
+func (r *hashRing) owner(key string) int {
+	return r.points[r.search(ringHash(key))].worker
+}
+
+// successors calls visit with the workers owning the points at and after key's hash, in
+// ring order, until visit returns false or every point was visited
+func (r *hashRing) successors(key string, visit func(worker int) bool) {
+	start := r.search(ringHash(key))
+	for i := 0; i < len(r.points); i++ {
+		if !visit(r.points[(start+i)%len(r.points)].worker) {
+			return
+		}
+	}
+}
+
+// search returns the index of the first point at or after hash, wrapping around the ring
+func (r *hashRing) search(hash uint64) int {
+	i := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i].hash >= hash
+	})
+	if i == len(r.points) {
+		return 0
+	}
+	return i
+}
+
+// ringHash hashes a key onto the ring. FNV alone clusters similar short keys (worker-1#1,
+// worker-1#2, ...), so its output goes through a 64-bit finalizer to spread the points.
+func ringHash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
 	"sync"
 	"time"
 
@@ -40,6 +42,7 @@ type IndicatorConsumer struct {
 	mu           sync.RWMutex
 	running      bool
 	stats        IndicatorConsumerStats
+	filter       SymbolFilter // Symbols whose indicators are applied (nil = all)
 }
 
 // IndicatorConsumerStats holds statistics about the indicator consumer
@@ -47,6 +50,7 @@ type IndicatorConsumerStats struct {
 	UpdatesReceived  int64
 	UpdatesProcessed int64
 	UpdatesFailed    int64
+	UpdatesSkipped   int64 // Updates for symbols owned by other workers
 	LastUpdateTime   time.Time
 	mu               sync.RWMutex
 }
@@ -116,6 +120,11 @@ func (ic *IndicatorConsumer) IsRunning() bool {
 	return ic.running
 }
 
+// SetSymbolFilter sets which symbols' indicator updates are applied; the rest are ignored (call before Start)
+func (ic *IndicatorConsumer) SetSymbolFilter(filter SymbolFilter) {
+	ic.filter = filter
+}
+
 // GetStats returns current consumer statistics
 func (ic *IndicatorConsumer) GetStats() IndicatorConsumerStats {
 	ic.stats.mu.RLock()
@@ -126,6 +135,7 @@ func (ic *IndicatorConsumer) GetStats() IndicatorConsumerStats {
 		UpdatesReceived:  ic.stats.UpdatesReceived,
 		UpdatesProcessed: ic.stats.UpdatesProcessed,
 		UpdatesFailed:    ic.stats.UpdatesFailed,
+		UpdatesSkipped:   ic.stats.UpdatesSkipped,
 		LastUpdateTime:   ic.stats.LastUpdateTime,
 	}
 }
@@ -205,20 +215,13 @@ func (ic *IndicatorConsumer) consumeUpdates() {
 				continue
 			}
 
-			// Fetch full indicator data from Redis
-			indicators, err := ic.fetchIndicators(symbol)
-			if err != nil {
-				logger.Error("Failed to fetch indicators",
-					logger.ErrorField(err),
-					logger.String("symbol", symbol),
-				)
-				ic.incrementFailed()
+			if ic.filter != nil && !ic.filter(symbol) {
+				ic.incrementSkipped()
 				continue
 			}
 
-			// Update state manager
-			if err := ic.stateManager.UpdateIndicators(symbol, indicators); err != nil {
-				logger.Error("Failed to update indicators in state manager",
+			if err := ic.applyUpdate(symbol); err != nil {
+				logger.Error("Failed to apply indicator update",
 					logger.ErrorField(err),
 					logger.String("symbol", symbol),
 				)
@@ -227,14 +230,32 @@ func (ic *IndicatorConsumer) consumeUpdates() {
 			}
 
 			ic.incrementProcessed()
-			logger.Debug("Updated indicators",
-				logger.String("symbol", symbol),
-				logger.Int("indicator_count", len(indicators)),
-			)
 		}
 	}
 }
 
+// applyUpdate fetches a symbol's published indicators and merges them into its state.
+// Payloads may carry only a subset of indicators (e.g. while some are still warming up);
+// indicators missing from the payload keep their previous values.
+func (ic *IndicatorConsumer) applyUpdate(symbol string) error {
+	// Fetch full indicator data from Redis
+	indicators, err := ic.fetchIndicators(symbol)
+	if err != nil {
+		return fmt.Errorf("failed to fetch indicators: %w", err)
+	}
+
+	// Update state manager (merges into existing indicators)
+	if err := ic.stateManager.UpdateIndicators(symbol, indicators); err != nil {
+		return fmt.Errorf("failed to update indicators in state manager: %w", err)
+	}
+
+	logger.Debug("Updated indicators",
+		logger.String("symbol", symbol),
+		logger.Int("indicator_count", len(indicators)),
+	)
+	return nil
+}
+
 // fetchIndicators fetches indicator values from Redis for a symbol
 func (ic *IndicatorConsumer) fetchIndicators(symbol string) (map[string]float64, error) {
 	key := fmt.Sprintf("%s%s", ic.config.IndicatorKeyPrefix, symbol)
@@ -259,30 +280,57 @@ func (ic *IndicatorConsumer) fetchIndicators(symbol string) (map[string]float64,
 		return nil, fmt.Errorf("indicator 'values' field is not a map")
 	}
 
-	// Convert to map[string]float64
-	indicators := make(map[string]float64)
+	// Convert to map[string]float64, leaving out values that aren't set (e.g. null while
+	// warming up) so they don't overwrite previously-set values when merged
+	indicators := make(map[string]float64, len(valuesMap))
 	for key, value := range valuesMap {
-		switch v := value.(type) {
-		case float64:
-			indicators[key] = v
-		case int:
-			indicators[key] = float64(v)
-		case int64:
-			indicators[key] = float64(v)
-		default:
-			// Try to convert via JSON unmarshaling
-			if jsonBytes, err := json.Marshal(v); err == nil {
-				var floatVal float64
-				if err := json.Unmarshal(jsonBytes, &floatVal); err == nil {
-					indicators[key] = floatVal
-				}
-			}
+		floatVal, ok := parseIndicatorValue(value)
+		if !ok {
+			logger.Debug("Skipping unset indicator value",
+				logger.String("symbol", symbol),
+				logger.String("indicator", key),
+			)
+			continue
 		}
+		indicators[key] = floatVal
 	}
 
 	return indicators, nil
 }
 
+// parseIndicatorValue converts a decoded JSON indicator value to float64.
+// Returns false for null, non-numeric and non-finite values.
+func parseIndicatorValue(value interface{}) (float64, bool) {
+	var f float64
+	switch v := value.(type) {
+	case float64:
+		f = v
+	case int:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case json.Number:
+		parsed, err := v.Float64()
+		if err != nil {
+			return 0, false
+		}
+		f = parsed
+	case string:
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, false
+		}
+		f = parsed
+	default:
+		return 0, false
+	}
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
 // incrementReceived increments the received update counter
 func (ic *IndicatorConsumer) incrementReceived() {
 	ic.stats.mu.Lock()
@@ -304,3 +352,10 @@ func (ic *IndicatorConsumer) incrementFailed() {
 	defer ic.stats.mu.Unlock()
 	ic.stats.UpdatesFailed++
 }
+
+// incrementSkipped increments the skipped updates counter
+func (ic *IndicatorConsumer) incrementSkipped() {
+	ic.stats.mu.Lock()
+	defer ic.stats.mu.Unlock()
+	ic.stats.UpdatesSkipped++
+}
@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -164,6 +165,71 @@ func TestIndicatorConsumer_UpdateIndicators(t *testing.T) {
 	}
 }
 
+func TestIndicatorConsumer_PartialUpdatePreservesIndicators(t *testing.T) {
+	sm := NewStateManager(10)
+	redis := storage.NewMockRedisClient()
+	ic := NewIndicatorConsumer(redis, DefaultIndicatorConsumerConfig(), sm)
+	ctx := context.Background()
+
+	publish := func(values map[string]interface{}) {
+		t.Helper()
+		indicatorData := map[string]interface{}{
+			"symbol":    "AAPL",
+			"timestamp": time.Now().UTC(),
+			"values":    values,
+		}
+		if err := redis.Set(ctx, "ind:AAPL", indicatorData, 10*time.Minute); err != nil {
+			t.Fatalf("Failed to set indicator data: %v", err)
+		}
+		if err := ic.applyUpdate("AAPL"); err != nil {
+			t.Fatalf("applyUpdate() error = %v", err)
+		}
+	}
+
+	publish(map[string]interface{}{"rsi_14": 65.5, "ema_20": 150.2, "vwap": 149.8})
+
+	// Only a subset is published; rsi_14 is still warming up and published as null
+	publish(map[string]interface{}{"ema_20": 151.0, "rsi_14": nil})
+
+	state := sm.GetState("AAPL")
+	state.mu.RLock()
+	defer state.mu.RUnlock()
+
+	want := map[string]float64{"rsi_14": 65.5, "ema_20": 151.0, "vwap": 149.8}
+	if len(state.Indicators) != len(want) {
+		t.Errorf("Expected %d indicators in state, got %v", len(want), state.Indicators)
+	}
+	for name, value := range want {
+		if got, ok := state.Indicators[name]; !ok || got != value {
+			t.Errorf("Expected %s = %v after partial update, got %v (present=%v)", name, value, got, ok)
+		}
+	}
+}
+
+func TestParseIndicatorValue(t *testing.T) {
+	tests := []struct {
+		value  interface{}
+		want   float64
+		wantOK bool
+	}{
+		{65.5, 65.5, true},
+		{int64(3), 3, true},
+		{json.Number("1.25"), 1.25, true},
+		{"42.5", 42.5, true},
+		{nil, 0, false},
+		{"warming_up", 0, false},
+		{"NaN", 0, false},
+		{map[string]interface{}{}, 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseIndicatorValue(tt.value)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("parseIndicatorValue(%v) = (%v, %v), want (%v, %v)", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
 func TestDefaultIndicatorConsumerConfig(t *testing.T) {
 	config := DefaultIndicatorConsumerConfig()
 
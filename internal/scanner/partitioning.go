@@ -4,15 +4,133 @@ import (
 	"crypto/sha256"
 	"fmt"
 	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 )
 
+// PartitionKeyFunc maps a symbol to the key that is hashed to pick its partition.
+// Symbols with the same key always land on the same worker.
+type PartitionKeyFunc func(symbol string) string
+
+// Partition key modes
+const (
+	PartitionBySymbol = "symbol" // Each symbol is hashed independently (default)
+	PartitionByGroup  = "group"  // Symbols in the same group (sector or custom grouping) share a worker
+)
+
+// SymbolPartitionKey partitions by the symbol itself
+func SymbolPartitionKey(symbol string) string {
+	return symbol
+}
+
+// GroupPartitionKey partitions by group (e.g. sector) so related symbols share a worker,
+// for pairs and relative-strength rules. Symbols without a group fall back to their own key.
+func GroupPartitionKey(groups map[string]string) PartitionKeyFunc {
+	return func(symbol string) string {
+		if group, ok := groups[symbol]; ok {
+			return "group:" + group
+		}
+		return symbol
+	}
+}
+
+// ParseSymbolGroups parses symbol groups in the format "GROUP:SYM|SYM,..."
+// (e.g. "Technology:AAPL|MSFT|NVDA,Energy:XOM|CVX") into a symbol -> group map
+func ParseSymbolGroups(spec string) (map[string]string, error) {
+	groups := make(map[string]string)
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return groups, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		group, symbols, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		group = strings.TrimSpace(group)
+		if !ok || group == "" || strings.TrimSpace(symbols) == "" {
+			return nil, fmt.Errorf("invalid symbol group %q (expected GROUP:SYM|SYM)", entry)
+		}
+		for _, symbol := range strings.Split(symbols, "|") {
+			symbol = strings.ToUpper(strings.TrimSpace(symbol))
+			if symbol == "" {
+				return nil, fmt.Errorf("invalid symbol group %q (empty symbol)", entry)
+			}
+			if existing, dup := groups[symbol]; dup && existing != group {
+				return nil, fmt.Errorf("symbol %s is in groups %s and %s", symbol, existing, group)
+			}
+			groups[symbol] = group
+		}
+	}
+
+	return groups, nil
+}
+
+// NewPartitionKeyFunc returns the partition key function for a mode
+func NewPartitionKeyFunc(mode string, groups map[string]string) (PartitionKeyFunc, error) {
+	switch mode {
+	case "", PartitionBySymbol:
+		return SymbolPartitionKey, nil
+	case PartitionByGroup:
+		if len(groups) == 0 {
+			return nil, fmt.Errorf("partition by %s requires symbol groups", PartitionByGroup)
+		}
+		return GroupPartitionKey(groups), nil
+	default:
+		return nil, fmt.Errorf("invalid partition key mode %q (must be %q or %q)", mode, PartitionBySymbol, PartitionByGroup)
+	}
+}
+
+// Partition strategies
+const (
+	PartitionModulo     = "modulo"     // hash(key) % workers (default); a worker count change moves most symbols
+	PartitionConsistent = "consistent" // Consistent hashing with virtual nodes; a worker count change moves ~1/N of symbols
+)
+
+// weightedLoadSlack is how far above the average weighted load a worker may go before
+// weighted assignment moves a symbol on to the next worker
+const weightedLoadSlack = 0.25
+
+// ParseSymbolWeights parses per-symbol load weights in the format "SYM:WEIGHT,..."
+// (e.g. "AAPL:5,TSLA:3"). Symbols without a weight count as 1.
+func ParseSymbolWeights(spec string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return weights, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		symbol, value, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if !ok || symbol == "" {
+			return nil, fmt.Errorf("invalid symbol weight %q (expected SYM:WEIGHT)", entry)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || weight <= 0 || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("invalid symbol weight %q (weight must be a positive number)", entry)
+		}
+		weights[symbol] = weight
+	}
+
+	return weights, nil
+}
+
 // PartitionManager manages symbol partitioning across multiple workers
 type PartitionManager struct {
-	workerID      int
-	totalWorkers  int
-	mu            sync.RWMutex
+	workerID        int
+	totalWorkers    int
+	keyFunc         PartitionKeyFunc // Maps symbols to the hashed partition key (default: the symbol)
+	mu              sync.RWMutex
 	assignedSymbols map[string]bool // Symbols assigned to this worker
+
+	strategy     string             // PartitionModulo (default) or PartitionConsistent
+	virtualNodes int                // Ring points per worker with consistent hashing
+	ring         *hashRing          // Consistent hash ring (nil with modulo partitioning)
+	weights      map[string]float64 // Per-symbol load weights (empty = every symbol counts as 1)
+	universe     []string           // Symbols of the last Repartition, used to balance weights
+	owners       map[string]int     // Weighted assignment of the universe: partition key -> worker
 }
 
 // NewPartitionManager creates a new partition manager
@@ -30,20 +148,99 @@ func NewPartitionManager(workerID, totalWorkers int) (*PartitionManager, error)
 	return &PartitionManager{
 		workerID:        workerID,
 		totalWorkers:    totalWorkers,
+		keyFunc:         SymbolPartitionKey,
 		assignedSymbols: make(map[string]bool),
+		strategy:        PartitionModulo,
 	}, nil
 }
 
+// SetPartitionStrategy sets how partition keys map to workers (call before assigning symbols).
+// virtualNodes is the number of ring points per worker with consistent hashing (0 = default).
+func (pm *PartitionManager) SetPartitionStrategy(strategy string, virtualNodes int) error {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	switch strategy {
+	case "", PartitionModulo:
+		pm.strategy = PartitionModulo
+		pm.ring = nil
+	case PartitionConsistent:
+		if virtualNodes <= 0 {
+			virtualNodes = DefaultVirtualNodes
+		}
+		pm.strategy = PartitionConsistent
+		pm.virtualNodes = virtualNodes
+		pm.ring = newHashRing(pm.totalWorkers, virtualNodes)
+	default:
+		return fmt.Errorf("invalid partition strategy %q (must be %q or %q)", strategy, PartitionModulo, PartitionConsistent)
+	}
+
+	pm.computeOwnersLocked()
+	return nil
+}
+
+// GetPartitionStrategy returns the partition strategy
+func (pm *PartitionManager) GetPartitionStrategy() string {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.strategy
+}
+
+// SetSymbolWeights sets per-symbol load weights (e.g. high-volume names count more). Once the
+// universe is known through Repartition, symbols are spread so each worker's weighted load
+// stays near the average: a symbol whose hashed worker is full moves on to the next worker
+// in the hash order. All workers must use the same weights and universe. Weighted
+// assignment trades remap stability for balance: see computeOwnersLocked.
+func (pm *PartitionManager) SetSymbolWeights(weights map[string]float64) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.weights = make(map[string]float64, len(weights))
+	for symbol, weight := range weights {
+		if weight > 0 {
+			pm.weights[symbol] = weight
+		}
+	}
+	pm.computeOwnersLocked()
+}
+
+// SetPartitionKeyFunc sets how symbols map to partition keys (call before assigning symbols)
+func (pm *PartitionManager) SetPartitionKeyFunc(keyFunc PartitionKeyFunc) {
+	if keyFunc == nil {
+		keyFunc = SymbolPartitionKey
+	}
+	pm.keyFunc = keyFunc
+}
+
 // GetPartition calculates which partition (worker) a symbol belongs to
-// Uses consistent hashing: hash(symbol) % totalWorkers
+// Uses hash(partition key) % totalWorkers, or the hash ring with consistent hashing
 func (pm *PartitionManager) GetPartition(symbol string) int {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.partitionLocked(symbol)
+}
+
+// partitionLocked returns a symbol's partition; the caller holds pm.mu
+func (pm *PartitionManager) partitionLocked(symbol string) int {
 	if symbol == "" {
 		return 0
 	}
 
+	key := pm.keyFunc(symbol)
+	if owner, ok := pm.owners[key]; ok {
+		return owner
+	}
+	if pm.ring != nil {
+		return pm.ring.owner(key)
+	}
+	return pm.modPartition(key)
+}
+
+// modPartition returns hash(key) % totalWorkers
+func (pm *PartitionManager) modPartition(key string) int {
 	// Use FNV hash for fast, consistent hashing
 	h := fnv.New32a()
-	h.Write([]byte(symbol))
+	h.Write([]byte(key))
 	hash := h.Sum32()
 
 	partition := int(hash) % pm.totalWorkers
@@ -64,6 +261,22 @@ func (pm *PartitionManager) IsOwned(symbol string) bool {
 	return partition == pm.workerID
 }
 
+// SymbolFilter reports whether a worker processes a symbol's ticks, bars and indicators
+type SymbolFilter func(symbol string) bool
+
+// OwnershipFilter returns a filter accepting the symbols this worker owns and the always
+// symbols (e.g. reference symbols every worker needs for cross-symbol metrics). Every worker
+// reads every stream entry, so the filter is what splits the universe between workers.
+func (pm *PartitionManager) OwnershipFilter(always []string) SymbolFilter {
+	keep := make(map[string]bool, len(always))
+	for _, symbol := range always {
+		keep[symbol] = true
+	}
+	return func(symbol string) bool {
+		return keep[symbol] || pm.IsOwned(symbol)
+	}
+}
+
 // GetWorkerID returns this worker's ID
 func (pm *PartitionManager) GetWorkerID() int {
 	pm.mu.RLock()
@@ -147,30 +360,183 @@ func (pm *PartitionManager) ClearAssignedSymbols() {
 // UpdateWorkerCount updates the total number of workers
 // This can be used for dynamic scaling
 func (pm *PartitionManager) UpdateWorkerCount(totalWorkers int) error {
+	_, err := pm.Rebalance(totalWorkers)
+	return err
+}
+
+// Rebalance changes the total number of workers and returns how many symbols of the universe
+// (see Repartition) changed owner. Assigned symbols no longer owned are removed and universe
+// symbols now owned are added. With consistent hashing and no weights about 1/N of the
+// symbols move, with modulo partitioning most of them. With weights more than 1/N can move,
+// since the load caps change with the worker count.
+func (pm *PartitionManager) Rebalance(totalWorkers int) (int, error) {
 	if totalWorkers <= 0 {
-		return fmt.Errorf("total workers must be positive, got %d", totalWorkers)
+		return 0, fmt.Errorf("total workers must be positive, got %d", totalWorkers)
 	}
 	if pm.workerID >= totalWorkers {
-		return fmt.Errorf("worker ID %d must be less than total workers %d", pm.workerID, totalWorkers)
+		return 0, fmt.Errorf("worker ID %d must be less than total workers %d", pm.workerID, totalWorkers)
 	}
 
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
+	previous := make(map[string]int, len(pm.universe))
+	for _, symbol := range pm.universe {
+		previous[symbol] = pm.partitionLocked(symbol)
+	}
+
 	pm.totalWorkers = totalWorkers
+	if pm.ring != nil {
+		pm.ring = newHashRing(totalWorkers, pm.virtualNodes)
+	}
+	pm.computeOwnersLocked()
+
+	moved := 0
+	for symbol, owner := range previous {
+		if pm.partitionLocked(symbol) != owner {
+			moved++
+		}
+	}
 
 	// Recalculate assigned symbols based on new worker count
 	// Symbols that are no longer owned by this worker should be removed
 	newAssigned := make(map[string]bool)
 	for symbol := range pm.assignedSymbols {
-		if pm.GetPartition(symbol) == pm.workerID {
+		if pm.partitionLocked(symbol) == pm.workerID {
+			newAssigned[symbol] = true
+		}
+	}
+	for _, symbol := range pm.universe {
+		if pm.partitionLocked(symbol) == pm.workerID {
 			newAssigned[symbol] = true
 		}
 	}
 
 	pm.assignedSymbols = newAssigned
 
-	return nil
+	return moved, nil
+}
+
+// computeOwnersLocked assigns the universe's partition keys to workers by weight, heaviest
+// first, each to the first worker in its hash order with room under the load cap. Without
+// weights or a universe, symbols go to their hashed worker. The caller holds pm.mu.
+//
+// The greedy pass does not keep consistent hashing's ~1/N remap bound: a worker count or
+// universe change moves the load cap, and one key placed differently can push later keys
+// on to other workers in a cascade.
+func (pm *PartitionManager) computeOwnersLocked() {
+	pm.owners = nil
+	if len(pm.weights) == 0 || len(pm.universe) == 0 {
+		return
+	}
+
+	keyWeights := make(map[string]float64)
+	total := 0.0
+	for _, symbol := range pm.universe {
+		weight := pm.symbolWeight(symbol)
+		keyWeights[pm.keyFunc(symbol)] += weight
+		total += weight
+	}
+
+	keys := make([]string, 0, len(keyWeights))
+	for key := range keyWeights {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keyWeights[keys[i]] != keyWeights[keys[j]] {
+			return keyWeights[keys[i]] > keyWeights[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	capacity := total / float64(pm.totalWorkers) * (1 + weightedLoadSlack)
+	loads := make([]float64, pm.totalWorkers)
+	pm.owners = make(map[string]int, len(keys))
+	for _, key := range keys {
+		weight := keyWeights[key]
+		owner := -1
+		pm.candidates(key, func(worker int) bool {
+			if loads[worker]+weight <= capacity {
+				owner = worker
+				return false
+			}
+			return true
+		})
+		if owner < 0 {
+			// Heavier than any worker's room: take the least loaded worker
+			owner = 0
+			for worker, load := range loads {
+				if load < loads[owner] {
+					owner = worker
+				}
+			}
+		}
+		loads[owner] += weight
+		pm.owners[key] = owner
+	}
+}
+
+// candidates calls visit with workers in a key's hash order (its hashed worker first) until
+// visit returns false
+func (pm *PartitionManager) candidates(key string, visit func(worker int) bool) {
+	if pm.ring != nil {
+		pm.ring.successors(key, visit)
+		return
+	}
+	start := pm.modPartition(key)
+	for i := 0; i < pm.totalWorkers; i++ {
+		if !visit((start + i) % pm.totalWorkers) {
+			return
+		}
+	}
+}
+
+// symbolWeight returns a symbol's load weight (1 without a weight)
+func (pm *PartitionManager) symbolWeight(symbol string) float64 {
+	if weight, ok := pm.weights[symbol]; ok {
+		return weight
+	}
+	return 1
+}
+
+// Repartition replaces the assigned symbols with the symbols of universe this worker owns,
+// for when the symbol universe changes at runtime. It returns the symbols newly assigned and
+// those no longer assigned, sorted.
+func (pm *PartitionManager) Repartition(universe []string) (added, removed []string) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.universe = make([]string, 0, len(universe))
+	for _, symbol := range universe {
+		if symbol != "" {
+			pm.universe = append(pm.universe, symbol)
+		}
+	}
+	pm.computeOwnersLocked()
+
+	newAssigned := make(map[string]bool)
+	for _, symbol := range pm.universe {
+		if pm.partitionLocked(symbol) == pm.workerID {
+			newAssigned[symbol] = true
+		}
+	}
+
+	added, removed = []string{}, []string{}
+	for symbol := range newAssigned {
+		if !pm.assignedSymbols[symbol] {
+			added = append(added, symbol)
+		}
+	}
+	for symbol := range pm.assignedSymbols {
+		if !newAssigned[symbol] {
+			removed = append(removed, symbol)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	pm.assignedSymbols = newAssigned
+	return added, removed
 }
 
 // GetPartitionDistribution returns a map of partition -> symbol count
@@ -186,10 +552,22 @@ func (pm *PartitionManager) GetPartitionDistribution(symbols []string) map[int]i
 	return distribution
 }
 
+// GetPartitionLoad returns a map of partition -> weighted symbol load (see SetSymbolWeights)
+func (pm *PartitionManager) GetPartitionLoad(symbols []string) map[int]float64 {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	load := make(map[int]float64)
+	for _, symbol := range symbols {
+		load[pm.partitionLocked(symbol)] += pm.symbolWeight(symbol)
+	}
+
+	return load
+}
+
 // HashSymbolSHA256 calculates SHA256 hash of a symbol (alternative hashing method)
 func HashSymbolSHA256(symbol string) uint32 {
 	h := sha256.Sum256([]byte(symbol))
 	// Use first 4 bytes for hash
 	return uint32(h[0])<<24 | uint32(h[1])<<16 | uint32(h[2])<<8 | uint32(h[3])
 }
-
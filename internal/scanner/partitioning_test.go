@@ -102,6 +102,25 @@ func TestPartitionManager_IsOwned(t *testing.T) {
 	}
 }
 
+func TestPartitionManager_OwnershipFilter(t *testing.T) {
+	pm, err := NewPartitionManager(1, 4)
+	if err != nil {
+		t.Fatalf("Failed to create partition manager: %v", err)
+	}
+
+	filter := pm.OwnershipFilter([]string{"SPY"})
+	for _, symbol := range []string{"AAPL", "GOOGL", "MSFT", "TSLA", "AMZN"} {
+		if filter(symbol) != pm.IsOwned(symbol) {
+			t.Errorf("filter(%s) = %v, expected ownership %v", symbol, filter(symbol), pm.IsOwned(symbol))
+		}
+	}
+
+	// Reference symbols pass on every worker
+	if !filter("SPY") {
+		t.Error("Expected SPY to pass the filter")
+	}
+}
+
 func TestPartitionManager_AssignedSymbols(t *testing.T) {
 	pm, err := NewPartitionManager(0, 4)
 	if err != nil {
@@ -358,3 +377,204 @@ func TestPartitionManager_Balance(t *testing.T) {
 	}
 }
 
+
+func TestPartitionManager_GroupPartitionKey(t *testing.T) {
+	groups, err := ParseSymbolGroups("Technology:AAPL|MSFT|NVDA|GOOGL|META,Energy:XOM|CVX|COP|SLB, Banks : jpm|bac|wfc")
+	if err != nil {
+		t.Fatalf("ParseSymbolGroups() error = %v", err)
+	}
+	if groups["JPM"] != "Banks" {
+		t.Errorf("Expected JPM in Banks, got %q", groups["JPM"])
+	}
+
+	keyFunc, err := NewPartitionKeyFunc(PartitionByGroup, groups)
+	if err != nil {
+		t.Fatalf("NewPartitionKeyFunc() error = %v", err)
+	}
+
+	for _, workers := range []int{2, 3, 7, 16} {
+		pm, _ := NewPartitionManager(0, workers)
+		pm.SetPartitionKeyFunc(keyFunc)
+
+		sectorPartitions := make(map[string]map[int]bool)
+		for symbol, sector := range groups {
+			if sectorPartitions[sector] == nil {
+				sectorPartitions[sector] = make(map[int]bool)
+			}
+			sectorPartitions[sector][pm.GetPartition(symbol)] = true
+		}
+
+		for sector, partitions := range sectorPartitions {
+			if len(partitions) != 1 {
+				t.Errorf("%d workers: expected all %s symbols on one partition, got %v", workers, sector, partitions)
+			}
+		}
+	}
+
+	// Ungrouped symbols keep symbol partitioning
+	grouped, _ := NewPartitionManager(0, 8)
+	grouped.SetPartitionKeyFunc(keyFunc)
+	plain, _ := NewPartitionManager(0, 8)
+	for _, symbol := range []string{"TSLA", "AMD", "SPY"} {
+		if grouped.GetPartition(symbol) != plain.GetPartition(symbol) {
+			t.Errorf("Expected ungrouped %s to use symbol partitioning", symbol)
+		}
+	}
+}
+
+func TestParseSymbolGroups_Invalid(t *testing.T) {
+	for _, spec := range []string{"Technology", ":AAPL", "Technology:", "Technology:AAPL||MSFT", "Technology:AAPL,Energy:AAPL"} {
+		if _, err := ParseSymbolGroups(spec); err == nil {
+			t.Errorf("ParseSymbolGroups(%q) expected error", spec)
+		}
+	}
+
+	if _, err := NewPartitionKeyFunc(PartitionByGroup, nil); err == nil {
+		t.Error("Expected error for group partitioning without groups")
+	}
+	if _, err := NewPartitionKeyFunc("sector-ish", nil); err == nil {
+		t.Error("Expected error for unknown partition key mode")
+	}
+	if keyFunc, err := NewPartitionKeyFunc("", nil); err != nil || keyFunc("AAPL") != "AAPL" {
+		t.Errorf("Expected default symbol partitioning, got err %v", err)
+	}
+}
+
+func TestPartitionManager_ConsistentRebalance(t *testing.T) {
+	symbols := make([]string, 1000)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("SYM%d", i)
+	}
+
+	newManager := func(strategy string) *PartitionManager {
+		pm, err := NewPartitionManager(0, 3)
+		if err != nil {
+			t.Fatalf("Failed to create partition manager: %v", err)
+		}
+		if err := pm.SetPartitionStrategy(strategy, 0); err != nil {
+			t.Fatalf("SetPartitionStrategy() error = %v", err)
+		}
+		pm.Repartition(symbols)
+		return pm
+	}
+
+	pm := newManager(PartitionConsistent)
+	before := make(map[string]int, len(symbols))
+	for _, symbol := range symbols {
+		before[symbol] = pm.GetPartition(symbol)
+	}
+
+	moved, err := pm.Rebalance(4)
+	if err != nil {
+		t.Fatalf("Rebalance() error = %v", err)
+	}
+
+	// Going from 3 to 4 workers only hands the new worker its ~1/4 share
+	if moved < 150 || moved > 350 {
+		t.Errorf("Expected about 250 of 1000 symbols to move, got %d", moved)
+	}
+	changed := 0
+	for _, symbol := range symbols {
+		if owner := pm.GetPartition(symbol); owner != before[symbol] {
+			changed++
+			if owner != 3 {
+				t.Errorf("Expected %s to move to the new worker, moved from %d to %d", symbol, before[symbol], owner)
+			}
+		}
+	}
+	if changed != moved {
+		t.Errorf("Rebalance() reported %d moves, observed %d", moved, changed)
+	}
+	for _, symbol := range pm.GetAssignedSymbols() {
+		if !pm.IsOwned(symbol) {
+			t.Errorf("Symbol %s is assigned but not owned after rebalance", symbol)
+		}
+	}
+
+	// Modulo partitioning reshuffles most symbols
+	modMoved, err := newManager(PartitionModulo).Rebalance(4)
+	if err != nil {
+		t.Fatalf("Rebalance() error = %v", err)
+	}
+	if modMoved <= moved*2 {
+		t.Errorf("Expected modulo partitioning to move far more than %d symbols, got %d", moved, modMoved)
+	}
+
+	if _, err := pm.Rebalance(0); err == nil {
+		t.Error("Expected error for zero worker count")
+	}
+	if err := pm.SetPartitionStrategy("rendezvous", 0); err == nil {
+		t.Error("Expected error for unknown partition strategy")
+	}
+}
+
+func TestPartitionManager_ConsistentBalance(t *testing.T) {
+	pm, _ := NewPartitionManager(0, 4)
+	if err := pm.SetPartitionStrategy(PartitionConsistent, 0); err != nil {
+		t.Fatalf("SetPartitionStrategy() error = %v", err)
+	}
+
+	symbols := make([]string, 1000)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("SYMBOL%d", i)
+	}
+
+	distribution := pm.GetPartitionDistribution(symbols)
+	for partition := 0; partition < 4; partition++ {
+		if count := distribution[partition]; count < 200 || count > 300 {
+			t.Errorf("Partition %d has %d of 1000 symbols, expected within 20%% of 250", partition, count)
+		}
+	}
+}
+
+func TestPartitionManager_SymbolWeights(t *testing.T) {
+	weights, err := ParseSymbolWeights("SYM0:40, sym1:40,SYM2:40,SYM3:40")
+	if err != nil {
+		t.Fatalf("ParseSymbolWeights() error = %v", err)
+	}
+	if weights["SYM1"] != 40 {
+		t.Errorf("Expected SYM1 weight 40, got %v", weights["SYM1"])
+	}
+
+	symbols := make([]string, 200)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("SYM%d", i)
+	}
+
+	for _, strategy := range []string{PartitionModulo, PartitionConsistent} {
+		managers := make([]*PartitionManager, 4)
+		for worker := range managers {
+			managers[worker], _ = NewPartitionManager(worker, 4)
+			managers[worker].SetPartitionStrategy(strategy, 0)
+			managers[worker].SetSymbolWeights(weights)
+			managers[worker].Repartition(symbols)
+		}
+
+		// Total weight 356: no worker may go past 25% above the average of 89
+		for partition, load := range managers[0].GetPartitionLoad(symbols) {
+			if load > 89*1.25 {
+				t.Errorf("%s: partition %d has weighted load %v over the cap", strategy, partition, load)
+			}
+		}
+
+		// Workers agree on the assignment and every symbol has exactly one owner
+		owned := 0
+		for _, pm := range managers {
+			owned += pm.GetAssignedSymbolCount()
+			for _, symbol := range symbols {
+				if pm.GetPartition(symbol) != managers[0].GetPartition(symbol) {
+					t.Errorf("%s: workers disagree on the owner of %s", strategy, symbol)
+				}
+			}
+		}
+		if owned != len(symbols) {
+			t.Errorf("%s: expected %d assigned symbols across workers, got %d", strategy, len(symbols), owned)
+		}
+	}
+
+	for _, spec := range []string{"AAPL", "AAPL:", ":5", "AAPL:0", "AAPL:-1", "AAPL:heavy"} {
+		if _, err := ParseSymbolWeights(spec); err == nil {
+			t.Errorf("ParseSymbolWeights(%q) expected error", spec)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package scanner
+
+import (
+	"strings"
+)
+
+// ReferenceMetricPrefix prefixes metrics read from a reference symbol in rule conditions,
+// e.g. "ref_SPY_price_change_5m_pct" is SPY's price_change_5m_pct
+const ReferenceMetricPrefix = "ref_"
+
+// ReferenceMetricName returns the rule metric name for a reference symbol's metric
+func ReferenceMetricName(symbol, metric string) string {
+	return ReferenceMetricPrefix + symbol + "_" + metric
+}
+
+// parseReferenceMetric splits a reference metric name into its symbol and metric.
+// Only configured reference symbols are recognized.
+func parseReferenceMetric(name string, referenceSymbols map[string]bool) (string, string, bool) {
+	if !strings.HasPrefix(name, ReferenceMetricPrefix) {
+		return "", "", false
+	}
+
+	rest := name[len(ReferenceMetricPrefix):]
+	for symbol := range referenceSymbols {
+		prefix := symbol + "_"
+		if strings.HasPrefix(rest, prefix) && len(rest) > len(prefix) {
+			return symbol, rest[len(prefix):], true
+		}
+	}
+
+	return "", "", false
+}
+
+// splitReferenceMetrics separates reference metrics from the symbol's own required metrics.
+// Returns the remaining local metrics and the metrics required per reference symbol.
+func splitReferenceMetrics(required map[string]bool, referenceSymbols map[string]bool) (map[string]bool, map[string]map[string]bool) {
+	local := make(map[string]bool, len(required))
+	reference := make(map[string]map[string]bool)
+
+	for name := range required {
+		symbol, metric, ok := parseReferenceMetric(name, referenceSymbols)
+		if !ok {
+			local[name] = true
+			continue
+		}
+
+		if reference[symbol] == nil {
+			reference[symbol] = make(map[string]bool)
+		}
+		reference[symbol][metric] = true
+	}
+
+	return local, reference
+}
+
+// normalizeReferenceSymbols builds the reference symbol set from configuration
+func normalizeReferenceSymbols(symbols []string) map[string]bool {
+	result := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol != "" {
+			result[symbol] = true
+		}
+	}
+	return result
+}
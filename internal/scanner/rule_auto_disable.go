@@ -0,0 +1,200 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// rulesAutoDisabledTotal counts rules disabled after repeated evaluation errors
+var rulesAutoDisabledTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "scanner_rules_auto_disabled_total",
+		Help: "Total number of times a rule was disabled automatically after consecutive evaluation errors",
+	},
+	[]string{"rule_id"},
+)
+
+// RuleDisabledChannel is the pub/sub channel on which automatically disabled rules are announced
+const RuleDisabledChannel = "rules.auto_disabled"
+
+// notifyTimeout bounds publishing an operator notification
+const notifyTimeout = 5 * time.Second
+
+// RuleDisableNotifier notifies operators that a rule was disabled automatically
+type RuleDisableNotifier interface {
+	NotifyRuleDisabled(ctx context.Context, event models.RuleDisableEvent) error
+}
+
+// DisabledRuleRecorder records automatically disabled rules where the API lists them and
+// clears them once the rule is re-enabled (e.g. storage.DisabledRuleStore)
+type DisabledRuleRecorder interface {
+	SaveDisabledRule(ctx context.Context, event models.RuleDisableEvent) error
+}
+
+// RedisRuleDisableNotifier announces disabled rules on RuleDisabledChannel
+type RedisRuleDisableNotifier struct {
+	redis storage.RedisClient
+}
+
+// NewRedisRuleDisableNotifier creates a notifier publishing to Redis pub/sub
+func NewRedisRuleDisableNotifier(redis storage.RedisClient) *RedisRuleDisableNotifier {
+	return &RedisRuleDisableNotifier{redis: redis}
+}
+
+// NotifyRuleDisabled publishes the event on RuleDisabledChannel
+func (n *RedisRuleDisableNotifier) NotifyRuleDisabled(ctx context.Context, event models.RuleDisableEvent) error {
+	return n.redis.Publish(ctx, RuleDisabledChannel, event)
+}
+
+// ruleErrorStreak is a rule's run of evaluation errors without a successful evaluation
+type ruleErrorStreak struct {
+	errors int64
+	first  time.Time
+}
+
+// ruleErrorTracker counts consecutive evaluation errors per rule. A successful evaluation
+// ends a rule's streak, as does the window elapsing since the streak's first error.
+type ruleErrorTracker struct {
+	limit   int64
+	window  time.Duration
+	mu      sync.Mutex
+	streaks map[string]*ruleErrorStreak
+}
+
+// newRuleErrorTracker creates a tracker disabling rules after limit consecutive errors
+// within window (nil = rules are never disabled)
+func newRuleErrorTracker(limit int, window time.Duration) *ruleErrorTracker {
+	if limit <= 0 {
+		return nil
+	}
+	return &ruleErrorTracker{
+		limit:   int64(limit),
+		window:  window,
+		streaks: make(map[string]*ruleErrorStreak),
+	}
+}
+
+// Record adds a scan cycle's results for a rule: whether any evaluation succeeded, and the
+// errors since the last successful evaluation. Returns the streak length and whether it
+// reached the limit. Safe to call on a nil tracker.
+func (t *ruleErrorTracker) Record(ruleID string, succeeded bool, trailingErrors int64, now time.Time) (int64, bool) {
+	if t == nil {
+		return 0, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	streak, exists := t.streaks[ruleID]
+	if succeeded || (exists && t.window > 0 && now.Sub(streak.first) > t.window) {
+		delete(t.streaks, ruleID)
+		exists = false
+	}
+	if trailingErrors == 0 {
+		return 0, false
+	}
+	if !exists {
+		streak = &ruleErrorStreak{first: now}
+		t.streaks[ruleID] = streak
+	}
+
+	streak.errors += trailingErrors
+	if streak.errors < t.limit {
+		return streak.errors, false
+	}
+	delete(t.streaks, ruleID)
+	return streak.errors, true
+}
+
+// SetRuleDisableNotifier sets the notifier told about rules disabled after repeated errors
+func (sl *ScanLoop) SetRuleDisableNotifier(notifier RuleDisableNotifier) {
+	sl.ruleDisableNotifier = notifier
+}
+
+// SetDisabledRuleRecorder sets where rules disabled after repeated errors are recorded
+func (sl *ScanLoop) SetDisabledRuleRecorder(recorder DisabledRuleRecorder) {
+	sl.disabledRuleRecorder = recorder
+}
+
+// recordRuleErrors updates a rule's error streak with a scan cycle's results, disabling the
+// rule once the streak reaches RuleErrorLimit
+func (sl *ScanLoop) recordRuleErrors(ruleID string, counts *ruleCycleCounts, rule *models.Rule, now time.Time) {
+	errors, limitReached := sl.ruleErrors.Record(ruleID, counts.evaluations > 0, counts.trailingErrors, now)
+	if !limitReached {
+		return
+	}
+
+	ruleName, userID := "", ""
+	if rule != nil {
+		ruleName, userID = rule.Name, rule.UserID
+	}
+	sl.autoDisableRule(ruleID, ruleName, userID, errors, counts.lastErr, now)
+}
+
+// autoDisableRule disables a rule in the rule store after errors consecutive evaluation errors,
+// stops evaluating it, records it and notifies operators
+func (sl *ScanLoop) autoDisableRule(ruleID, ruleName, userID string, errors int64, lastErr error, now time.Time) {
+	if err := sl.ruleStore.DisableRule(ruleID); err != nil {
+		logger.Error("Failed to auto-disable rule",
+			logger.ErrorField(err),
+			logger.String("rule_id", ruleID),
+		)
+		return
+	}
+
+	event := models.RuleDisableEvent{
+		RuleID:     ruleID,
+		RuleName:   ruleName,
+		UserID:     userID,
+		Reason:     fmt.Sprintf("%d consecutive evaluation errors", errors),
+		Errors:     errors,
+		DisabledAt: now,
+	}
+	if lastErr != nil {
+		event.LastError = lastErr.Error()
+	}
+	rulesAutoDisabledTotal.WithLabelValues(ruleID).Inc()
+
+	logger.Error("Rule disabled after repeated evaluation errors",
+		logger.String("rule_id", ruleID),
+		logger.String("rule_name", ruleName),
+		logger.Int64("errors", errors),
+		logger.String("last_error", event.LastError),
+	)
+
+	if err := sl.reloadRules(); err != nil {
+		logger.Warn("Failed to reload rules after auto-disabling rule",
+			logger.ErrorField(err),
+			logger.String("rule_id", ruleID),
+		)
+	}
+
+	ctx, cancel := context.WithTimeout(sl.ctx, notifyTimeout)
+	defer cancel()
+
+	if sl.disabledRuleRecorder != nil {
+		if err := sl.disabledRuleRecorder.SaveDisabledRule(ctx, event); err != nil {
+			logger.Warn("Failed to record auto-disabled rule",
+				logger.ErrorField(err),
+				logger.String("rule_id", ruleID),
+			)
+		}
+	}
+
+	if sl.ruleDisableNotifier != nil {
+		if err := sl.ruleDisableNotifier.NotifyRuleDisabled(ctx, event); err != nil {
+			logger.Warn("Failed to notify operators of auto-disabled rule",
+				logger.ErrorField(err),
+				logger.String("rule_id", ruleID),
+			)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+)
+
+// recordingDisableNotifier records rule disable notifications and disabled rule records
+type recordingDisableNotifier struct {
+	events   []models.RuleDisableEvent
+	recorded []models.RuleDisableEvent
+}
+
+func (n *recordingDisableNotifier) NotifyRuleDisabled(ctx context.Context, event models.RuleDisableEvent) error {
+	n.events = append(n.events, event)
+	return nil
+}
+
+func (n *recordingDisableNotifier) SaveDisabledRule(ctx context.Context, event models.RuleDisableEvent) error {
+	n.recorded = append(n.recorded, event)
+	return nil
+}
+
+// setupAutoDisableTest creates a scan loop disabling rules after errorLimit errors, with a
+// healthy rule and a rule that always fails to evaluate, scanning a single symbol
+func setupAutoDisableTest(t *testing.T, errorLimit int) (*ScanLoop, *rules.InMemoryRuleStore, *recordingDisableNotifier) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+
+	for _, rule := range []*models.Rule{
+		{
+			ID:         "rule-broken",
+			Name:       "Broken Rule",
+			UserID:     "alice",
+			Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+			Enabled:    true,
+		},
+		{
+			ID:         "rule-price",
+			Name:       "Price Above 100",
+			Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+			Enabled:    true,
+		},
+	} {
+		if err := ruleStore.AddRule(rule); err != nil {
+			t.Fatalf("Failed to add rule: %v", err)
+		}
+	}
+
+	config := DefaultScanLoopConfig()
+	config.RuleErrorLimit = errorLimit
+	config.RuleErrorWindow = time.Minute
+	sl := NewScanLoop(config, sm, ruleStore, rules.NewCompiler(nil), nil, &recordingAlertEmitter{}, nil)
+	notifier := &recordingDisableNotifier{}
+	sl.SetRuleDisableNotifier(notifier)
+	sl.SetDisabledRuleRecorder(notifier)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	sl.rulesMu.Lock()
+	sl.compiledRules["rule-broken"] = func(symbol string, metrics map[string]float64) (bool, error) {
+		return false, errors.New("metric not available")
+	}
+	sl.rulesMu.Unlock()
+
+	tick := &models.Tick{Symbol: "AAPL", Price: 150.0, Size: 100, Timestamp: time.Now(), Type: "trade"}
+	if err := sm.UpdateLiveBar("AAPL", tick); err != nil {
+		t.Fatalf("Failed to update live bar: %v", err)
+	}
+
+	return sl, ruleStore, notifier
+}
+
+func isRuleEnabled(t *testing.T, ruleStore *rules.InMemoryRuleStore, ruleID string) bool {
+	rule, err := ruleStore.GetRule(ruleID)
+	if err != nil {
+		t.Fatalf("Failed to get rule %s: %v", ruleID, err)
+	}
+	return rule.Enabled
+}
+
+func TestScanLoop_AutoDisableErroringRule(t *testing.T) {
+	sl, ruleStore, notifier := setupAutoDisableTest(t, 3)
+
+	for i := 0; i < 2; i++ {
+		sl.Scan()
+	}
+	if !isRuleEnabled(t, ruleStore, "rule-broken") {
+		t.Fatal("Expected rule to stay enabled below the error limit")
+	}
+
+	sl.Scan()
+
+	if isRuleEnabled(t, ruleStore, "rule-broken") {
+		t.Error("Expected rule to be disabled after 3 consecutive errors")
+	}
+	if !isRuleEnabled(t, ruleStore, "rule-price") {
+		t.Error("Expected healthy rule to stay enabled")
+	}
+
+	sl.rulesMu.RLock()
+	_, compiled := sl.compiledRules["rule-broken"]
+	sl.rulesMu.RUnlock()
+	if compiled {
+		t.Error("Expected disabled rule to no longer be evaluated")
+	}
+
+	disabled := notifier.recorded
+	if len(disabled) != 1 {
+		t.Fatalf("Expected 1 disabled rule recorded, got %d", len(disabled))
+	}
+	if disabled[0].RuleID != "rule-broken" || disabled[0].RuleName != "Broken Rule" || disabled[0].UserID != "alice" {
+		t.Errorf("Unexpected disabled rule: %+v", disabled[0])
+	}
+	if disabled[0].Errors != 3 {
+		t.Errorf("Expected 3 errors recorded, got %d", disabled[0].Errors)
+	}
+	if disabled[0].LastError != "metric not available" {
+		t.Errorf("Expected last error to be recorded, got %q", disabled[0].LastError)
+	}
+	if disabled[0].Reason == "" {
+		t.Error("Expected a disable reason")
+	}
+
+	if len(notifier.events) != 1 || notifier.events[0].RuleID != "rule-broken" {
+		t.Errorf("Expected one notification for rule-broken, got %+v", notifier.events)
+	}
+}
+
+func TestScanLoop_AutoDisableHealthyRuleNeverDisabled(t *testing.T) {
+	sl, ruleStore, notifier := setupAutoDisableTest(t, 1)
+
+	for i := 0; i < 10; i++ {
+		sl.Scan()
+	}
+
+	if !isRuleEnabled(t, ruleStore, "rule-price") {
+		t.Error("Expected healthy rule to stay enabled")
+	}
+	for _, event := range notifier.events {
+		if event.RuleID == "rule-price" {
+			t.Error("Expected no notification for the healthy rule")
+		}
+	}
+}
+
+func TestRuleErrorTracker_SuccessResetsStreak(t *testing.T) {
+	tracker := newRuleErrorTracker(3, time.Minute)
+	now := time.Now()
+
+	tracker.Record("rule-1", false, 2, now)
+	if _, limitReached := tracker.Record("rule-1", true, 0, now); limitReached {
+		t.Error("Expected successful evaluation not to reach the limit")
+	}
+	if count, limitReached := tracker.Record("rule-1", false, 2, now); limitReached || count != 2 {
+		t.Errorf("Expected streak to restart after a success, got %d errors (limit reached: %v)", count, limitReached)
+	}
+	if _, limitReached := tracker.Record("rule-1", false, 1, now.Add(2*time.Minute)); limitReached {
+		t.Error("Expected streak to restart once the window elapsed")
+	}
+	if newRuleErrorTracker(0, time.Minute) != nil {
+		t.Error("Expected nil tracker when the error limit is 0")
+	}
+}
@@ -0,0 +1,98 @@
+package scanner
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+)
+
+// DryRunRuleID is the ID rules evaluated by EvaluateRule are compiled under
+const DryRunRuleID = "dry-run"
+
+// EvaluateRule evaluates a rule, which need not be saved, against the current state of every
+// symbol without emitting alerts. Matching follows the scan loop (stale symbols, reference
+// symbols and volume/session filters are skipped), but cooldowns, mutes and bar-close timing
+// are ignored: the result is which symbols the rule matches right now. The rule is compiled
+// under a dry-run ID with its own hysteresis state, so it cannot affect active rules.
+func (sl *ScanLoop) EvaluateRule(rule *models.Rule) (*models.RuleEvaluation, error) {
+	dryRun := *rule
+	dryRun.ID = DryRunRuleID
+	rule = &dryRun
+
+	compiled, err := sl.compiler.Detached().CompileRule(rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile rule: %w", err)
+	}
+
+	required, reference := splitReferenceMetrics(
+		rules.ExtractRequiredMetricsWithCustom([]*models.Rule{rule}, sl.compiler.CustomMetricRegistry()),
+		sl.referenceSymbols,
+	)
+
+	snapshot := sl.stateManager.Snapshot()
+	now := sl.clock.Now()
+	referenceValues := sl.referenceMetricValues(snapshot, reference)
+
+	evaluation := &models.RuleEvaluation{
+		EvaluatedAt: now,
+		Matches:     []models.RuleMatch{},
+	}
+
+	for _, symbol := range snapshot.Symbols {
+		symbolState := snapshot.States[symbol]
+		if symbolState == nil || sl.referenceSymbols[symbol] {
+			continue
+		}
+		if sl.isStale(symbolState, now) {
+			evaluation.SymbolsStale++
+			continue
+		}
+		evaluation.SymbolsEvaluated++
+
+		metrics := sl.getMetricsFromSnapshot(symbolState, required)
+		for name, value := range referenceValues {
+			metrics[name] = value
+		}
+
+		matched, err := evaluateDryRun(compiled, symbol, metrics)
+		if err != nil {
+			sl.returnMetricsToPool(metrics)
+			return nil, fmt.Errorf("failed to evaluate rule for %s: %w", symbol, err)
+		}
+
+		if matched {
+			match := models.RuleMatch{Symbol: symbol, Metrics: make(map[string]float64, len(required)+len(referenceValues))}
+			for name, value := range metrics {
+				if required[name] || hasReferenceValue(referenceValues, name) {
+					match.Metrics[name] = value
+				}
+			}
+			evaluation.Matches = append(evaluation.Matches, match)
+		}
+		sl.returnMetricsToPool(metrics)
+	}
+
+	sort.Slice(evaluation.Matches, func(i, j int) bool {
+		return evaluation.Matches[i].Symbol < evaluation.Matches[j].Symbol
+	})
+	return evaluation, nil
+}
+
+// evaluateDryRun evaluates a compiled rule, turning a panic into an error. Unlike
+// evaluateCompiled it leaves the rule panic statistics alone, as the rule is not active.
+func evaluateDryRun(compiled rules.CompiledRule, symbol string, metrics map[string]float64) (matched bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			matched, err = false, fmt.Errorf("rule panicked: %v", r)
+		}
+	}()
+	return compiled(symbol, metrics)
+}
+
+// hasReferenceValue returns whether name is one of the computed reference metric values
+func hasReferenceValue(values map[string]float64, name string) bool {
+	_, ok := values[name]
+	return ok
+}
@@ -0,0 +1,91 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+)
+
+func TestScanLoop_EvaluateRule(t *testing.T) {
+	start := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+	clock := NewSimulationClock(start)
+	sm := NewStateManager(10)
+	sm.SetClock(clock)
+
+	config := DefaultScanLoopConfig()
+	config.MaxDataStaleness = time.Minute
+	config.ReferenceSymbols = []string{"SPY"}
+	emitter := &recordingAlertEmitter{}
+	sl := NewScanLoop(config, sm, rules.NewInMemoryRuleStore(), rules.NewCompiler(nil), nil, emitter, nil)
+	sl.SetClock(clock)
+
+	updatePrice := func(symbol string, price float64) {
+		tick := &models.Tick{Symbol: symbol, Price: price, Size: 100, Timestamp: clock.Now(), Type: "trade"}
+		if err := sm.UpdateLiveBar(symbol, tick); err != nil {
+			t.Fatalf("Failed to update live bar: %v", err)
+		}
+	}
+
+	// NFLX would match but its data is stale by the time of the evaluation
+	updatePrice("NFLX", 500.0)
+	clock.Observe(start.Add(5 * time.Minute))
+	updatePrice("AAPL", 150.0)
+	updatePrice("MSFT", 90.0)
+	updatePrice("TSLA", 200.0)
+	updatePrice("SPY", 470.0)
+
+	// An unsaved rule: price above 100 while SPY trades above 400
+	rule := &models.Rule{
+		ID:   "dry-run",
+		Name: "Price Above 100",
+		Conditions: []models.Condition{
+			{Metric: "price", Operator: ">", Value: 100.0},
+			{Metric: "ref_SPY_price", Operator: ">", Value: 400.0},
+		},
+		Enabled: true,
+	}
+
+	evaluation, err := sl.EvaluateRule(rule)
+	if err != nil {
+		t.Fatalf("EvaluateRule() error = %v", err)
+	}
+
+	if evaluation.SymbolsEvaluated != 3 || evaluation.SymbolsStale != 1 {
+		t.Errorf("Expected 3 symbols evaluated and 1 stale, got %d and %d", evaluation.SymbolsEvaluated, evaluation.SymbolsStale)
+	}
+	if len(evaluation.Matches) != 2 {
+		t.Fatalf("Expected 2 matches, got %+v", evaluation.Matches)
+	}
+
+	expected := []struct {
+		symbol string
+		price  float64
+	}{{"AAPL", 150.0}, {"TSLA", 200.0}}
+	for i, match := range evaluation.Matches {
+		if match.Symbol != expected[i].symbol {
+			t.Errorf("Match %d: expected %s, got %s", i, expected[i].symbol, match.Symbol)
+		}
+		if match.Metrics["price"] != expected[i].price {
+			t.Errorf("Match %d: expected price %v, got %v", i, expected[i].price, match.Metrics["price"])
+		}
+		if match.Metrics["ref_SPY_price"] != 470.0 {
+			t.Errorf("Match %d: expected ref_SPY_price 470, got %v", i, match.Metrics["ref_SPY_price"])
+		}
+		if len(match.Metrics) != 2 {
+			t.Errorf("Match %d: expected only the rule's metrics, got %v", i, match.Metrics)
+		}
+	}
+
+	// A dry run emits no alerts
+	if len(emitter.alerts) != 0 {
+		t.Errorf("Expected no alerts, got %d", len(emitter.alerts))
+	}
+
+	// Invalid rules are reported
+	rule.Conditions[0].Operator = "~"
+	if _, err := sl.EvaluateRule(rule); err == nil {
+		t.Error("Expected an error for a rule that does not compile")
+	}
+}
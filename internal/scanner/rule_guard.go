@@ -0,0 +1,38 @@
+package scanner
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// rulePanicsTotal counts compiled rule evaluations that panicked
+var rulePanicsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "scanner_rule_panics_total",
+		Help: "Total number of rule evaluations that panicked and were skipped",
+	},
+	[]string{"rule_id"},
+)
+
+// evaluateCompiled runs a compiled rule, converting a panic into an error so one broken
+// rule is skipped instead of crashing the scan goroutine
+func (sl *ScanLoop) evaluateCompiled(ruleID string, compiled rules.CompiledRule, symbol string, metrics map[string]float64) (matched bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			atomic.AddInt64(&sl.stats.RulePanics, 1)
+			rulePanicsTotal.WithLabelValues(ruleID).Inc()
+			logger.Error("Rule evaluation panicked, skipping rule",
+				logger.String("rule_id", ruleID),
+				logger.String("symbol", symbol),
+				logger.String("panic", fmt.Sprint(r)),
+			)
+			matched, err = false, fmt.Errorf("rule %s panicked: %v", ruleID, r)
+		}
+	}()
+	return compiled(symbol, metrics)
+}
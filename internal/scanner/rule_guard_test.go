@@ -0,0 +1,71 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+)
+
+func TestScanLoop_PanickingRuleIsSkipped(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	for _, rule := range []*models.Rule{
+		{
+			ID:         "rule-broken",
+			Name:       "Broken Rule",
+			Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+			Enabled:    true,
+		},
+		{
+			ID:         "rule-price",
+			Name:       "Price Above 100",
+			Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+			Enabled:    true,
+		},
+	} {
+		if err := ruleStore.AddRule(rule); err != nil {
+			t.Fatalf("Failed to add rule: %v", err)
+		}
+	}
+
+	sl := NewScanLoop(DefaultScanLoopConfig(), sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	// Simulate a condition type the compiler mishandles
+	sl.rulesMu.Lock()
+	sl.compiledRules["rule-broken"] = func(symbol string, metrics map[string]float64) (bool, error) {
+		panic("unsupported condition type")
+	}
+	sl.rulesMu.Unlock()
+
+	for _, symbol := range []string{"AAPL", "MSFT"} {
+		tick := &models.Tick{Symbol: symbol, Price: 150.0, Size: 100, Timestamp: time.Now(), Type: "trade"}
+		if err := sm.UpdateLiveBar(symbol, tick); err != nil {
+			t.Fatalf("Failed to update live bar: %v", err)
+		}
+	}
+
+	sl.Scan()
+
+	stats := sl.GetStats()
+	if stats.RulePanics != 2 {
+		t.Errorf("Expected 2 rule panics, got %d", stats.RulePanics)
+	}
+	if stats.SymbolsScanned != 2 {
+		t.Errorf("Expected the scan to complete for 2 symbols, got %d", stats.SymbolsScanned)
+	}
+	if len(emitter.alerts) != 2 {
+		t.Fatalf("Expected 2 alerts from the healthy rule, got %d", len(emitter.alerts))
+	}
+	for _, alert := range emitter.alerts {
+		if alert.RuleID != "rule-price" {
+			t.Errorf("Expected alerts only from the healthy rule, got %s", alert.RuleID)
+		}
+	}
+}
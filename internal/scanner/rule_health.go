@@ -0,0 +1,303 @@
+package scanner
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// RuleStats holds per-rule evaluation statistics collected by the scan loop
+type RuleStats struct {
+	RuleID         string    `json:"rule_id"`
+	Evaluations    int64     `json:"evaluations"`
+	Matches        int64     `json:"matches"`
+	FirstEvaluated time.Time `json:"first_evaluated"`
+	LastMatched    time.Time `json:"last_matched,omitempty"`
+}
+
+// RuleStatsTracker accumulates per-rule evaluation and match counts
+type RuleStatsTracker struct {
+	stats map[string]*RuleStats
+	mu    sync.RWMutex
+}
+
+// NewRuleStatsTracker creates a new rule stats tracker
+func NewRuleStatsTracker() *RuleStatsTracker {
+	return &RuleStatsTracker{
+		stats: make(map[string]*RuleStats),
+	}
+}
+
+// Record adds evaluation and match counts for a rule observed at the given time
+func (t *RuleStatsTracker) Record(ruleID string, evaluations, matches int64, now time.Time) {
+	if evaluations <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats, exists := t.stats[ruleID]
+	if !exists {
+		stats = &RuleStats{RuleID: ruleID, FirstEvaluated: now}
+		t.stats[ruleID] = stats
+	}
+	stats.Evaluations += evaluations
+	stats.Matches += matches
+	if matches > 0 {
+		stats.LastMatched = now
+	}
+}
+
+// Snapshot returns a copy of the stats keyed by rule ID
+func (t *RuleStatsTracker) Snapshot() map[string]RuleStats {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	result := make(map[string]RuleStats, len(t.stats))
+	for ruleID, stats := range t.stats {
+		result[ruleID] = *stats
+	}
+	return result
+}
+
+// Prune drops stats for rules that are no longer active
+func (t *RuleStatsTracker) Prune(active map[string]rules.CompiledRule) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for ruleID := range t.stats {
+		if _, ok := active[ruleID]; !ok {
+			delete(t.stats, ruleID)
+		}
+	}
+}
+
+// RuleHealthFlag identifies a likely misconfigured rule
+type RuleHealthFlag string
+
+const (
+	// RuleHealthNeverFiring flags rules that have not matched within the never-firing window
+	RuleHealthNeverFiring RuleHealthFlag = "never_firing"
+	// RuleHealthAlwaysFiring flags rules that match on nearly every evaluation
+	RuleHealthAlwaysFiring RuleHealthFlag = "always_firing"
+)
+
+// RuleHealthConfig holds configuration for the rules health analyzer
+type RuleHealthConfig struct {
+	NeverFiringAfter  time.Duration // Flag rules with no match for this long (default: 7 days)
+	AlwaysFiringRatio float64       // Flag rules matching at least this fraction of evaluations (default: 0.95)
+	MinEvaluations    int64         // Evaluations required before flagging always-firing rules (default: 100)
+	CheckInterval     time.Duration // How often the report is refreshed (default: 1 minute)
+}
+
+// DefaultRuleHealthConfig returns default configuration
+func DefaultRuleHealthConfig() RuleHealthConfig {
+	return RuleHealthConfig{
+		NeverFiringAfter:  7 * 24 * time.Hour,
+		AlwaysFiringRatio: 0.95,
+		MinEvaluations:    100,
+		CheckInterval:     1 * time.Minute,
+	}
+}
+
+// RuleHealthEntry is the health of a single rule
+type RuleHealthEntry struct {
+	RuleID      string           `json:"rule_id"`
+	Name        string           `json:"name"`
+	Flags       []RuleHealthFlag `json:"flags"`
+	Evaluations int64            `json:"evaluations"`
+	Matches     int64            `json:"matches"`
+	MatchRatio  float64          `json:"match_ratio"`
+	LastMatched *time.Time       `json:"last_matched,omitempty"`
+}
+
+// RuleHealthReport is the rules health report
+type RuleHealthReport struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	Rules       []RuleHealthEntry `json:"rules"`
+	Flagged     int               `json:"flagged"`
+}
+
+// AnalyzeRuleHealth flags enabled rules that never fire or fire on nearly every evaluation.
+// Rules without stats have not been evaluated yet and are reported without flags.
+func AnalyzeRuleHealth(ruleList []*models.Rule, stats map[string]RuleStats, config RuleHealthConfig, now time.Time) RuleHealthReport {
+	report := RuleHealthReport{
+		GeneratedAt: now,
+		Rules:       make([]RuleHealthEntry, 0, len(ruleList)),
+	}
+
+	for _, rule := range ruleList {
+		if rule == nil || !rule.Enabled {
+			continue
+		}
+
+		entry := RuleHealthEntry{
+			RuleID: rule.ID,
+			Name:   rule.Name,
+			Flags:  []RuleHealthFlag{},
+		}
+
+		ruleStats, ok := stats[rule.ID]
+		if ok && ruleStats.Evaluations > 0 {
+			entry.Evaluations = ruleStats.Evaluations
+			entry.Matches = ruleStats.Matches
+			entry.MatchRatio = float64(ruleStats.Matches) / float64(ruleStats.Evaluations)
+			if !ruleStats.LastMatched.IsZero() {
+				lastMatched := ruleStats.LastMatched
+				entry.LastMatched = &lastMatched
+			}
+
+			// Measure silence from the last match, or from the first evaluation if it never matched
+			silentSince := ruleStats.LastMatched
+			if silentSince.IsZero() {
+				silentSince = ruleStats.FirstEvaluated
+			}
+			if config.NeverFiringAfter > 0 && now.Sub(silentSince) >= config.NeverFiringAfter {
+				entry.Flags = append(entry.Flags, RuleHealthNeverFiring)
+			}
+
+			if config.AlwaysFiringRatio > 0 &&
+				ruleStats.Evaluations >= config.MinEvaluations &&
+				entry.MatchRatio >= config.AlwaysFiringRatio {
+				entry.Flags = append(entry.Flags, RuleHealthAlwaysFiring)
+			}
+		}
+
+		if len(entry.Flags) > 0 {
+			report.Flagged++
+		}
+		report.Rules = append(report.Rules, entry)
+	}
+
+	// Flagged rules first, then by rule ID for stable output
+	sort.Slice(report.Rules, func(i, j int) bool {
+		fi, fj := len(report.Rules[i].Flags) > 0, len(report.Rules[j].Flags) > 0
+		if fi != fj {
+			return fi
+		}
+		return report.Rules[i].RuleID < report.Rules[j].RuleID
+	})
+
+	return report
+}
+
+// RuleHealthAnalyzer periodically builds the rules health report in the background
+type RuleHealthAnalyzer struct {
+	config    RuleHealthConfig
+	ruleStore rules.RuleStore
+	stats     func() map[string]RuleStats
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	mu        sync.RWMutex
+	report    *RuleHealthReport
+	running   bool
+}
+
+// NewRuleHealthAnalyzer creates a new rules health analyzer reading stats from the given source
+func NewRuleHealthAnalyzer(config RuleHealthConfig, ruleStore rules.RuleStore, stats func() map[string]RuleStats) *RuleHealthAnalyzer {
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = DefaultRuleHealthConfig().CheckInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &RuleHealthAnalyzer{
+		config:    config,
+		ruleStore: ruleStore,
+		stats:     stats,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Start starts the background analysis
+func (a *RuleHealthAnalyzer) Start() {
+	a.mu.Lock()
+	if a.running {
+		a.mu.Unlock()
+		return
+	}
+	a.running = true
+	a.mu.Unlock()
+
+	a.wg.Add(1)
+	go a.run()
+}
+
+// Stop stops the background analysis
+func (a *RuleHealthAnalyzer) Stop() {
+	a.mu.Lock()
+	if !a.running {
+		a.mu.Unlock()
+		return
+	}
+	a.running = false
+	a.mu.Unlock()
+
+	a.cancel()
+	a.wg.Wait()
+}
+
+// Report returns the latest rules health report, analyzing on demand if none exists yet
+func (a *RuleHealthAnalyzer) Report() (RuleHealthReport, error) {
+	a.mu.RLock()
+	report := a.report
+	a.mu.RUnlock()
+
+	if report != nil {
+		return *report, nil
+	}
+	return a.Analyze()
+}
+
+// Analyze builds a fresh rules health report and caches it
+func (a *RuleHealthAnalyzer) Analyze() (RuleHealthReport, error) {
+	ruleList, err := a.ruleStore.GetAllRules()
+	if err != nil {
+		return RuleHealthReport{}, err
+	}
+
+	report := AnalyzeRuleHealth(ruleList, a.stats(), a.config, time.Now())
+
+	a.mu.Lock()
+	a.report = &report
+	a.mu.Unlock()
+
+	return report, nil
+}
+
+// run is the main analysis loop
+func (a *RuleHealthAnalyzer) run() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(a.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := a.Analyze()
+			if err != nil {
+				logger.Error("Failed to analyze rules health",
+					logger.ErrorField(err),
+				)
+				continue
+			}
+			if report.Flagged > 0 {
+				logger.Warn("Rules health check flagged rules",
+					logger.Int("flagged", report.Flagged),
+					logger.Int("rule_count", len(report.Rules)),
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+)
+
+func healthEntry(t *testing.T, report RuleHealthReport, ruleID string) RuleHealthEntry {
+	t.Helper()
+	for _, entry := range report.Rules {
+		if entry.RuleID == ruleID {
+			return entry
+		}
+	}
+	t.Fatalf("Expected rule %s in report", ruleID)
+	return RuleHealthEntry{}
+}
+
+func hasFlag(entry RuleHealthEntry, flag RuleHealthFlag) bool {
+	for _, f := range entry.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAnalyzeRuleHealth_Flags(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	config := DefaultRuleHealthConfig()
+
+	ruleList := []*models.Rule{
+		{ID: "healthy", Name: "Healthy", Enabled: true},
+		{ID: "never-matched", Name: "Never Matched", Enabled: true},
+		{ID: "stale", Name: "Stale", Enabled: true},
+		{ID: "always", Name: "Always", Enabled: true},
+		{ID: "new-never", Name: "New Rule", Enabled: true},
+		{ID: "few-evals", Name: "Few Evaluations", Enabled: true},
+		{ID: "unevaluated", Name: "Unevaluated", Enabled: true},
+		{ID: "disabled", Name: "Disabled", Enabled: false},
+	}
+
+	stats := map[string]RuleStats{
+		"healthy": {
+			Evaluations: 10000, Matches: 50,
+			FirstEvaluated: now.Add(-30 * 24 * time.Hour), LastMatched: now.Add(-time.Hour),
+		},
+		"never-matched": {
+			Evaluations: 10000, Matches: 0,
+			FirstEvaluated: now.Add(-8 * 24 * time.Hour),
+		},
+		"stale": {
+			Evaluations: 10000, Matches: 10,
+			FirstEvaluated: now.Add(-30 * 24 * time.Hour), LastMatched: now.Add(-10 * 24 * time.Hour),
+		},
+		"always": {
+			Evaluations: 1000, Matches: 990,
+			FirstEvaluated: now.Add(-time.Hour), LastMatched: now,
+		},
+		"new-never": {
+			Evaluations: 500, Matches: 0,
+			FirstEvaluated: now.Add(-time.Hour),
+		},
+		"few-evals": {
+			Evaluations: 10, Matches: 10,
+			FirstEvaluated: now.Add(-time.Minute), LastMatched: now,
+		},
+		"disabled": {
+			Evaluations: 10000, Matches: 0,
+			FirstEvaluated: now.Add(-30 * 24 * time.Hour),
+		},
+	}
+
+	report := AnalyzeRuleHealth(ruleList, stats, config, now)
+
+	if len(report.Rules) != 7 {
+		t.Fatalf("Expected 7 enabled rules in report, got %d", len(report.Rules))
+	}
+	if report.Flagged != 3 {
+		t.Errorf("Expected 3 flagged rules, got %d", report.Flagged)
+	}
+
+	tests := []struct {
+		ruleID string
+		flags  []RuleHealthFlag
+	}{
+		{"healthy", nil},
+		{"never-matched", []RuleHealthFlag{RuleHealthNeverFiring}},
+		{"stale", []RuleHealthFlag{RuleHealthNeverFiring}},
+		{"always", []RuleHealthFlag{RuleHealthAlwaysFiring}},
+		{"new-never", nil},
+		{"few-evals", nil},
+		{"unevaluated", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ruleID, func(t *testing.T) {
+			entry := healthEntry(t, report, tt.ruleID)
+			if len(entry.Flags) != len(tt.flags) {
+				t.Fatalf("Expected flags %v, got %v", tt.flags, entry.Flags)
+			}
+			for _, flag := range tt.flags {
+				if !hasFlag(entry, flag) {
+					t.Errorf("Expected flag %s, got %v", flag, entry.Flags)
+				}
+			}
+		})
+	}
+
+	// Flagged rules are listed first
+	for i, entry := range report.Rules {
+		if i < report.Flagged && len(entry.Flags) == 0 {
+			t.Errorf("Expected flagged rules first, got unflagged %s at %d", entry.RuleID, i)
+		}
+	}
+
+	if entry := healthEntry(t, report, "always"); entry.MatchRatio != 0.99 {
+		t.Errorf("Expected match ratio 0.99, got %f", entry.MatchRatio)
+	}
+}
+
+func TestRuleStatsTracker(t *testing.T) {
+	tracker := NewRuleStatsTracker()
+	start := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tracker.Record("rule-1", 10, 0, start)
+	tracker.Record("rule-1", 10, 2, start.Add(time.Second))
+	tracker.Record("rule-2", 0, 0, start) // No evaluations, ignored
+
+	stats := tracker.Snapshot()
+	if len(stats) != 1 {
+		t.Fatalf("Expected stats for 1 rule, got %d", len(stats))
+	}
+
+	rule1 := stats["rule-1"]
+	if rule1.Evaluations != 20 || rule1.Matches != 2 {
+		t.Errorf("Expected 20 evaluations and 2 matches, got %d and %d", rule1.Evaluations, rule1.Matches)
+	}
+	if !rule1.FirstEvaluated.Equal(start) || !rule1.LastMatched.Equal(start.Add(time.Second)) {
+		t.Errorf("Unexpected timestamps: %+v", rule1)
+	}
+
+	tracker.Prune(map[string]rules.CompiledRule{})
+	if len(tracker.Snapshot()) != 0 {
+		t.Error("Expected stats of inactive rules to be pruned")
+	}
+}
+
+func TestScanLoop_RuleStats(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+
+	for _, rule := range []*models.Rule{
+		{ID: "rule-match", Name: "Price Above 100", Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}}, Enabled: true},
+		{ID: "rule-miss", Name: "Price Above 1000", Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 1000.0}}, Enabled: true},
+	} {
+		if err := ruleStore.AddRule(rule); err != nil {
+			t.Fatalf("Failed to add rule: %v", err)
+		}
+	}
+
+	sl := NewScanLoop(DefaultScanLoopConfig(), sm, ruleStore, rules.NewCompiler(nil), nil, &recordingAlertEmitter{}, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	now := time.Now()
+	for _, symbol := range []string{"AAPL", "MSFT"} {
+		tick := &models.Tick{Symbol: symbol, Price: 150.0, Size: 100, Timestamp: now, Type: "trade"}
+		if err := sm.UpdateLiveBar(symbol, tick); err != nil {
+			t.Fatalf("Failed to update live bar: %v", err)
+		}
+	}
+
+	sl.Scan()
+	sl.Scan()
+
+	stats := sl.GetRuleStats()
+	if s := stats["rule-match"]; s.Evaluations != 4 || s.Matches != 4 || s.LastMatched.IsZero() {
+		t.Errorf("Unexpected stats for matching rule: %+v", s)
+	}
+	if s := stats["rule-miss"]; s.Evaluations != 4 || s.Matches != 0 || !s.LastMatched.IsZero() {
+		t.Errorf("Unexpected stats for non-matching rule: %+v", s)
+	}
+
+	analyzer := NewRuleHealthAnalyzer(RuleHealthConfig{AlwaysFiringRatio: 0.95, MinEvaluations: 4}, ruleStore, sl.GetRuleStats)
+	report, err := analyzer.Report()
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if entry := healthEntry(t, report, "rule-match"); !hasFlag(entry, RuleHealthAlwaysFiring) {
+		t.Errorf("Expected matching rule flagged always_firing, got %v", entry.Flags)
+	}
+	if entry := healthEntry(t, report, "rule-miss"); len(entry.Flags) != 0 {
+		t.Errorf("Expected no flags for non-matching rule without a never-firing window, got %v", entry.Flags)
+	}
+}
@@ -0,0 +1,56 @@
+package scanner
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// ruleSampler limits rules with an evaluation interval to one evaluation per interval.
+// Expensive rules that don't need sub-second freshness skip the cycles in between.
+type ruleSampler struct {
+	mu            sync.Mutex
+	lastEvaluated map[string]time.Time // rule ID -> start of the last cycle the rule was evaluated in
+}
+
+// newRuleSampler creates an empty rule sampler
+func newRuleSampler() *ruleSampler {
+	return &ruleSampler{
+		lastEvaluated: make(map[string]time.Time),
+	}
+}
+
+// Skipped returns the IDs of rules that sit out the cycle starting at now, and records
+// now as the last evaluation of every sampled rule that is due
+func (s *ruleSampler) Skipped(ruleDetails map[string]*models.Rule, now time.Time) map[string]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var skipped map[string]bool
+	for ruleID, rule := range ruleDetails {
+		if rule.EvaluationInterval <= 0 {
+			continue
+		}
+
+		interval := time.Duration(rule.EvaluationInterval) * time.Second
+		last, ok := s.lastEvaluated[ruleID]
+		if ok && now.Sub(last) < interval {
+			if skipped == nil {
+				skipped = make(map[string]bool)
+			}
+			skipped[ruleID] = true
+			continue
+		}
+		s.lastEvaluated[ruleID] = now
+	}
+
+	// Forget rules that were removed or no longer sampled
+	for ruleID := range s.lastEvaluated {
+		if rule, ok := ruleDetails[ruleID]; !ok || rule.EvaluationInterval <= 0 {
+			delete(s.lastEvaluated, ruleID)
+		}
+	}
+
+	return skipped
+}
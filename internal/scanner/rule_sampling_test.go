@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+)
+
+func TestRuleSampler_Skipped(t *testing.T) {
+	s := newRuleSampler()
+	ruleDetails := map[string]*models.Rule{
+		"sampled": {ID: "sampled", EvaluationInterval: 5},
+		"every":   {ID: "every"},
+	}
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	for second := 0; second <= 10; second++ {
+		skipped := s.Skipped(ruleDetails, start.Add(time.Duration(second)*time.Second))
+		wantSkipped := second%5 != 0
+		if skipped["sampled"] != wantSkipped {
+			t.Errorf("At %ds: sampled rule skipped = %v, want %v", second, skipped["sampled"], wantSkipped)
+		}
+		if skipped["every"] {
+			t.Errorf("At %ds: rule without interval should never be skipped", second)
+		}
+	}
+
+	// Removed rules are forgotten
+	s.Skipped(map[string]*models.Rule{}, start)
+	if len(s.lastEvaluated) != 0 {
+		t.Errorf("Expected removed rules to be forgotten, got %d entries", len(s.lastEvaluated))
+	}
+}
+
+func TestScanLoop_EvaluationIntervalSkipsIntermediateCycles(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	rule := &models.Rule{
+		ID:   "rule-price",
+		Name: "Price Above 100",
+		Conditions: []models.Condition{
+			{Metric: "price", Operator: ">", Value: 100.0},
+		},
+		EvaluationInterval: 5,
+		Enabled:            true,
+	}
+	if err := ruleStore.AddRule(rule); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	clock := NewSimulationClock(start)
+	sl := NewScanLoop(DefaultScanLoopConfig(), sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	sl.SetClock(clock)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	tick := &models.Tick{Symbol: "AAPL", Price: 150.0, Size: 100, Timestamp: start, Type: "trade"}
+	if err := sm.UpdateLiveBar("AAPL", tick); err != nil {
+		t.Fatalf("Failed to update live bar: %v", err)
+	}
+
+	// One cycle per second: the rule is evaluated at 0s and again on the 5s boundary
+	want := []int{1, 1, 1, 1, 1, 2, 2}
+	for second, expected := range want {
+		clock.Observe(start.Add(time.Duration(second) * time.Second))
+		sl.Scan()
+		if got := len(emitter.alerts); got != expected {
+			t.Fatalf("After cycle at %ds: expected %d alerts, got %d", second, expected, got)
+		}
+	}
+
+	if got := sl.GetRuleStats()["rule-price"].Evaluations; got != 2 {
+		t.Errorf("Expected 2 evaluations, got %d", got)
+	}
+}
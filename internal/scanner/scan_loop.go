@@ -3,6 +3,7 @@ package scanner
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,6 +14,11 @@ import (
 	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
 )
 
+// CustomMetricSource lists every user's custom metrics (implemented by storage.CustomMetricStore)
+type CustomMetricSource interface {
+	ListMetrics(ctx context.Context) ([]*models.CustomMetric, error)
+}
+
 // CooldownTracker defines the interface for checking and managing rule cooldowns
 // This will be fully implemented in Phase 3.2.6
 type CooldownTracker interface {
@@ -20,6 +26,8 @@ type CooldownTracker interface {
 	IsOnCooldown(ruleID, symbol string) bool
 	// RecordCooldown records that a rule fired for a symbol (starts cooldown)
 	RecordCooldown(ruleID, symbol string, cooldownSeconds int)
+	// ClearCooldown resets the cooldown for a rule and symbol
+	ClearCooldown(ruleID, symbol string)
 }
 
 // AlertEmitter defines the interface for emitting alerts
@@ -35,6 +43,18 @@ type ScanLoopConfig struct {
 	MaxScanTime        time.Duration // Maximum time allowed for a scan cycle (default: 800ms)
 	MetricsPoolSize    int           // Size of metrics map pool (default: 100)
 	RuleReloadInterval time.Duration // How often to reload rules from store (default: 30 seconds)
+	LULDTiers          []metrics.LULDTier // LULD band tiers (default: metrics.DefaultLULDTiers)
+	ReferenceSymbols   []string           // Symbols kept for cross-symbol metrics (e.g. SPY), never alerted on
+	TrackedSymbols     []string           // Symbols exporting per-symbol Prometheus metrics (max MaxTrackedSymbols)
+	MaxDataStaleness   time.Duration      // Skip symbols whose state was last updated longer ago than this (0 = disabled)
+	MaxAlertMetrics    int                // Max metrics attached to an alert; rule-referenced metrics always included (0 = unlimited)
+	CoalesceCycles     int                // Suppress re-emitting a (rule, symbol) alert within this many scan cycles (0 = disabled)
+	ExplainAlerts      bool               // Attach per-condition actual vs threshold values to alert metadata
+	BreadthRules       []BreadthRule      // System rules emitting a breadth alert when enough symbols match within a window
+	BreadthTopSymbols  int                // Top contributing symbols listed in breadth alerts, by the triggering metric (0 = none)
+	SymbolTiers        []SymbolTier       // Liquidity tiers scanned at their own interval (untiered symbols are scanned every cycle)
+	RuleErrorLimit     int                // Disable a rule after this many consecutive evaluation errors (0 = never)
+	RuleErrorWindow    time.Duration      // Window the consecutive errors must occur within (0 = unbounded)
 }
 
 // DefaultScanLoopConfig returns default configuration
@@ -61,6 +81,7 @@ type ScanLoop struct {
 	wg                 sync.WaitGroup
 	mu                 sync.RWMutex
 	running            bool
+	paused             bool // Scan cycles are skipped while paused (state and consumers stay alive)
 	stats              ScanLoopStats
 
 	// Performance optimization: pool for metrics maps
@@ -71,15 +92,73 @@ type ScanLoop struct {
 
 	// Compiled rules cache (updated when rules change)
 	compiledRules map[string]rules.CompiledRule
+	compiledExits map[string]rules.CompiledRule // Exit conditions of rules that define them
+	ruleDetails   map[string]*models.Rule       // Rules behind compiledRules, so scans never hit the rule store
 	rulesMu       sync.RWMutex
 
 	// Required metrics for all active rules (for lazy computation)
 	requiredMetrics map[string]bool
 	requiredMetricsMu sync.RWMutex
+	// Whether active rules have crossing conditions (metrics are then kept for the next cycle)
+	tracksCrossings bool
 
 	// Rule reload tracking
 	lastRuleReload time.Time
 	lastReloadMu   sync.RWMutex
+
+	// Bar close tracking: finalized bar count seen in the previous scan cycle per symbol
+	barsSeen   map[string]int64
+	barsSeenMu sync.Mutex
+
+	// Reference symbols (metrics only, excluded from rule matching and alerts)
+	referenceSymbols map[string]bool
+	// Metrics required from each reference symbol by active rules
+	referenceMetrics map[string]map[string]bool
+
+	// Active alert state per (rule, symbol) for rules with exit conditions
+	activeAlerts   map[string]string // Active alert key -> severity of the entry alert
+	activeAlertsMu sync.Mutex
+
+	// Per-symbol Prometheus metrics for tracked symbols (nil = disabled)
+	symbolMetrics *SymbolMetrics
+
+	// Per-rule evaluation and match statistics (for rules health analysis)
+	ruleStats *RuleStatsTracker
+
+	// Symbol-level alert kill switch (nil = no mutes)
+	symbolMutes SymbolMuteChecker
+
+	// User custom metric definitions loaded into the compiler's registry on every rule reload (nil = none)
+	customMetricSource CustomMetricSource
+
+	// Time source for staleness, rule statistics and alert timestamps (event time in replays)
+	clock Clock
+
+	// Suppression of re-matches in adjacent scan cycles (nil = disabled)
+	coalescer *alertCoalescer
+	scanCycle int64 // Scan cycles started, used as the coalescing clock
+
+	// Market breadth aggregation of system rule matches (nil = disabled)
+	breadth *breadthAggregator
+
+	// Last evaluation time of rules with an evaluation interval
+	ruleSampler *ruleSampler
+
+	// Per-symbol scan cadence by liquidity tier (nil = every symbol scanned every cycle)
+	symbolScheduler *symbolScheduler
+
+	// Automatic disabling of rules failing to evaluate (nil = disabled)
+	ruleErrors           *ruleErrorTracker
+	ruleDisableNotifier  RuleDisableNotifier
+	disabledRuleRecorder DisabledRuleRecorder
+}
+
+// ruleCycleCounts holds a rule's evaluation, match and error counts within one scan cycle
+type ruleCycleCounts struct {
+	evaluations    int64
+	matches        int64
+	trailingErrors int64 // Errors since the rule's last successful evaluation in the cycle
+	lastErr        error
 }
 
 // ScanLoopStats holds statistics about the scan loop
@@ -89,11 +168,18 @@ type ScanLoopStats struct {
 	RulesEvaluated   int64
 	RulesMatched     int64
 	AlertsEmitted    int64
+	AlertsMuted      int64 // Alerts suppressed because their symbol was muted
+	AlertsCoalesced  int64 // Alerts suppressed because the rule fired for the symbol within CoalesceCycles
+	BreadthAlertsEmitted int64 // Breadth alerts emitted (also counted in AlertsEmitted)
+	SymbolsSkippedStale int64 // Symbol scans skipped because their data was older than MaxDataStaleness
+	SymbolsSkippedTier  int64 // Symbol scans skipped because their tier's scan interval had not elapsed
+	RulePanics          int64 // Rule evaluations that panicked and were skipped
 	ScanCycleTime    time.Duration // Last scan cycle time
 	MaxScanCycleTime time.Duration // Maximum scan cycle time observed
 	MinScanCycleTime time.Duration // Minimum scan cycle time observed
 	AvgScanCycleTime time.Duration // Average scan cycle time
 	ScanCycleTimeSum time.Duration // Sum of all scan cycle times (for average calculation)
+	Paused           bool          // Whether scanning is currently paused
 	mu               sync.RWMutex
 }
 
@@ -117,6 +203,11 @@ func NewScanLoop(
 		panic("compiler cannot be nil")
 	}
 
+	// Crossing conditions compare against the metrics of the symbol's previous scan
+	compiler.SetPreviousMetrics(stateManager.PreviousMetrics)
+	// Condition volume thresholds and session filters use the symbol's current session
+	compiler.SetSession(stateManager.CurrentSession)
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// Initialize metrics pool for performance
@@ -128,6 +219,16 @@ func NewScanLoop(
 
 	// Initialize metric registry
 	metricRegistry := metrics.NewRegistry()
+	if len(config.LULDTiers) > 0 {
+		for _, computer := range metrics.NewLULDComputers(config.LULDTiers) {
+			metricRegistry.Replace(computer)
+		}
+	}
+
+	symbolMetrics, err := NewSymbolMetrics(config.TrackedSymbols)
+	if err != nil {
+		logger.Error("Per-symbol metrics disabled", logger.ErrorField(err))
+	}
 
 	return &ScanLoop{
 		config:             config,
@@ -142,8 +243,22 @@ func NewScanLoop(
 		metricsPool:        metricsPool,
 		metricRegistry:     metricRegistry,
 		compiledRules:      make(map[string]rules.CompiledRule),
+		compiledExits:      make(map[string]rules.CompiledRule),
+		ruleDetails:        make(map[string]*models.Rule),
+		activeAlerts:       make(map[string]string),
 		requiredMetrics:    make(map[string]bool),
 		lastRuleReload:     time.Now(),
+		barsSeen:           make(map[string]int64),
+		referenceSymbols:   normalizeReferenceSymbols(config.ReferenceSymbols),
+		referenceMetrics:   make(map[string]map[string]bool),
+		symbolMetrics:      symbolMetrics,
+		ruleStats:          NewRuleStatsTracker(),
+		clock:              RealClock{},
+		coalescer:          newAlertCoalescer(config.CoalesceCycles),
+		breadth:            newBreadthAggregator(config.BreadthRules),
+		ruleSampler:        newRuleSampler(),
+		symbolScheduler:    newSymbolScheduler(config.SymbolTiers),
+		ruleErrors:         newRuleErrorTracker(config.RuleErrorLimit, config.RuleErrorWindow),
 		stats: ScanLoopStats{
 			MinScanCycleTime: time.Hour, // Initialize to large value
 		},
@@ -199,8 +314,37 @@ func (sl *ScanLoop) IsRunning() bool {
 	return sl.running
 }
 
+// Pause suspends rule evaluation without stopping the scan loop
+func (sl *ScanLoop) Pause() {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	if !sl.paused {
+		sl.paused = true
+		logger.Info("Scan loop paused")
+	}
+}
+
+// Resume restores rule evaluation after Pause
+func (sl *ScanLoop) Resume() {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	if sl.paused {
+		sl.paused = false
+		logger.Info("Scan loop resumed")
+	}
+}
+
+// IsPaused returns whether scanning is paused
+func (sl *ScanLoop) IsPaused() bool {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+	return sl.paused
+}
+
 // GetStats returns current scan loop statistics
 func (sl *ScanLoop) GetStats() ScanLoopStats {
+	paused := sl.IsPaused()
+
 	sl.stats.mu.RLock()
 	defer sl.stats.mu.RUnlock()
 
@@ -217,11 +361,75 @@ func (sl *ScanLoop) GetStats() ScanLoopStats {
 		RulesEvaluated:   sl.stats.RulesEvaluated,
 		RulesMatched:     sl.stats.RulesMatched,
 		AlertsEmitted:    sl.stats.AlertsEmitted,
+		AlertsMuted:      sl.stats.AlertsMuted,
+		AlertsCoalesced:  sl.stats.AlertsCoalesced,
+		BreadthAlertsEmitted: sl.stats.BreadthAlertsEmitted,
+		SymbolsSkippedStale: sl.stats.SymbolsSkippedStale,
+		SymbolsSkippedTier:  sl.stats.SymbolsSkippedTier,
+		RulePanics:          sl.stats.RulePanics,
 		ScanCycleTime:    sl.stats.ScanCycleTime,
 		MaxScanCycleTime: sl.stats.MaxScanCycleTime,
 		MinScanCycleTime: sl.stats.MinScanCycleTime,
 		AvgScanCycleTime: avgTime,
+		ScanCycleTimeSum: sl.stats.ScanCycleTimeSum,
+		Paused:           paused,
+	}
+}
+
+// GetRuleStats returns per-rule evaluation and match statistics keyed by rule ID
+func (sl *ScanLoop) GetRuleStats() map[string]RuleStats {
+	return sl.ruleStats.Snapshot()
+}
+
+// SetClock sets the time source for staleness checks, rule statistics and alert timestamps.
+// Replays share the state manager's SimulationClock so they follow event time.
+func (sl *ScanLoop) SetClock(clock Clock) {
+	if clock == nil {
+		clock = RealClock{}
 	}
+	sl.clock = clock
+}
+
+// SetSymbolMutes sets the symbol mute checker consulted before emitting alerts
+func (sl *ScanLoop) SetSymbolMutes(symbolMutes SymbolMuteChecker) {
+	sl.symbolMutes = symbolMutes
+}
+
+// SetCustomMetricSource sets where user custom metric definitions are loaded from. They are
+// reloaded with the rules, so rules using a changed definition pick it up at the next reload.
+// The compiler must have a custom metric registry.
+func (sl *ScanLoop) SetCustomMetricSource(source CustomMetricSource) {
+	sl.customMetricSource = source
+}
+
+// loadCustomMetrics loads the custom metric definitions into the compiler's registry,
+// keeping the last loaded definitions if the source is unavailable
+func (sl *ScanLoop) loadCustomMetrics() {
+	registry := sl.compiler.CustomMetricRegistry()
+	if sl.customMetricSource == nil || registry == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	definitions, err := sl.customMetricSource.ListMetrics(ctx)
+	if err != nil {
+		logger.Warn("Failed to load custom metrics, keeping previous definitions",
+			logger.ErrorField(err),
+		)
+		return
+	}
+	if err := registry.Load(definitions); err != nil {
+		logger.Warn("Skipped invalid custom metrics",
+			logger.ErrorField(err),
+		)
+	}
+}
+
+// isSymbolMuted returns true if all alerts for the symbol are muted
+func (sl *ScanLoop) isSymbolMuted(symbol string) bool {
+	return sl.symbolMutes != nil && sl.symbolMutes.IsMuted(symbol)
 }
 
 // ReloadRules reloads and recompiles rules from the rule store
@@ -253,6 +461,9 @@ func (sl *ScanLoop) run() {
 		case <-sl.ctx.Done():
 			return
 		case <-scanTicker.C:
+			if sl.IsPaused() {
+				continue
+			}
 			sl.Scan()
 		case <-ruleReloadChan:
 			// Periodically reload rules from store
@@ -267,6 +478,10 @@ func (sl *ScanLoop) run() {
 
 // Scan performs a single scan cycle (exported for testing)
 func (sl *ScanLoop) Scan() {
+	if sl.IsPaused() {
+		return
+	}
+
 	startTime := time.Now()
 
 	// Check if scan takes too long
@@ -288,16 +503,35 @@ func (sl *ScanLoop) Scan() {
 		return // No symbols to scan
 	}
 
+	// Event time for staleness and statistics (wall clock unless replaying)
+	now := sl.clock.Now()
+
+	cycle := atomic.AddInt64(&sl.scanCycle, 1)
+	sl.coalescer.Prune(cycle)
+
 	// Get compiled rules (read lock)
 	sl.rulesMu.RLock()
 	compiledRules := sl.compiledRules
+	compiledExits := sl.compiledExits
+	ruleDetails := sl.ruleDetails
 	sl.rulesMu.RUnlock()
 
+	// Rules with an evaluation interval sit out cycles until the interval has elapsed
+	sampledOut := sl.ruleSampler.Skipped(ruleDetails, now)
+
+	// Compute reference symbol metrics once per cycle for cross-symbol conditions
+	referenceValues := sl.computeReferenceMetrics(snapshot)
+
+	sl.requiredMetricsMu.RLock()
+	tracksCrossings := sl.tracksCrossings
+	sl.requiredMetricsMu.RUnlock()
+
 	// Scan each symbol
 	symbolsScanned := int64(0)
 	rulesEvaluated := int64(0)
 	rulesMatched := int64(0)
 	alertsEmitted := int64(0)
+	ruleCounts := make(map[string]*ruleCycleCounts, len(compiledRules))
 
 	for _, symbol := range snapshot.Symbols {
 		symbolState := snapshot.States[symbol]
@@ -305,51 +539,96 @@ func (sl *ScanLoop) Scan() {
 			continue
 		}
 
+		// Reference symbols are never alert targets
+		if sl.referenceSymbols[symbol] {
+			continue
+		}
+
+		// Rules must not fire on stale data (e.g. during a feed hiccup)
+		if sl.isStale(symbolState, now) {
+			atomic.AddInt64(&sl.stats.SymbolsSkippedStale, 1)
+			continue
+		}
+
+		// Lower liquidity tiers are scanned less often than every cycle
+		if !sl.symbolScheduler.Due(symbolState, now) {
+			atomic.AddInt64(&sl.stats.SymbolsSkippedTier, 1)
+			continue
+		}
+
 		symbolsScanned++
+		sl.symbolMetrics.RecordScan(symbol)
+		symbolMatched := 0
 
 		// Get metrics for this symbol (computed from snapshot, no lock needed)
 		// Only compute metrics that are actually needed by active rules
 		metrics := sl.getMetricsFromSnapshot(symbolState, sl.getRequiredMetrics())
 
-		// Get current session for this symbol (as string to avoid import cycle)
-		currentSession := string(symbolState.CurrentSession)
+		// Expose reference symbol metrics (e.g. ref_SPY_price_change_5m_pct)
+		for name, value := range referenceValues {
+			metrics[name] = value
+		}
+
+		// Bar-close rules are only evaluated in the cycle following a finalized bar
+		barClosed := sl.consumeBarClosed(symbolState)
 
 		// Evaluate each rule (if any rules exist)
 		for ruleID, compiledRule := range compiledRules {
+			if sampledOut[ruleID] {
+				continue
+			}
 			rulesEvaluated++
 
-			// Get rule details for filter configuration checks
-			rule, err := sl.ruleStore.GetRule(ruleID)
-			if err != nil {
-				logger.Error("Failed to get rule details for filter checks",
-					logger.ErrorField(err),
-					logger.String("rule_id", ruleID),
-				)
+			// Rule details are cached with the compiled rules
+			rule, ok := ruleDetails[ruleID]
+			if !ok {
 				continue
 			}
 
-			// Pre-filter: Check volume threshold and session for all conditions
-			shouldEvaluate := sl.shouldEvaluateRule(rule, metrics, currentSession)
-			if !shouldEvaluate {
-				continue // Pre-filter failed, skip rule evaluation
+			if rule.EvaluatesOnBarClose() && !barClosed {
+				continue // No new finalized bar since the last cycle
+			}
+
+			// While an alert is active, only the exit conditions are evaluated
+			if compiledExit, ok := compiledExits[ruleID]; ok && sl.isAlertActive(ruleID, symbol) {
+				if sl.evaluateExit(rule, compiledExit, symbol, metrics, symbolState) {
+					alertsEmitted++
+				}
+				continue
 			}
 
 			// Evaluate rule
-			matched, err := compiledRule(symbol, metrics)
+			matched, err := sl.evaluateCompiled(ruleID, compiledRule, symbol, metrics)
+			counts := ruleCounts[ruleID]
+			if counts == nil {
+				counts = &ruleCycleCounts{}
+				ruleCounts[ruleID] = counts
+			}
 			if err != nil {
 				logger.Error("Failed to evaluate rule",
 					logger.ErrorField(err),
 					logger.String("rule_id", ruleID),
 					logger.String("symbol", symbol),
 				)
+				counts.trailingErrors++
+				counts.lastErr = err
 				continue
 			}
+			counts.evaluations++
+			counts.trailingErrors = 0
 
 			if !matched {
 				continue // Rule didn't match, move to next rule
 			}
 
+			counts.matches++
 			rulesMatched++
+			symbolMatched++
+
+			// Breadth counts every matching symbol, regardless of per-symbol alert suppression
+			if rule.UserID == "" {
+				sl.breadth.Record(ruleID, symbol, breadthMetricValue(rule, metrics), now)
+			}
 
 			// Check cooldown
 			if sl.cooldownTracker != nil && sl.cooldownTracker.IsOnCooldown(ruleID, symbol) {
@@ -360,11 +639,21 @@ func (sl *ScanLoop) Scan() {
 				continue
 			}
 
-			// Rule already retrieved above for filter checks, reuse it
+			// Muted symbols produce no alerts (and record no cooldown)
+			if sl.isSymbolMuted(symbol) {
+				atomic.AddInt64(&sl.stats.AlertsMuted, 1)
+				continue
+			}
+
+			// Re-matches shortly after an emitted alert are coalesced into it
+			if sl.coalescer.Suppress(ruleID, symbol, cycle) {
+				atomic.AddInt64(&sl.stats.AlertsCoalesced, 1)
+				continue
+			}
 
 			// Emit alert
 			if sl.alertEmitter != nil {
-				alert := sl.createAlert(rule, symbol, metrics, symbolState)
+				alert := sl.createAlert(rule, rule.EntryGroup(), symbol, metrics, symbolState)
 				if err := sl.alertEmitter.EmitAlert(alert); err != nil {
 					logger.Error("Failed to emit alert",
 						logger.ErrorField(err),
@@ -375,14 +664,27 @@ func (sl *ScanLoop) Scan() {
 				}
 
 				alertsEmitted++
+				sl.coalescer.Record(ruleID, symbol, cycle)
 
-				// Record cooldown (using global cooldown, cooldownSeconds parameter is ignored)
+				// Record the rule's cooldown (0 = none)
 				if sl.cooldownTracker != nil {
-					sl.cooldownTracker.RecordCooldown(ruleID, symbol, 0)
+					sl.cooldownTracker.RecordCooldown(ruleID, symbol, rule.Cooldown)
+				}
+
+				// Track the active alert until the exit conditions match
+				if _, ok := compiledExits[ruleID]; ok {
+					sl.setAlertActive(ruleID, symbol, alert.Severity)
 				}
 			}
 		}
 
+		sl.symbolMetrics.RecordMatches(symbol, symbolMatched)
+
+		// Keep this cycle's metrics for crossing conditions in the symbol's next scan
+		if tracksCrossings {
+			sl.stateManager.SetPreviousMetrics(symbol, metrics)
+		}
+
 		// Update toplists if integration is enabled
 		if sl.toplistIntegration != nil {
 			// Create a copy of metrics for toplist update (since we'll return metrics to pool)
@@ -403,6 +705,10 @@ func (sl *ScanLoop) Scan() {
 		sl.returnMetricsToPool(metrics)
 	}
 
+	// Forget symbols that have not been scanned for longer than any tier interval
+	sl.symbolScheduler.Prune(now)
+	sl.pruneBarsSeen(snapshot.States)
+
 	// Publish toplist updates after scan cycle
 	if sl.toplistIntegration != nil {
 		if err := sl.toplistIntegration.PublishUpdates(sl.ctx); err != nil {
@@ -412,6 +718,23 @@ func (sl *ScanLoop) Scan() {
 		}
 	}
 
+	// Emit breadth alerts for system rules that matched enough symbols within their window
+	for _, crossing := range sl.breadth.Evaluate(sl.ctx, now) {
+		if sl.emitBreadthAlert(ruleDetails[crossing.rule.RuleID], crossing, now) {
+			alertsEmitted++
+		}
+	}
+
+	// Update per-rule statistics once per cycle
+	for ruleID, counts := range ruleCounts {
+		sl.ruleStats.Record(ruleID, counts.evaluations, counts.matches, now)
+	}
+
+	// Disable rules that keep failing to evaluate
+	for ruleID, counts := range ruleCounts {
+		sl.recordRuleErrors(ruleID, counts, ruleDetails[ruleID], now)
+	}
+
 	// Update statistics
 	atomic.AddInt64(&sl.stats.SymbolsScanned, symbolsScanned)
 	atomic.AddInt64(&sl.stats.RulesEvaluated, rulesEvaluated)
@@ -419,6 +742,76 @@ func (sl *ScanLoop) Scan() {
 	atomic.AddInt64(&sl.stats.AlertsEmitted, alertsEmitted)
 }
 
+// computeReferenceMetrics computes the reference symbol metrics required by active rules,
+// keyed by their reference metric name
+func (sl *ScanLoop) computeReferenceMetrics(snapshot *StateSnapshot) map[string]float64 {
+	sl.requiredMetricsMu.RLock()
+	required := sl.referenceMetrics
+	sl.requiredMetricsMu.RUnlock()
+
+	return sl.referenceMetricValues(snapshot, required)
+}
+
+// referenceMetricValues computes the given metrics of each reference symbol, keyed by their
+// reference metric name
+func (sl *ScanLoop) referenceMetricValues(snapshot *StateSnapshot, required map[string]map[string]bool) map[string]float64 {
+	if len(required) == 0 {
+		return nil
+	}
+
+	values := make(map[string]float64)
+	for symbol, metricNames := range required {
+		symbolState := snapshot.States[symbol]
+		if symbolState == nil {
+			continue // Reference symbol has no data yet
+		}
+
+		metrics := sl.getMetricsFromSnapshot(symbolState, metricNames)
+		for name := range metricNames {
+			if value, ok := metrics[name]; ok {
+				values[ReferenceMetricName(symbol, name)] = value
+			}
+		}
+		sl.returnMetricsToPool(metrics)
+	}
+
+	return values
+}
+
+// isStale returns true if the symbol's state is older than the configured max staleness
+func (sl *ScanLoop) isStale(snapshot *SymbolStateSnapshot, now time.Time) bool {
+	if sl.config.MaxDataStaleness <= 0 {
+		return false
+	}
+	return now.Sub(snapshot.LastUpdate) > sl.config.MaxDataStaleness
+}
+
+// consumeBarClosed returns whether a bar was finalized for the symbol since the previous
+// scan cycle, and marks it as seen
+func (sl *ScanLoop) consumeBarClosed(snapshot *SymbolStateSnapshot) bool {
+	sl.barsSeenMu.Lock()
+	defer sl.barsSeenMu.Unlock()
+
+	seen, exists := sl.barsSeen[snapshot.Symbol]
+	sl.barsSeen[snapshot.Symbol] = snapshot.FinalizedBarCount
+	if !exists {
+		return snapshot.FinalizedBarCount > 0
+	}
+	return snapshot.FinalizedBarCount != seen
+}
+
+// pruneBarsSeen forgets the bar counts of symbols no longer in the state manager
+func (sl *ScanLoop) pruneBarsSeen(states map[string]*SymbolStateSnapshot) {
+	sl.barsSeenMu.Lock()
+	defer sl.barsSeenMu.Unlock()
+
+	for symbol := range sl.barsSeen {
+		if states[symbol] == nil {
+			delete(sl.barsSeen, symbol)
+		}
+	}
+}
+
 // getRequiredMetrics returns the set of metrics required by active rules
 func (sl *ScanLoop) getRequiredMetrics() map[string]bool {
 	sl.requiredMetricsMu.RLock()
@@ -484,7 +877,11 @@ func (sl *ScanLoop) getMetricsFromSnapshot(snapshot *SymbolStateSnapshot, requir
 		PremarketVolume:  snapshot.PremarketVolume,
 		MarketVolume:     snapshot.MarketVolume,
 		PostmarketVolume: snapshot.PostmarketVolume,
+		SessionVWAPNum:    snapshot.SessionVWAPNum,
+		SessionVWAPVolume: snapshot.SessionVWAPVolume,
 		TradeCount:       snapshot.TradeCount,
+		LULDUpper:        snapshot.LULDUpper,
+		LULDLower:        snapshot.LULDLower,
 	}
 
 	// Copy trade count history
@@ -531,6 +928,9 @@ func (sl *ScanLoop) returnMetricsToPool(metrics map[string]float64) {
 
 // reloadRules reloads rules from store and recompiles them
 func (sl *ScanLoop) reloadRules() error {
+	// Custom metrics first, so rules compile with the current definitions
+	sl.loadCustomMetrics()
+
 	// Get all rules and filter enabled ones
 	allRules, err := sl.ruleStore.GetAllRules()
 	if err != nil {
@@ -550,19 +950,47 @@ func (sl *ScanLoop) reloadRules() error {
 	if err != nil {
 		return fmt.Errorf("failed to compile rules: %w", err)
 	}
+	compiledExits, err := sl.compiler.CompileExitRules(enabledRules)
+	if err != nil {
+		return fmt.Errorf("failed to compile exit conditions: %w", err)
+	}
 
-	// Extract required metrics from enabled rules
-	requiredMetrics := rules.ExtractRequiredMetrics(enabledRules)
+	// Extract required metrics from enabled rules, separating reference symbol metrics
+	requiredMetrics, referenceMetrics := splitReferenceMetrics(rules.ExtractRequiredMetricsWithCustom(enabledRules, sl.compiler.CustomMetricRegistry()), sl.referenceSymbols)
+	tracksCrossings := false
+	for _, rule := range enabledRules {
+		if rules.UsesCrossing(rule) {
+			tracksCrossings = true
+			break
+		}
+	}
+
+	ruleDetails := make(map[string]*models.Rule, len(compiled))
+	for _, rule := range enabledRules {
+		if _, ok := compiled[rule.ID]; ok {
+			ruleDetails[rule.ID] = rule
+		}
+	}
 
 	// Update compiled rules cache and required metrics (write lock)
 	sl.rulesMu.Lock()
 	oldCount := len(sl.compiledRules)
 	sl.compiledRules = compiled
+	sl.compiledExits = compiledExits
+	sl.ruleDetails = ruleDetails
 	sl.rulesMu.Unlock()
 
+	// Drop active alert state for rules that no longer have exit conditions
+	sl.pruneActiveAlerts(compiledExits)
+
+	// Drop statistics of removed or disabled rules
+	sl.ruleStats.Prune(compiled)
+
 	// Update required metrics
 	sl.requiredMetricsMu.Lock()
 	sl.requiredMetrics = requiredMetrics
+	sl.referenceMetrics = referenceMetrics
+	sl.tracksCrossings = tracksCrossings
 	sl.requiredMetricsMu.Unlock()
 
 	// Update last reload time
@@ -585,32 +1013,115 @@ func (sl *ScanLoop) reloadRules() error {
 	return nil
 }
 
-// shouldEvaluateRule checks if a rule should be evaluated based on filter configuration
-// Returns true if volume threshold and session filters pass
-func (sl *ScanLoop) shouldEvaluateRule(rule *models.Rule, metrics map[string]float64, currentSession string) bool {
-	// Check each condition's filter configuration
-	for _, cond := range rule.Conditions {
-		// Check volume threshold
-		if cond.VolumeThreshold != nil && *cond.VolumeThreshold > 0 {
-			if !rules.CheckVolumeThreshold(metrics, cond.VolumeThreshold) {
-				return false // Volume threshold not met
-			}
+// activeAlertKey returns the active alert state key for a rule and symbol
+func activeAlertKey(ruleID, symbol string) string {
+	return ruleID + "|" + symbol
+}
+
+// isAlertActive returns whether a rule has an active (not yet exited) alert for a symbol
+func (sl *ScanLoop) isAlertActive(ruleID, symbol string) bool {
+	sl.activeAlertsMu.Lock()
+	defer sl.activeAlertsMu.Unlock()
+	_, active := sl.activeAlerts[activeAlertKey(ruleID, symbol)]
+	return active
+}
+
+// activeAlertSeverity returns the severity of a rule's active entry alert for a symbol
+// (empty if none is active)
+func (sl *ScanLoop) activeAlertSeverity(ruleID, symbol string) string {
+	sl.activeAlertsMu.Lock()
+	defer sl.activeAlertsMu.Unlock()
+	return sl.activeAlerts[activeAlertKey(ruleID, symbol)]
+}
+
+// setAlertActive records the active alert of a rule for a symbol with its entry severity
+func (sl *ScanLoop) setAlertActive(ruleID, symbol, severity string) {
+	sl.activeAlertsMu.Lock()
+	defer sl.activeAlertsMu.Unlock()
+	sl.activeAlerts[activeAlertKey(ruleID, symbol)] = severity
+}
+
+// clearAlertActive clears the active alert state for a rule and symbol
+func (sl *ScanLoop) clearAlertActive(ruleID, symbol string) {
+	sl.activeAlertsMu.Lock()
+	defer sl.activeAlertsMu.Unlock()
+	delete(sl.activeAlerts, activeAlertKey(ruleID, symbol))
+}
+
+// pruneActiveAlerts removes active alert state for rules without compiled exit conditions
+func (sl *ScanLoop) pruneActiveAlerts(compiledExits map[string]rules.CompiledRule) {
+	sl.activeAlertsMu.Lock()
+	defer sl.activeAlertsMu.Unlock()
+	for key := range sl.activeAlerts {
+		ruleID := key[:strings.LastIndex(key, "|")]
+		if _, ok := compiledExits[ruleID]; !ok {
+			delete(sl.activeAlerts, key)
 		}
+	}
+}
 
-		// Check session filter
-		if cond.CalculatedDuring != "" && cond.CalculatedDuring != "all" {
-			if !rules.CheckSessionFilter(currentSession, cond.CalculatedDuring) {
-				return false // Session filter not met
-			}
+// evaluateExit evaluates a rule's exit conditions for a symbol with an active alert.
+// When they match, an exit alert is emitted, the active state is cleared and the cooldown
+// is reset so the rule can trigger again. Returns true if an exit alert was emitted.
+func (sl *ScanLoop) evaluateExit(
+	rule *models.Rule,
+	compiledExit rules.CompiledRule,
+	symbol string,
+	metrics map[string]float64,
+	snapshot *SymbolStateSnapshot,
+) bool {
+	matched, err := sl.evaluateCompiled(rule.ID, compiledExit, symbol, metrics)
+	if err != nil {
+		logger.Error("Failed to evaluate rule exit conditions",
+			logger.ErrorField(err),
+			logger.String("rule_id", rule.ID),
+			logger.String("symbol", symbol),
+		)
+		return false
+	}
+	if !matched {
+		return false
+	}
+
+	// Keep the alert active while muted so the exit is delivered once unmuted
+	if sl.isSymbolMuted(symbol) {
+		atomic.AddInt64(&sl.stats.AlertsMuted, 1)
+		return false
+	}
+
+	if sl.alertEmitter != nil {
+		alert := sl.createAlert(rule, models.ConditionGroup{Conditions: rule.ExitConditions}, symbol, metrics, snapshot)
+		alert.Type = models.AlertTypeExit
+		alert.Message = fmt.Sprintf("Rule '%s' exit conditions matched for %s", rule.Name, symbol)
+		alert.Metadata["alert_type"] = models.AlertTypeExit
+		// The exit carries the entry's severity, so sinks keying alerts by their labels (such
+		// as Alertmanager) resolve the alert the entry opened
+		if severity := sl.activeAlertSeverity(rule.ID, symbol); severity != "" {
+			alert.Severity = severity
 		}
+		if err := sl.alertEmitter.EmitAlert(alert); err != nil {
+			logger.Error("Failed to emit exit alert",
+				logger.ErrorField(err),
+				logger.String("rule_id", rule.ID),
+				logger.String("symbol", symbol),
+			)
+			return false
+		}
+	}
+
+	sl.clearAlertActive(rule.ID, symbol)
+	if sl.cooldownTracker != nil {
+		sl.cooldownTracker.ClearCooldown(rule.ID, symbol)
 	}
 
-	return true // All pre-filters passed
+	return sl.alertEmitter != nil
 }
 
-// createAlert creates an alert from a matched rule
+// createAlert creates an alert from a matched rule; group holds the conditions that matched
+// (the rule's entry group or its exit conditions) and is used to classify and explain the alert
 func (sl *ScanLoop) createAlert(
 	rule *models.Rule,
+	group models.ConditionGroup,
 	symbol string,
 	metrics map[string]float64,
 	snapshot *SymbolStateSnapshot,
@@ -624,28 +1135,118 @@ func (sl *ScanLoop) createAlert(
 	}
 
 	// Generate alert ID (simple UUID-like, will be improved in Phase 3.2.7)
-	alertID := fmt.Sprintf("%s-%s-%d", rule.ID, symbol, time.Now().UnixNano())
+	now := sl.clock.Now()
+	alertID := fmt.Sprintf("%s-%s-%d", rule.ID, symbol, now.UnixNano())
 
 	// Create alert message
 	message := fmt.Sprintf("Rule '%s' matched for %s", rule.Name, symbol)
 
+	// Bound the metrics payload when many custom/window metrics are computed
+	alertMetrics, truncated := capAlertMetrics(rule, metrics, sl.config.MaxAlertMetrics)
+
 	// Create alert
 	alert := &models.Alert{
 		ID:        alertID,
 		RuleID:    rule.ID,
 		RuleName:  rule.Name,
 		Symbol:    symbol,
-		Timestamp: time.Now(),
+		Timestamp: now,
 		Price:     price,
 		Message:   message,
 		Metadata: map[string]interface{}{
-			"metrics": metrics,
+			"metrics": alertMetrics,
 		},
+		Priority: rule.Priority,
+		Delivery: rule.Delivery,
+	}
+	if truncated > 0 {
+		alert.Metadata["metrics_truncated"] = truncated
 	}
 
+	// Alerts of a user rule are addressed to its owner, so the owner's preferences and
+	// webhooks apply to them
+	if rule.UserID != "" {
+		alert.Metadata[models.AlertMetadataUserID] = rule.UserID
+	}
+
+	// Rules with exit conditions emit paired entry/exit alerts
+	if rule.HasExitConditions() {
+		alert.Type = models.AlertTypeEntry
+	}
+
+	// The alert service composes the dedup key from the rule's configuration
+	if rule.DedupKey != nil {
+		alert.Metadata[models.AlertMetadataDedupKey] = rule.DedupKey
+	}
+
+	sl.classifyAlert(alert, rule, group, metrics)
+
 	return alert
 }
 
+// emitBreadthAlert emits a market-wide alert for a breadth rule crossing its threshold.
+// rule is nil if the rule was disabled or deleted since its symbols matched.
+func (sl *ScanLoop) emitBreadthAlert(rule *models.Rule, crossing breadthCrossing, now time.Time) bool {
+	if sl.alertEmitter == nil {
+		return false
+	}
+
+	ruleID := crossing.rule.RuleID
+	ruleName := ruleID
+	priority := 0
+	if rule != nil {
+		ruleName = rule.Name
+		priority = rule.Priority
+	}
+
+	alert := &models.Alert{
+		ID:        fmt.Sprintf("breadth-%s-%d", ruleID, now.UnixNano()),
+		RuleID:    ruleID,
+		RuleName:  ruleName,
+		Symbol:    models.BreadthAlertSymbol,
+		Type:      models.AlertTypeBreadth,
+		Timestamp: now,
+		Message: fmt.Sprintf("%d symbols matched rule '%s' within %s",
+			len(crossing.symbols), ruleName, crossing.rule.Window),
+		Metadata: map[string]interface{}{
+			"breadth_count":     len(crossing.symbols),
+			"breadth_threshold": crossing.rule.Threshold,
+			"breadth_window":    crossing.rule.Window.String(),
+			"breadth_symbols":   crossing.symbols,
+		},
+		Priority: priority,
+	}
+	if top := topBreadthContributors(rule, crossing, sl.config.BreadthTopSymbols); len(top) > 0 {
+		metric, _ := breadthRankMetric(rule)
+		alert.Metadata["breadth_top_metric"] = metric
+		alert.Metadata["breadth_top_symbols"] = top
+	}
+	if err := sl.alertEmitter.EmitAlert(alert); err != nil {
+		logger.Error("Failed to emit breadth alert",
+			logger.ErrorField(err),
+			logger.String("rule_id", ruleID),
+		)
+		return false
+	}
+
+	atomic.AddInt64(&sl.stats.BreadthAlertsEmitted, 1)
+	return true
+}
+
+// classifyAlert sets the alert's severity from how far its matched conditions were exceeded
+// and, when explain mode is enabled, attaches the condition group with each condition's actual
+// vs threshold values to the alert's metadata
+func (sl *ScanLoop) classifyAlert(alert *models.Alert, rule *models.Rule, group models.ConditionGroup, metrics map[string]float64) {
+	alert.Severity = sl.compiler.AlertSeverity(rule, group.AllConditions(), alert.Symbol, metrics)
+
+	if !sl.config.ExplainAlerts {
+		return
+	}
+	explanation := sl.compiler.ExplainGroup(rule, group, metrics)
+	alert.Metadata["explanation"] = explanation.String()
+	alert.Metadata["conditions"] = explanation
+}
+
 // updateStats updates scan loop statistics
 func (sl *ScanLoop) updateStats(scanTime time.Duration) {
 	sl.stats.mu.Lock()
@@ -0,0 +1,914 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+)
+
+// compileWithSession compiles a rule's entry conditions with a fixed market session
+func compileWithSession(t *testing.T, rule *models.Rule, session *string) rules.CompiledRule {
+	t.Helper()
+	compiler := rules.NewCompiler(nil)
+	compiler.SetSession(func(string) string { return *session })
+	compiled, err := compiler.CompileRule(rule)
+	if err != nil {
+		t.Fatalf("Failed to compile rule: %v", err)
+	}
+	return compiled
+}
+
+// matches evaluates a compiled rule, failing the test on error
+func matches(t *testing.T, compiled rules.CompiledRule, metrics map[string]float64) bool {
+	t.Helper()
+	matched, err := compiled("AAPL", metrics)
+	if err != nil {
+		t.Fatalf("Failed to evaluate rule: %v", err)
+	}
+	return matched
+}
+
+func TestCompiledRule_SessionVolumeThresholds(t *testing.T) {
+	fallback := int64(200000)
+
+	rule := &models.Rule{
+		ID:      "rule-1",
+		Name:    "Liquid RSI",
+		Enabled: true,
+		Conditions: []models.Condition{
+			{
+				Metric:          "rsi_14",
+				Operator:        ">",
+				Value:           70.0,
+				VolumeThreshold: &fallback,
+				SessionVolumeThresholds: map[string]int64{
+					"premarket": 20000,
+					"market":    1000000,
+				},
+			},
+		},
+	}
+
+	metrics := map[string]float64{
+		"rsi_14":           75.0,
+		"premarket_volume": 50000,
+		"volume_daily":     500000,
+	}
+	session := "premarket"
+	compiled := compileWithSession(t, rule, &session)
+
+	// Premarket threshold (20k) applies during premarket
+	if !matches(t, compiled, metrics) {
+		t.Error("Expected rule to pass premarket volume threshold during premarket")
+	}
+
+	// Regular threshold (1M) applies during market
+	session = "market"
+	if matches(t, compiled, metrics) {
+		t.Error("Expected rule to fail market volume threshold during market")
+	}
+
+	// No postmarket entry - falls back to the single threshold (200k)
+	session = "postmarket"
+	if !matches(t, compiled, metrics) {
+		t.Error("Expected rule to fall back to volume_threshold during postmarket")
+	}
+}
+
+func TestCompiledRule_ConditionGroupFilters(t *testing.T) {
+	threshold := int64(1000000)
+
+	// rsi_14 < 30 OR (price_change_5m_pct > 5 with a 1M volume threshold)
+	rule := &models.Rule{
+		ID:            "rule-1",
+		Name:          "Oversold or Liquid Momentum",
+		LogicOperator: models.LogicOr,
+		Enabled:       true,
+		Conditions: []models.Condition{
+			{Metric: "rsi_14", Operator: "<", Value: 30.0},
+		},
+		ConditionGroups: []models.ConditionGroup{
+			{
+				Conditions: []models.Condition{
+					{Metric: "price_change_5m_pct", Operator: ">", Value: 5.0, VolumeThreshold: &threshold},
+				},
+			},
+		},
+	}
+	session := "market"
+
+	// Only one branch has a volume threshold, the other can still match
+	thinOversold := map[string]float64{"rsi_14": 25.0, "price_change_5m_pct": 0.0, "volume_daily": 50000}
+	if !matches(t, compileWithSession(t, rule, &session), thinOversold) {
+		t.Error("Expected OR rule to match on the branch without a volume threshold")
+	}
+
+	// The filtered branch cannot match through the other branch's filters passing
+	thinMomentum := map[string]float64{"rsi_14": 50.0, "price_change_5m_pct": 8.0, "volume_daily": 50000}
+	if matches(t, compileWithSession(t, rule, &session), thinMomentum) {
+		t.Error("Expected OR rule not to match on a branch failing its volume threshold")
+	}
+	liquidMomentum := map[string]float64{"rsi_14": 50.0, "price_change_5m_pct": 8.0, "volume_daily": 2000000}
+	if !matches(t, compileWithSession(t, rule, &session), liquidMomentum) {
+		t.Error("Expected OR rule to match on a branch passing its volume threshold")
+	}
+
+	// With AND logic the volume threshold applies to the whole rule
+	rule.LogicOperator = models.LogicAnd
+	thinBoth := map[string]float64{"rsi_14": 25.0, "price_change_5m_pct": 8.0, "volume_daily": 50000}
+	if matches(t, compileWithSession(t, rule, &session), thinBoth) {
+		t.Error("Expected AND rule not to match when a condition fails its volume threshold")
+	}
+
+	// A session filter only fails its own branch
+	rule.LogicOperator = models.LogicOr
+	rule.Conditions[0].CalculatedDuring = "premarket"
+	if matches(t, compileWithSession(t, rule, &session), thinOversold) {
+		t.Error("Expected OR rule not to match on a branch outside its session")
+	}
+}
+
+// recordingAlertEmitter records emitted alerts
+type recordingAlertEmitter struct {
+	alerts []*models.Alert
+}
+
+func (e *recordingAlertEmitter) EmitAlert(alert *models.Alert) error {
+	e.alerts = append(e.alerts, alert)
+	return nil
+}
+
+func TestScanLoop_BarCloseRule(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	rule := &models.Rule{
+		ID:         "rule-bar-close",
+		Name:       "Price Above 100 On Close",
+		EvaluateOn: models.RuleEvaluateOnBarClose,
+		Conditions: []models.Condition{
+			{Metric: "price", Operator: ">", Value: 100.0},
+		},
+		Enabled: true,
+	}
+	if err := ruleStore.AddRule(rule); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	sl := NewScanLoop(DefaultScanLoopConfig(), sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	now := time.Now()
+	tick := &models.Tick{Symbol: "AAPL", Price: 150.0, Size: 100, Timestamp: now, Type: "trade"}
+
+	// Live bar updates do not trigger a bar-close rule
+	if err := sm.UpdateLiveBar("AAPL", tick); err != nil {
+		t.Fatalf("Failed to update live bar: %v", err)
+	}
+	sl.Scan()
+	if len(emitter.alerts) != 0 {
+		t.Fatalf("Expected no alerts on live bar update, got %d", len(emitter.alerts))
+	}
+
+	// Finalized bar triggers evaluation in the next cycle
+	bar := &models.Bar1m{Symbol: "AAPL", Timestamp: now, Open: 149.0, High: 151.0, Low: 148.0, Close: 150.0, Volume: 1000}
+	if err := sm.UpdateFinalizedBar(bar); err != nil {
+		t.Fatalf("Failed to update finalized bar: %v", err)
+	}
+	sl.Scan()
+	if len(emitter.alerts) != 1 {
+		t.Fatalf("Expected 1 alert after bar close, got %d", len(emitter.alerts))
+	}
+
+	// Following cycles with only live updates do not re-evaluate
+	tick.Price = 152.0
+	if err := sm.UpdateLiveBar("AAPL", tick); err != nil {
+		t.Fatalf("Failed to update live bar: %v", err)
+	}
+	sl.Scan()
+	if len(emitter.alerts) != 1 {
+		t.Errorf("Expected no new alerts until the next bar close, got %d", len(emitter.alerts))
+	}
+
+	// Removed symbols are forgotten
+	msft := &models.Tick{Symbol: "MSFT", Price: 50.0, Size: 100, Timestamp: now, Type: "trade"}
+	if err := sm.UpdateLiveBar("MSFT", msft); err != nil {
+		t.Fatalf("Failed to update live bar: %v", err)
+	}
+	sm.RemoveSymbol("AAPL")
+	sl.Scan()
+	if _, ok := sl.barsSeen["AAPL"]; ok {
+		t.Errorf("Expected bar counts of removed symbols to be pruned, got %v", sl.barsSeen)
+	}
+}
+
+func TestScanLoop_TickRuleEvaluatesEveryCycle(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	rule := &models.Rule{
+		ID:   "rule-tick",
+		Name: "Price Above 100",
+		Conditions: []models.Condition{
+			{Metric: "price", Operator: ">", Value: 100.0},
+		},
+		Enabled: true,
+	}
+	if err := ruleStore.AddRule(rule); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	sl := NewScanLoop(DefaultScanLoopConfig(), sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	tick := &models.Tick{Symbol: "AAPL", Price: 150.0, Size: 100, Timestamp: time.Now(), Type: "trade"}
+	if err := sm.UpdateLiveBar("AAPL", tick); err != nil {
+		t.Fatalf("Failed to update live bar: %v", err)
+	}
+	sl.Scan()
+	sl.Scan()
+
+	if len(emitter.alerts) != 2 {
+		t.Errorf("Expected tick rule to fire on every cycle, got %d alerts", len(emitter.alerts))
+	}
+}
+
+func TestScanLoop_ReferenceSymbol(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	// Matches every symbol, including the reference symbol if it were scanned
+	if err := ruleStore.AddRule(&models.Rule{
+		ID:         "rule-price",
+		Name:       "Price Above 100",
+		Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+		Enabled:    true,
+	}); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+	// Cross-symbol condition using the reference symbol's metrics
+	if err := ruleStore.AddRule(&models.Rule{
+		ID:   "rule-relative",
+		Name: "Market Above 400",
+		Conditions: []models.Condition{
+			{Metric: "price", Operator: ">", Value: 100.0},
+			{Metric: ReferenceMetricName("SPY", "price"), Operator: ">", Value: 400.0},
+		},
+		Enabled: true,
+	}); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	config := DefaultScanLoopConfig()
+	config.ReferenceSymbols = []string{"spy"}
+	sl := NewScanLoop(config, sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	now := time.Now()
+	for _, tick := range []*models.Tick{
+		{Symbol: "SPY", Price: 450.0, Size: 100, Timestamp: now, Type: "trade"},
+		{Symbol: "AAPL", Price: 150.0, Size: 100, Timestamp: now, Type: "trade"},
+	} {
+		if err := sm.UpdateLiveBar(tick.Symbol, tick); err != nil {
+			t.Fatalf("Failed to update live bar: %v", err)
+		}
+	}
+
+	sl.Scan()
+
+	if len(emitter.alerts) != 2 {
+		t.Fatalf("Expected 2 alerts for AAPL, got %d", len(emitter.alerts))
+	}
+	for _, alert := range emitter.alerts {
+		if alert.Symbol != "AAPL" {
+			t.Errorf("Expected alerts only for AAPL, got alert for %s", alert.Symbol)
+		}
+	}
+
+	if stats := sl.GetStats(); stats.SymbolsScanned != 1 {
+		t.Errorf("Expected reference symbol to be excluded from scanning, got %d symbols scanned", stats.SymbolsScanned)
+	}
+
+	// Reference metric drives the cross-symbol rule
+	emitter.alerts = nil
+	tick := &models.Tick{Symbol: "SPY", Price: 390.0, Size: 100, Timestamp: now, Type: "trade"}
+	if err := sm.UpdateLiveBar("SPY", tick); err != nil {
+		t.Fatalf("Failed to update live bar: %v", err)
+	}
+	time.Sleep(110 * time.Millisecond) // Let the per-cycle metric cache expire
+
+	sl.Scan()
+
+	if len(emitter.alerts) != 1 || emitter.alerts[0].RuleID != "rule-price" {
+		t.Errorf("Expected only the price rule to fire once SPY drops below 400, got %d alerts", len(emitter.alerts))
+	}
+}
+
+func TestSplitReferenceMetrics(t *testing.T) {
+	required := map[string]bool{
+		"price":                       true,
+		"ref_SPY_price_change_5m_pct": true,
+		"ref_QQQ_rsi_14":              true,
+		"ref_IWM_price":               true, // Not a configured reference symbol
+	}
+
+	local, reference := splitReferenceMetrics(required, map[string]bool{"SPY": true, "QQQ": true})
+
+	if len(local) != 2 || !local["price"] || !local["ref_IWM_price"] {
+		t.Errorf("Unexpected local metrics: %v", local)
+	}
+	if !reference["SPY"]["price_change_5m_pct"] || !reference["QQQ"]["rsi_14"] || len(reference) != 2 {
+		t.Errorf("Unexpected reference metrics: %v", reference)
+	}
+}
+
+func TestScanLoop_ExitConditions(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+	cooldown := NewCooldownTracker(time.Minute)
+
+	rule := &models.Rule{
+		ID:   "rule-breakout",
+		Name: "Breakout",
+		Conditions: []models.Condition{
+			{Metric: "price", Operator: ">", Value: 100.0},
+		},
+		ExitConditions: []models.Condition{
+			{Metric: "price", Operator: "<", Value: 95.0},
+		},
+		Cooldown: 3600,
+		Enabled:  true,
+	}
+	if err := ruleStore.AddRule(rule); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	sl := NewScanLoop(DefaultScanLoopConfig(), sm, ruleStore, rules.NewCompiler(nil), cooldown, emitter, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	setPrice := func(price float64) {
+		tick := &models.Tick{Symbol: "AAPL", Price: price, Size: 100, Timestamp: time.Now(), Type: "trade"}
+		if err := sm.UpdateLiveBar("AAPL", tick); err != nil {
+			t.Fatalf("Failed to update live bar: %v", err)
+		}
+		time.Sleep(110 * time.Millisecond) // Let the per-cycle metric cache expire
+	}
+
+	// Entry
+	setPrice(105.0)
+	sl.Scan()
+	if len(emitter.alerts) != 1 || emitter.alerts[0].Type != models.AlertTypeEntry {
+		t.Fatalf("Expected 1 entry alert, got %d", len(emitter.alerts))
+	}
+	if !sl.isAlertActive("rule-breakout", "AAPL") {
+		t.Fatal("Expected active alert state after entry")
+	}
+	if !cooldown.IsOnCooldown("rule-breakout", "AAPL") {
+		t.Fatal("Expected cooldown after entry")
+	}
+
+	// Still above the exit level: nothing happens
+	setPrice(98.0)
+	sl.Scan()
+	if len(emitter.alerts) != 1 {
+		t.Fatalf("Expected no alert before exit conditions match, got %d", len(emitter.alerts))
+	}
+
+	// Exit
+	setPrice(94.0)
+	sl.Scan()
+	if len(emitter.alerts) != 2 {
+		t.Fatalf("Expected exit alert, got %d alerts", len(emitter.alerts))
+	}
+	exit := emitter.alerts[1]
+	if !exit.IsExit() || exit.Symbol != "AAPL" || exit.RuleID != "rule-breakout" {
+		t.Errorf("Unexpected exit alert: %+v", exit)
+	}
+	// The exit carries the entry's severity (105 is 5% past 100: info), not its own
+	if exit.Severity != emitter.alerts[0].Severity || exit.Severity != models.SeverityInfo {
+		t.Errorf("Expected the exit to carry the entry severity %q, got %q", emitter.alerts[0].Severity, exit.Severity)
+	}
+	if sl.isAlertActive("rule-breakout", "AAPL") {
+		t.Error("Expected active alert state to be reset after exit")
+	}
+	if cooldown.IsOnCooldown("rule-breakout", "AAPL") {
+		t.Error("Expected cooldown to be reset after exit")
+	}
+
+	// Exit does not fire again without a new entry
+	sl.Scan()
+	if len(emitter.alerts) != 2 {
+		t.Errorf("Expected no repeated exit alert, got %d alerts", len(emitter.alerts))
+	}
+
+	// Rule can trigger again immediately since the cooldown was reset
+	setPrice(106.0)
+	sl.Scan()
+	if len(emitter.alerts) != 3 || emitter.alerts[2].IsExit() {
+		t.Errorf("Expected a new entry alert after exit, got %d alerts", len(emitter.alerts))
+	}
+}
+
+// staticCustomMetricSource serves fixed custom metric definitions
+type staticCustomMetricSource struct {
+	metrics []*models.CustomMetric
+}
+
+func (s *staticCustomMetricSource) ListMetrics(ctx context.Context) ([]*models.CustomMetric, error) {
+	return s.metrics, nil
+}
+
+func TestScanLoop_CustomMetricSource(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	rule := &models.Rule{
+		ID:     "rule-custom",
+		UserID: "alice",
+		Name:   "Double Price",
+		Conditions: []models.Condition{
+			{Metric: "double_price", Operator: ">", Value: 200.0},
+		},
+		Enabled: true,
+	}
+	if err := ruleStore.AddRule(rule); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	compiler := rules.NewCompiler(nil)
+	compiler.SetCustomMetricRegistry(rules.NewCustomMetricRegistry([]string{"price"}))
+	source := &staticCustomMetricSource{}
+
+	sl := NewScanLoop(DefaultScanLoopConfig(), sm, ruleStore, compiler, nil, emitter, nil)
+	sl.SetCustomMetricSource(source)
+
+	tick := &models.Tick{Symbol: "AAPL", Price: 105.0, Size: 100, Timestamp: time.Now(), Type: "trade"}
+	if err := sm.UpdateLiveBar("AAPL", tick); err != nil {
+		t.Fatalf("Failed to update live bar: %v", err)
+	}
+
+	// Without the definition the rule cannot resolve its metric
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+	sl.Scan()
+	if len(emitter.alerts) != 0 {
+		t.Fatalf("Expected no alert without the custom metric, got %d", len(emitter.alerts))
+	}
+
+	// The definition is loaded from the source at the next rule reload
+	source.metrics = []*models.CustomMetric{{UserID: "alice", Name: "double_price", Expression: "price * 2"}}
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+	time.Sleep(110 * time.Millisecond) // Let the per-cycle metric cache expire
+	sl.Scan()
+	if len(emitter.alerts) != 1 {
+		t.Fatalf("Expected 1 alert once the custom metric is loaded, got %d", len(emitter.alerts))
+	}
+}
+
+func TestScanLoop_PauseResume(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	rule := &models.Rule{
+		ID:   "rule-pause",
+		Name: "Price Above 100",
+		Conditions: []models.Condition{
+			{Metric: "price", Operator: ">", Value: 100.0},
+		},
+		Enabled: true,
+	}
+	if err := ruleStore.AddRule(rule); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	sl := NewScanLoop(DefaultScanLoopConfig(), sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	tick := &models.Tick{Symbol: "AAPL", Price: 150.0, Size: 100, Timestamp: time.Now(), Type: "trade"}
+	if err := sm.UpdateLiveBar("AAPL", tick); err != nil {
+		t.Fatalf("Failed to update live bar: %v", err)
+	}
+
+	sl.Pause()
+	if !sl.IsPaused() || !sl.GetStats().Paused {
+		t.Fatal("Expected scan loop to report paused")
+	}
+
+	sl.Scan()
+	sl.Scan()
+	if len(emitter.alerts) != 0 {
+		t.Errorf("Expected no alerts while paused, got %d", len(emitter.alerts))
+	}
+	stats := sl.GetStats()
+	if stats.ScanCycles != 0 || stats.RulesEvaluated != 0 {
+		t.Errorf("Expected no scan cycles while paused, got %d cycles and %d evaluations", stats.ScanCycles, stats.RulesEvaluated)
+	}
+
+	// State keeps updating while paused
+	tick.Price = 160.0
+	if err := sm.UpdateLiveBar("AAPL", tick); err != nil {
+		t.Fatalf("Failed to update live bar: %v", err)
+	}
+
+	sl.Resume()
+	if sl.IsPaused() || sl.GetStats().Paused {
+		t.Fatal("Expected scan loop to report resumed")
+	}
+
+	sl.Scan()
+	if len(emitter.alerts) != 1 {
+		t.Fatalf("Expected 1 alert after resume, got %d", len(emitter.alerts))
+	}
+	if emitter.alerts[0].Price != 160.0 {
+		t.Errorf("Expected alert at latest price 160, got %v", emitter.alerts[0].Price)
+	}
+	if sl.GetStats().ScanCycles != 1 {
+		t.Errorf("Expected 1 scan cycle after resume, got %d", sl.GetStats().ScanCycles)
+	}
+}
+
+func TestScanLoop_SkipsStaleSymbols(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	rule := &models.Rule{
+		ID:   "rule-fresh",
+		Name: "Price Above 100",
+		Conditions: []models.Condition{
+			{Metric: "price", Operator: ">", Value: 100.0},
+		},
+		Enabled: true,
+	}
+	if err := ruleStore.AddRule(rule); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	config := DefaultScanLoopConfig()
+	config.MaxDataStaleness = 30 * time.Second
+	sl := NewScanLoop(config, sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	now := time.Now()
+	for _, symbol := range []string{"AAPL", "MSFT"} {
+		tick := &models.Tick{Symbol: symbol, Price: 150.0, Size: 100, Timestamp: now, Type: "trade"}
+		if err := sm.UpdateLiveBar(symbol, tick); err != nil {
+			t.Fatalf("Failed to update live bar: %v", err)
+		}
+	}
+
+	// AAPL's feed stalled a minute ago
+	state := sm.GetOrCreateState("AAPL")
+	state.mu.Lock()
+	state.LastUpdate = now.Add(-time.Minute)
+	state.mu.Unlock()
+
+	sl.Scan()
+
+	if len(emitter.alerts) != 1 {
+		t.Fatalf("Expected 1 alert for the fresh symbol, got %d", len(emitter.alerts))
+	}
+	if emitter.alerts[0].Symbol != "MSFT" {
+		t.Errorf("Expected alert for fresh symbol MSFT, got %s", emitter.alerts[0].Symbol)
+	}
+
+	stats := sl.GetStats()
+	if stats.SymbolsSkippedStale != 1 {
+		t.Errorf("Expected 1 stale skip, got %d", stats.SymbolsSkippedStale)
+	}
+	if stats.SymbolsScanned != 1 {
+		t.Errorf("Expected 1 symbol scanned, got %d", stats.SymbolsScanned)
+	}
+
+	// Disabled staleness check evaluates every symbol
+	sl.config.MaxDataStaleness = 0
+	sl.Scan()
+	if len(emitter.alerts) != 3 {
+		t.Errorf("Expected both symbols evaluated with staleness disabled, got %d total alerts", len(emitter.alerts))
+	}
+}
+
+// flakyRuleStore counts store reads and fails them while err is set
+type flakyRuleStore struct {
+	rules.RuleStore
+	err          error
+	getRuleCalls int
+}
+
+func (s *flakyRuleStore) GetRule(id string) (*models.Rule, error) {
+	s.getRuleCalls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.RuleStore.GetRule(id)
+}
+
+func (s *flakyRuleStore) GetAllRules() ([]*models.Rule, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.RuleStore.GetAllRules()
+}
+
+func TestScanLoop_RuleStoreUnavailableAfterReload(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := &flakyRuleStore{RuleStore: rules.NewInMemoryRuleStore()}
+	emitter := &recordingAlertEmitter{}
+
+	for _, rule := range []*models.Rule{
+		{ID: "rule-price", Name: "Price Above 100", Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}}, Enabled: true},
+		{ID: "rule-price-below", Name: "Price Below 200", Conditions: []models.Condition{{Metric: "price", Operator: "<", Value: 200.0}}, Enabled: true},
+	} {
+		if err := ruleStore.AddRule(rule); err != nil {
+			t.Fatalf("Failed to add rule: %v", err)
+		}
+	}
+
+	sl := NewScanLoop(DefaultScanLoopConfig(), sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	for _, symbol := range []string{"AAPL", "MSFT", "TSLA"} {
+		tick := &models.Tick{Symbol: symbol, Price: 150.0, Size: 100, Timestamp: time.Now(), Type: "trade"}
+		if err := sm.UpdateLiveBar(symbol, tick); err != nil {
+			t.Fatalf("Failed to update live bar: %v", err)
+		}
+	}
+
+	// The store goes down after the rules were loaded
+	ruleStore.err = errors.New("redis: connection refused")
+	sl.Scan()
+
+	if len(emitter.alerts) != 6 {
+		t.Errorf("Expected every rule to fire for every symbol from cached rules, got %d alerts", len(emitter.alerts))
+	}
+	if ruleStore.getRuleCalls != 0 {
+		t.Errorf("Expected no per-symbol rule store reads during a scan, got %d", ruleStore.getRuleCalls)
+	}
+
+	// A failed reload keeps scanning with the previously loaded rules
+	if err := sl.ReloadRules(); err == nil {
+		t.Error("Expected reload to fail while the store is unavailable")
+	}
+	sl.Scan()
+	if len(emitter.alerts) != 12 {
+		t.Errorf("Expected scanning to continue with cached rules after a failed reload, got %d alerts", len(emitter.alerts))
+	}
+}
+
+func TestScanLoop_NewSymbolWithoutFinalizedBars(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	// Any real change value matches these; a symbol without history must not
+	for _, metric := range []string{"price_change_1m_pct", "price_change_5m_pct", "change_5m"} {
+		rule := &models.Rule{
+			ID:         "rule-" + metric,
+			Name:       "Any " + metric,
+			Conditions: []models.Condition{{Metric: metric, Operator: ">", Value: -1e9}},
+			Enabled:    true,
+		}
+		if err := ruleStore.AddRule(rule); err != nil {
+			t.Fatalf("Failed to add rule: %v", err)
+		}
+	}
+	priceRule := &models.Rule{
+		ID:         "rule-price",
+		Name:       "Price Above 1",
+		Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 1.0}},
+		Enabled:    true,
+	}
+	if err := ruleStore.AddRule(priceRule); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	sl := NewScanLoop(DefaultScanLoopConfig(), sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	tick := &models.Tick{Symbol: "NEWCO", Price: 10.0, Size: 100, Timestamp: time.Now(), Type: "trade"}
+	if err := sm.UpdateLiveBar("NEWCO", tick); err != nil {
+		t.Fatalf("Failed to update live bar: %v", err)
+	}
+
+	sl.Scan()
+
+	if len(emitter.alerts) != 1 || emitter.alerts[0].RuleID != "rule-price" {
+		t.Fatalf("Expected only the price rule to fire, got %d alerts", len(emitter.alerts))
+	}
+
+	// Change metrics without history are left out of the alert payload, which must encode
+	alertMetrics := emitter.alerts[0].Metadata["metrics"].(map[string]float64)
+	for name, value := range alertMetrics {
+		if math.IsNaN(value) {
+			t.Errorf("Expected NaN metric %s to be dropped from the alert", name)
+		}
+	}
+	if _, err := json.Marshal(emitter.alerts[0]); err != nil {
+		t.Errorf("Failed to encode alert: %v", err)
+	}
+}
+
+func TestScanLoop_CrossingCondition(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	if err := ruleStore.AddRule(&models.Rule{
+		ID:         "rule-cross",
+		Name:       "EMA Crosses Above SMA",
+		Conditions: []models.Condition{{Metric: "ema_20", Operator: models.OperatorCrossesAbove, Value: "sma_20"}},
+		Enabled:    true,
+	}); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	sl := NewScanLoop(DefaultScanLoopConfig(), sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	tick := &models.Tick{Symbol: "AAPL", Price: 150.0, Size: 100, Timestamp: time.Now(), Type: "trade"}
+	if err := sm.UpdateLiveBar("AAPL", tick); err != nil {
+		t.Fatalf("Failed to update live bar: %v", err)
+	}
+
+	cycles := []struct {
+		ema        float64
+		wantAlerts int
+	}{
+		{ema: 101, wantAlerts: 0}, // First scan: no previous value
+		{ema: 99, wantAlerts: 0},  // Crosses below
+		{ema: 101, wantAlerts: 1}, // Crosses above
+		{ema: 102, wantAlerts: 1}, // Stays above
+	}
+	for i, cycle := range cycles {
+		if err := sm.UpdateIndicators("AAPL", map[string]float64{"ema_20": cycle.ema, "sma_20": 100}); err != nil {
+			t.Fatalf("Failed to update indicators: %v", err)
+		}
+		sl.Scan()
+		if len(emitter.alerts) != cycle.wantAlerts {
+			t.Fatalf("Cycle %d: expected %d alerts, got %d", i, cycle.wantAlerts, len(emitter.alerts))
+		}
+	}
+}
+
+func TestScanLoop_GapRulesNeedYesterdayClose(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	for _, rule := range []*models.Rule{
+		{
+			ID:         "rule-gap",
+			Name:       "Gap Up",
+			Conditions: []models.Condition{{Metric: "gap_pct", Operator: ">", Value: 4.0}},
+			Enabled:    true,
+		},
+		{
+			ID:         "rule-moved",
+			Name:       "Moved From Close",
+			Conditions: []models.Condition{{Metric: "gap_from_prev_close_pct", Operator: "!=", Value: 0.0}},
+			Enabled:    true,
+		},
+	} {
+		if err := ruleStore.AddRule(rule); err != nil {
+			t.Fatalf("Failed to add rule: %v", err)
+		}
+	}
+
+	sl := NewScanLoop(DefaultScanLoopConfig(), sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	setPrice := func(price float64) {
+		tick := &models.Tick{Symbol: "AAPL", Price: price, Size: 100, Timestamp: time.Now(), Type: "trade"}
+		if err := sm.UpdateLiveBar("AAPL", tick); err != nil {
+			t.Fatalf("Failed to update live bar: %v", err)
+		}
+		time.Sleep(110 * time.Millisecond) // Let the per-cycle metric cache expire
+	}
+
+	// Without yesterday's close the gaps are absent and match nothing, not even "!= 0"
+	setPrice(110.0)
+	sl.Scan()
+	if len(emitter.alerts) != 0 {
+		t.Fatalf("Expected no alerts without yesterday's close, got %d", len(emitter.alerts))
+	}
+
+	state := sm.GetState("AAPL")
+	state.mu.Lock()
+	state.YesterdayClose = 100.0
+	state.TodayOpen = 105.0
+	state.mu.Unlock()
+
+	setPrice(110.0)
+	sl.Scan()
+	if len(emitter.alerts) != 2 {
+		t.Fatalf("Expected both rules to match once yesterday's close is set, got %d alerts", len(emitter.alerts))
+	}
+	metrics := sm.GetMetrics("AAPL")
+	if metrics["gap_pct"] != 5.0 || metrics["gap_from_prev_close_pct"] != 10.0 {
+		t.Errorf("Expected gap_pct 5 and gap_from_prev_close_pct 10, got %v and %v",
+			metrics["gap_pct"], metrics["gap_from_prev_close_pct"])
+	}
+}
+
+func TestScanLoop_MissingMetricPolicy(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	for _, rule := range []*models.Rule{
+		{
+			ID:         "rule-flat",
+			Name:       "Flat",
+			Conditions: []models.Condition{{Metric: "price_change_5m_pct", Operator: "<", Value: 1.0}},
+			Enabled:    true,
+		},
+		{
+			ID:             "rule-flat-or-new",
+			Name:           "Flat Or New",
+			Conditions:     []models.Condition{{Metric: "price_change_5m_pct", Operator: "<", Value: 1.0}},
+			MatchOnMissing: true,
+			Enabled:        true,
+		},
+	} {
+		if err := ruleStore.AddRule(rule); err != nil {
+			t.Fatalf("Failed to add rule: %v", err)
+		}
+	}
+
+	sl := NewScanLoop(DefaultScanLoopConfig(), sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	// A symbol that just started trading has no 5 minute history: the change is absent, not 0
+	tick := &models.Tick{Symbol: "NEWCO", Price: 10.0, Size: 100, Timestamp: time.Now(), Type: "trade"}
+	if err := sm.UpdateLiveBar("NEWCO", tick); err != nil {
+		t.Fatalf("Failed to update live bar: %v", err)
+	}
+	if _, ok := sm.GetMetrics("NEWCO")["price_change_5m_pct"]; ok {
+		t.Fatal("Expected price_change_5m_pct to be absent without history")
+	}
+
+	sl.Scan()
+	if len(emitter.alerts) != 1 || emitter.alerts[0].RuleID != "rule-flat-or-new" {
+		t.Fatalf("Expected only the MatchOnMissing rule to match, got %d alerts", len(emitter.alerts))
+	}
+}
+
+func TestScanLoop_UserRuleAlertAddressedToOwner(t *testing.T) {
+	alert := scanOnce(t, &models.Rule{
+		ID:         "rule-user",
+		UserID:     "user-1",
+		Name:       "Price Above 100",
+		Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+		Enabled:    true,
+	}, false)
+	if got := alert.TargetUserID(); got != "user-1" {
+		t.Errorf("Expected alert addressed to the rule owner user-1, got %q", got)
+	}
+
+	system := scanOnce(t, &models.Rule{
+		ID:         "rule-system",
+		Name:       "Price Above 100",
+		Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+		Enabled:    true,
+	}, false)
+	if got := system.TargetUserID(); got != "" {
+		t.Errorf("Expected system rule alert to be broadcast, got target %q", got)
+	}
+}
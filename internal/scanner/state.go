@@ -1,11 +1,13 @@
 package scanner
 
 import (
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
 	"github.com/mohamedkhairy/stock-scanner/internal/metrics"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
 )
 
 // SymbolState represents the current state of a symbol for scanning
@@ -33,6 +35,17 @@ type SymbolState struct {
 	MarketVolume    int64 // Volume traded during market session
 	PostmarketVolume int64 // Volume traded during post-market session
 
+	// Session-anchored VWAP accumulators over the current session's finalized bars
+	SessionVWAPNum    float64 // Sum of bar VWAP * volume
+	SessionVWAPVolume int64   // Sum of bar volume
+
+	// Bar close tracking (incremented on each finalized bar)
+	FinalizedBarCount int64
+
+	// Provider-supplied LULD bands (0 = not available)
+	LULDUpper float64
+	LULDLower float64
+
 	// Trade count tracking
 	TradeCount      int64 // Total trade count (incremented on each tick)
 	TradeCountHistory []int64 // Ring buffer for timeframe-based trade counts
@@ -41,6 +54,9 @@ type SymbolState struct {
 	// Map of timeframe -> direction history (true = green/up, false = red/down)
 	CandleDirections map[string][]bool // timeframe -> []bool
 
+	// Metrics of the last scan cycle the symbol was scanned in (for crossing conditions)
+	PreviousMetrics map[string]float64
+
 	// Metric caching for performance optimization
 	// Cache computed metrics with invalidation timestamp
 	cachedMetrics     map[string]float64
@@ -48,12 +64,24 @@ type SymbolState struct {
 	cacheInvalidation time.Time // When cache should be invalidated
 }
 
+// OutOfOrderBarPolicy controls how finalized bars older than the latest stored bar are handled
+type OutOfOrderBarPolicy string
+
+const (
+	// OutOfOrderBarInsert inserts late bars in timestamp order; duplicates replace the stored bar
+	OutOfOrderBarInsert OutOfOrderBarPolicy = "insert"
+	// OutOfOrderBarReject drops late and duplicate bars
+	OutOfOrderBarReject OutOfOrderBarPolicy = "reject"
+)
+
 // StateManager manages symbol states for the scanner
 type StateManager struct {
 	states        map[string]*SymbolState
 	mu            sync.RWMutex
 	maxFinalBars  int // Maximum number of finalized bars to keep per symbol
 	metricRegistry *metrics.Registry // Metric registry for computing metrics
+	outOfOrderPolicy OutOfOrderBarPolicy // Handling of late finalized bars (default: insert)
+	clock          Clock             // Time source for LastUpdate (default: wall clock)
 }
 
 // NewStateManager creates a new state manager
@@ -66,7 +94,27 @@ func NewStateManager(maxFinalBars int) *StateManager {
 		states:         make(map[string]*SymbolState),
 		maxFinalBars:   maxFinalBars,
 		metricRegistry: metrics.NewRegistry(),
+		outOfOrderPolicy: OutOfOrderBarInsert,
+		clock:          RealClock{},
+	}
+}
+
+// SetClock sets the time source. Replays use a SimulationClock, which is advanced
+// by the timestamps of incoming ticks and bars. Must be called before data is processed.
+func (sm *StateManager) SetClock(clock Clock) {
+	if clock == nil {
+		clock = RealClock{}
 	}
+	sm.clock = clock
+}
+
+// SetOutOfOrderBarPolicy sets how late finalized bars are handled.
+// Must be called before bars are processed.
+func (sm *StateManager) SetOutOfOrderBarPolicy(policy OutOfOrderBarPolicy) {
+	if policy != OutOfOrderBarReject {
+		policy = OutOfOrderBarInsert
+	}
+	sm.outOfOrderPolicy = policy
 }
 
 // GetOrCreateState gets an existing symbol state or creates a new one
@@ -115,6 +163,7 @@ func (sm *StateManager) UpdateLiveBar(symbol string, tick *models.Tick) error {
 		return nil
 	}
 
+	sm.clock.Observe(tick.Timestamp)
 	state := sm.GetOrCreateState(symbol)
 
 	state.mu.Lock()
@@ -129,8 +178,7 @@ func (sm *StateManager) UpdateLiveBar(symbol string, tick *models.Tick) error {
 
 	// Initialize live bar if needed
 	// Use tick's timestamp to determine which minute it belongs to
-	tickTime := tick.Timestamp
-	minuteStart := time.Date(tickTime.Year(), tickTime.Month(), tickTime.Day(), tickTime.Hour(), tickTime.Minute(), 0, 0, tickTime.Location())
+	minuteStart := models.AlignToMinute(tick.Timestamp, nil)
 
 	if state.LiveBar == nil || !state.LiveBar.Timestamp.Equal(minuteStart) {
 		// New minute - create new live bar
@@ -143,7 +191,13 @@ func (sm *StateManager) UpdateLiveBar(symbol string, tick *models.Tick) error {
 	// Update live bar with tick
 	state.LiveBar.Update(tick)
 	state.LastTickTime = tick.Timestamp
-	state.LastUpdate = time.Now()
+	state.LastUpdate = sm.clock.Now()
+
+	// Track provider-supplied LULD bands when present on the tick
+	if tick.LULDUpper > 0 && tick.LULDLower > 0 {
+		state.LULDUpper = tick.LULDUpper
+		state.LULDLower = tick.LULDLower
+	}
 
 	// Invalidate metric cache (data has changed)
 	// Note: We invalidate on every tick update, but cache can still help
@@ -187,6 +241,24 @@ func (sm *StateManager) handleSessionTransition(state *SymbolState, newSession M
 
 	// Reset trade count at start of each session
 	state.TradeCount = 0
+
+	// Session VWAP is anchored at the start of each session
+	state.SessionVWAPNum = 0
+	state.SessionVWAPVolume = 0
+}
+
+// accumulateSessionVWAP adds a finalized bar to the session VWAP, weighting its VWAP
+// (or typical price, when the bar has none) by its volume
+func accumulateSessionVWAP(state *SymbolState, bar *models.Bar1m) {
+	if bar.Volume <= 0 {
+		return
+	}
+	price := bar.VWAP
+	if price <= 0 {
+		price = (bar.High + bar.Low + bar.Close) / 3
+	}
+	state.SessionVWAPNum += price * float64(bar.Volume)
+	state.SessionVWAPVolume += bar.Volume
 }
 
 // updateSessionVolume updates the appropriate session-specific volume counter
@@ -207,11 +279,19 @@ func (sm *StateManager) UpdateFinalizedBar(bar *models.Bar1m) error {
 		return nil
 	}
 
+	// A finalized bar is complete at the end of its minute
+	sm.clock.Observe(bar.Timestamp.Add(time.Minute))
 	state := sm.GetOrCreateState(bar.Symbol)
 
 	state.mu.Lock()
 	defer state.mu.Unlock()
 
+	// Replayed or late-corrected bars must not corrupt the ring buffer ordering
+	if n := len(state.LastFinalBars); n > 0 && !bar.Timestamp.After(state.LastFinalBars[n-1].Timestamp) {
+		sm.applyLateFinalizedBar(state, bar)
+		return nil
+	}
+
 	// Check and update session
 	newSession := GetMarketSession(bar.Timestamp)
 	if newSession != state.CurrentSession {
@@ -228,6 +308,8 @@ func (sm *StateManager) UpdateFinalizedBar(bar *models.Bar1m) error {
 		state.TodayClose = bar.Close
 	}
 
+	accumulateSessionVWAP(state, bar)
+
 	// Track candle direction (green = close > open, red = close < open)
 	isGreen := bar.Close > bar.Open
 	sm.updateCandleDirection(state, "1m", isGreen)
@@ -249,6 +331,9 @@ func (sm *StateManager) UpdateFinalizedBar(bar *models.Bar1m) error {
 	// Invalidate metric cache (data has changed)
 	state.invalidateMetricCache()
 
+	// Mark bar closed for bar-close rules
+	state.FinalizedBarCount++
+
 	// Add to ring buffer
 	state.LastFinalBars = append(state.LastFinalBars, bar)
 	if len(state.LastFinalBars) > sm.maxFinalBars {
@@ -262,11 +347,51 @@ func (sm *StateManager) UpdateFinalizedBar(bar *models.Bar1m) error {
 		state.LiveBar = nil
 	}
 
-	state.LastUpdate = time.Now()
+	state.LastUpdate = sm.clock.Now()
 
 	return nil
 }
 
+// applyLateFinalizedBar handles a finalized bar that is not newer than the latest stored bar.
+// Only the ring buffer is updated; session, session VWAP, trade count and bar close tracking
+// follow the latest bar and are left unchanged.
+func (sm *StateManager) applyLateFinalizedBar(state *SymbolState, bar *models.Bar1m) {
+	if sm.outOfOrderPolicy == OutOfOrderBarReject {
+		logger.Debug("Dropping out-of-order finalized bar",
+			logger.String("symbol", bar.Symbol),
+			logger.Time("timestamp", bar.Timestamp),
+		)
+		return
+	}
+
+	bars := state.LastFinalBars
+	idx := sort.Search(len(bars), func(i int) bool {
+		return !bars[i].Timestamp.Before(bar.Timestamp)
+	})
+
+	// Duplicate (symbol, timestamp): the latest correction wins
+	if idx < len(bars) && bars[idx].Timestamp.Equal(bar.Timestamp) {
+		bars[idx] = bar
+		state.invalidateMetricCache()
+		return
+	}
+
+	// Older than every retained bar in a full buffer: it would be evicted immediately
+	if idx == 0 && len(bars) >= sm.maxFinalBars {
+		return
+	}
+
+	bars = append(bars, nil)
+	copy(bars[idx+1:], bars[idx:])
+	bars[idx] = bar
+	if len(bars) > sm.maxFinalBars {
+		copy(bars, bars[1:])
+		bars = bars[:len(bars)-1]
+	}
+	state.LastFinalBars = bars
+	state.invalidateMetricCache()
+}
+
 // updateCandleDirection updates the candle direction history for a timeframe
 func (sm *StateManager) updateCandleDirection(state *SymbolState, timeframe string, isGreen bool) {
 	if state.CandleDirections == nil {
@@ -306,11 +431,55 @@ func (sm *StateManager) UpdateIndicators(symbol string, indicators map[string]fl
 		state.Indicators[key] = value
 	}
 
-	state.LastUpdate = time.Now()
+	state.LastUpdate = sm.clock.Now()
+	state.invalidateMetricCache()
 
 	return nil
 }
 
+// SetPreviousMetrics stores a copy of the metrics a symbol was scanned with, for crossing
+// conditions in its next scan cycle
+func (sm *StateManager) SetPreviousMetrics(symbol string, metrics map[string]float64) {
+	state := sm.GetState(symbol)
+	if state == nil {
+		return
+	}
+
+	previous := make(map[string]float64, len(metrics))
+	for key, value := range metrics {
+		previous[key] = value
+	}
+
+	state.mu.Lock()
+	state.PreviousMetrics = previous
+	state.mu.Unlock()
+}
+
+// PreviousMetrics returns the metrics of a symbol's previous scan cycle (nil if none).
+// The returned map must not be modified.
+func (sm *StateManager) PreviousMetrics(symbol string) map[string]float64 {
+	state := sm.GetState(symbol)
+	if state == nil {
+		return nil
+	}
+
+	state.mu.RLock()
+	defer state.mu.RUnlock()
+	return state.PreviousMetrics
+}
+
+// CurrentSession returns a symbol's current market session ("closed" if the symbol is unknown)
+func (sm *StateManager) CurrentSession(symbol string) string {
+	state := sm.GetState(symbol)
+	if state == nil {
+		return string(SessionClosed)
+	}
+
+	state.mu.RLock()
+	defer state.mu.RUnlock()
+	return string(state.CurrentSession)
+}
+
 // GetMetrics returns a snapshot of all metrics for a symbol (for rule evaluation)
 // This method uses the metric registry to compute metrics consistently
 func (sm *StateManager) GetMetrics(symbol string) map[string]float64 {
@@ -338,6 +507,8 @@ func (sm *StateManager) GetMetrics(symbol string) map[string]float64 {
 		PremarketVolume:  state.PremarketVolume,
 		MarketVolume:     state.MarketVolume,
 		PostmarketVolume: state.PostmarketVolume,
+		SessionVWAPNum:    state.SessionVWAPNum,
+		SessionVWAPVolume: state.SessionVWAPVolume,
 		TradeCount:       state.TradeCount,
 	}
 
@@ -391,6 +562,17 @@ type SymbolStateSnapshot struct {
 	MarketVolume    int64
 	PostmarketVolume int64
 
+	// Session-anchored VWAP accumulators
+	SessionVWAPNum    float64
+	SessionVWAPVolume int64
+
+	// Bar close tracking
+	FinalizedBarCount int64
+
+	// Provider-supplied LULD bands
+	LULDUpper float64
+	LULDLower float64
+
 	// Trade count tracking
 	TradeCount      int64
 	TradeCountHistory []int64
@@ -425,7 +607,12 @@ func (sm *StateManager) Snapshot() *StateSnapshot {
 			PremarketVolume:  state.PremarketVolume,
 			MarketVolume:     state.MarketVolume,
 			PostmarketVolume: state.PostmarketVolume,
+			SessionVWAPNum:    state.SessionVWAPNum,
+			SessionVWAPVolume: state.SessionVWAPVolume,
 			TradeCount:       state.TradeCount,
+			FinalizedBarCount: state.FinalizedBarCount,
+			LULDUpper:        state.LULDUpper,
+			LULDLower:        state.LULDLower,
 		}
 
 		// Copy trade count history
@@ -480,6 +667,18 @@ func (sm *StateManager) GetSymbolCount() int {
 	return len(sm.states)
 }
 
+// GetSymbols returns the symbols that have state
+func (sm *StateManager) GetSymbols() []string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	symbols := make([]string, 0, len(sm.states))
+	for symbol := range sm.states {
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
+
 // RemoveSymbol removes a symbol from the state manager
 func (sm *StateManager) RemoveSymbol(symbol string) {
 	sm.mu.Lock()
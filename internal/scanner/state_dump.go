@@ -0,0 +1,150 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// SymbolStateDump is the serialized form of a symbol's state in a debug dump
+type SymbolStateDump struct {
+	Symbol            string             `json:"symbol"`
+	LiveBar           *models.LiveBar    `json:"live_bar,omitempty"`
+	LastFinalBars     []*models.Bar1m    `json:"last_final_bars"`
+	Indicators        map[string]float64 `json:"indicators"`
+	LastTickTime      time.Time          `json:"last_tick_time"`
+	LastUpdate        time.Time          `json:"last_update"`
+	CurrentSession    MarketSession      `json:"current_session"`
+	SessionStartTime  time.Time          `json:"session_start_time"`
+	YesterdayClose    float64            `json:"yesterday_close"`
+	TodayOpen         float64            `json:"today_open"`
+	TodayClose        float64            `json:"today_close"`
+	PremarketVolume   int64              `json:"premarket_volume"`
+	MarketVolume      int64              `json:"market_volume"`
+	PostmarketVolume  int64              `json:"postmarket_volume"`
+	SessionVWAPNum    float64            `json:"session_vwap_num"`
+	SessionVWAPVolume int64              `json:"session_vwap_volume"`
+	FinalizedBarCount int64              `json:"finalized_bar_count"`
+	LULDUpper         float64            `json:"luld_upper,omitempty"`
+	LULDLower         float64            `json:"luld_lower,omitempty"`
+	TradeCount        int64              `json:"trade_count"`
+	TradeCountHistory []int64            `json:"trade_count_history,omitempty"`
+	CandleDirections  map[string][]bool  `json:"candle_directions,omitempty"`
+}
+
+// newSymbolStateDump converts a symbol state snapshot to its dump form
+func newSymbolStateDump(s *SymbolStateSnapshot) *SymbolStateDump {
+	return &SymbolStateDump{
+		Symbol:            s.Symbol,
+		LiveBar:           s.LiveBar,
+		LastFinalBars:     s.LastFinalBars,
+		Indicators:        s.Indicators,
+		LastTickTime:      s.LastTickTime,
+		LastUpdate:        s.LastUpdate,
+		CurrentSession:    s.CurrentSession,
+		SessionStartTime:  s.SessionStartTime,
+		YesterdayClose:    s.YesterdayClose,
+		TodayOpen:         s.TodayOpen,
+		TodayClose:        s.TodayClose,
+		PremarketVolume:   s.PremarketVolume,
+		MarketVolume:      s.MarketVolume,
+		PostmarketVolume:  s.PostmarketVolume,
+		SessionVWAPNum:    s.SessionVWAPNum,
+		SessionVWAPVolume: s.SessionVWAPVolume,
+		FinalizedBarCount: s.FinalizedBarCount,
+		LULDUpper:         s.LULDUpper,
+		LULDLower:         s.LULDLower,
+		TradeCount:        s.TradeCount,
+		TradeCountHistory: s.TradeCountHistory,
+		CandleDirections:  s.CandleDirections,
+	}
+}
+
+// WriteStateDump writes a snapshot as NDJSON (one symbol per line, sorted by symbol).
+// At most limit symbols are written (limit <= 0 writes all). Returns the number of symbols written.
+func WriteStateDump(w io.Writer, snapshot *StateSnapshot, limit int) (int, error) {
+	symbols := make([]string, 0, len(snapshot.States))
+	for symbol := range snapshot.States {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	if limit > 0 && len(symbols) > limit {
+		symbols = symbols[:limit]
+	}
+
+	encoder := json.NewEncoder(w)
+	for i, symbol := range symbols {
+		if err := encoder.Encode(newSymbolStateDump(snapshot.States[symbol])); err != nil {
+			return i, err
+		}
+	}
+	return len(symbols), nil
+}
+
+// StateDumpHandler serves GET /debug/dump: the full scanner state as NDJSON for offline analysis.
+// Requests must carry "Authorization: Bearer <token>". The dump is built from a state snapshot,
+// so live scanning is never blocked while it streams.
+type StateDumpHandler struct {
+	stateManager *StateManager
+	token        string
+	maxSymbols   int
+}
+
+// NewStateDumpHandler creates a state dump handler (maxSymbols <= 0 = unbounded)
+func NewStateDumpHandler(stateManager *StateManager, token string, maxSymbols int) *StateDumpHandler {
+	return &StateDumpHandler{
+		stateManager: stateManager,
+		token:        token,
+		maxSymbols:   maxSymbols,
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (h *StateDumpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !tokenAuthorized(r, h.token) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	limit := h.maxSymbols
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		requested, err := strconv.Atoi(limitStr)
+		if err != nil || requested <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		if limit <= 0 || requested < limit {
+			limit = requested
+		}
+	}
+
+	start := time.Now()
+	snapshot := h.stateManager.Snapshot()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	buffered := bufio.NewWriter(w)
+	written, err := WriteStateDump(buffered, snapshot, limit)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if err != nil {
+		logger.Warn("State dump interrupted",
+			logger.ErrorField(err),
+			logger.Int("symbols_written", written),
+		)
+		return
+	}
+
+	logger.Info("State dump served",
+		logger.Int("symbols", written),
+		logger.Int("total_symbols", len(snapshot.States)),
+		logger.Duration("duration", time.Since(start)),
+	)
+}
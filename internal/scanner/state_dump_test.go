@@ -0,0 +1,162 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// newDumpTestStateManager creates a state manager with a live bar, finalized bar and indicators per symbol
+func newDumpTestStateManager(t *testing.T, symbols []string) *StateManager {
+	t.Helper()
+
+	sm := NewStateManager(10)
+	now := time.Now()
+	for i, symbol := range symbols {
+		price := 100.0 + float64(i)
+		if err := sm.UpdateFinalizedBar(&models.Bar1m{
+			Symbol:    symbol,
+			Timestamp: now.Add(-time.Minute).Truncate(time.Minute),
+			Open:      price,
+			High:      price + 1,
+			Low:       price - 1,
+			Close:     price,
+			Volume:    1000,
+		}); err != nil {
+			t.Fatalf("UpdateFinalizedBar() error = %v", err)
+		}
+		if err := sm.UpdateLiveBar(symbol, &models.Tick{
+			Symbol:    symbol,
+			Price:     price,
+			Size:      100,
+			Timestamp: now,
+			Type:      "trade",
+		}); err != nil {
+			t.Fatalf("UpdateLiveBar() error = %v", err)
+		}
+		if err := sm.UpdateIndicators(symbol, map[string]float64{"rsi_14": 50 + float64(i)}); err != nil {
+			t.Fatalf("UpdateIndicators() error = %v", err)
+		}
+	}
+	return sm
+}
+
+// readStateDump parses an NDJSON dump into generic records
+func readStateDump(t *testing.T, body string) []map[string]interface{} {
+	t.Helper()
+
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Invalid NDJSON line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestStateDumpHandler_DumpsAllSymbols(t *testing.T) {
+	symbols := []string{"MSFT", "AAPL", "TSLA"}
+	handler := NewStateDumpHandler(newDumpTestStateManager(t, symbols), "secret", 0)
+
+	req := httptest.NewRequest("GET", "/debug/dump", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Expected NDJSON content type, got %s", ct)
+	}
+
+	records := readStateDump(t, w.Body.String())
+	if len(records) != len(symbols) {
+		t.Fatalf("Expected %d records, got %d", len(symbols), len(records))
+	}
+
+	// Sorted by symbol
+	for i, want := range []string{"AAPL", "MSFT", "TSLA"} {
+		if records[i]["symbol"] != want {
+			t.Errorf("Record %d: expected symbol %s, got %v", i, want, records[i]["symbol"])
+		}
+	}
+
+	for _, record := range records {
+		for _, field := range []string{"live_bar", "last_final_bars", "indicators", "last_tick_time", "current_session", "trade_count"} {
+			if _, ok := record[field]; !ok {
+				t.Errorf("%v: missing field %s", record["symbol"], field)
+			}
+		}
+		if bars, _ := record["last_final_bars"].([]interface{}); len(bars) != 1 {
+			t.Errorf("%v: expected 1 finalized bar, got %v", record["symbol"], record["last_final_bars"])
+		}
+		if indicators, _ := record["indicators"].(map[string]interface{}); indicators["rsi_14"] == nil {
+			t.Errorf("%v: expected rsi_14 indicator, got %v", record["symbol"], record["indicators"])
+		}
+	}
+}
+
+func TestStateDumpHandler_Auth(t *testing.T) {
+	sm := newDumpTestStateManager(t, []string{"AAPL"})
+
+	tests := []struct {
+		name   string
+		token  string
+		header string
+	}{
+		{"missing header", "secret", ""},
+		{"wrong token", "secret", "Bearer nope"},
+		{"no token configured", "", "Bearer "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/debug/dump", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			NewStateDumpHandler(sm, tt.token, 0).ServeHTTP(w, req)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, w.Code)
+			}
+		})
+	}
+}
+
+func TestStateDumpHandler_Limits(t *testing.T) {
+	sm := newDumpTestStateManager(t, []string{"AAPL", "MSFT", "TSLA", "NVDA"})
+
+	serve := func(maxSymbols int, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/debug/dump"+query, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		NewStateDumpHandler(sm, "secret", maxSymbols).ServeHTTP(w, req)
+		return w
+	}
+
+	if got := len(readStateDump(t, serve(2, "").Body.String())); got != 2 {
+		t.Errorf("Expected max symbols to bound the dump to 2, got %d", got)
+	}
+	if got := len(readStateDump(t, serve(0, "?limit=3").Body.String())); got != 3 {
+		t.Errorf("Expected limit=3 to return 3 symbols, got %d", got)
+	}
+	if got := len(readStateDump(t, serve(2, "?limit=3").Body.String())); got != 2 {
+		t.Errorf("Expected limit above max symbols to be capped at 2, got %d", got)
+	}
+	if w := serve(0, "?limit=abc"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for invalid limit, got %d", http.StatusBadRequest, w.Code)
+	}
+}
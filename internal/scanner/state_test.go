@@ -193,6 +193,119 @@ func TestStateManager_UpdateFinalizedBar_RingBuffer(t *testing.T) {
 	}
 }
 
+func TestStateManager_UpdateFinalizedBar_OutOfOrder(t *testing.T) {
+	sm := NewStateManager(10)
+	base := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	// Minutes delivered out of order, including duplicates and a late correction
+	deliveries := []struct {
+		minute int
+		close  float64
+	}{
+		{0, 100}, {2, 102}, {1, 101}, {4, 104}, {3, 103}, {2, 102}, {1, 111}, {5, 105},
+	}
+	for _, d := range deliveries {
+		bar := &models.Bar1m{
+			Symbol:    "AAPL",
+			Timestamp: base.Add(time.Duration(d.minute) * time.Minute),
+			Close:     d.close,
+		}
+		if err := sm.UpdateFinalizedBar(bar); err != nil {
+			t.Fatalf("UpdateFinalizedBar() error = %v", err)
+		}
+	}
+
+	state := sm.GetState("AAPL")
+	state.mu.RLock()
+	finalBars := state.LastFinalBars
+	barCount := state.FinalizedBarCount
+	state.mu.RUnlock()
+
+	if len(finalBars) != 6 {
+		t.Fatalf("Expected 6 deduplicated bars, got %d", len(finalBars))
+	}
+	for i, bar := range finalBars {
+		expected := base.Add(time.Duration(i) * time.Minute)
+		if !bar.Timestamp.Equal(expected) {
+			t.Errorf("Bar %d: expected timestamp %v, got %v", i, expected, bar.Timestamp)
+		}
+	}
+
+	// Late correction replaces the stored bar
+	if finalBars[1].Close != 111 {
+		t.Errorf("Expected corrected close 111 for minute 1, got %f", finalBars[1].Close)
+	}
+
+	// Only in-order bars count as bar closes
+	if barCount != 4 {
+		t.Errorf("Expected 4 bar closes, got %d", barCount)
+	}
+}
+
+func TestStateManager_UpdateFinalizedBar_OutOfOrderFullBuffer(t *testing.T) {
+	sm := NewStateManager(3)
+	base := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	for _, minute := range []int{1, 3, 5, 0, 4} {
+		bar := &models.Bar1m{
+			Symbol:    "AAPL",
+			Timestamp: base.Add(time.Duration(minute) * time.Minute),
+			Close:     float64(100 + minute),
+		}
+		if err := sm.UpdateFinalizedBar(bar); err != nil {
+			t.Fatalf("UpdateFinalizedBar() error = %v", err)
+		}
+	}
+
+	state := sm.GetState("AAPL")
+	state.mu.RLock()
+	finalBars := state.LastFinalBars
+	state.mu.RUnlock()
+
+	// Minute 0 is older than every retained bar; minute 4 evicts minute 1
+	expected := []float64{103, 104, 105}
+	if len(finalBars) != len(expected) {
+		t.Fatalf("Expected %d bars, got %d", len(expected), len(finalBars))
+	}
+	for i, close := range expected {
+		if finalBars[i].Close != close {
+			t.Errorf("Bar %d: expected close %f, got %f", i, close, finalBars[i].Close)
+		}
+	}
+}
+
+func TestStateManager_UpdateFinalizedBar_RejectPolicy(t *testing.T) {
+	sm := NewStateManager(10)
+	sm.SetOutOfOrderBarPolicy(OutOfOrderBarReject)
+	base := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	for _, minute := range []int{0, 2, 1, 2, 3} {
+		bar := &models.Bar1m{
+			Symbol:    "AAPL",
+			Timestamp: base.Add(time.Duration(minute) * time.Minute),
+			Close:     float64(100 + minute),
+		}
+		if err := sm.UpdateFinalizedBar(bar); err != nil {
+			t.Fatalf("UpdateFinalizedBar() error = %v", err)
+		}
+	}
+
+	state := sm.GetState("AAPL")
+	state.mu.RLock()
+	finalBars := state.LastFinalBars
+	state.mu.RUnlock()
+
+	expected := []float64{100, 102, 103}
+	if len(finalBars) != len(expected) {
+		t.Fatalf("Expected %d bars, got %d", len(expected), len(finalBars))
+	}
+	for i, close := range expected {
+		if finalBars[i].Close != close {
+			t.Errorf("Bar %d: expected close %f, got %f", i, close, finalBars[i].Close)
+		}
+	}
+}
+
 func TestStateManager_UpdateIndicators(t *testing.T) {
 	sm := NewStateManager(10)
 
@@ -425,3 +538,76 @@ func TestStateManager_Concurrency(t *testing.T) {
 	}
 }
 
+
+func TestStateManager_UpdateLiveBar_DSTFallBack(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata not available: %v", err)
+	}
+
+	sm := NewStateManager(10)
+
+	// 01:30 ET occurs twice on 2024-11-03; ticks from the second (EST) pass must not
+	// land in a bar stamped with the first (EDT) pass
+	secondPass := time.Date(2024, 11, 3, 6, 30, 45, 0, time.UTC).In(newYork)
+	tick := &models.Tick{
+		Symbol:    "AAPL",
+		Price:     150.0,
+		Size:      100,
+		Timestamp: secondPass,
+		Type:      "trade",
+	}
+	if err := sm.UpdateLiveBar("AAPL", tick); err != nil {
+		t.Fatalf("UpdateLiveBar() error = %v", err)
+	}
+
+	state := sm.GetState("AAPL")
+	state.mu.RLock()
+	barStart := state.LiveBar.Timestamp
+	state.mu.RUnlock()
+
+	want := time.Date(2024, 11, 3, 6, 30, 0, 0, time.UTC)
+	if !barStart.Equal(want) {
+		t.Errorf("Expected bar start %v, got %v", want, barStart.UTC())
+	}
+}
+
+func TestStateManager_SessionVWAP_ResetsAtSessionBoundary(t *testing.T) {
+	sm := NewStateManager(10)
+	// 09:28 ET on 2024-01-02 (EST, UTC-5)
+	premarket := time.Date(2024, 1, 2, 14, 28, 0, 0, time.UTC)
+
+	addBar := func(ts time.Time, vwap float64, volume int64) {
+		t.Helper()
+		bar := &models.Bar1m{Symbol: "AAPL", Timestamp: ts, Open: vwap, High: vwap, Low: vwap, Close: vwap, Volume: volume, VWAP: vwap}
+		if err := sm.UpdateFinalizedBar(bar); err != nil {
+			t.Fatalf("Failed to update finalized bar: %v", err)
+		}
+	}
+
+	// Premarket: 1000 shares at 90 and 1000 at 92
+	addBar(premarket, 90, 1000)
+	addBar(premarket.Add(time.Minute), 92, 1000)
+	if dist := sm.GetMetrics("AAPL")["vwap_session_dist_pct"]; dist != 1.0/91*100 {
+		t.Errorf("Expected premarket VWAP distance %v, got %v", 1.0/91*100, dist)
+	}
+
+	// The 09:30 bar opens the market session and the VWAP anchor resets to it
+	addBar(premarket.Add(2*time.Minute), 100, 500)
+	state := sm.GetState("AAPL")
+	if state.CurrentSession != SessionMarket {
+		t.Fatalf("Expected market session, got %s", state.CurrentSession)
+	}
+	if state.SessionVWAPNum != 100*500 || state.SessionVWAPVolume != 500 {
+		t.Errorf("Expected the session VWAP anchored at the market open, got %v / %d", state.SessionVWAPNum, state.SessionVWAPVolume)
+	}
+	if dist := sm.GetMetrics("AAPL")["vwap_session_dist_pct"]; dist != 0 {
+		t.Errorf("Expected no distance from a VWAP of only the first market bar, got %v", dist)
+	}
+
+	// 500 more shares at 104 put the market VWAP at 102
+	addBar(premarket.Add(3*time.Minute), 104, 500)
+	if dist := sm.GetMetrics("AAPL")["vwap_session_dist_pct"]; dist != 2.0/102*100 {
+		t.Errorf("Expected market VWAP distance %v, got %v", 2.0/102*100, dist)
+	}
+}
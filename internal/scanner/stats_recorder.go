@@ -0,0 +1,132 @@
+package scanner
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// ScanStatsRecorder periodically persists the scan loop statistics as a time series, one
+// sample per interval with counters as deltas, so history survives worker restarts
+type ScanStatsRecorder struct {
+	workerID string
+	interval time.Duration
+	stats    func() ScanLoopStats
+	storage  storage.ScanStatsStorage
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	running  bool
+	previous *ScanLoopStats // Stats at the end of the previous interval
+}
+
+// NewScanStatsRecorder creates a recorder sampling stats every interval (default: 1 minute)
+func NewScanStatsRecorder(workerID string, interval time.Duration, stats func() ScanLoopStats, statsStorage storage.ScanStatsStorage) *ScanStatsRecorder {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	initial := stats()
+
+	return &ScanStatsRecorder{
+		workerID: workerID,
+		interval: interval,
+		stats:    stats,
+		storage:  statsStorage,
+		ctx:      ctx,
+		cancel:   cancel,
+		previous: &initial,
+	}
+}
+
+// Start starts periodic persistence
+func (r *ScanStatsRecorder) Start() {
+	r.mu.Lock()
+	if r.running {
+		r.mu.Unlock()
+		return
+	}
+	r.running = true
+	r.mu.Unlock()
+
+	r.wg.Add(1)
+	go r.run()
+}
+
+// Stop stops periodic persistence
+func (r *ScanStatsRecorder) Stop() {
+	r.mu.Lock()
+	if !r.running {
+		r.mu.Unlock()
+		return
+	}
+	r.running = false
+	r.mu.Unlock()
+
+	r.cancel()
+	r.wg.Wait()
+}
+
+func (r *ScanStatsRecorder) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := r.Record(r.ctx, now); err != nil {
+				logger.Error("Failed to persist scan stats",
+					logger.ErrorField(err),
+					logger.String("worker_id", r.workerID),
+				)
+			}
+		}
+	}
+}
+
+// Record writes the activity since the previous sample, timestamped now
+func (r *ScanStatsRecorder) Record(ctx context.Context, now time.Time) error {
+	current := r.stats()
+
+	r.mu.Lock()
+	sample := newScanStatsSample(r.workerID, now, r.previous, &current)
+	r.previous = &current
+	r.mu.Unlock()
+
+	return r.storage.WriteScanStats(ctx, sample)
+}
+
+// newScanStatsSample builds a sample from the stats at the start and end of an interval
+func newScanStatsSample(workerID string, now time.Time, previous, current *ScanLoopStats) *models.ScanStatsSample {
+	sample := &models.ScanStatsSample{
+		WorkerID:            workerID,
+		Timestamp:           now.UTC(),
+		ScanCycles:          current.ScanCycles - previous.ScanCycles,
+		SymbolsScanned:      current.SymbolsScanned - previous.SymbolsScanned,
+		SymbolsSkippedStale: current.SymbolsSkippedStale - previous.SymbolsSkippedStale,
+		RulesEvaluated:      current.RulesEvaluated - previous.RulesEvaluated,
+		RulesMatched:        current.RulesMatched - previous.RulesMatched,
+		AlertsEmitted:       current.AlertsEmitted - previous.AlertsEmitted,
+		LastCycleTimeMs:     durationMs(current.ScanCycleTime),
+	}
+	if sample.ScanCycles > 0 {
+		cycleTime := current.ScanCycleTimeSum - previous.ScanCycleTimeSum
+		sample.AvgCycleTimeMs = durationMs(cycleTime) / float64(sample.ScanCycles)
+	}
+	return sample
+}
+
+// durationMs converts a duration to fractional milliseconds
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
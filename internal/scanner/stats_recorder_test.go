@@ -0,0 +1,98 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+func TestScanStatsRecorder_RecordsDeltas(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	rule := &models.Rule{
+		ID:         "rule-price",
+		Name:       "Price Above 100",
+		Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+		Enabled:    true,
+	}
+	if err := ruleStore.AddRule(rule); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	sl := NewScanLoop(DefaultScanLoopConfig(), sm, ruleStore, rules.NewCompiler(nil), nil, &recordingAlertEmitter{}, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+	tick := &models.Tick{Symbol: "AAPL", Price: 150.0, Size: 100, Timestamp: time.Now(), Type: "trade"}
+	if err := sm.UpdateLiveBar("AAPL", tick); err != nil {
+		t.Fatalf("Failed to update live bar: %v", err)
+	}
+
+	statsStorage := &storage.MockScanStatsStorage{}
+	recorder := NewScanStatsRecorder("worker-1", time.Minute, sl.GetStats, statsStorage)
+
+	sl.Scan()
+	sl.Scan()
+	first := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	if err := recorder.Record(context.Background(), first); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	sl.Scan()
+	if err := recorder.Record(context.Background(), first.Add(time.Minute)); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	samples := statsStorage.GetSamples()
+	if len(samples) != 2 {
+		t.Fatalf("Expected 2 samples, got %d", len(samples))
+	}
+	if samples[0].WorkerID != "worker-1" || !samples[0].Timestamp.Equal(first) {
+		t.Errorf("Unexpected first sample %+v", samples[0])
+	}
+	if samples[0].ScanCycles != 2 || samples[0].SymbolsScanned != 2 || samples[0].RulesMatched != 2 {
+		t.Errorf("Expected 2 cycles, symbols and matches in the first interval, got %+v", samples[0])
+	}
+	if samples[1].ScanCycles != 1 || samples[1].RulesEvaluated != 1 || samples[1].AlertsEmitted != 1 {
+		t.Errorf("Expected only the third cycle in the second interval, got %+v", samples[1])
+	}
+	if samples[0].AvgCycleTimeMs <= 0 {
+		t.Errorf("Expected a positive average cycle time, got %v", samples[0].AvgCycleTimeMs)
+	}
+}
+
+func TestScanStatsRecorder_PeriodicWrites(t *testing.T) {
+	statsStorage := &storage.MockScanStatsStorage{}
+	cycles := int64(0)
+	stats := func() ScanLoopStats {
+		cycles += 10
+		return ScanLoopStats{ScanCycles: cycles, ScanCycleTimeSum: time.Duration(cycles) * time.Millisecond}
+	}
+
+	recorder := NewScanStatsRecorder("worker-1", 10*time.Millisecond, stats, statsStorage)
+	recorder.Start()
+	defer recorder.Stop()
+
+	deadline := time.Now().Add(time.Second)
+	for len(statsStorage.GetSamples()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	recorder.Stop()
+
+	samples := statsStorage.GetSamples()
+	if len(samples) < 3 {
+		t.Fatalf("Expected at least 3 periodic samples, got %d", len(samples))
+	}
+	for i, sample := range samples {
+		if sample.ScanCycles != 10 || sample.AvgCycleTimeMs != 1 {
+			t.Errorf("Sample %d: expected 10 cycles averaging 1ms, got %+v", i, sample)
+		}
+		if i > 0 && !sample.Timestamp.After(samples[i-1].Timestamp) {
+			t.Errorf("Sample %d: timestamps should increase", i)
+		}
+	}
+}
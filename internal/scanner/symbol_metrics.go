@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// MaxTrackedSymbols caps the per-symbol metrics allowlist to bound Prometheus cardinality
+const MaxTrackedSymbols = 1000
+
+var (
+	// Per-symbol metrics, only exported for tracked symbols
+	symbolScansTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scanner_symbol_scans_total",
+			Help: "Total number of scan evaluations per tracked symbol",
+		},
+		[]string{"symbol"},
+	)
+
+	symbolMatchesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scanner_symbol_matches_total",
+			Help: "Total number of rule matches per tracked symbol",
+		},
+		[]string{"symbol"},
+	)
+)
+
+// SymbolMetrics exports detailed per-symbol Prometheus metrics for an allowlist of
+// tracked symbols. Untracked symbols are ignored so series count stays bounded.
+type SymbolMetrics struct {
+	tracked map[string]bool
+	scans   *prometheus.CounterVec
+	matches *prometheus.CounterVec
+}
+
+// ValidateTrackedSymbols checks the tracked symbol allowlist size
+func ValidateTrackedSymbols(symbols []string) error {
+	if count := len(trackedSymbolSet(symbols)); count > MaxTrackedSymbols {
+		return fmt.Errorf("tracked symbols allowlist has %d symbols, maximum is %d", count, MaxTrackedSymbols)
+	}
+	return nil
+}
+
+// trackedSymbolSet builds the tracked symbol set from the allowlist, upper-casing symbols
+// and dropping blank entries so duplicates count once against MaxTrackedSymbols
+func trackedSymbolSet(symbols []string) map[string]bool {
+	tracked := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol != "" {
+			tracked[symbol] = true
+		}
+	}
+	return tracked
+}
+
+// NewSymbolMetrics creates per-symbol metrics for the given allowlist.
+// An empty allowlist disables per-symbol metrics.
+func NewSymbolMetrics(symbols []string) (*SymbolMetrics, error) {
+	return newSymbolMetrics(symbols, symbolScansTotal, symbolMatchesTotal)
+}
+
+func newSymbolMetrics(symbols []string, scans, matches *prometheus.CounterVec) (*SymbolMetrics, error) {
+	if err := ValidateTrackedSymbols(symbols); err != nil {
+		return nil, err
+	}
+
+	return &SymbolMetrics{
+		tracked: trackedSymbolSet(symbols),
+		scans:   scans,
+		matches: matches,
+	}, nil
+}
+
+// IsTracked returns true if per-symbol metrics are exported for the symbol
+func (m *SymbolMetrics) IsTracked(symbol string) bool {
+	if m == nil || len(m.tracked) == 0 {
+		return false
+	}
+	return m.tracked[strings.ToUpper(symbol)]
+}
+
+// RecordScan records a scan evaluation of a symbol
+func (m *SymbolMetrics) RecordScan(symbol string) {
+	if !m.IsTracked(symbol) {
+		return
+	}
+	m.scans.WithLabelValues(strings.ToUpper(symbol)).Inc()
+}
+
+// RecordMatches records rule matches for a symbol in a scan cycle
+func (m *SymbolMetrics) RecordMatches(symbol string, count int) {
+	if count <= 0 || !m.IsTracked(symbol) {
+		return
+	}
+	m.matches.WithLabelValues(strings.ToUpper(symbol)).Add(float64(count))
+}
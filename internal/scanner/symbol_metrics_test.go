@@ -0,0 +1,169 @@
+package scanner
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// symbolSeries returns the counter value per symbol label exported by a counter vector
+func symbolSeries(t *testing.T, vec *prometheus.CounterVec) map[string]float64 {
+	t.Helper()
+
+	ch := make(chan prometheus.Metric, 100)
+	vec.Collect(ch)
+	close(ch)
+
+	series := make(map[string]float64)
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			t.Fatalf("Failed to write metric: %v", err)
+		}
+		for _, label := range m.GetLabel() {
+			if label.GetName() == "symbol" {
+				series[label.GetValue()] = m.GetCounter().GetValue()
+			}
+		}
+	}
+	return series
+}
+
+func newTestCounterVec(name string) *prometheus.CounterVec {
+	return prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: name}, []string{"symbol"})
+}
+
+func TestSymbolMetrics_OnlyTrackedSymbols(t *testing.T) {
+	scans := newTestCounterVec("test_symbol_scans_total")
+	matches := newTestCounterVec("test_symbol_matches_total")
+
+	m, err := newSymbolMetrics([]string{"aapl", " MSFT "}, scans, matches)
+	if err != nil {
+		t.Fatalf("newSymbolMetrics() error = %v", err)
+	}
+
+	for _, symbol := range []string{"AAPL", "MSFT", "TSLA", "GOOGL", "AAPL"} {
+		m.RecordScan(symbol)
+		m.RecordMatches(symbol, 2)
+	}
+
+	scanSeries := symbolSeries(t, scans)
+	if len(scanSeries) != 2 {
+		t.Fatalf("Expected 2 scan series, got %v", scanSeries)
+	}
+	if scanSeries["AAPL"] != 2 || scanSeries["MSFT"] != 1 {
+		t.Errorf("Unexpected scan counts: %v", scanSeries)
+	}
+
+	matchSeries := symbolSeries(t, matches)
+	if len(matchSeries) != 2 {
+		t.Fatalf("Expected 2 match series, got %v", matchSeries)
+	}
+	if matchSeries["AAPL"] != 4 || matchSeries["MSFT"] != 2 {
+		t.Errorf("Unexpected match counts: %v", matchSeries)
+	}
+}
+
+func TestSymbolMetrics_EmptyAllowlist(t *testing.T) {
+	scans := newTestCounterVec("test_symbol_scans_total")
+	matches := newTestCounterVec("test_symbol_matches_total")
+
+	m, err := newSymbolMetrics(nil, scans, matches)
+	if err != nil {
+		t.Fatalf("newSymbolMetrics() error = %v", err)
+	}
+
+	m.RecordScan("AAPL")
+	m.RecordMatches("AAPL", 1)
+
+	if series := symbolSeries(t, scans); len(series) != 0 {
+		t.Errorf("Expected no series with an empty allowlist, got %v", series)
+	}
+
+	// A nil tracker is a no-op
+	var disabled *SymbolMetrics
+	disabled.RecordScan("AAPL")
+	disabled.RecordMatches("AAPL", 1)
+}
+
+func TestValidateTrackedSymbols(t *testing.T) {
+	symbols := make([]string, MaxTrackedSymbols+1)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("SYM%d", i)
+	}
+
+	if err := ValidateTrackedSymbols(symbols[:MaxTrackedSymbols]); err != nil {
+		t.Errorf("Expected allowlist at the limit to be valid, got %v", err)
+	}
+	if err := ValidateTrackedSymbols(symbols); err == nil {
+		t.Error("Expected error for allowlist over the limit")
+	}
+	if _, err := NewSymbolMetrics(symbols); err == nil {
+		t.Error("Expected NewSymbolMetrics to reject allowlist over the limit")
+	}
+}
+
+func TestTrackedSymbolSet(t *testing.T) {
+	tracked := trackedSymbolSet([]string{" aapl ", "AAPL", "", "msft"})
+
+	if len(tracked) != 2 {
+		t.Errorf("Expected 2 tracked symbols, got %d", len(tracked))
+	}
+	for _, symbol := range []string{"AAPL", "MSFT"} {
+		if !tracked[symbol] {
+			t.Errorf("Expected %s to be tracked", symbol)
+		}
+	}
+}
+
+func TestScanLoop_TrackedSymbolMetrics(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+
+	if err := ruleStore.AddRule(&models.Rule{
+		ID:         "rule-price",
+		Name:       "Price Above 100",
+		Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+		Enabled:    true,
+	}); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	config := DefaultScanLoopConfig()
+	config.TrackedSymbols = []string{"TRKA"}
+	sl := NewScanLoop(config, sm, ruleStore, rules.NewCompiler(nil), nil, &recordingAlertEmitter{}, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	now := time.Now()
+	for _, symbol := range []string{"TRKA", "UNTRKB"} {
+		tick := &models.Tick{Symbol: symbol, Price: 150.0, Size: 100, Timestamp: now, Type: "trade"}
+		if err := sm.UpdateLiveBar(symbol, tick); err != nil {
+			t.Fatalf("Failed to update live bar: %v", err)
+		}
+	}
+
+	sl.Scan()
+
+	scanSeries := symbolSeries(t, symbolScansTotal)
+	if _, ok := scanSeries["TRKA"]; !ok {
+		t.Error("Expected scan series for tracked symbol")
+	}
+	if _, ok := scanSeries["UNTRKB"]; ok {
+		t.Error("Expected no scan series for untracked symbol")
+	}
+
+	matchSeries := symbolSeries(t, symbolMatchesTotal)
+	if matchSeries["TRKA"] < 1 {
+		t.Errorf("Expected match recorded for tracked symbol, got %v", matchSeries["TRKA"])
+	}
+	if _, ok := matchSeries["UNTRKB"]; ok {
+		t.Error("Expected no match series for untracked symbol")
+	}
+}
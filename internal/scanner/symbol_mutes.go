@@ -0,0 +1,138 @@
+package scanner
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// SymbolMuteChecker reports whether all alerts for a symbol are muted
+type SymbolMuteChecker interface {
+	IsMuted(symbol string) bool
+}
+
+// SymbolMuteSource lists the active symbol mutes (implemented by storage.SymbolMuteStore)
+type SymbolMuteSource interface {
+	ListMutes(ctx context.Context) ([]*models.SymbolMute, error)
+}
+
+// SymbolMuteCache keeps the muted symbols in memory so the scan loop can check them
+// on every emission, refreshing from the source in the background. Mute TTLs are
+// also enforced locally so a mute stops as soon as it expires.
+type SymbolMuteCache struct {
+	source   SymbolMuteSource
+	interval time.Duration
+	now      func() time.Time
+	mutes    map[string]*models.SymbolMute
+	mu       sync.RWMutex
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	running  bool
+}
+
+// NewSymbolMuteCache creates a new symbol mute cache refreshed every interval
+func NewSymbolMuteCache(source SymbolMuteSource, interval time.Duration) *SymbolMuteCache {
+	if interval <= 0 {
+		interval = 1 * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &SymbolMuteCache{
+		source:   source,
+		interval: interval,
+		now:      time.Now,
+		mutes:    make(map[string]*models.SymbolMute),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Refresh reloads the muted symbols from the source
+func (c *SymbolMuteCache) Refresh(ctx context.Context) error {
+	mutes, err := c.source.ListMutes(ctx)
+	if err != nil {
+		return err
+	}
+
+	updated := make(map[string]*models.SymbolMute, len(mutes))
+	for _, mute := range mutes {
+		updated[strings.ToUpper(mute.Symbol)] = mute
+	}
+
+	c.mu.Lock()
+	c.mutes = updated
+	c.mu.Unlock()
+
+	return nil
+}
+
+// IsMuted returns true if the symbol has an unexpired mute
+func (c *SymbolMuteCache) IsMuted(symbol string) bool {
+	c.mu.RLock()
+	mute, ok := c.mutes[strings.ToUpper(symbol)]
+	c.mu.RUnlock()
+
+	return ok && !mute.IsExpired(c.now())
+}
+
+// Start loads the mutes and starts refreshing them in the background
+func (c *SymbolMuteCache) Start() {
+	c.mu.Lock()
+	if c.running {
+		c.mu.Unlock()
+		return
+	}
+	c.running = true
+	c.mu.Unlock()
+
+	if err := c.Refresh(c.ctx); err != nil {
+		logger.Warn("Failed to load symbol mutes",
+			logger.ErrorField(err),
+		)
+	}
+
+	c.wg.Add(1)
+	go c.run()
+}
+
+// Stop stops the background refresh
+func (c *SymbolMuteCache) Stop() {
+	c.mu.Lock()
+	if !c.running {
+		c.mu.Unlock()
+		return
+	}
+	c.running = false
+	c.mu.Unlock()
+
+	c.cancel()
+	c.wg.Wait()
+}
+
+// run is the background refresh loop
+func (c *SymbolMuteCache) run() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			// Keep the last known mutes if Redis is unavailable
+			if err := c.Refresh(c.ctx); err != nil {
+				logger.Warn("Failed to refresh symbol mutes",
+					logger.ErrorField(err),
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+// alertCountBySymbol counts emitted alerts per symbol
+func alertCountBySymbol(alerts []*models.Alert) map[string]int {
+	counts := make(map[string]int)
+	for _, alert := range alerts {
+		counts[alert.Symbol]++
+	}
+	return counts
+}
+
+func TestScanLoop_SymbolMutes(t *testing.T) {
+	ctx := context.Background()
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	if err := ruleStore.AddRule(&models.Rule{
+		ID:         "rule-price",
+		Name:       "Price Above 100",
+		Conditions: []models.Condition{{Metric: "price", Operator: ">", Value: 100.0}},
+		Enabled:    true,
+	}); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	sl := NewScanLoop(DefaultScanLoopConfig(), sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	now := time.Now()
+	for _, symbol := range []string{"AAPL", "MSFT"} {
+		tick := &models.Tick{Symbol: symbol, Price: 150.0, Size: 100, Timestamp: now, Type: "trade"}
+		if err := sm.UpdateLiveBar(symbol, tick); err != nil {
+			t.Fatalf("Failed to update live bar: %v", err)
+		}
+	}
+
+	muteStore := storage.NewSymbolMuteStore(storage.NewMockRedisClient())
+	mutes := NewSymbolMuteCache(muteStore, time.Second)
+	clock := now
+	mutes.now = func() time.Time { return clock }
+	sl.SetSymbolMutes(mutes)
+
+	scan := func() map[string]int {
+		emitter.alerts = nil
+		sl.Scan()
+		return alertCountBySymbol(emitter.alerts)
+	}
+
+	// Muted with a TTL: no alerts for the symbol, others unaffected
+	if _, err := muteStore.Mute(ctx, "AAPL", "bad data", 10*time.Minute); err != nil {
+		t.Fatalf("Mute() error = %v", err)
+	}
+	if err := mutes.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if counts := scan(); counts["AAPL"] != 0 || counts["MSFT"] != 1 {
+		t.Errorf("Expected alerts only for MSFT while AAPL is muted, got %v", counts)
+	}
+	if stats := sl.GetStats(); stats.AlertsMuted != 1 {
+		t.Errorf("Expected 1 muted alert, got %d", stats.AlertsMuted)
+	}
+
+	// TTL expiry ends the mute even before the next refresh
+	clock = clock.Add(11 * time.Minute)
+	if counts := scan(); counts["AAPL"] != 1 {
+		t.Errorf("Expected AAPL alert after mute TTL expiry, got %v", counts)
+	}
+
+	// Muted until unmuted
+	if _, err := muteStore.Mute(ctx, "AAPL", "maintenance", 0); err != nil {
+		t.Fatalf("Mute() error = %v", err)
+	}
+	if err := mutes.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	clock = clock.Add(24 * time.Hour)
+	if counts := scan(); counts["AAPL"] != 0 {
+		t.Errorf("Expected no AAPL alerts while muted without TTL, got %v", counts)
+	}
+
+	if err := muteStore.Unmute(ctx, "AAPL"); err != nil {
+		t.Fatalf("Unmute() error = %v", err)
+	}
+	if err := mutes.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if counts := scan(); counts["AAPL"] != 1 {
+		t.Errorf("Expected AAPL alert after unmute, got %v", counts)
+	}
+}
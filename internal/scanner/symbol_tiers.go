@@ -0,0 +1,149 @@
+package scanner
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SymbolTier scans symbols whose daily volume is at least MinDailyVolume once per Interval.
+// A symbol belongs to the highest tier it qualifies for.
+type SymbolTier struct {
+	Name           string
+	MinDailyVolume int64
+	Interval       time.Duration
+}
+
+// ParseSymbolTiers parses symbol tiers from "name:min_daily_volume:interval,..."
+// (e.g. "liquid:1000000:1s,thin:0:5s")
+func ParseSymbolTiers(spec string) ([]SymbolTier, error) {
+	var tiers []SymbolTier
+	seenNames := make(map[string]bool)
+	seenVolumes := make(map[int64]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid symbol tier %q (expected name:min_daily_volume:interval)", entry)
+		}
+
+		name := strings.TrimSpace(parts[0])
+		if name == "" {
+			return nil, fmt.Errorf("invalid symbol tier %q: name is required", entry)
+		}
+		if seenNames[name] {
+			return nil, fmt.Errorf("duplicate symbol tier %q", name)
+		}
+
+		minVolume, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil || minVolume < 0 {
+			return nil, fmt.Errorf("invalid symbol tier %q: min_daily_volume must be a non-negative integer", entry)
+		}
+		if seenVolumes[minVolume] {
+			return nil, fmt.Errorf("invalid symbol tier %q: another tier has min_daily_volume %d", entry, minVolume)
+		}
+
+		interval, err := time.ParseDuration(strings.TrimSpace(parts[2]))
+		if err != nil || interval < 0 {
+			return nil, fmt.Errorf("invalid symbol tier %q: interval must be a non-negative duration", entry)
+		}
+
+		seenNames[name] = true
+		seenVolumes[minVolume] = true
+		tiers = append(tiers, SymbolTier{Name: name, MinDailyVolume: minVolume, Interval: interval})
+	}
+	return tiers, nil
+}
+
+// symbolScheduler limits symbols to one scan per interval of their tier. Symbols below
+// every tier's volume threshold are scanned every cycle.
+type symbolScheduler struct {
+	tiers       []SymbolTier // Sorted by MinDailyVolume, highest first
+	maxInterval time.Duration
+	mu          sync.Mutex
+	lastScanned map[string]time.Time // symbol -> start of the last cycle the symbol was scanned in
+}
+
+// newSymbolScheduler creates a scheduler for the given tiers (nil = every symbol scanned every cycle)
+func newSymbolScheduler(tiers []SymbolTier) *symbolScheduler {
+	if len(tiers) == 0 {
+		return nil
+	}
+	sorted := append([]SymbolTier(nil), tiers...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].MinDailyVolume > sorted[j].MinDailyVolume
+	})
+	var maxInterval time.Duration
+	for _, tier := range sorted {
+		maxInterval = max(maxInterval, tier.Interval)
+	}
+	return &symbolScheduler{
+		tiers:       sorted,
+		maxInterval: maxInterval,
+		lastScanned: make(map[string]time.Time),
+	}
+}
+
+// Due returns whether the symbol is scanned in the cycle starting at now, and if so records
+// now as its last scan. Safe to call on a nil scheduler.
+func (s *symbolScheduler) Due(snapshot *SymbolStateSnapshot, now time.Time) bool {
+	if s == nil {
+		return true
+	}
+
+	tier, ok := s.tierFor(snapshot)
+	if !ok || tier.Interval <= 0 {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last, scanned := s.lastScanned[snapshot.Symbol]
+	if scanned && now.Sub(last) < tier.Interval {
+		return false
+	}
+	s.lastScanned[snapshot.Symbol] = now
+	return true
+}
+
+// Prune forgets symbols last scanned at least the longest tier interval before now. They are
+// due again whatever their tier, so only symbols that stopped trading or were removed are
+// affected. Safe to call on a nil scheduler.
+func (s *symbolScheduler) Prune(now time.Time) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for symbol, last := range s.lastScanned {
+		if now.Sub(last) >= s.maxInterval {
+			delete(s.lastScanned, symbol)
+		}
+	}
+}
+
+// tierFor returns the highest tier whose volume threshold the symbol's daily volume meets
+func (s *symbolScheduler) tierFor(snapshot *SymbolStateSnapshot) (SymbolTier, bool) {
+	volume := dailyVolume(snapshot)
+	for _, tier := range s.tiers {
+		if volume >= tier.MinDailyVolume {
+			return tier, true
+		}
+	}
+	return SymbolTier{}, false
+}
+
+// dailyVolume returns the volume traded so far today, from the session volume counters
+// (reset when a new day's pre-market starts)
+func dailyVolume(snapshot *SymbolStateSnapshot) int64 {
+	return snapshot.PremarketVolume + snapshot.MarketVolume + snapshot.PostmarketVolume
+}
@@ -0,0 +1,173 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/rules"
+)
+
+func TestParseSymbolTiers(t *testing.T) {
+	tiers, err := ParseSymbolTiers(" liquid:1000000:1s, thin:0:5s ,")
+	if err != nil {
+		t.Fatalf("ParseSymbolTiers() error = %v", err)
+	}
+	want := []SymbolTier{
+		{Name: "liquid", MinDailyVolume: 1000000, Interval: time.Second},
+		{Name: "thin", MinDailyVolume: 0, Interval: 5 * time.Second},
+	}
+	if len(tiers) != len(want) {
+		t.Fatalf("Expected %d tiers, got %d", len(want), len(tiers))
+	}
+	for i := range want {
+		if tiers[i] != want[i] {
+			t.Errorf("tiers[%d] = %+v, want %+v", i, tiers[i], want[i])
+		}
+	}
+
+	if tiers, err := ParseSymbolTiers(""); err != nil || len(tiers) != 0 {
+		t.Errorf("Expected no tiers for empty spec, got %v, %v", tiers, err)
+	}
+
+	invalid := []string{
+		"liquid:1000000",
+		":0:5s",
+		"thin:-1:5s",
+		"thin:abc:5s",
+		"thin:0:soon",
+		"thin:0:-5s",
+		"thin:0:5s,thin:100:10s",
+		"a:100:5s,b:100:10s",
+	}
+	for _, spec := range invalid {
+		if _, err := ParseSymbolTiers(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestScanLoop_SymbolTiersScanCadence(t *testing.T) {
+	sm := NewStateManager(10)
+	ruleStore := rules.NewInMemoryRuleStore()
+	emitter := &recordingAlertEmitter{}
+
+	rule := &models.Rule{
+		ID:   "rule-price",
+		Name: "Price Above 100",
+		Conditions: []models.Condition{
+			{Metric: "price", Operator: ">", Value: 100.0},
+		},
+		Enabled: true,
+	}
+	if err := ruleStore.AddRule(rule); err != nil {
+		t.Fatalf("Failed to add rule: %v", err)
+	}
+
+	config := DefaultScanLoopConfig()
+	config.SymbolTiers = []SymbolTier{
+		{Name: "thin", MinDailyVolume: 0, Interval: 5 * time.Second},
+		{Name: "liquid", MinDailyVolume: 1000000, Interval: time.Second},
+	}
+
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	clock := NewSimulationClock(start)
+	sl := NewScanLoop(config, sm, ruleStore, rules.NewCompiler(nil), nil, emitter, nil)
+	sl.SetClock(clock)
+	if err := sl.ReloadRules(); err != nil {
+		t.Fatalf("Failed to reload rules: %v", err)
+	}
+
+	ticks := []*models.Tick{
+		{Symbol: "AAPL", Price: 150.0, Size: 2000000, Timestamp: start, Type: "trade"},
+		{Symbol: "THIN", Price: 120.0, Size: 100, Timestamp: start, Type: "trade"},
+	}
+	for _, tick := range ticks {
+		if err := sm.UpdateLiveBar(tick.Symbol, tick); err != nil {
+			t.Fatalf("Failed to update live bar: %v", err)
+		}
+	}
+
+	// One cycle per second for 11 seconds: the liquid symbol is scanned every cycle, the
+	// thin symbol at 0s, 5s and 10s
+	for second := 0; second <= 10; second++ {
+		clock.Observe(start.Add(time.Duration(second) * time.Second))
+		sl.Scan()
+	}
+
+	counts := make(map[string]int)
+	for _, alert := range emitter.alerts {
+		counts[alert.Symbol]++
+	}
+	if counts["AAPL"] != 11 {
+		t.Errorf("Expected liquid symbol evaluated 11 times, got %d", counts["AAPL"])
+	}
+	if counts["THIN"] != 3 {
+		t.Errorf("Expected thin symbol evaluated 3 times, got %d", counts["THIN"])
+	}
+
+	stats := sl.GetStats()
+	if stats.SymbolsScanned != 14 {
+		t.Errorf("Expected 14 symbol scans, got %d", stats.SymbolsScanned)
+	}
+	if stats.SymbolsSkippedTier != 8 {
+		t.Errorf("Expected 8 tier skips, got %d", stats.SymbolsSkippedTier)
+	}
+}
+
+func TestSymbolScheduler_UntieredSymbolsScannedEveryCycle(t *testing.T) {
+	s := newSymbolScheduler([]SymbolTier{{Name: "mid", MinDailyVolume: 1000, Interval: 10 * time.Second}})
+	snapshot := &SymbolStateSnapshot{Symbol: "TINY", LiveBar: &models.LiveBar{Volume: 10}}
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	for second := 0; second < 3; second++ {
+		if !s.Due(snapshot, start.Add(time.Duration(second)*time.Second)) {
+			t.Errorf("At %ds: symbol below every tier should be scanned", second)
+		}
+	}
+
+	// A nil scheduler scans everything
+	var none *symbolScheduler
+	if !none.Due(snapshot, start) {
+		t.Error("Expected nil scheduler to scan every symbol")
+	}
+}
+
+func TestSymbolScheduler_TierFromSessionVolume(t *testing.T) {
+	s := newSymbolScheduler([]SymbolTier{{Name: "liquid", MinDailyVolume: 1000, Interval: 10 * time.Second}})
+
+	// Only the last bars are kept in memory; the day's volume comes from the session counters
+	snapshot := &SymbolStateSnapshot{
+		Symbol:          "AAPL",
+		LastFinalBars:   []*models.Bar1m{{Volume: 100}},
+		PremarketVolume: 600,
+		MarketVolume:    500,
+	}
+	tier, ok := s.tierFor(snapshot)
+	if !ok || tier.Name != "liquid" {
+		t.Errorf("Expected liquid tier for 1100 shares traded today, got %+v (ok=%v)", tier, ok)
+	}
+}
+
+func TestSymbolScheduler_Prune(t *testing.T) {
+	s := newSymbolScheduler([]SymbolTier{
+		{Name: "liquid", MinDailyVolume: 1000, Interval: time.Second},
+		{Name: "thin", MinDailyVolume: 0, Interval: 5 * time.Second},
+	})
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	s.Due(&SymbolStateSnapshot{Symbol: "OLD"}, start)
+	s.Due(&SymbolStateSnapshot{Symbol: "NEW"}, start.Add(3*time.Second))
+	s.Prune(start.Add(5 * time.Second))
+
+	if _, ok := s.lastScanned["OLD"]; ok {
+		t.Error("Expected symbol not scanned for the longest tier interval to be pruned")
+	}
+	if _, ok := s.lastScanned["NEW"]; !ok {
+		t.Error("Expected recently scanned symbol to be kept")
+	}
+
+	// A nil scheduler has nothing to prune
+	var none *symbolScheduler
+	none.Prune(start)
+}
@@ -0,0 +1,57 @@
+package scanner
+
+import (
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// SymbolUniverseHandler applies symbol universe changes to a running worker: the universe is
+// repartitioned across workers and the state of symbols dropped from it is removed, so they
+// stop being scanned.
+type SymbolUniverseHandler struct {
+	partitions *PartitionManager
+	state      *StateManager
+	keep       map[string]bool // Symbols whose state is kept outside the universe (reference symbols)
+}
+
+// NewSymbolUniverseHandler creates a handler. keepSymbols (e.g. reference symbols) keep their
+// state even when they are not in the universe.
+func NewSymbolUniverseHandler(partitions *PartitionManager, state *StateManager, keepSymbols []string) *SymbolUniverseHandler {
+	keep := make(map[string]bool, len(keepSymbols))
+	for _, symbol := range keepSymbols {
+		keep[symbol] = true
+	}
+	return &SymbolUniverseHandler{
+		partitions: partitions,
+		state:      state,
+		keep:       keep,
+	}
+}
+
+// Apply repartitions the universe and removes the state of symbols no longer in it or no
+// longer owned by this worker. It returns the symbols newly assigned to this worker and
+// those no longer assigned.
+func (h *SymbolUniverseHandler) Apply(universe []string) (added, removed []string) {
+	added, removed = h.partitions.Repartition(universe)
+
+	inUniverse := make(map[string]bool, len(universe))
+	for _, symbol := range universe {
+		inUniverse[symbol] = true
+	}
+
+	dropped := 0
+	for _, symbol := range h.state.GetSymbols() {
+		if !h.keep[symbol] && (!inUniverse[symbol] || !h.partitions.IsOwned(symbol)) {
+			h.state.RemoveSymbol(symbol)
+			dropped++
+		}
+	}
+
+	logger.Info("Applied symbol universe",
+		logger.Int("universe_size", len(universe)),
+		logger.Int("assigned", h.partitions.GetAssignedSymbolCount()),
+		logger.Int("added", len(added)),
+		logger.Int("removed", len(removed)),
+		logger.Int("state_dropped", dropped),
+	)
+	return added, removed
+}
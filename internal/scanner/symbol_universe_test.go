@@ -0,0 +1,121 @@
+package scanner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+func TestPartitionManager_Repartition(t *testing.T) {
+	pm, err := NewPartitionManager(0, 2)
+	if err != nil {
+		t.Fatalf("Failed to create partition manager: %v", err)
+	}
+
+	// Find a symbol owned by each worker
+	var owned, other string
+	for _, symbol := range []string{"AAPL", "MSFT", "GOOGL", "TSLA", "NVDA", "AMZN", "META", "NFLX"} {
+		if pm.IsOwned(symbol) && owned == "" {
+			owned = symbol
+		} else if !pm.IsOwned(symbol) && other == "" {
+			other = symbol
+		}
+	}
+	if owned == "" || other == "" {
+		t.Fatal("Expected symbols on both workers")
+	}
+
+	added, removed := pm.Repartition([]string{other})
+	if len(added) != 0 || len(removed) != 0 || pm.GetAssignedSymbolCount() != 0 {
+		t.Errorf("Expected nothing assigned, got added %v removed %v", added, removed)
+	}
+
+	// A symbol added to the universe appears in its worker's assigned set
+	added, removed = pm.Repartition([]string{other, owned})
+	if len(added) != 1 || added[0] != owned || len(removed) != 0 {
+		t.Errorf("Expected %s added, got added %v removed %v", owned, added, removed)
+	}
+	if !pm.IsAssigned(owned) || pm.IsAssigned(other) {
+		t.Errorf("Expected only %s assigned, got %v", owned, pm.GetAssignedSymbols())
+	}
+
+	added, removed = pm.Repartition([]string{other})
+	if len(added) != 0 || len(removed) != 1 || removed[0] != owned {
+		t.Errorf("Expected %s removed, got added %v removed %v", owned, added, removed)
+	}
+	if pm.IsAssigned(owned) {
+		t.Errorf("Expected %s to be unassigned", owned)
+	}
+}
+
+func TestSymbolUniverseHandler_Apply(t *testing.T) {
+	pm, err := NewPartitionManager(0, 1)
+	if err != nil {
+		t.Fatalf("Failed to create partition manager: %v", err)
+	}
+	sm := NewStateManager(10)
+	for _, symbol := range []string{"AAPL", "MSFT", "SPY"} {
+		tick := &models.Tick{Symbol: symbol, Price: 100.0, Size: 100, Timestamp: time.Now(), Type: "trade"}
+		if err := sm.UpdateLiveBar(symbol, tick); err != nil {
+			t.Fatalf("Failed to update live bar: %v", err)
+		}
+	}
+
+	handler := NewSymbolUniverseHandler(pm, sm, []string{"SPY"})
+	added, removed := handler.Apply([]string{"AAPL", "MSFT"})
+	if len(added) != 2 || len(removed) != 0 {
+		t.Errorf("Expected 2 symbols added, got added %v removed %v", added, removed)
+	}
+
+	// MSFT leaves the universe and its state is dropped; reference symbol SPY is kept
+	added, removed = handler.Apply([]string{"AAPL", "TSLA"})
+	if len(added) != 1 || added[0] != "TSLA" || len(removed) != 1 || removed[0] != "MSFT" {
+		t.Errorf("Expected TSLA added and MSFT removed, got added %v removed %v", added, removed)
+	}
+	if !pm.IsAssigned("TSLA") || pm.IsAssigned("MSFT") {
+		t.Errorf("Expected TSLA assigned and MSFT not, got %v", pm.GetAssignedSymbols())
+	}
+	if sm.GetState("MSFT") != nil {
+		t.Error("Expected MSFT state to be removed")
+	}
+	if sm.GetState("AAPL") == nil || sm.GetState("SPY") == nil {
+		t.Error("Expected AAPL and SPY state to be kept")
+	}
+}
+
+func TestSymbolUniverseHandler_ApplyDropsUnownedState(t *testing.T) {
+	pm, err := NewPartitionManager(0, 2)
+	if err != nil {
+		t.Fatalf("Failed to create partition manager: %v", err)
+	}
+
+	var owned, other string
+	for _, symbol := range []string{"AAPL", "MSFT", "GOOGL", "TSLA", "NVDA", "AMZN", "META", "NFLX"} {
+		if pm.IsOwned(symbol) && owned == "" {
+			owned = symbol
+		} else if !pm.IsOwned(symbol) && other == "" {
+			other = symbol
+		}
+	}
+	if owned == "" || other == "" {
+		t.Fatal("Expected symbols on both workers")
+	}
+
+	sm := NewStateManager(10)
+	for _, symbol := range []string{owned, other} {
+		tick := &models.Tick{Symbol: symbol, Price: 100.0, Size: 100, Timestamp: time.Now(), Type: "trade"}
+		if err := sm.UpdateLiveBar(symbol, tick); err != nil {
+			t.Fatalf("Failed to update live bar: %v", err)
+		}
+	}
+
+	// State rehydrated for the whole universe keeps only this worker's symbols
+	NewSymbolUniverseHandler(pm, sm, nil).Apply([]string{owned, other})
+	if sm.GetState(owned) == nil {
+		t.Errorf("Expected %s state to be kept", owned)
+	}
+	if sm.GetState(other) != nil {
+		t.Errorf("Expected %s state owned by another worker to be removed", other)
+	}
+}
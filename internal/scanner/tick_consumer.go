@@ -11,6 +11,33 @@ import (
 	"github.com/mohamedkhairy/stock-scanner/internal/pubsub"
 	"github.com/mohamedkhairy/stock-scanner/internal/storage"
 	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Metrics for tick consumer saturation
+	tickQueueDepth = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "scanner_tick_queue_depth",
+			Help: "Number of ticks received but not yet applied to symbol state",
+		},
+		[]string{"stream"},
+	)
+
+	ticksDropped = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "scanner_ticks_dropped_total",
+			Help: "Total number of ticks dropped without updating symbol state",
+		},
+		[]string{"stream", "reason"},
+	)
+)
+
+// Reasons a tick is dropped
+const (
+	dropReasonInvalid     = "invalid"
+	dropReasonStateUpdate = "state_update"
 )
 
 // TickConsumer consumes ticks from Redis streams and updates symbol state
@@ -24,6 +51,8 @@ type TickConsumer struct {
 	mu           sync.RWMutex
 	running      bool
 	stats        TickConsumerStats
+	queueDepths  map[string]int64 // Current backlog per stream (guarded by stats.mu)
+	filter       SymbolFilter     // Symbols whose ticks are applied (nil = all)
 }
 
 // TickConsumerStats holds statistics about the tick consumer
@@ -31,8 +60,15 @@ type TickConsumerStats struct {
 	TicksProcessed int64
 	TicksAcked     int64
 	TicksFailed    int64
+	TicksDropped   int64 // Ticks discarded without updating state
+	TicksSkipped   int64 // Ticks of symbols owned by other workers, acknowledged unapplied
 	LastTickTime   time.Time
 	Lag            int64
+	QueueDepth     int64 // Ticks received but not yet applied to state (all streams)
+	MaxQueueDepth  int64 // Highest queue depth observed
+	StreamLength   int64 // Entries in the consumed streams (XLEN)
+	PendingCount   int64 // Entries delivered to the consumer group but not yet acknowledged (XPENDING)
+	LagMs          int64 // Age of the consumer group's oldest pending entry
 	mu             sync.RWMutex
 }
 
@@ -51,6 +87,7 @@ func NewTickConsumer(redis storage.RedisClient, config pubsub.StreamConsumerConf
 		ctx:          ctx,
 		cancel:       cancel,
 		stats:        TickConsumerStats{},
+		queueDepths:  make(map[string]int64),
 	}
 }
 
@@ -84,9 +121,45 @@ func (tc *TickConsumer) Start() error {
 		go tc.consumeStream(stream)
 	}
 
+	if tc.config.BacklogInterval > 0 {
+		tc.wg.Add(1)
+		go tc.monitorBacklog()
+	}
+
 	return nil
 }
 
+// monitorBacklog periodically refreshes the consumer group's pending entries and lag, so
+// GetStats never waits on Redis
+func (tc *TickConsumer) monitorBacklog() {
+	defer tc.wg.Done()
+
+	tc.refreshBacklog()
+
+	ticker := time.NewTicker(tc.config.BacklogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-tc.ctx.Done():
+			return
+		case <-ticker.C:
+			tc.refreshBacklog()
+		}
+	}
+}
+
+// refreshBacklog queries the consumer group's backlog and caches it in the stats
+func (tc *TickConsumer) refreshBacklog() {
+	backlog := pubsub.StreamBacklog(tc.redis, tc.getStreams(), tc.config.ConsumerGroup)
+
+	tc.stats.mu.Lock()
+	defer tc.stats.mu.Unlock()
+	tc.stats.StreamLength = backlog.StreamLength
+	tc.stats.PendingCount = backlog.PendingCount
+	tc.stats.LagMs = backlog.LagMs
+}
+
 // Stop stops the tick consumer
 func (tc *TickConsumer) Stop() {
 	tc.mu.Lock()
@@ -112,7 +185,13 @@ func (tc *TickConsumer) IsRunning() bool {
 	return tc.running
 }
 
-// GetStats returns current consumer statistics
+// SetSymbolFilter sets which symbols' ticks are applied; the rest are acknowledged and skipped (call before Start)
+func (tc *TickConsumer) SetSymbolFilter(filter SymbolFilter) {
+	tc.filter = filter
+}
+
+// GetStats returns current consumer statistics, including the consumer group's backlog as
+// of the last refresh
 func (tc *TickConsumer) GetStats() TickConsumerStats {
 	tc.stats.mu.RLock()
 	defer tc.stats.mu.RUnlock()
@@ -122,8 +201,15 @@ func (tc *TickConsumer) GetStats() TickConsumerStats {
 		TicksProcessed: tc.stats.TicksProcessed,
 		TicksAcked:     tc.stats.TicksAcked,
 		TicksFailed:    tc.stats.TicksFailed,
+		TicksDropped:   tc.stats.TicksDropped,
+		TicksSkipped:   tc.stats.TicksSkipped,
 		LastTickTime:   tc.stats.LastTickTime,
 		Lag:            tc.stats.Lag,
+		QueueDepth:     tc.stats.QueueDepth,
+		MaxQueueDepth:  tc.stats.MaxQueueDepth,
+		StreamLength:   tc.stats.StreamLength,
+		PendingCount:   tc.stats.PendingCount,
+		LagMs:          tc.stats.LagMs,
 	}
 }
 
@@ -176,15 +262,22 @@ func (tc *TickConsumer) consumeStream(stream string) {
 				logger.Warn("Message channel closed",
 					logger.String("stream", stream),
 				)
+				// Apply what was already received before exiting
+				if len(batch) > 0 {
+					tc.processBatch(stream, batch)
+				}
+				tc.setQueueDepth(stream, 0)
 				return
 			}
 
 			batch = append(batch, msg)
+			tc.setQueueDepth(stream, int64(len(messageChan)+len(batch)))
 
 			// Process batch if it's full
 			if len(batch) >= tc.config.BatchSize {
 				tc.processBatch(stream, batch)
 				batch = batch[:0] // Clear batch
+				tc.setQueueDepth(stream, int64(len(messageChan)))
 			}
 
 		case <-ticker.C:
@@ -193,6 +286,7 @@ func (tc *TickConsumer) consumeStream(stream string) {
 				tc.processBatch(stream, batch)
 				batch = batch[:0] // Clear batch
 			}
+			tc.setQueueDepth(stream, int64(len(messageChan)))
 		}
 	}
 }
@@ -216,6 +310,13 @@ func (tc *TickConsumer) processBatch(stream string, messages []storage.StreamMes
 			)
 			failed = append(failed, msg.ID)
 			tc.incrementFailed()
+			tc.incrementDropped(stream, dropReasonInvalid)
+			continue
+		}
+
+		if tc.filter != nil && !tc.filter(tick.Symbol) {
+			processed = append(processed, msg.ID)
+			tc.incrementSkipped()
 			continue
 		}
 
@@ -229,6 +330,7 @@ func (tc *TickConsumer) processBatch(stream string, messages []storage.StreamMes
 			)
 			failed = append(failed, msg.ID)
 			tc.incrementFailed()
+			tc.incrementDropped(stream, dropReasonStateUpdate)
 			continue
 		}
 
@@ -321,3 +423,33 @@ func (tc *TickConsumer) incrementFailed() {
 	tc.stats.TicksFailed++
 }
 
+// incrementSkipped increments the skipped tick counter
+func (tc *TickConsumer) incrementSkipped() {
+	tc.stats.mu.Lock()
+	defer tc.stats.mu.Unlock()
+	tc.stats.TicksSkipped++
+}
+
+// incrementDropped increments the dropped tick counter
+func (tc *TickConsumer) incrementDropped(stream string, reason string) {
+	tc.stats.mu.Lock()
+	tc.stats.TicksDropped++
+	tc.stats.mu.Unlock()
+	ticksDropped.WithLabelValues(stream, reason).Inc()
+}
+
+// setQueueDepth records the current backlog for a stream
+func (tc *TickConsumer) setQueueDepth(stream string, depth int64) {
+	tc.stats.mu.Lock()
+	tc.queueDepths[stream] = depth
+	var total int64
+	for _, d := range tc.queueDepths {
+		total += d
+	}
+	tc.stats.QueueDepth = total
+	if total > tc.stats.MaxQueueDepth {
+		tc.stats.MaxQueueDepth = total
+	}
+	tc.stats.mu.Unlock()
+	tickQueueDepth.WithLabelValues(stream).Set(float64(depth))
+}
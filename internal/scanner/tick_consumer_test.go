@@ -1,6 +1,7 @@
 package scanner
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -184,3 +185,145 @@ func TestTickConsumer_IsRunning(t *testing.T) {
 	// This would require more sophisticated mocking
 }
 
+
+func TestTickConsumer_QueueDepthUnderBacklog(t *testing.T) {
+	sm := NewStateManager(10)
+	config := pubsub.DefaultStreamConsumerConfig("ticks", "scanner-group", "scanner-1")
+	config.BatchSize = 10
+	config.AckTimeout = time.Hour // Only full batches are processed
+	redis := storage.NewMockRedisClient()
+
+	// Build a backlog of ticks waiting in the stream
+	for i := 0; i < 100; i++ {
+		tick := &models.Tick{
+			Symbol:    fmt.Sprintf("SYM%d", i%5),
+			Price:     100.0 + float64(i),
+			Size:      100,
+			Timestamp: time.Now(),
+			Type:      "trade",
+		}
+		tickJSON, _ := json.Marshal(tick)
+		redis.StreamData = append(redis.StreamData, storage.StreamMessage{
+			ID:     fmt.Sprintf("%d-0", i),
+			Stream: "ticks",
+			Values: map[string]interface{}{"tick": string(tickJSON)},
+		})
+	}
+
+	tc := NewTickConsumer(redis, config, sm)
+	if err := tc.Start(); err != nil {
+		t.Fatalf("Failed to start tick consumer: %v", err)
+	}
+	defer tc.Stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for tc.GetStats().TicksProcessed < 100 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	stats := tc.GetStats()
+	if stats.TicksProcessed != 100 {
+		t.Fatalf("Expected 100 ticks processed, got %d", stats.TicksProcessed)
+	}
+
+	// Depth rose while the backlog was queued...
+	if stats.MaxQueueDepth < 90 {
+		t.Errorf("Expected max queue depth >= 90 under backlog, got %d", stats.MaxQueueDepth)
+	}
+
+	// ...and recovered once it drained
+	deadline = time.Now().Add(time.Second)
+	for tc.GetStats().QueueDepth != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if depth := tc.GetStats().QueueDepth; depth != 0 {
+		t.Errorf("Expected queue depth 0 after draining, got %d", depth)
+	}
+	if stats.TicksDropped != 0 {
+		t.Errorf("Expected no dropped ticks, got %d", stats.TicksDropped)
+	}
+}
+
+func TestTickConsumer_DroppedTicks(t *testing.T) {
+	sm := NewStateManager(10)
+	config := pubsub.DefaultStreamConsumerConfig("ticks", "scanner-group", "scanner-1")
+	redis := storage.NewMockRedisClient()
+	tc := NewTickConsumer(redis, config, sm)
+
+	messages := []storage.StreamMessage{
+		{ID: "1-0", Values: map[string]interface{}{"tick": "not json"}},
+		{ID: "2-0", Values: map[string]interface{}{"tick": `{"symbol":"","price":0}`}},
+	}
+	tc.processBatch("ticks", messages)
+
+	stats := tc.GetStats()
+	if stats.TicksDropped != 2 {
+		t.Errorf("Expected 2 dropped ticks, got %d", stats.TicksDropped)
+	}
+	if stats.TicksProcessed != 0 {
+		t.Errorf("Expected 0 ticks processed, got %d", stats.TicksProcessed)
+	}
+}
+
+func TestTickConsumer_StatsBacklog(t *testing.T) {
+	sm := NewStateManager(10)
+	config := pubsub.DefaultStreamConsumerConfig("ticks", "scanner-group", "scanner-1")
+	redis := storage.NewMockRedisClient()
+	for i := 0; i < 4; i++ {
+		redis.StreamData = append(redis.StreamData, storage.StreamMessage{
+			ID:     fmt.Sprintf("%d-0", i),
+			Stream: "ticks",
+		})
+	}
+	if _, err := redis.ConsumeFromStream(context.Background(), "ticks", "scanner-group", "scanner-1"); err != nil {
+		t.Fatalf("ConsumeFromStream() error = %v", err)
+	}
+	redis.AcknowledgeMessage(nil, "ticks", "scanner-group", "0-0")
+
+	tc := NewTickConsumer(redis, config, sm)
+	if stats := tc.GetStats(); stats.StreamLength != 0 {
+		t.Errorf("Expected no backlog before the first refresh, got stream length %d", stats.StreamLength)
+	}
+
+	tc.refreshBacklog()
+	stats := tc.GetStats()
+	if stats.StreamLength != 4 {
+		t.Errorf("Expected stream length 4, got %d", stats.StreamLength)
+	}
+	if stats.PendingCount != 3 {
+		t.Errorf("Expected 3 pending entries, got %d", stats.PendingCount)
+	}
+}
+
+func TestTickConsumer_SymbolFilter(t *testing.T) {
+	sm := NewStateManager(10)
+	config := pubsub.DefaultStreamConsumerConfig("ticks", "scanner-group-0", "scanner-1")
+	redis := storage.NewMockRedisClient()
+	tc := NewTickConsumer(redis, config, sm)
+	tc.SetSymbolFilter(func(symbol string) bool { return symbol == "AAPL" })
+
+	messages := make([]storage.StreamMessage, 0, 2)
+	for i, symbol := range []string{"AAPL", "GOOGL"} {
+		tickJSON, _ := json.Marshal(&models.Tick{Symbol: symbol, Price: 100.0, Size: 10, Timestamp: time.Now(), Type: "trade"})
+		messages = append(messages, storage.StreamMessage{
+			ID:     fmt.Sprintf("%d-0", i),
+			Values: map[string]interface{}{"tick": string(tickJSON)},
+		})
+	}
+	tc.processBatch("ticks", messages)
+
+	if sm.GetState("AAPL") == nil {
+		t.Error("Expected AAPL state to exist")
+	}
+	if sm.GetState("GOOGL") != nil {
+		t.Error("Expected GOOGL tick to be skipped")
+	}
+
+	stats := tc.GetStats()
+	if stats.TicksProcessed != 1 || stats.TicksSkipped != 1 {
+		t.Errorf("Expected 1 processed and 1 skipped tick, got %d and %d", stats.TicksProcessed, stats.TicksSkipped)
+	}
+	if stats.TicksAcked != 2 {
+		t.Errorf("Expected skipped ticks to be acknowledged, got %d acked", stats.TicksAcked)
+	}
+}
@@ -3,6 +3,7 @@ package scanner
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
@@ -15,6 +16,7 @@ type ToplistIntegration struct {
 	updater        toplist.ToplistUpdater
 	store          toplist.ToplistStore
 	mapper         *toplist.MetricMapper
+	normalizer     *toplist.Normalizer // Per-symbol history for normalized toplists
 	enabled        bool
 	updateInterval time.Duration
 	reloadInterval time.Duration
@@ -22,6 +24,8 @@ type ToplistIntegration struct {
 	lastReload     time.Time
 	updates        []toplist.ToplistUpdate
 	toplists       []*models.ToplistConfig // Cached enabled toplists
+	chunkSize      int                     // Max updates per BatchUpdate pipeline (0 = single pipeline)
+	concurrency    int                     // Max chunks flushed concurrently
 	mu             sync.RWMutex
 }
 
@@ -31,6 +35,7 @@ func NewToplistIntegration(updater toplist.ToplistUpdater, store toplist.Toplist
 		updater:        updater,
 		store:          store,
 		mapper:         toplist.NewMetricMapper(),
+		normalizer:     toplist.NewNormalizer(toplist.DefaultNormalizationWindow),
 		enabled:        enabled,
 		updateInterval: updateInterval,
 		reloadInterval: 30 * time.Second, // Reload toplists every 30 seconds
@@ -38,9 +43,35 @@ func NewToplistIntegration(updater toplist.ToplistUpdater, store toplist.Toplist
 		lastReload:     time.Time{}, // Will trigger immediate reload
 		updates:        make([]toplist.ToplistUpdate, 0, 100),
 		toplists:       make([]*models.ToplistConfig, 0),
+		concurrency:    1,
 	}
 }
 
+// SetFlushLimits splits flushed updates into pipelines of at most chunkSize updates
+// (0 = a single pipeline), with up to concurrency chunks in flight at once
+func (ti *ToplistIntegration) SetFlushLimits(chunkSize, concurrency int) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	ti.chunkSize = chunkSize
+	ti.concurrency = concurrency
+}
+
+// SetNormalizationWindow sets how many recent values per symbol normalized toplists compare against.
+// Existing history is discarded.
+func (ti *ToplistIntegration) SetNormalizationWindow(window int) {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	ti.normalizer = toplist.NewNormalizer(window)
+}
+
+// SetCustomMetricResolver enables user toplists ranked by the owner's custom metrics
+func (ti *ToplistIntegration) SetCustomMetricResolver(resolver toplist.CustomMetricResolver) {
+	ti.mapper.SetCustomMetricResolver(resolver)
+}
+
 // reloadToplists reloads enabled toplists from the store
 func (ti *ToplistIntegration) reloadToplists(ctx context.Context) error {
 	// Load all enabled toplists (system and user)
@@ -49,9 +80,18 @@ func (ti *ToplistIntegration) reloadToplists(ctx context.Context) error {
 		return err
 	}
 
+	// Forget normalization history of removed toplists and of symbols no longer updated
+	normalized := make(map[string]bool)
+	for _, config := range toplists {
+		if config.IsNormalized() {
+			normalized[ti.mapper.GetToplistRedisKey(config)] = true
+		}
+	}
+
 	ti.mu.Lock()
 	ti.toplists = toplists
 	ti.lastReload = time.Now()
+	ti.normalizer.Prune(normalized, ti.lastReload.Add(-toplist.NormalizationHistoryTTL))
 	ti.mu.Unlock()
 
 	logger.Info("Reloaded toplists",
@@ -131,6 +171,15 @@ func (ti *ToplistIntegration) UpdateToplists(ctx context.Context, symbol string,
 
 		// Get Redis key for this toplist
 		key := ti.mapper.GetToplistRedisKey(config)
+
+		// Normalized toplists rank how unusual the value is for the symbol
+		if config.IsNormalized() {
+			normalized, ok := ti.normalizer.Normalize(config.Normalization, key, symbol, value)
+			if !ok {
+				continue // Not enough history for the symbol yet
+			}
+			value = normalized
+		}
 		logger.Debug("Adding toplist update",
 			logger.String("toplist_id", config.ID),
 			logger.String("key", key),
@@ -138,15 +187,64 @@ func (ti *ToplistIntegration) UpdateToplists(ctx context.Context, symbol string,
 			logger.Float64("value", value),
 		)
 		ti.updates = append(ti.updates, toplist.ToplistUpdate{
-			Key:    key,
-			Symbol: symbol,
-			Value:  value,
+			Key:       key,
+			Symbol:    symbol,
+			Value:     value,
+			MaxSize:   config.MaxSize,
+			SortOrder: config.SortOrder,
 		})
 	}
 
 	return nil
 }
 
+// flushUpdates applies updates in chunks of at most chunkSize (0 = one chunk), with at most
+// concurrency chunks in flight, so large universes don't produce one oversized pipeline.
+// Returns the number of updates in chunks that failed.
+func (ti *ToplistIntegration) flushUpdates(ctx context.Context, updates []toplist.ToplistUpdate, chunkSize, concurrency int) int {
+	if chunkSize <= 0 || len(updates) <= chunkSize {
+		if err := ti.updater.BatchUpdate(ctx, updates); err != nil {
+			logger.Warn("Failed to flush toplist updates",
+				logger.ErrorField(err),
+				logger.Int("update_count", len(updates)),
+			)
+			return len(updates)
+		}
+		return 0
+	}
+
+	var (
+		wg     sync.WaitGroup
+		failed int64
+		sem    = make(chan struct{}, concurrency)
+	)
+	for start := 0; start < len(updates); start += chunkSize {
+		end := start + chunkSize
+		if end > len(updates) {
+			end = len(updates)
+		}
+		chunk := updates[start:end]
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := ti.updater.BatchUpdate(ctx, chunk); err != nil {
+				logger.Warn("Failed to flush toplist update chunk",
+					logger.ErrorField(err),
+					logger.Int("update_count", len(chunk)),
+				)
+				atomic.AddInt64(&failed, int64(len(chunk)))
+			}
+		}()
+	}
+	wg.Wait()
+
+	return int(failed)
+}
+
 // PublishUpdates flushes accumulated updates and publishes toplist update notifications
 func (ti *ToplistIntegration) PublishUpdates(ctx context.Context) error {
 	if !ti.enabled {
@@ -157,6 +255,7 @@ func (ti *ToplistIntegration) PublishUpdates(ctx context.Context) error {
 	updates := ti.updates
 	ti.updates = ti.updates[:0] // Clear but keep capacity
 	shouldPublish := time.Since(ti.lastPublish) >= ti.updateInterval
+	chunkSize, concurrency := ti.chunkSize, ti.concurrency
 	ti.mu.Unlock()
 
 	// Batch update all accumulated updates
@@ -171,10 +270,10 @@ func (ti *ToplistIntegration) PublishUpdates(ctx context.Context) error {
 				logger.Float64("value", update.Value),
 			)
 		}
-		if err := ti.updater.BatchUpdate(ctx, updates); err != nil {
+		if failed := ti.flushUpdates(ctx, updates, chunkSize, concurrency); failed > 0 {
 			logger.Warn("Failed to batch update toplists",
-				logger.ErrorField(err),
 				logger.Int("update_count", len(updates)),
+				logger.Int("failed_update_count", failed),
 			)
 			// Continue to publish notifications even if batch update fails
 		} else {
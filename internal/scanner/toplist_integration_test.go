@@ -0,0 +1,312 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+	"github.com/mohamedkhairy/stock-scanner/internal/toplist"
+)
+
+func TestToplistIntegration_ChangeUnits(t *testing.T) {
+	ctx := context.Background()
+	mockRedis := storage.NewMockRedisClient()
+	store := toplist.NewMockToplistStore()
+
+	pctConfig := &models.ToplistConfig{
+		ID:         "gainers-5m",
+		Name:       "Gainers 5m",
+		Metric:     models.MetricChangePct,
+		TimeWindow: models.Window5m,
+		SortOrder:  models.SortOrderDesc,
+		Enabled:    true,
+	}
+	pointsConfig := &models.ToplistConfig{
+		ID:         "dollar-movers-5m",
+		Name:       "Dollar Movers 5m",
+		Metric:     models.MetricChangePct,
+		ChangeUnit: models.ChangeUnitPoints,
+		TimeWindow: models.Window5m,
+		SortOrder:  models.SortOrderDesc,
+		Enabled:    true,
+	}
+	for _, config := range []*models.ToplistConfig{pctConfig, pointsConfig} {
+		if err := store.CreateToplist(ctx, config); err != nil {
+			t.Fatalf("CreateToplist() error = %v", err)
+		}
+	}
+
+	ti := NewToplistIntegration(toplist.NewRedisToplistUpdater(mockRedis), store, true, time.Second)
+
+	// High-priced symbol: $500 -> $505 is +$5 but only +1%
+	if err := ti.UpdateToplists(ctx, "HIGH", map[string]float64{
+		"close":               505,
+		"change_5m":           5,
+		"price_change_5m_pct": 1,
+	}); err != nil {
+		t.Fatalf("UpdateToplists() error = %v", err)
+	}
+	// Low-priced symbol: $10 -> $11 is +$1 but +10%
+	if err := ti.UpdateToplists(ctx, "LOW", map[string]float64{
+		"close":               11,
+		"change_5m":           1,
+		"price_change_5m_pct": 10,
+	}); err != nil {
+		t.Fatalf("UpdateToplists() error = %v", err)
+	}
+	if err := ti.PublishUpdates(ctx); err != nil {
+		t.Fatalf("PublishUpdates() error = %v", err)
+	}
+
+	if pctConfig.RedisKey() == pointsConfig.RedisKey() {
+		t.Fatalf("Expected distinct Redis keys, both are %s", pctConfig.RedisKey())
+	}
+
+	pctRanking, err := mockRedis.ZRevRange(ctx, pctConfig.RedisKey(), 0, -1)
+	if err != nil {
+		t.Fatalf("ZRevRange() error = %v", err)
+	}
+	if len(pctRanking) != 2 || pctRanking[0].Member != "LOW" {
+		t.Errorf("Expected LOW to lead the percentage toplist, got %v", pctRanking)
+	}
+
+	pointsRanking, err := mockRedis.ZRevRange(ctx, pointsConfig.RedisKey(), 0, -1)
+	if err != nil {
+		t.Fatalf("ZRevRange() error = %v", err)
+	}
+	if len(pointsRanking) != 2 || pointsRanking[0].Member != "HIGH" {
+		t.Errorf("Expected HIGH to lead the points toplist, got %v", pointsRanking)
+	}
+
+	score, err := mockRedis.ZScore(ctx, pointsConfig.RedisKey(), "HIGH")
+	if err != nil {
+		t.Fatalf("ZScore() error = %v", err)
+	}
+	if score != 5 {
+		t.Errorf("Expected HIGH points score 5, got %v", score)
+	}
+}
+
+// chunkRecordingUpdater records BatchUpdate chunk sizes and peak concurrency
+type chunkRecordingUpdater struct {
+	toplist.ToplistUpdater
+	mu          sync.Mutex
+	chunks      []int
+	symbols     map[string]bool
+	inFlight    int
+	maxInFlight int
+	failSymbol  string
+}
+
+func (u *chunkRecordingUpdater) BatchUpdate(ctx context.Context, updates []toplist.ToplistUpdate) error {
+	u.mu.Lock()
+	u.inFlight++
+	if u.inFlight > u.maxInFlight {
+		u.maxInFlight = u.inFlight
+	}
+	u.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.inFlight--
+	u.chunks = append(u.chunks, len(updates))
+	for _, update := range updates {
+		if update.Symbol == u.failSymbol {
+			return errors.New("pipeline failed")
+		}
+	}
+	for _, update := range updates {
+		u.symbols[update.Symbol] = true
+	}
+	return nil
+}
+
+func toplistUpdates(n int) []toplist.ToplistUpdate {
+	updates := make([]toplist.ToplistUpdate, n)
+	for i := range updates {
+		updates[i] = toplist.ToplistUpdate{Key: "toplist:gainers", Symbol: fmt.Sprintf("SYM%d", i), Value: float64(i)}
+	}
+	return updates
+}
+
+func TestToplistIntegration_FlushUpdatesInBoundedChunks(t *testing.T) {
+	updater := &chunkRecordingUpdater{symbols: make(map[string]bool)}
+	ti := NewToplistIntegration(updater, toplist.NewMockToplistStore(), true, time.Second)
+
+	if failed := ti.flushUpdates(context.Background(), toplistUpdates(25), 10, 2); failed != 0 {
+		t.Fatalf("Expected no failed updates, got %d", failed)
+	}
+
+	if len(updater.chunks) != 3 {
+		t.Fatalf("Expected 3 chunks, got %v", updater.chunks)
+	}
+	total := 0
+	for _, size := range updater.chunks {
+		if size > 10 {
+			t.Errorf("Chunk of %d updates exceeds chunk size 10", size)
+		}
+		total += size
+	}
+	if total != 25 || len(updater.symbols) != 25 {
+		t.Errorf("Expected all 25 updates applied, got %d (%d symbols)", total, len(updater.symbols))
+	}
+	if updater.maxInFlight > 2 {
+		t.Errorf("Expected at most 2 chunks in flight, got %d", updater.maxInFlight)
+	}
+}
+
+func TestToplistIntegration_FlushUpdatesSingleChunk(t *testing.T) {
+	updater := &chunkRecordingUpdater{symbols: make(map[string]bool)}
+	ti := NewToplistIntegration(updater, toplist.NewMockToplistStore(), true, time.Second)
+
+	ti.flushUpdates(context.Background(), toplistUpdates(25), 0, 4)
+
+	if len(updater.chunks) != 1 || updater.chunks[0] != 25 {
+		t.Errorf("Expected a single chunk of 25 updates when chunking is disabled, got %v", updater.chunks)
+	}
+}
+
+func TestToplistIntegration_FlushUpdatesCountsFailedChunks(t *testing.T) {
+	updater := &chunkRecordingUpdater{symbols: make(map[string]bool), failSymbol: "SYM12"}
+	ti := NewToplistIntegration(updater, toplist.NewMockToplistStore(), true, time.Second)
+
+	if failed := ti.flushUpdates(context.Background(), toplistUpdates(25), 10, 3); failed != 10 {
+		t.Errorf("Expected the failing chunk's 10 updates to be counted, got %d", failed)
+	}
+	if len(updater.symbols) != 15 {
+		t.Errorf("Expected the other chunks to be applied, got %d symbols", len(updater.symbols))
+	}
+}
+
+func TestToplistIntegration_NormalizedRankings(t *testing.T) {
+	ctx := context.Background()
+	mockRedis := storage.NewMockRedisClient()
+	store := toplist.NewMockToplistStore()
+
+	configs := map[models.ToplistNormalization]*models.ToplistConfig{}
+	for _, normalization := range []models.ToplistNormalization{models.NormalizationNone, models.NormalizationZScore, models.NormalizationPercentile} {
+		config := &models.ToplistConfig{
+			ID:            "gainers-5m-" + string(normalization),
+			Name:          "Gainers 5m",
+			Metric:        models.MetricChangePct,
+			Normalization: normalization,
+			TimeWindow:    models.Window5m,
+			SortOrder:     models.SortOrderDesc,
+			Enabled:       true,
+		}
+		if err := store.CreateToplist(ctx, config); err != nil {
+			t.Fatalf("CreateToplist() error = %v", err)
+		}
+		configs[normalization] = config
+	}
+
+	ti := NewToplistIntegration(toplist.NewRedisToplistUpdater(mockRedis), store, true, time.Second)
+	ti.SetNormalizationWindow(10)
+
+	// Skewed history: VOLATILE routinely swings +/-5%, CALM barely moves
+	history := map[string][]float64{
+		"VOLATILE": {4, -4, 5, -5, 4.5, -4.5},
+		"CALM":     {0.1, -0.1, 0.2, -0.2, 0.1, -0.1},
+	}
+	latest := map[string]float64{"VOLATILE": 3, "CALM": 1}
+	for _, symbol := range []string{"VOLATILE", "CALM"} {
+		for _, change := range append(history[symbol], latest[symbol]) {
+			if err := ti.UpdateToplists(ctx, symbol, map[string]float64{"price_change_5m_pct": change}); err != nil {
+				t.Fatalf("UpdateToplists() error = %v", err)
+			}
+		}
+	}
+	if err := ti.PublishUpdates(ctx); err != nil {
+		t.Fatalf("PublishUpdates() error = %v", err)
+	}
+
+	leader := func(normalization models.ToplistNormalization) string {
+		ranking, err := mockRedis.ZRevRange(ctx, configs[normalization].RedisKey(), 0, -1)
+		if err != nil {
+			t.Fatalf("ZRevRange() error = %v", err)
+		}
+		if len(ranking) != 2 {
+			t.Fatalf("Expected 2 ranked symbols for %s, got %v", normalization, ranking)
+		}
+		return ranking[0].Member
+	}
+
+	// Raw rankings favour the large mover; normalized rankings surface the unusual move
+	if got := leader(models.NormalizationNone); got != "VOLATILE" {
+		t.Errorf("Expected VOLATILE to lead the raw toplist, got %s", got)
+	}
+	if got := leader(models.NormalizationZScore); got != "CALM" {
+		t.Errorf("Expected CALM to lead the z-score toplist, got %s", got)
+	}
+	if got := leader(models.NormalizationPercentile); got != "CALM" {
+		t.Errorf("Expected CALM to lead the percentile toplist, got %s", got)
+	}
+
+	score, err := mockRedis.ZScore(ctx, configs[models.NormalizationPercentile].RedisKey(), "CALM")
+	if err != nil {
+		t.Fatalf("ZScore() error = %v", err)
+	}
+	if score != 100 {
+		t.Errorf("Expected CALM percentile 100, got %v", score)
+	}
+}
+
+func TestToplistIntegration_CompoundWindows(t *testing.T) {
+	ctx := context.Background()
+	mockRedis := storage.NewMockRedisClient()
+	store := toplist.NewMockToplistStore()
+
+	config := &models.ToplistConfig{
+		ID:         "sustained-gainers",
+		UserID:     "user-1",
+		Name:       "Sustained Gainers",
+		Metric:     models.MetricChangePct,
+		TimeWindow: models.Window1m,
+		SortOrder:  models.SortOrderDesc,
+		CompoundWindows: []models.ToplistWindowThreshold{
+			{Window: models.Window1m, Threshold: 1},
+			{Window: models.Window5m, Threshold: 2},
+		},
+		Enabled: true,
+	}
+	if err := store.CreateToplist(ctx, config); err != nil {
+		t.Fatalf("CreateToplist() error = %v", err)
+	}
+
+	ti := NewToplistIntegration(toplist.NewRedisToplistUpdater(mockRedis), store, true, time.Second)
+
+	metrics := map[string]map[string]float64{
+		"STEADY": {"price_change_1m_pct": 2, "price_change_5m_pct": 4},   // Up over both windows
+		"STRONG": {"price_change_1m_pct": 3, "price_change_5m_pct": 6},   // Up more over both windows
+		"SPIKE":  {"price_change_1m_pct": 8, "price_change_5m_pct": 0.5}, // 1m spike only
+	}
+	for _, symbol := range []string{"STEADY", "STRONG", "SPIKE"} {
+		if err := ti.UpdateToplists(ctx, symbol, metrics[symbol]); err != nil {
+			t.Fatalf("UpdateToplists() error = %v", err)
+		}
+	}
+	if err := ti.PublishUpdates(ctx); err != nil {
+		t.Fatalf("PublishUpdates() error = %v", err)
+	}
+
+	ranking, err := mockRedis.ZRevRange(ctx, config.RedisKey(), 0, -1)
+	if err != nil {
+		t.Fatalf("ZRevRange() error = %v", err)
+	}
+	if len(ranking) != 2 {
+		t.Fatalf("Expected 2 ranked symbols, got %v", ranking)
+	}
+	if ranking[0].Member != "STRONG" || ranking[0].Score != 9 {
+		t.Errorf("Expected STRONG to lead with combined strength 9, got %v", ranking[0])
+	}
+	if ranking[1].Member != "STEADY" || ranking[1].Score != 6 {
+		t.Errorf("Expected STEADY second with combined strength 6, got %v", ranking[1])
+	}
+}
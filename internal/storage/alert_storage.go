@@ -7,7 +7,7 @@ import (
 	"fmt"
 	"time"
 
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/lib/pq"
 	"github.com/mohamedkhairy/stock-scanner/internal/config"
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
 	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
@@ -81,7 +81,7 @@ func (s *TimescaleAlertStorage) WriteAlerts(ctx context.Context, alerts []*model
 // GetAlerts retrieves alerts with filtering options
 func (s *TimescaleAlertStorage) GetAlerts(ctx context.Context, filter AlertFilter) ([]*models.Alert, error) {
 	query := `
-		SELECT id, rule_id, rule_name, symbol, timestamp, price, message, metadata, trace_id
+		SELECT id, rule_id, rule_name, symbol, timestamp, price, message, metadata, trace_id, occurrences, severity, alert_ids
 		FROM alert_history
 		WHERE 1=1
 	`
@@ -147,6 +147,9 @@ func (s *TimescaleAlertStorage) GetAlerts(ctx context.Context, filter AlertFilte
 			&alert.Message,
 			&metadataJSON,
 			&alert.TraceID,
+			&alert.Occurrences,
+			&alert.Severity,
+			pq.Array(&alert.AlertIDs),
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan alert: %w", err)
 		}
@@ -171,12 +174,13 @@ func (s *TimescaleAlertStorage) GetAlerts(ctx context.Context, filter AlertFilte
 	return alerts, nil
 }
 
-// GetAlert retrieves a single alert by ID
+// GetAlert retrieves a single alert by ID, including alerts aggregated into another record
 func (s *TimescaleAlertStorage) GetAlert(ctx context.Context, alertID string) (*models.Alert, error) {
 	query := `
-		SELECT id, rule_id, rule_name, symbol, timestamp, price, message, metadata, trace_id
+		SELECT id, rule_id, rule_name, symbol, timestamp, price, message, metadata, trace_id, occurrences, severity, alert_ids
 		FROM alert_history
-		WHERE id = $1
+		WHERE id = $1 OR alert_ids @> ARRAY[$1]::text[]
+		LIMIT 1
 	`
 
 	var alert models.Alert
@@ -192,6 +196,9 @@ func (s *TimescaleAlertStorage) GetAlert(ctx context.Context, alertID string) (*
 		&alert.Message,
 		&metadataJSON,
 		&alert.TraceID,
+		&alert.Occurrences,
+		&alert.Severity,
+		pq.Array(&alert.AlertIDs),
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -213,6 +220,69 @@ func (s *TimescaleAlertStorage) GetAlert(ctx context.Context, alertID string) (*
 	return &alert, nil
 }
 
+// AddNote stores a note attached to an alert
+func (s *TimescaleAlertStorage) AddNote(ctx context.Context, note *models.AlertNote) error {
+	query := `
+		INSERT INTO alert_notes (id, alert_id, author, text, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := s.db.ExecContext(ctx, query,
+		note.ID,
+		note.AlertID,
+		note.Author,
+		note.Text,
+		note.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert alert note: %w", err)
+	}
+
+	return nil
+}
+
+// GetNotes retrieves all notes for an alert, oldest first
+func (s *TimescaleAlertStorage) GetNotes(ctx context.Context, alertID string) ([]*models.AlertNote, error) {
+	query := `
+		SELECT id, alert_id, author, text, created_at
+		FROM alert_notes
+		WHERE alert_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, alertID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := make([]*models.AlertNote, 0)
+	for rows.Next() {
+		var note models.AlertNote
+		if err := rows.Scan(
+			&note.ID,
+			&note.AlertID,
+			&note.Author,
+			&note.Text,
+			&note.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan alert note: %w", err)
+		}
+		notes = append(notes, &note)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alert notes: %w", err)
+	}
+
+	return notes, nil
+}
+
+// Hypertables returns a hypertable manager using this storage's connection
+func (s *TimescaleAlertStorage) Hypertables() *HypertableManager {
+	return NewHypertableManager(s.db)
+}
+
 // Close closes the database connection
 func (s *TimescaleAlertStorage) Close() error {
 	return s.db.Close()
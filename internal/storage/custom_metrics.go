@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+const (
+	// CustomMetricUsersSetKey is the Redis set of users with custom metrics
+	CustomMetricUsersSetKey = "custom_metrics:users"
+	// customMetricKeyPrefix prefixes the per-user hash of custom metrics keyed by name
+	customMetricKeyPrefix = "custom_metrics:user:"
+)
+
+// CustomMetricStore stores user custom metric definitions in Redis, so the API can manage
+// them and scanner workers load them into their custom metric registry
+type CustomMetricStore struct {
+	redis RedisClient
+	now   func() time.Time
+}
+
+// NewCustomMetricStore creates a new custom metric store
+func NewCustomMetricStore(redis RedisClient) *CustomMetricStore {
+	return &CustomMetricStore{
+		redis: redis,
+		now:   time.Now,
+	}
+}
+
+func customMetricKey(userID string) string {
+	return customMetricKeyPrefix + userID
+}
+
+// SaveMetric stores a user's custom metric, replacing any metric with the same name.
+// The caller validates the expression.
+func (s *CustomMetricStore) SaveMetric(ctx context.Context, metric *models.CustomMetric) error {
+	if err := metric.Validate(); err != nil {
+		return err
+	}
+
+	now := s.now()
+	if existing, err := s.GetMetric(ctx, metric.UserID, metric.Name); err != nil {
+		return err
+	} else if existing != nil {
+		metric.ID = existing.ID
+		metric.CreatedAt = existing.CreatedAt
+	} else {
+		metric.ID = uuid.New().String()
+		metric.CreatedAt = now
+	}
+	metric.UpdatedAt = now
+
+	data, err := json.Marshal(metric)
+	if err != nil {
+		return fmt.Errorf("failed to marshal custom metric: %w", err)
+	}
+	if err := s.redis.HSetBatch(ctx, customMetricKey(metric.UserID), map[string]string{metric.Name: string(data)}); err != nil {
+		return fmt.Errorf("failed to store custom metric %s: %w", metric.Name, err)
+	}
+	if err := s.redis.SetAdd(ctx, CustomMetricUsersSetKey, metric.UserID); err != nil {
+		return fmt.Errorf("failed to index custom metric user: %w", err)
+	}
+	return nil
+}
+
+// DeleteMetric removes a user's custom metric
+func (s *CustomMetricStore) DeleteMetric(ctx context.Context, userID, name string) error {
+	if err := s.redis.HDel(ctx, customMetricKey(userID), name); err != nil {
+		return fmt.Errorf("failed to delete custom metric %s: %w", name, err)
+	}
+	return nil
+}
+
+// GetMetric returns a user's custom metric, or nil if it does not exist
+func (s *CustomMetricStore) GetMetric(ctx context.Context, userID, name string) (*models.CustomMetric, error) {
+	metrics, err := s.ListUserMetrics(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, metric := range metrics {
+		if metric.Name == name {
+			return metric, nil
+		}
+	}
+	return nil, nil
+}
+
+// ListUserMetrics returns a user's custom metrics sorted by name
+func (s *CustomMetricStore) ListUserMetrics(ctx context.Context, userID string) ([]*models.CustomMetric, error) {
+	fields, err := s.redis.HGetAll(ctx, customMetricKey(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom metrics for user %s: %w", userID, err)
+	}
+
+	metrics := make([]*models.CustomMetric, 0, len(fields))
+	for name, data := range fields {
+		var metric models.CustomMetric
+		if err := json.Unmarshal([]byte(data), &metric); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal custom metric %s: %w", name, err)
+		}
+		metrics = append(metrics, &metric)
+	}
+
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].Name < metrics[j].Name
+	})
+	return metrics, nil
+}
+
+// ListMetrics returns every user's custom metrics, sorted by user and name
+func (s *CustomMetricStore) ListMetrics(ctx context.Context) ([]*models.CustomMetric, error) {
+	users, err := s.redis.SetMembers(ctx, CustomMetricUsersSetKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom metric users: %w", err)
+	}
+	sort.Strings(users)
+
+	var metrics []*models.CustomMetric
+	for _, userID := range users {
+		userMetrics, err := s.ListUserMetrics(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		// Users whose metrics were all deleted are dropped from the index
+		if len(userMetrics) == 0 {
+			_ = s.redis.SetRemove(ctx, CustomMetricUsersSetKey, userID)
+			continue
+		}
+		metrics = append(metrics, userMetrics...)
+	}
+	return metrics, nil
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomMetricStore_SaveListDelete(t *testing.T) {
+	ctx := context.Background()
+	store := NewCustomMetricStore(NewMockRedisClient())
+
+	require.NoError(t, store.SaveMetric(ctx, &models.CustomMetric{UserID: "alice", Name: "spread", Expression: "high - low"}))
+	require.NoError(t, store.SaveMetric(ctx, &models.CustomMetric{UserID: "alice", Name: "range_pct", Expression: "(high - low) / close * 100"}))
+	require.NoError(t, store.SaveMetric(ctx, &models.CustomMetric{UserID: "bob", Name: "spread", Expression: "close - open"}))
+
+	alice, err := store.ListUserMetrics(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, alice, 2)
+	assert.Equal(t, "range_pct", alice[0].Name)
+	assert.Equal(t, "spread", alice[1].Name)
+	assert.NotEmpty(t, alice[1].ID)
+
+	// Saving under an existing name replaces the definition and keeps its identity
+	original := alice[1]
+	require.NoError(t, store.SaveMetric(ctx, &models.CustomMetric{UserID: "alice", Name: "spread", Expression: "ask - bid"}))
+	updated, err := store.GetMetric(ctx, "alice", "spread")
+	require.NoError(t, err)
+	require.NotNil(t, updated)
+	assert.Equal(t, "ask - bid", updated.Expression)
+	assert.Equal(t, original.ID, updated.ID)
+	assert.Equal(t, original.CreatedAt.Unix(), updated.CreatedAt.Unix())
+
+	all, err := store.ListMetrics(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	require.NoError(t, store.DeleteMetric(ctx, "bob", "spread"))
+	all, err = store.ListMetrics(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+	for _, metric := range all {
+		assert.Equal(t, "alice", metric.UserID)
+	}
+
+	missing, err := store.GetMetric(ctx, "bob", "spread")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	assert.Error(t, store.SaveMetric(ctx, &models.CustomMetric{UserID: "alice", Name: "empty"}))
+}
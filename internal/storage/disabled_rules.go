@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// DisabledRulesKey is the Redis hash of automatically disabled rules, keyed by rule ID
+const DisabledRulesKey = "rules:auto_disabled"
+
+// DisabledRuleStore records the rules scanner workers disabled after repeated evaluation
+// errors in Redis, so the API can list them and clear the record when a rule is re-enabled
+type DisabledRuleStore struct {
+	redis RedisClient
+}
+
+// NewDisabledRuleStore creates a new disabled rule store
+func NewDisabledRuleStore(redis RedisClient) *DisabledRuleStore {
+	return &DisabledRuleStore{redis: redis}
+}
+
+// SaveDisabledRule records a disabled rule, replacing any previous record for it
+func (s *DisabledRuleStore) SaveDisabledRule(ctx context.Context, event models.RuleDisableEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal disabled rule: %w", err)
+	}
+	if err := s.redis.HSetBatch(ctx, DisabledRulesKey, map[string]string{event.RuleID: string(data)}); err != nil {
+		return fmt.Errorf("failed to store disabled rule %s: %w", event.RuleID, err)
+	}
+	return nil
+}
+
+// DeleteDisabledRule removes a rule's disable record
+func (s *DisabledRuleStore) DeleteDisabledRule(ctx context.Context, ruleID string) error {
+	if err := s.redis.HDel(ctx, DisabledRulesKey, ruleID); err != nil {
+		return fmt.Errorf("failed to delete disabled rule %s: %w", ruleID, err)
+	}
+	return nil
+}
+
+// ListDisabledRules returns the disabled rules sorted by rule ID
+func (s *DisabledRuleStore) ListDisabledRules(ctx context.Context) ([]models.RuleDisableEvent, error) {
+	fields, err := s.redis.HGetAll(ctx, DisabledRulesKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disabled rules: %w", err)
+	}
+
+	events := make([]models.RuleDisableEvent, 0, len(fields))
+	for ruleID, data := range fields {
+		var event models.RuleDisableEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal disabled rule %s: %w", ruleID, err)
+		}
+		events = append(events, event)
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].RuleID < events[j].RuleID
+	})
+	return events, nil
+}
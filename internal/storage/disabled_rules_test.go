@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisabledRuleStore_SaveListDelete(t *testing.T) {
+	ctx := context.Background()
+	store := NewDisabledRuleStore(NewMockRedisClient())
+	disabledAt := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+
+	require.NoError(t, store.SaveDisabledRule(ctx, models.RuleDisableEvent{RuleID: "rule-2", UserID: "alice", Errors: 5, DisabledAt: disabledAt}))
+	require.NoError(t, store.SaveDisabledRule(ctx, models.RuleDisableEvent{RuleID: "rule-1", Errors: 3, LastError: "division by zero", DisabledAt: disabledAt}))
+
+	events, err := store.ListDisabledRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "rule-1", events[0].RuleID)
+	assert.Equal(t, "division by zero", events[0].LastError)
+	assert.Equal(t, "alice", events[1].UserID)
+	assert.True(t, events[1].DisabledAt.Equal(disabledAt))
+
+	require.NoError(t, store.DeleteDisabledRule(ctx, "rule-1"))
+	events, err = store.ListDisabledRules(ctx)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "rule-2", events[0].RuleID)
+}
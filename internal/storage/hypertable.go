@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+const (
+	// BarsHypertable is the hypertable storing finalized 1-minute bars
+	BarsHypertable = "bars_1m"
+	// AlertsHypertable is the hypertable storing alert history
+	AlertsHypertable = "alert_history"
+)
+
+// HypertablePolicy describes the chunk sizing and native compression policy for a hypertable
+type HypertablePolicy struct {
+	Table         string
+	ChunkInterval time.Duration // Chunk time interval (0 = leave unchanged)
+	CompressAfter time.Duration // Compress chunks older than this (0 = compression disabled)
+	SegmentBy     string        // Column to segment compressed data by
+	OrderBy       string        // Ordering of compressed data
+}
+
+// HypertableSettings holds the current chunk and compression settings of a hypertable
+type HypertableSettings struct {
+	Table              string `json:"table"`
+	ChunkInterval      string `json:"chunk_interval,omitempty"`
+	CompressionEnabled bool   `json:"compression_enabled"`
+	CompressAfter      string `json:"compress_after,omitempty"`
+}
+
+// BarsHypertablePolicy returns the bars hypertable policy from configuration
+func BarsHypertablePolicy(dbConfig config.DatabaseConfig) HypertablePolicy {
+	return HypertablePolicy{
+		Table:         BarsHypertable,
+		ChunkInterval: dbConfig.BarsChunkInterval,
+		CompressAfter: dbConfig.BarsCompressAfter,
+		SegmentBy:     "symbol",
+		OrderBy:       "timestamp DESC",
+	}
+}
+
+// AlertsHypertablePolicy returns the alert history hypertable policy from configuration
+func AlertsHypertablePolicy(dbConfig config.DatabaseConfig) HypertablePolicy {
+	return HypertablePolicy{
+		Table:         AlertsHypertable,
+		ChunkInterval: dbConfig.AlertsChunkInterval,
+		CompressAfter: dbConfig.AlertsCompressAfter,
+		SegmentBy:     "symbol",
+		OrderBy:       "timestamp DESC",
+	}
+}
+
+// HypertableManager manages TimescaleDB chunk intervals and compression policies
+type HypertableManager struct {
+	db *sql.DB
+}
+
+// NewHypertableManager creates a new hypertable manager
+func NewHypertableManager(db *sql.DB) *HypertableManager {
+	return &HypertableManager{db: db}
+}
+
+// ApplyPolicy sets the chunk interval and enables compression with a compression policy
+// if not already present. Compression settings are only applied when compression is not
+// yet enabled, since TimescaleDB rejects changes once chunks are compressed.
+func (m *HypertableManager) ApplyPolicy(ctx context.Context, policy HypertablePolicy) error {
+	if policy.Table == "" {
+		return fmt.Errorf("hypertable name cannot be empty")
+	}
+
+	if policy.ChunkInterval > 0 {
+		if _, err := m.db.ExecContext(ctx,
+			"SELECT set_chunk_time_interval($1::regclass, $2::interval)",
+			policy.Table, intervalString(policy.ChunkInterval),
+		); err != nil {
+			return fmt.Errorf("failed to set chunk interval for %s: %w", policy.Table, err)
+		}
+	}
+
+	if policy.CompressAfter <= 0 {
+		return nil
+	}
+
+	var compressionEnabled bool
+	err := m.db.QueryRowContext(ctx,
+		"SELECT compression_enabled FROM timescaledb_information.hypertables WHERE hypertable_name = $1",
+		policy.Table,
+	).Scan(&compressionEnabled)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("table %s is not a hypertable", policy.Table)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read compression settings for %s: %w", policy.Table, err)
+	}
+
+	if !compressionEnabled {
+		options := []string{"timescaledb.compress"}
+		if policy.SegmentBy != "" {
+			options = append(options, fmt.Sprintf("timescaledb.compress_segmentby = %s", pq.QuoteLiteral(policy.SegmentBy)))
+		}
+		if policy.OrderBy != "" {
+			options = append(options, fmt.Sprintf("timescaledb.compress_orderby = %s", pq.QuoteLiteral(policy.OrderBy)))
+		}
+
+		query := fmt.Sprintf("ALTER TABLE %s SET (%s)", pq.QuoteIdentifier(policy.Table), strings.Join(options, ", "))
+		if _, err := m.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to enable compression for %s: %w", policy.Table, err)
+		}
+	}
+
+	if _, err := m.db.ExecContext(ctx,
+		"SELECT add_compression_policy($1::regclass, $2::interval, if_not_exists => TRUE)",
+		policy.Table, intervalString(policy.CompressAfter),
+	); err != nil {
+		return fmt.Errorf("failed to add compression policy for %s: %w", policy.Table, err)
+	}
+
+	logger.Info("Applied hypertable policy",
+		logger.String("table", policy.Table),
+		logger.Duration("chunk_interval", policy.ChunkInterval),
+		logger.Duration("compress_after", policy.CompressAfter),
+	)
+
+	return nil
+}
+
+// GetSettings returns the current chunk and compression settings for the given hypertables
+func (m *HypertableManager) GetSettings(ctx context.Context, tables []string) ([]HypertableSettings, error) {
+	query := `
+		SELECT h.hypertable_name, h.compression_enabled, d.time_interval::text, j.config->>'compress_after'
+		FROM timescaledb_information.hypertables h
+		LEFT JOIN timescaledb_information.dimensions d
+			ON d.hypertable_name = h.hypertable_name AND d.dimension_type = 'Time'
+		LEFT JOIN timescaledb_information.jobs j
+			ON j.hypertable_name = h.hypertable_name AND j.proc_name = 'policy_compression'
+		WHERE h.hypertable_name = ANY($1)
+		ORDER BY h.hypertable_name
+	`
+
+	rows, err := m.db.QueryContext(ctx, query, pq.Array(tables))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query hypertable settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings := make([]HypertableSettings, 0, len(tables))
+	for rows.Next() {
+		var s HypertableSettings
+		var chunkInterval, compressAfter sql.NullString
+		if err := rows.Scan(&s.Table, &s.CompressionEnabled, &chunkInterval, &compressAfter); err != nil {
+			return nil, fmt.Errorf("failed to scan hypertable settings: %w", err)
+		}
+		s.ChunkInterval = chunkInterval.String
+		s.CompressAfter = compressAfter.String
+		settings = append(settings, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating hypertable settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// intervalString formats a duration as a PostgreSQL interval
+func intervalString(d time.Duration) string {
+	return fmt.Sprintf("%d seconds", int64(d/time.Second))
+}
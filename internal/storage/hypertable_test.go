@@ -0,0 +1,252 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedStatement is a statement issued through the recording driver
+type recordedStatement struct {
+	Query string
+	Args  []driver.Value
+}
+
+// recordingDriver is a minimal database/sql driver that records statements and returns
+// scripted rows for queries containing a registered substring
+type recordingDriver struct {
+	mu         sync.Mutex
+	statements []recordedStatement
+	rows       map[string][][]driver.Value // query substring -> rows
+	columns    map[string][]string
+}
+
+var (
+	recordingDrivers   = make(map[string]*recordingDriver)
+	recordingDriversMu sync.Mutex
+	registerRecording  sync.Once
+)
+
+// recordingDriverRouter dispatches connections to the recording driver named by the DSN
+type recordingDriverRouter struct{}
+
+func (recordingDriverRouter) Open(name string) (driver.Conn, error) {
+	recordingDriversMu.Lock()
+	defer recordingDriversMu.Unlock()
+	d, ok := recordingDrivers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown recording driver %q", name)
+	}
+	return &recordingConn{driver: d}, nil
+}
+
+func newRecordingDB(t *testing.T) (*sql.DB, *recordingDriver) {
+	registerRecording.Do(func() {
+		sql.Register("recording", recordingDriverRouter{})
+	})
+
+	d := &recordingDriver{
+		rows:    make(map[string][][]driver.Value),
+		columns: make(map[string][]string),
+	}
+	recordingDriversMu.Lock()
+	recordingDrivers[t.Name()] = d
+	recordingDriversMu.Unlock()
+
+	db, err := sql.Open("recording", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db, d
+}
+
+func (d *recordingDriver) expectRows(substring string, columns []string, rows ...[]driver.Value) {
+	d.columns[substring] = columns
+	d.rows[substring] = rows
+}
+
+func (d *recordingDriver) record(query string, args []driver.NamedValue) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	d.mu.Lock()
+	d.statements = append(d.statements, recordedStatement{Query: query, Args: values})
+	d.mu.Unlock()
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepare not supported")
+}
+
+func (c *recordingConn) Close() error { return nil }
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transactions not supported")
+}
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.driver.record(query, args)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.driver.record(query, args)
+	for substring, rows := range c.driver.rows {
+		if strings.Contains(query, substring) {
+			return &recordingRows{columns: c.driver.columns[substring], rows: rows}, nil
+		}
+	}
+	return &recordingRows{}, nil
+}
+
+// CheckNamedValue accepts any argument type (e.g. pq.Array)
+func (c *recordingConn) CheckNamedValue(*driver.NamedValue) error { return nil }
+
+type recordingRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *recordingRows) Columns() []string { return r.columns }
+
+func (r *recordingRows) Close() error { return nil }
+
+func (r *recordingRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+func TestBarsHypertablePolicy_FromConfig(t *testing.T) {
+	dbConfig := config.DatabaseConfig{
+		BarsChunkInterval:   12 * time.Hour,
+		BarsCompressAfter:   72 * time.Hour,
+		AlertsChunkInterval: 48 * time.Hour,
+		AlertsCompressAfter: 240 * time.Hour,
+	}
+
+	bars := BarsHypertablePolicy(dbConfig)
+	assert.Equal(t, "bars_1m", bars.Table)
+	assert.Equal(t, 12*time.Hour, bars.ChunkInterval)
+	assert.Equal(t, 72*time.Hour, bars.CompressAfter)
+	assert.Equal(t, "symbol", bars.SegmentBy)
+
+	alerts := AlertsHypertablePolicy(dbConfig)
+	assert.Equal(t, "alert_history", alerts.Table)
+	assert.Equal(t, 48*time.Hour, alerts.ChunkInterval)
+	assert.Equal(t, 240*time.Hour, alerts.CompressAfter)
+}
+
+func TestHypertableManager_ApplyPolicy(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	rec.expectRows("compression_enabled FROM", []string{"compression_enabled"}, []driver.Value{false})
+
+	manager := NewHypertableManager(db)
+	err := manager.ApplyPolicy(context.Background(), HypertablePolicy{
+		Table:         "bars_1m",
+		ChunkInterval: 24 * time.Hour,
+		CompressAfter: 7 * 24 * time.Hour,
+		SegmentBy:     "symbol",
+		OrderBy:       "timestamp DESC",
+	})
+	require.NoError(t, err)
+
+	require.Len(t, rec.statements, 4)
+
+	assert.Contains(t, rec.statements[0].Query, "set_chunk_time_interval")
+	assert.Equal(t, []driver.Value{"bars_1m", "86400 seconds"}, rec.statements[0].Args)
+
+	assert.Contains(t, rec.statements[1].Query, "timescaledb_information.hypertables")
+
+	assert.Equal(t,
+		`ALTER TABLE "bars_1m" SET (timescaledb.compress, timescaledb.compress_segmentby = 'symbol', timescaledb.compress_orderby = 'timestamp DESC')`,
+		rec.statements[2].Query,
+	)
+
+	assert.Contains(t, rec.statements[3].Query, "add_compression_policy")
+	assert.Contains(t, rec.statements[3].Query, "if_not_exists => TRUE")
+	assert.Equal(t, []driver.Value{"bars_1m", "604800 seconds"}, rec.statements[3].Args)
+}
+
+func TestHypertableManager_ApplyPolicy_CompressionAlreadyEnabled(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	rec.expectRows("compression_enabled FROM", []string{"compression_enabled"}, []driver.Value{true})
+
+	manager := NewHypertableManager(db)
+	err := manager.ApplyPolicy(context.Background(), HypertablePolicy{
+		Table:         "alert_history",
+		ChunkInterval: 7 * 24 * time.Hour,
+		CompressAfter: 30 * 24 * time.Hour,
+		SegmentBy:     "symbol",
+	})
+	require.NoError(t, err)
+
+	// Compression settings are left alone; only the chunk interval and policy are (re)applied
+	require.Len(t, rec.statements, 3)
+	for _, stmt := range rec.statements {
+		assert.NotContains(t, stmt.Query, "ALTER TABLE")
+	}
+	assert.Equal(t, []driver.Value{"alert_history", "2592000 seconds"}, rec.statements[2].Args)
+}
+
+func TestHypertableManager_ApplyPolicy_CompressionDisabled(t *testing.T) {
+	db, rec := newRecordingDB(t)
+
+	manager := NewHypertableManager(db)
+	err := manager.ApplyPolicy(context.Background(), HypertablePolicy{
+		Table:         "bars_1m",
+		ChunkInterval: time.Hour,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, rec.statements, 1)
+	assert.Contains(t, rec.statements[0].Query, "set_chunk_time_interval")
+	assert.Equal(t, []driver.Value{"bars_1m", "3600 seconds"}, rec.statements[0].Args)
+}
+
+func TestHypertableManager_ApplyPolicy_NotHypertable(t *testing.T) {
+	db, _ := newRecordingDB(t)
+
+	manager := NewHypertableManager(db)
+	err := manager.ApplyPolicy(context.Background(), HypertablePolicy{
+		Table:         "bars_1m",
+		CompressAfter: time.Hour,
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not a hypertable")
+}
+
+func TestHypertableManager_GetSettings(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	rec.expectRows("hypertable_name, h.compression_enabled",
+		[]string{"hypertable_name", "compression_enabled", "time_interval", "compress_after"},
+		[]driver.Value{"alert_history", true, "7 days", "30 days"},
+		[]driver.Value{"bars_1m", false, "1 day", nil},
+	)
+
+	manager := NewHypertableManager(db)
+	settings, err := manager.GetSettings(context.Background(), []string{"bars_1m", "alert_history"})
+	require.NoError(t, err)
+
+	require.Len(t, settings, 2)
+	assert.Equal(t, HypertableSettings{Table: "alert_history", ChunkInterval: "7 days", CompressionEnabled: true, CompressAfter: "30 days"}, settings[0])
+	assert.Equal(t, HypertableSettings{Table: "bars_1m", ChunkInterval: "1 day"}, settings[1])
+}
@@ -0,0 +1,326 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// indicatorInsertMaxRows caps rows per INSERT statement to stay well under PostgreSQL's parameter limit
+const indicatorInsertMaxRows = 1000
+
+// TimescaleIndicatorStorage implements IndicatorStorage for TimescaleDB.
+// Writes are queued and flushed in batches, like bars.
+type TimescaleIndicatorStorage struct {
+	db          *sql.DB
+	writeConfig WriteConfig
+
+	// Write queue
+	writeQueue chan []*models.IndicatorValue
+	wg         sync.WaitGroup
+	mu         sync.RWMutex
+	running    bool
+}
+
+// WriteConfigFromIndicatorConfig creates a WriteConfig from IndicatorConfig
+func WriteConfigFromIndicatorConfig(indicatorConfig config.IndicatorConfig) WriteConfig {
+	return WriteConfig{
+		BatchSize:  indicatorConfig.DBWriteBatchSize,
+		Interval:   indicatorConfig.DBWriteInterval,
+		QueueSize:  indicatorConfig.DBWriteQueueSize,
+		MaxRetries: indicatorConfig.DBMaxRetries,
+		RetryDelay: indicatorConfig.DBRetryDelay,
+	}
+}
+
+// NewTimescaleIndicatorStorage creates a new TimescaleDB indicator storage
+func NewTimescaleIndicatorStorage(dbConfig config.DatabaseConfig, writeConfig WriteConfig) (*TimescaleIndicatorStorage, error) {
+	// Build connection string
+	connStr := fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		dbConfig.Host,
+		dbConfig.Port,
+		dbConfig.User,
+		dbConfig.Password,
+		dbConfig.Database,
+		dbConfig.SSLMode,
+	)
+
+	// Open database connection
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+
+	// Configure connection pool
+	db.SetMaxOpenConns(dbConfig.MaxConnections)
+	db.SetMaxIdleConns(dbConfig.MaxIdleConns)
+	db.SetConnMaxLifetime(dbConfig.ConnMaxLifetime)
+
+	// Test connection
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	logger.Info("TimescaleDB indicator storage initialized",
+		logger.String("host", dbConfig.Host),
+		logger.Int("port", dbConfig.Port),
+		logger.String("database", dbConfig.Database),
+	)
+
+	return newTimescaleIndicatorStorage(db, writeConfig), nil
+}
+
+// newTimescaleIndicatorStorage creates an indicator storage on an open connection
+func newTimescaleIndicatorStorage(db *sql.DB, writeConfig WriteConfig) *TimescaleIndicatorStorage {
+	return &TimescaleIndicatorStorage{
+		db:          db,
+		writeConfig: writeConfig,
+		writeQueue:  make(chan []*models.IndicatorValue, writeConfig.QueueSize),
+	}
+}
+
+// Start starts the write queue processor. Storage used only for reads doesn't need to be started.
+func (s *TimescaleIndicatorStorage) Start() error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return fmt.Errorf("indicator storage is already running")
+	}
+	s.running = true
+	s.mu.Unlock()
+
+	logger.Info("Starting indicator write queue processor",
+		logger.Int("batch_size", s.writeConfig.BatchSize),
+		logger.Duration("interval", s.writeConfig.Interval),
+	)
+
+	s.wg.Add(1)
+	go s.processWriteQueue()
+
+	return nil
+}
+
+// Stop stops the write queue processor and flushes remaining writes
+func (s *TimescaleIndicatorStorage) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	s.mu.Unlock()
+
+	logger.Info("Stopping indicator write queue processor")
+	close(s.writeQueue)
+	s.wg.Wait()
+}
+
+// WriteIndicators enqueues indicator values for batched writing
+func (s *TimescaleIndicatorStorage) WriteIndicators(ctx context.Context, values []*models.IndicatorValue) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.running {
+		return fmt.Errorf("indicator storage is not running")
+	}
+
+	// Never block the publishing path on a full queue
+	select {
+	case s.writeQueue <- values:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		timescaleWriteErrors.WithLabelValues("indicator_queue_full").Inc()
+		return fmt.Errorf("indicator write queue is full")
+	}
+}
+
+// GetIndicators retrieves indicator values for a symbol, oldest first
+func (s *TimescaleIndicatorStorage) GetIndicators(ctx context.Context, filter IndicatorFilter) ([]*models.IndicatorValue, error) {
+	query := `
+		SELECT symbol, timestamp, indicator, value
+		FROM indicator_values
+		WHERE symbol = $1
+	`
+	args := []interface{}{filter.Symbol}
+	argIndex := 2
+
+	if filter.Name != "" {
+		query += fmt.Sprintf(" AND indicator = $%d", argIndex)
+		args = append(args, filter.Name)
+		argIndex++
+	}
+
+	if !filter.StartTime.IsZero() {
+		query += fmt.Sprintf(" AND timestamp >= $%d", argIndex)
+		args = append(args, filter.StartTime)
+		argIndex++
+	}
+
+	if !filter.EndTime.IsZero() {
+		query += fmt.Sprintf(" AND timestamp <= $%d", argIndex)
+		args = append(args, filter.EndTime)
+		argIndex++
+	}
+
+	query += " ORDER BY timestamp ASC, indicator ASC"
+
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query indicators: %w", err)
+	}
+	defer rows.Close()
+
+	var values []*models.IndicatorValue
+	for rows.Next() {
+		var value models.IndicatorValue
+		if err := rows.Scan(&value.Symbol, &value.Timestamp, &value.Name, &value.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan indicator: %w", err)
+		}
+		values = append(values, &value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return values, nil
+}
+
+// Close flushes pending writes and closes the database connection
+func (s *TimescaleIndicatorStorage) Close() error {
+	s.Stop()
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("failed to close database connection: %w", err)
+	}
+	return nil
+}
+
+// processWriteQueue processes the write queue
+func (s *TimescaleIndicatorStorage) processWriteQueue() {
+	defer s.wg.Done()
+
+	batch := make([]*models.IndicatorValue, 0, s.writeConfig.BatchSize)
+	ticker := time.NewTicker(s.writeConfig.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case values, ok := <-s.writeQueue:
+			if !ok {
+				// Channel closed, flush and exit
+				if len(batch) > 0 {
+					s.writeIndicatorsSync(context.Background(), batch)
+				}
+				return
+			}
+
+			batch = append(batch, values...)
+
+			// Flush if batch is full
+			if len(batch) >= s.writeConfig.BatchSize {
+				s.writeIndicatorsSync(context.Background(), batch)
+				batch = batch[:0]
+			}
+
+		case <-ticker.C:
+			// Flush on interval
+			if len(batch) > 0 {
+				s.writeIndicatorsSync(context.Background(), batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// writeIndicatorsSync writes indicator values synchronously with retry logic
+func (s *TimescaleIndicatorStorage) writeIndicatorsSync(ctx context.Context, values []*models.IndicatorValue) {
+	startTime := time.Now()
+	timescaleWriteBatchSize.WithLabelValues("write_indicators").Observe(float64(len(values)))
+
+	var err error
+	for attempt := 0; attempt < s.writeConfig.MaxRetries; attempt++ {
+		err = s.insertIndicators(ctx, values)
+		if err == nil {
+			break
+		}
+
+		if attempt < s.writeConfig.MaxRetries-1 {
+			delay := s.writeConfig.RetryDelay * time.Duration(1<<uint(attempt)) // Exponential backoff
+			logger.Warn("Failed to write indicators, retrying",
+				logger.ErrorField(err),
+				logger.Int("attempt", attempt+1),
+				logger.Int("values_count", len(values)),
+				logger.Duration("delay", delay),
+			)
+			time.Sleep(delay)
+		}
+	}
+
+	timescaleWriteLatency.WithLabelValues("write_indicators").Observe(time.Since(startTime).Seconds())
+
+	if err != nil {
+		timescaleWriteErrors.WithLabelValues("indicator_write_failed").Inc()
+		logger.Error("Failed to write indicators after retries",
+			logger.ErrorField(err),
+			logger.Int("values_count", len(values)),
+		)
+		return
+	}
+
+	logger.Debug("Wrote indicators to TimescaleDB",
+		logger.Int("count", len(values)),
+		logger.Duration("latency", time.Since(startTime)),
+	)
+}
+
+// insertIndicators inserts indicator values using multi-row INSERT statements
+func (s *TimescaleIndicatorStorage) insertIndicators(ctx context.Context, values []*models.IndicatorValue) error {
+	for start := 0; start < len(values); start += indicatorInsertMaxRows {
+		end := start + indicatorInsertMaxRows
+		if end > len(values) {
+			end = len(values)
+		}
+		chunk := values[start:end]
+
+		placeholders := make([]string, 0, len(chunk))
+		args := make([]interface{}, 0, len(chunk)*4)
+		for i, value := range chunk {
+			n := i * 4
+			placeholders = append(placeholders, fmt.Sprintf("($%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4))
+			args = append(args, value.Symbol, value.Timestamp, value.Name, value.Value)
+		}
+
+		query := `INSERT INTO indicator_values (symbol, timestamp, indicator, value) VALUES ` +
+			strings.Join(placeholders, ", ") +
+			` ON CONFLICT (symbol, indicator, timestamp) DO UPDATE SET value = EXCLUDED.value`
+
+		if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to insert indicators: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func indicatorValues(symbol string, ts time.Time, names ...string) []*models.IndicatorValue {
+	values := make([]*models.IndicatorValue, 0, len(names))
+	for i, name := range names {
+		values = append(values, &models.IndicatorValue{Symbol: symbol, Timestamp: ts, Name: name, Value: float64(i + 1)})
+	}
+	return values
+}
+
+func (d *recordingDriver) statementCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.statements)
+}
+
+func TestWriteConfigFromIndicatorConfig(t *testing.T) {
+	writeConfig := WriteConfigFromIndicatorConfig(config.IndicatorConfig{
+		DBWriteBatchSize: 500,
+		DBWriteInterval:  2 * time.Second,
+		DBWriteQueueSize: 5000,
+		DBMaxRetries:     4,
+		DBRetryDelay:     50 * time.Millisecond,
+	})
+
+	assert.Equal(t, WriteConfig{BatchSize: 500, Interval: 2 * time.Second, QueueSize: 5000, MaxRetries: 4, RetryDelay: 50 * time.Millisecond}, writeConfig)
+}
+
+func TestTimescaleIndicatorStorage_BatchedWrites(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	s := newTimescaleIndicatorStorage(db, WriteConfig{
+		BatchSize:  4,
+		Interval:   time.Hour,
+		QueueSize:  10,
+		MaxRetries: 1,
+	})
+	require.NoError(t, s.Start())
+
+	ts := time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	// Below the batch size nothing is written
+	require.NoError(t, s.WriteIndicators(ctx, indicatorValues("AAPL", ts, "rsi_14", "ema_20")))
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 0, rec.statementCount())
+
+	// Reaching the batch size writes all queued values in one statement
+	require.NoError(t, s.WriteIndicators(ctx, indicatorValues("MSFT", ts, "rsi_14", "ema_20")))
+	require.Eventually(t, func() bool { return rec.statementCount() == 1 }, time.Second, 5*time.Millisecond)
+
+	// Stopping flushes the partial batch
+	require.NoError(t, s.WriteIndicators(ctx, indicatorValues("TSLA", ts, "vwap")))
+	s.Stop()
+
+	require.Equal(t, 2, rec.statementCount())
+
+	first := rec.statements[0]
+	assert.True(t, strings.HasPrefix(first.Query, "INSERT INTO indicator_values (symbol, timestamp, indicator, value) VALUES "))
+	assert.Contains(t, first.Query, "($13, $14, $15, $16) ON CONFLICT (symbol, indicator, timestamp) DO UPDATE SET value = EXCLUDED.value")
+	require.Len(t, first.Args, 16)
+	assert.Equal(t, []driver.Value{"AAPL", ts, "rsi_14", 1.0}, first.Args[:4])
+	assert.Equal(t, []driver.Value{"MSFT", ts, "ema_20", 2.0}, first.Args[12:])
+
+	assert.Equal(t, []driver.Value{"TSLA", ts, "vwap", 1.0}, rec.statements[1].Args)
+
+	// Writes after stop are rejected rather than queued
+	assert.Error(t, s.WriteIndicators(ctx, indicatorValues("AAPL", ts, "rsi_14")))
+}
+
+func TestTimescaleIndicatorStorage_QueueFull(t *testing.T) {
+	db, _ := newRecordingDB(t)
+	s := newTimescaleIndicatorStorage(db, WriteConfig{BatchSize: 10, Interval: time.Hour, QueueSize: 1, MaxRetries: 1})
+	s.running = true // Queue without a processor draining it
+
+	ts := time.Now()
+	require.NoError(t, s.WriteIndicators(context.Background(), indicatorValues("AAPL", ts, "rsi_14")))
+	err := s.WriteIndicators(context.Background(), indicatorValues("AAPL", ts, "rsi_14"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "queue is full")
+}
+
+func TestTimescaleIndicatorStorage_GetIndicators(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	from := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	rec.expectRows("FROM indicator_values",
+		[]string{"symbol", "timestamp", "indicator", "value"},
+		[]driver.Value{"AAPL", from.Add(time.Minute), "rsi_14", 55.5},
+		[]driver.Value{"AAPL", from.Add(2 * time.Minute), "rsi_14", 61.25},
+	)
+
+	s := newTimescaleIndicatorStorage(db, WriteConfig{QueueSize: 1})
+	values, err := s.GetIndicators(context.Background(), IndicatorFilter{
+		Symbol:    "AAPL",
+		Name:      "rsi_14",
+		StartTime: from,
+		EndTime:   to,
+		Limit:     500,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, rec.statements, 1)
+	query := rec.statements[0].Query
+	assert.Contains(t, query, "WHERE symbol = $1")
+	assert.Contains(t, query, "AND indicator = $2 AND timestamp >= $3 AND timestamp <= $4")
+	assert.Contains(t, query, "ORDER BY timestamp ASC, indicator ASC LIMIT $5")
+	assert.Equal(t, []driver.Value{"AAPL", "rsi_14", from, to, 500}, rec.statements[0].Args)
+
+	require.Len(t, values, 2)
+	assert.Equal(t, models.IndicatorValue{Symbol: "AAPL", Timestamp: from.Add(time.Minute), Name: "rsi_14", Value: 55.5}, *values[0])
+	assert.Equal(t, 61.25, values[1].Value)
+}
+
+func TestTimescaleIndicatorStorage_GetIndicators_AllIndicators(t *testing.T) {
+	db, rec := newRecordingDB(t)
+
+	s := newTimescaleIndicatorStorage(db, WriteConfig{QueueSize: 1})
+	values, err := s.GetIndicators(context.Background(), IndicatorFilter{Symbol: "AAPL"})
+	require.NoError(t, err)
+	assert.Empty(t, values)
+
+	require.Len(t, rec.statements, 1)
+	assert.NotContains(t, rec.statements[0].Query, "indicator =")
+	assert.NotContains(t, rec.statements[0].Query, "LIMIT")
+	assert.Equal(t, []driver.Value{"AAPL"}, rec.statements[0].Args)
+}
@@ -40,6 +40,45 @@ type AlertStorage interface {
 	Close() error
 }
 
+// IndicatorStorage defines the interface for historical indicator storage
+type IndicatorStorage interface {
+	// WriteIndicators writes published indicator values to storage
+	WriteIndicators(ctx context.Context, values []*models.IndicatorValue) error
+
+	// GetIndicators retrieves indicator values for a symbol, oldest first
+	GetIndicators(ctx context.Context, filter IndicatorFilter) ([]*models.IndicatorValue, error)
+
+	// Close closes the storage connection
+	Close() error
+}
+
+// ScanStatsStorage defines the interface for scan statistics history
+type ScanStatsStorage interface {
+	// WriteScanStats writes one scan statistics sample
+	WriteScanStats(ctx context.Context, sample *models.ScanStatsSample) error
+
+	// GetScanStats retrieves scan statistics samples, oldest first
+	GetScanStats(ctx context.Context, filter ScanStatsFilter) ([]*models.ScanStatsSample, error)
+
+	// Close closes the storage connection
+	Close() error
+}
+
+// AlertNoteStorage defines the interface for alert note storage
+type AlertNoteStorage interface {
+	// AddNote stores a note attached to an alert
+	AddNote(ctx context.Context, note *models.AlertNote) error
+
+	// GetNotes retrieves all notes for an alert, oldest first
+	GetNotes(ctx context.Context, alertID string) ([]*models.AlertNote, error)
+}
+
+// HypertableSettingsReader reads current TimescaleDB hypertable settings
+type HypertableSettingsReader interface {
+	// GetSettings returns chunk and compression settings for the given hypertables
+	GetSettings(ctx context.Context, tables []string) ([]HypertableSettings, error)
+}
+
 // AlertFilter defines filtering options for alert queries
 type AlertFilter struct {
 	Symbol    string
@@ -50,6 +89,23 @@ type AlertFilter struct {
 	Offset    int
 }
 
+// IndicatorFilter defines filtering options for indicator history queries
+type IndicatorFilter struct {
+	Symbol    string
+	Name      string // Indicator name (empty = all indicators)
+	StartTime time.Time
+	EndTime   time.Time
+	Limit     int
+}
+
+// ScanStatsFilter defines filtering options for scan statistics history queries
+type ScanStatsFilter struct {
+	WorkerID  string // Scanner worker (empty = all workers)
+	StartTime time.Time
+	EndTime   time.Time
+	Limit     int
+}
+
 // RedisClient defines the interface for Redis operations
 type RedisClient interface {
 	// Stream operations
@@ -57,19 +113,47 @@ type RedisClient interface {
 	PublishBatchToStream(ctx context.Context, stream string, messages []map[string]interface{}) error
 	ConsumeFromStream(ctx context.Context, stream string, group string, consumer string) (<-chan StreamMessage, error)
 	AcknowledgeMessage(ctx context.Context, stream string, group string, id string) error
+	// ReadPendingFromStream returns messages delivered to the consumer but not yet acknowledged,
+	// with IDs greater than afterID (use "0" to read from the start)
+	ReadPendingFromStream(ctx context.Context, stream string, group string, consumer string, afterID string, count int64) ([]StreamMessage, error)
+	// ClaimPendingFromStream transfers up to count entries of the group pending for at least
+	// minIdle, whichever consumer they were delivered to, to consumer (XAUTOCLAIM), scanning
+	// from start. Returns the claimed messages and the start of the next scan ("0-0" when done).
+	ClaimPendingFromStream(ctx context.Context, stream string, group string, consumer string, minIdle time.Duration, start string, count int64) ([]StreamMessage, string, error)
+	// FirstStreamIDAtOrAfter returns the ID of the first stream entry added at or after t
+	// (empty if there is none)
+	FirstStreamIDAtOrAfter(ctx context.Context, stream string, t time.Time) (string, error)
+	// SetConsumerGroupID moves the consumer group's last delivered ID to id, creating the group
+	// (and stream) if needed. Entries after id are delivered next.
+	SetConsumerGroupID(ctx context.Context, stream string, group string, id string) error
+	// StreamLength returns the number of entries in the stream (0 if it does not exist)
+	StreamLength(ctx context.Context, stream string) (int64, error)
+	// StreamPending summarizes the entries delivered to the consumer group but not yet
+	// acknowledged (empty if the stream or group does not exist)
+	StreamPending(ctx context.Context, stream string, group string) (StreamPendingSummary, error)
 
 	// Key-value operations
 	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	// SetNX sets the key only if it does not exist, returning whether it was set
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
 	Get(ctx context.Context, key string) (string, error)
 	GetJSON(ctx context.Context, key string, dest interface{}) error
 	Delete(ctx context.Context, key string) error
 	Exists(ctx context.Context, key string) (bool, error)
+	// IncrWithTTL increments the integer counter at key (created at 0), refreshes its TTL and
+	// returns the new value
+	IncrWithTTL(ctx context.Context, key string, ttl time.Duration) (int64, error)
 
 	// Set operations
 	SetAdd(ctx context.Context, key string, members ...string) error
 	SetMembers(ctx context.Context, key string) ([]string, error)
 	SetRemove(ctx context.Context, key string, members ...string) error
 
+	// Hash operations
+	HSetBatch(ctx context.Context, key string, fields map[string]string) error
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	HDel(ctx context.Context, key string, fields ...string) error
+
 	// Pub/Sub operations
 	Publish(ctx context.Context, channel string, message interface{}) error
 	Subscribe(ctx context.Context, channels ...string) (<-chan PubSubMessage, error)
@@ -78,9 +162,12 @@ type RedisClient interface {
 	ZAdd(ctx context.Context, key string, score float64, member string) error
 	ZAddBatch(ctx context.Context, key string, members map[string]float64) error
 	ZRevRange(ctx context.Context, key string, start, stop int64) ([]ZSetMember, error)
+	ZRangeByScore(ctx context.Context, key string, min, max float64) ([]string, error)
 	ZRem(ctx context.Context, key string, members ...string) error
+	ZRemRangeByRank(ctx context.Context, key string, start, stop int64) (int64, error)
 	ZCard(ctx context.Context, key string) (int64, error)
 	ZScore(ctx context.Context, key string, member string) (float64, error)
+	ZMScore(ctx context.Context, key string, members ...string) ([]float64, error)
 
 	// Close closes the Redis connection
 	Close() error
@@ -99,6 +186,12 @@ type StreamMessage struct {
 	Values map[string]interface{}
 }
 
+// StreamPendingSummary summarizes a consumer group's pending entries list
+type StreamPendingSummary struct {
+	Count    int64
+	OldestID string // ID of the oldest pending entry (empty if there is none)
+}
+
 // PubSubMessage represents a message from Redis pub/sub
 type PubSubMessage struct {
 	Channel string
@@ -3,6 +3,9 @@ package storage
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -133,13 +136,161 @@ func (m *MockAlertStorage) Close() error {
 	return nil
 }
 
+// MockIndicatorStorage is a mock implementation of IndicatorStorage for testing
+type MockIndicatorStorage struct {
+	Values   []*models.IndicatorValue
+	Filters  []IndicatorFilter // Filters passed to GetIndicators
+	WriteErr error
+	GetErr   error
+	mu       sync.RWMutex
+}
+
+func (m *MockIndicatorStorage) WriteIndicators(ctx context.Context, values []*models.IndicatorValue) error {
+	if m.WriteErr != nil {
+		return m.WriteErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Values = append(m.Values, values...)
+	return nil
+}
+
+func (m *MockIndicatorStorage) GetIndicators(ctx context.Context, filter IndicatorFilter) ([]*models.IndicatorValue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Filters = append(m.Filters, filter)
+	if m.GetErr != nil {
+		return nil, m.GetErr
+	}
+	var result []*models.IndicatorValue
+	for _, value := range m.Values {
+		if value.Symbol != filter.Symbol {
+			continue
+		}
+		if filter.Name != "" && value.Name != filter.Name {
+			continue
+		}
+		if !filter.StartTime.IsZero() && value.Timestamp.Before(filter.StartTime) {
+			continue
+		}
+		if !filter.EndTime.IsZero() && value.Timestamp.After(filter.EndTime) {
+			continue
+		}
+		result = append(result, value)
+	}
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+func (m *MockIndicatorStorage) Close() error {
+	return nil
+}
+
+// MockScanStatsStorage is a mock implementation of ScanStatsStorage for testing
+type MockScanStatsStorage struct {
+	Samples  []*models.ScanStatsSample
+	Filters  []ScanStatsFilter // Filters passed to GetScanStats
+	WriteErr error
+	GetErr   error
+	mu       sync.RWMutex
+}
+
+func (m *MockScanStatsStorage) WriteScanStats(ctx context.Context, sample *models.ScanStatsSample) error {
+	if m.WriteErr != nil {
+		return m.WriteErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Samples = append(m.Samples, sample)
+	return nil
+}
+
+func (m *MockScanStatsStorage) GetScanStats(ctx context.Context, filter ScanStatsFilter) ([]*models.ScanStatsSample, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Filters = append(m.Filters, filter)
+	if m.GetErr != nil {
+		return nil, m.GetErr
+	}
+	var result []*models.ScanStatsSample
+	for _, sample := range m.Samples {
+		if filter.WorkerID != "" && sample.WorkerID != filter.WorkerID {
+			continue
+		}
+		if !filter.StartTime.IsZero() && sample.Timestamp.Before(filter.StartTime) {
+			continue
+		}
+		if !filter.EndTime.IsZero() && sample.Timestamp.After(filter.EndTime) {
+			continue
+		}
+		result = append(result, sample)
+	}
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+// GetSamples returns a copy of the written samples
+func (m *MockScanStatsStorage) GetSamples() []*models.ScanStatsSample {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]*models.ScanStatsSample(nil), m.Samples...)
+}
+
+func (m *MockScanStatsStorage) Close() error {
+	return nil
+}
+
+// MockAlertNoteStorage is a mock implementation of AlertNoteStorage for testing
+type MockAlertNoteStorage struct {
+	Notes    []*models.AlertNote
+	WriteErr error
+	GetErr   error
+	mu       sync.RWMutex
+}
+
+func (m *MockAlertNoteStorage) AddNote(ctx context.Context, note *models.AlertNote) error {
+	if m.WriteErr != nil {
+		return m.WriteErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Notes = append(m.Notes, note)
+	return nil
+}
+
+func (m *MockAlertNoteStorage) GetNotes(ctx context.Context, alertID string) ([]*models.AlertNote, error) {
+	if m.GetErr != nil {
+		return nil, m.GetErr
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]*models.AlertNote, 0)
+	for _, note := range m.Notes {
+		if note.AlertID == alertID {
+			result = append(result, note)
+		}
+	}
+	return result, nil
+}
+
 // MockRedisClient is a mock implementation of RedisClient for testing
 type MockRedisClient struct {
 	Data          map[string]string
 	Sets          map[string]map[string]bool // Map of set keys to their members
 	ZSets         map[string]map[string]float64 // Map of ZSET keys to member->score mappings
+	Hashes        map[string]map[string]string  // Map of hash keys to field->value mappings
 	StreamData    []StreamMessage
 	PubSubData    []PubSubMessage
+	Published     []PubSubMessage // Messages published via Publish (JSON-encoded)
+	Acked         map[string]bool // Acknowledged stream message IDs
+	GroupIDs      map[string]string // "stream:group" -> last delivered ID set via SetConsumerGroupID
+	PendingOwners map[string]string // Message ID -> consumer, the pending entries ClaimPendingFromStream may claim
+	GroupEntries  map[string]map[string]bool // "stream:group" -> IDs delivered to the group -> acknowledged
+	AckErr        error
 	PublishErr    error
 	GetErr        error
 	SetErr        error
@@ -153,6 +304,7 @@ func NewMockRedisClient() *MockRedisClient {
 		Data:  make(map[string]string),
 		Sets:  make(map[string]map[string]bool),
 		ZSets: make(map[string]map[string]float64),
+		Hashes: make(map[string]map[string]string),
 	}
 }
 
@@ -160,7 +312,17 @@ func (m *MockRedisClient) PublishToStream(ctx context.Context, stream string, ke
 	if m.PublishErr != nil {
 		return m.PublishErr
 	}
-	// Mock implementation
+	// Marshal to JSON like the real implementation and record for testing
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.StreamData = append(m.StreamData, StreamMessage{
+		Stream: stream,
+		Values: map[string]interface{}{key: string(jsonData)},
+	})
 	return nil
 }
 
@@ -169,6 +331,8 @@ func (m *MockRedisClient) PublishBatchToStream(ctx context.Context, stream strin
 		return m.PublishErr
 	}
 	// Store messages in StreamData for testing
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	for _, msg := range messages {
 		// Convert map to StreamMessage format
 		streamMsg := StreamMessage{
@@ -185,18 +349,177 @@ func (m *MockRedisClient) ConsumeFromStream(ctx context.Context, stream string,
 	if m.ConsumeErr != nil {
 		return nil, m.ConsumeErr
 	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	ch := make(chan StreamMessage, len(m.StreamData))
+	lastID, seeked := m.GroupIDs[stream+":"+group]
 	for _, msg := range m.StreamData {
+		// A group moved with SetConsumerGroupID only receives its stream's entries after the ID
+		if seeked && (msg.Stream != stream || lastID == "$" || CompareStreamIDs(msg.ID, lastID) <= 0) {
+			continue
+		}
+		if msg.Stream == stream && msg.ID != "" {
+			m.recordDelivered(stream, group, msg.ID)
+		}
 		ch <- msg
 	}
 	close(ch)
 	return ch, nil
 }
 
+// recordDelivered adds a message to the group's pending entries unless it was delivered before.
+// Must be called with the lock held.
+func (m *MockRedisClient) recordDelivered(stream, group, id string) {
+	if m.GroupEntries == nil {
+		m.GroupEntries = make(map[string]map[string]bool)
+	}
+	entries := m.GroupEntries[stream+":"+group]
+	if entries == nil {
+		entries = make(map[string]bool)
+		m.GroupEntries[stream+":"+group] = entries
+	}
+	if _, delivered := entries[id]; !delivered {
+		entries[id] = false
+	}
+}
+
+// FirstStreamIDAtOrAfter returns the smallest message ID of the stream at or after t
+func (m *MockRedisClient) FirstStreamIDAtOrAfter(ctx context.Context, stream string, t time.Time) (string, error) {
+	if m.ConsumeErr != nil {
+		return "", m.ConsumeErr
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	start := fmt.Sprintf("%d-0", t.UnixMilli())
+	first := ""
+	for _, msg := range m.StreamData {
+		if msg.Stream != stream || msg.ID == "" || CompareStreamIDs(msg.ID, start) < 0 {
+			continue
+		}
+		if first == "" || CompareStreamIDs(msg.ID, first) < 0 {
+			first = msg.ID
+		}
+	}
+	return first, nil
+}
+
+func (m *MockRedisClient) SetConsumerGroupID(ctx context.Context, stream string, group string, id string) error {
+	if m.ConsumeErr != nil {
+		return m.ConsumeErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.GroupIDs == nil {
+		m.GroupIDs = make(map[string]string)
+	}
+	m.GroupIDs[stream+":"+group] = id
+	return nil
+}
+
+// StreamLength counts the messages published to the stream
+func (m *MockRedisClient) StreamLength(ctx context.Context, stream string) (int64, error) {
+	if m.ConsumeErr != nil {
+		return 0, m.ConsumeErr
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var length int64
+	for _, msg := range m.StreamData {
+		if msg.Stream == stream {
+			length++
+		}
+	}
+	return length, nil
+}
+
+// StreamPending summarizes the group's pending entries like XPENDING: the messages delivered
+// to the group by ConsumeFromStream and not yet acknowledged by it. A group that never
+// consumed the stream has none.
+func (m *MockRedisClient) StreamPending(ctx context.Context, stream string, group string) (StreamPendingSummary, error) {
+	if m.ConsumeErr != nil {
+		return StreamPendingSummary{}, m.ConsumeErr
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var summary StreamPendingSummary
+	for id, acked := range m.GroupEntries[stream+":"+group] {
+		if acked {
+			continue
+		}
+		summary.Count++
+		if summary.OldestID == "" || CompareStreamIDs(id, summary.OldestID) < 0 {
+			summary.OldestID = id
+		}
+	}
+	return summary, nil
+}
+
 func (m *MockRedisClient) AcknowledgeMessage(ctx context.Context, stream string, group string, id string) error {
+	if m.AckErr != nil {
+		return m.AckErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Acked == nil {
+		m.Acked = make(map[string]bool)
+	}
+	m.Acked[id] = true
+	if entries := m.GroupEntries[stream+":"+group]; entries != nil {
+		if _, delivered := entries[id]; delivered {
+			entries[id] = true
+		}
+	}
 	return nil
 }
 
+// ClaimPendingFromStream claims the unacknowledged messages listed in PendingOwners with IDs
+// at or after start, ignoring minIdle, and records consumer as their new owner
+func (m *MockRedisClient) ClaimPendingFromStream(ctx context.Context, stream string, group string, consumer string, minIdle time.Duration, start string, count int64) ([]StreamMessage, string, error) {
+	if m.ConsumeErr != nil {
+		return nil, "", m.ConsumeErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []StreamMessage
+	for _, msg := range m.StreamData {
+		if _, pending := m.PendingOwners[msg.ID]; msg.Stream != stream || !pending || m.Acked[msg.ID] {
+			continue
+		}
+		if CompareStreamIDs(msg.ID, start) < 0 {
+			continue
+		}
+		if count > 0 && int64(len(result)) >= count {
+			return result, msg.ID, nil
+		}
+		m.PendingOwners[msg.ID] = consumer
+		result = append(result, msg)
+	}
+	return result, "0-0", nil
+}
+
+// ReadPendingFromStream treats every unacknowledged message with an ID as pending
+func (m *MockRedisClient) ReadPendingFromStream(ctx context.Context, stream string, group string, consumer string, afterID string, count int64) ([]StreamMessage, error) {
+	if m.ConsumeErr != nil {
+		return nil, m.ConsumeErr
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var result []StreamMessage
+	for _, msg := range m.StreamData {
+		if msg.Stream != stream || msg.ID == "" || m.Acked[msg.ID] {
+			continue
+		}
+		if CompareStreamIDs(msg.ID, afterID) <= 0 {
+			continue
+		}
+		result = append(result, msg)
+		if count > 0 && int64(len(result)) >= count {
+			break
+		}
+	}
+	return result, nil
+}
+
 func (m *MockRedisClient) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	if m.SetErr != nil {
 		return m.SetErr
@@ -206,14 +529,50 @@ func (m *MockRedisClient) Set(ctx context.Context, key string, value interface{}
 	if err != nil {
 		return err
 	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.Data[key] = string(jsonData)
 	return nil
 }
 
+func (m *MockRedisClient) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	if m.SetErr != nil {
+		return false, m.SetErr
+	}
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.Data[key]; exists {
+		return false, nil
+	}
+	m.Data[key] = string(jsonData)
+	return true, nil
+}
+
+func (m *MockRedisClient) IncrWithTTL(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	if m.SetErr != nil {
+		return 0, m.SetErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, err := strconv.ParseInt(m.Data[key], 10, 64)
+	if err != nil && m.Data[key] != "" {
+		return 0, err
+	}
+	value++
+	m.Data[key] = strconv.FormatInt(value, 10)
+	return value, nil
+}
+
 func (m *MockRedisClient) Get(ctx context.Context, key string) (string, error) {
 	if m.GetErr != nil {
 		return "", m.GetErr
 	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.Data[key], nil
 }
 
@@ -221,7 +580,9 @@ func (m *MockRedisClient) GetJSON(ctx context.Context, key string, dest interfac
 	if m.GetErr != nil {
 		return m.GetErr
 	}
+	m.mu.RLock()
 	value, exists := m.Data[key]
+	m.mu.RUnlock()
 	if !exists {
 		return nil // Return nil if key doesn't exist (like real implementation)
 	}
@@ -229,11 +590,15 @@ func (m *MockRedisClient) GetJSON(ctx context.Context, key string, dest interfac
 }
 
 func (m *MockRedisClient) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	delete(m.Data, key)
 	return nil
 }
 
 func (m *MockRedisClient) Exists(ctx context.Context, key string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	_, exists := m.Data[key]
 	return exists, nil
 }
@@ -277,10 +642,52 @@ func (m *MockRedisClient) SetRemove(ctx context.Context, key string, members ...
 	return nil
 }
 
+func (m *MockRedisClient) HSetBatch(ctx context.Context, key string, fields map[string]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Hashes[key] == nil {
+		m.Hashes[key] = make(map[string]string)
+	}
+	for field, value := range fields {
+		m.Hashes[key][field] = value
+	}
+	return nil
+}
+
+func (m *MockRedisClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make(map[string]string, len(m.Hashes[key]))
+	for field, value := range m.Hashes[key] {
+		result[field] = value
+	}
+	return result, nil
+}
+
+func (m *MockRedisClient) HDel(ctx context.Context, key string, fields ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	hash, exists := m.Hashes[key]
+	if !exists {
+		return nil
+	}
+	for _, field := range fields {
+		delete(hash, field)
+	}
+	return nil
+}
+
 func (m *MockRedisClient) Publish(ctx context.Context, channel string, message interface{}) error {
 	if m.PublishErr != nil {
 		return m.PublishErr
 	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Published = append(m.Published, PubSubMessage{Channel: channel, Message: string(data)})
 	return nil
 }
 
@@ -318,6 +725,30 @@ func (m *MockRedisClient) ZAddBatch(ctx context.Context, key string, members map
 	return nil
 }
 
+func (m *MockRedisClient) ZRangeByScore(ctx context.Context, key string, min, max float64) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var members []ZSetMember
+	for member, score := range m.ZSets[key] {
+		if score >= min && score <= max {
+			members = append(members, ZSetMember{Member: member, Score: score})
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Score != members[j].Score {
+			return members[i].Score < members[j].Score
+		}
+		return members[i].Member < members[j].Member
+	})
+
+	result := make([]string, len(members))
+	for i, member := range members {
+		result[i] = member.Member
+	}
+	return result, nil
+}
+
 func (m *MockRedisClient) ZRevRange(ctx context.Context, key string, start, stop int64) ([]ZSetMember, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -337,14 +768,13 @@ func (m *MockRedisClient) ZRevRange(ctx context.Context, key string, start, stop
 		members = append(members, memberScore{member: member, score: score})
 	}
 
-	// Simple sort by score (descending)
-	for i := 0; i < len(members); i++ {
-		for j := i + 1; j < len(members); j++ {
-			if members[i].score < members[j].score {
-				members[i], members[j] = members[j], members[i]
-			}
+	// Sort by score (descending), ties broken by member in reverse order like Redis
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].score != members[j].score {
+			return members[i].score > members[j].score
 		}
-	}
+		return members[i].member > members[j].member
+	})
 
 	// Apply start/stop range
 	if start < 0 {
@@ -381,6 +811,50 @@ func (m *MockRedisClient) ZRem(ctx context.Context, key string, members ...strin
 	return nil
 }
 
+func (m *MockRedisClient) ZRemRangeByRank(ctx context.Context, key string, start, stop int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	zset, exists := m.ZSets[key]
+	if !exists {
+		return 0, nil
+	}
+
+	// Sort members by score (ascending), ties broken by member like Redis
+	members := make([]string, 0, len(zset))
+	for member := range zset {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if zset[members[i]] != zset[members[j]] {
+			return zset[members[i]] < zset[members[j]]
+		}
+		return members[i] < members[j]
+	})
+
+	// Resolve negative indexes relative to the end, like Redis
+	n := int64(len(members))
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	if start > stop {
+		return 0, nil
+	}
+
+	for i := start; i <= stop; i++ {
+		delete(zset, members[i])
+	}
+	return stop - start + 1, nil
+}
+
 func (m *MockRedisClient) ZCard(ctx context.Context, key string) (int64, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -405,6 +879,16 @@ func (m *MockRedisClient) ZScore(ctx context.Context, key string, member string)
 	return score, nil
 }
 
+func (m *MockRedisClient) ZMScore(ctx context.Context, key string, members ...string) ([]float64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	scores := make([]float64, len(members))
+	for i, member := range members {
+		scores[i] = m.ZSets[key][member]
+	}
+	return scores, nil
+}
+
 func (m *MockRedisClient) Close() error {
 	return nil
 }
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// TimescaleScanStatsStorage implements ScanStatsStorage for TimescaleDB.
+// Samples are written one row per persistence interval, so writes are not batched.
+type TimescaleScanStatsStorage struct {
+	db *sql.DB
+}
+
+// NewTimescaleScanStatsStorage creates a new TimescaleDB scan statistics storage
+func NewTimescaleScanStatsStorage(dbConfig config.DatabaseConfig) (*TimescaleScanStatsStorage, error) {
+	// Build connection string
+	connStr := fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		dbConfig.Host,
+		dbConfig.Port,
+		dbConfig.User,
+		dbConfig.Password,
+		dbConfig.Database,
+		dbConfig.SSLMode,
+	)
+
+	// Open database connection
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+
+	// A single sample per interval needs only a small pool
+	db.SetMaxOpenConns(2)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(dbConfig.ConnMaxLifetime)
+
+	// Test connection
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	logger.Info("TimescaleDB scan stats storage initialized",
+		logger.String("host", dbConfig.Host),
+		logger.Int("port", dbConfig.Port),
+		logger.String("database", dbConfig.Database),
+	)
+
+	return newTimescaleScanStatsStorage(db), nil
+}
+
+// newTimescaleScanStatsStorage creates a scan statistics storage on an open connection
+func newTimescaleScanStatsStorage(db *sql.DB) *TimescaleScanStatsStorage {
+	return &TimescaleScanStatsStorage{db: db}
+}
+
+// WriteScanStats writes one scan statistics sample
+func (s *TimescaleScanStatsStorage) WriteScanStats(ctx context.Context, sample *models.ScanStatsSample) error {
+	query := `
+		INSERT INTO scan_stats (
+			worker_id, timestamp, scan_cycles, symbols_scanned, symbols_skipped_stale,
+			rules_evaluated, rules_matched, alerts_emitted, avg_cycle_time_ms, last_cycle_time_ms
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (worker_id, timestamp) DO NOTHING
+	`
+	if _, err := s.db.ExecContext(ctx, query,
+		sample.WorkerID,
+		sample.Timestamp,
+		sample.ScanCycles,
+		sample.SymbolsScanned,
+		sample.SymbolsSkippedStale,
+		sample.RulesEvaluated,
+		sample.RulesMatched,
+		sample.AlertsEmitted,
+		sample.AvgCycleTimeMs,
+		sample.LastCycleTimeMs,
+	); err != nil {
+		timescaleWriteErrors.WithLabelValues("scan_stats_write_failed").Inc()
+		return fmt.Errorf("failed to insert scan stats: %w", err)
+	}
+	return nil
+}
+
+// GetScanStats retrieves scan statistics samples, oldest first
+func (s *TimescaleScanStatsStorage) GetScanStats(ctx context.Context, filter ScanStatsFilter) ([]*models.ScanStatsSample, error) {
+	query := `
+		SELECT worker_id, timestamp, scan_cycles, symbols_scanned, symbols_skipped_stale,
+			rules_evaluated, rules_matched, alerts_emitted, avg_cycle_time_ms, last_cycle_time_ms
+		FROM scan_stats
+		WHERE 1=1
+	`
+	args := []interface{}{}
+	argIndex := 1
+
+	if filter.WorkerID != "" {
+		query += fmt.Sprintf(" AND worker_id = $%d", argIndex)
+		args = append(args, filter.WorkerID)
+		argIndex++
+	}
+
+	if !filter.StartTime.IsZero() {
+		query += fmt.Sprintf(" AND timestamp >= $%d", argIndex)
+		args = append(args, filter.StartTime)
+		argIndex++
+	}
+
+	if !filter.EndTime.IsZero() {
+		query += fmt.Sprintf(" AND timestamp <= $%d", argIndex)
+		args = append(args, filter.EndTime)
+		argIndex++
+	}
+
+	query += " ORDER BY timestamp ASC, worker_id ASC"
+
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scan stats: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []*models.ScanStatsSample
+	for rows.Next() {
+		var sample models.ScanStatsSample
+		if err := rows.Scan(
+			&sample.WorkerID,
+			&sample.Timestamp,
+			&sample.ScanCycles,
+			&sample.SymbolsScanned,
+			&sample.SymbolsSkippedStale,
+			&sample.RulesEvaluated,
+			&sample.RulesMatched,
+			&sample.AlertsEmitted,
+			&sample.AvgCycleTimeMs,
+			&sample.LastCycleTimeMs,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan scan stats: %w", err)
+		}
+		samples = append(samples, &sample)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return samples, nil
+}
+
+// Close closes the database connection
+func (s *TimescaleScanStatsStorage) Close() error {
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("failed to close database connection: %w", err)
+	}
+	return nil
+}
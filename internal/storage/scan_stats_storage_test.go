@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimescaleScanStatsStorage_WriteScanStats(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	s := newTimescaleScanStatsStorage(db)
+
+	ts := time.Date(2024, 1, 2, 15, 30, 0, 0, time.UTC)
+	require.NoError(t, s.WriteScanStats(context.Background(), &models.ScanStatsSample{
+		WorkerID:            "worker-1",
+		Timestamp:           ts,
+		ScanCycles:          60,
+		SymbolsScanned:      6000,
+		SymbolsSkippedStale: 3,
+		RulesEvaluated:      30000,
+		RulesMatched:        42,
+		AlertsEmitted:       40,
+		AvgCycleTimeMs:      12.5,
+		LastCycleTimeMs:     11,
+	}))
+
+	require.Len(t, rec.statements, 1)
+	assert.True(t, strings.Contains(rec.statements[0].Query, "INSERT INTO scan_stats"))
+	assert.Equal(t, []driver.Value{"worker-1", ts, int64(60), int64(6000), int64(3), int64(30000), int64(42), int64(40), 12.5, 11.0}, rec.statements[0].Args)
+}
+
+func TestTimescaleScanStatsStorage_GetScanStats(t *testing.T) {
+	db, rec := newRecordingDB(t)
+	from := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	rec.expectRows("FROM scan_stats",
+		[]string{"worker_id", "timestamp", "scan_cycles", "symbols_scanned", "symbols_skipped_stale",
+			"rules_evaluated", "rules_matched", "alerts_emitted", "avg_cycle_time_ms", "last_cycle_time_ms"},
+		[]driver.Value{"worker-1", from.Add(time.Minute), int64(60), int64(6000), int64(0), int64(30000), int64(42), int64(40), 12.5, 11.0},
+		[]driver.Value{"worker-1", from.Add(2 * time.Minute), int64(59), int64(5900), int64(2), int64(29500), int64(10), int64(9), 14.0, 15.0},
+	)
+
+	s := newTimescaleScanStatsStorage(db)
+	samples, err := s.GetScanStats(context.Background(), ScanStatsFilter{
+		WorkerID:  "worker-1",
+		StartTime: from,
+		EndTime:   to,
+		Limit:     500,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, rec.statements, 1)
+	query := rec.statements[0].Query
+	assert.Contains(t, query, "AND worker_id = $1 AND timestamp >= $2 AND timestamp <= $3")
+	assert.Contains(t, query, "ORDER BY timestamp ASC, worker_id ASC LIMIT $4")
+	assert.Equal(t, []driver.Value{"worker-1", from, to, 500}, rec.statements[0].Args)
+
+	require.Len(t, samples, 2)
+	assert.Equal(t, models.ScanStatsSample{
+		WorkerID:        "worker-1",
+		Timestamp:       from.Add(time.Minute),
+		ScanCycles:      60,
+		SymbolsScanned:  6000,
+		RulesEvaluated:  30000,
+		RulesMatched:    42,
+		AlertsEmitted:   40,
+		AvgCycleTimeMs:  12.5,
+		LastCycleTimeMs: 11,
+	}, *samples[0])
+	assert.Equal(t, int64(2), samples[1].SymbolsSkippedStale)
+	assert.Equal(t, 14.0, samples[1].AvgCycleTimeMs)
+}
+
+func TestTimescaleScanStatsStorage_GetScanStats_AllWorkers(t *testing.T) {
+	db, rec := newRecordingDB(t)
+
+	s := newTimescaleScanStatsStorage(db)
+	samples, err := s.GetScanStats(context.Background(), ScanStatsFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, samples)
+
+	require.Len(t, rec.statements, 1)
+	assert.NotContains(t, rec.statements[0].Query, "worker_id =")
+	assert.NotContains(t, rec.statements[0].Query, "LIMIT")
+	assert.Empty(t, rec.statements[0].Args)
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CompareStreamIDs compares two Redis stream IDs ("<ms>-<seq>").
+// Returns -1 if a < b, 0 if equal, and 1 if a > b. An empty ID sorts first.
+func CompareStreamIDs(a, b string) int {
+	aMs, aSeq := parseStreamID(a)
+	bMs, bSeq := parseStreamID(b)
+
+	switch {
+	case aMs < bMs:
+		return -1
+	case aMs > bMs:
+		return 1
+	case aSeq < bSeq:
+		return -1
+	case aSeq > bSeq:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// StreamIDBefore returns the greatest stream ID sorting before id, so that reading after it
+// starts at id ("0" for the first possible ID)
+func StreamIDBefore(id string) string {
+	ms, seq := parseStreamID(id)
+	switch {
+	case seq > 0:
+		return fmt.Sprintf("%d-%d", ms, seq-1)
+	case ms > 0:
+		return fmt.Sprintf("%d-%d", ms-1, uint64(math.MaxUint64))
+	default:
+		return "0"
+	}
+}
+
+// StreamIDTime returns the time a stream ID was generated at, from its millisecond part
+func StreamIDTime(id string) time.Time {
+	ms, _ := parseStreamID(id)
+	return time.UnixMilli(int64(ms))
+}
+
+// parseStreamID splits a stream ID into its millisecond and sequence parts
+func parseStreamID(id string) (uint64, uint64) {
+	msPart, seqPart, _ := strings.Cut(id, "-")
+	ms, _ := strconv.ParseUint(msPart, 10, 64)
+	seq, _ := strconv.ParseUint(seqPart, 10, 64)
+	return ms, seq
+}
@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStreamIDBefore(t *testing.T) {
+	tests := map[string]string{
+		"2000-5": "2000-4",
+		"2000-0": "1999-18446744073709551615",
+		"0-0":    "0",
+	}
+	for id, want := range tests {
+		if got := StreamIDBefore(id); got != want {
+			t.Errorf("StreamIDBefore(%q) = %q, want %q", id, got, want)
+		}
+		if id != "0-0" && CompareStreamIDs(StreamIDBefore(id), id) >= 0 {
+			t.Errorf("StreamIDBefore(%q) should sort before it", id)
+		}
+	}
+}
+
+func TestStreamIDTime(t *testing.T) {
+	want := time.UnixMilli(1700000000123)
+	if got := StreamIDTime("1700000000123-7"); !got.Equal(want) {
+		t.Errorf("StreamIDTime = %v, want %v", got, want)
+	}
+}
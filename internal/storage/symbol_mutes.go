@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+const (
+	// SymbolMutesSetKey is the Redis set of muted symbols
+	SymbolMutesSetKey = "symbols:muted"
+	// symbolMuteKeyPrefix prefixes the per-symbol mute details key (expires with the mute TTL)
+	symbolMuteKeyPrefix = "symbols:mute:"
+)
+
+// SymbolMuteStore stores symbol-level alert mutes in Redis. The set of muted symbols
+// indexes per-symbol keys holding the mute details; expired entries are dropped lazily.
+type SymbolMuteStore struct {
+	redis RedisClient
+	now   func() time.Time
+}
+
+// NewSymbolMuteStore creates a new symbol mute store
+func NewSymbolMuteStore(redis RedisClient) *SymbolMuteStore {
+	return &SymbolMuteStore{
+		redis: redis,
+		now:   time.Now,
+	}
+}
+
+func symbolMuteKey(symbol string) string {
+	return symbolMuteKeyPrefix + symbol
+}
+
+// Mute mutes all alerts for a symbol. A ttl of 0 mutes until Unmute is called.
+func (s *SymbolMuteStore) Mute(ctx context.Context, symbol, reason string, ttl time.Duration) (*models.SymbolMute, error) {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if symbol == "" {
+		return nil, models.ErrInvalidSymbol
+	}
+	if ttl < 0 {
+		return nil, fmt.Errorf("mute ttl must be non-negative, got %v", ttl)
+	}
+
+	now := s.now()
+	mute := &models.SymbolMute{
+		Symbol:  symbol,
+		Reason:  reason,
+		MutedAt: now,
+	}
+	if ttl > 0 {
+		expiresAt := now.Add(ttl)
+		mute.ExpiresAt = &expiresAt
+	}
+
+	if err := s.redis.Set(ctx, symbolMuteKey(symbol), mute, ttl); err != nil {
+		return nil, fmt.Errorf("failed to store mute: %w", err)
+	}
+	if err := s.redis.SetAdd(ctx, SymbolMutesSetKey, symbol); err != nil {
+		return nil, fmt.Errorf("failed to add symbol to muted set: %w", err)
+	}
+
+	return mute, nil
+}
+
+// Unmute removes a symbol's mute
+func (s *SymbolMuteStore) Unmute(ctx context.Context, symbol string) error {
+	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+
+	if err := s.redis.SetRemove(ctx, SymbolMutesSetKey, symbol); err != nil {
+		return fmt.Errorf("failed to remove symbol from muted set: %w", err)
+	}
+	if err := s.redis.Delete(ctx, symbolMuteKey(symbol)); err != nil {
+		return fmt.Errorf("failed to delete mute: %w", err)
+	}
+	return nil
+}
+
+// ListMutes returns the active mutes sorted by symbol
+func (s *SymbolMuteStore) ListMutes(ctx context.Context) ([]*models.SymbolMute, error) {
+	symbols, err := s.redis.SetMembers(ctx, SymbolMutesSetKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get muted symbols: %w", err)
+	}
+
+	now := s.now()
+	mutes := make([]*models.SymbolMute, 0, len(symbols))
+	for _, symbol := range symbols {
+		var mute models.SymbolMute
+		if err := s.redis.GetJSON(ctx, symbolMuteKey(symbol), &mute); err != nil {
+			return nil, fmt.Errorf("failed to get mute for %s: %w", symbol, err)
+		}
+
+		// Details key expired (or never written): the mute is over
+		if mute.Symbol == "" || mute.IsExpired(now) {
+			_ = s.redis.SetRemove(ctx, SymbolMutesSetKey, symbol)
+			continue
+		}
+		mutes = append(mutes, &mute)
+	}
+
+	sort.Slice(mutes, func(i, j int) bool {
+		return mutes[i].Symbol < mutes[j].Symbol
+	})
+	return mutes, nil
+}
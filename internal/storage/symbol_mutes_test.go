@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymbolMuteStore_MuteAndUnmute(t *testing.T) {
+	ctx := context.Background()
+	store := NewSymbolMuteStore(NewMockRedisClient())
+
+	mute, err := store.Mute(ctx, " aapl ", "exchange maintenance", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "AAPL", mute.Symbol)
+	assert.Nil(t, mute.ExpiresAt)
+
+	_, err = store.Mute(ctx, "MSFT", "", 30*time.Minute)
+	require.NoError(t, err)
+
+	mutes, err := store.ListMutes(ctx)
+	require.NoError(t, err)
+	require.Len(t, mutes, 2)
+	assert.Equal(t, "AAPL", mutes[0].Symbol)
+	assert.Equal(t, "exchange maintenance", mutes[0].Reason)
+	assert.Equal(t, "MSFT", mutes[1].Symbol)
+	require.NotNil(t, mutes[1].ExpiresAt)
+
+	require.NoError(t, store.Unmute(ctx, "aapl"))
+
+	mutes, err = store.ListMutes(ctx)
+	require.NoError(t, err)
+	require.Len(t, mutes, 1)
+	assert.Equal(t, "MSFT", mutes[0].Symbol)
+
+	_, err = store.Mute(ctx, "  ", "", 0)
+	assert.Error(t, err)
+	_, err = store.Mute(ctx, "TSLA", "", -time.Minute)
+	assert.Error(t, err)
+}
+
+func TestSymbolMuteStore_TTLExpiry(t *testing.T) {
+	ctx := context.Background()
+	redis := NewMockRedisClient()
+	store := NewSymbolMuteStore(redis)
+
+	now := time.Date(2024, 3, 15, 14, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	_, err := store.Mute(ctx, "AAPL", "bad prints", 10*time.Minute)
+	require.NoError(t, err)
+
+	mutes, err := store.ListMutes(ctx)
+	require.NoError(t, err)
+	assert.Len(t, mutes, 1)
+
+	// Expired mutes are dropped from the list and the muted set
+	now = now.Add(10 * time.Minute)
+	mutes, err = store.ListMutes(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, mutes)
+
+	members, err := redis.SetMembers(ctx, SymbolMutesSetKey)
+	require.NoError(t, err)
+	assert.Empty(t, members)
+}
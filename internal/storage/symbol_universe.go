@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// SymbolUniverseKey is the Redis key holding the runtime symbol universe
+const SymbolUniverseKey = "symbols:universe"
+
+// DefaultUniverseReconcileInterval is how often Follow re-reads the stored symbol universe
+// to catch updates whose announcement was missed
+const DefaultUniverseReconcileInterval = time.Minute
+
+// SymbolUniverseStore stores the symbol universe in Redis so it can change without a restart.
+// Until a universe is set, services use their configured symbols.
+type SymbolUniverseStore struct {
+	redis         RedisClient
+	now           func() time.Time
+	retryDelay    time.Duration // First delay before resubscribing in Follow
+	maxRetryDelay time.Duration // Cap of the doubling resubscribe delay
+}
+
+// NewSymbolUniverseStore creates a new symbol universe store
+func NewSymbolUniverseStore(redis RedisClient) *SymbolUniverseStore {
+	return &SymbolUniverseStore{
+		redis:         redis,
+		now:           time.Now,
+		retryDelay:    time.Second,
+		maxRetryDelay: 30 * time.Second,
+	}
+}
+
+// GetUniverse returns the stored symbol universe, sorted (nil if none was set)
+func (s *SymbolUniverseStore) GetUniverse(ctx context.Context) ([]string, error) {
+	var symbols []string
+	if err := s.redis.GetJSON(ctx, SymbolUniverseKey, &symbols); err != nil {
+		return nil, fmt.Errorf("failed to get symbol universe: %w", err)
+	}
+	return symbols, nil
+}
+
+// SetUniverse replaces the symbol universe and announces the change on
+// models.SymbolUniverseChannel. Symbols are upper-cased and deduplicated.
+func (s *SymbolUniverseStore) SetUniverse(ctx context.Context, symbols []string) (*models.SymbolUniverseUpdate, error) {
+	normalized, err := normalizeUniverse(symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := s.GetUniverse(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	update := &models.SymbolUniverseUpdate{
+		Symbols:   normalized,
+		Added:     symbolsNotIn(normalized, previous),
+		Removed:   symbolsNotIn(previous, normalized),
+		UpdatedAt: s.now().UTC(),
+	}
+
+	if err := s.redis.Set(ctx, SymbolUniverseKey, normalized, 0); err != nil {
+		return nil, fmt.Errorf("failed to store symbol universe: %w", err)
+	}
+	if err := s.redis.Publish(ctx, models.SymbolUniverseChannel, update); err != nil {
+		return nil, fmt.Errorf("failed to announce symbol universe: %w", err)
+	}
+
+	return update, nil
+}
+
+// Follow keeps a service in step with the symbol universe until ctx is done. current is the
+// universe the service already uses; handle is called with the new symbols whenever the
+// universe differs from the last one handled. Announced updates are applied as they arrive.
+// The subscription is renewed with a doubling backoff when it fails or closes, and the stored
+// universe is re-read after every subscribe and every reconcileInterval, so updates announced
+// while disconnected are not lost.
+func (s *SymbolUniverseStore) Follow(ctx context.Context, current []string, reconcileInterval time.Duration, handle func(symbols []string)) {
+	if reconcileInterval <= 0 {
+		reconcileInterval = DefaultUniverseReconcileInterval
+	}
+
+	last := append([]string(nil), current...)
+	sort.Strings(last)
+	apply := func(symbols []string) {
+		if len(symbols) == 0 || slices.Equal(symbols, last) {
+			return
+		}
+		last = append([]string(nil), symbols...)
+		handle(symbols)
+	}
+	reconcile := func() {
+		symbols, err := s.GetUniverse(ctx)
+		if err != nil {
+			logger.Warn("Failed to reconcile symbol universe",
+				logger.ErrorField(err),
+			)
+			return
+		}
+		apply(symbols)
+	}
+
+	ticker := time.NewTicker(reconcileInterval)
+	defer ticker.Stop()
+
+	delay := s.retryDelay
+	for {
+		messageChan, err := s.redis.Subscribe(ctx, models.SymbolUniverseChannel)
+		if err != nil {
+			logger.Warn("Failed to subscribe to symbol universe updates",
+				logger.ErrorField(err),
+				logger.Duration("retry_in", delay),
+			)
+		} else {
+			delay = s.retryDelay
+			reconcile()
+			s.readUpdates(ctx, messageChan, ticker.C, reconcile, func(update *models.SymbolUniverseUpdate) {
+				apply(update.Symbols)
+			})
+			if ctx.Err() == nil {
+				logger.Warn("Symbol universe subscription closed, resubscribing",
+					logger.Duration("retry_in", delay),
+				)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, s.maxRetryDelay)
+	}
+}
+
+// readUpdates calls handle with the valid updates on messageChan, and onTick on every tick,
+// until ctx is done or messageChan closes
+func (s *SymbolUniverseStore) readUpdates(ctx context.Context, messageChan <-chan PubSubMessage, tick <-chan time.Time, onTick func(), handle func(update *models.SymbolUniverseUpdate)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			onTick()
+		case msg, ok := <-messageChan:
+			if !ok {
+				return
+			}
+			if msg.Channel != models.SymbolUniverseChannel {
+				continue
+			}
+
+			var update models.SymbolUniverseUpdate
+			if err := json.Unmarshal([]byte(msg.Message), &update); err != nil {
+				logger.Warn("Failed to parse symbol universe update",
+					logger.ErrorField(err),
+				)
+				continue
+			}
+			if len(update.Symbols) == 0 {
+				logger.Warn("Ignoring empty symbol universe update")
+				continue
+			}
+
+			handle(&update)
+		}
+	}
+}
+
+// normalizeUniverse upper-cases, deduplicates and sorts symbols
+func normalizeUniverse(symbols []string) ([]string, error) {
+	seen := make(map[string]bool, len(symbols))
+	normalized := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol == "" {
+			return nil, models.ErrInvalidSymbol
+		}
+		if !seen[symbol] {
+			seen[symbol] = true
+			normalized = append(normalized, symbol)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, models.ErrEmptySymbolUniverse
+	}
+
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// symbolsNotIn returns the symbols of a that are not in b
+func symbolsNotIn(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, symbol := range b {
+		in[symbol] = true
+	}
+
+	result := make([]string, 0)
+	for _, symbol := range a {
+		if !in[symbol] {
+			result = append(result, symbol)
+		}
+	}
+	return result
+}
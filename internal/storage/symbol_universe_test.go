@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymbolUniverseStore_SetUniverse(t *testing.T) {
+	ctx := context.Background()
+	redis := NewMockRedisClient()
+	store := NewSymbolUniverseStore(redis)
+
+	universe, err := store.GetUniverse(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, universe)
+
+	update, err := store.SetUniverse(ctx, []string{"msft", " AAPL ", "MSFT"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL", "MSFT"}, update.Symbols)
+	assert.Equal(t, []string{"AAPL", "MSFT"}, update.Added)
+	assert.Empty(t, update.Removed)
+
+	update, err = store.SetUniverse(ctx, []string{"AAPL", "TSLA"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TSLA"}, update.Added)
+	assert.Equal(t, []string{"MSFT"}, update.Removed)
+
+	universe, err = store.GetUniverse(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AAPL", "TSLA"}, universe)
+
+	// Every change is announced
+	require.Len(t, redis.Published, 2)
+	assert.Equal(t, models.SymbolUniverseChannel, redis.Published[1].Channel)
+	var announced models.SymbolUniverseUpdate
+	require.NoError(t, json.Unmarshal([]byte(redis.Published[1].Message), &announced))
+	assert.Equal(t, []string{"AAPL", "TSLA"}, announced.Symbols)
+
+	// Invalid universes are rejected and leave the stored one alone
+	_, err = store.SetUniverse(ctx, []string{"AAPL", " "})
+	assert.ErrorIs(t, err, models.ErrInvalidSymbol)
+	_, err = store.SetUniverse(ctx, nil)
+	assert.ErrorIs(t, err, models.ErrEmptySymbolUniverse)
+	universe, _ = store.GetUniverse(ctx)
+	assert.Equal(t, []string{"AAPL", "TSLA"}, universe)
+}
+
+func TestSymbolUniverseStore_ReadUpdates(t *testing.T) {
+	redis := NewMockRedisClient()
+	store := NewSymbolUniverseStore(redis)
+
+	valid, err := json.Marshal(models.SymbolUniverseUpdate{
+		Symbols:   []string{"AAPL", "NVDA"},
+		Added:     []string{"NVDA"},
+		UpdatedAt: time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+	redis.PubSubData = []PubSubMessage{
+		{Channel: "other", Message: string(valid)},
+		{Channel: models.SymbolUniverseChannel, Message: "not json"},
+		{Channel: models.SymbolUniverseChannel, Message: `{"symbols": []}`},
+		{Channel: models.SymbolUniverseChannel, Message: string(valid)},
+	}
+
+	messageChan, err := redis.Subscribe(context.Background(), models.SymbolUniverseChannel)
+	require.NoError(t, err)
+
+	var updates []*models.SymbolUniverseUpdate
+	store.readUpdates(context.Background(), messageChan, nil, nil, func(update *models.SymbolUniverseUpdate) {
+		updates = append(updates, update)
+	})
+
+	require.Len(t, updates, 1)
+	assert.Equal(t, []string{"AAPL", "NVDA"}, updates[0].Symbols)
+	assert.Equal(t, []string{"NVDA"}, updates[0].Added)
+}
+
+func TestSymbolUniverseStore_Follow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	redis := NewMockRedisClient()
+	store := NewSymbolUniverseStore(redis)
+	store.retryDelay = time.Millisecond
+	store.maxRetryDelay = 5 * time.Millisecond
+
+	// The mock's subscription closes at once, so Follow keeps resubscribing
+	require.NoError(t, redis.Set(ctx, SymbolUniverseKey, []string{"AAPL", "NVDA"}, 0))
+
+	updates := make(chan []string, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		store.Follow(ctx, []string{"AAPL"}, time.Hour, func(symbols []string) {
+			updates <- symbols
+		})
+	}()
+
+	select {
+	case symbols := <-updates:
+		assert.Equal(t, []string{"AAPL", "NVDA"}, symbols)
+	case <-time.After(time.Second):
+		t.Fatal("Expected the stored universe to be applied")
+	}
+
+	// A change stored while the subscription is down is picked up when resubscribing
+	_, err := store.SetUniverse(ctx, []string{"AAPL", "NVDA", "TSLA"})
+	require.NoError(t, err)
+	select {
+	case symbols := <-updates:
+		assert.Equal(t, []string{"AAPL", "NVDA", "TSLA"}, symbols)
+	case <-time.After(time.Second):
+		t.Fatal("Expected the universe to be reconciled after resubscribing")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Follow to return when the context is done")
+	}
+
+	// Unchanged universes are not handled again
+	assert.Empty(t, updates)
+}
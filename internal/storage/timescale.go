@@ -486,6 +486,11 @@ func (t *TimescaleDBClient) insertBars(ctx context.Context, bars []*models.Bar1m
 	return nil
 }
 
+// Hypertables returns a hypertable manager using this client's connection
+func (t *TimescaleDBClient) Hypertables() *HypertableManager {
+	return NewHypertableManager(t.db)
+}
+
 // IsRunning returns whether the client is running
 func (t *TimescaleDBClient) IsRunning() bool {
 	t.mu.RLock()
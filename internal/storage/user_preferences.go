@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// userPreferencesKeyPrefix prefixes the per-user preferences key
+const userPreferencesKeyPrefix = "user:preferences:"
+
+// UserPreferencesStore stores per-user alert delivery preferences in Redis
+type UserPreferencesStore struct {
+	redis RedisClient
+	now   func() time.Time
+}
+
+// NewUserPreferencesStore creates a new user preferences store
+func NewUserPreferencesStore(redis RedisClient) *UserPreferencesStore {
+	return &UserPreferencesStore{
+		redis: redis,
+		now:   time.Now,
+	}
+}
+
+func userPreferencesKey(userID string) string {
+	return userPreferencesKeyPrefix + userID
+}
+
+// GetPreferences returns a user's preferences, or empty preferences if none are stored
+func (s *UserPreferencesStore) GetPreferences(ctx context.Context, userID string) (*models.UserPreferences, error) {
+	var prefs models.UserPreferences
+	if err := s.redis.GetJSON(ctx, userPreferencesKey(userID), &prefs); err != nil {
+		return nil, fmt.Errorf("failed to get preferences for user %s: %w", userID, err)
+	}
+	prefs.UserID = userID
+	return &prefs, nil
+}
+
+// SetPreferences validates and stores a user's preferences, replacing any existing ones.
+// Expired snoozes are dropped.
+func (s *UserPreferencesStore) SetPreferences(ctx context.Context, prefs *models.UserPreferences) error {
+	if err := prefs.Validate(); err != nil {
+		return err
+	}
+
+	now := s.now()
+	active := prefs.Snoozes[:0]
+	for _, snooze := range prefs.Snoozes {
+		if now.Before(snooze.Until) {
+			active = append(active, snooze)
+		}
+	}
+	prefs.Snoozes = active
+	prefs.UpdatedAt = now
+
+	if err := s.redis.Set(ctx, userPreferencesKey(prefs.UserID), prefs, 0); err != nil {
+		return fmt.Errorf("failed to store preferences for user %s: %w", prefs.UserID, err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserPreferencesStore_Persistence(t *testing.T) {
+	ctx := context.Background()
+	redis := NewMockRedisClient()
+	store := NewUserPreferencesStore(redis)
+
+	now := time.Date(2024, 3, 15, 14, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	// No stored preferences yields empty preferences for the user
+	prefs, err := store.GetPreferences(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, &models.UserPreferences{UserID: "user-1"}, prefs)
+
+	err = store.SetPreferences(ctx, &models.UserPreferences{
+		UserID:          "user-1",
+		DefaultChannels: []string{"kafka", "websocket"},
+		QuietHours:      &models.QuietHours{Start: "22:00", End: "07:00", Timezone: "America/New_York"},
+		Locale:          "de-DE",
+		Snoozes: []models.AlertSnooze{
+			{Symbol: "AAPL", Until: now.Add(time.Hour)},
+			{RuleID: "rule-1", Until: now.Add(-time.Minute)}, // Already expired
+		},
+	})
+	require.NoError(t, err)
+
+	// A new store over the same Redis sees the persisted preferences
+	prefs, err = NewUserPreferencesStore(redis).GetPreferences(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kafka", "websocket"}, prefs.DefaultChannels)
+	assert.Equal(t, &models.QuietHours{Start: "22:00", End: "07:00", Timezone: "America/New_York"}, prefs.QuietHours)
+	assert.Equal(t, "de-DE", prefs.Locale)
+	require.Len(t, prefs.Snoozes, 1)
+	assert.Equal(t, "AAPL", prefs.Snoozes[0].Symbol)
+	assert.True(t, prefs.UpdatedAt.Equal(now))
+
+	// Other users are unaffected
+	prefs, err = store.GetPreferences(ctx, "user-2")
+	require.NoError(t, err)
+	assert.Empty(t, prefs.DefaultChannels)
+}
+
+func TestUserPreferencesStore_RejectsInvalidPreferences(t *testing.T) {
+	store := NewUserPreferencesStore(NewMockRedisClient())
+
+	err := store.SetPreferences(context.Background(), &models.UserPreferences{
+		UserID:     "user-1",
+		QuietHours: &models.QuietHours{Start: "25:00", End: "07:00"},
+	})
+	assert.True(t, errors.Is(err, models.ErrInvalidUserPreferences))
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// WebhooksKey is the Redis hash of user-registered webhooks, keyed by webhook ID
+const WebhooksKey = "webhooks"
+
+// WebhookStore stores user-registered webhooks in Redis, so the API can manage them and the
+// alert service delivers to them
+type WebhookStore struct {
+	redis RedisClient
+	now   func() time.Time
+}
+
+// NewWebhookStore creates a new webhook store
+func NewWebhookStore(redis RedisClient) *WebhookStore {
+	return &WebhookStore{
+		redis: redis,
+		now:   time.Now,
+	}
+}
+
+// CreateWebhook stores a new webhook, assigning its ID, signing secret and creation time
+func (s *WebhookStore) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	if err := webhook.Validate(); err != nil {
+		return err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	webhook.ID = uuid.New().String()
+	webhook.Secret = hex.EncodeToString(secret)
+	webhook.CreatedAt = s.now().UTC()
+
+	data, err := json.Marshal(webhook)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook: %w", err)
+	}
+	if err := s.redis.HSetBatch(ctx, WebhooksKey, map[string]string{webhook.ID: string(data)}); err != nil {
+		return fmt.Errorf("failed to store webhook %s: %w", webhook.ID, err)
+	}
+	return nil
+}
+
+// GetWebhook returns a webhook by ID (nil if it does not exist)
+func (s *WebhookStore) GetWebhook(ctx context.Context, id string) (*models.Webhook, error) {
+	webhooks, err := s.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, webhook := range webhooks {
+		if webhook.ID == id {
+			return webhook, nil
+		}
+	}
+	return nil, nil
+}
+
+// DeleteWebhook removes a webhook
+func (s *WebhookStore) DeleteWebhook(ctx context.Context, id string) error {
+	if err := s.redis.HDel(ctx, WebhooksKey, id); err != nil {
+		return fmt.Errorf("failed to delete webhook %s: %w", id, err)
+	}
+	return nil
+}
+
+// ListWebhooks returns every user's webhooks, oldest first
+func (s *WebhookStore) ListWebhooks(ctx context.Context) ([]*models.Webhook, error) {
+	fields, err := s.redis.HGetAll(ctx, WebhooksKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	webhooks := make([]*models.Webhook, 0, len(fields))
+	for id, data := range fields {
+		var webhook models.Webhook
+		if err := json.Unmarshal([]byte(data), &webhook); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal webhook %s: %w", id, err)
+		}
+		webhooks = append(webhooks, &webhook)
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		if !webhooks[i].CreatedAt.Equal(webhooks[j].CreatedAt) {
+			return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+		}
+		return webhooks[i].ID < webhooks[j].ID
+	})
+	return webhooks, nil
+}
+
+// ListUserWebhooks returns a user's webhooks, oldest first
+func (s *WebhookStore) ListUserWebhooks(ctx context.Context, userID string) ([]*models.Webhook, error) {
+	webhooks, err := s.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make([]*models.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		if webhook.UserID == userID {
+			owned = append(owned, webhook)
+		}
+	}
+	return owned, nil
+}
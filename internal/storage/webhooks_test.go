@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookStore_CreateListDelete(t *testing.T) {
+	ctx := context.Background()
+	store := NewWebhookStore(NewMockRedisClient())
+
+	alice := &models.Webhook{UserID: "alice", URL: "https://alice.example.com/hook", RuleID: "rule-1"}
+	bob := &models.Webhook{UserID: "bob", URL: "https://bob.example.com/hook"}
+	require.NoError(t, store.CreateWebhook(ctx, alice))
+	require.NoError(t, store.CreateWebhook(ctx, bob))
+
+	assert.NotEmpty(t, alice.ID)
+	assert.NotEqual(t, alice.ID, bob.ID)
+	assert.Len(t, alice.Secret, 64)
+	assert.NotEqual(t, alice.Secret, bob.Secret, "each webhook gets its own secret")
+	assert.False(t, alice.CreatedAt.IsZero())
+
+	webhooks, err := store.ListWebhooks(ctx)
+	require.NoError(t, err)
+	assert.Len(t, webhooks, 2)
+
+	owned, err := store.ListUserWebhooks(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, owned, 1)
+	assert.Equal(t, alice.ID, owned[0].ID)
+	assert.Equal(t, "rule-1", owned[0].RuleID)
+	assert.Equal(t, alice.Secret, owned[0].Secret)
+
+	got, err := store.GetWebhook(ctx, bob.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "https://bob.example.com/hook", got.URL)
+
+	require.NoError(t, store.DeleteWebhook(ctx, bob.ID))
+	got, err = store.GetWebhook(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestWebhookStore_CreateValidates(t *testing.T) {
+	ctx := context.Background()
+	store := NewWebhookStore(NewMockRedisClient())
+
+	assert.ErrorIs(t, store.CreateWebhook(ctx, &models.Webhook{URL: "https://example.com"}), models.ErrInvalidWebhookUser)
+	assert.ErrorIs(t, store.CreateWebhook(ctx, &models.Webhook{UserID: "alice", URL: "ftp://example.com"}), models.ErrInvalidWebhookURL)
+	assert.ErrorIs(t, store.CreateWebhook(ctx, &models.Webhook{UserID: "alice", URL: "/relative"}), models.ErrInvalidWebhookURL)
+}
@@ -70,7 +70,7 @@ func NewDatabaseToplistStore(dbConfig config.DatabaseConfig) (*DatabaseToplistSt
 func (s *DatabaseToplistStore) GetToplistConfig(ctx context.Context, toplistID string) (*models.ToplistConfig, error) {
 	query := `
 		SELECT id, user_id, name, description, metric, time_window, sort_order,
-		       filters, columns, color_scheme, enabled, created_at, updated_at
+		       filters, columns, color_scheme, max_size, custom_metric, change_unit, normalization, tie_breakers, compound_windows, enabled, created_at, updated_at
 		FROM toplist_configs
 		WHERE id = $1
 	`
@@ -79,6 +79,12 @@ func (s *DatabaseToplistStore) GetToplistConfig(ctx context.Context, toplistID s
 	var userID sql.NullString
 	var description sql.NullString
 	var filtersJSON, columnsJSON, colorSchemeJSON sql.NullString
+	var maxSize sql.NullInt64
+	var customMetric sql.NullString
+	var changeUnit sql.NullString
+	var normalization sql.NullString
+	var tieBreakersJSON sql.NullString
+	var compoundWindowsJSON sql.NullString
 	var createdAt, updatedAt time.Time
 
 	err := s.db.QueryRowContext(ctx, query, toplistID).Scan(
@@ -92,6 +98,12 @@ func (s *DatabaseToplistStore) GetToplistConfig(ctx context.Context, toplistID s
 		&filtersJSON,
 		&columnsJSON,
 		&colorSchemeJSON,
+		&maxSize,
+		&customMetric,
+		&changeUnit,
+		&normalization,
+		&tieBreakersJSON,
+		&compoundWindowsJSON,
 		&config.Enabled,
 		&createdAt,
 		&updatedAt,
@@ -105,6 +117,10 @@ func (s *DatabaseToplistStore) GetToplistConfig(ctx context.Context, toplistID s
 
 	config.UserID = userID.String
 	config.Description = description.String
+	config.MaxSize = int(maxSize.Int64)
+	config.CustomMetric = customMetric.String
+	config.ChangeUnit = models.ToplistChangeUnit(changeUnit.String)
+	config.Normalization = models.ToplistNormalization(normalization.String)
 	config.CreatedAt = createdAt
 	config.UpdatedAt = updatedAt
 
@@ -129,6 +145,18 @@ func (s *DatabaseToplistStore) GetToplistConfig(ctx context.Context, toplistID s
 		}
 	}
 
+	if tieBreakersJSON.Valid && tieBreakersJSON.String != "" {
+		if err := json.Unmarshal([]byte(tieBreakersJSON.String), &config.TieBreakers); err != nil {
+			config.TieBreakers = nil
+		}
+	}
+
+	if compoundWindowsJSON.Valid && compoundWindowsJSON.String != "" {
+		if err := json.Unmarshal([]byte(compoundWindowsJSON.String), &config.CompoundWindows); err != nil {
+			config.CompoundWindows = nil
+		}
+	}
+
 	return &config, nil
 }
 
@@ -136,7 +164,7 @@ func (s *DatabaseToplistStore) GetToplistConfig(ctx context.Context, toplistID s
 func (s *DatabaseToplistStore) GetUserToplists(ctx context.Context, userID string) ([]*models.ToplistConfig, error) {
 	query := `
 		SELECT id, user_id, name, description, metric, time_window, sort_order,
-		       filters, columns, color_scheme, enabled, created_at, updated_at
+		       filters, columns, color_scheme, max_size, custom_metric, change_unit, normalization, tie_breakers, compound_windows, enabled, created_at, updated_at
 		FROM toplist_configs
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -161,7 +189,7 @@ func (s *DatabaseToplistStore) GetEnabledToplists(ctx context.Context, userID st
 		// Get all enabled toplists (both system and user) for processing by scanner/indicator services
 		query = `
 			SELECT id, user_id, name, description, metric, time_window, sort_order,
-			       filters, columns, color_scheme, enabled, created_at, updated_at
+			       filters, columns, color_scheme, max_size, custom_metric, change_unit, normalization, tie_breakers, compound_windows, enabled, created_at, updated_at
 			FROM toplist_configs
 			WHERE enabled = true
 			ORDER BY created_at DESC
@@ -170,7 +198,7 @@ func (s *DatabaseToplistStore) GetEnabledToplists(ctx context.Context, userID st
 		// Get enabled toplists for a specific user
 		query = `
 			SELECT id, user_id, name, description, metric, time_window, sort_order,
-			       filters, columns, color_scheme, enabled, created_at, updated_at
+			       filters, columns, color_scheme, max_size, custom_metric, change_unit, normalization, tie_breakers, compound_windows, enabled, created_at, updated_at
 			FROM toplist_configs
 			WHERE user_id = $1 AND enabled = true
 			ORDER BY created_at DESC
@@ -215,8 +243,9 @@ func (s *DatabaseToplistStore) CreateToplist(ctx context.Context, config *models
 	query := `
 		INSERT INTO toplist_configs (
 			id, user_id, name, description, metric, time_window, sort_order,
-			filters, columns, color_scheme, enabled, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			filters, columns, color_scheme, max_size, enabled, created_at, updated_at, custom_metric, change_unit,
+			normalization, tie_breakers, compound_windows
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
 	`
 
 	var userID interface{}
@@ -237,9 +266,15 @@ func (s *DatabaseToplistStore) CreateToplist(ctx context.Context, config *models
 		string(filtersJSON),
 		string(columnsJSON),
 		string(colorSchemeJSON),
+		maxSizeParam(config.MaxSize),
 		config.Enabled,
 		config.CreatedAt,
 		config.UpdatedAt,
+		customMetricParam(config.CustomMetric),
+		changeUnitParam(config.ChangeUnit),
+		normalizationParam(config.Normalization),
+		tieBreakersParam(config.TieBreakers),
+		compoundWindowsParam(config.CompoundWindows),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create toplist: %w", err)
@@ -266,7 +301,9 @@ func (s *DatabaseToplistStore) UpdateToplist(ctx context.Context, config *models
 	query := `
 		UPDATE toplist_configs
 		SET name = $2, description = $3, metric = $4, time_window = $5, sort_order = $6,
-		    filters = $7, columns = $8, color_scheme = $9, enabled = $10, updated_at = $11
+		    filters = $7, columns = $8, color_scheme = $9, max_size = $10, enabled = $11, updated_at = $12,
+		    custom_metric = $13, change_unit = $14, normalization = $15, tie_breakers = $16,
+		    compound_windows = $17
 		WHERE id = $1
 	`
 
@@ -280,8 +317,14 @@ func (s *DatabaseToplistStore) UpdateToplist(ctx context.Context, config *models
 		string(filtersJSON),
 		string(columnsJSON),
 		string(colorSchemeJSON),
+		maxSizeParam(config.MaxSize),
 		config.Enabled,
 		config.UpdatedAt,
+		customMetricParam(config.CustomMetric),
+		changeUnitParam(config.ChangeUnit),
+		normalizationParam(config.Normalization),
+		tieBreakersParam(config.TieBreakers),
+		compoundWindowsParam(config.CompoundWindows),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update toplist: %w", err)
@@ -323,6 +366,56 @@ func (s *DatabaseToplistStore) Close() error {
 	return s.db.Close()
 }
 
+// maxSizeParam converts a max size to a query parameter (NULL when unset)
+func maxSizeParam(maxSize int) interface{} {
+	if maxSize <= 0 {
+		return nil
+	}
+	return maxSize
+}
+
+// customMetricParam converts a custom metric name to a query parameter (NULL when unset)
+func customMetricParam(name string) interface{} {
+	if name == "" {
+		return nil
+	}
+	return name
+}
+
+// changeUnitParam converts a change unit to a query parameter (NULL when unset)
+func changeUnitParam(unit models.ToplistChangeUnit) interface{} {
+	if unit == "" {
+		return nil
+	}
+	return string(unit)
+}
+
+// normalizationParam converts a normalization method to a query parameter (NULL when unset)
+func normalizationParam(normalization models.ToplistNormalization) interface{} {
+	if normalization == "" {
+		return nil
+	}
+	return string(normalization)
+}
+
+// tieBreakersParam converts tie breakers to a JSON query parameter (NULL when unset)
+func tieBreakersParam(tieBreakers []models.ToplistTieBreaker) interface{} {
+	if len(tieBreakers) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(tieBreakers)
+	return string(data)
+}
+
+// compoundWindowsParam converts compound windows to a JSON query parameter (NULL when unset)
+func compoundWindowsParam(windows []models.ToplistWindowThreshold) interface{} {
+	if len(windows) == 0 {
+		return nil
+	}
+	data, _ := json.Marshal(windows)
+	return string(data)
+}
+
 // scanToplistConfigs scans rows into ToplistConfig structs
 func (s *DatabaseToplistStore) scanToplistConfigs(rows *sql.Rows) ([]*models.ToplistConfig, error) {
 	var configs []*models.ToplistConfig
@@ -332,6 +425,12 @@ func (s *DatabaseToplistStore) scanToplistConfigs(rows *sql.Rows) ([]*models.Top
 		var userID sql.NullString
 		var description sql.NullString
 		var filtersJSON, columnsJSON, colorSchemeJSON sql.NullString
+		var maxSize sql.NullInt64
+		var customMetric sql.NullString
+		var changeUnit sql.NullString
+		var normalization sql.NullString
+		var tieBreakersJSON sql.NullString
+		var compoundWindowsJSON sql.NullString
 		var createdAt, updatedAt time.Time
 
 		err := rows.Scan(
@@ -345,6 +444,12 @@ func (s *DatabaseToplistStore) scanToplistConfigs(rows *sql.Rows) ([]*models.Top
 			&filtersJSON,
 			&columnsJSON,
 			&colorSchemeJSON,
+			&maxSize,
+			&customMetric,
+			&changeUnit,
+			&normalization,
+			&tieBreakersJSON,
+			&compoundWindowsJSON,
 			&config.Enabled,
 			&createdAt,
 			&updatedAt,
@@ -355,6 +460,10 @@ func (s *DatabaseToplistStore) scanToplistConfigs(rows *sql.Rows) ([]*models.Top
 
 		config.UserID = userID.String
 		config.Description = description.String
+		config.MaxSize = int(maxSize.Int64)
+		config.CustomMetric = customMetric.String
+		config.ChangeUnit = models.ToplistChangeUnit(changeUnit.String)
+		config.Normalization = models.ToplistNormalization(normalization.String)
 		config.CreatedAt = createdAt
 		config.UpdatedAt = updatedAt
 
@@ -379,6 +488,18 @@ func (s *DatabaseToplistStore) scanToplistConfigs(rows *sql.Rows) ([]*models.Top
 			}
 		}
 
+		if tieBreakersJSON.Valid && tieBreakersJSON.String != "" {
+			if err := json.Unmarshal([]byte(tieBreakersJSON.String), &config.TieBreakers); err != nil {
+				config.TieBreakers = nil
+			}
+		}
+
+		if compoundWindowsJSON.Valid && compoundWindowsJSON.String != "" {
+			if err := json.Unmarshal([]byte(compoundWindowsJSON.String), &config.CompoundWindows); err != nil {
+				config.CompoundWindows = nil
+			}
+		}
+
 		configs = append(configs, &config)
 	}
 
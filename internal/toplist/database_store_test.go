@@ -92,3 +92,86 @@ func TestToplistService_GetToplistCount(t *testing.T) {
 	}
 }
 
+
+func TestToplistService_TieBreakers(t *testing.T) {
+	mockStore := NewMockToplistStore()
+	mockRedis := storage.NewMockRedisClient()
+	service := NewToplistService(mockStore, mockRedis, NewRedisToplistUpdater(mockRedis))
+	ctx := context.Background()
+
+	config := &models.ToplistConfig{
+		ID:          "test-1",
+		UserID:      "user-123",
+		Name:        "Test Toplist",
+		Metric:      models.MetricChangePct,
+		TimeWindow:  models.Window1m,
+		SortOrder:   models.SortOrderDesc,
+		TieBreakers: []models.ToplistTieBreaker{"volume", models.TieBreakSymbol},
+		Enabled:     true,
+	}
+	mockStore.CreateToplist(ctx, config)
+
+	key := models.GetUserToplistRedisKey("user-123", "test-1")
+	mockRedis.ZAdd(ctx, key, 5.0, "TSLA")
+	mockRedis.ZAdd(ctx, key, 2.5, "AAPL")
+	mockRedis.ZAdd(ctx, key, 2.5, "MSFT")
+	mockRedis.ZAdd(ctx, key, 2.5, "AMZN")
+	mockRedis.ZAdd(ctx, key, 2.5, "GOOGL")
+	mockRedis.ZAdd(ctx, key, 1.0, "NVDA")
+
+	// MSFT has the highest volume; AAPL and AMZN tie on volume and fall back to symbol
+	volumeKey := models.GetSystemToplistRedisKey(models.MetricVolume, models.Window1m)
+	mockRedis.ZAdd(ctx, volumeKey, 3000, "MSFT")
+	mockRedis.ZAdd(ctx, volumeKey, 1000, "AAPL")
+	mockRedis.ZAdd(ctx, volumeKey, 1000, "AMZN")
+	mockRedis.ZAdd(ctx, volumeKey, 500, "GOOGL")
+
+	t.Run("full list", func(t *testing.T) {
+		rankings, err := service.GetToplistRankings(ctx, "test-1", 10, 0, nil)
+		if err != nil {
+			t.Fatalf("GetToplistRankings() error = %v", err)
+		}
+		want := []string{"TSLA", "MSFT", "AAPL", "AMZN", "GOOGL", "NVDA"}
+		assertRankingSymbols(t, rankings, want)
+	})
+
+	t.Run("tie group across pages", func(t *testing.T) {
+		first, err := service.GetToplistRankings(ctx, "test-1", 2, 0, nil)
+		if err != nil {
+			t.Fatalf("GetToplistRankings() error = %v", err)
+		}
+		assertRankingSymbols(t, first, []string{"TSLA", "MSFT"})
+
+		second, err := service.GetToplistRankings(ctx, "test-1", 2, 2, nil)
+		if err != nil {
+			t.Fatalf("GetToplistRankings() error = %v", err)
+		}
+		assertRankingSymbols(t, second, []string{"AAPL", "AMZN"})
+		if second[0].Rank != 3 {
+			t.Errorf("Rank = %d, want 3", second[0].Rank)
+		}
+	})
+
+	t.Run("ascending keeps tie order", func(t *testing.T) {
+		asc := *config
+		asc.SortOrder = models.SortOrderAsc
+		rankings, err := service.GetRankingsByConfig(ctx, &asc, 10, 0, nil)
+		if err != nil {
+			t.Fatalf("GetRankingsByConfig() error = %v", err)
+		}
+		want := []string{"NVDA", "MSFT", "AAPL", "AMZN", "GOOGL", "TSLA"}
+		assertRankingSymbols(t, rankings, want)
+	})
+}
+
+func assertRankingSymbols(t *testing.T, rankings []models.ToplistRanking, want []string) {
+	t.Helper()
+	if len(rankings) != len(want) {
+		t.Fatalf("got %d rankings, want %d", len(rankings), len(want))
+	}
+	for i, symbol := range want {
+		if rankings[i].Symbol != symbol {
+			t.Errorf("rankings[%d] = %s, want %s", i, rankings[i].Symbol, symbol)
+		}
+	}
+}
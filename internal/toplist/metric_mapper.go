@@ -1,22 +1,40 @@
 package toplist
 
 import (
+	"math"
+
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
 )
 
+// CustomMetricResolver resolves user-defined custom metrics
+type CustomMetricResolver interface {
+	// ResolveUserMetric computes a user's custom metric from the given metrics
+	ResolveUserMetric(userID, name string, metrics map[string]float64) (float64, bool)
+}
+
 // MetricMapper maps toplist configurations to actual metric names in computed metrics
-type MetricMapper struct{}
+type MetricMapper struct {
+	customMetrics CustomMetricResolver // Optional, for user toplists ranking custom metrics
+}
 
 // NewMetricMapper creates a new metric mapper
 func NewMetricMapper() *MetricMapper {
 	return &MetricMapper{}
 }
 
+// SetCustomMetricResolver enables ranking user toplists by the owner's custom metrics
+func (m *MetricMapper) SetCustomMetricResolver(resolver CustomMetricResolver) {
+	m.customMetrics = resolver
+}
+
 // GetMetricName returns the actual metric name for a given toplist config
 // Returns empty string if the metric is not available in computed metrics
 func (m *MetricMapper) GetMetricName(config *models.ToplistConfig) string {
 	switch config.Metric {
 	case models.MetricChangePct:
+		if config.UsesPoints() {
+			return m.getChangePointsMetricName(config.TimeWindow)
+		}
 		switch config.TimeWindow {
 		case models.Window1m:
 			return "price_change_1m_pct"
@@ -60,6 +78,8 @@ func (m *MetricMapper) GetMetricName(config *models.ToplistConfig) string {
 		// VWAP distance is calculated from vwap_5m and close price
 		// This is a special case that needs to be handled separately
 		return "" // Special handling needed
+	case models.MetricCustom:
+		return config.CustomMetric
 	}
 	return ""
 }
@@ -67,6 +87,28 @@ func (m *MetricMapper) GetMetricName(config *models.ToplistConfig) string {
 // GetMetricValue extracts the metric value from a metrics map based on toplist config
 // Returns the value and whether it was found
 func (m *MetricMapper) GetMetricValue(config *models.ToplistConfig, metrics map[string]float64) (float64, bool) {
+	if config.IsCompound() {
+		return m.getCompoundValue(config, metrics)
+	}
+
+	value, ok := m.lookupMetricValue(config, metrics)
+	// NaN marks a metric without enough history yet; such symbols are left out of the ranking
+	if !ok || math.IsNaN(value) {
+		return 0, false
+	}
+	return value, true
+}
+
+// lookupMetricValue returns the raw metric value backing a toplist
+func (m *MetricMapper) lookupMetricValue(config *models.ToplistConfig, metrics map[string]float64) (float64, bool) {
+	// Custom metrics resolve with the toplist owner's definitions only
+	if config.Metric == models.MetricCustom {
+		if m.customMetrics == nil || config.IsSystemToplist() {
+			return 0, false
+		}
+		return m.customMetrics.ResolveUserMetric(config.UserID, config.CustomMetric, metrics)
+	}
+
 	metricName := m.GetMetricName(config)
 	if metricName == "" {
 		// Special handling for VWAP distance
@@ -91,6 +133,49 @@ func (m *MetricMapper) GetMetricValue(config *models.ToplistConfig, metrics map[
 	return 0, false
 }
 
+// getCompoundValue returns the combined strength of a compound toplist's metric: the sum of
+// its values across the windows. Symbols missing a window or not exceeding every window's
+// threshold are left out of the ranking.
+func (m *MetricMapper) getCompoundValue(config *models.ToplistConfig, metrics map[string]float64) (float64, bool) {
+	windowConfig := *config
+	windowConfig.CompoundWindows = nil
+
+	var combined float64
+	for _, w := range config.CompoundWindows {
+		windowConfig.TimeWindow = w.Window
+		value, ok := m.GetMetricValue(&windowConfig, metrics)
+		if !ok {
+			return 0, false
+		}
+		if config.SortOrder == models.SortOrderAsc {
+			if value >= w.Threshold {
+				return 0, false
+			}
+		} else if value <= w.Threshold {
+			return 0, false
+		}
+		combined += value
+	}
+	return combined, true
+}
+
+// getChangePointsMetricName returns the absolute (dollar) price change metric for a window
+func (m *MetricMapper) getChangePointsMetricName(window models.ToplistTimeWindow) string {
+	switch window {
+	case models.Window1m:
+		return "change_1m"
+	case models.Window5m:
+		return "change_5m"
+	case models.Window15m:
+		return "change_15m"
+	case models.Window1h:
+		return "change_60m"
+	case models.Window1d:
+		return "change_from_close"
+	}
+	return ""
+}
+
 // getVWAPDistance calculates VWAP distance from metrics
 func (m *MetricMapper) getVWAPDistance(config *models.ToplistConfig, metrics map[string]float64) (float64, bool) {
 	var vwapKey string
@@ -118,9 +203,6 @@ func (m *MetricMapper) getVWAPDistance(config *models.ToplistConfig, metrics map
 
 // GetToplistRedisKey returns the Redis key for a toplist config
 func (m *MetricMapper) GetToplistRedisKey(config *models.ToplistConfig) string {
-	if config.IsSystemToplist() {
-		return models.GetSystemToplistRedisKey(config.Metric, config.TimeWindow)
-	}
-	return models.GetUserToplistRedisKey(config.UserID, config.ID)
+	return config.RedisKey()
 }
 
@@ -0,0 +1,55 @@
+package toplist
+
+import (
+	"testing"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+func TestMetricMapper_CompoundWindows(t *testing.T) {
+	mapper := NewMetricMapper()
+	config := &models.ToplistConfig{
+		ID:         "sustained-gainers",
+		UserID:     "user-1",
+		Metric:     models.MetricChangePct,
+		TimeWindow: models.Window1m,
+		SortOrder:  models.SortOrderDesc,
+		CompoundWindows: []models.ToplistWindowThreshold{
+			{Window: models.Window1m, Threshold: 1},
+			{Window: models.Window5m, Threshold: 2},
+		},
+	}
+
+	// Up over both windows: ranked by the combined change
+	sustained := map[string]float64{"price_change_1m_pct": 1.5, "price_change_5m_pct": 3}
+	if got, ok := mapper.GetMetricValue(config, sustained); !ok || got != 4.5 {
+		t.Errorf("GetMetricValue(sustained) = %v, %v, want 4.5, true", got, ok)
+	}
+
+	// A 1m spike alone is excluded
+	spike := map[string]float64{"price_change_1m_pct": 5, "price_change_5m_pct": 0.5}
+	if _, ok := mapper.GetMetricValue(config, spike); ok {
+		t.Error("Expected a symbol passing only the 1m window to be excluded")
+	}
+
+	// So is a symbol missing a window
+	partial := map[string]float64{"price_change_1m_pct": 5}
+	if _, ok := mapper.GetMetricValue(config, partial); ok {
+		t.Error("Expected a symbol missing the 5m window to be excluded")
+	}
+
+	// Ascending toplists require values below the thresholds
+	losers := *config
+	losers.SortOrder = models.SortOrderAsc
+	losers.CompoundWindows = []models.ToplistWindowThreshold{
+		{Window: models.Window1m, Threshold: -1},
+		{Window: models.Window5m, Threshold: -2},
+	}
+	falling := map[string]float64{"price_change_1m_pct": -1.5, "price_change_5m_pct": -3}
+	if got, ok := mapper.GetMetricValue(&losers, falling); !ok || got != -4.5 {
+		t.Errorf("GetMetricValue(falling) = %v, %v, want -4.5, true", got, ok)
+	}
+	if _, ok := mapper.GetMetricValue(&losers, sustained); ok {
+		t.Error("Expected a rising symbol to be excluded from sustained losers")
+	}
+}
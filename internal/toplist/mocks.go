@@ -42,14 +42,9 @@ func (m *MockToplistStore) GetEnabledToplists(ctx context.Context, userID string
 		if !config.Enabled {
 			continue
 		}
-		if userID == "" {
-			if config.UserID == "" {
-				result = append(result, config)
-			}
-		} else {
-			if config.UserID == userID {
-				result = append(result, config)
-			}
+		// Like the database store, an empty userID returns every enabled toplist
+		if userID == "" || config.UserID == userID {
+			result = append(result, config)
 		}
 	}
 	return result, nil
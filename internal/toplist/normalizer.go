@@ -0,0 +1,138 @@
+package toplist
+
+import (
+	"math"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// DefaultNormalizationWindow is the number of recent values per symbol used for normalization
+const DefaultNormalizationWindow = 300
+
+// NormalizationHistoryTTL is how long a symbol's history is kept without new values. It spans
+// a long weekend, so symbols keep their history between trading days.
+const NormalizationHistoryTTL = 96 * time.Hour
+
+// Normalizer normalizes each symbol's metric values against that symbol's own recent values,
+// so toplists rank how unusual a value is for the symbol rather than its raw size.
+// Not safe for concurrent use.
+type Normalizer struct {
+	window  int
+	history map[string]map[string]*symbolHistory // Toplist key -> symbol -> recent values
+	now     func() time.Time
+}
+
+// symbolHistory holds a symbol's recent values for one toplist
+type symbolHistory struct {
+	values  []float64 // Oldest first
+	updated time.Time // When the last value was recorded
+}
+
+// NewNormalizer creates a normalizer keeping up to window recent values per symbol and toplist
+func NewNormalizer(window int) *Normalizer {
+	if window < 2 {
+		window = DefaultNormalizationWindow
+	}
+	return &Normalizer{
+		window:  window,
+		history: make(map[string]map[string]*symbolHistory),
+		now:     time.Now,
+	}
+}
+
+// Normalize returns value normalized against the symbol's previous values for the toplist key,
+// then records value. Returns false until enough history exists: two previous values for
+// z-scores, one for percentiles.
+func (n *Normalizer) Normalize(method models.ToplistNormalization, key, symbol string, value float64) (float64, bool) {
+	symbols, exists := n.history[key]
+	if !exists {
+		symbols = make(map[string]*symbolHistory)
+		n.history[key] = symbols
+	}
+	history, exists := symbols[symbol]
+	if !exists {
+		history = &symbolHistory{}
+		symbols[symbol] = history
+	}
+	previous := history.values
+
+	var normalized float64
+	ok := false
+	switch method {
+	case models.NormalizationZScore:
+		normalized, ok = zScore(previous, value)
+	case models.NormalizationPercentile:
+		normalized, ok = percentile(previous, value)
+	default:
+		normalized, ok = value, true
+	}
+
+	if len(previous) >= n.window {
+		previous = append(previous[:0], previous[len(previous)-n.window+1:]...)
+	}
+	history.values = append(previous, value)
+	history.updated = n.now()
+
+	return normalized, ok
+}
+
+// Prune drops the history of toplists not in keys and of symbols without a value recorded
+// since idleSince (e.g. delisted or no longer scanned)
+func (n *Normalizer) Prune(keys map[string]bool, idleSince time.Time) {
+	for key, symbols := range n.history {
+		if !keys[key] {
+			delete(n.history, key)
+			continue
+		}
+		for symbol, history := range symbols {
+			if history.updated.Before(idleSince) {
+				delete(symbols, symbol)
+			}
+		}
+		if len(symbols) == 0 {
+			delete(n.history, key)
+		}
+	}
+}
+
+// zScore returns how many standard deviations value is from the mean of previous.
+// A flat history yields 0.
+func zScore(previous []float64, value float64) (float64, bool) {
+	if len(previous) < 2 {
+		return 0, false
+	}
+
+	var sum float64
+	for _, v := range previous {
+		sum += v
+	}
+	mean := sum / float64(len(previous))
+
+	var variance float64
+	for _, v := range previous {
+		variance += (v - mean) * (v - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(previous)))
+	if stdDev == 0 {
+		return 0, true
+	}
+	return (value - mean) / stdDev, true
+}
+
+// percentile returns the percentage (0-100) of previous values below value, counting ties as half
+func percentile(previous []float64, value float64) (float64, bool) {
+	if len(previous) == 0 {
+		return 0, false
+	}
+
+	var below float64
+	for _, v := range previous {
+		if v < value {
+			below++
+		} else if v == value {
+			below += 0.5
+		}
+	}
+	return below / float64(len(previous)) * 100, true
+}
@@ -0,0 +1,101 @@
+package toplist
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+func TestNormalizer_ZScore(t *testing.T) {
+	n := NewNormalizer(10)
+
+	// Needs two previous values before a z-score is available
+	for i, value := range []float64{1, 3} {
+		if _, ok := n.Normalize(models.NormalizationZScore, "key", "AAPL", value); ok {
+			t.Errorf("Value %d: expected no z-score during warmup", i)
+		}
+	}
+
+	// History {1, 3}: mean 2, population std dev 1
+	got, ok := n.Normalize(models.NormalizationZScore, "key", "AAPL", 5)
+	if !ok || got != 3 {
+		t.Errorf("Normalize() = %v, %v, want 3, true", got, ok)
+	}
+
+	// Other symbols and toplists keep separate history
+	if _, ok := n.Normalize(models.NormalizationZScore, "key", "MSFT", 5); ok {
+		t.Error("Expected MSFT to have no history")
+	}
+	if _, ok := n.Normalize(models.NormalizationZScore, "other", "AAPL", 5); ok {
+		t.Error("Expected AAPL to have no history for another toplist")
+	}
+}
+
+func TestNormalizer_ZScoreFlatHistory(t *testing.T) {
+	n := NewNormalizer(10)
+	n.Normalize(models.NormalizationZScore, "key", "AAPL", 2)
+	n.Normalize(models.NormalizationZScore, "key", "AAPL", 2)
+
+	got, ok := n.Normalize(models.NormalizationZScore, "key", "AAPL", 4)
+	if !ok || got != 0 || math.IsNaN(got) {
+		t.Errorf("Normalize() = %v, %v, want 0 for a flat history", got, ok)
+	}
+}
+
+func TestNormalizer_Percentile(t *testing.T) {
+	n := NewNormalizer(10)
+	if _, ok := n.Normalize(models.NormalizationPercentile, "key", "AAPL", 10); ok {
+		t.Error("Expected no percentile without history")
+	}
+	for _, value := range []float64{20, 30, 40} {
+		n.Normalize(models.NormalizationPercentile, "key", "AAPL", value)
+	}
+
+	// History {10, 20, 30, 40}: 2 below and 1 tie counted as half
+	got, ok := n.Normalize(models.NormalizationPercentile, "key", "AAPL", 30)
+	if !ok || got != 62.5 {
+		t.Errorf("Normalize() = %v, %v, want 62.5, true", got, ok)
+	}
+}
+
+func TestNormalizer_Window(t *testing.T) {
+	n := NewNormalizer(2)
+	for _, value := range []float64{100, 1, 3} {
+		n.Normalize(models.NormalizationZScore, "key", "AAPL", value)
+	}
+
+	// The window keeps only {1, 3}: the early outlier no longer counts
+	got, ok := n.Normalize(models.NormalizationZScore, "key", "AAPL", 5)
+	if !ok || got != 3 {
+		t.Errorf("Normalize() = %v, %v, want 3, true", got, ok)
+	}
+	if values := n.history["key"]["AAPL"].values; len(values) != 2 {
+		t.Errorf("Expected history bounded to 2 values, got %v", values)
+	}
+}
+
+func TestNormalizer_Prune(t *testing.T) {
+	n := NewNormalizer(10)
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	now := start
+	n.now = func() time.Time { return now }
+
+	n.Normalize(models.NormalizationZScore, "key", "IDLE", 1)
+	n.Normalize(models.NormalizationZScore, "removed", "AAPL", 1)
+	now = start.Add(time.Hour)
+	n.Normalize(models.NormalizationZScore, "key", "AAPL", 1)
+
+	n.Prune(map[string]bool{"key": true}, start.Add(time.Minute))
+
+	if _, ok := n.history["removed"]; ok {
+		t.Error("Expected history of a removed toplist to be dropped")
+	}
+	if _, ok := n.history["key"]["IDLE"]; ok {
+		t.Error("Expected history of an idle symbol to be dropped")
+	}
+	if _, ok := n.history["key"]["AAPL"]; !ok {
+		t.Error("Expected history of an active symbol to be kept")
+	}
+}
@@ -3,6 +3,7 @@ package toplist
 import (
 	"context"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
@@ -15,30 +16,71 @@ const (
 	ToplistUpdateChannel = "toplists.updated"
 	// DefaultToplistTTL is the default TTL for toplist ZSET keys (5 minutes)
 	DefaultToplistTTL = 5 * time.Minute
+	// DefaultToplistMaxSize is the default number of entries kept in each toplist ZSET
+	DefaultToplistMaxSize = 500
 )
 
 // RedisToplistUpdater implements ToplistUpdater using Redis ZSETs
 type RedisToplistUpdater struct {
-	redisClient storage.RedisClient
+	redisClient    storage.RedisClient
+	defaultMaxSize int
+	evictAfter     time.Duration    // Members not updated within this window are evicted (0 = disabled)
+	now            func() time.Time // Clock, replaceable in tests
 }
 
 // NewRedisToplistUpdater creates a new Redis-based toplist updater
 func NewRedisToplistUpdater(redisClient storage.RedisClient) *RedisToplistUpdater {
 	return &RedisToplistUpdater{
-		redisClient: redisClient,
+		redisClient:    redisClient,
+		defaultMaxSize: DefaultToplistMaxSize,
+		now:            time.Now,
 	}
 }
 
+// SetDefaultMaxSize sets the max size applied to toplists that don't configure their own (0 = unbounded)
+func (r *RedisToplistUpdater) SetDefaultMaxSize(maxSize int) {
+	if maxSize < 0 {
+		maxSize = 0
+	}
+	r.defaultMaxSize = maxSize
+}
+
+// SetEvictionWindow evicts toplist members that haven't been updated within window (0 = disabled).
+// Last-update times are tracked per member in a ZSET alongside each toplist, scored by time.
+func (r *RedisToplistUpdater) SetEvictionWindow(window time.Duration) {
+	if window < 0 {
+		window = 0
+	}
+	r.evictAfter = window
+}
+
+// lastUpdateKey returns the ZSET key tracking member update times (Unix millis) for a toplist ZSET
+func lastUpdateKey(key string) string {
+	return key + ":updated_at"
+}
+
 // UpdateSystemToplist updates a system toplist
 func (r *RedisToplistUpdater) UpdateSystemToplist(ctx context.Context, metric models.ToplistMetric, window models.ToplistTimeWindow, symbol string, value float64) error {
 	key := models.GetSystemToplistRedisKey(metric, window)
-	return r.updateZSet(ctx, key, symbol, value)
+	if err := r.updateZSet(ctx, key, symbol, value); err != nil {
+		return err
+	}
+	if err := r.evictStale(ctx, key, []string{symbol}); err != nil {
+		return err
+	}
+	return r.trim(ctx, key, trimLimit{maxSize: r.defaultMaxSize, keepHighest: true})
 }
 
 // UpdateUserToplist updates a user-custom toplist
 func (r *RedisToplistUpdater) UpdateUserToplist(ctx context.Context, userID string, toplistID string, symbol string, value float64) error {
 	key := models.GetUserToplistRedisKey(userID, toplistID)
-	return r.updateZSet(ctx, key, symbol, value)
+	if err := r.updateZSet(ctx, key, symbol, value); err != nil {
+		return err
+	}
+	if err := r.evictStale(ctx, key, []string{symbol}); err != nil {
+		return err
+	}
+	return r.trim(ctx, key, trimLimit{maxSize: r.defaultMaxSize, keepHighest: true})
 }
 
 // updateZSet updates a Redis ZSET with a new score for a member
@@ -64,11 +106,14 @@ func (r *RedisToplistUpdater) BatchUpdate(ctx context.Context, updates []Toplist
 
 	// Group updates by key for efficient batching
 	updatesByKey := make(map[string]map[string]float64)
+	limitsByKey := make(map[string]*trimLimit)
 	for _, update := range updates {
 		if updatesByKey[update.Key] == nil {
 			updatesByKey[update.Key] = make(map[string]float64)
+			limitsByKey[update.Key] = &trimLimit{}
 		}
 		updatesByKey[update.Key][update.Symbol] = update.Value
+		limitsByKey[update.Key].add(r.maxSizeFor(update.MaxSize), update.SortOrder)
 	}
 
 	// Perform batch updates for each key
@@ -81,6 +126,119 @@ func (r *RedisToplistUpdater) BatchUpdate(ctx context.Context, updates []Toplist
 			// Continue with other keys even if one fails
 			continue
 		}
+
+		symbols := make([]string, 0, len(members))
+		for symbol := range members {
+			symbols = append(symbols, symbol)
+		}
+		if err := r.evictStale(ctx, key, symbols); err != nil {
+			logger.Warn("Failed to evict stale toplist entries",
+				logger.ErrorField(err),
+				logger.String("key", key),
+			)
+		}
+
+		if err := r.trim(ctx, key, *limitsByKey[key]); err != nil {
+			logger.Warn("Failed to trim toplist",
+				logger.ErrorField(err),
+				logger.String("key", key),
+			)
+		}
+	}
+
+	return nil
+}
+
+// evictStale records the update time of the given members and removes members
+// whose last update is older than the eviction window. Only the updated and the stale
+// members are touched, so the cost does not grow with the size of the toplist.
+func (r *RedisToplistUpdater) evictStale(ctx context.Context, key string, updated []string) error {
+	if r.evictAfter <= 0 {
+		return nil
+	}
+
+	timesKey := lastUpdateKey(key)
+	now := r.now()
+
+	stamps := make(map[string]float64, len(updated))
+	nowMillis := float64(now.UnixMilli())
+	for _, symbol := range updated {
+		stamps[symbol] = nowMillis
+	}
+	if err := r.redisClient.ZAddBatch(ctx, timesKey, stamps); err != nil {
+		return fmt.Errorf("failed to record toplist update times %s: %w", timesKey, err)
+	}
+
+	cutoff := float64(now.Add(-r.evictAfter).UnixMilli())
+	stale, err := r.redisClient.ZRangeByScore(ctx, timesKey, math.Inf(-1), cutoff-1)
+	if err != nil {
+		return fmt.Errorf("failed to read stale toplist members %s: %w", timesKey, err)
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	if err := r.redisClient.ZRem(ctx, key, stale...); err != nil {
+		return fmt.Errorf("failed to evict stale entries from ZSET %s: %w", key, err)
+	}
+	if err := r.redisClient.ZRem(ctx, timesKey, stale...); err != nil {
+		return fmt.Errorf("failed to clear toplist update times %s: %w", timesKey, err)
+	}
+
+	return nil
+}
+
+// trimLimit describes how a toplist ZSET is trimmed. A key shared by toplists with
+// different sort orders (e.g. gainers and losers) keeps entries at both ends.
+type trimLimit struct {
+	maxSize     int
+	keepHighest bool
+	keepLowest  bool
+}
+
+// add merges a toplist's max size and sort order into the limit
+func (l *trimLimit) add(maxSize int, sortOrder models.ToplistSortOrder) {
+	if maxSize <= 0 {
+		// An unbounded toplist on the key disables trimming for it
+		l.maxSize = -1
+	} else if l.maxSize >= 0 && maxSize > l.maxSize {
+		l.maxSize = maxSize
+	}
+	if sortOrder == models.SortOrderAsc {
+		l.keepLowest = true
+	} else {
+		l.keepHighest = true
+	}
+}
+
+// maxSizeFor returns the effective max size for a toplist (0 = unbounded)
+func (r *RedisToplistUpdater) maxSizeFor(maxSize int) int {
+	if maxSize > 0 {
+		return maxSize
+	}
+	return r.defaultMaxSize
+}
+
+// trim removes entries beyond the max size, keeping the top entries for each sort order
+func (r *RedisToplistUpdater) trim(ctx context.Context, key string, limit trimLimit) error {
+	if limit.maxSize <= 0 {
+		return nil
+	}
+
+	// ZREMRANGEBYRANK ranks are ascending by score, negative ranks count from the highest
+	n := int64(limit.maxSize)
+	var start, stop int64
+	switch {
+	case limit.keepHighest && limit.keepLowest:
+		start, stop = n, -n-1
+	case limit.keepLowest:
+		start, stop = n, -1
+	default:
+		start, stop = 0, -n-1
+	}
+
+	if _, err := r.redisClient.ZRemRangeByRank(ctx, key, start, stop); err != nil {
+		return fmt.Errorf("failed to trim ZSET %s: %w", key, err)
 	}
 
 	return nil
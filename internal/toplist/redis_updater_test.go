@@ -2,7 +2,9 @@ package toplist
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
 	"github.com/mohamedkhairy/stock-scanner/internal/storage"
@@ -94,6 +96,201 @@ func TestRedisToplistUpdater_BatchUpdate_Empty(t *testing.T) {
 	}
 }
 
+func TestRedisToplistUpdater_BatchUpdate_TrimsToMaxSize(t *testing.T) {
+	mockRedis := storage.NewMockRedisClient()
+	updater := NewRedisToplistUpdater(mockRedis)
+	ctx := context.Background()
+
+	key := "toplist:volume:1d"
+	for round := 0; round < 5; round++ {
+		updates := make([]ToplistUpdate, 0, 50)
+		for i := 0; i < 50; i++ {
+			updates = append(updates, ToplistUpdate{
+				Key:       key,
+				Symbol:    fmt.Sprintf("SYM%03d", round*50+i),
+				Value:     float64(round*50 + i),
+				MaxSize:   10,
+				SortOrder: models.SortOrderDesc,
+			})
+		}
+		if err := updater.BatchUpdate(ctx, updates); err != nil {
+			t.Fatalf("BatchUpdate() error = %v", err)
+		}
+	}
+
+	count, _ := mockRedis.ZCard(ctx, key)
+	if count != 10 {
+		t.Fatalf("ZCard() = %d, want 10", count)
+	}
+
+	// The 10 highest scores (240-249) should remain
+	members, _ := mockRedis.ZRevRange(ctx, key, 0, -1)
+	for i, member := range members {
+		want := fmt.Sprintf("SYM%03d", 249-i)
+		if member.Member != want {
+			t.Errorf("rank %d = %s, want %s", i, member.Member, want)
+		}
+	}
+}
+
+func TestRedisToplistUpdater_BatchUpdate_TrimsAscending(t *testing.T) {
+	mockRedis := storage.NewMockRedisClient()
+	updater := NewRedisToplistUpdater(mockRedis)
+	ctx := context.Background()
+
+	key := "toplist:user:user-123:losers"
+	updates := make([]ToplistUpdate, 0, 100)
+	for i := 0; i < 100; i++ {
+		updates = append(updates, ToplistUpdate{
+			Key:       key,
+			Symbol:    fmt.Sprintf("SYM%03d", i),
+			Value:     float64(i) - 50,
+			MaxSize:   5,
+			SortOrder: models.SortOrderAsc,
+		})
+	}
+	if err := updater.BatchUpdate(ctx, updates); err != nil {
+		t.Fatalf("BatchUpdate() error = %v", err)
+	}
+
+	count, _ := mockRedis.ZCard(ctx, key)
+	if count != 5 {
+		t.Fatalf("ZCard() = %d, want 5", count)
+	}
+
+	// The 5 lowest scores should remain
+	for i := 0; i < 5; i++ {
+		symbol := fmt.Sprintf("SYM%03d", i)
+		if _, exists := mockRedis.ZSets[key][symbol]; !exists {
+			t.Errorf("expected %s to remain in ascending toplist", symbol)
+		}
+	}
+}
+
+func TestRedisToplistUpdater_BatchUpdate_SharedKeyKeepsBothEnds(t *testing.T) {
+	mockRedis := storage.NewMockRedisClient()
+	updater := NewRedisToplistUpdater(mockRedis)
+	ctx := context.Background()
+
+	// Gainers and losers share the same system ZSET
+	key := models.GetSystemToplistRedisKey(models.MetricChangePct, models.Window1m)
+	updates := make([]ToplistUpdate, 0, 60)
+	for i := 0; i < 30; i++ {
+		symbol := fmt.Sprintf("SYM%03d", i)
+		value := float64(i) - 15
+		updates = append(updates,
+			ToplistUpdate{Key: key, Symbol: symbol, Value: value, MaxSize: 3, SortOrder: models.SortOrderDesc},
+			ToplistUpdate{Key: key, Symbol: symbol, Value: value, MaxSize: 3, SortOrder: models.SortOrderAsc},
+		)
+	}
+	if err := updater.BatchUpdate(ctx, updates); err != nil {
+		t.Fatalf("BatchUpdate() error = %v", err)
+	}
+
+	count, _ := mockRedis.ZCard(ctx, key)
+	if count != 6 {
+		t.Fatalf("ZCard() = %d, want 6", count)
+	}
+
+	for _, symbol := range []string{"SYM000", "SYM001", "SYM002", "SYM027", "SYM028", "SYM029"} {
+		if _, exists := mockRedis.ZSets[key][symbol]; !exists {
+			t.Errorf("expected %s to remain in shared toplist", symbol)
+		}
+	}
+}
+
+func TestRedisToplistUpdater_DefaultMaxSize(t *testing.T) {
+	mockRedis := storage.NewMockRedisClient()
+	updater := NewRedisToplistUpdater(mockRedis)
+	updater.SetDefaultMaxSize(3)
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		err := updater.UpdateSystemToplist(ctx, models.MetricVolume, models.Window1m, fmt.Sprintf("SYM%d", i), float64(i))
+		if err != nil {
+			t.Fatalf("UpdateSystemToplist() error = %v", err)
+		}
+	}
+
+	key := models.GetSystemToplistRedisKey(models.MetricVolume, models.Window1m)
+	count, _ := mockRedis.ZCard(ctx, key)
+	if count != 3 {
+		t.Errorf("ZCard() = %d, want 3", count)
+	}
+
+	// Unbounded when disabled
+	updater.SetDefaultMaxSize(0)
+	for i := 10; i < 20; i++ {
+		updater.UpdateSystemToplist(ctx, models.MetricVolume, models.Window1m, fmt.Sprintf("SYM%d", i), float64(i))
+	}
+	count, _ = mockRedis.ZCard(ctx, key)
+	if count != 13 {
+		t.Errorf("ZCard() = %d, want 13", count)
+	}
+}
+
+func TestRedisToplistUpdater_EvictsStaleMembers(t *testing.T) {
+	mockRedis := storage.NewMockRedisClient()
+	updater := NewRedisToplistUpdater(mockRedis)
+	updater.SetEvictionWindow(5 * time.Minute)
+	ctx := context.Background()
+
+	now := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+	updater.now = func() time.Time { return now }
+
+	key := models.GetSystemToplistRedisKey(models.MetricChangePct, models.Window5m)
+	err := updater.BatchUpdate(ctx, []ToplistUpdate{
+		{Key: key, Symbol: "AAPL", Value: 9.5},
+		{Key: key, Symbol: "MSFT", Value: 3.0},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpdate() error = %v", err)
+	}
+
+	// Within the window only MSFT updates; AAPL keeps its (now older) score
+	now = now.Add(4 * time.Minute)
+	if err := updater.BatchUpdate(ctx, []ToplistUpdate{{Key: key, Symbol: "MSFT", Value: 3.5}}); err != nil {
+		t.Fatalf("BatchUpdate() error = %v", err)
+	}
+	if count, _ := mockRedis.ZCard(ctx, key); count != 2 {
+		t.Fatalf("ZCard() = %d before the window elapsed, want 2", count)
+	}
+
+	// AAPL hasn't updated for 6 minutes and is evicted, fresh members remain
+	now = now.Add(2 * time.Minute)
+	if err := updater.BatchUpdate(ctx, []ToplistUpdate{{Key: key, Symbol: "TSLA", Value: 1.0}}); err != nil {
+		t.Fatalf("BatchUpdate() error = %v", err)
+	}
+
+	members, _ := mockRedis.ZRevRange(ctx, key, 0, -1)
+	got := make([]string, 0, len(members))
+	for _, m := range members {
+		got = append(got, m.Member)
+	}
+	want := []string{"MSFT", "TSLA"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("members after eviction = %v, want %v", got, want)
+	}
+	if _, tracked := mockRedis.ZSets[lastUpdateKey(key)]["AAPL"]; tracked {
+		t.Error("evicted member still tracked in update-time ZSET")
+	}
+}
+
+func TestRedisToplistUpdater_EvictionDisabledByDefault(t *testing.T) {
+	mockRedis := storage.NewMockRedisClient()
+	updater := NewRedisToplistUpdater(mockRedis)
+	ctx := context.Background()
+
+	if err := updater.UpdateSystemToplist(ctx, models.MetricVolume, models.Window1d, "AAPL", 1000); err != nil {
+		t.Fatalf("UpdateSystemToplist() error = %v", err)
+	}
+
+	key := models.GetSystemToplistRedisKey(models.MetricVolume, models.Window1d)
+	if len(mockRedis.ZSets[lastUpdateKey(key)]) != 0 {
+		t.Error("update times tracked with eviction disabled")
+	}
+}
+
 func TestRedisToplistUpdater_PublishUpdate(t *testing.T) {
 	mockRedis := storage.NewMockRedisClient()
 	updater := NewRedisToplistUpdater(mockRedis)
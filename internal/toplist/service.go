@@ -2,7 +2,9 @@ package toplist
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
 	"github.com/mohamedkhairy/stock-scanner/internal/storage"
@@ -39,12 +41,7 @@ func (s *ToplistService) GetToplistRankings(ctx context.Context, toplistID strin
 // GetRankingsByConfig retrieves rankings using a config directly (for system toplists)
 func (s *ToplistService) GetRankingsByConfig(ctx context.Context, config *models.ToplistConfig, limit, offset int, filters *models.ToplistFilter) ([]models.ToplistRanking, error) {
 	// Determine Redis key
-	var redisKey string
-	if config.IsSystemToplist() {
-		redisKey = models.GetSystemToplistRedisKey(config.Metric, config.TimeWindow)
-	} else {
-		redisKey = models.GetUserToplistRedisKey(config.UserID, config.ID)
-	}
+	redisKey := config.RedisKey()
 
 	// Get rankings from Redis ZSET
 	// For descending order, use ZRevRange (highest to lowest)
@@ -55,7 +52,7 @@ func (s *ToplistService) GetRankingsByConfig(ctx context.Context, config *models
 		stop = 0
 	}
 
-	members, err := s.redisClient.ZRevRange(ctx, redisKey, start, stop)
+	members, err := s.fetchMembers(ctx, config, redisKey, start, stop)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get rankings from Redis: %w", err)
 	}
@@ -97,6 +94,139 @@ func (s *ToplistService) GetRankingsByConfig(ctx context.Context, config *models
 	return rankings, nil
 }
 
+// fetchMembers returns the ZSET members for ranks start..stop (highest score first).
+// When the config has tie breakers, members tied with the page are fetched too so that
+// groups straddling a page boundary are ordered consistently across pages.
+func (s *ToplistService) fetchMembers(ctx context.Context, config *models.ToplistConfig, redisKey string, start, stop int64) ([]storage.ZSetMember, error) {
+	if len(config.TieBreakers) == 0 {
+		return s.redisClient.ZRevRange(ctx, redisKey, start, stop)
+	}
+
+	members, err := s.redisClient.ZRevRange(ctx, redisKey, 0, stop)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extend through the trailing tie group, one batch at a time
+	batch := stop - start + 1
+	if batch < 1 {
+		batch = 1
+	}
+	for fetched := stop; int64(len(members)) == fetched+1 && len(members) > 0; fetched += batch {
+		next, err := s.redisClient.ZRevRange(ctx, redisKey, fetched+1, fetched+batch)
+		if err != nil {
+			return nil, err
+		}
+		last := members[len(members)-1].Score
+		tied := 0
+		for tied < len(next) && next[tied].Score == last {
+			tied++
+		}
+		members = append(members, next[:tied]...)
+		if tied < len(next) || len(next) == 0 {
+			break
+		}
+	}
+
+	s.breakTies(ctx, config, members)
+
+	if start >= int64(len(members)) {
+		return []storage.ZSetMember{}, nil
+	}
+	end := stop + 1
+	if end > int64(len(members)) {
+		end = int64(len(members))
+	}
+	return members[start:end], nil
+}
+
+// breakTies reorders members with equal scores by the config's tie breakers.
+// Ascending toplists reverse the page afterwards, so their tie order is inverted here.
+func (s *ToplistService) breakTies(ctx context.Context, config *models.ToplistConfig, members []storage.ZSetMember) {
+	// Fetch secondary metrics only for members that share a score with a neighbour
+	var tied []string
+	for i, member := range members {
+		if (i > 0 && members[i-1].Score == member.Score) ||
+			(i+1 < len(members) && members[i+1].Score == member.Score) {
+			tied = append(tied, member.Member)
+		}
+	}
+	if len(tied) == 0 {
+		return
+	}
+
+	// One ZMSCORE per tie breaker
+	secondary := make(map[models.ToplistTieBreaker]map[string]float64)
+	for _, tb := range config.TieBreakers {
+		if tb == models.TieBreakSymbol {
+			continue
+		}
+		key := models.GetSystemToplistRedisKey(models.ToplistMetric(tb), config.TimeWindow)
+		scores, err := s.redisClient.ZMScore(ctx, key, tied...)
+		if err != nil {
+			logger.Debug("Failed to fetch tie breaker values",
+				logger.String("tie_breaker", string(tb)),
+				logger.Int("symbols", len(tied)),
+				logger.ErrorField(err),
+			)
+			continue
+		}
+		values := make(map[string]float64, len(tied))
+		for i, symbol := range tied {
+			values[symbol] = scores[i]
+		}
+		secondary[tb] = values
+	}
+
+	ascending := config.SortOrder == models.SortOrderAsc
+	sort.SliceStable(members, func(i, j int) bool {
+		a, b := members[i], members[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		for _, tb := range config.TieBreakers {
+			var less, greater bool
+			if tb == models.TieBreakSymbol {
+				less, greater = a.Member < b.Member, a.Member > b.Member
+			} else {
+				va, vb := secondary[tb][a.Member], secondary[tb][b.Member]
+				less, greater = va > vb, va < vb
+			}
+			if less || greater {
+				return less != ascending
+			}
+		}
+		return false
+	})
+}
+
+// ErrPreviewUnsupported is returned when a config has no live ZSET to preview from
+var ErrPreviewUnsupported = errors.New("toplist preview is not supported for custom metrics")
+
+// PreviewRankings computes rankings for an unsaved config from the live system ZSETs.
+// A user toplist has no ZSET of its own until it is persisted, so the preview reads the
+// system toplist with the same metric, change unit and time window.
+func (s *ToplistService) PreviewRankings(ctx context.Context, config *models.ToplistConfig, limit int) ([]models.ToplistRanking, int64, error) {
+	if config.Metric == models.MetricCustom {
+		return nil, 0, ErrPreviewUnsupported
+	}
+
+	preview := *config
+	preview.UserID = ""
+
+	rankings, err := s.GetRankingsByConfig(ctx, &preview, limit, 0, config.Filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.GetCountByConfig(ctx, &preview)
+	if err != nil {
+		total = int64(len(rankings))
+	}
+
+	return rankings, total, nil
+}
+
 // GetToplistCount returns the total number of symbols in a toplist
 func (s *ToplistService) GetToplistCount(ctx context.Context, toplistID string) (int64, error) {
 	// Get toplist configuration
@@ -111,12 +241,7 @@ func (s *ToplistService) GetToplistCount(ctx context.Context, toplistID string)
 // GetCountByConfig returns the count using a config directly (for system toplists)
 func (s *ToplistService) GetCountByConfig(ctx context.Context, config *models.ToplistConfig) (int64, error) {
 	// Determine Redis key
-	var redisKey string
-	if config.IsSystemToplist() {
-		redisKey = models.GetSystemToplistRedisKey(config.Metric, config.TimeWindow)
-	} else {
-		redisKey = models.GetUserToplistRedisKey(config.UserID, config.ID)
-	}
+	redisKey := config.RedisKey()
 
 	// Get count from Redis
 	count, err := s.redisClient.ZCard(ctx, redisKey)
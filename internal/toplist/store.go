@@ -13,7 +13,7 @@ type ToplistStore interface {
 	// GetUserToplists retrieves all toplists for a user
 	GetUserToplists(ctx context.Context, userID string) ([]*models.ToplistConfig, error)
 
-	// GetEnabledToplists retrieves all enabled toplists (for a user or all system toplists)
+	// GetEnabledToplists retrieves all enabled toplists for a user (or all enabled toplists, system and user, if userID is empty)
 	GetEnabledToplists(ctx context.Context, userID string) ([]*models.ToplistConfig, error)
 
 	// CreateToplist creates a new toplist configuration
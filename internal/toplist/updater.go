@@ -7,9 +7,11 @@ import (
 
 // ToplistUpdate represents a single toplist update operation
 type ToplistUpdate struct {
-	Key    string  // Redis key for the toplist
-	Symbol string  // Symbol to update
-	Value  float64 // Metric value for ranking
+	Key       string                  // Redis key for the toplist
+	Symbol    string                  // Symbol to update
+	Value     float64                 // Metric value for ranking
+	MaxSize   int                     // Max entries to keep for this toplist (0 = updater default)
+	SortOrder models.ToplistSortOrder // Determines which end of the ZSET is kept when trimming
 }
 
 // ToplistUpdater defines the interface for updating toplists
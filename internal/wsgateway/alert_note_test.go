@@ -0,0 +1,70 @@
+package wsgateway
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+func TestConnection_SubscribeAlert(t *testing.T) {
+	conn := NewConnection("test-conn", "user-123", nil)
+
+	conn.SubscribeAlert("alert-1")
+	if !conn.IsSubscribedToAlert("alert-1") {
+		t.Error("SubscribeAlert() failed - alert not subscribed")
+	}
+
+	conn.UnsubscribeAlert("alert-1")
+	if conn.IsSubscribedToAlert("alert-1") {
+		t.Error("UnsubscribeAlert() failed - alert still subscribed")
+	}
+}
+
+func TestHub_BroadcastAlertNote(t *testing.T) {
+	hub := NewHub(config.WSGatewayConfig{}, storage.NewMockRedisClient(), "alerts.filtered", "ws-gateway")
+
+	subscribed := NewConnection("conn-1", "user-123", nil)
+	other := NewConnection("conn-2", "user-456", nil)
+	subscribed.SubscribeAlert("alert-1")
+	other.SubscribeAlert("alert-2")
+	hub.registry.Add(subscribed)
+	hub.registry.Add(other)
+
+	note := &models.AlertNote{
+		ID:        "note-1",
+		AlertID:   "alert-1",
+		Author:    "user-789",
+		Text:      "Looking into this",
+		CreatedAt: time.Now(),
+	}
+	hub.broadcastAlertNote(note)
+
+	select {
+	case data := <-subscribed.Send:
+		var message struct {
+			Type string           `json:"type"`
+			Data models.AlertNote `json:"data"`
+		}
+		if err := json.Unmarshal(data, &message); err != nil {
+			t.Fatalf("Failed to unmarshal message: %v", err)
+		}
+		if message.Type != "alert_note" {
+			t.Errorf("Expected message type alert_note, got %s", message.Type)
+		}
+		if message.Data.ID != "note-1" || message.Data.Text != "Looking into this" {
+			t.Errorf("Unexpected note payload: %+v", message.Data)
+		}
+	default:
+		t.Fatal("Expected subscribed connection to receive the note")
+	}
+
+	select {
+	case <-other.Send:
+		t.Error("Connection subscribed to a different alert should not receive the note")
+	default:
+	}
+}
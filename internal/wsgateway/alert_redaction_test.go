@@ -0,0 +1,119 @@
+package wsgateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+func TestHub_BroadcastAlert_RedactsByScope(t *testing.T) {
+	hub := NewHub(config.WSGatewayConfig{}, storage.NewMockRedisClient(), "alerts.filtered", "ws-gateway")
+	hub.SetAlertRedactor(models.NewAlertRedactor(nil))
+
+	privileged := NewConnection("conn-1", "user-admin", nil)
+	privileged.SetAlertScope(models.AlertScopeFull)
+	restricted := NewConnection("conn-2", "user-tenant", nil)
+	restricted.SetAlertScope(models.AlertScopeRestricted)
+	unscoped := NewConnection("conn-3", "user-other", nil)
+	hub.registry.Add(privileged)
+	hub.registry.Add(restricted)
+	hub.registry.Add(unscoped)
+
+	hub.broadcastAlert(&models.Alert{
+		ID:      "alert-1",
+		RuleID:  "rule-1",
+		Symbol:  "AAPL",
+		TraceID: "trace-1",
+		Metadata: map[string]interface{}{
+			"metrics":    map[string]interface{}{"price": 150.0},
+			"conditions": []interface{}{"price > 100"},
+			"alert_type": models.AlertTypeEntry,
+		},
+	})
+
+	full := receivedAlert(t, privileged)
+	if full == nil {
+		t.Fatal("Expected privileged connection to receive the alert")
+	}
+	if _, ok := full.Metadata["metrics"]; !ok {
+		t.Error("Expected privileged connection to receive metrics")
+	}
+	if _, ok := full.Metadata["conditions"]; !ok {
+		t.Error("Expected privileged connection to receive conditions")
+	}
+	if full.TraceID != "trace-1" {
+		t.Errorf("Expected privileged connection to receive trace ID, got %q", full.TraceID)
+	}
+
+	for _, conn := range []*Connection{restricted, unscoped} {
+		redacted := receivedAlert(t, conn)
+		if redacted == nil {
+			t.Fatalf("Expected %s to receive the alert", conn.UserID)
+		}
+		if _, ok := redacted.Metadata["metrics"]; ok {
+			t.Errorf("Expected metrics to be redacted for %s", conn.UserID)
+		}
+		if _, ok := redacted.Metadata["conditions"]; ok {
+			t.Errorf("Expected conditions to be redacted for %s", conn.UserID)
+		}
+		if redacted.TraceID != "" {
+			t.Errorf("Expected trace ID to be redacted for %s", conn.UserID)
+		}
+		if redacted.Metadata["alert_type"] != models.AlertTypeEntry || redacted.Symbol != "AAPL" {
+			t.Errorf("Expected non-sensitive fields to be delivered to %s", conn.UserID)
+		}
+	}
+}
+
+func TestHub_BroadcastAlert_NoRedactorDeliversFullMetadata(t *testing.T) {
+	hub := NewHub(config.WSGatewayConfig{}, storage.NewMockRedisClient(), "alerts.filtered", "ws-gateway")
+
+	conn := NewConnection("conn-1", "user-1", nil)
+	conn.SetAlertScope(models.AlertScopeRestricted)
+	hub.registry.Add(conn)
+
+	hub.broadcastAlert(&models.Alert{
+		ID:       "alert-1",
+		RuleID:   "rule-1",
+		Symbol:   "AAPL",
+		Metadata: map[string]interface{}{"metrics": map[string]interface{}{"price": 150.0}},
+	})
+
+	alert := receivedAlert(t, conn)
+	if alert == nil {
+		t.Fatal("Expected connection to receive the alert")
+	}
+	if _, ok := alert.Metadata["metrics"]; !ok {
+		t.Error("Expected metrics to be delivered without a redactor")
+	}
+}
+
+func TestAuthManager_ValidateTokenClaims_AlertScope(t *testing.T) {
+	secret := "test-secret-key"
+	authManager := NewAuthManager(secret)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":     "user-1",
+		"alert_scope": models.AlertScopeRestricted,
+		"exp":         time.Now().Add(time.Hour).Unix(),
+	})
+	tokenString, err := token.SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("Failed to create token: %v", err)
+	}
+
+	claims, err := authManager.ValidateTokenClaims(tokenString)
+	if err != nil {
+		t.Fatalf("Failed to validate token: %v", err)
+	}
+	if claims.UserID != "user-1" {
+		t.Errorf("Expected user ID user-1, got %s", claims.UserID)
+	}
+	if claims.AlertScope != models.AlertScopeRestricted {
+		t.Errorf("Expected alert scope %s, got %q", models.AlertScopeRestricted, claims.AlertScope)
+	}
+}
@@ -1,10 +1,19 @@
 package wsgateway
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
 )
 
 // AuthManager handles JWT authentication
@@ -19,12 +28,27 @@ func NewAuthManager(jwtSecret string) *AuthManager {
 	}
 }
 
+// TokenClaims holds the claims of a validated token used by the gateway
+type TokenClaims struct {
+	UserID     string
+	AlertScope string // Alert metadata scope from the "alert_scope" claim (empty if absent)
+}
+
 // ValidateToken validates a JWT token and returns the user ID
 func (a *AuthManager) ValidateToken(tokenString string) (string, error) {
+	claims, err := a.ValidateTokenClaims(tokenString)
+	if err != nil {
+		return "", err
+	}
+	return claims.UserID, nil
+}
+
+// ValidateTokenClaims validates a JWT token and returns its user ID and alert scope
+func (a *AuthManager) ValidateTokenClaims(tokenString string) (TokenClaims, error) {
 	if a.jwtSecret == nil || len(a.jwtSecret) == 0 {
 		// MVP: If no JWT secret is configured, allow all connections with default user
 		// In production, this should be required
-		return "default", nil
+		return TokenClaims{UserID: "default"}, nil
 	}
 
 	// Parse token
@@ -37,30 +61,32 @@ func (a *AuthManager) ValidateToken(tokenString string) (string, error) {
 	})
 
 	if err != nil {
-		return "", fmt.Errorf("failed to parse token: %w", err)
+		return TokenClaims{}, fmt.Errorf("failed to parse token: %w", err)
 	}
 
 	if !token.Valid {
-		return "", fmt.Errorf("invalid token")
+		return TokenClaims{}, fmt.Errorf("invalid token")
 	}
 
 	// Extract claims
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return "", fmt.Errorf("invalid token claims")
+		return TokenClaims{}, fmt.Errorf("invalid token claims")
 	}
 
+	alertScope, _ := claims["alert_scope"].(string)
+
 	// Extract user ID
 	userID, ok := claims["user_id"].(string)
 	if !ok {
 		// Try "sub" (subject) as fallback
 		if sub, ok := claims["sub"].(string); ok {
-			return sub, nil
+			return TokenClaims{UserID: sub, AlertScope: alertScope}, nil
 		}
-		return "", fmt.Errorf("user_id not found in token")
+		return TokenClaims{}, fmt.Errorf("user_id not found in token")
 	}
 
-	return userID, nil
+	return TokenClaims{UserID: userID, AlertScope: alertScope}, nil
 }
 
 // ExtractTokenFromHeader extracts JWT token from Authorization header
@@ -84,3 +110,209 @@ func (a *AuthManager) ExtractTokenFromHeader(authHeader string) (string, error)
 	return "", fmt.Errorf("invalid authorization header format")
 }
 
+
+// Auth failure reasons (values of the reason label on auth_failures_total)
+const (
+	AuthFailureExpired       = "expired"
+	AuthFailureMalformed     = "malformed"
+	AuthFailureBadSignature  = "bad_signature"
+	AuthFailureInvalidClaims = "invalid_claims"
+	AuthFailureRateLimited   = "rate_limited"
+)
+
+var (
+	// Metrics for rejected authentication attempts
+	authFailures = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "auth_failures_total",
+			Help: "Total number of rejected authentication attempts",
+		},
+		[]string{"service", "reason"},
+	)
+)
+
+// ClassifyAuthError maps a ValidateToken error to an auth failure reason
+func ClassifyAuthError(err error) string {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return AuthFailureExpired
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return AuthFailureMalformed
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return AuthFailureBadSignature
+	default:
+		return AuthFailureInvalidClaims
+	}
+}
+
+// RecordAuthFailure counts a rejected authentication attempt and logs it with the remote IP
+func RecordAuthFailure(service, remoteIP, reason string, err error) {
+	authFailures.WithLabelValues(service, reason).Inc()
+
+	fields := []zap.Field{
+		logger.String("service", service),
+		logger.String("remote_ip", remoteIP),
+		logger.String("reason", reason),
+	}
+	if err != nil {
+		fields = append(fields, logger.ErrorField(err))
+	}
+	logger.Warn("Authentication failed", fields...)
+}
+
+// TrustedProxies holds the proxies whose X-Forwarded-For and X-Real-IP headers are honored.
+// Anyone else can set these headers, so trusting them from any peer would let clients evade
+// the auth failure limiter or get another IP locked out.
+type TrustedProxies struct {
+	networks []*net.IPNet
+}
+
+// ParseTrustedProxies parses proxy IPs and CIDR ranges (e.g. "10.0.0.0/8", "127.0.0.1")
+func ParseTrustedProxies(specs []string) (*TrustedProxies, error) {
+	proxies := &TrustedProxies{}
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		if !strings.Contains(spec, "/") {
+			ip := net.ParseIP(spec)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", spec)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies.networks = append(proxies.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", spec, err)
+		}
+		proxies.networks = append(proxies.networks, network)
+	}
+	return proxies, nil
+}
+
+// trusts returns whether ip belongs to a trusted proxy
+func (p *TrustedProxies) trusts(ip string) bool {
+	if p == nil {
+		return false
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range p.networks {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the client IP for a request: RemoteAddr without port, unless the request
+// came through a trusted proxy. Then it is the nearest X-Forwarded-For address that is not a
+// trusted proxy itself, or X-Real-IP without X-Forwarded-For. A nil TrustedProxies trusts no one.
+func (p *TrustedProxies) ClientIP(r *http.Request) string {
+	remoteIP := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remoteIP = host
+	}
+	if !p.trusts(remoteIP) {
+		return remoteIP
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		// Each proxy appends the address it received the request from, so walk back from the
+		// nearest hop until one is not a trusted proxy
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if hop != "" && (!p.trusts(hop) || i == 0) {
+				return hop
+			}
+		}
+	}
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
+		return realIP
+	}
+	return remoteIP
+}
+
+// AuthFailureLimiter blocks IPs that repeatedly fail authentication within a window
+type AuthFailureLimiter struct {
+	limit     int
+	window    time.Duration
+	failures  map[string]*authFailureWindow
+	lastPrune time.Time
+	now       func() time.Time
+	mu        sync.Mutex
+}
+
+// authFailureWindow tracks failures for one IP in the current window
+type authFailureWindow struct {
+	count int
+	start time.Time
+}
+
+// NewAuthFailureLimiter creates a limiter allowing limit failures per IP per window (limit <= 0 disables it)
+func NewAuthFailureLimiter(limit int, window time.Duration) *AuthFailureLimiter {
+	return &AuthFailureLimiter{
+		limit:    limit,
+		window:   window,
+		failures: make(map[string]*authFailureWindow),
+		now:      time.Now,
+	}
+}
+
+// Allow returns false if the IP has reached the failure limit in the current window
+func (l *AuthFailureLimiter) Allow(ip string) bool {
+	if l == nil || l.limit <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, exists := l.failures[ip]
+	if !exists || l.now().Sub(entry.start) >= l.window {
+		return true
+	}
+	return entry.count < l.limit
+}
+
+// RecordFailure records a failed authentication attempt from the IP
+func (l *AuthFailureLimiter) RecordFailure(ip string) {
+	if l == nil || l.limit <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.pruneLocked(now)
+
+	entry, exists := l.failures[ip]
+	if !exists || now.Sub(entry.start) >= l.window {
+		l.failures[ip] = &authFailureWindow{count: 1, start: now}
+		return
+	}
+	entry.count++
+}
+
+// pruneLocked drops expired windows at most once per window
+func (l *AuthFailureLimiter) pruneLocked(now time.Time) {
+	if now.Sub(l.lastPrune) < l.window {
+		return
+	}
+	for ip, entry := range l.failures {
+		if now.Sub(entry.start) >= l.window {
+			delete(l.failures, ip)
+		}
+	}
+	l.lastPrune = now
+}
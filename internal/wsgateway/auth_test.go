@@ -1,10 +1,12 @@
 package wsgateway
 
 import (
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	dto "github.com/prometheus/client_model/go"
 )
 
 func TestAuthManager_ValidateToken(t *testing.T) {
@@ -120,3 +122,155 @@ func TestAuthManager_ValidateToken_SubjectClaim(t *testing.T) {
 	}
 }
 
+
+// authFailureCount returns the current auth_failures_total value for a service and reason
+func authFailureCount(t *testing.T, service, reason string) float64 {
+	t.Helper()
+
+	var m dto.Metric
+	if err := authFailures.WithLabelValues(service, reason).Write(&m); err != nil {
+		t.Fatalf("Failed to write metric: %v", err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+func TestRecordAuthFailure_Reasons(t *testing.T) {
+	secret := "test-secret-key"
+	authManager := NewAuthManager(secret)
+
+	sign := func(claims jwt.MapClaims, key string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
+		if err != nil {
+			t.Fatalf("Failed to create token: %v", err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		reason string
+	}{
+		{
+			name:   "expired",
+			token:  sign(jwt.MapClaims{"user_id": "user-1", "exp": time.Now().Add(-time.Hour).Unix()}, secret),
+			reason: AuthFailureExpired,
+		},
+		{
+			name:   "malformed",
+			token:  "not-a-jwt",
+			reason: AuthFailureMalformed,
+		},
+		{
+			name:   "bad signature",
+			token:  sign(jwt.MapClaims{"user_id": "user-1", "exp": time.Now().Add(time.Hour).Unix()}, "wrong-secret"),
+			reason: AuthFailureBadSignature,
+		},
+		{
+			name:   "missing user",
+			token:  sign(jwt.MapClaims{"exp": time.Now().Add(time.Hour).Unix()}, secret),
+			reason: AuthFailureInvalidClaims,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authManager.ValidateToken(tt.token)
+			if err == nil {
+				t.Fatal("Expected token validation to fail")
+			}
+
+			reason := ClassifyAuthError(err)
+			if reason != tt.reason {
+				t.Fatalf("ClassifyAuthError() = %s, want %s (err: %v)", reason, tt.reason, err)
+			}
+
+			before := authFailureCount(t, "test", tt.reason)
+			RecordAuthFailure("test", "10.0.0.1", reason, err)
+			if got := authFailureCount(t, "test", tt.reason); got != before+1 {
+				t.Errorf("Expected %s counter %v, got %v", tt.reason, before+1, got)
+			}
+		})
+	}
+}
+
+func TestAuthFailureLimiter(t *testing.T) {
+	now := time.Now()
+	limiter := NewAuthFailureLimiter(2, time.Minute)
+	limiter.now = func() time.Time { return now }
+
+	limiter.RecordFailure("10.0.0.1")
+	if !limiter.Allow("10.0.0.1") {
+		t.Error("Expected IP to be allowed below the failure limit")
+	}
+
+	limiter.RecordFailure("10.0.0.1")
+	if limiter.Allow("10.0.0.1") {
+		t.Error("Expected IP to be blocked at the failure limit")
+	}
+	if !limiter.Allow("10.0.0.2") {
+		t.Error("Expected other IPs to be unaffected")
+	}
+
+	now = now.Add(time.Minute)
+	if !limiter.Allow("10.0.0.1") {
+		t.Error("Expected IP to be allowed once the window expires")
+	}
+
+	disabled := NewAuthFailureLimiter(0, time.Minute)
+	for i := 0; i < 5; i++ {
+		disabled.RecordFailure("10.0.0.1")
+	}
+	if !disabled.Allow("10.0.0.1") {
+		t.Error("Expected a zero limit to disable rate limiting")
+	}
+}
+
+func TestTrustedProxies_ClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.5"})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		proxies    *TrustedProxies
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{"untrusted peer headers ignored", proxies, "203.0.113.7:4000", "198.51.100.1", "198.51.100.2", "203.0.113.7"},
+		{"no proxies trusts no one", nil, "10.0.0.1:4000", "198.51.100.1", "", "10.0.0.1"},
+		{"trusted proxy forwards client", proxies, "10.0.0.1:4000", "198.51.100.1", "", "198.51.100.1"},
+		{"spoofed leftmost hop skipped", proxies, "10.0.0.1:4000", "1.2.3.4, 198.51.100.1, 10.0.0.2", "", "198.51.100.1"},
+		{"single-IP proxy", proxies, "192.168.1.5:4000", "198.51.100.1", "", "198.51.100.1"},
+		{"all hops trusted", proxies, "10.0.0.1:4000", "10.0.0.3, 10.0.0.2", "", "10.0.0.3"},
+		{"real ip from trusted proxy", proxies, "10.0.0.1:4000", "", "198.51.100.2", "198.51.100.2"},
+		{"trusted proxy without headers", proxies, "10.0.0.1:4000", "", "", "10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/ws", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := tt.proxies.ClientIP(req); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxies_Invalid(t *testing.T) {
+	for _, spec := range []string{"not-an-ip", "10.0.0.0/33"} {
+		if _, err := ParseTrustedProxies([]string{spec}); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
@@ -4,11 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/mohamedkhairy/stock-scanner/internal/models"
-	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
 )
 
 // Connection represents a WebSocket connection with a client
@@ -19,12 +19,42 @@ type Connection struct {
 	Send              chan []byte
 	Subscriptions     map[string]bool // symbol -> subscribed
 	ToplistSubscriptions map[string]bool // toplist_id -> subscribed
+	AlertSubscriptions map[string]bool // alert_id -> subscribed (for alert notes)
+	minSeverity       map[string]int  // symbol (or AllSymbols) -> minimum alert severity rank (missing = every alert)
+	limits            SubscriptionLimits
+	universe          map[string]bool // Symbols that can be subscribed (nil = any symbol)
+	unsubscribedAlerts string         // Alerts delivered before any subscription: UnsubscribedAlertsAll (default) or UnsubscribedAlertsNone
+	alertScope        string // Alert metadata scope (models.AlertScopeFull or restricted)
 	mu                sync.RWMutex
 	ctx               context.Context
 	cancel            context.CancelFunc
 	lastPong          time.Time
 	createdAt         time.Time
 	closed            bool // Track if connection is already closed
+	limiter           *sendRateLimiter // Outbound rate limit (nil = unlimited)
+	messagesDropped   atomic.Int64     // Messages dropped because the send buffer was full
+	onMessageDropped  func()           // Called when a queued message is dropped (optional)
+}
+
+// Subscription limit policies
+const (
+	SubscriptionLimitReject   = "reject"   // Reject the whole subscribe request
+	SubscriptionLimitTruncate = "truncate" // Subscribe up to the limit and reject the rest
+)
+
+// AllSymbols subscribes a connection to alerts for every symbol
+const AllSymbols = "all"
+
+// Policies for alerts sent to connections without symbol subscriptions
+const (
+	UnsubscribedAlertsAll  = "all"  // Deliver every alert until the client subscribes
+	UnsubscribedAlertsNone = "none" // Deliver nothing until the client subscribes
+)
+
+// SubscriptionLimits holds per-connection subscription limits
+type SubscriptionLimits struct {
+	MaxSymbols int    // Max subscribed symbols (0 = unlimited)
+	Policy     string // SubscriptionLimitReject (default) or SubscriptionLimitTruncate
 }
 
 // NewConnection creates a new WebSocket connection
@@ -34,9 +64,11 @@ func NewConnection(id string, userID string, conn *websocket.Conn) *Connection {
 		ID:                  id,
 		UserID:              userID,
 		Conn:                conn,
-		Send:                make(chan []byte, 256), // Buffered channel
+		Send:                make(chan []byte, DefaultSendBufferSize), // Buffered channel
 		Subscriptions:       make(map[string]bool),
 		ToplistSubscriptions: make(map[string]bool),
+		AlertSubscriptions:  make(map[string]bool),
+		minSeverity:         make(map[string]int),
 		ctx:                 ctx,
 		cancel:              cancel,
 		createdAt:           time.Now(),
@@ -51,11 +83,124 @@ func (c *Connection) Subscribe(symbol string) {
 	c.Subscriptions[symbol] = true
 }
 
+// SetSubscriptionLimits sets the per-connection symbol subscription limits
+func (c *Connection) SetSubscriptionLimits(limits SubscriptionLimits) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.limits = limits
+}
+
+// SetSymbolUniverse restricts the symbols the connection can subscribe to (nil = any symbol).
+// The map is shared and must not be modified afterwards.
+func (c *Connection) SetSymbolUniverse(universe map[string]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.universe = universe
+}
+
+// SetUnsubscribedAlerts sets whether alerts are delivered before the client subscribes to any
+// symbol: UnsubscribedAlertsAll (default) or UnsubscribedAlertsNone
+func (c *Connection) SetUnsubscribedAlerts(policy string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unsubscribedAlerts = policy
+}
+
+// SplitUnknownSymbols splits symbols into those in the connection's symbol universe (or
+// AllSymbols, or the breadth alert symbol) and those outside it
+func (c *Connection) SplitUnknownSymbols(symbols []string) ([]string, []string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.universe == nil {
+		return symbols, nil
+	}
+
+	var known, unknown []string
+	for _, symbol := range symbols {
+		if symbol == AllSymbols || symbol == models.BreadthAlertSymbol || c.universe[symbol] {
+			known = append(known, symbol)
+		} else {
+			unknown = append(unknown, symbol)
+		}
+	}
+	return known, unknown
+}
+
+// SetAlertScope sets the alert metadata scope of the connection's user
+func (c *Connection) SetAlertScope(scope string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.alertScope = scope
+}
+
+// AlertScope returns the alert metadata scope of the connection's user
+func (c *Connection) AlertScope() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.alertScope
+}
+
+// SubscribeSymbols subscribes to symbols, enforcing the connection's subscription limit.
+// With the reject policy, no new symbols are subscribed if the request would exceed the limit;
+// with the truncate policy, new symbols are subscribed in order until the limit is reached.
+// Returns the subscribed symbols (including ones already subscribed) and the rejected symbols.
+func (c *Connection) SubscribeSymbols(symbols []string) ([]string, []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var existing, added []string
+	seen := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		if seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		if c.Subscriptions[symbol] {
+			existing = append(existing, symbol)
+		} else {
+			added = append(added, symbol)
+		}
+	}
+
+	var rejected []string
+	if max := c.limits.MaxSymbols; max > 0 && len(c.Subscriptions)+len(added) > max {
+		available := max - len(c.Subscriptions)
+		if available < 0 || c.limits.Policy != SubscriptionLimitTruncate {
+			available = 0
+		}
+		rejected = added[available:]
+		added = added[:available]
+	}
+
+	for _, symbol := range added {
+		c.Subscriptions[symbol] = true
+	}
+
+	return append(existing, added...), rejected
+}
+
 // Unsubscribe unsubscribes from alerts for a symbol
 func (c *Connection) Unsubscribe(symbol string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	delete(c.Subscriptions, symbol)
+	delete(c.minSeverity, symbol)
+}
+
+// SetMinSeverity sets the minimum severity of alerts delivered for subscribed symbols
+// (models.SeverityInfo or "" delivers every alert)
+func (c *Connection) SetMinSeverity(symbols []string, severity string) {
+	rank := models.SeverityRank(severity)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, symbol := range symbols {
+		if rank <= 0 {
+			delete(c.minSeverity, symbol)
+		} else {
+			c.minSeverity[symbol] = rank
+		}
+	}
 }
 
 // IsSubscribed checks if the connection is subscribed to a symbol
@@ -69,14 +214,32 @@ func (c *Connection) IsSubscribed(symbol string) bool {
 func (c *Connection) ShouldReceiveAlert(alert *models.Alert) bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
+
+	// Alerts addressed to a specific user (e.g. test alerts) only go to that user
+	if targetUserID := alert.TargetUserID(); targetUserID != "" && targetUserID != c.UserID {
+		return false
+	}
 	
-	// If no subscriptions, receive all alerts (MVP behavior)
+	// Without subscriptions, the unsubscribed policy decides (all alerts by default)
 	if len(c.Subscriptions) == 0 {
-		return true
+		return c.unsubscribedAlerts != UnsubscribedAlertsNone
 	}
 	
-	// Check if subscribed to this symbol
-	return c.Subscriptions[alert.Symbol]
+	// Check if subscribed to this symbol or to all symbols, at the subscription's minimum severity
+	// (alerts without a known severity count as info)
+	rank := max(models.SeverityRank(alert.Severity), 0)
+	if c.Subscriptions[alert.Symbol] && rank >= c.minSeverity[alert.Symbol] {
+		return true
+	}
+	// Breadth alerts are market-wide, so they also reach subscribers of the symbols they cover
+	if alert.IsBreadth() {
+		for _, symbol := range alert.BreadthSymbols() {
+			if c.Subscriptions[symbol] && rank >= c.minSeverity[symbol] {
+				return true
+			}
+		}
+	}
+	return c.Subscriptions[AllSymbols] && rank >= c.minSeverity[AllSymbols]
 }
 
 // SubscribeToplist subscribes to toplist updates
@@ -100,6 +263,27 @@ func (c *Connection) IsSubscribedToToplist(toplistID string) bool {
 	return c.ToplistSubscriptions[toplistID]
 }
 
+// SubscribeAlert subscribes to notes added to an alert
+func (c *Connection) SubscribeAlert(alertID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.AlertSubscriptions[alertID] = true
+}
+
+// UnsubscribeAlert unsubscribes from notes added to an alert
+func (c *Connection) UnsubscribeAlert(alertID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.AlertSubscriptions, alertID)
+}
+
+// IsSubscribedToAlert checks if the connection is subscribed to an alert's notes
+func (c *Connection) IsSubscribedToAlert(alertID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.AlertSubscriptions[alertID]
+}
+
 // UpdateLastPong updates the last pong time
 func (c *Connection) UpdateLastPong() {
 	c.mu.Lock()
@@ -178,18 +362,23 @@ func (c *Connection) SendAlert(alert *models.Alert) error {
 		return err
 	}
 	
-	select {
-	case c.Send <- data:
-		return nil
-	case <-c.ctx.Done():
-		return c.ctx.Err()
-	case <-time.After(1 * time.Second):
-		logger.Warn("Failed to send alert, channel full",
-			logger.String("connection_id", c.ID),
-			logger.String("user_id", c.UserID),
-		)
-		return nil // Drop message if channel is full
+	// Never blocks: the oldest queued message is dropped if the client is falling behind
+	return c.enqueue(data)
+}
+
+// SendAlertNote sends an alert note to the connection
+func (c *Connection) SendAlertNote(note *models.AlertNote) error {
+	message := map[string]interface{}{
+		"type": "alert_note",
+		"data": note,
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
 	}
+
+	return c.enqueue(data)
 }
 
 // SendError sends an error message to the connection
@@ -205,14 +394,6 @@ func (c *Connection) SendError(code string, message string) error {
 		return err
 	}
 	
-	select {
-	case c.Send <- data:
-		return nil
-	case <-c.ctx.Done():
-		return c.ctx.Err()
-	default:
-		// Drop error message if channel is full
-		return nil
-	}
+	return c.enqueue(data)
 }
 
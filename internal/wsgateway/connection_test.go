@@ -88,3 +88,31 @@ func TestConnection_UpdateLastPong(t *testing.T) {
 	}
 }
 
+
+func TestConnection_ShouldReceiveAlert_TargetUser(t *testing.T) {
+	conn := &Connection{
+		ID:            "conn-1",
+		UserID:        "user-1",
+		Subscriptions: make(map[string]bool),
+	}
+
+	alert := &models.Alert{
+		ID:        "test-1",
+		RuleID:    "test",
+		Symbol:    "TEST",
+		Timestamp: time.Now(),
+		Metadata: map[string]interface{}{
+			models.AlertMetadataTest:   true,
+			models.AlertMetadataUserID: "user-1",
+		},
+	}
+
+	if !conn.ShouldReceiveAlert(alert) {
+		t.Error("Expected connection to receive alert addressed to its user")
+	}
+
+	alert.Metadata[models.AlertMetadataUserID] = "user-2"
+	if conn.ShouldReceiveAlert(alert) {
+		t.Error("Expected connection not to receive alert addressed to another user")
+	}
+}
@@ -26,7 +26,12 @@ type Hub struct {
 	wg             sync.WaitGroup
 	mu             sync.RWMutex
 	running        bool
+	draining       bool // Shutting down: new connections are refused
 	stats          HubStats
+	reorder        *alertReorderBuffer // Per-symbol alert ordering (nil = deliver in stream order)
+	preferences    *userPreferencesCache // Per-user quiet hours, snoozes and locale (nil = not applied)
+	redactor       *models.AlertRedactor // Alert metadata redaction by connection scope (nil = full metadata)
+	universe       map[string]bool       // Symbols clients can subscribe to (nil = any symbol)
 }
 
 // HubStats holds statistics about the hub
@@ -38,6 +43,8 @@ type HubStats struct {
 	AlertsDropped       int64
 	MessagesSent        int64
 	MessagesFailed      int64
+	AlertsLate          int64 // Sequenced alerts that arrived after a later alert for the symbol was delivered
+	MessagesDropped     int64 // Queued messages dropped because a slow connection's send buffer was full
 	LastAlertTime       time.Time
 	mu                  sync.RWMutex
 }
@@ -45,7 +52,7 @@ type HubStats struct {
 // NewHub creates a new WebSocket hub
 func NewHub(config config.WSGatewayConfig, redis storage.RedisClient, alertStream string, consumerGroup string) *Hub {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &Hub{
+	hub := &Hub{
 		config:        config,
 		registry:      NewConnectionRegistry(),
 		redis:         redis,
@@ -55,6 +62,70 @@ func NewHub(config config.WSGatewayConfig, redis storage.RedisClient, alertStrea
 		cancel:        cancel,
 		stats:         HubStats{},
 	}
+	if config.AlertReorderWindow > 0 {
+		hub.reorder = newAlertReorderBuffer(config.AlertReorderWindow)
+	}
+	hub.SetSymbolUniverse(config.SymbolUniverse)
+	return hub
+}
+
+// SetSymbolUniverse replaces the symbols clients can subscribe to (empty = any symbol) on the
+// hub and every registered connection, e.g. when the universe changes through the API.
+// Existing subscriptions are kept.
+func (h *Hub) SetSymbolUniverse(symbols []string) {
+	var universe map[string]bool
+	if len(symbols) > 0 {
+		universe = make(map[string]bool, len(symbols))
+		for _, symbol := range symbols {
+			universe[symbol] = true
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.universe = universe
+	for _, conn := range h.registry.GetAll() {
+		conn.SetSymbolUniverse(universe)
+	}
+}
+
+// SetUserPreferences applies each connection's user preferences to broadcast alerts:
+// quiet hours and snoozes suppress the alert, and the message is sent in the user's locale.
+// Preferences are cached for ttl.
+func (h *Hub) SetUserPreferences(source UserPreferencesSource, ttl time.Duration) {
+	h.preferences = newUserPreferencesCache(source, ttl)
+}
+
+// SetAlertRedactor strips internal alert metadata for connections without the full alert scope
+func (h *Hub) SetAlertRedactor(redactor *models.AlertRedactor) {
+	h.redactor = redactor
+}
+
+// alertForUser returns the alert as delivered to a user, or nil if the user's preferences suppress it.
+// Preference lookup failures are logged and the alert is delivered as is.
+func (h *Hub) alertForUser(userID string, alert *models.Alert) *models.Alert {
+	if h.preferences == nil {
+		return alert
+	}
+
+	prefs, err := h.preferences.get(h.ctx, userID)
+	if err != nil {
+		logger.Debug("Failed to get user preferences",
+			logger.ErrorField(err),
+			logger.String("user_id", userID),
+		)
+		return alert
+	}
+
+	if prefs.SuppressesAlert(alert, h.preferences.now()) {
+		return nil
+	}
+	if message := alert.MessageFor(prefs.Locale); message != alert.Message {
+		localized := *alert
+		localized.Message = message
+		return &localized
+	}
+	return alert
 }
 
 // Start starts the hub (consumes alerts and broadcasts)
@@ -80,6 +151,10 @@ func (h *Hub) Start() error {
 	h.wg.Add(1)
 	go h.consumeToplistUpdates()
 
+	// Start consuming alert notes from pub/sub
+	h.wg.Add(1)
+	go h.consumeAlertNotes()
+
 	// Start connection health monitor
 	h.wg.Add(1)
 	go h.monitorConnections()
@@ -105,7 +180,11 @@ func (h *Hub) Stop() {
 
 // Register registers a new connection
 func (h *Hub) Register(conn *Connection) {
+	// Holding the read lock keeps a concurrent universe change from missing the connection
+	h.mu.RLock()
+	h.configureConnection(conn)
 	h.registry.Add(conn)
+	h.mu.RUnlock()
 	h.incrementConnectionsTotal()
 	h.incrementConnectionsActive()
 
@@ -121,6 +200,21 @@ func (h *Hub) Register(conn *Connection) {
 	go h.readPump(conn)
 }
 
+// configureConnection applies the gateway's subscription and send settings to a connection
+func (h *Hub) configureConnection(conn *Connection) {
+	conn.SetSubscriptionLimits(SubscriptionLimits{
+		MaxSymbols: h.config.MaxSubscriptionsPerConnection,
+		Policy:     h.config.SubscriptionLimitPolicy,
+	})
+	conn.SetSymbolUniverse(h.universe)
+	conn.SetUnsubscribedAlerts(h.config.UnsubscribedAlerts)
+	conn.SetSendLimits(SendLimits{
+		MaxMessagesPerSecond: h.config.MaxMessagesPerSecond,
+		BufferSize:           h.config.SendBufferSize,
+	})
+	conn.SetOnMessageDropped(h.incrementMessagesDropped)
+}
+
 // Unregister unregisters a connection
 func (h *Hub) Unregister(conn *Connection) {
 	h.registry.Remove(conn.ID)
@@ -152,11 +246,27 @@ func (h *Hub) consumeAlerts() {
 		return
 	}
 
+	// Buffered alerts are released in per-symbol sequence order as their window elapses
+	var releaseTick <-chan time.Time
+	if h.reorder != nil {
+		interval := h.reorder.window / 2
+		if interval < 10*time.Millisecond {
+			interval = 10 * time.Millisecond
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		releaseTick = ticker.C
+		defer h.releaseBufferedAlerts(time.Time{})
+	}
+
 	for {
 		select {
 		case <-h.ctx.Done():
 			return
 
+		case <-releaseTick:
+			h.releaseBufferedAlerts(time.Now())
+
 		case msg, ok := <-messageChan:
 			if !ok {
 				logger.Warn("Alert message channel closed")
@@ -174,31 +284,64 @@ func (h *Hub) consumeAlerts() {
 			}
 
 			h.incrementAlertsReceived()
-			h.broadcastAlert(alert)
+			if h.reorder == nil {
+				h.deliverAlert(alert, msg.ID)
+				continue
+			}
 
-			// Acknowledge message
-			ackCtx, ackCancel := context.WithTimeout(context.Background(), 5*time.Second)
-			err = h.redis.AcknowledgeMessage(ackCtx, h.alertStream, h.consumerGroup, msg.ID)
-			ackCancel()
-			if err != nil {
-				logger.Warn("Failed to acknowledge alert message",
-					logger.ErrorField(err),
-					logger.String("message_id", msg.ID),
+			immediate, late := h.reorder.add(alert, msg.ID, time.Now())
+			if late {
+				h.incrementAlertsLate()
+				logger.Debug("Alert arrived after its reorder window",
+					logger.String("alert_id", alert.ID),
+					logger.String("symbol", alert.Symbol),
 				)
 			}
+			if immediate != nil {
+				h.deliverAlert(immediate.alert, immediate.messageID)
+			}
 		}
 	}
 }
 
+// releaseBufferedAlerts delivers reorder-buffered alerts whose window elapsed by now (all if now is zero)
+func (h *Hub) releaseBufferedAlerts(now time.Time) {
+	for _, entry := range h.reorder.release(now) {
+		h.deliverAlert(entry.alert, entry.messageID)
+	}
+}
+
+// deliverAlert broadcasts an alert and acknowledges its stream message
+func (h *Hub) deliverAlert(alert *models.Alert, messageID string) {
+	h.broadcastAlert(alert)
+
+	// Acknowledge message
+	ackCtx, ackCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	err := h.redis.AcknowledgeMessage(ackCtx, h.alertStream, h.consumerGroup, messageID)
+	ackCancel()
+	if err != nil {
+		logger.Warn("Failed to acknowledge alert message",
+			logger.ErrorField(err),
+			logger.String("message_id", messageID),
+		)
+	}
+}
+
 // broadcastAlert broadcasts an alert to all subscribed connections
 func (h *Hub) broadcastAlert(alert *models.Alert) {
 	connections := h.registry.GetAll()
 	sent := 0
 	dropped := 0
+	suppressed := 0
 
 	for _, conn := range connections {
 		if conn.ShouldReceiveAlert(alert) {
-			err := conn.SendAlert(alert)
+			userAlert := h.alertForUser(conn.UserID, alert)
+			if userAlert == nil {
+				suppressed++
+				continue
+			}
+			err := conn.SendAlert(h.redactor.Redact(userAlert, conn.AlertScope()))
 			if err != nil {
 				dropped++
 				logger.Debug("Failed to send alert to connection",
@@ -222,6 +365,7 @@ func (h *Hub) broadcastAlert(alert *models.Alert) {
 		logger.String("symbol", alert.Symbol),
 		logger.Int("sent", sent),
 		logger.Int("dropped", dropped),
+		logger.Int("suppressed", suppressed),
 		logger.Int("total_connections", len(connections)),
 	)
 }
@@ -247,7 +391,9 @@ func (h *Hub) deserializeAlert(msg storage.StreamMessage) (*models.Alert, error)
 	return &alert, nil
 }
 
-// writePump pumps messages from the hub to the WebSocket connection
+// writePump pumps messages from the hub to the WebSocket connection, at most at the
+// connection's rate limit. Messages queue in the connection's send buffer meanwhile, so a
+// slow client never blocks broadcasts.
 func (h *Hub) writePump(conn *Connection) {
 	defer h.wg.Done()
 	defer h.Unregister(conn)
@@ -268,17 +414,32 @@ func (h *Hub) writePump(conn *Connection) {
 				return
 			}
 
+			if !conn.waitToSend(h.ctx) {
+				return
+			}
+			conn.Conn.SetWriteDeadline(time.Now().Add(h.config.WriteTimeout))
+
 			w, err := conn.Conn.NextWriter(websocket.TextMessage)
 			if err != nil {
 				return
 			}
 			w.Write(message)
 
-			// Add queued messages to the current message
+			// Add queued messages to the current message, as far as the rate limit allows
 			n := len(conn.Send)
-			for i := 0; i < n; i++ {
-				w.Write([]byte{'\n'})
-				w.Write(<-conn.Send)
+		batch:
+			for i := 0; i < n && conn.trySend(); i++ {
+				select {
+				case queued, ok := <-conn.Send:
+					if !ok {
+						break batch
+					}
+					w.Write([]byte{'\n'})
+					w.Write(queued)
+				default:
+					// Drained by a concurrent drop of the oldest message
+					break batch
+				}
 			}
 
 			if err := w.Close(); err != nil {
@@ -430,6 +591,84 @@ func (h *Hub) broadcastToplistUpdate(toplistID string, toplistType string, updat
 	}
 }
 
+// consumeAlertNotes consumes alert notes from Redis pub/sub and broadcasts them
+func (h *Hub) consumeAlertNotes() {
+	defer h.wg.Done()
+
+	messageChan, err := h.redis.Subscribe(h.ctx, models.AlertNotesChannel)
+	if err != nil {
+		logger.Error("Failed to subscribe to alert notes",
+			logger.ErrorField(err),
+			logger.String("channel", models.AlertNotesChannel),
+		)
+		return
+	}
+
+	for {
+		select {
+		case <-h.ctx.Done():
+			return
+		case msg, ok := <-messageChan:
+			if !ok {
+				logger.Warn("Alert notes channel closed")
+				return
+			}
+
+			if msg.Channel != models.AlertNotesChannel {
+				continue
+			}
+
+			var note models.AlertNote
+			if err := json.Unmarshal([]byte(msg.Message), &note); err != nil {
+				logger.Warn("Failed to parse alert note",
+					logger.ErrorField(err),
+				)
+				continue
+			}
+
+			if note.AlertID == "" {
+				logger.Warn("Alert note missing alert_id")
+				continue
+			}
+
+			h.broadcastAlertNote(&note)
+		}
+	}
+}
+
+// broadcastAlertNote sends an alert note to connections subscribed to the alert
+func (h *Hub) broadcastAlertNote(note *models.AlertNote) {
+	connections := h.registry.GetAll()
+	broadcastCount := 0
+
+	for _, conn := range connections {
+		if !conn.IsSubscribedToAlert(note.AlertID) {
+			continue
+		}
+
+		if err := conn.SendAlertNote(note); err != nil {
+			logger.Debug("Failed to send alert note to connection",
+				logger.ErrorField(err),
+				logger.String("connection_id", conn.ID),
+				logger.String("alert_id", note.AlertID),
+			)
+			h.incrementMessagesFailed()
+			continue
+		}
+
+		h.incrementMessagesSent()
+		broadcastCount++
+	}
+
+	if broadcastCount > 0 {
+		logger.Debug("Broadcast alert note",
+			logger.String("alert_id", note.AlertID),
+			logger.String("note_id", note.ID),
+			logger.Int("connections", broadcastCount),
+		)
+	}
+}
+
 // monitorConnections monitors connection health and removes stale connections
 func (h *Hub) monitorConnections() {
 	defer h.wg.Done()
@@ -479,6 +718,8 @@ func (h *Hub) GetStats() HubStats {
 		AlertsDropped:     h.stats.AlertsDropped,
 		MessagesSent:      h.stats.MessagesSent,
 		MessagesFailed:    h.stats.MessagesFailed,
+		AlertsLate:        h.stats.AlertsLate,
+		MessagesDropped:   h.stats.MessagesDropped,
 		LastAlertTime:     h.stats.LastAlertTime,
 	}
 }
@@ -535,3 +776,14 @@ func (h *Hub) incrementMessagesFailed() {
 	h.stats.MessagesFailed++
 }
 
+func (h *Hub) incrementAlertsLate() {
+	h.stats.mu.Lock()
+	defer h.stats.mu.Unlock()
+	h.stats.AlertsLate++
+}
+
+func (h *Hub) incrementMessagesDropped() {
+	h.stats.mu.Lock()
+	defer h.stats.mu.Unlock()
+	h.stats.MessagesDropped++
+}
@@ -0,0 +1,84 @@
+package wsgateway
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// bufferedAlert is an alert held for reordering together with its stream message ID
+type bufferedAlert struct {
+	alert     *models.Alert
+	messageID string
+	deadline  time.Time // Released no later than this
+}
+
+// alertReorderBuffer holds alerts per symbol for a short window and releases them in
+// sequence order, so every gateway replica delivers a symbol's alerts in the order the
+// scanner emitted them even when stream reads arrive out of order.
+type alertReorderBuffer struct {
+	window  time.Duration
+	pending map[string][]*bufferedAlert // Symbol -> alerts sorted by sequence
+	lastSeq map[string]int64            // Symbol -> last released sequence
+}
+
+// newAlertReorderBuffer creates a reorder buffer holding alerts for window
+func newAlertReorderBuffer(window time.Duration) *alertReorderBuffer {
+	return &alertReorderBuffer{
+		window:  window,
+		pending: make(map[string][]*bufferedAlert),
+		lastSeq: make(map[string]int64),
+	}
+}
+
+// add buffers an alert received at now. Unsequenced alerts and alerts arriving after
+// a later sequence was already released can't be ordered, so they are returned for
+// immediate delivery instead (late is true for the latter).
+func (b *alertReorderBuffer) add(alert *models.Alert, messageID string, now time.Time) (immediate *bufferedAlert, late bool) {
+	entry := &bufferedAlert{alert: alert, messageID: messageID, deadline: now.Add(b.window)}
+	if alert.Sequence == 0 {
+		return entry, false
+	}
+	if alert.Sequence <= b.lastSeq[alert.Symbol] {
+		return entry, true
+	}
+
+	queue := b.pending[alert.Symbol]
+	i := sort.Search(len(queue), func(i int) bool { return queue[i].alert.Sequence > alert.Sequence })
+	queue = append(queue, nil)
+	copy(queue[i+1:], queue[i:])
+	queue[i] = entry
+	b.pending[alert.Symbol] = queue
+	return nil, false
+}
+
+// release returns buffered alerts whose window has elapsed by now, in sequence order per
+// symbol. Alerts sequenced before an expired one are released with it, since they must
+// be delivered first. A zero now releases everything (used on shutdown).
+func (b *alertReorderBuffer) release(now time.Time) []*bufferedAlert {
+	var released []*bufferedAlert
+	for symbol, queue := range b.pending {
+		cut := len(queue)
+		if !now.IsZero() {
+			cut = 0
+			for i, entry := range queue {
+				if !now.Before(entry.deadline) {
+					cut = i + 1
+				}
+			}
+		}
+		if cut == 0 {
+			continue
+		}
+
+		released = append(released, queue[:cut]...)
+		b.lastSeq[symbol] = queue[cut-1].alert.Sequence
+		if cut == len(queue) {
+			delete(b.pending, symbol)
+		} else {
+			b.pending[symbol] = queue[cut:]
+		}
+	}
+	return released
+}
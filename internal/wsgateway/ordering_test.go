@@ -0,0 +1,140 @@
+package wsgateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+func sequencedAlert(symbol string, seq int64) *models.Alert {
+	return &models.Alert{
+		ID:        fmt.Sprintf("%s-%d", symbol, seq),
+		RuleID:    fmt.Sprintf("rule-%d", seq),
+		RuleName:  "Test Rule",
+		Symbol:    symbol,
+		Timestamp: time.Now(),
+		Price:     150.0,
+		Sequence:  seq,
+	}
+}
+
+func releasedSequences(entries []*bufferedAlert, symbol string) []int64 {
+	var seqs []int64
+	for _, entry := range entries {
+		if entry.alert.Symbol == symbol {
+			seqs = append(seqs, entry.alert.Sequence)
+		}
+	}
+	return seqs
+}
+
+func TestAlertReorderBuffer_ReleasesInSequenceOrder(t *testing.T) {
+	buffer := newAlertReorderBuffer(100 * time.Millisecond)
+	start := time.Now()
+
+	buffer.add(sequencedAlert("AAPL", 3), "1-0", start)
+	buffer.add(sequencedAlert("AAPL", 1), "1-1", start.Add(20*time.Millisecond))
+	buffer.add(sequencedAlert("AAPL", 5), "1-2", start.Add(150*time.Millisecond))
+
+	if released := buffer.release(start.Add(50 * time.Millisecond)); len(released) != 0 {
+		t.Errorf("Expected nothing released inside the window, got %d", len(released))
+	}
+
+	// Sequence 3's window elapsed; 1 precedes it so is released first, 5 stays buffered
+	released := buffer.release(start.Add(100 * time.Millisecond))
+	if got := releasedSequences(released, "AAPL"); fmt.Sprint(got) != "[1 3]" {
+		t.Errorf("Expected sequences [1 3] released, got %v", got)
+	}
+
+	released = buffer.release(time.Time{})
+	if got := releasedSequences(released, "AAPL"); fmt.Sprint(got) != "[5]" {
+		t.Errorf("Expected sequence [5] released on flush, got %v", got)
+	}
+}
+
+func TestAlertReorderBuffer_LateAndUnsequencedAlerts(t *testing.T) {
+	buffer := newAlertReorderBuffer(100 * time.Millisecond)
+	now := time.Now()
+
+	// Alerts without a sequence can't be ordered and are delivered immediately
+	unsequenced := sequencedAlert("AAPL", 0)
+	if immediate, late := buffer.add(unsequenced, "1-0", now); immediate == nil || late {
+		t.Errorf("Expected unsequenced alert delivered immediately, got immediate=%v late=%v", immediate, late)
+	}
+
+	buffer.add(sequencedAlert("AAPL", 5), "1-1", now)
+	buffer.release(time.Time{})
+
+	// A lower sequence arriving after 5 was delivered is passed through and flagged late
+	if immediate, late := buffer.add(sequencedAlert("AAPL", 4), "1-2", now); immediate == nil || !late {
+		t.Errorf("Expected late alert delivered immediately and flagged, got immediate=%v late=%v", immediate, late)
+	}
+
+	// Other symbols are unaffected
+	if immediate, _ := buffer.add(sequencedAlert("MSFT", 1), "1-3", now); immediate != nil {
+		t.Error("Expected MSFT alert buffered")
+	}
+}
+
+func TestHub_ReordersAlertsPerSymbol(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+
+	// Stream reads interleave symbols and arrive out of sequence order
+	reads := []*models.Alert{
+		sequencedAlert("AAPL", 3),
+		sequencedAlert("MSFT", 2),
+		sequencedAlert("AAPL", 1),
+		sequencedAlert("MSFT", 1),
+		sequencedAlert("AAPL", 2),
+	}
+	for i, alert := range reads {
+		data, err := json.Marshal(alert)
+		if err != nil {
+			t.Fatalf("Failed to marshal alert: %v", err)
+		}
+		redis.StreamData = append(redis.StreamData, storage.StreamMessage{
+			ID:     fmt.Sprintf("1-%d", i),
+			Stream: "alerts.filtered",
+			Values: map[string]interface{}{"alert": string(data)},
+		})
+	}
+
+	hub := NewHub(config.WSGatewayConfig{AlertReorderWindow: time.Hour}, redis, "alerts.filtered", "ws-gateway")
+	conn := NewConnection("conn-1", "user-123", nil)
+	conn.Subscribe("AAPL")
+	conn.Subscribe("MSFT")
+	hub.registry.Add(conn)
+
+	// The mock stream closes after the reads, which releases the buffer
+	hub.wg.Add(1)
+	hub.consumeAlerts()
+
+	delivered := map[string][]int64{}
+	for len(conn.Send) > 0 {
+		var message struct {
+			Type string       `json:"type"`
+			Data models.Alert `json:"data"`
+		}
+		if err := json.Unmarshal(<-conn.Send, &message); err != nil {
+			t.Fatalf("Failed to unmarshal message: %v", err)
+		}
+		delivered[message.Data.Symbol] = append(delivered[message.Data.Symbol], message.Data.Sequence)
+	}
+
+	if got := fmt.Sprint(delivered["AAPL"]); got != "[1 2 3]" {
+		t.Errorf("Expected AAPL frames in sequence order [1 2 3], got %s", got)
+	}
+	if got := fmt.Sprint(delivered["MSFT"]); got != "[1 2]" {
+		t.Errorf("Expected MSFT frames in sequence order [1 2], got %s", got)
+	}
+	for i := range reads {
+		if id := fmt.Sprintf("1-%d", i); !redis.Acked[id] {
+			t.Errorf("Expected message %s acknowledged after delivery", id)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package wsgateway
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// UserPreferencesSource looks up a user's delivery preferences (implemented by storage.UserPreferencesStore)
+type UserPreferencesSource interface {
+	GetPreferences(ctx context.Context, userID string) (*models.UserPreferences, error)
+}
+
+// cachedPreferences is a user's preferences and when they were fetched
+type cachedPreferences struct {
+	prefs     *models.UserPreferences
+	fetchedAt time.Time
+}
+
+// userPreferencesCache caches user preferences for a TTL so broadcasting an alert
+// doesn't read them once per connection
+type userPreferencesCache struct {
+	source  UserPreferencesSource
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]cachedPreferences
+	mu      sync.Mutex
+}
+
+func newUserPreferencesCache(source UserPreferencesSource, ttl time.Duration) *userPreferencesCache {
+	return &userPreferencesCache{
+		source:  source,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]cachedPreferences),
+	}
+}
+
+// get returns a user's preferences, fetching them from the source when missing or stale
+func (c *userPreferencesCache) get(ctx context.Context, userID string) (*models.UserPreferences, error) {
+	now := c.now()
+
+	c.mu.Lock()
+	entry, exists := c.entries[userID]
+	c.mu.Unlock()
+	if exists && now.Sub(entry.fetchedAt) < c.ttl {
+		return entry.prefs, nil
+	}
+
+	prefs, err := c.source.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[userID] = cachedPreferences{prefs: prefs, fetchedAt: now}
+	c.mu.Unlock()
+
+	return prefs, nil
+}
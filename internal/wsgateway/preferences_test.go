@@ -0,0 +1,90 @@
+package wsgateway
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+// receivedAlert returns the alert queued on a connection, or nil if none was sent
+func receivedAlert(t *testing.T, conn *Connection) *models.Alert {
+	t.Helper()
+	select {
+	case data := <-conn.Send:
+		var message struct {
+			Data models.Alert `json:"data"`
+		}
+		if err := json.Unmarshal(data, &message); err != nil {
+			t.Fatalf("Failed to unmarshal message: %v", err)
+		}
+		return &message.Data
+	default:
+		return nil
+	}
+}
+
+func TestHub_BroadcastAlert_AppliesUserPreferences(t *testing.T) {
+	redis := storage.NewMockRedisClient()
+	prefsStore := storage.NewUserPreferencesStore(redis)
+	hub := NewHub(config.WSGatewayConfig{}, redis, "alerts.filtered", "ws-gateway")
+	hub.SetUserPreferences(prefsStore, time.Minute)
+
+	now := time.Date(2024, 3, 15, 14, 0, 0, 0, time.UTC)
+	hub.preferences.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	if err := prefsStore.SetPreferences(ctx, &models.UserPreferences{
+		UserID:  "user-snoozed",
+		Snoozes: []models.AlertSnooze{{Symbol: "AAPL", Until: time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)}},
+	}); err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+	if err := prefsStore.SetPreferences(ctx, &models.UserPreferences{UserID: "user-german", Locale: "de-DE"}); err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+
+	snoozed := NewConnection("conn-1", "user-snoozed", nil)
+	german := NewConnection("conn-2", "user-german", nil)
+	plain := NewConnection("conn-3", "user-plain", nil)
+	hub.registry.Add(snoozed)
+	hub.registry.Add(german)
+	hub.registry.Add(plain)
+
+	hub.broadcastAlert(&models.Alert{
+		ID:       "alert-1",
+		RuleID:   "rule-1",
+		Symbol:   "AAPL",
+		Message:  "AAPL crossed 150.00",
+		Messages: map[string]string{"en-US": "AAPL crossed 150.00", "de-DE": "AAPL kreuzte 150,00"},
+	})
+
+	if alert := receivedAlert(t, snoozed); alert != nil {
+		t.Errorf("Expected snoozed user not to receive the alert, got %+v", alert)
+	}
+	if alert := receivedAlert(t, german); alert == nil || alert.Message != "AAPL kreuzte 150,00" {
+		t.Errorf("Expected alert message in the user's locale, got %+v", alert)
+	}
+	if alert := receivedAlert(t, plain); alert == nil || alert.Message != "AAPL crossed 150.00" {
+		t.Errorf("Expected alert with the default message, got %+v", alert)
+	}
+
+	// Preference changes take effect once the cached preferences expire
+	if err := prefsStore.SetPreferences(ctx, &models.UserPreferences{UserID: "user-snoozed"}); err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+	hub.broadcastAlert(&models.Alert{ID: "alert-2", RuleID: "rule-1", Symbol: "AAPL"})
+	if alert := receivedAlert(t, snoozed); alert != nil {
+		t.Error("Expected cached snooze to apply until the TTL elapses")
+	}
+
+	now = now.Add(time.Minute)
+	hub.broadcastAlert(&models.Alert{ID: "alert-3", RuleID: "rule-1", Symbol: "AAPL"})
+	if alert := receivedAlert(t, snoozed); alert == nil {
+		t.Error("Expected alert after the snooze was removed and the cache expired")
+	}
+}
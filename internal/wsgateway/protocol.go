@@ -3,7 +3,9 @@ package wsgateway
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
 	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
 )
 
@@ -15,6 +17,8 @@ const (
 	MessageTypeUnsubscribe      MessageType = "unsubscribe"
 	MessageTypeSubscribeToplist MessageType = "subscribe_toplist"
 	MessageTypeUnsubscribeToplist MessageType = "unsubscribe_toplist"
+	MessageTypeSubscribeAlert   MessageType = "subscribe_alert"
+	MessageTypeUnsubscribeAlert MessageType = "unsubscribe_alert"
 	MessageTypePing             MessageType = "ping"
 	MessageTypePong             MessageType = "pong"
 )
@@ -25,6 +29,7 @@ type ClientMessage struct {
 	Symbol  string          `json:"symbol,omitempty"`
 	Symbols []string        `json:"symbols,omitempty"`
 	Data    json.RawMessage `json:"data,omitempty"`
+	MinSeverity string      `json:"min_severity,omitempty"` // Subscribe only: minimum alert severity delivered for the symbols (default: every alert)
 }
 
 // ServerMessage represents a message to the client
@@ -39,24 +44,54 @@ type ServerMessage struct {
 func (c *Connection) HandleClientMessage(msg *ClientMessage) error {
 	switch MessageType(msg.Type) {
 	case MessageTypeSubscribe:
+		if models.SeverityRank(msg.MinSeverity) < 0 {
+			return c.SendError("invalid_request", "min_severity must be info, warning or critical")
+		}
 		if msg.Symbol != "" {
-			c.Subscribe(msg.Symbol)
+			if known, unknown := c.SplitUnknownSymbols([]string{msg.Symbol}); len(known) == 0 {
+				return c.sendUnknownSymbolsError(unknown)
+			}
+			if _, rejected := c.SubscribeSymbols([]string{msg.Symbol}); len(rejected) > 0 {
+				return c.sendSubscriptionLimitError(rejected)
+			}
+			c.SetMinSeverity([]string{msg.Symbol}, msg.MinSeverity)
 			logger.Debug("Client subscribed to symbol",
 				logger.String("connection_id", c.ID),
 				logger.String("user_id", c.UserID),
 				logger.String("symbol", msg.Symbol),
 			)
-			return c.SendSuccess("subscribed", map[string]string{"symbol": msg.Symbol})
+			data := map[string]string{"symbol": msg.Symbol}
+			if msg.MinSeverity != "" {
+				data["min_severity"] = msg.MinSeverity
+			}
+			return c.SendSuccess("subscribed", data)
 		} else if len(msg.Symbols) > 0 {
-			for _, symbol := range msg.Symbols {
-				c.Subscribe(symbol)
+			known, unknown := c.SplitUnknownSymbols(msg.Symbols)
+			if len(unknown) > 0 {
+				if err := c.sendUnknownSymbolsError(unknown); err != nil || len(known) == 0 {
+					return err
+				}
+			}
+			subscribed, rejected := c.SubscribeSymbols(known)
+			if len(rejected) > 0 {
+				if err := c.sendSubscriptionLimitError(rejected); err != nil || len(subscribed) == 0 {
+					return err
+				}
 			}
+			c.SetMinSeverity(subscribed, msg.MinSeverity)
 			logger.Debug("Client subscribed to symbols",
 				logger.String("connection_id", c.ID),
 				logger.String("user_id", c.UserID),
-				logger.Int("count", len(msg.Symbols)),
+				logger.Int("count", len(subscribed)),
 			)
-			return c.SendSuccess("subscribed", map[string]interface{}{"symbols": msg.Symbols})
+			data := map[string]interface{}{"symbols": subscribed}
+			if msg.MinSeverity != "" {
+				data["min_severity"] = msg.MinSeverity
+			}
+			if rejected = append(unknown, rejected...); len(rejected) > 0 {
+				data["rejected"] = rejected
+			}
+			return c.SendSuccess("subscribed", data)
 		}
 		return c.SendError("invalid_request", "symbol or symbols field required")
 
@@ -108,6 +143,32 @@ func (c *Connection) HandleClientMessage(msg *ClientMessage) error {
 		)
 		return c.SendSuccess("unsubscribed_toplist", map[string]string{"toplist_id": toplistID})
 
+	case MessageTypeSubscribeAlert:
+		alertID := msg.Symbol // Reuse Symbol field for alert ID
+		if alertID == "" {
+			return c.SendError("invalid_request", "alert_id field required")
+		}
+		c.SubscribeAlert(alertID)
+		logger.Debug("Client subscribed to alert notes",
+			logger.String("connection_id", c.ID),
+			logger.String("user_id", c.UserID),
+			logger.String("alert_id", alertID),
+		)
+		return c.SendSuccess("subscribed_alert", map[string]string{"alert_id": alertID})
+
+	case MessageTypeUnsubscribeAlert:
+		alertID := msg.Symbol // Reuse Symbol field for alert ID
+		if alertID == "" {
+			return c.SendError("invalid_request", "alert_id field required")
+		}
+		c.UnsubscribeAlert(alertID)
+		logger.Debug("Client unsubscribed from alert notes",
+			logger.String("connection_id", c.ID),
+			logger.String("user_id", c.UserID),
+			logger.String("alert_id", alertID),
+		)
+		return c.SendSuccess("unsubscribed_alert", map[string]string{"alert_id": alertID})
+
 	case MessageTypePing:
 		// Respond with pong
 		return c.SendPong()
@@ -117,6 +178,33 @@ func (c *Connection) HandleClientMessage(msg *ClientMessage) error {
 	}
 }
 
+// sendSubscriptionLimitError sends an error frame for symbols rejected by the subscription limit
+func (c *Connection) sendSubscriptionLimitError(rejected []string) error {
+	c.mu.RLock()
+	max := c.limits.MaxSymbols
+	c.mu.RUnlock()
+
+	logger.Warn("Client exceeded subscription limit",
+		logger.String("connection_id", c.ID),
+		logger.String("user_id", c.UserID),
+		logger.Int("max_symbols", max),
+		logger.Int("rejected", len(rejected)),
+	)
+	return c.SendError("subscription_limit_exceeded",
+		fmt.Sprintf("subscription limit of %d symbols exceeded, %d symbol(s) rejected", max, len(rejected)))
+}
+
+// sendUnknownSymbolsError sends an error frame for symbols outside the symbol universe
+func (c *Connection) sendUnknownSymbolsError(unknown []string) error {
+	logger.Debug("Client subscribed to unknown symbols",
+		logger.String("connection_id", c.ID),
+		logger.String("user_id", c.UserID),
+		logger.Int("unknown", len(unknown)),
+	)
+	return c.SendError("unknown_symbol",
+		fmt.Sprintf("%d symbol(s) not in the symbol universe rejected: %s", len(unknown), strings.Join(unknown, ",")))
+}
+
 // SendSuccess sends a success message to the client
 func (c *Connection) SendSuccess(action string, data interface{}) error {
 	message := ServerMessage{
@@ -126,7 +214,7 @@ func (c *Connection) SendSuccess(action string, data interface{}) error {
 			"data":   data,
 		},
 	}
-	return c.WriteJSON(message)
+	return c.sendServerMessage(message)
 }
 
 // SendPong sends a pong message to the client
@@ -134,6 +222,16 @@ func (c *Connection) SendPong() error {
 	message := ServerMessage{
 		Type: "pong",
 	}
-	return c.WriteJSON(message)
+	return c.sendServerMessage(message)
+}
+
+// sendServerMessage queues a message for the connection's writer, which is the only
+// goroutine writing to the WebSocket
+func (c *Connection) sendServerMessage(message ServerMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return c.enqueue(data)
 }
 
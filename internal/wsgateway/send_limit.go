@@ -0,0 +1,155 @@
+package wsgateway
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DefaultSendBufferSize is the number of outbound messages queued per connection
+const DefaultSendBufferSize = 256
+
+// ErrConnectionClosed is returned when a message is queued on a closed connection
+var ErrConnectionClosed = errors.New("connection closed")
+
+// SendLimits holds per-connection outbound message limits
+type SendLimits struct {
+	MaxMessagesPerSecond int // Messages written to the client per second (0 = unlimited)
+	BufferSize           int // Messages queued while the client is slow; the oldest is dropped when full (0 = default)
+}
+
+// SetSendLimits sets the connection's outbound rate and buffer size. It must be called before
+// the connection's writer is started; messages already queued are kept, newest first.
+func (c *Connection) SetSendLimits(limits SendLimits) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if limits.MaxMessagesPerSecond > 0 {
+		c.limiter = newSendRateLimiter(limits.MaxMessagesPerSecond)
+	} else {
+		c.limiter = nil
+	}
+
+	if limits.BufferSize <= 0 {
+		limits.BufferSize = DefaultSendBufferSize
+	}
+	if c.closed || c.Send == nil || cap(c.Send) == limits.BufferSize {
+		return
+	}
+
+	queued := make([][]byte, 0, len(c.Send))
+	for len(c.Send) > 0 {
+		queued = append(queued, <-c.Send)
+	}
+	if overflow := len(queued) - limits.BufferSize; overflow > 0 {
+		queued = queued[overflow:]
+		c.messagesDropped.Add(int64(overflow))
+	}
+	c.Send = make(chan []byte, limits.BufferSize)
+	for _, data := range queued {
+		c.Send <- data
+	}
+}
+
+// SetOnMessageDropped sets a callback invoked each time a queued message is dropped
+func (c *Connection) SetOnMessageDropped(callback func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onMessageDropped = callback
+}
+
+// MessagesDropped returns the number of messages dropped because the send buffer was full
+func (c *Connection) MessagesDropped() int64 {
+	return c.messagesDropped.Load()
+}
+
+// enqueue queues a message for the writer without blocking. When the buffer is full the
+// oldest queued message is dropped, so a slow client sees the most recent messages.
+func (c *Connection) enqueue(data []byte) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return ErrConnectionClosed
+	}
+
+	for {
+		select {
+		case c.Send <- data:
+			return nil
+		default:
+		}
+
+		// Buffer full: drop the oldest message (the writer may have drained it already)
+		select {
+		case <-c.Send:
+			c.messagesDropped.Add(1)
+			if c.onMessageDropped != nil {
+				c.onMessageDropped()
+			}
+		default:
+		}
+	}
+}
+
+// waitToSend blocks until the connection's rate limit allows writing a message,
+// returning false if ctx is done first
+func (c *Connection) waitToSend(ctx context.Context) bool {
+	if c.limiter == nil {
+		return true
+	}
+
+	for {
+		wait := c.limiter.reserve(time.Now())
+		if wait <= 0 {
+			return true
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		case <-timer.C:
+		}
+	}
+}
+
+// trySend reports whether the connection's rate limit allows writing a message now
+func (c *Connection) trySend() bool {
+	return c.limiter == nil || c.limiter.reserve(time.Now()) <= 0
+}
+
+// sendRateLimiter is a token bucket refilled at rate tokens per second, holding at most
+// one second's worth of tokens. It is only used by the connection's writer.
+type sendRateLimiter struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// newSendRateLimiter creates a full token bucket
+func newSendRateLimiter(perSecond int) *sendRateLimiter {
+	return &sendRateLimiter{
+		rate:   float64(perSecond),
+		tokens: float64(perSecond),
+	}
+}
+
+// reserve takes a token if one is available and returns 0, otherwise it returns how long
+// until the next token is available
+func (l *sendRateLimiter) reserve(now time.Time) time.Duration {
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.rate {
+			l.tokens = l.rate
+		}
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
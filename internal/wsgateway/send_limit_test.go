@@ -0,0 +1,116 @@
+package wsgateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+func TestHub_BroadcastAlert_DropsOldestForSlowConnection(t *testing.T) {
+	hub := NewHub(config.WSGatewayConfig{SendBufferSize: 3}, storage.NewMockRedisClient(), "alerts.filtered", "ws-gateway")
+
+	// No writer drains the connection, as with a stalled client
+	conn := NewConnection("conn-1", "user-123", nil)
+	hub.configureConnection(conn)
+	hub.registry.Add(conn)
+
+	start := time.Now()
+	for i := 1; i <= 5; i++ {
+		hub.broadcastAlert(&models.Alert{ID: fmt.Sprintf("alert-%d", i), RuleID: "rule-1", Symbol: "AAPL"})
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected broadcasts not to block on a full buffer, took %v", elapsed)
+	}
+
+	var received []string
+	for len(conn.Send) > 0 {
+		var message struct {
+			Data models.Alert `json:"data"`
+		}
+		if err := json.Unmarshal(<-conn.Send, &message); err != nil {
+			t.Fatalf("Failed to decode message: %v", err)
+		}
+		received = append(received, message.Data.ID)
+	}
+	if fmt.Sprint(received) != "[alert-3 alert-4 alert-5]" {
+		t.Errorf("Expected the 3 newest alerts, got %v", received)
+	}
+
+	if conn.MessagesDropped() != 2 {
+		t.Errorf("Expected 2 dropped messages on the connection, got %d", conn.MessagesDropped())
+	}
+	if stats := hub.GetStats(); stats.MessagesDropped != 2 {
+		t.Errorf("Expected 2 dropped messages in hub stats, got %d", stats.MessagesDropped)
+	}
+}
+
+func TestConnection_SetSendLimits_ResizesBuffer(t *testing.T) {
+	conn := NewConnection("conn-1", "user-123", nil)
+	for i := 1; i <= 4; i++ {
+		conn.Send <- []byte(fmt.Sprintf("message-%d", i))
+	}
+
+	conn.SetSendLimits(SendLimits{MaxMessagesPerSecond: 10, BufferSize: 2})
+
+	if cap(conn.Send) != 2 {
+		t.Fatalf("Expected buffer size 2, got %d", cap(conn.Send))
+	}
+	if first, second := string(<-conn.Send), string(<-conn.Send); first != "message-3" || second != "message-4" {
+		t.Errorf("Expected the newest queued messages to be kept, got %s and %s", first, second)
+	}
+	if conn.MessagesDropped() != 2 {
+		t.Errorf("Expected 2 dropped messages, got %d", conn.MessagesDropped())
+	}
+	if conn.limiter == nil {
+		t.Error("Expected a rate limiter")
+	}
+
+	conn.SetSendLimits(SendLimits{})
+	if cap(conn.Send) != DefaultSendBufferSize || conn.limiter != nil {
+		t.Errorf("Expected default buffer and no rate limit, got %d and %v", cap(conn.Send), conn.limiter)
+	}
+}
+
+func TestConnection_SendAfterClose(t *testing.T) {
+	conn := NewConnection("conn-1", "user-123", nil)
+	conn.Close()
+
+	if err := conn.SendAlert(&models.Alert{ID: "alert-1", Symbol: "AAPL"}); err != ErrConnectionClosed {
+		t.Errorf("Expected ErrConnectionClosed, got %v", err)
+	}
+}
+
+func TestSendRateLimiter(t *testing.T) {
+	limiter := newSendRateLimiter(2)
+	now := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+
+	// A full bucket allows a burst of one second's messages
+	for i := 0; i < 2; i++ {
+		if wait := limiter.reserve(now); wait != 0 {
+			t.Fatalf("Message %d: expected no wait, got %v", i, wait)
+		}
+	}
+	if wait := limiter.reserve(now); wait != 500*time.Millisecond {
+		t.Errorf("Expected 500ms wait, got %v", wait)
+	}
+
+	if wait := limiter.reserve(now.Add(500 * time.Millisecond)); wait != 0 {
+		t.Errorf("Expected a token after 500ms, got wait %v", wait)
+	}
+
+	// Idle time refills at most one second's worth of tokens
+	later := now.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		if wait := limiter.reserve(later); wait != 0 {
+			t.Fatalf("Message %d after idle: expected no wait, got %v", i, wait)
+		}
+	}
+	if wait := limiter.reserve(later); wait == 0 {
+		t.Error("Expected the refilled bucket to be capped")
+	}
+}
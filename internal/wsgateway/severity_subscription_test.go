@@ -0,0 +1,71 @@
+package wsgateway
+
+import (
+	"testing"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+func TestHub_BroadcastAlert_MinSeverity(t *testing.T) {
+	hub, conns := newSubscriptionTestHub(config.WSGatewayConfig{}, "user-1")
+	conn := conns[0]
+
+	// AAPL only at warning or above, MSFT at any severity
+	if err := conn.HandleClientMessage(&ClientMessage{Type: string(MessageTypeSubscribe), Symbol: "AAPL", MinSeverity: models.SeverityWarning}); err != nil {
+		t.Fatalf("subscribe error = %v", err)
+	}
+	if err := conn.HandleClientMessage(&ClientMessage{Type: string(MessageTypeSubscribe), Symbol: "MSFT"}); err != nil {
+		t.Fatalf("subscribe error = %v", err)
+	}
+	drainFrames(t, conn)
+
+	broadcast := func(id, symbol, severity string) {
+		hub.broadcastAlert(&models.Alert{ID: id, RuleID: "rule-1", Symbol: symbol, Severity: severity})
+	}
+	broadcast("aapl-info", "AAPL", models.SeverityInfo)
+	broadcast("aapl-none", "AAPL", "")
+	broadcast("aapl-warning", "AAPL", models.SeverityWarning)
+	broadcast("aapl-critical", "AAPL", models.SeverityCritical)
+	broadcast("msft-info", "MSFT", models.SeverityInfo)
+
+	var received []string
+	for _, frame := range drainFrames(t, conn) {
+		if frame["type"] == "alert" {
+			received = append(received, frame["data"].(map[string]interface{})["id"].(string))
+		}
+	}
+	want := []string{"aapl-warning", "aapl-critical", "msft-info"}
+	if len(received) != len(want) {
+		t.Fatalf("Expected alerts %v, got %v", want, received)
+	}
+	for i := range want {
+		if received[i] != want[i] {
+			t.Errorf("Alert %d: expected %s, got %s", i, want[i], received[i])
+		}
+	}
+
+	// Resubscribing without a minimum delivers every alert again
+	conn.HandleClientMessage(&ClientMessage{Type: string(MessageTypeSubscribe), Symbol: "AAPL"})
+	drainFrames(t, conn)
+	broadcast("aapl-info-2", "AAPL", models.SeverityInfo)
+	if symbols := alertSymbols(t, conn); len(symbols) != 1 {
+		t.Errorf("Expected the info alert after resubscribing, got %v", symbols)
+	}
+}
+
+func TestProtocol_Subscribe_InvalidMinSeverity(t *testing.T) {
+	conn := NewConnection("conn-1", "user-1", nil)
+
+	if err := conn.HandleClientMessage(&ClientMessage{Type: string(MessageTypeSubscribe), Symbol: "AAPL", MinSeverity: "urgent"}); err != nil {
+		t.Fatalf("HandleClientMessage() error = %v", err)
+	}
+
+	frames := drainFrames(t, conn)
+	if len(frames) != 1 || frames[0]["type"] != "error" || frames[0]["code"] != "invalid_request" {
+		t.Errorf("Expected an invalid_request error, got %v", frames)
+	}
+	if conn.IsSubscribed("AAPL") {
+		t.Error("Expected no subscription for an invalid min_severity")
+	}
+}
@@ -0,0 +1,105 @@
+package wsgateway
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mohamedkhairy/stock-scanner/pkg/logger"
+)
+
+// MessageTypeShutdown notifies clients that the gateway is shutting down and when to reconnect
+const MessageTypeShutdown MessageType = "shutdown"
+
+// shutdownDrainPoll is how often the drain checks whether every client has disconnected
+const shutdownDrainPoll = 50 * time.Millisecond
+
+// ShutdownMessage is sent to every connection when the hub starts draining
+type ShutdownMessage struct {
+	Type             string `json:"type"`
+	ReconnectAfterMs int64  `json:"reconnect_after_ms"`
+}
+
+// Draining returns whether the hub is shutting down and no longer accepts connections
+func (h *Hub) Draining() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.draining
+}
+
+// Shutdown drains the hub and stops it: new connections are refused, every client is sent a
+// shutdown message, and connections still open after the grace period (or when ctx is done)
+// are closed with a normal close code
+func (h *Hub) Shutdown(ctx context.Context) {
+	h.mu.Lock()
+	if h.draining {
+		h.mu.Unlock()
+		return
+	}
+	h.draining = true
+	h.mu.Unlock()
+
+	connections := h.registry.GetAll()
+	logger.Info("Draining WebSocket hub",
+		logger.Int("connections", len(connections)),
+		logger.Duration("grace_period", h.config.ShutdownGracePeriod),
+	)
+
+	for _, conn := range connections {
+		if err := conn.SendShutdown(h.config.ShutdownReconnectAfter); err != nil {
+			logger.Debug("Failed to send shutdown message",
+				logger.ErrorField(err),
+				logger.String("connection_id", conn.ID),
+			)
+		}
+	}
+
+	h.waitForDrain(ctx)
+
+	// Close remaining connections cleanly; the write pumps exit when the hub stops
+	deadline := time.Now().Add(h.config.WriteTimeout)
+	closeMessage := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "server shutting down")
+	for _, conn := range h.registry.GetAll() {
+		if err := conn.Conn.WriteControl(websocket.CloseMessage, closeMessage, deadline); err != nil {
+			logger.Debug("Failed to send close message",
+				logger.ErrorField(err),
+				logger.String("connection_id", conn.ID),
+			)
+		}
+	}
+
+	h.Stop()
+}
+
+// waitForDrain waits until the grace period elapses, every client has disconnected or ctx is done
+func (h *Hub) waitForDrain(ctx context.Context) {
+	grace := time.NewTimer(h.config.ShutdownGracePeriod)
+	defer grace.Stop()
+
+	poll := time.NewTicker(shutdownDrainPoll)
+	defer poll.Stop()
+
+	for h.registry.Count() > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-grace.C:
+			return
+		case <-poll.C:
+		}
+	}
+}
+
+// SendShutdown queues the shutdown message for the connection
+func (c *Connection) SendShutdown(reconnectAfter time.Duration) error {
+	data, err := json.Marshal(ShutdownMessage{
+		Type:             string(MessageTypeShutdown),
+		ReconnectAfterMs: reconnectAfter.Milliseconds(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.enqueue(data)
+}
@@ -0,0 +1,147 @@
+package wsgateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+// newShutdownTestServer starts a hub and a server registering upgraded connections with it
+func newShutdownTestServer(t *testing.T, gracePeriod time.Duration) (*Hub, *httptest.Server) {
+	hub := NewHub(config.WSGatewayConfig{
+		ReadTimeout:            time.Minute,
+		WriteTimeout:           time.Second,
+		PingInterval:           time.Minute,
+		ShutdownGracePeriod:    gracePeriod,
+		ShutdownReconnectAfter: 1500 * time.Millisecond,
+	}, storage.NewMockRedisClient(), "alerts.filtered", "ws-gateway")
+	if err := hub.Start(); err != nil {
+		t.Fatalf("Failed to start hub: %v", err)
+	}
+
+	connections := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hub.Draining() {
+			http.Error(w, "Server shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Logf("Upgrade error: %v", err)
+			return
+		}
+		connections++
+		hub.Register(NewConnection(fmt.Sprintf("conn-%d", connections), "user-123", conn))
+	}))
+	t.Cleanup(server.Close)
+
+	return hub, server
+}
+
+// dialShutdownTestServer connects a client and waits until the hub has registered it
+func dialShutdownTestServer(t *testing.T, hub *Hub, server *httptest.Server, want int) *websocket.Conn {
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.registry.Count() < want {
+		if time.Now().After(deadline) {
+			t.Fatalf("Connection was not registered")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return client
+}
+
+// readShutdownMessage reads the next frame and requires it to be the shutdown message
+func readShutdownMessage(t *testing.T, client *websocket.Conn) ShutdownMessage {
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := client.ReadMessage()
+	if err != nil {
+		t.Fatalf("Expected the shutdown message, got error: %v", err)
+	}
+
+	var message ShutdownMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		t.Fatalf("Failed to unmarshal message %s: %v", data, err)
+	}
+	if message.Type != string(MessageTypeShutdown) {
+		t.Fatalf("Expected a %s message, got %s", MessageTypeShutdown, data)
+	}
+	return message
+}
+
+func TestHub_Shutdown_NotifiesAndClosesClients(t *testing.T) {
+	hub, server := newShutdownTestServer(t, 200*time.Millisecond)
+	clients := []*websocket.Conn{
+		dialShutdownTestServer(t, hub, server, 1),
+		dialShutdownTestServer(t, hub, server, 2),
+	}
+
+	done := make(chan struct{})
+	go func() {
+		hub.Shutdown(context.Background())
+		close(done)
+	}()
+
+	for i, client := range clients {
+		message := readShutdownMessage(t, client)
+		if message.ReconnectAfterMs != 1500 {
+			t.Errorf("Client %d: expected reconnect_after_ms 1500, got %d", i, message.ReconnectAfterMs)
+		}
+
+		// The connection then closes with a normal close code
+		client.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, _, err := client.ReadMessage()
+		var closeErr *websocket.CloseError
+		if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseNormalClosure {
+			t.Errorf("Client %d: expected a normal closure, got %v", i, err)
+		}
+	}
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Shutdown did not return")
+	}
+
+	// New connections are refused once draining
+	_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected new connections to be refused with 503, got %v", err)
+	}
+}
+
+func TestHub_Shutdown_EndsWhenClientsDisconnect(t *testing.T) {
+	hub, server := newShutdownTestServer(t, time.Minute)
+	client := dialShutdownTestServer(t, hub, server, 1)
+
+	done := make(chan struct{})
+	go func() {
+		hub.Shutdown(context.Background())
+		close(done)
+	}()
+
+	// The client reconnects elsewhere as soon as it is notified
+	readShutdownMessage(t, client)
+	client.Close()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected shutdown to end before the grace period once every client disconnected")
+	}
+}
@@ -0,0 +1,91 @@
+package wsgateway
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConnection_SubscribeSymbols_Unlimited(t *testing.T) {
+	conn := NewConnection("test-conn", "user-123", nil)
+
+	subscribed, rejected := conn.SubscribeSymbols([]string{"AAPL", "MSFT", "GOOGL"})
+	if len(subscribed) != 3 || len(rejected) != 0 {
+		t.Errorf("Expected all symbols subscribed without a limit, got %v subscribed, %v rejected", subscribed, rejected)
+	}
+}
+
+func TestConnection_SubscribeSymbols_RejectPolicy(t *testing.T) {
+	conn := NewConnection("test-conn", "user-123", nil)
+	conn.SetSubscriptionLimits(SubscriptionLimits{MaxSymbols: 3, Policy: SubscriptionLimitReject})
+
+	conn.SubscribeSymbols([]string{"AAPL", "MSFT"})
+
+	subscribed, rejected := conn.SubscribeSymbols([]string{"MSFT", "GOOGL", "AMZN"})
+	if len(rejected) != 2 {
+		t.Fatalf("Expected 2 rejected symbols, got %v", rejected)
+	}
+	if len(subscribed) != 1 || subscribed[0] != "MSFT" {
+		t.Errorf("Expected only the already subscribed symbol to be reported, got %v", subscribed)
+	}
+	if conn.IsSubscribed("GOOGL") || conn.IsSubscribed("AMZN") {
+		t.Error("Expected rejected request to subscribe no new symbols")
+	}
+
+	// A request within the limit still succeeds
+	if _, rejected := conn.SubscribeSymbols([]string{"GOOGL"}); len(rejected) != 0 {
+		t.Errorf("Expected subscribe within the limit to succeed, got %v rejected", rejected)
+	}
+}
+
+func TestConnection_SubscribeSymbols_TruncatePolicy(t *testing.T) {
+	conn := NewConnection("test-conn", "user-123", nil)
+	conn.SetSubscriptionLimits(SubscriptionLimits{MaxSymbols: 3, Policy: SubscriptionLimitTruncate})
+
+	conn.SubscribeSymbols([]string{"AAPL"})
+
+	subscribed, rejected := conn.SubscribeSymbols([]string{"MSFT", "GOOGL", "AMZN", "TSLA"})
+	if len(subscribed) != 2 || subscribed[0] != "MSFT" || subscribed[1] != "GOOGL" {
+		t.Errorf("Expected first 2 symbols subscribed, got %v", subscribed)
+	}
+	if len(rejected) != 2 || rejected[0] != "AMZN" || rejected[1] != "TSLA" {
+		t.Errorf("Expected remaining symbols rejected, got %v", rejected)
+	}
+	if len(conn.Subscriptions) != 3 {
+		t.Errorf("Expected 3 subscriptions, got %d", len(conn.Subscriptions))
+	}
+
+	// At the limit, every new symbol is rejected
+	if _, rejected := conn.SubscribeSymbols([]string{"NFLX"}); len(rejected) != 1 {
+		t.Errorf("Expected new symbol rejected at the limit, got %v", rejected)
+	}
+}
+
+func TestProtocol_SubscribeBeyondLimitSendsError(t *testing.T) {
+	conn := NewConnection("test-conn", "user-123", nil)
+	conn.SetSubscriptionLimits(SubscriptionLimits{MaxSymbols: 2, Policy: SubscriptionLimitReject})
+
+	err := conn.HandleClientMessage(&ClientMessage{
+		Type:    string(MessageTypeSubscribe),
+		Symbols: []string{"AAPL", "MSFT", "GOOGL"},
+	})
+	if err != nil {
+		t.Fatalf("HandleClientMessage() error = %v", err)
+	}
+
+	select {
+	case data := <-conn.Send:
+		var frame map[string]interface{}
+		if err := json.Unmarshal(data, &frame); err != nil {
+			t.Fatalf("Failed to decode frame: %v", err)
+		}
+		if frame["type"] != "error" || frame["code"] != "subscription_limit_exceeded" {
+			t.Errorf("Expected subscription_limit_exceeded error frame, got %v", frame)
+		}
+	default:
+		t.Fatal("Expected an error frame")
+	}
+
+	if len(conn.Subscriptions) != 0 {
+		t.Errorf("Expected no subscriptions after rejected request, got %d", len(conn.Subscriptions))
+	}
+}
@@ -0,0 +1,202 @@
+package wsgateway
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/config"
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+	"github.com/mohamedkhairy/stock-scanner/internal/storage"
+)
+
+// drainFrames decodes every frame queued for the connection
+func drainFrames(t *testing.T, conn *Connection) []map[string]interface{} {
+	t.Helper()
+	var frames []map[string]interface{}
+	for len(conn.Send) > 0 {
+		var frame map[string]interface{}
+		if err := json.Unmarshal(<-conn.Send, &frame); err != nil {
+			t.Fatalf("Failed to decode frame: %v", err)
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+// alertSymbols returns the symbols of the alert frames queued for the connection
+func alertSymbols(t *testing.T, conn *Connection) []string {
+	t.Helper()
+	var symbols []string
+	for _, frame := range drainFrames(t, conn) {
+		if frame["type"] != "alert" {
+			continue
+		}
+		symbols = append(symbols, frame["data"].(map[string]interface{})["symbol"].(string))
+	}
+	return symbols
+}
+
+func newSubscriptionTestHub(cfg config.WSGatewayConfig, users ...string) (*Hub, []*Connection) {
+	hub := NewHub(cfg, storage.NewMockRedisClient(), "alerts.filtered", "ws-gateway")
+	var conns []*Connection
+	for _, user := range users {
+		conn := NewConnection("conn-"+user, user, nil)
+		hub.configureConnection(conn)
+		hub.registry.Add(conn)
+		conns = append(conns, conn)
+	}
+	return hub, conns
+}
+
+func broadcastSymbols(hub *Hub, symbols ...string) {
+	for _, symbol := range symbols {
+		hub.broadcastAlert(&models.Alert{ID: "alert-" + symbol, RuleID: "rule-1", Symbol: symbol})
+	}
+}
+
+func TestHub_BroadcastAlert_DisjointSubscriptions(t *testing.T) {
+	hub, conns := newSubscriptionTestHub(config.WSGatewayConfig{}, "user-1", "user-2")
+	tech, autos := conns[0], conns[1]
+
+	for conn, symbols := range map[*Connection][]string{tech: {"AAPL", "MSFT"}, autos: {"TSLA", "F"}} {
+		if err := conn.HandleClientMessage(&ClientMessage{Type: string(MessageTypeSubscribe), Symbols: symbols}); err != nil {
+			t.Fatalf("HandleClientMessage() error = %v", err)
+		}
+		frames := drainFrames(t, conn)
+		if len(frames) != 1 || frames[0]["type"] != "success" {
+			t.Errorf("Expected a success frame for %s, got %v", conn.UserID, frames)
+		}
+	}
+
+	broadcastSymbols(hub, "AAPL", "TSLA", "MSFT", "GOOGL", "F")
+
+	if got := alertSymbols(t, tech); len(got) != 2 || got[0] != "AAPL" || got[1] != "MSFT" {
+		t.Errorf("Expected AAPL and MSFT alerts, got %v", got)
+	}
+	if got := alertSymbols(t, autos); len(got) != 2 || got[0] != "TSLA" || got[1] != "F" {
+		t.Errorf("Expected TSLA and F alerts, got %v", got)
+	}
+
+	// Unsubscribing stops the symbol's alerts
+	if err := autos.HandleClientMessage(&ClientMessage{Type: string(MessageTypeUnsubscribe), Symbol: "TSLA"}); err != nil {
+		t.Fatalf("HandleClientMessage() error = %v", err)
+	}
+	drainFrames(t, autos)
+
+	broadcastSymbols(hub, "TSLA", "F")
+	if got := alertSymbols(t, autos); len(got) != 1 || got[0] != "F" {
+		t.Errorf("Expected only F after unsubscribing TSLA, got %v", got)
+	}
+}
+
+func TestHub_BroadcastAlert_SubscribeAll(t *testing.T) {
+	hub, conns := newSubscriptionTestHub(config.WSGatewayConfig{UnsubscribedAlerts: UnsubscribedAlertsNone}, "user-1")
+	conn := conns[0]
+
+	conn.HandleClientMessage(&ClientMessage{Type: string(MessageTypeSubscribe), Symbol: AllSymbols})
+	drainFrames(t, conn)
+
+	broadcastSymbols(hub, "AAPL", "TSLA")
+	if got := alertSymbols(t, conn); len(got) != 2 {
+		t.Errorf("Expected every alert when subscribed to all, got %v", got)
+	}
+}
+
+func TestHub_BroadcastAlert_UnsubscribedPolicy(t *testing.T) {
+	hub, conns := newSubscriptionTestHub(config.WSGatewayConfig{}, "user-1")
+	broadcastSymbols(hub, "AAPL")
+	if got := alertSymbols(t, conns[0]); len(got) != 1 {
+		t.Errorf("Expected alerts before subscribing by default, got %v", got)
+	}
+
+	hub, conns = newSubscriptionTestHub(config.WSGatewayConfig{UnsubscribedAlerts: UnsubscribedAlertsNone}, "user-1")
+	broadcastSymbols(hub, "AAPL")
+	if got := alertSymbols(t, conns[0]); len(got) != 0 {
+		t.Errorf("Expected no alerts before subscribing, got %v", got)
+	}
+
+	conns[0].HandleClientMessage(&ClientMessage{Type: string(MessageTypeSubscribe), Symbol: "AAPL"})
+	drainFrames(t, conns[0])
+	broadcastSymbols(hub, "AAPL")
+	if got := alertSymbols(t, conns[0]); len(got) != 1 {
+		t.Errorf("Expected alerts once subscribed, got %v", got)
+	}
+}
+
+func TestProtocol_SubscribeUnknownSymbol(t *testing.T) {
+	_, conns := newSubscriptionTestHub(config.WSGatewayConfig{SymbolUniverse: []string{"AAPL", "MSFT"}}, "user-1")
+	conn := conns[0]
+
+	// A single unknown symbol is rejected outright
+	conn.HandleClientMessage(&ClientMessage{Type: string(MessageTypeSubscribe), Symbol: "ZZZZ"})
+	frames := drainFrames(t, conn)
+	if len(frames) != 1 || frames[0]["type"] != "error" || frames[0]["code"] != "unknown_symbol" {
+		t.Errorf("Expected an unknown_symbol error frame, got %v", frames)
+	}
+
+	// Known symbols of a batch are subscribed, unknown ones reported as rejected
+	conn.HandleClientMessage(&ClientMessage{Type: string(MessageTypeSubscribe), Symbols: []string{"AAPL", "ZZZZ", AllSymbols}})
+	frames = drainFrames(t, conn)
+	if len(frames) != 2 || frames[0]["code"] != "unknown_symbol" || frames[1]["type"] != "success" {
+		t.Fatalf("Expected an unknown_symbol error and a success frame, got %v", frames)
+	}
+	data := frames[1]["data"].(map[string]interface{})["data"].(map[string]interface{})
+	if rejected := data["rejected"].([]interface{}); len(rejected) != 1 || rejected[0] != "ZZZZ" {
+		t.Errorf("Expected ZZZZ rejected, got %v", rejected)
+	}
+
+	if !conn.IsSubscribed("AAPL") || !conn.IsSubscribed(AllSymbols) || conn.IsSubscribed("ZZZZ") {
+		t.Errorf("Unexpected subscriptions: %v", conn.Subscriptions)
+	}
+}
+
+func TestHub_SetSymbolUniverse(t *testing.T) {
+	hub, conns := newSubscriptionTestHub(config.WSGatewayConfig{SymbolUniverse: []string{"AAPL"}}, "user-1")
+
+	// Symbols added at runtime become subscribable on existing connections
+	hub.SetSymbolUniverse([]string{"AAPL", "NVDA"})
+	known, unknown := conns[0].SplitUnknownSymbols([]string{"NVDA", "ZZZZ"})
+	if len(known) != 1 || known[0] != "NVDA" || len(unknown) != 1 || unknown[0] != "ZZZZ" {
+		t.Errorf("Expected NVDA known and ZZZZ unknown, got %v and %v", known, unknown)
+	}
+
+	// An empty universe accepts any symbol
+	hub.SetSymbolUniverse(nil)
+	if _, unknown := conns[0].SplitUnknownSymbols([]string{"ZZZZ"}); len(unknown) != 0 {
+		t.Errorf("Expected any symbol to be known, got %v unknown", unknown)
+	}
+}
+
+func TestHub_BroadcastAlert_Breadth(t *testing.T) {
+	hub, conns := newSubscriptionTestHub(config.WSGatewayConfig{SymbolUniverse: []string{"AAPL", "TSLA"}}, "user-1", "user-2", "user-3")
+	tech, autos, market := conns[0], conns[1], conns[2]
+
+	tech.HandleClientMessage(&ClientMessage{Type: string(MessageTypeSubscribe), Symbol: "AAPL"})
+	autos.HandleClientMessage(&ClientMessage{Type: string(MessageTypeSubscribe), Symbol: "TSLA"})
+	// The breadth alert symbol is subscribable whatever the symbol universe
+	market.HandleClientMessage(&ClientMessage{Type: string(MessageTypeSubscribe), Symbol: models.BreadthAlertSymbol})
+	for _, conn := range conns {
+		if frames := drainFrames(t, conn); len(frames) != 1 || frames[0]["type"] != "success" {
+			t.Fatalf("Expected a success frame for %s, got %v", conn.UserID, frames)
+		}
+	}
+
+	// Decoded from the stream, breadth_symbols is a []interface{}
+	hub.broadcastAlert(&models.Alert{
+		ID:       "breadth-1",
+		RuleID:   "rule-system",
+		Symbol:   models.BreadthAlertSymbol,
+		Type:     models.AlertTypeBreadth,
+		Metadata: map[string]interface{}{"breadth_symbols": []interface{}{"AAPL", "MSFT"}},
+	})
+
+	if got := alertSymbols(t, tech); len(got) != 1 || got[0] != models.BreadthAlertSymbol {
+		t.Errorf("Expected the breadth alert for a subscriber of a covered symbol, got %v", got)
+	}
+	if got := alertSymbols(t, autos); len(got) != 0 {
+		t.Errorf("Expected no breadth alert for a subscriber of other symbols, got %v", got)
+	}
+	if got := alertSymbols(t, market); len(got) != 1 {
+		t.Errorf("Expected the breadth alert for a MARKET subscriber, got %v", got)
+	}
+}
@@ -0,0 +1,139 @@
+package indicator
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// BollingerBandType selects which Bollinger Bands value a calculator produces
+type BollingerBandType string
+
+// Bollinger Bands values
+const (
+	BollingerUpper    BollingerBandType = "upper"  // Middle band + multiplier * standard deviation
+	BollingerMiddle   BollingerBandType = "middle" // Simple moving average of closes
+	BollingerLower    BollingerBandType = "lower"  // Middle band - multiplier * standard deviation
+	BollingerPercentB BollingerBandType = "pctb"   // Position of the close between the bands (0 = lower, 1 = upper)
+)
+
+// BollingerBand calculates one Bollinger Bands value over the closes of the last period
+// finalized bars, using the population standard deviation. It is not ready until period
+// bars have been processed.
+type BollingerBand struct {
+	band       BollingerBandType
+	period     int
+	multiplier float64
+	name       string
+	closes     []float64
+	processed  int
+}
+
+// NewBollingerBand creates a Bollinger Bands calculator named bb_<band>_<period>
+// (e.g. bb_upper_20)
+func NewBollingerBand(band BollingerBandType, period int, multiplier float64) (*BollingerBand, error) {
+	switch band {
+	case BollingerUpper, BollingerMiddle, BollingerLower, BollingerPercentB:
+	default:
+		return nil, fmt.Errorf("invalid bollinger band %q", band)
+	}
+	if period < 2 {
+		return nil, fmt.Errorf("bollinger band period must be at least 2, got %d", period)
+	}
+	if multiplier <= 0 {
+		return nil, fmt.Errorf("bollinger band multiplier must be positive, got %v", multiplier)
+	}
+
+	return &BollingerBand{
+		band:       band,
+		period:     period,
+		multiplier: multiplier,
+		name:       fmt.Sprintf("bb_%s_%d", band, period),
+		closes:     make([]float64, 0, period),
+	}, nil
+}
+
+// Name returns the indicator name
+func (b *BollingerBand) Name() string {
+	return b.name
+}
+
+// Update processes a new bar and updates the band
+func (b *BollingerBand) Update(bar *models.Bar1m) (float64, error) {
+	if bar == nil {
+		return 0, fmt.Errorf("bar cannot be nil")
+	}
+
+	if len(b.closes) == b.period {
+		copy(b.closes, b.closes[1:])
+		b.closes = b.closes[:b.period-1]
+	}
+	b.closes = append(b.closes, bar.Close)
+	b.processed++
+
+	if !b.IsReady() {
+		return 0, nil
+	}
+	return b.calculate(), nil
+}
+
+// calculate computes the band over the current window
+func (b *BollingerBand) calculate() float64 {
+	sum := 0.0
+	for _, price := range b.closes {
+		sum += price
+	}
+	middle := sum / float64(len(b.closes))
+
+	variance := 0.0
+	for _, price := range b.closes {
+		variance += (price - middle) * (price - middle)
+	}
+	width := b.multiplier * math.Sqrt(variance/float64(len(b.closes)))
+
+	switch b.band {
+	case BollingerUpper:
+		return middle + width
+	case BollingerLower:
+		return middle - width
+	case BollingerPercentB:
+		if width == 0 {
+			// Flat prices: the close sits on the middle band
+			return 0.5
+		}
+		last := b.closes[len(b.closes)-1]
+		return (last - (middle - width)) / (2 * width)
+	default:
+		return middle
+	}
+}
+
+// Value returns the current band value
+func (b *BollingerBand) Value() (float64, error) {
+	if !b.IsReady() {
+		return 0, fmt.Errorf("bollinger band not ready: need %d bars, have %d", b.period, len(b.closes))
+	}
+	return b.calculate(), nil
+}
+
+// Reset clears the band state
+func (b *BollingerBand) Reset() {
+	b.closes = b.closes[:0]
+	b.processed = 0
+}
+
+// IsReady returns true once period bars have been processed
+func (b *BollingerBand) IsReady() bool {
+	return len(b.closes) == b.period
+}
+
+// WindowSize returns the number of bars required
+func (b *BollingerBand) WindowSize() int {
+	return b.period
+}
+
+// BarsProcessed returns the number of bars processed
+func (b *BollingerBand) BarsProcessed() int {
+	return b.processed
+}
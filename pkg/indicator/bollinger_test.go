@@ -0,0 +1,111 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+func TestBollingerBand_NewBollingerBand(t *testing.T) {
+	bb, err := NewBollingerBand(BollingerUpper, 20, 2.0)
+	if err != nil {
+		t.Fatalf("Failed to create BollingerBand: %v", err)
+	}
+	if bb.Name() != "bb_upper_20" {
+		t.Errorf("Expected name 'bb_upper_20', got '%s'", bb.Name())
+	}
+
+	pctb, _ := NewBollingerBand(BollingerPercentB, 20, 2.0)
+	if pctb.Name() != "bb_pctb_20" {
+		t.Errorf("Expected name 'bb_pctb_20', got '%s'", pctb.Name())
+	}
+
+	if _, err := NewBollingerBand("outer", 20, 2.0); err == nil {
+		t.Error("Expected error for unknown band")
+	}
+	if _, err := NewBollingerBand(BollingerUpper, 1, 2.0); err == nil {
+		t.Error("Expected error for period below 2")
+	}
+	if _, err := NewBollingerBand(BollingerUpper, 20, 0); err == nil {
+		t.Error("Expected error for non-positive multiplier")
+	}
+}
+
+func TestBollingerBand_KnownSeries(t *testing.T) {
+	bands := make(map[BollingerBandType]*BollingerBand)
+	for _, band := range []BollingerBandType{BollingerUpper, BollingerMiddle, BollingerLower, BollingerPercentB} {
+		bands[band], _ = NewBollingerBand(band, 5, 2.0)
+	}
+
+	update := func(close float64, i int) {
+		bar := &models.Bar1m{Symbol: "AAPL", Timestamp: time.Date(2024, 1, 2, 14, 30+i, 0, 0, time.UTC), Close: close}
+		for _, bb := range bands {
+			if _, err := bb.Update(bar); err != nil {
+				t.Fatalf("Update failed: %v", err)
+			}
+		}
+	}
+
+	// Warm-up: no value until 5 bars
+	for i, close := range []float64{1, 2, 3, 4} {
+		update(close, i)
+	}
+	for band, bb := range bands {
+		if bb.IsReady() {
+			t.Errorf("%s should not be ready after 4 bars", band)
+		}
+		if _, err := bb.Value(); err == nil {
+			t.Errorf("%s: expected an error before warm-up completes", band)
+		}
+	}
+
+	// Closes 1..5: mean 3, population variance (4+1+0+1+4)/5 = 2, std dev sqrt(2)
+	// upper = 3 + 2*sqrt(2) = 5.828427, lower = 3 - 2*sqrt(2) = 0.171573
+	// %B = (5 - 0.171573) / (4*sqrt(2)) = 0.853553
+	update(5, 4)
+	expected := map[BollingerBandType]float64{
+		BollingerUpper:    5.828427,
+		BollingerMiddle:   3.0,
+		BollingerLower:    0.171573,
+		BollingerPercentB: 0.853553,
+	}
+	for band, want := range expected {
+		got, err := bands[band].Value()
+		if err != nil {
+			t.Fatalf("%s: Value() error = %v", band, err)
+		}
+		if math.Abs(got-want) > 1e-6 {
+			t.Errorf("%s: expected %.6f, got %.6f", band, want, got)
+		}
+	}
+
+	// The window slides: closes 2..6 have mean 4 and the same spread
+	update(6, 5)
+	if got, _ := bands[BollingerUpper].Value(); math.Abs(got-6.828427) > 1e-6 {
+		t.Errorf("upper: expected 6.828427 after sliding, got %.6f", got)
+	}
+	// A sharp drop puts the close just above the lower band
+	// Closes 3,4,5,6,0: mean 3.6, variance (0.36+0.16+1.96+5.76+12.96)/5 = 4.24, std dev 2.059126
+	// lower = 3.6 - 4.118252 = -0.518252, %B = 0.518252 / 8.236504 = 0.062921
+	update(0, 6)
+	if got, _ := bands[BollingerPercentB].Value(); math.Abs(got-0.062921) > 1e-6 {
+		t.Errorf("pctb: expected 0.062921, got %.6f", got)
+	}
+
+	bands[BollingerMiddle].Reset()
+	if bands[BollingerMiddle].IsReady() || bands[BollingerMiddle].BarsProcessed() != 0 {
+		t.Error("Expected Reset to clear the window")
+	}
+}
+
+func TestBollingerBand_FlatPrices(t *testing.T) {
+	bb, _ := NewBollingerBand(BollingerPercentB, 3, 2.0)
+	for i := 0; i < 3; i++ {
+		bb.Update(&models.Bar1m{Symbol: "AAPL", Timestamp: time.Date(2024, 1, 2, 14, 30+i, 0, 0, time.UTC), Close: 100})
+	}
+	if got, _ := bb.Value(); got != 0.5 {
+		t.Errorf("Expected %%B 0.5 for flat prices, got %v", got)
+	}
+}
@@ -0,0 +1,258 @@
+package indicator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// MACDOutput selects which MACD value a calculator produces
+type MACDOutput string
+
+// MACD values
+const (
+	MACDLine      MACDOutput = "macd"        // Fast EMA - slow EMA of closes
+	MACDSignal    MACDOutput = "macd_signal" // EMA of the MACD line
+	MACDHistogram MACDOutput = "macd_hist"   // MACD line - signal line
+)
+
+// MACDParams holds the MACD EMA periods
+type MACDParams struct {
+	Fast   int
+	Slow   int
+	Signal int
+}
+
+// DefaultMACDParams returns the standard 12/26/9 periods
+func DefaultMACDParams() MACDParams {
+	return MACDParams{Fast: 12, Slow: 26, Signal: 9}
+}
+
+// ParseMACDParams parses MACD periods in the format "FAST,SLOW,SIGNAL" (e.g. "12,26,9").
+// An empty spec returns the defaults.
+func ParseMACDParams(spec string) (MACDParams, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return DefaultMACDParams(), nil
+	}
+
+	parts := strings.Split(spec, ",")
+	if len(parts) != 3 {
+		return MACDParams{}, fmt.Errorf("invalid MACD periods %q (expected FAST,SLOW,SIGNAL)", spec)
+	}
+	periods := make([]int, len(parts))
+	for i, part := range parts {
+		period, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return MACDParams{}, fmt.Errorf("invalid MACD period %q: %w", part, err)
+		}
+		periods[i] = period
+	}
+
+	params := MACDParams{Fast: periods[0], Slow: periods[1], Signal: periods[2]}
+	if err := params.Validate(); err != nil {
+		return MACDParams{}, err
+	}
+	return params, nil
+}
+
+// Validate checks the periods are positive and the fast EMA is shorter than the slow one
+func (p MACDParams) Validate() error {
+	if p.Fast < 1 || p.Slow < 1 || p.Signal < 1 {
+		return fmt.Errorf("MACD periods must be positive, got %d,%d,%d", p.Fast, p.Slow, p.Signal)
+	}
+	if p.Fast >= p.Slow {
+		return fmt.Errorf("MACD fast period %d must be less than slow period %d", p.Fast, p.Slow)
+	}
+	return nil
+}
+
+// ema is an exponential moving average updated one value at a time. It is seeded with the
+// simple average of its first period values, so it is ready after period values.
+type ema struct {
+	period int
+	alpha  float64
+	value  float64
+	count  int
+	sum    float64 // Sum of the values seen during warm-up
+}
+
+func newEMA(period int) ema {
+	return ema{period: period, alpha: 2.0 / float64(period+1)}
+}
+
+// update adds a value and returns whether the EMA is ready
+func (e *ema) update(value float64) bool {
+	e.count++
+	switch {
+	case e.count < e.period:
+		e.sum += value
+		return false
+	case e.count == e.period:
+		e.value = (e.sum + value) / float64(e.period)
+		e.sum = 0
+	default:
+		e.value += e.alpha * (value - e.value)
+	}
+	return true
+}
+
+func (e *ema) reset() {
+	e.value, e.count, e.sum = 0, 0, 0
+}
+
+// MACDSeries holds the EMA state behind the MACD line, signal and histogram. The calculators
+// of one series share it, so each bar advances the EMAs once however many outputs are
+// computed, at O(1) per bar however long the history. The line is ready after Slow bars, the
+// signal and histogram after Slow+Signal-1 bars.
+type MACDSeries struct {
+	params    MACDParams
+	fast      ema
+	slow      ema
+	signal    ema
+	line      float64
+	lineReady bool
+	processed int
+	last      *models.Bar1m // Last bar applied; the series' other calculators pass it again
+}
+
+// NewMACDSeries creates the shared state of a MACD line, signal and histogram
+func NewMACDSeries(params MACDParams) (*MACDSeries, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &MACDSeries{
+		params: params,
+		fast:   newEMA(params.Fast),
+		slow:   newEMA(params.Slow),
+		signal: newEMA(params.Signal),
+	}, nil
+}
+
+// Calculators returns the line, signal and histogram calculators of the series, by name
+func (s *MACDSeries) Calculators() map[string]Calculator {
+	calcs := make(map[string]Calculator, 3)
+	for _, output := range []MACDOutput{MACDLine, MACDSignal, MACDHistogram} {
+		calcs[string(output)] = &MACD{output: output, series: s}
+	}
+	return calcs
+}
+
+// update advances the EMAs with a bar, once per bar
+func (s *MACDSeries) update(bar *models.Bar1m) {
+	if bar == s.last {
+		return
+	}
+	s.last = bar
+
+	s.processed++
+	fastReady := s.fast.update(bar.Close)
+	slowReady := s.slow.update(bar.Close)
+	if fastReady && slowReady {
+		s.line = s.fast.value - s.slow.value
+		s.lineReady = true
+		s.signal.update(s.line)
+	}
+}
+
+// reset clears the EMA state
+func (s *MACDSeries) reset() {
+	s.fast.reset()
+	s.slow.reset()
+	s.signal.reset()
+	s.line = 0
+	s.lineReady = false
+	s.processed = 0
+	s.last = nil
+}
+
+// MACD calculates one MACD value from a MACDSeries
+type MACD struct {
+	output MACDOutput
+	series *MACDSeries
+}
+
+// NewMACD creates a MACD calculator named after its output (macd, macd_signal or macd_hist)
+// with a series of its own. Use MACDSeries.Calculators to compute several outputs.
+func NewMACD(output MACDOutput, params MACDParams) (*MACD, error) {
+	switch output {
+	case MACDLine, MACDSignal, MACDHistogram:
+	default:
+		return nil, fmt.Errorf("invalid MACD output %q", output)
+	}
+	series, err := NewMACDSeries(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MACD{output: output, series: series}, nil
+}
+
+// Name returns the indicator name
+func (m *MACD) Name() string {
+	return string(m.output)
+}
+
+// Update processes a new bar and advances the series' EMAs if no other calculator of the
+// series has yet
+func (m *MACD) Update(bar *models.Bar1m) (float64, error) {
+	if bar == nil {
+		return 0, fmt.Errorf("bar cannot be nil")
+	}
+
+	m.series.update(bar)
+
+	if !m.IsReady() {
+		return 0, nil
+	}
+	return m.current(), nil
+}
+
+// current returns the output value (the caller checks readiness)
+func (m *MACD) current() float64 {
+	switch m.output {
+	case MACDSignal:
+		return m.series.signal.value
+	case MACDHistogram:
+		return m.series.line - m.series.signal.value
+	default:
+		return m.series.line
+	}
+}
+
+// Value returns the current MACD value
+func (m *MACD) Value() (float64, error) {
+	if !m.IsReady() {
+		return 0, fmt.Errorf("%s not ready: need %d bars, have %d", m.output, m.WindowSize(), m.series.processed)
+	}
+	return m.current(), nil
+}
+
+// Reset clears the series' EMA state
+func (m *MACD) Reset() {
+	m.series.reset()
+}
+
+// IsReady returns true once the output has enough bars
+func (m *MACD) IsReady() bool {
+	if m.output == MACDLine {
+		return m.series.lineReady
+	}
+	return m.series.signal.count >= m.series.signal.period
+}
+
+// WindowSize returns the number of bars needed before the output is ready
+func (m *MACD) WindowSize() int {
+	if m.output == MACDLine {
+		return m.series.params.Slow
+	}
+	return m.series.params.Slow + m.series.params.Signal - 1
+}
+
+// BarsProcessed returns the number of bars processed
+func (m *MACD) BarsProcessed() int {
+	return m.series.processed
+}
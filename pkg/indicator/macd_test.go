@@ -0,0 +1,137 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mohamedkhairy/stock-scanner/internal/models"
+)
+
+// macdSeries is a deterministic price series with trend and oscillation
+func macdSeries(n int) []float64 {
+	closes := make([]float64, n)
+	for i := range closes {
+		closes[i] = 100 + 0.3*float64(i) + 5*math.Sin(float64(i)/4)
+	}
+	return closes
+}
+
+// batchEMA computes an SMA-seeded EMA over the whole series from scratch (NaN before warm-up)
+func batchEMA(values []float64, period int) []float64 {
+	result := make([]float64, len(values))
+	alpha := 2.0 / float64(period+1)
+	for i := range values {
+		if i < period-1 {
+			result[i] = math.NaN()
+			continue
+		}
+		sum := 0.0
+		for _, v := range values[:period] {
+			sum += v
+		}
+		ema := sum / float64(period)
+		for _, v := range values[period : i+1] {
+			ema = alpha*v + (1-alpha)*ema
+		}
+		result[i] = ema
+	}
+	return result
+}
+
+func TestMACD_IncrementalMatchesBatch(t *testing.T) {
+	params := DefaultMACDParams()
+	closes := macdSeries(120)
+
+	// Batch: MACD line from full-series EMAs, signal as an EMA over the valid line values
+	fast := batchEMA(closes, params.Fast)
+	slow := batchEMA(closes, params.Slow)
+	lineStart := params.Slow - 1
+	line := make([]float64, len(closes)-lineStart)
+	for i := range line {
+		line[i] = fast[lineStart+i] - slow[lineStart+i]
+	}
+	signal := batchEMA(line, params.Signal)
+
+	// The three outputs share one series; each bar reaches it once per output
+	series, err := NewMACDSeries(params)
+	if err != nil {
+		t.Fatalf("NewMACDSeries() error = %v", err)
+	}
+	calcs := make(map[MACDOutput]*MACD)
+	for name, calc := range series.Calculators() {
+		calcs[MACDOutput(name)] = calc.(*MACD)
+	}
+
+	base := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+	for i, close := range closes {
+		bar := &models.Bar1m{Symbol: "AAPL", Timestamp: base.Add(time.Duration(i) * time.Minute), Close: close}
+		for _, calc := range calcs {
+			if _, err := calc.Update(bar); err != nil {
+				t.Fatalf("Update failed: %v", err)
+			}
+		}
+
+		if ready := calcs[MACDLine].IsReady(); ready != (i >= lineStart) {
+			t.Fatalf("bar %d: macd ready = %v", i, ready)
+		}
+		signalStart := lineStart + params.Signal - 1
+		if ready := calcs[MACDSignal].IsReady(); ready != (i >= signalStart) {
+			t.Fatalf("bar %d: macd_signal ready = %v", i, ready)
+		}
+
+		if i >= lineStart {
+			got, _ := calcs[MACDLine].Value()
+			if want := line[i-lineStart]; math.Abs(got-want) > 1e-9 {
+				t.Errorf("bar %d: macd = %v, batch = %v", i, got, want)
+			}
+		}
+		if i >= signalStart {
+			wantSignal := signal[i-lineStart]
+			gotSignal, _ := calcs[MACDSignal].Value()
+			if math.Abs(gotSignal-wantSignal) > 1e-9 {
+				t.Errorf("bar %d: macd_signal = %v, batch = %v", i, gotSignal, wantSignal)
+			}
+			gotHist, _ := calcs[MACDHistogram].Value()
+			if want := line[i-lineStart] - wantSignal; math.Abs(gotHist-want) > 1e-9 {
+				t.Errorf("bar %d: macd_hist = %v, batch = %v", i, gotHist, want)
+			}
+		}
+	}
+
+	if processed := calcs[MACDLine].BarsProcessed(); processed != len(closes) {
+		t.Errorf("Expected the shared EMAs to advance once per bar, got %d updates for %d bars", processed, len(closes))
+	}
+
+	calcs[MACDHistogram].Reset()
+	if calcs[MACDHistogram].IsReady() || calcs[MACDHistogram].BarsProcessed() != 0 {
+		t.Error("Expected Reset to clear the EMA state")
+	}
+	if _, err := calcs[MACDHistogram].Value(); err == nil {
+		t.Error("Expected an error after Reset")
+	}
+}
+
+func TestParseMACDParams(t *testing.T) {
+	params, err := ParseMACDParams(" 5, 35 ,5")
+	if err != nil {
+		t.Fatalf("ParseMACDParams() error = %v", err)
+	}
+	if params != (MACDParams{Fast: 5, Slow: 35, Signal: 5}) {
+		t.Errorf("ParseMACDParams() = %+v, want 5/35/5", params)
+	}
+
+	if params, err := ParseMACDParams(""); err != nil || params != DefaultMACDParams() {
+		t.Errorf("ParseMACDParams(\"\") = %+v, %v, want defaults", params, err)
+	}
+
+	for _, spec := range []string{"12,26", "12,26,9,3", "a,26,9", "26,12,9", "12,12,9", "0,26,9", "12,26,0"} {
+		if _, err := ParseMACDParams(spec); err == nil {
+			t.Errorf("ParseMACDParams(%q) expected error", spec)
+		}
+	}
+
+	if _, err := NewMACD("macd_line", DefaultMACDParams()); err == nil {
+		t.Error("Expected error for unknown MACD output")
+	}
+}
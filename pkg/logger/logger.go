@@ -66,6 +66,11 @@ func Get() *zap.Logger {
 	return globalLogger
 }
 
+// Set replaces the global logger (useful for tests capturing log output)
+func Set(logger *zap.Logger) {
+	globalLogger = logger
+}
+
 // Sync flushes any buffered log entries
 func Sync() error {
 	if globalLogger != nil {
@@ -83,6 +88,9 @@ func WithContext(ctx context.Context) *zap.Logger {
 	if spanID := ctx.Value("span_id"); spanID != nil {
 		logger = logger.With(zap.String("span_id", fmt.Sprintf("%v", spanID)))
 	}
+	if requestID := ctx.Value("request_id"); requestID != nil {
+		logger = logger.With(zap.String("request_id", fmt.Sprintf("%v", requestID)))
+	}
 	return logger
 }
 
@@ -24,6 +24,11 @@ func WithSpanID(ctx context.Context, spanID string) context.Context {
 	return context.WithValue(ctx, "span_id", spanID)
 }
 
+// WithRequestID adds a request ID to the context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, "request_id", requestID)
+}
+
 // GetTraceID retrieves the trace ID from context
 func GetTraceID(ctx context.Context) string {
 	if traceID := ctx.Value("trace_id"); traceID != nil {
@@ -32,6 +37,14 @@ func GetTraceID(ctx context.Context) string {
 	return ""
 }
 
+// GetRequestID retrieves the request ID from context
+func GetRequestID(ctx context.Context) string {
+	if requestID := ctx.Value("request_id"); requestID != nil {
+		return fmt.Sprintf("%v", requestID)
+	}
+	return ""
+}
+
 // GetSpanID retrieves the span ID from context
 func GetSpanID(ctx context.Context) string {
 	if spanID := ctx.Value("span_id"); spanID != nil {
@@ -0,0 +1,7 @@
+-- Migration: Add max_size to toplist_configs
+-- Description: Bounds the number of entries kept in each toplist ZSET
+-- NULL means the service default (TOPLIST_DEFAULT_MAX_SIZE) is used
+
+ALTER TABLE toplist_configs ADD COLUMN IF NOT EXISTS max_size INTEGER CHECK (max_size IS NULL OR max_size > 0);
+
+COMMENT ON COLUMN toplist_configs.max_size IS 'Maximum number of entries kept in the toplist ZSET (NULL = service default)';
@@ -0,0 +1,6 @@
+-- Migration: Add evaluate_on to rules
+-- Description: Controls whether a rule is evaluated on every tick or only after a bar is finalized
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS evaluate_on VARCHAR(20) NOT NULL DEFAULT 'tick' CHECK (evaluate_on IN ('tick', 'bar_close'));
+
+COMMENT ON COLUMN rules.evaluate_on IS 'When the rule is evaluated: tick (every scan cycle) or bar_close (only after a finalized bar)';
@@ -0,0 +1,16 @@
+-- Create alert_notes table for storing notes attached to alerts
+-- Notes are added by users while triaging alerts
+
+CREATE TABLE IF NOT EXISTS alert_notes (
+    id TEXT PRIMARY KEY,
+    alert_id TEXT NOT NULL,
+    author TEXT NOT NULL,
+    text TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Create indexes for common queries
+CREATE INDEX IF NOT EXISTS idx_alert_notes_alert_id_created_at ON alert_notes(alert_id, created_at);
+
+-- Add comment
+COMMENT ON TABLE alert_notes IS 'Stores notes attached to alerts for collaborative triage';
@@ -0,0 +1,6 @@
+-- Migration: Add exit_conditions to rules
+-- Description: Optional invalidation conditions that clear a rule's active alert for a symbol
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS exit_conditions JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+COMMENT ON COLUMN rules.exit_conditions IS 'Exit conditions (AND logic); when matched for a symbol with an active alert, an exit alert is emitted and the alert state is reset';
@@ -0,0 +1,11 @@
+-- Migration: Add user scoping for custom metrics
+-- Description: Rules record their owner so user custom metrics resolve with the owner's
+-- definitions; user toplists can rank by a custom metric
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS user_id VARCHAR(255);
+CREATE INDEX IF NOT EXISTS idx_rules_user_id ON rules(user_id);
+
+ALTER TABLE toplist_configs ADD COLUMN IF NOT EXISTS custom_metric VARCHAR(100);
+
+COMMENT ON COLUMN rules.user_id IS 'Rule owner; custom metrics in conditions resolve with this user''s definitions (NULL = system rule)';
+COMMENT ON COLUMN toplist_configs.custom_metric IS 'Custom metric name when metric is ''custom'' (user toplists only)';
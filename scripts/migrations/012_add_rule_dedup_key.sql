@@ -0,0 +1,6 @@
+-- Migration: Add dedup_key to rules
+-- Description: Optional alert deduplication key composition per rule
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS dedup_key JSONB;
+
+COMMENT ON COLUMN rules.dedup_key IS 'Alert dedup key fields, e.g. {"fields": ["rule", "symbol", "bar"]}; NULL uses rule, symbol and timestamp';
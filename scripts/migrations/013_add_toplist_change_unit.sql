@@ -0,0 +1,6 @@
+-- Migration: Add change unit to toplist configs
+-- Description: Change toplists can rank by percentage (default) or absolute points (dollar) change
+
+ALTER TABLE toplist_configs ADD COLUMN IF NOT EXISTS change_unit VARCHAR(10);
+
+COMMENT ON COLUMN toplist_configs.change_unit IS 'Change unit for change_pct toplists: ''pct'' (default when NULL) or ''points''';
@@ -0,0 +1,6 @@
+-- Migration: Add priority to rules
+-- Description: Alert delivery priority per rule; higher-priority alerts are delivered first under backpressure
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS priority INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN rules.priority IS 'Alert delivery priority (higher is delivered first, default 0)';
@@ -0,0 +1,6 @@
+-- Migration: Add delivery to rules
+-- Description: Optional per-rule delivery policy across notification channels
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS delivery JSONB;
+
+COMMENT ON COLUMN rules.delivery IS 'Alert delivery policy, e.g. {"mode": "primary_fallback", "primary": "websocket", "fallbacks": ["kafka"]}; NULL delivers to all channels';
@@ -0,0 +1,6 @@
+-- Migration: Add occurrences to alert_history
+-- Description: Number of alerts collapsed into a row when persister aggregation is enabled
+
+ALTER TABLE alert_history ADD COLUMN IF NOT EXISTS occurrences INTEGER NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN alert_history.occurrences IS 'Alerts with the same rule and symbol aggregated into this row within ALERT_DB_AGGREGATE_WINDOW; 1 when aggregation is disabled';
@@ -0,0 +1,18 @@
+-- Migration: Create indicator_values table
+-- Description: Historical indicator values persisted by the indicator engine when INDICATOR_DB_PERSIST_ENABLED is set
+
+CREATE TABLE IF NOT EXISTS indicator_values (
+    symbol VARCHAR(10) NOT NULL,
+    timestamp TIMESTAMPTZ NOT NULL,
+    indicator VARCHAR(64) NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (symbol, indicator, timestamp)
+);
+
+-- Create hypertable (TimescaleDB extension)
+SELECT create_hypertable('indicator_values', 'timestamp', if_not_exists => TRUE);
+
+-- Create composite index for per-symbol history queries
+CREATE INDEX IF NOT EXISTS idx_indicator_values_symbol_timestamp ON indicator_values (symbol, timestamp DESC);
+
+COMMENT ON TABLE indicator_values IS 'Indicator values published by the indicator engine, one row per symbol, indicator and publish time';
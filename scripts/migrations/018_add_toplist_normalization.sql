@@ -0,0 +1,6 @@
+-- Migration: Add normalization to toplist configs
+-- Description: Toplists can rank by metric values normalized against each symbol's recent values
+
+ALTER TABLE toplist_configs ADD COLUMN IF NOT EXISTS normalization VARCHAR(20);
+
+COMMENT ON COLUMN toplist_configs.normalization IS 'Normalization applied before ranking: ''none'' (default when NULL), ''zscore'' or ''percentile''';
@@ -0,0 +1,6 @@
+-- Migration: Add tie breakers to toplist configs
+-- Description: Toplists can order symbols with tied scores by secondary metrics instead of by symbol name
+
+ALTER TABLE toplist_configs ADD COLUMN IF NOT EXISTS tie_breakers JSONB;
+
+COMMENT ON COLUMN toplist_configs.tie_breakers IS 'Secondary sort keys applied when scores tie, e.g. ["volume", "symbol"]';
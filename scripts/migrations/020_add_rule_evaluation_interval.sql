@@ -0,0 +1,6 @@
+-- Migration: Add evaluation interval to rules
+-- Description: Expensive rules can opt into being evaluated at most once every N seconds instead of every scan cycle
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS evaluation_interval INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN rules.evaluation_interval IS 'Minimum seconds between evaluations of the rule (0 = every scan cycle)';
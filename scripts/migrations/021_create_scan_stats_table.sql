@@ -0,0 +1,24 @@
+-- Migration: Create scan_stats table
+-- Description: Scan loop statistics history persisted by scanner workers when SCANNER_STATS_PERSIST_ENABLED is set
+
+CREATE TABLE IF NOT EXISTS scan_stats (
+    worker_id VARCHAR(64) NOT NULL,
+    timestamp TIMESTAMPTZ NOT NULL,
+    scan_cycles BIGINT NOT NULL DEFAULT 0,
+    symbols_scanned BIGINT NOT NULL DEFAULT 0,
+    symbols_skipped_stale BIGINT NOT NULL DEFAULT 0,
+    rules_evaluated BIGINT NOT NULL DEFAULT 0,
+    rules_matched BIGINT NOT NULL DEFAULT 0,
+    alerts_emitted BIGINT NOT NULL DEFAULT 0,
+    avg_cycle_time_ms DOUBLE PRECISION NOT NULL DEFAULT 0,
+    last_cycle_time_ms DOUBLE PRECISION NOT NULL DEFAULT 0,
+    PRIMARY KEY (worker_id, timestamp)
+);
+
+-- Create hypertable (TimescaleDB extension)
+SELECT create_hypertable('scan_stats', 'timestamp', if_not_exists => TRUE);
+
+-- Create index for time range queries across workers
+CREATE INDEX IF NOT EXISTS idx_scan_stats_timestamp ON scan_stats (timestamp DESC);
+
+COMMENT ON TABLE scan_stats IS 'Scan loop statistics per scanner worker, one row per persistence interval with counters as deltas over the interval';
@@ -0,0 +1,8 @@
+-- Migration: Add condition groups and logic operator to rules
+-- Description: Rules can combine conditions with OR logic and nest AND/OR condition groups
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS condition_groups JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS logic_operator TEXT NOT NULL DEFAULT 'AND';
+
+COMMENT ON COLUMN rules.condition_groups IS 'Nested AND/OR condition groups combined with conditions';
+COMMENT ON COLUMN rules.logic_operator IS 'How conditions and condition groups combine: AND (default) or OR';
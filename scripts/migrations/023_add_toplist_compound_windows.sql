@@ -0,0 +1,6 @@
+-- Migration: Add compound windows to toplist configs
+-- Description: Toplists can require their metric to exceed thresholds across several windows at once (e.g. sustained gainers)
+
+ALTER TABLE toplist_configs ADD COLUMN IF NOT EXISTS compound_windows JSONB;
+
+COMMENT ON COLUMN toplist_configs.compound_windows IS 'Windows the metric must exceed its threshold in simultaneously, e.g. [{"window": "1m", "threshold": 1}, {"window": "5m", "threshold": 2}]';
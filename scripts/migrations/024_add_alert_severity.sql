@@ -0,0 +1,6 @@
+-- Migration: Add severity to alert_history
+-- Description: How far an alert's rule conditions were exceeded, classified by the scanner
+
+ALTER TABLE alert_history ADD COLUMN IF NOT EXISTS severity TEXT NOT NULL DEFAULT 'info';
+
+COMMENT ON COLUMN alert_history.severity IS 'info, warning or critical from the distance of the metric past each condition threshold; the highest severity when alerts are aggregated';
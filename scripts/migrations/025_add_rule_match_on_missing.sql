@@ -0,0 +1,6 @@
+-- Migration: Add missing metric policy to rules
+-- Description: Rules can opt into matching a condition whose metric has no value (e.g. not enough history) instead of treating it as a non-match
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS match_on_missing BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN rules.match_on_missing IS 'Whether a condition whose metric has no value matches (default: no match)';
@@ -0,0 +1,11 @@
+-- Migration: Restore per-rule cooldown on rules
+-- Description: Rules carry their own cooldown in seconds again (0 = no cooldown). Existing rules
+-- are backfilled with 10 seconds, the global SCANNER_COOLDOWN_DEFAULT they fired with until now
+
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS cooldown INTEGER;
+UPDATE rules SET cooldown = 10 WHERE cooldown IS NULL;
+ALTER TABLE rules ALTER COLUMN cooldown SET DEFAULT 0;
+ALTER TABLE rules ALTER COLUMN cooldown SET NOT NULL;
+
+COMMENT ON COLUMN rules.cooldown IS 'Seconds between alerts of the rule for a symbol (0 = no cooldown)';
+COMMENT ON TABLE rules IS 'Stores trading rules that can be used by the scanner';
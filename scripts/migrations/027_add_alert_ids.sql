@@ -0,0 +1,8 @@
+-- Migration: Add alert_ids to alert_history
+-- Description: IDs of every alert collapsed into a row when persister aggregation is enabled
+
+ALTER TABLE alert_history ADD COLUMN IF NOT EXISTS alert_ids TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_alert_history_alert_ids ON alert_history USING GIN (alert_ids);
+
+COMMENT ON COLUMN alert_history.alert_ids IS 'IDs of the alerts aggregated into this row within ALERT_DB_AGGREGATE_WINDOW, oldest first; the row ID alone when aggregation is disabled';
@@ -48,7 +48,7 @@ func TestFilterIntegration_ScanLoop(t *testing.T) {
 	sm := scanner.NewStateManager(100)
 	ruleStore := rules.NewInMemoryRuleStore()
 	compiler := rules.NewCompiler(nil)
-	cooldownTracker := scanner.NewCooldownTracker(10*time.Second, 5*time.Minute)
+	cooldownTracker := scanner.NewCooldownTracker(5 * time.Minute)
 	alertEmitter := newMockAlertEmitterE2E()
 
 	config := scanner.DefaultScanLoopConfig()
@@ -148,7 +148,7 @@ func TestFilterIntegration_VolumeThreshold(t *testing.T) {
 	sm := scanner.NewStateManager(100)
 	ruleStore := rules.NewInMemoryRuleStore()
 	compiler := rules.NewCompiler(nil)
-	cooldownTracker := scanner.NewCooldownTracker(10*time.Second, 5*time.Minute)
+	cooldownTracker := scanner.NewCooldownTracker(5 * time.Minute)
 	alertEmitter := newMockAlertEmitterE2E()
 
 	config := scanner.DefaultScanLoopConfig()
@@ -249,7 +249,7 @@ func TestFilterIntegration_SessionFiltering(t *testing.T) {
 	sm := scanner.NewStateManager(100)
 	ruleStore := rules.NewInMemoryRuleStore()
 	compiler := rules.NewCompiler(nil)
-	cooldownTracker := scanner.NewCooldownTracker(10*time.Second, 5*time.Minute)
+	cooldownTracker := scanner.NewCooldownTracker(5 * time.Minute)
 	alertEmitter := newMockAlertEmitterE2E()
 
 	config := scanner.DefaultScanLoopConfig()
@@ -363,7 +363,7 @@ func TestFilterIntegration_Timeframes(t *testing.T) {
 	sm := scanner.NewStateManager(100)
 	ruleStore := rules.NewInMemoryRuleStore()
 	compiler := rules.NewCompiler(nil)
-	cooldownTracker := scanner.NewCooldownTracker(10*time.Second, 5*time.Minute)
+	cooldownTracker := scanner.NewCooldownTracker(5 * time.Minute)
 	alertEmitter := newMockAlertEmitterE2E()
 
 	config := scanner.DefaultScanLoopConfig()
@@ -466,7 +466,7 @@ func TestFilterIntegration_ValueTypes(t *testing.T) {
 	sm := scanner.NewStateManager(100)
 	ruleStore := rules.NewInMemoryRuleStore()
 	compiler := rules.NewCompiler(nil)
-	cooldownTracker := scanner.NewCooldownTracker(10*time.Second, 5*time.Minute)
+	cooldownTracker := scanner.NewCooldownTracker(5 * time.Minute)
 	alertEmitter := newMockAlertEmitterE2E()
 
 	config := scanner.DefaultScanLoopConfig()
@@ -571,7 +571,7 @@ func TestFilterIntegration_Performance(t *testing.T) {
 	sm := scanner.NewStateManager(100)
 	ruleStore := rules.NewInMemoryRuleStore()
 	compiler := rules.NewCompiler(nil)
-	cooldownTracker := scanner.NewCooldownTracker(10*time.Second, 5*time.Minute)
+	cooldownTracker := scanner.NewCooldownTracker(5 * time.Minute)
 	alertEmitter := newMockAlertEmitterE2E()
 
 	config := scanner.DefaultScanLoopConfig()
@@ -724,7 +724,7 @@ func TestFilterIntegration_LazyComputation(t *testing.T) {
 	sm := scanner.NewStateManager(100)
 	ruleStore := rules.NewInMemoryRuleStore()
 	compiler := rules.NewCompiler(nil)
-	cooldownTracker := scanner.NewCooldownTracker(10*time.Second, 5*time.Minute)
+	cooldownTracker := scanner.NewCooldownTracker(5 * time.Minute)
 	alertEmitter := newMockAlertEmitterE2E()
 
 	config := scanner.DefaultScanLoopConfig()
@@ -639,6 +639,13 @@ func (m *mockCooldownTracker) RecordCooldown(ruleID, symbol string, cooldownSeco
 	m.cooldowns[key] = time.Now().Add(time.Duration(cooldownSeconds) * time.Second)
 }
 
+func (m *mockCooldownTracker) ClearCooldown(ruleID, symbol string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.cooldowns, ruleID+"|"+symbol)
+}
+
 func (m *mockCooldownTracker) Start() error { return nil }
 func (m *mockCooldownTracker) Stop()        {}
 